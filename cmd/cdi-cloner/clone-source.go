@@ -5,18 +5,23 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"flag"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"os/exec"
+	"strconv"
+	"sync"
+	"time"
 
-	"github.com/golang/snappy"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/klog/v2"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/util"
+	"kubevirt.io/containerized-data-importer/pkg/util/clonecodec"
 	prometheusutil "kubevirt.io/containerized-data-importer/pkg/util/prometheus"
 )
 
@@ -24,6 +29,8 @@ var (
 	contentType string
 	mountPoint  string
 	uploadBytes uint64
+	// fanOutWriteTimeout is how long a target of a fan-out clone may take to read a chunk before it is dropped
+	fanOutWriteTimeout = 5 * time.Minute
 )
 
 type execReader struct {
@@ -47,8 +54,30 @@ func (er *execReader) Close() error {
 	return er.stdout.Close()
 }
 
+// sourceProgressReader reports the progress of the stream sent to a target in bytes read from the source, rather than
+// bytes of the compressed stream, or of the extent stream of a sparse clone which skips the zero ranges
+type sourceProgressReader struct {
+	*prometheusutil.ProgressReader
+	position func() uint64
+}
+
+func (r *sourceProgressReader) Read(p []byte) (int, error) {
+	n, err := r.ProgressReader.Read(p)
+	r.Current = r.position()
+	return n, err
+}
+
+// cloneTarget is an upload server the source is cloned to
+type cloneTarget struct {
+	name       string
+	url        string
+	ownerUID   string
+	clientKey  []byte
+	clientCert []byte
+}
+
 func init() {
-	flag.StringVar(&contentType, "content-type", "", "filesystem-clone|blockdevice-clone")
+	flag.StringVar(&contentType, "content-type", "", "filesystem-clone|blockdevice-clone|blockdevice-clone-sparse")
 	flag.StringVar(&mountPoint, "mount", "", "pvc mount point")
 	flag.Uint64Var(&uploadBytes, "upload-bytes", 0, "approx number of bytes in input")
 	klog.InitFlags(nil)
@@ -92,7 +121,7 @@ func startPrometheus() {
 	prometheusutil.StartPrometheusEndpoint(certsDirectory)
 }
 
-func createProgressReader(readCloser io.ReadCloser, ownerUID string, totalBytes uint64) io.ReadCloser {
+func createProgress() *prometheus.CounterVec {
 	progress := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "clone_progress",
@@ -101,24 +130,30 @@ func createProgressReader(readCloser io.ReadCloser, ownerUID string, totalBytes
 		[]string{"ownerUID"},
 	)
 	prometheus.MustRegister(progress)
+	return progress
+}
 
+func createProgressReader(readCloser io.ReadCloser, progress *prometheus.CounterVec, ownerUID string, totalBytes uint64, position func() uint64) io.ReadCloser {
 	promReader := prometheusutil.NewProgressReader(readCloser, totalBytes, progress, ownerUID)
 	promReader.StartTimedUpdate()
 
-	return promReader
+	return &sourceProgressReader{ProgressReader: promReader, position: position}
 }
 
-func pipeToSnappy(reader io.ReadCloser) io.ReadCloser {
+func pipeToCodec(reader io.ReadCloser, codec string, level int) io.ReadCloser {
 	pr, pw := io.Pipe()
-	sbw := snappy.NewBufferedWriter(pw)
+	cw, err := clonecodec.NewWriter(pw, codec, level)
+	if err != nil {
+		klog.Fatalf("Error creating %s writer %+v", codec, err)
+	}
 
 	go func() {
-		n, err := io.Copy(sbw, reader)
+		n, err := io.Copy(cw, reader)
 		if err != nil {
-			klog.Fatalf("Error %s piping to gzip", err)
+			klog.Fatalf("Error %s piping to %s", err, codec)
 		}
-		if err = sbw.Close(); err != nil {
-			klog.Fatalf("Error closing snappy writer %+v", err)
+		if err = cw.Close(); err != nil {
+			klog.Fatalf("Error closing %s writer %+v", codec, err)
 		}
 		if err = pw.Close(); err != nil {
 			klog.Fatalf("Error closing pipe writer %+v", err)
@@ -131,7 +166,7 @@ func pipeToSnappy(reader io.ReadCloser) io.ReadCloser {
 
 func validateContentType() {
 	switch contentType {
-	case "filesystem-clone", "blockdevice-clone":
+	case "filesystem-clone", "blockdevice-clone", "blockdevice-clone-sparse":
 	default:
 		klog.Fatalf("Invalid content-type %q", contentType)
 	}
@@ -162,6 +197,166 @@ func newTarReader() (io.ReadCloser, error) {
 	return &execReader{cmd: cmd, stdout: stdout, stderr: ioutil.NopCloser(&stderr)}, nil
 }
 
+// getCodec returns the codec compressing the clone stream and its level, snappy unless the controller selected another
+func getCodec() (string, int) {
+	codec := os.Getenv(common.CloneCodec)
+	if codec == "" {
+		codec = clonecodec.Snappy
+	}
+	if err := clonecodec.Validate(codec); err != nil {
+		klog.Fatalf("Invalid codec %+v", err)
+	}
+
+	level := 0
+	if value := os.Getenv(common.CloneCodecLevel); value != "" {
+		var err error
+		if level, err = strconv.Atoi(value); err != nil {
+			klog.Fatalf("Invalid codec level %q", value)
+		}
+	}
+	return codec, level
+}
+
+// getTargets returns the targets of the clone, the fan-out targets when the source pod streams to several of them
+func getTargets() []cloneTarget {
+	count := os.Getenv(common.CloneTargets)
+	if count == "" {
+		return []cloneTarget{
+			{
+				url:        getEnvVarOrDie("UPLOAD_URL"),
+				ownerUID:   getEnvVarOrDie(common.OwnerUID),
+				clientKey:  []byte(getEnvVarOrDie("CLIENT_KEY")),
+				clientCert: []byte(getEnvVarOrDie("CLIENT_CERT")),
+			},
+		}
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n <= 0 {
+		klog.Fatalf("Invalid number of clone targets %q", count)
+	}
+	var targets []cloneTarget
+	for i := 0; i < n; i++ {
+		targetVar := func(name string) string {
+			return fmt.Sprintf(common.CloneTargetEnvFormat, i, name)
+		}
+		targets = append(targets, cloneTarget{
+			name:       getEnvVarOrDie(targetVar("NAME")),
+			url:        getEnvVarOrDie(targetVar("UPLOAD_URL")),
+			ownerUID:   os.Getenv(targetVar(common.OwnerUID)),
+			clientKey:  []byte(getEnvVarOrDie(targetVar("CLIENT_KEY"))),
+			clientCert: []byte(getEnvVarOrDie(targetVar("CLIENT_CERT"))),
+		})
+	}
+	return targets
+}
+
+// upload POSTs the body to the upload server of the target
+func upload(target cloneTarget, body io.Reader, header string, serverCert []byte) error {
+	client := createHTTPClient(target.clientKey, target.clientCert, serverCert)
+
+	req, err := http.NewRequest("POST", target.url, body)
+	if err != nil {
+		return errors.Wrapf(err, "error creating request to %s", target.url)
+	}
+	req.Header.Set(common.UploadContentTypeHeader, header)
+
+	response, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "error POSTing to %s", target.url)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("unexpected status code %d from %s", response.StatusCode, target.url)
+	}
+
+	var buf bytes.Buffer
+	if _, err = io.Copy(&buf, response.Body); err != nil {
+		return errors.Wrapf(err, "error copying response body from %s", target.url)
+	}
+
+	klog.V(1).Infof("Response body from %s:\n%s", target.url, buf.String())
+	return nil
+}
+
+// fanOut streams the reader to all the targets in parallel, and returns the error of each of them. The source is read
+// once, each chunk is written to the targets still streaming, so a failing target is dropped without affecting the
+// others. A target not reading a chunk within fanOutWriteTimeout is dropped too, so it holds back the others at most
+// that long.
+func fanOut(reader io.Reader, targets []cloneTarget, upload func(target cloneTarget, body io.ReadCloser) error) []error {
+	errs := make([]error, len(targets))
+	writers := make([]*io.PipeWriter, len(targets))
+	var uploads sync.WaitGroup
+	for i, target := range targets {
+		pr, pw := io.Pipe()
+		writers[i] = pw
+		uploads.Add(1)
+		go func(i int, target cloneTarget, pr *io.PipeReader) {
+			defer uploads.Done()
+			errs[i] = upload(target, pr)
+			// fails the writes to a target that stopped reading
+			pr.CloseWithError(errors.Errorf("upload to %s ended", target.url))
+		}(i, target, pr)
+	}
+
+	buf := make([]byte, 1024*1024)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			var writes sync.WaitGroup
+			for i, pw := range writers {
+				if pw == nil {
+					continue
+				}
+				writes.Add(1)
+				go func(i int, pw *io.PipeWriter) {
+					defer writes.Done()
+					if err := writeWithTimeout(pw, buf[:n], fanOutWriteTimeout); err != nil {
+						klog.Errorf("Error streaming to %s, dropping it: %v", targets[i].url, err)
+						writers[i] = nil
+					}
+				}(i, pw)
+			}
+			writes.Wait()
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			klog.Fatalf("Error reading clone source: %+v", err)
+		}
+	}
+	for _, pw := range writers {
+		if pw != nil {
+			pw.Close()
+		}
+	}
+	uploads.Wait()
+	return errs
+}
+
+// writeWithTimeout writes p to the pipe, and fails the pipe if the reader doesn't read it within timeout. It only
+// returns once the pipe is done with p.
+func writeWithTimeout(pw *io.PipeWriter, p []byte, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := pw.Write(p)
+		done <- err
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		err := errors.Errorf("timed out after %s waiting for the upload to read", timeout)
+		pw.CloseWithError(err)
+		<-done
+		return err
+	}
+}
+
 func getInputStream() (rc io.ReadCloser) {
 	var err error
 	switch contentType {
@@ -175,6 +370,15 @@ func getInputStream() (rc io.ReadCloser) {
 		if err != nil {
 			klog.Fatalf("Error opening block device %q: %+v", mountPoint, err)
 		}
+	case "blockdevice-clone-sparse":
+		file, err := os.Open(mountPoint)
+		if err != nil {
+			klog.Fatalf("Error opening block device %q: %+v", mountPoint, err)
+		}
+		rc, err = util.NewExtentReader(file)
+		if err != nil {
+			klog.Fatalf("Error creating extent reader for %q: %+v", mountPoint, err)
+		}
 	default:
 		klog.Fatalf("Invalid content-type %q", contentType)
 	}
@@ -192,49 +396,54 @@ func main() {
 	validateContentType()
 	validateMount()
 
-	ownerUID := getEnvVarOrDie(common.OwnerUID)
-
-	clientKey := []byte(getEnvVarOrDie("CLIENT_KEY"))
-	clientCert := []byte(getEnvVarOrDie("CLIENT_CERT"))
+	targets := getTargets()
 	serverCert := []byte(getEnvVarOrDie("SERVER_CA_CERT"))
 
-	url := getEnvVarOrDie("UPLOAD_URL")
-
 	klog.V(1).Infoln("Starting cloner target")
 
-	reader := pipeToSnappy(createProgressReader(getInputStream(), ownerUID, uploadBytes))
+	codec, level := getCodec()
+	klog.Infof("codec is %q, level %d", codec, level)
 
-	startPrometheus()
-
-	client := createHTTPClient(clientKey, clientCert, serverCert)
+	input := getInputStream()
+	source := &util.CountingReader{Reader: input}
+	position := func() uint64 {
+		return source.Current
+	}
+	if extents, ok := input.(*util.ExtentReader); ok {
+		position = func() uint64 {
+			return uint64(extents.Offset())
+		}
+		defer func() {
+			klog.Infof("Sent %d data bytes of %d", extents.DataBytes(), extents.Offset())
+		}()
+	}
+	reader := pipeToCodec(source, codec, level)
 
-	req, _ := http.NewRequest("POST", url, reader)
+	startPrometheus()
+	progress := createProgress()
 
-	if contentType != "" {
-		req.Header.Set("x-cdi-content-type", contentType)
-		klog.Infof("Set header to %s", contentType)
-	}
+	header := clonecodec.ContentType(contentType, codec)
+	klog.Infof("Set header to %s", header)
 
-	response, err := client.Do(req)
-	if err != nil {
-		klog.Fatalf("Error %s POSTing to %s", err, url)
-	}
+	errs := fanOut(reader, targets, func(target cloneTarget, body io.ReadCloser) error {
+		return upload(target, createProgressReader(body, progress, target.ownerUID, uploadBytes, position), header, serverCert)
+	})
 
-	if response.StatusCode < 200 || response.StatusCode >= 300 {
-		klog.Fatalf("Unexpected status code %d", response.StatusCode)
+	var failedTargets []string
+	for i, err := range errs {
+		if err != nil {
+			klog.Errorf("Clone to %s failed: %+v", targets[i].url, err)
+			failedTargets = append(failedTargets, targets[i].name)
+		}
 	}
-
-	var buf bytes.Buffer
-	_, err = io.Copy(&buf, response.Body)
-	if err != nil {
-		klog.Fatalf("Error %s copying response body", err)
+	// a single target is retried by restarting the pod, the failed targets of a fan-out clone fall back to a source
+	// pod of their own
+	if len(failedTargets) == len(targets) {
+		klog.Fatalf("Clone failed for all targets")
 	}
 
-	klog.V(1).Infof("Response body:\n%s", buf.String())
-
 	klog.V(1).Infoln("clone complete")
-	err = util.WriteTerminationMessage("Clone Complete")
-	if err != nil {
+	if err := util.WriteCloneCompletionMessage("Clone Complete", failedTargets); err != nil {
 		klog.Errorf("%+v", err)
 		os.Exit(1)
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"io"
 	"io/ioutil"
 	"os"
@@ -8,7 +9,9 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
+	"kubevirt.io/containerized-data-importer/pkg/common"
 	prometheusutil "kubevirt.io/containerized-data-importer/pkg/util/prometheus"
 )
 
@@ -29,6 +32,86 @@ var _ = Describe("Prometheus Endpoint", func() {
 	})
 })
 
+var _ = Describe("Fan-out clone", func() {
+	It("Should stream the source to all targets and isolate a failing one", func() {
+		source := bytes.Repeat([]byte("0123456789"), 500*1024)
+		targets := []cloneTarget{{name: "ns/good1", url: "good1"}, {name: "ns/bad", url: "bad"}, {name: "ns/good2", url: "good2"}}
+		received := make([][]byte, len(targets))
+		errs := fanOut(bytes.NewReader(source), targets, func(target cloneTarget, body io.ReadCloser) error {
+			defer body.Close()
+			if target.url == "bad" {
+				// stop reading after the first chunk
+				if _, err := body.Read(make([]byte, 10)); err != nil {
+					return err
+				}
+				return errors.New("upload failed")
+			}
+			data, err := ioutil.ReadAll(body)
+			for i := range targets {
+				if targets[i].url == target.url {
+					received[i] = data
+				}
+			}
+			return err
+		})
+		Expect(errs[0]).ToNot(HaveOccurred())
+		Expect(errs[1]).To(MatchError("upload failed"))
+		Expect(errs[2]).ToNot(HaveOccurred())
+		Expect(received[0]).To(Equal(source))
+		Expect(received[2]).To(Equal(source))
+	})
+
+	It("Should drop a target that stops reading without holding back the others", func() {
+		defer func(timeout time.Duration) { fanOutWriteTimeout = timeout }(fanOutWriteTimeout)
+		fanOutWriteTimeout = 100 * time.Millisecond
+		source := bytes.Repeat([]byte("0123456789"), 500*1024)
+		targets := []cloneTarget{{name: "ns/good", url: "good"}, {name: "ns/stalled", url: "stalled"}}
+		var received []byte
+		goodDone := make(chan struct{})
+		errs := fanOut(bytes.NewReader(source), targets, func(target cloneTarget, body io.ReadCloser) error {
+			defer body.Close()
+			if target.url == "stalled" {
+				// never reads until the other target received everything
+				<-goodDone
+				_, err := ioutil.ReadAll(body)
+				return err
+			}
+			defer close(goodDone)
+			var err error
+			received, err = ioutil.ReadAll(body)
+			return err
+		})
+		Expect(errs[0]).ToNot(HaveOccurred())
+		Expect(received).To(Equal(source))
+		Expect(errs[1]).To(HaveOccurred())
+		Expect(errs[1].Error()).To(ContainSubstring("timed out"))
+	})
+
+	It("Should read the targets of a fan-out clone", func() {
+		env := map[string]string{
+			common.CloneTargets:          "2",
+			"CLONE_TARGET_0_NAME":        "ns/target0",
+			"CLONE_TARGET_0_UPLOAD_URL":  "https://target0",
+			"CLONE_TARGET_0_CLIENT_KEY":  "key0",
+			"CLONE_TARGET_0_CLIENT_CERT": "cert0",
+			"CLONE_TARGET_0_OWNER_UID":   "uid0",
+			"CLONE_TARGET_1_NAME":        "ns/target1",
+			"CLONE_TARGET_1_UPLOAD_URL":  "https://target1",
+			"CLONE_TARGET_1_CLIENT_KEY":  "key1",
+			"CLONE_TARGET_1_CLIENT_CERT": "cert1",
+		}
+		for name, value := range env {
+			os.Setenv(name, value)
+			defer os.Unsetenv(name)
+		}
+		targets := getTargets()
+		Expect(targets).To(Equal([]cloneTarget{
+			{name: "ns/target0", url: "https://target0", ownerUID: "uid0", clientKey: []byte("key0"), clientCert: []byte("cert0")},
+			{name: "ns/target1", url: "https://target1", clientKey: []byte("key1"), clientCert: []byte("cert1")},
+		}))
+	})
+})
+
 func isDirEmpty(dirName string) (bool, error) {
 	f, err := os.Open(dirName)
 	if err != nil {
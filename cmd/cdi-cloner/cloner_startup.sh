@@ -26,14 +26,32 @@ if [[ -z "$MOUNT_POINT" ]]; then
     exit 1
 fi
 
+TARGET_VOLUME_MODE=${TARGET_VOLUME_MODE:-$VOLUME_MODE}
+
 echo "VOLUME_MODE=$VOLUME_MODE"
+echo "TARGET_VOLUME_MODE=$TARGET_VOLUME_MODE"
 echo "MOUNT_POINT=$MOUNT_POINT"
 
+# A block source is always streamed as raw extents, skipping its zero ranges, the upload server writes them to the
+# target device or to disk.img
 if [ "$VOLUME_MODE" == "block" ]; then
     UPLOAD_BYTES=$(blockdev --getsize64 $MOUNT_POINT)
     echo "UPLOAD_BYTES=$UPLOAD_BYTES"
 
-    /usr/bin/cdi-cloner -v=3 -alsologtostderr -content-type blockdevice-clone -upload-bytes $UPLOAD_BYTES -mount $MOUNT_POINT
+    /usr/bin/cdi-cloner -v=3 -alsologtostderr -content-type blockdevice-clone-sparse -upload-bytes $UPLOAD_BYTES -mount $MOUNT_POINT
+# A filesystem source is streamed as the extents of the raw disk.img it holds when the target is a block device,
+# its holes are skipped
+elif [ "$TARGET_VOLUME_MODE" == "block" ]; then
+    DISK_IMAGE=$MOUNT_POINT/disk.img
+    if [ ! -f "$DISK_IMAGE" ]; then
+        echo "$DISK_IMAGE missing, cannot clone to a block volume" 1>&2
+        exit 1
+    fi
+
+    UPLOAD_BYTES=$(stat -c %s $DISK_IMAGE)
+    echo "UPLOAD_BYTES=$UPLOAD_BYTES"
+
+    /usr/bin/cdi-cloner -v=3 -alsologtostderr -content-type blockdevice-clone-sparse -upload-bytes $UPLOAD_BYTES -mount $DISK_IMAGE
 else
     pushd $MOUNT_POINT
     UPLOAD_BYTES=$(du -sb . | cut -f1)
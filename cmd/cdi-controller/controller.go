@@ -2,10 +2,8 @@ package main
 
 import (
 	"context"
-	"crypto/rsa"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"strconv"
 
@@ -28,6 +26,8 @@ import (
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/controller"
+	"kubevirt.io/containerized-data-importer/pkg/keys"
+	"kubevirt.io/containerized-data-importer/pkg/token"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/fetcher"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/generator"
@@ -142,7 +142,7 @@ func start(cfg *rest.Config, stopCh <-chan struct{}) {
 		os.Exit(1)
 	}
 	// TODO: Current DV controller had threadiness 3, should we do the same here, defaults to one thread.
-	if _, err := controller.NewDatavolumeController(mgr, extClient, log); err != nil {
+	if _, err := controller.NewDatavolumeController(mgr, extClient, log, importerImage, pullPolicy, verbose); err != nil {
 		klog.Errorf("Unable to setup datavolume controller: %v", err)
 		os.Exit(1)
 	}
@@ -152,7 +152,7 @@ func start(cfg *rest.Config, stopCh <-chan struct{}) {
 		os.Exit(1)
 	}
 	
-	if _, err := controller.NewCloneController(mgr, log, clonerImage, pullPolicy, verbose, uploadClientCertGenerator, uploadServerBundleFetcher, getAPIServerPublicKey()); err != nil {
+	if _, err := controller.NewCloneController(mgr, log, clonerImage, pullPolicy, verbose, uploadClientCertGenerator, uploadServerBundleFetcher, getAPIServerPublicKeys()); err != nil {
 		klog.Errorf("Unable to setup clone controller: %v", err)
 		os.Exit(1)
 	}
@@ -166,6 +166,21 @@ func start(cfg *rest.Config, stopCh <-chan struct{}) {
 		klog.Errorf("Unable to setup upload controller: %v", err)
 		os.Exit(1)
 	}
+
+	if _, err := controller.NewExportController(mgr, log, uploadServerImage, pullPolicy, verbose, uploadServerCertGenerator, uploadClientBundleFetcher); err != nil {
+		klog.Errorf("Unable to setup export controller: %v", err)
+		os.Exit(1)
+	}
+
+	if _, err := controller.NewDataSourceController(mgr, log); err != nil {
+		klog.Errorf("Unable to setup datasource controller: %v", err)
+		os.Exit(1)
+	}
+
+	if _, err := controller.NewDataImportCronController(mgr, log, importerImage, pullPolicy, verbose); err != nil {
+		klog.Errorf("Unable to setup dataimportcron controller: %v", err)
+		os.Exit(1)
+	}
 	
 
 	klog.V(1).Infoln("created cdi controllers")
@@ -254,16 +269,11 @@ func startSmartController(extclient extclientset.Interface, mgr manager.Manager,
 	}
 }
 
-func getAPIServerPublicKey() *rsa.PublicKey {
-	keyBytes, err := ioutil.ReadFile(controller.APIServerPublicKeyPath)
-	if err != nil {
-		klog.Fatalf("Error reading apiserver public key")
-	}
-
-	key, err := controller.DecodePublicKey(keyBytes)
-	if err != nil {
-		klog.Fatalf("Error decoding public key")
+func getAPIServerPublicKeys() token.PublicKeySource {
+	bundle := keys.NewPublicKeyBundleFile(controller.APIServerPublicKeyBundlePath, controller.APIServerPublicKeyPath)
+	if _, err := bundle.PublicKeys(); err != nil {
+		klog.Fatalf("Error reading apiserver public keys: %v", err)
 	}
 
-	return key
+	return bundle
 }
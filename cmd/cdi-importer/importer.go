@@ -63,6 +63,26 @@ func main() {
 	uuid, _ := util.ParseEnvVar(common.ImporterUUID, false)
 	backingFile, _ := util.ParseEnvVar(common.ImporterBackingFile, false)
 	thumbprint, _ := util.ParseEnvVar(common.ImporterThumbprint, false)
+	currentCheckpoint, _ := util.ParseEnvVar(common.ImporterCurrentCheckpoint, false)
+	previousCheckpoint, _ := util.ParseEnvVar(common.ImporterPreviousCheckpoint, false)
+	finalCheckpoint, _ := strconv.ParseBool(os.Getenv(common.ImporterFinalCheckpoint))
+	preallocation, _ := strconv.ParseBool(os.Getenv(common.Preallocation))
+	checksum, _ := util.ParseEnvVar(common.Checksum, false)
+	ovaDisk, _ := util.ParseEnvVar(common.ImporterOVADisk, false)
+	concurrency, _ := strconv.Atoi(os.Getenv(common.ImporterConcurrency))
+	pollSourceDigest, _ := strconv.ParseBool(os.Getenv(common.ImporterPollSourceDigest))
+	probeVirtualSize, _ := strconv.ParseBool(os.Getenv(common.ImporterProbeVirtualSize))
+	preallocationApplied := false
+
+	if pollSourceDigest {
+		pollDigest(source, ep, acc, sec, certDir, insecureTLS)
+		return
+	}
+
+	if probeVirtualSize {
+		probeSize(source, ep, acc, sec, certDir)
+		return
+	}
 
 	//Registry import currently support kubevirt content type only
 	if contentType != string(cdiv1.DataVolumeKubeVirt) && (source == controller.SourceRegistry || source == controller.SourceImageio) {
@@ -90,14 +110,27 @@ func main() {
 		klog.Errorf("%+v", err)
 		os.Exit(1)
 	}
-	if source == controller.SourceNone && contentType == string(cdiv1.DataVolumeKubeVirt) {
+	if source == controller.SourceNone && contentType == string(cdiv1.DataVolumeKubeVirt) && volumeMode == v1.PersistentVolumeBlock {
+		if preallocation {
+			klog.V(1).Infoln("Preallocating blank block volume")
+			if err := util.PreallocateBlock(dest, 0); err != nil {
+				klog.Errorf("%+v", err)
+				err = util.WriteTerminationMessage(fmt.Sprintf("Unable to preallocate blank block volume: %+v", err))
+				if err != nil {
+					klog.Errorf("%+v", err)
+				}
+				os.Exit(1)
+			}
+			preallocationApplied = true
+		}
+	} else if source == controller.SourceNone && contentType == string(cdiv1.DataVolumeKubeVirt) {
 		requestImageSizeQuantity := resource.MustParse(imageSize)
 		minSizeQuantity := util.MinQuantity(resource.NewScaledQuantity(availableDestSpace, 0), &requestImageSizeQuantity)
 		if minSizeQuantity.Cmp(requestImageSizeQuantity) != 0 {
 			// Available dest space is smaller than the size we want to create
 			klog.Warningf("Available space less than requested size, creating blank image sized to available space: %s.\n", minSizeQuantity.String())
 		}
-		err := image.CreateBlankImage(common.ImporterWritePath, minSizeQuantity)
+		err := image.CreateBlankImage(common.ImporterWritePath, minSizeQuantity, preallocation)
 		if err != nil {
 			klog.Errorf("%+v", err)
 			err = util.WriteTerminationMessage(fmt.Sprintf("Unable to create blank image: %+v", err))
@@ -106,6 +139,7 @@ func main() {
 			}
 			os.Exit(1)
 		}
+		preallocationApplied = preallocation
 	} else if source == controller.SourceNone && contentType == string(cdiv1.DataVolumeArchive) {
 		klog.Errorf("%+v", errors.New("Cannot create empty disk with content type archive"))
 		err = util.WriteTerminationMessage("Cannot create empty disk with content type archive")
@@ -118,7 +152,7 @@ func main() {
 		var dp importer.DataSourceInterface
 		switch source {
 		case controller.SourceHTTP:
-			dp, err = importer.NewHTTPDataSource(ep, acc, sec, certDir, cdiv1.DataVolumeContentType(contentType))
+			dp, err = importer.NewHTTPDataSource(ep, acc, sec, certDir, cdiv1.DataVolumeContentType(contentType), checksum, ovaDisk, concurrency)
 			if err != nil {
 				klog.Errorf("%+v", err)
 				err = util.WriteTerminationMessage(fmt.Sprintf("Unable to connect to http data source: %+v", err))
@@ -138,9 +172,9 @@ func main() {
 				os.Exit(1)
 			}
 		case controller.SourceRegistry:
-			dp = importer.NewRegistryDataSource(ep, acc, sec, certDir, insecureTLS)
+			dp = importer.NewRegistryDataSource(ep, acc, sec, certDir, insecureTLS, checksum)
 		case controller.SourceS3:
-			dp, err = importer.NewS3DataSource(ep, acc, sec)
+			dp, err = importer.NewS3DataSource(ep, acc, sec, checksum, ovaDisk, concurrency)
 			if err != nil {
 				klog.Errorf("%+v", err)
 				err = util.WriteTerminationMessage(fmt.Sprintf("Unable to connect to s3 data source: %+v", err))
@@ -150,7 +184,7 @@ func main() {
 				os.Exit(1)
 			}
 		case controller.SourceVDDK:
-			dp, err = importer.NewVDDKDataSource(ep, acc, sec, thumbprint, uuid, backingFile)
+			dp, err = importer.NewVDDKDataSource(ep, acc, sec, thumbprint, uuid, backingFile, currentCheckpoint, previousCheckpoint, finalCheckpoint)
 			if err != nil {
 				klog.Errorf("%+v", err)
 				err = util.WriteTerminationMessage(fmt.Sprintf("Unable to connect to vddk data source: %+v", err))
@@ -168,24 +202,90 @@ func main() {
 			os.Exit(1)
 		}
 		defer dp.Close()
-		processor := importer.NewDataProcessor(dp, dest, dataDir, common.ScratchDataDir, imageSize, filesystemOverhead)
-		err = processor.ProcessData()
+		processor := importer.NewDataProcessor(dp, dest, dataDir, common.ScratchDataDir, imageSize, filesystemOverhead, preallocation)
+		if previousCheckpoint != "" {
+			// A later stage of a multistage import writes on top of the existing data, so resume instead of starting from scratch.
+			err = processor.ProcessDataResume()
+		} else {
+			err = processor.ProcessData()
+		}
 		if err != nil {
 			klog.Errorf("%+v", err)
 			if err == importer.ErrRequiresScratchSpace {
 				os.Exit(common.ScratchSpaceNeededExitCode)
 			}
+			exitCode := 1
+			if util.IsChecksumMismatch(err) {
+				exitCode = common.ChecksumMismatchExitCode
+			}
 			err = util.WriteTerminationMessage(fmt.Sprintf("Unable to process data: %+v", err))
 			if err != nil {
 				klog.Errorf("%+v", err)
 			}
-			os.Exit(1)
+			os.Exit(exitCode)
 		}
+		preallocationApplied = processor.PreallocationApplied()
 	}
-	err = util.WriteTerminationMessage("Import Complete")
+	err = util.WriteCompletionMessage("Import Complete", dest, preallocationApplied)
 	if err != nil {
 		klog.Errorf("%+v", err)
 		os.Exit(1)
 	}
 	klog.V(1).Infoln("Import complete")
 }
+
+// pollDigest reports the digest of the source in the termination message, without importing it
+func pollDigest(source, ep, acc, sec, certDir string, insecureTLS bool) {
+	klog.V(1).Infoln("Polling source digest")
+	var digest string
+	var err error
+	switch source {
+	case controller.SourceRegistry:
+		digest, err = importer.GetImageDigest(ep, acc, sec, certDir, insecureTLS)
+	case controller.SourceHTTP:
+		digest, err = importer.GetHTTPEtag(ep, acc, sec, certDir)
+	default:
+		err = errors.Errorf("Unsupported source for polling: %s", source)
+	}
+	if err != nil {
+		klog.Errorf("%+v", err)
+		err = util.WriteTerminationMessage(fmt.Sprintf("Unable to poll source: %+v", err))
+		if err != nil {
+			klog.Errorf("%+v", err)
+		}
+		os.Exit(1)
+	}
+	if err := util.WriteSourceDigestMessage(digest); err != nil {
+		klog.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	klog.V(1).Infof("Source digest %s\n", digest)
+}
+
+// probeSize reports the virtual size of the source image in the termination message, without importing it
+func probeSize(source, ep, acc, sec, certDir string) {
+	klog.V(1).Infoln("Probing source virtual size")
+	var virtualSize int64
+	var err error
+	switch source {
+	case controller.SourceHTTP:
+		virtualSize, err = importer.GetHTTPVirtualSize(ep, acc, sec, certDir)
+	case controller.SourceS3:
+		virtualSize, err = importer.GetS3VirtualSize(ep, acc, sec)
+	default:
+		err = errors.Errorf("Unsupported source for probing: %s", source)
+	}
+	if err != nil {
+		klog.Errorf("%+v", err)
+		err = util.WriteTerminationMessage(fmt.Sprintf("Unable to probe source: %+v", err))
+		if err != nil {
+			klog.Errorf("%+v", err)
+		}
+		os.Exit(1)
+	}
+	if err := util.WriteVirtualSizeMessage(virtualSize); err != nil {
+		klog.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	klog.V(1).Infof("Source virtual size %d\n", virtualSize)
+}
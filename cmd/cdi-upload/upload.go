@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned"
+	"kubevirt.io/containerized-data-importer/pkg/uploadclient"
+)
+
+var (
+	configPath             string
+	masterURL              string
+	namespace              string
+	name                   string
+	size                   string
+	storageClass           string
+	imagePath              string
+	proxyURL               string
+	caCertPath             string
+	insecure               bool
+	checksum               string
+	computeChecksum        bool
+	chunkSize              int64
+	retries                int
+	tokenExpirationSeconds int64
+	waitForSucceeded       bool
+	timeout                time.Duration
+)
+
+func init() {
+	flag.StringVar(&configPath, "kubeconfig", os.Getenv("KUBECONFIG"), "(Optional) Overrides $KUBECONFIG")
+	flag.StringVar(&masterURL, "server", "", "(Optional) URL address of a remote api server.  Do not set for local clusters.")
+	flag.StringVar(&namespace, "namespace", "default", "Namespace of the DataVolume")
+	flag.StringVar(&name, "dv", "", "Name of the DataVolume to upload to")
+	flag.StringVar(&size, "size", "", "(Optional) Size of the DataVolume to create, an existing DataVolume is used if not set")
+	flag.StringVar(&storageClass, "storage-class", "", "(Optional) Storage class of the DataVolume to create")
+	flag.StringVar(&imagePath, "image-path", "", "Path of the disk image to upload")
+	flag.StringVar(&proxyURL, "uploadproxy-url", "", "URL of the cdi-uploadproxy service")
+	flag.StringVar(&caCertPath, "ca-cert", "", "(Optional) CA certificate of the cdi-uploadproxy service")
+	flag.BoolVar(&insecure, "insecure", false, "Do not verify the certificate of the cdi-uploadproxy service")
+	flag.StringVar(&checksum, "checksum", "", "(Optional) Checksum the image must match, in the <algorithm>:<hex digest> format")
+	flag.BoolVar(&computeChecksum, "compute-checksum", false, "Compute the SHA-256 checksum of the image, so the uploaded data is verified")
+	flag.Int64Var(&chunkSize, "chunk-size", 0, "(Optional) Size of the chunks the image is sent in, 64MiB by default")
+	flag.IntVar(&retries, "retries", 0, "(Optional) Number of retries of failing requests, 5 by default")
+	flag.Int64Var(&tokenExpirationSeconds, "token-expiration-seconds", 0, "(Optional) Requested lifetime of the upload tokens")
+	flag.BoolVar(&waitForSucceeded, "wait", false, "Wait for the uploaded data to be processed")
+	flag.DurationVar(&timeout, "timeout", time.Hour, "Time allowed for the whole upload")
+	klog.InitFlags(nil)
+	flag.Parse()
+}
+
+func main() {
+	defer klog.Flush()
+
+	if name == "" || imagePath == "" || proxyURL == "" {
+		fmt.Fprintln(os.Stderr, "-dv, -image-path and -uploadproxy-url are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := upload(); err != nil {
+		klog.Errorf("Upload failed: %v", err)
+		os.Exit(1)
+	}
+}
+
+func upload() error {
+	cfg, err := clientcmd.BuildConfigFromFlags(masterURL, configPath)
+	if err != nil {
+		return errors.Wrap(err, "unable to get kube config")
+	}
+	cdiClient, err := cdiclient.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "unable to get CDI client")
+	}
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	image, err := os.Open(imagePath)
+	if err != nil {
+		return errors.Wrap(err, "unable to open the image")
+	}
+	defer image.Close()
+	info, err := image.Stat()
+	if err != nil {
+		return errors.Wrap(err, "unable to get the image size")
+	}
+
+	if computeChecksum && checksum == "" {
+		fmt.Printf("Computing the checksum of %s\n", imagePath)
+		if checksum, err = uploadclient.Checksum(image); err != nil {
+			return err
+		}
+		fmt.Printf("Checksum %s\n", checksum)
+	}
+
+	client := uploadclient.NewClient(cdiClient, httpClient, proxyURL, uploadclient.Options{
+		ChunkSize:              chunkSize,
+		Retries:                retries,
+		TokenExpirationSeconds: tokenExpirationSeconds,
+		Progress:               os.Stdout,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if size != "" {
+		quantity, err := resource.ParseQuantity(size)
+		if err != nil {
+			return errors.Wrap(err, "invalid size")
+		}
+		dv := uploadclient.NewUploadDataVolume(namespace, name, quantity, storageClass, checksum)
+		if _, err := client.EnsureDataVolume(ctx, dv); err != nil {
+			return err
+		}
+	}
+	if err := client.WaitForUploadReady(ctx, namespace, name); err != nil {
+		return err
+	}
+	if err := client.Upload(ctx, namespace, name, image, info.Size(), checksum); err != nil {
+		return err
+	}
+	if waitForSucceeded {
+		return client.WaitForSucceeded(ctx, namespace, name)
+	}
+	return nil
+}
+
+func newHTTPClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if caCertPath != "" {
+		caCert, err := ioutil.ReadFile(caCertPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the CA certificate")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("invalid CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}}, nil
+}
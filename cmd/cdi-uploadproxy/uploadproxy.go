@@ -11,6 +11,8 @@ import (
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
 
+	"kubevirt.io/containerized-data-importer/pkg/controller"
+	"kubevirt.io/containerized-data-importer/pkg/keys"
 	"kubevirt.io/containerized-data-importer/pkg/uploadproxy"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 	certfetcher "kubevirt.io/containerized-data-importer/pkg/util/cert/fetcher"
@@ -69,10 +71,6 @@ func main() {
 	if err != nil {
 		klog.Fatalf("Unable to get kube client: %v\n", errors.WithStack(err))
 	}
-	apiServerPublicKey, err := getAPIServerPublicKey()
-	if err != nil {
-		klog.Fatalf("Unable to get apiserver public key %v\n", errors.WithStack(err))
-	}
 	certWatcher, err := certwatcher.New(serverCertFile, serverKeyFile)
 	if err != nil {
 		klog.Fatalf("Unable to create certwatcher: %v\n", errors.WithStack(err))
@@ -86,7 +84,7 @@ func main() {
 
 	uploadProxy, err := uploadproxy.NewUploadProxy(defaultHost,
 		defaultPort,
-		apiServerPublicKey,
+		keys.NewPublicKeyBundleFile(controller.APIServerPublicKeyBundlePath, controller.APIServerPublicKeyPath),
 		certWatcher,
 		clientCertFetcher,
 		serverCAFetcher,
@@ -102,12 +100,3 @@ func main() {
 		klog.Fatalf("TLS server failed: %v\n", errors.WithStack(err))
 	}
 }
-
-func getAPIServerPublicKey() (string, error) {
-	const envName = "APISERVER_PUBLIC_KEY"
-	val, ok := os.LookupEnv(envName)
-	if !ok {
-		return "", errors.Errorf("%s not defined", envName)
-	}
-	return val, nil
-}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/exportserver"
 	"kubevirt.io/containerized-data-importer/pkg/uploadserver"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
@@ -48,9 +49,15 @@ func main() {
 
 	listenAddress, listenPort := getListenAddressAndPort()
 
+	if source, exists := os.LookupEnv(common.ExportSource); exists {
+		runExportServer(listenAddress, listenPort, source)
+		return
+	}
+
 	destination := getDestination()
 
 	filesystemOverhead, _ := strconv.ParseFloat(os.Getenv(common.FilesystemOverheadVar), 64)
+	preallocation, _ := strconv.ParseBool(os.Getenv(common.Preallocation))
 
 	server := uploadserver.NewUploadServer(
 		listenAddress,
@@ -62,6 +69,8 @@ func main() {
 		os.Getenv("CLIENT_NAME"),
 		os.Getenv(common.UploadImageSize),
 		filesystemOverhead,
+		preallocation,
+		os.Getenv(common.Checksum),
 	)
 
 	klog.Infof("Upload destination: %s", destination)
@@ -82,9 +91,9 @@ func main() {
 		clone = true
 	}
 	if clone {
-		err = util.WriteTerminationMessage("Clone Complete")
+		err = util.WriteCompletionMessage("Clone Complete", destination, server.PreallocationApplied())
 	} else {
-		err = util.WriteTerminationMessage("Upload Complete")
+		err = util.WriteCompletionMessage("Upload Complete", destination, server.PreallocationApplied())
 	}
 	if err != nil {
 		klog.Errorf("%+v", err)
@@ -93,6 +102,29 @@ func main() {
 	klog.Info("UploadServer successfully exited")
 }
 
+// runExportServer serves the data of a volume mounted read-only, the upload server image doubles as export server
+func runExportServer(listenAddress string, listenPort int, source string) {
+	server := exportserver.NewExportServer(
+		listenAddress,
+		listenPort,
+		source,
+		common.ExportServerScratchDir,
+		os.Getenv("TLS_KEY"),
+		os.Getenv("TLS_CERT"),
+		os.Getenv("CLIENT_CERT"),
+		os.Getenv("CLIENT_NAME"),
+	)
+
+	klog.Infof("Export source: %s", source)
+
+	klog.Infof("Running export server on %s:%d", listenAddress, listenPort)
+
+	if err := server.Run(); err != nil {
+		klog.Errorf("ExportServer failed: %s", err)
+		os.Exit(1)
+	}
+}
+
 func getListenAddressAndPort() (string, int) {
 	addr, port := defaultListenAddress, defaultListenPort
 
@@ -18,6 +18,7 @@ require (
 	github.com/golang/snappy v0.0.2
 	github.com/google/uuid v1.1.1
 	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/klauspost/compress v1.10.8
 	github.com/kubernetes-csi/external-snapshotter/v2 v2.1.1
 	github.com/mrnold/go-libnbd v1.4.1-cdi
 	github.com/onsi/ginkgo v1.12.1
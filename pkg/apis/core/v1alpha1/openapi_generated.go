@@ -295,6 +295,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CDIList":                  schema_pkg_apis_core_v1alpha1_CDIList(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CDISpec":                  schema_pkg_apis_core_v1alpha1_CDISpec(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CDIStatus":                schema_pkg_apis_core_v1alpha1_CDIStatus(ref),
+		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CloneCodec":               schema_pkg_apis_core_v1alpha1_CloneCodec(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolume":               schema_pkg_apis_core_v1alpha1_DataVolume(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeBlankImage":     schema_pkg_apis_core_v1alpha1_DataVolumeBlankImage(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeCheckpoint":     schema_pkg_apis_core_v1alpha1_DataVolumeCheckpoint(ref),
@@ -303,6 +304,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSource":         schema_pkg_apis_core_v1alpha1_DataVolumeSource(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourceHTTP":     schema_pkg_apis_core_v1alpha1_DataVolumeSourceHTTP(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourceImageIO":  schema_pkg_apis_core_v1alpha1_DataVolumeSourceImageIO(ref),
+		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourceOVA":      schema_pkg_apis_core_v1alpha1_DataVolumeSourceOVA(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourcePVC":      schema_pkg_apis_core_v1alpha1_DataVolumeSourcePVC(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourceRegistry": schema_pkg_apis_core_v1alpha1_DataVolumeSourceRegistry(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourceS3":       schema_pkg_apis_core_v1alpha1_DataVolumeSourceS3(ref),
@@ -13624,11 +13626,38 @@ func schema_pkg_apis_core_v1alpha1_CDIConfigSpec(ref common.ReferenceCallback) c
 							Ref:         ref("kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.FilesystemOverhead"),
 						},
 					},
+					"preallocation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Preallocation controls whether storage for DataVolumes should be allocated in advance, unless the DataVolume overrides it.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"maxDownloadConcurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxDownloadConcurrency caps the number of parallel ranged requests a DataVolume may use to download an http or S3 source, if not defined it is 4",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"cloneCodec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CloneCodec selects the compression of the stream of host-assisted clones, unless the DataVolume overrides it. If not defined it is snappy",
+							Ref:         ref("kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CloneCodec"),
+						},
+					},
+					"uploadTokenMaxExpirationSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UploadTokenMaxExpirationSeconds caps the lifetime clients may request for upload tokens, if not defined it is 3600, and it is at most 86400",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.ResourceRequirements", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.FilesystemOverhead"},
+			"k8s.io/api/core/v1.ResourceRequirements", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CloneCodec", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.FilesystemOverhead"},
 	}
 }
 
@@ -13665,11 +13694,38 @@ func schema_pkg_apis_core_v1alpha1_CDIConfigStatus(ref common.ReferenceCallback)
 							Ref:         ref("kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.FilesystemOverhead"),
 						},
 					},
+					"preallocation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Preallocation controls whether storage for DataVolumes should be allocated in advance.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"maxDownloadConcurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxDownloadConcurrency is the maximum number of parallel ranged requests a DataVolume may use to download an http or S3 source",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"cloneCodec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CloneCodec is the compression of the stream of host-assisted clones",
+							Ref:         ref("kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CloneCodec"),
+						},
+					},
+					"uploadTokenMaxExpirationSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UploadTokenMaxExpirationSeconds is the maximum lifetime of upload tokens",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.ResourceRequirements", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.FilesystemOverhead"},
+			"k8s.io/api/core/v1.ResourceRequirements", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CloneCodec", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.FilesystemOverhead"},
 	}
 }
 
@@ -13823,6 +13879,34 @@ func schema_pkg_apis_core_v1alpha1_CDIStatus(ref common.ReferenceCallback) commo
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_CloneCodec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "CloneCodec selects the compression of the stream of a host-assisted clone",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the codec, \"none\", \"snappy\" or \"zstd\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"level": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_DataVolume(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -14088,10 +14172,32 @@ func schema_pkg_apis_core_v1alpha1_DataVolumeSourceHTTP(ref common.ReferenceCall
 							Format:      "",
 						},
 					},
+					"checksum": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>. With an OVA, it is the checksum of the imported disk file.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ova": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import",
+							Ref:         ref("kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourceOVA"),
+						},
+					},
+					"concurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 				},
 				Required: []string{"url"},
 			},
 		},
+		Dependencies: []string{
+			"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourceOVA"},
 	}
 }
 
@@ -14137,6 +14243,26 @@ func schema_pkg_apis_core_v1alpha1_DataVolumeSourceImageIO(ref common.ReferenceC
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_DataVolumeSourceOVA(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DataVolumeSourceOVA selects the disk to import from an OVA archive",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"disk": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Disk is either the index of the disk in the DiskSection of the OVF descriptor, starting at 0, or the href of the disk file in its References section. Defaults to the first disk.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_DataVolumeSourcePVC(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -14193,6 +14319,13 @@ func schema_pkg_apis_core_v1alpha1_DataVolumeSourceRegistry(ref common.Reference
 							Format:      "",
 						},
 					},
+					"checksum": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Checksum is the expected checksum of the disk image file in the container image, in the <algorithm>:<hex digest> format",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"url"},
 			},
@@ -14221,10 +14354,32 @@ func schema_pkg_apis_core_v1alpha1_DataVolumeSourceS3(ref common.ReferenceCallba
 							Format:      "",
 						},
 					},
+					"checksum": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>. With an OVA, it is the checksum of the imported disk file.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ova": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import",
+							Ref:         ref("kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourceOVA"),
+						},
+					},
+					"concurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 				},
 				Required: []string{"url"},
 			},
 		},
+		Dependencies: []string{
+			"kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSourceOVA"},
 	}
 }
 
@@ -14234,6 +14389,15 @@ func schema_pkg_apis_core_v1alpha1_DataVolumeSourceUpload(ref common.ReferenceCa
 			SchemaProps: spec.SchemaProps{
 				Description: "DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source",
 				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"checksum": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Checksum is the expected checksum of the uploaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
 			},
 		},
 	}
@@ -14333,12 +14497,25 @@ func schema_pkg_apis_core_v1alpha1_DataVolumeSpec(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"preallocation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Preallocation controls whether storage for DataVolumes should be allocated in advance. If not set, the preallocation value of the CDIConfig is used.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"cloneCodec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CloneCodec selects the compression of the stream of a host-assisted clone. If not set, the cloneCodec of the CDIConfig is used.",
+							Ref:         ref("kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CloneCodec"),
+						},
+					},
 				},
 				Required: []string{"source", "pvc"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.PersistentVolumeClaimSpec", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeCheckpoint", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSource"},
+			"k8s.io/api/core/v1.PersistentVolumeClaimSpec", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.CloneCodec", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeCheckpoint", "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1.DataVolumeSource"},
 	}
 }
 
@@ -14381,6 +14558,13 @@ func schema_pkg_apis_core_v1alpha1_DataVolumeStatus(ref common.ReferenceCallback
 							},
 						},
 					},
+					"allocatedBytes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AllocatedBytes is the amount of storage allocated on the target volume when it was populated. Zero blocks of the source are not allocated, so this may be smaller than the size of the image. On block volumes it is the amount of data written to them.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
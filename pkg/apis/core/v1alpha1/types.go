@@ -52,6 +52,35 @@ type DataVolumeSpec struct {
 	Checkpoints []DataVolumeCheckpoint `json:"checkpoints,omitempty"`
 	// FinalCheckpoint indicates whether the current DataVolumeCheckpoint is the final checkpoint.
 	FinalCheckpoint bool `json:"finalCheckpoint,omitempty"`
+	// Preallocation controls whether storage for DataVolumes should be allocated in advance.
+	// If not set, the preallocation value of the CDIConfig is used.
+	Preallocation *bool `json:"preallocation,omitempty"`
+	// CloneCodec selects the compression of the stream of a host-assisted clone.
+	// If not set, the cloneCodec of the CDIConfig is used.
+	// +optional
+	CloneCodec *CloneCodec `json:"cloneCodec,omitempty"`
+}
+
+// CloneCodecName is the name of a codec compressing the stream of a host-assisted clone
+type CloneCodecName string
+
+const (
+	// CloneCodecNone sends the clone stream uncompressed
+	CloneCodecNone CloneCodecName = "none"
+	// CloneCodecSnappy compresses the clone stream with snappy
+	CloneCodecSnappy CloneCodecName = "snappy"
+	// CloneCodecZstd compresses the clone stream with zstd
+	CloneCodecZstd CloneCodecName = "zstd"
+)
+
+// CloneCodec selects the compression of the stream of a host-assisted clone
+type CloneCodec struct {
+	// Name of the codec, "none", "snappy" or "zstd"
+	// +kubebuilder:validation:Enum="none";"snappy";"zstd"
+	Name CloneCodecName `json:"name"`
+	// Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3
+	// +optional
+	Level *int32 `json:"level,omitempty"`
 }
 
 // DataVolumeCheckpoint defines a stage in a warm migration.
@@ -97,6 +126,9 @@ type DataVolumeBlankImage struct{}
 
 // DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source
 type DataVolumeSourceUpload struct {
+	// Checksum is the expected checksum of the uploaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
 }
 
 // DataVolumeSourceS3 provides the parameters to create a Data Volume from an S3 source
@@ -105,6 +137,16 @@ type DataVolumeSourceS3 struct {
 	URL string `json:"url"`
 	//SecretRef provides the secret reference needed to access the S3 source
 	SecretRef string `json:"secretRef,omitempty"`
+	// Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>.
+	// With an OVA, it is the checksum of the imported disk file.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+	// OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import
+	// +optional
+	OVA *DataVolumeSourceOVA `json:"ova,omitempty"`
+	// Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.
+	// +optional
+	Concurrency *int32 `json:"concurrency,omitempty"`
 }
 
 // DataVolumeSourceRegistry provides the parameters to create a Data Volume from an registry source
@@ -115,6 +157,9 @@ type DataVolumeSourceRegistry struct {
 	SecretRef string `json:"secretRef,omitempty"`
 	//CertConfigMap provides a reference to the Registry certs
 	CertConfigMap string `json:"certConfigMap,omitempty"`
+	// Checksum is the expected checksum of the disk image file in the container image, in the <algorithm>:<hex digest> format
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
 }
 
 // DataVolumeSourceHTTP can be either an http or https endpoint, with an optional basic auth user name and password, and an optional configmap containing additional CAs
@@ -127,6 +172,24 @@ type DataVolumeSourceHTTP struct {
 	// CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate
 	// +optional
 	CertConfigMap string `json:"certConfigMap,omitempty"`
+	// Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>.
+	// With an OVA, it is the checksum of the imported disk file.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+	// OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import
+	// +optional
+	OVA *DataVolumeSourceOVA `json:"ova,omitempty"`
+	// Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.
+	// +optional
+	Concurrency *int32 `json:"concurrency,omitempty"`
+}
+
+// DataVolumeSourceOVA selects the disk to import from an OVA archive
+type DataVolumeSourceOVA struct {
+	// Disk is either the index of the disk in the DiskSection of the OVF descriptor, starting at 0, or the href of
+	// the disk file in its References section. Defaults to the first disk.
+	// +optional
+	Disk string `json:"disk,omitempty"`
 }
 
 // DataVolumeSourceImageIO provides the parameters to create a Data Volume from an imageio source
@@ -163,6 +226,11 @@ type DataVolumeStatus struct {
 	// RestartCount is the number of times the pod populating the DataVolume has restarted
 	RestartCount int32                 `json:"restartCount,omitempty"`
 	Conditions   []DataVolumeCondition `json:"conditions,omitempty" optional:"true"`
+	// AllocatedBytes is the amount of storage allocated on the target volume when it was populated.
+	// Zero blocks of the source are not allocated, so this may be smaller than the size of the image.
+	// On block volumes it is the amount of data written to them.
+	// +optional
+	AllocatedBytes *int64 `json:"allocatedBytes,omitempty"`
 }
 
 //DataVolumeList provides the needed parameters to do request a list of Data Volumes from the system
@@ -378,6 +446,14 @@ type CDIConfigSpec struct {
 	PodResourceRequirements *corev1.ResourceRequirements `json:"podResourceRequirements,omitempty"`
 	// FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A value is between 0 and 1, if not defined it is 0.055 (5.5% overhead)
 	FilesystemOverhead *FilesystemOverhead `json:"filesystemOverhead,omitempty"`
+	// Preallocation controls whether storage for DataVolumes should be allocated in advance, unless the DataVolume overrides it.
+	Preallocation bool `json:"preallocation,omitempty"`
+	// MaxDownloadConcurrency caps the number of parallel ranged requests a DataVolume may use to download an http or S3 source, if not defined it is 4
+	MaxDownloadConcurrency *int32 `json:"maxDownloadConcurrency,omitempty"`
+	// CloneCodec selects the compression of the stream of host-assisted clones, unless the DataVolume overrides it. If not defined it is snappy
+	CloneCodec *CloneCodec `json:"cloneCodec,omitempty"`
+	// UploadTokenMaxExpirationSeconds caps the lifetime clients may request for upload tokens, if not defined it is 3600, and it is at most 86400
+	UploadTokenMaxExpirationSeconds *int64 `json:"uploadTokenMaxExpirationSeconds,omitempty"`
 }
 
 //CDIConfigStatus provides the most recently observed status of the CDI Config resource
@@ -390,6 +466,14 @@ type CDIConfigStatus struct {
 	DefaultPodResourceRequirements *corev1.ResourceRequirements `json:"defaultPodResourceRequirements,omitempty"`
 	// FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A percentage value is between 0 and 1
 	FilesystemOverhead *FilesystemOverhead `json:"filesystemOverhead,omitempty"`
+	// Preallocation controls whether storage for DataVolumes should be allocated in advance.
+	Preallocation bool `json:"preallocation,omitempty"`
+	// MaxDownloadConcurrency is the maximum number of parallel ranged requests a DataVolume may use to download an http or S3 source
+	MaxDownloadConcurrency int32 `json:"maxDownloadConcurrency,omitempty"`
+	// CloneCodec is the compression of the stream of host-assisted clones
+	CloneCodec *CloneCodec `json:"cloneCodec,omitempty"`
+	// UploadTokenMaxExpirationSeconds is the maximum lifetime of upload tokens
+	UploadTokenMaxExpirationSeconds int64 `json:"uploadTokenMaxExpirationSeconds,omitempty"`
 }
 
 //CDIConfigList provides the needed parameters to do request a list of CDIConfigs from the system
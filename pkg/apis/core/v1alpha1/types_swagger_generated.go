@@ -16,6 +16,16 @@ func (DataVolumeSpec) SwaggerDoc() map[string]string {
 		"contentType":     "DataVolumeContentType options: \"kubevirt\", \"archive\"\n+kubebuilder:validation:Enum=\"kubevirt\";\"archive\"",
 		"checkpoints":     "Checkpoints is a list of DataVolumeCheckpoints, representing stages in a multistage import.",
 		"finalCheckpoint": "FinalCheckpoint indicates whether the current DataVolumeCheckpoint is the final checkpoint.",
+		"preallocation":   "Preallocation controls whether storage for DataVolumes should be allocated in advance.\nIf not set, the preallocation value of the CDIConfig is used.",
+		"cloneCodec":      "CloneCodec selects the compression of the stream of a host-assisted clone.\nIf not set, the cloneCodec of the CDIConfig is used.\n+optional",
+	}
+}
+
+func (CloneCodec) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":      "CloneCodec selects the compression of the stream of a host-assisted clone",
+		"name":  "Name of the codec, \"none\", \"snappy\" or \"zstd\"\n+kubebuilder:validation:Enum=\"none\";\"snappy\";\"zstd\"",
+		"level": "Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3\n+optional",
 	}
 }
 
@@ -49,15 +59,19 @@ func (DataVolumeBlankImage) SwaggerDoc() map[string]string {
 
 func (DataVolumeSourceUpload) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"": "DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source",
+		"":         "DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source",
+		"checksum": "Checksum is the expected checksum of the uploaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>\n+optional",
 	}
 }
 
 func (DataVolumeSourceS3) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":          "DataVolumeSourceS3 provides the parameters to create a Data Volume from an S3 source",
-		"url":       "URL is the url of the S3 source",
-		"secretRef": "SecretRef provides the secret reference needed to access the S3 source",
+		"":            "DataVolumeSourceS3 provides the parameters to create a Data Volume from an S3 source",
+		"url":         "URL is the url of the S3 source",
+		"secretRef":   "SecretRef provides the secret reference needed to access the S3 source",
+		"checksum":    "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>.\nWith an OVA, it is the checksum of the imported disk file.\n+optional",
+		"ova":         "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import\n+optional",
+		"concurrency": "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.\n+optional",
 	}
 }
 
@@ -67,6 +81,7 @@ func (DataVolumeSourceRegistry) SwaggerDoc() map[string]string {
 		"url":           "URL is the url of the Docker registry source",
 		"secretRef":     "SecretRef provides the secret reference needed to access the Registry source",
 		"certConfigMap": "CertConfigMap provides a reference to the Registry certs",
+		"checksum":      "Checksum is the expected checksum of the disk image file in the container image, in the <algorithm>:<hex digest> format\n+optional",
 	}
 }
 
@@ -76,6 +91,16 @@ func (DataVolumeSourceHTTP) SwaggerDoc() map[string]string {
 		"url":           "URL is the URL of the http(s) endpoint",
 		"secretRef":     "SecretRef A Secret reference, the secret should contain accessKeyId (user name) base64 encoded, and secretKey (password) also base64 encoded\n+optional",
 		"certConfigMap": "CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate\n+optional",
+		"checksum":      "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>.\nWith an OVA, it is the checksum of the imported disk file.\n+optional",
+		"ova":           "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import\n+optional",
+		"concurrency":   "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.\n+optional",
+	}
+}
+
+func (DataVolumeSourceOVA) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":     "DataVolumeSourceOVA selects the disk to import from an OVA archive",
+		"disk": "Disk is either the index of the disk in the DiskSection of the OVF descriptor, starting at 0, or the href of\nthe disk file in its References section. Defaults to the first disk.\n+optional",
 	}
 }
 
@@ -102,9 +127,10 @@ func (DataVolumeSourceVDDK) SwaggerDoc() map[string]string {
 
 func (DataVolumeStatus) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":             "DataVolumeStatus contains the current status of the DataVolume",
-		"phase":        "Phase is the current phase of the data volume",
-		"restartCount": "RestartCount is the number of times the pod populating the DataVolume has restarted",
+		"":               "DataVolumeStatus contains the current status of the DataVolume",
+		"phase":          "Phase is the current phase of the data volume",
+		"restartCount":   "RestartCount is the number of times the pod populating the DataVolume has restarted",
+		"allocatedBytes": "AllocatedBytes is the amount of storage allocated on the target volume when it was populated.\nZero blocks of the source are not allocated, so this may be smaller than the size of the image.\nOn block volumes it is the amount of data written to them.\n+optional",
 	}
 }
 
@@ -168,21 +194,29 @@ func (FilesystemOverhead) SwaggerDoc() map[string]string {
 
 func (CDIConfigSpec) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":                         "CDIConfigSpec defines specification for user configuration",
-		"uploadProxyURLOverride":   "Override the URL used when uploading to a DataVolume",
-		"scratchSpaceStorageClass": "Override the storage class to used for scratch space during transfer operations. The scratch space storage class is determined in the following order: 1. value of scratchSpaceStorageClass, if that doesn't exist, use the default storage class, if there is no default storage class, use the storage class of the DataVolume, if no storage class specified, use no storage class for scratch space",
-		"podResourceRequirements":  "ResourceRequirements describes the compute resource requirements.",
-		"filesystemOverhead":       "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A value is between 0 and 1, if not defined it is 0.055 (5.5% overhead)",
+		"":                                "CDIConfigSpec defines specification for user configuration",
+		"uploadProxyURLOverride":          "Override the URL used when uploading to a DataVolume",
+		"scratchSpaceStorageClass":        "Override the storage class to used for scratch space during transfer operations. The scratch space storage class is determined in the following order: 1. value of scratchSpaceStorageClass, if that doesn't exist, use the default storage class, if there is no default storage class, use the storage class of the DataVolume, if no storage class specified, use no storage class for scratch space",
+		"podResourceRequirements":         "ResourceRequirements describes the compute resource requirements.",
+		"filesystemOverhead":              "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A value is between 0 and 1, if not defined it is 0.055 (5.5% overhead)",
+		"preallocation":                   "Preallocation controls whether storage for DataVolumes should be allocated in advance, unless the DataVolume overrides it.",
+		"maxDownloadConcurrency":          "MaxDownloadConcurrency caps the number of parallel ranged requests a DataVolume may use to download an http or S3 source, if not defined it is 4",
+		"cloneCodec":                      "CloneCodec selects the compression of the stream of host-assisted clones, unless the DataVolume overrides it. If not defined it is snappy",
+		"uploadTokenMaxExpirationSeconds": "UploadTokenMaxExpirationSeconds caps the lifetime clients may request for upload tokens, if not defined it is 3600, and it is at most 86400",
 	}
 }
 
 func (CDIConfigStatus) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":                               "CDIConfigStatus provides the most recently observed status of the CDI Config resource",
-		"uploadProxyURL":                 "The calculated upload proxy URL",
-		"scratchSpaceStorageClass":       "The calculated storage class to be used for scratch space",
-		"defaultPodResourceRequirements": "ResourceRequirements describes the compute resource requirements.",
-		"filesystemOverhead":             "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A percentage value is between 0 and 1",
+		"":                                "CDIConfigStatus provides the most recently observed status of the CDI Config resource",
+		"uploadProxyURL":                  "The calculated upload proxy URL",
+		"scratchSpaceStorageClass":        "The calculated storage class to be used for scratch space",
+		"defaultPodResourceRequirements":  "ResourceRequirements describes the compute resource requirements.",
+		"filesystemOverhead":              "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A percentage value is between 0 and 1",
+		"preallocation":                   "Preallocation controls whether storage for DataVolumes should be allocated in advance.",
+		"maxDownloadConcurrency":          "MaxDownloadConcurrency is the maximum number of parallel ranged requests a DataVolume may use to download an http or S3 source",
+		"cloneCodec":                      "CloneCodec is the compression of the stream of host-assisted clones",
+		"uploadTokenMaxExpirationSeconds": "UploadTokenMaxExpirationSeconds is the maximum lifetime of upload tokens",
 	}
 }
 
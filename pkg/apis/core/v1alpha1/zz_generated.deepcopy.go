@@ -137,6 +137,21 @@ func (in *CDIConfigSpec) DeepCopyInto(out *CDIConfigSpec) {
 		*out = new(FilesystemOverhead)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MaxDownloadConcurrency != nil {
+		in, out := &in.MaxDownloadConcurrency, &out.MaxDownloadConcurrency
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CloneCodec != nil {
+		in, out := &in.CloneCodec, &out.CloneCodec
+		*out = new(CloneCodec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UploadTokenMaxExpirationSeconds != nil {
+		in, out := &in.UploadTokenMaxExpirationSeconds, &out.UploadTokenMaxExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -168,6 +183,11 @@ func (in *CDIConfigStatus) DeepCopyInto(out *CDIConfigStatus) {
 		*out = new(FilesystemOverhead)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CloneCodec != nil {
+		in, out := &in.CloneCodec, &out.CloneCodec
+		*out = new(CloneCodec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -259,6 +279,27 @@ func (in *CDIStatus) DeepCopy() *CDIStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneCodec) DeepCopyInto(out *CloneCodec) {
+	*out = *in
+	if in.Level != nil {
+		in, out := &in.Level, &out.Level
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneCodec.
+func (in *CloneCodec) DeepCopy() *CloneCodec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneCodec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolume) DeepCopyInto(out *DataVolume) {
 	*out = *in
@@ -376,12 +417,12 @@ func (in *DataVolumeSource) DeepCopyInto(out *DataVolumeSource) {
 	if in.HTTP != nil {
 		in, out := &in.HTTP, &out.HTTP
 		*out = new(DataVolumeSourceHTTP)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.S3 != nil {
 		in, out := &in.S3, &out.S3
 		*out = new(DataVolumeSourceS3)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Registry != nil {
 		in, out := &in.Registry, &out.Registry
@@ -429,6 +470,16 @@ func (in *DataVolumeSource) DeepCopy() *DataVolumeSource {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeSourceHTTP) DeepCopyInto(out *DataVolumeSourceHTTP) {
 	*out = *in
+	if in.OVA != nil {
+		in, out := &in.OVA, &out.OVA
+		*out = new(DataVolumeSourceOVA)
+		**out = **in
+	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -458,6 +509,22 @@ func (in *DataVolumeSourceImageIO) DeepCopy() *DataVolumeSourceImageIO {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceOVA) DeepCopyInto(out *DataVolumeSourceOVA) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceOVA.
+func (in *DataVolumeSourceOVA) DeepCopy() *DataVolumeSourceOVA {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceOVA)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeSourcePVC) DeepCopyInto(out *DataVolumeSourcePVC) {
 	*out = *in
@@ -493,6 +560,16 @@ func (in *DataVolumeSourceRegistry) DeepCopy() *DataVolumeSourceRegistry {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeSourceS3) DeepCopyInto(out *DataVolumeSourceS3) {
 	*out = *in
+	if in.OVA != nil {
+		in, out := &in.OVA, &out.OVA
+		*out = new(DataVolumeSourceOVA)
+		**out = **in
+	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -552,6 +629,16 @@ func (in *DataVolumeSpec) DeepCopyInto(out *DataVolumeSpec) {
 		*out = make([]DataVolumeCheckpoint, len(*in))
 		copy(*out, *in)
 	}
+	if in.Preallocation != nil {
+		in, out := &in.Preallocation, &out.Preallocation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CloneCodec != nil {
+		in, out := &in.CloneCodec, &out.CloneCodec
+		*out = new(CloneCodec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -575,6 +662,11 @@ func (in *DataVolumeStatus) DeepCopyInto(out *DataVolumeStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AllocatedBytes != nil {
+		in, out := &in.AllocatedBytes, &out.AllocatedBytes
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -36,6 +36,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&DataVolume{},
 		&DataVolumeList{},
+		&DataSource{},
+		&DataSourceList{},
+		&DataImportCron{},
+		&DataImportCronList{},
+		&VolumeExport{},
+		&VolumeExportList{},
+		&StorageProfile{},
+		&StorageProfileList{},
 		&CDIConfig{},
 		&CDIConfigList{},
 		&CDI{},
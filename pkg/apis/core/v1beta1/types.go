@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 )
@@ -43,9 +44,18 @@ type DataVolume struct {
 // DataVolumeSpec defines the DataVolume type specification
 type DataVolumeSpec struct {
 	//Source is the src of the data for the requested DataVolume
+	// +optional
 	Source DataVolumeSource `json:"source"`
+	//SourceRef is an indirect reference to the source of data for the requested DataVolume
+	// +optional
+	SourceRef *DataVolumeSourceRef `json:"sourceRef,omitempty"`
 	//PVC is the PVC specification
-	PVC *corev1.PersistentVolumeClaimSpec `json:"pvc"`
+	// +optional
+	PVC *corev1.PersistentVolumeClaimSpec `json:"pvc,omitempty"`
+	// Storage is the requirements to be used for the PVC, an alternative to PVC where the size may be left out
+	// to be inferred from the source
+	// +optional
+	Storage *StorageSpec `json:"storage,omitempty"`
 	//DataVolumeContentType options: "kubevirt", "archive"
 	// +kubebuilder:validation:Enum="kubevirt";"archive"
 	ContentType DataVolumeContentType `json:"contentType,omitempty"`
@@ -53,6 +63,62 @@ type DataVolumeSpec struct {
 	Checkpoints []DataVolumeCheckpoint `json:"checkpoints,omitempty"`
 	// FinalCheckpoint indicates whether the current DataVolumeCheckpoint is the final checkpoint.
 	FinalCheckpoint bool `json:"finalCheckpoint,omitempty"`
+	// Preallocation controls whether storage for DataVolumes should be allocated in advance.
+	// If not set, the preallocation value of the CDIConfig is used.
+	Preallocation *bool `json:"preallocation,omitempty"`
+	// CloneCodec selects the compression of the stream of a host-assisted clone.
+	// If not set, the cloneCodec of the CDIConfig is used.
+	// +optional
+	CloneCodec *CloneCodec `json:"cloneCodec,omitempty"`
+}
+
+// CloneCodecName is the name of a codec compressing the stream of a host-assisted clone
+type CloneCodecName string
+
+const (
+	// CloneCodecNone sends the clone stream uncompressed
+	CloneCodecNone CloneCodecName = "none"
+	// CloneCodecSnappy compresses the clone stream with snappy
+	CloneCodecSnappy CloneCodecName = "snappy"
+	// CloneCodecZstd compresses the clone stream with zstd
+	CloneCodecZstd CloneCodecName = "zstd"
+)
+
+// CloneCodec selects the compression of the stream of a host-assisted clone
+type CloneCodec struct {
+	// Name of the codec, "none", "snappy" or "zstd"
+	// +kubebuilder:validation:Enum="none";"snappy";"zstd"
+	Name CloneCodecName `json:"name"`
+	// Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3
+	// +optional
+	Level *int32 `json:"level,omitempty"`
+}
+
+// StorageSpec defines the storage requirements of the PVC of a DataVolume. When the storage size is not requested,
+// it is inferred from the virtual size of an http or S3 source image, or the size of the source PVC of a clone.
+type StorageSpec struct {
+	// AccessModes contains the desired access modes the volume should have.
+	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#access-modes-1
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// A label query over volumes to consider for binding.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Resources represents the minimum resources the volume should have.
+	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#resources
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// VolumeName is the binding reference to the PersistentVolume backing this claim.
+	// +optional
+	VolumeName string `json:"volumeName,omitempty"`
+	// Name of the StorageClass required by the claim.
+	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#class-1
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// volumeMode defines what type of volume is required by the claim.
+	// Value of Filesystem is implied when not included in claim spec.
+	// +optional
+	VolumeMode *corev1.PersistentVolumeMode `json:"volumeMode,omitempty"`
 }
 
 // DataVolumeCheckpoint defines a stage in a warm migration.
@@ -83,6 +149,7 @@ type DataVolumeSource struct {
 	Blank    *DataVolumeBlankImage     `json:"blank,omitempty"`
 	Imageio  *DataVolumeSourceImageIO  `json:"imageio,omitempty"`
 	VDDK     *DataVolumeSourceVDDK     `json:"vddk,omitempty"`
+	Snapshot *DataVolumeSourceSnapshot `json:"snapshot,omitempty"`
 }
 
 // DataVolumeSourcePVC provides the parameters to create a Data Volume from an existing PVC
@@ -93,11 +160,38 @@ type DataVolumeSourcePVC struct {
 	Name string `json:"name"`
 }
 
+// DataVolumeSourceSnapshot provides the parameters to create a Data Volume from an existing VolumeSnapshot
+type DataVolumeSourceSnapshot struct {
+	// The namespace of the source VolumeSnapshot
+	Namespace string `json:"namespace"`
+	// The name of the source VolumeSnapshot
+	Name string `json:"name"`
+}
+
+// DataVolumeSourceRef defines an indirect reference to the source of data for the DataVolume
+type DataVolumeSourceRef struct {
+	// The kind of the source reference, currently only "DataSource" is supported
+	Kind string `json:"kind"`
+	// The namespace of the source reference, defaults to the DataVolume namespace
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+	// The name of the source reference
+	Name string `json:"name"`
+}
+
+const (
+	// DataVolumeDataSource is DataSource source reference for DataVolume
+	DataVolumeDataSource = "DataSource"
+)
+
 // DataVolumeBlankImage provides the parameters to create a new raw blank image for the PVC
 type DataVolumeBlankImage struct{}
 
 // DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source
 type DataVolumeSourceUpload struct {
+	// Checksum is the expected checksum of the uploaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
 }
 
 // DataVolumeSourceS3 provides the parameters to create a Data Volume from an S3 source
@@ -106,6 +200,16 @@ type DataVolumeSourceS3 struct {
 	URL string `json:"url"`
 	//SecretRef provides the secret reference needed to access the S3 source
 	SecretRef string `json:"secretRef,omitempty"`
+	// Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>.
+	// With an OVA, it is the checksum of the imported disk file.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+	// OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import
+	// +optional
+	OVA *DataVolumeSourceOVA `json:"ova,omitempty"`
+	// Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.
+	// +optional
+	Concurrency *int32 `json:"concurrency,omitempty"`
 }
 
 // DataVolumeSourceRegistry provides the parameters to create a Data Volume from an registry source
@@ -116,6 +220,9 @@ type DataVolumeSourceRegistry struct {
 	SecretRef string `json:"secretRef,omitempty"`
 	//CertConfigMap provides a reference to the Registry certs
 	CertConfigMap string `json:"certConfigMap,omitempty"`
+	// Checksum is the expected checksum of the disk image file in the container image, in the <algorithm>:<hex digest> format
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
 }
 
 // DataVolumeSourceHTTP can be either an http or https endpoint, with an optional basic auth user name and password, and an optional configmap containing additional CAs
@@ -128,6 +235,24 @@ type DataVolumeSourceHTTP struct {
 	// CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate
 	// +optional
 	CertConfigMap string `json:"certConfigMap,omitempty"`
+	// Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>.
+	// With an OVA, it is the checksum of the imported disk file.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+	// OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import
+	// +optional
+	OVA *DataVolumeSourceOVA `json:"ova,omitempty"`
+	// Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.
+	// +optional
+	Concurrency *int32 `json:"concurrency,omitempty"`
+}
+
+// DataVolumeSourceOVA selects the disk to import from an OVA archive
+type DataVolumeSourceOVA struct {
+	// Disk is either the index of the disk in the DiskSection of the OVF descriptor, starting at 0, or the href of
+	// the disk file in its References section. Defaults to the first disk.
+	// +optional
+	Disk string `json:"disk,omitempty"`
 }
 
 // DataVolumeSourceImageIO provides the parameters to create a Data Volume from an imageio source
@@ -164,6 +289,15 @@ type DataVolumeStatus struct {
 	// RestartCount is the number of times the pod populating the DataVolume has restarted
 	RestartCount int32                 `json:"restartCount,omitempty"`
 	Conditions   []DataVolumeCondition `json:"conditions,omitempty" optional:"true"`
+	// AllocatedBytes is the amount of storage allocated on the target volume when it was populated.
+	// Zero blocks of the source are not allocated, so this may be smaller than the size of the image.
+	// On block volumes it is the amount of data written to them.
+	// +optional
+	AllocatedBytes *int64 `json:"allocatedBytes,omitempty"`
+	// InferredSize is the storage size requested for the PVC when spec.storage doesn't request one, computed from
+	// the size of the source and the filesystem overhead.
+	// +optional
+	InferredSize *resource.Quantity `json:"inferredSize,omitempty"`
 }
 
 //DataVolumeList provides the needed parameters to do request a list of Data Volumes from the system
@@ -262,6 +396,277 @@ const (
 // DataVolumeCloneSourceSubresource is the subresource checked for permission to clone
 const DataVolumeCloneSourceSubresource = "source"
 
+// DataSource references an import/clone source for a DataVolume
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:shortName=das,categories=all
+type DataSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DataSourceSpec   `json:"spec"`
+	Status DataSourceStatus `json:"status,omitempty"`
+}
+
+// DataSourceSpec defines specification for DataSource
+type DataSourceSpec struct {
+	// Source is the source of the data referenced by the DataSource
+	Source DataSourceSource `json:"source"`
+}
+
+// DataSourceSource represents the source for our DataSource
+type DataSourceSource struct {
+	// +optional
+	PVC *DataVolumeSourcePVC `json:"pvc,omitempty"`
+}
+
+// DataSourceStatus provides the most recently observed status of the DataSource
+type DataSourceStatus struct {
+	Conditions []DataSourceCondition `json:"conditions,omitempty" optional:"true"`
+}
+
+// DataSourceCondition represents the state of a data source condition
+type DataSourceCondition struct {
+	Type               DataSourceConditionType `json:"type" description:"type of condition ie. Ready"`
+	Status             corev1.ConditionStatus  `json:"status" description:"status of the condition, one of True, False, Unknown"`
+	LastTransitionTime metav1.Time             `json:"lastTransitionTime,omitempty"`
+	LastHeartbeatTime  metav1.Time             `json:"lastHeartbeatTime,omitempty"`
+	Reason             string                  `json:"reason,omitempty" description:"reason for the condition's last transition"`
+	Message            string                  `json:"message,omitempty" description:"human-readable message indicating details about last transition"`
+}
+
+// DataSourceConditionType is the string representation of known condition types
+type DataSourceConditionType string
+
+const (
+	// DataSourceReady is the condition that indicates if the data source is ready to be consumed
+	DataSourceReady DataSourceConditionType = "Ready"
+)
+
+// DataSourceList provides the needed parameters to do request a list of Data Sources from the system
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	// Items provides a list of DataSources
+	Items []DataSource `json:"items"`
+}
+
+// DataImportCron defines a cron job for recurring polling/importing disk images as PVCs
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:shortName=dic;dics,categories=all
+type DataImportCron struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DataImportCronSpec   `json:"spec"`
+	Status DataImportCronStatus `json:"status,omitempty"`
+}
+
+// DataImportCronSpec defines specification for DataImportCron
+type DataImportCronSpec struct {
+	// Template specifies template for the DVs to be created, its source has to be registry or http
+	Template DataVolume `json:"template"`
+	// Schedule specifies in cron format when and how often to look for new imports
+	Schedule string `json:"schedule"`
+	// GarbageCollect specifies whether old PVCs should be cleaned up after a new PVC is imported.
+	// Options are currently "Outdated" and "Never", defaults to "Outdated".
+	// +optional
+	GarbageCollect *DataImportCronGarbageCollect `json:"garbageCollect,omitempty"`
+	// Number of import PVCs to keep when garbage collecting. Default is 3.
+	// +optional
+	ImportsToKeep *int32 `json:"importsToKeep,omitempty"`
+	// ManagedDataSource specifies the name of the DataSource this cron will point to the last imported PVC.
+	// The DataSource is in the same namespace, and is created if it doesn't exist.
+	// +optional
+	ManagedDataSource string `json:"managedDataSource,omitempty"`
+}
+
+// DataImportCronGarbageCollect represents the DataImportCron garbage collection mode
+type DataImportCronGarbageCollect string
+
+const (
+	// DataImportCronGarbageCollectNever specifies that garbage collection is disabled
+	DataImportCronGarbageCollectNever DataImportCronGarbageCollect = "Never"
+	// DataImportCronGarbageCollectOutdated specifies that old PVCs should be cleaned up after a new PVC is imported
+	DataImportCronGarbageCollectOutdated DataImportCronGarbageCollect = "Outdated"
+)
+
+// DataImportCronStatus provides the most recently observed status of the DataImportCron
+type DataImportCronStatus struct {
+	// CurrentImports are the imports in progress. Currently only a single import is supported.
+	CurrentImports []ImportStatus `json:"currentImports,omitempty"`
+	// LastImportedPVC is the last imported PVC
+	LastImportedPVC *DataVolumeSourcePVC `json:"lastImportedPVC,omitempty"`
+	// LastExecutionTimestamp is the time of the last polling
+	LastExecutionTimestamp *metav1.Time `json:"lastExecutionTimestamp,omitempty"`
+	// LastImportTimestamp is the time of the last import
+	LastImportTimestamp *metav1.Time              `json:"lastImportTimestamp,omitempty"`
+	Conditions          []DataImportCronCondition `json:"conditions,omitempty" optional:"true"`
+}
+
+// ImportStatus of a currently in progress import
+type ImportStatus struct {
+	// DataVolumeName is the currently in progress import DataVolume
+	DataVolumeName string `json:"dataVolumeName"`
+	// Digest of the currently imported image
+	Digest string `json:"digest,omitempty"`
+}
+
+// DataImportCronCondition represents the state of a data import cron condition
+type DataImportCronCondition struct {
+	Type               DataImportCronConditionType `json:"type" description:"type of condition ie. Progressing, UpToDate"`
+	Status             corev1.ConditionStatus      `json:"status" description:"status of the condition, one of True, False, Unknown"`
+	LastTransitionTime metav1.Time                 `json:"lastTransitionTime,omitempty"`
+	LastHeartbeatTime  metav1.Time                 `json:"lastHeartbeatTime,omitempty"`
+	Reason             string                      `json:"reason,omitempty" description:"reason for the condition's last transition"`
+	Message            string                      `json:"message,omitempty" description:"human-readable message indicating details about last transition"`
+}
+
+// DataImportCronConditionType is the string representation of known condition types
+type DataImportCronConditionType string
+
+const (
+	// DataImportCronProgressing is the condition that indicates import is progressing
+	DataImportCronProgressing DataImportCronConditionType = "Progressing"
+
+	// DataImportCronUpToDate is the condition that indicates the last imported PVC matches the last polled source digest
+	DataImportCronUpToDate DataImportCronConditionType = "UpToDate"
+)
+
+// DataImportCronList provides the needed parameters to do request a list of DataImportCrons from the system
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataImportCronList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	// Items provides a list of DataImportCrons
+	Items []DataImportCron `json:"items"`
+}
+
+// VolumeExport makes the data of a PVC or DataVolume available for download through the upload proxy
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:shortName=vex;vexs,categories=all
+type VolumeExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeExportSpec   `json:"spec"`
+	Status VolumeExportStatus `json:"status,omitempty"`
+}
+
+// VolumeExportSpec defines specification for VolumeExport
+type VolumeExportSpec struct {
+	// Source is the PVC or DataVolume to export, in the namespace of the VolumeExport
+	Source VolumeExportSource `json:"source"`
+}
+
+// VolumeExportSource is the PVC or DataVolume exported by a VolumeExport
+type VolumeExportSource struct {
+	// The kind of the source, PersistentVolumeClaim or DataVolume
+	Kind string `json:"kind"`
+	// The name of the source
+	Name string `json:"name"`
+}
+
+const (
+	// VolumeExportSourcePVC is PersistentVolumeClaim source kind for VolumeExport
+	VolumeExportSourcePVC = "PersistentVolumeClaim"
+	// VolumeExportSourceDataVolume is DataVolume source kind for VolumeExport
+	VolumeExportSourceDataVolume = "DataVolume"
+)
+
+// VolumeExportStatus provides the most recently observed status of the VolumeExport
+type VolumeExportStatus struct {
+	// Phase is the current phase of the export
+	Phase VolumeExportPhase `json:"phase,omitempty"`
+}
+
+// VolumeExportPhase is the current phase of the VolumeExport
+type VolumeExportPhase string
+
+const (
+	// VolumeExportPending represents an export waiting for its source or its server
+	VolumeExportPending VolumeExportPhase = "Pending"
+	// VolumeExportReady represents an export that can be downloaded
+	VolumeExportReady VolumeExportPhase = "Ready"
+)
+
+// VolumeExportList provides the needed parameters to do request a list of VolumeExports from the system
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type VolumeExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	// Items provides a list of VolumeExports
+	Items []VolumeExport `json:"items"`
+}
+
+// this has to be here otherwise informer-gen doesn't recognize it
+// see https://github.com/kubernetes/code-generator/issues/59
+// +genclient:nonNamespaced
+
+// StorageProfile provides a CDI specific recommendation for storage parameters
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:scope=Cluster
+type StorageProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StorageProfileSpec   `json:"spec"`
+	Status StorageProfileStatus `json:"status,omitempty"`
+}
+
+// StorageProfileSpec defines specification for StorageProfile
+type StorageProfileSpec struct {
+	// ClaimPropertySets is a provided set of properties applicable to PVC, overriding the recommended ones
+	ClaimPropertySets []ClaimPropertySet `json:"claimPropertySets,omitempty"`
+}
+
+// StorageProfileStatus provides the most recently observed status of the StorageProfile
+type StorageProfileStatus struct {
+	// The StorageClass name for which capabilities are defined
+	StorageClass *string `json:"storageClass,omitempty"`
+	// The Storage class provisioner plugin name
+	Provisioner *string `json:"provisioner,omitempty"`
+	// ClaimPropertySets computed from the spec and detected in the system, the first one is used to fill in a DataVolume
+	ClaimPropertySets []ClaimPropertySet `json:"claimPropertySets,omitempty"`
+}
+
+// ClaimPropertySet is a set of properties applicable to PVC
+type ClaimPropertySet struct {
+	// AccessModes contains the desired access modes the volume should have.
+	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#access-modes-1
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// VolumeMode defines what type of volume is required by the claim.
+	// Value of Filesystem is implied when not included in claim spec.
+	// +optional
+	VolumeMode *corev1.PersistentVolumeMode `json:"volumeMode,omitempty"`
+}
+
+// StorageProfileList provides the needed parameters to request a list of StorageProfile from the system
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type StorageProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	// Items provides a list of StorageProfile
+	Items []StorageProfile `json:"items"`
+}
+
 // this has to be here otherwise informer-gen doesn't recognize it
 // see https://github.com/kubernetes/code-generator/issues/59
 // +genclient:nonNamespaced
@@ -371,6 +776,14 @@ type CDIConfigSpec struct {
 	FeatureGates []string `json:"featureGates,omitempty"`
 	// FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A value is between 0 and 1, if not defined it is 0.055 (5.5% overhead)
 	FilesystemOverhead *FilesystemOverhead `json:"filesystemOverhead,omitempty"`
+	// Preallocation controls whether storage for DataVolumes should be allocated in advance, unless the DataVolume overrides it.
+	Preallocation bool `json:"preallocation,omitempty"`
+	// MaxDownloadConcurrency caps the number of parallel ranged requests a DataVolume may use to download an http or S3 source, if not defined it is 4
+	MaxDownloadConcurrency *int32 `json:"maxDownloadConcurrency,omitempty"`
+	// CloneCodec selects the compression of the stream of host-assisted clones, unless the DataVolume overrides it. If not defined it is snappy
+	CloneCodec *CloneCodec `json:"cloneCodec,omitempty"`
+	// UploadTokenMaxExpirationSeconds caps the lifetime clients may request for upload tokens, if not defined it is 3600, and it is at most 86400
+	UploadTokenMaxExpirationSeconds *int64 `json:"uploadTokenMaxExpirationSeconds,omitempty"`
 }
 
 //CDIConfigStatus provides the most recently observed status of the CDI Config resource
@@ -383,6 +796,14 @@ type CDIConfigStatus struct {
 	DefaultPodResourceRequirements *corev1.ResourceRequirements `json:"defaultPodResourceRequirements,omitempty"`
 	// FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A percentage value is between 0 and 1
 	FilesystemOverhead *FilesystemOverhead `json:"filesystemOverhead,omitempty"`
+	// Preallocation controls whether storage for DataVolumes should be allocated in advance.
+	Preallocation bool `json:"preallocation,omitempty"`
+	// MaxDownloadConcurrency is the maximum number of parallel ranged requests a DataVolume may use to download an http or S3 source
+	MaxDownloadConcurrency int32 `json:"maxDownloadConcurrency,omitempty"`
+	// CloneCodec is the compression of the stream of host-assisted clones
+	CloneCodec *CloneCodec `json:"cloneCodec,omitempty"`
+	// UploadTokenMaxExpirationSeconds is the maximum lifetime of upload tokens
+	UploadTokenMaxExpirationSeconds int64 `json:"uploadTokenMaxExpirationSeconds,omitempty"`
 }
 
 //CDIConfigList provides the needed parameters to do request a list of CDIConfigs from the system
@@ -11,11 +11,35 @@ func (DataVolume) SwaggerDoc() map[string]string {
 func (DataVolumeSpec) SwaggerDoc() map[string]string {
 	return map[string]string{
 		"":                "DataVolumeSpec defines the DataVolume type specification",
-		"source":          "Source is the src of the data for the requested DataVolume",
-		"pvc":             "PVC is the PVC specification",
+		"source":          "Source is the src of the data for the requested DataVolume\n+optional",
+		"sourceRef":       "SourceRef is an indirect reference to the source of data for the requested DataVolume\n+optional",
+		"pvc":             "PVC is the PVC specification\n+optional",
+		"storage":         "Storage is the requirements to be used for the PVC, an alternative to PVC where the size may be left out\nto be inferred from the source\n+optional",
 		"contentType":     "DataVolumeContentType options: \"kubevirt\", \"archive\"\n+kubebuilder:validation:Enum=\"kubevirt\";\"archive\"",
 		"checkpoints":     "Checkpoints is a list of DataVolumeCheckpoints, representing stages in a multistage import.",
 		"finalCheckpoint": "FinalCheckpoint indicates whether the current DataVolumeCheckpoint is the final checkpoint.",
+		"preallocation":   "Preallocation controls whether storage for DataVolumes should be allocated in advance.\nIf not set, the preallocation value of the CDIConfig is used.",
+		"cloneCodec":      "CloneCodec selects the compression of the stream of a host-assisted clone.\nIf not set, the cloneCodec of the CDIConfig is used.\n+optional",
+	}
+}
+
+func (CloneCodec) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":      "CloneCodec selects the compression of the stream of a host-assisted clone",
+		"name":  "Name of the codec, \"none\", \"snappy\" or \"zstd\"\n+kubebuilder:validation:Enum=\"none\";\"snappy\";\"zstd\"",
+		"level": "Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3\n+optional",
+	}
+}
+
+func (StorageSpec) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                 "StorageSpec defines the storage requirements of the PVC of a DataVolume. When the storage size is not requested,\nit is inferred from the virtual size of an http or S3 source image, or the size of the source PVC of a clone.",
+		"accessModes":      "AccessModes contains the desired access modes the volume should have.\nMore info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#access-modes-1\n+optional",
+		"selector":         "A label query over volumes to consider for binding.\n+optional",
+		"resources":        "Resources represents the minimum resources the volume should have.\nMore info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#resources\n+optional",
+		"volumeName":       "VolumeName is the binding reference to the PersistentVolume backing this claim.\n+optional",
+		"storageClassName": "Name of the StorageClass required by the claim.\nMore info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#class-1\n+optional",
+		"volumeMode":       "volumeMode defines what type of volume is required by the claim.\nValue of Filesystem is implied when not included in claim spec.\n+optional",
 	}
 }
 
@@ -41,6 +65,23 @@ func (DataVolumeSourcePVC) SwaggerDoc() map[string]string {
 	}
 }
 
+func (DataVolumeSourceSnapshot) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "DataVolumeSourceSnapshot provides the parameters to create a Data Volume from an existing VolumeSnapshot",
+		"namespace": "The namespace of the source VolumeSnapshot",
+		"name":      "The name of the source VolumeSnapshot",
+	}
+}
+
+func (DataVolumeSourceRef) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "DataVolumeSourceRef defines an indirect reference to the source of data for the DataVolume",
+		"kind":      "The kind of the source reference, currently only \"DataSource\" is supported",
+		"namespace": "The namespace of the source reference, defaults to the DataVolume namespace\n+optional",
+		"name":      "The name of the source reference",
+	}
+}
+
 func (DataVolumeBlankImage) SwaggerDoc() map[string]string {
 	return map[string]string{
 		"": "DataVolumeBlankImage provides the parameters to create a new raw blank image for the PVC",
@@ -49,15 +90,19 @@ func (DataVolumeBlankImage) SwaggerDoc() map[string]string {
 
 func (DataVolumeSourceUpload) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"": "DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source",
+		"":         "DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source",
+		"checksum": "Checksum is the expected checksum of the uploaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>\n+optional",
 	}
 }
 
 func (DataVolumeSourceS3) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":          "DataVolumeSourceS3 provides the parameters to create a Data Volume from an S3 source",
-		"url":       "URL is the url of the S3 source",
-		"secretRef": "SecretRef provides the secret reference needed to access the S3 source",
+		"":            "DataVolumeSourceS3 provides the parameters to create a Data Volume from an S3 source",
+		"url":         "URL is the url of the S3 source",
+		"secretRef":   "SecretRef provides the secret reference needed to access the S3 source",
+		"checksum":    "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>.\nWith an OVA, it is the checksum of the imported disk file.\n+optional",
+		"ova":         "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import\n+optional",
+		"concurrency": "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.\n+optional",
 	}
 }
 
@@ -67,6 +112,7 @@ func (DataVolumeSourceRegistry) SwaggerDoc() map[string]string {
 		"url":           "URL is the url of the Docker registry source",
 		"secretRef":     "SecretRef provides the secret reference needed to access the Registry source",
 		"certConfigMap": "CertConfigMap provides a reference to the Registry certs",
+		"checksum":      "Checksum is the expected checksum of the disk image file in the container image, in the <algorithm>:<hex digest> format\n+optional",
 	}
 }
 
@@ -76,6 +122,16 @@ func (DataVolumeSourceHTTP) SwaggerDoc() map[string]string {
 		"url":           "URL is the URL of the http(s) endpoint",
 		"secretRef":     "SecretRef A Secret reference, the secret should contain accessKeyId (user name) base64 encoded, and secretKey (password) also base64 encoded\n+optional",
 		"certConfigMap": "CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate\n+optional",
+		"checksum":      "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>.\nWith an OVA, it is the checksum of the imported disk file.\n+optional",
+		"ova":           "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import\n+optional",
+		"concurrency":   "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.\n+optional",
+	}
+}
+
+func (DataVolumeSourceOVA) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":     "DataVolumeSourceOVA selects the disk to import from an OVA archive",
+		"disk": "Disk is either the index of the disk in the DiskSection of the OVF descriptor, starting at 0, or the href of\nthe disk file in its References section. Defaults to the first disk.\n+optional",
 	}
 }
 
@@ -102,9 +158,11 @@ func (DataVolumeSourceVDDK) SwaggerDoc() map[string]string {
 
 func (DataVolumeStatus) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":             "DataVolumeStatus contains the current status of the DataVolume",
-		"phase":        "Phase is the current phase of the data volume",
-		"restartCount": "RestartCount is the number of times the pod populating the DataVolume has restarted",
+		"":               "DataVolumeStatus contains the current status of the DataVolume",
+		"phase":          "Phase is the current phase of the data volume",
+		"restartCount":   "RestartCount is the number of times the pod populating the DataVolume has restarted",
+		"allocatedBytes": "AllocatedBytes is the amount of storage allocated on the target volume when it was populated.\nZero blocks of the source are not allocated, so this may be smaller than the size of the image.\nOn block volumes it is the amount of data written to them.\n+optional",
+		"inferredSize":   "InferredSize is the storage size requested for the PVC when spec.storage doesn't request one, computed from\nthe size of the source and the filesystem overhead.\n+optional",
 	}
 }
 
@@ -121,6 +179,165 @@ func (DataVolumeCondition) SwaggerDoc() map[string]string {
 	}
 }
 
+func (DataSource) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"": "DataSource references an import/clone source for a DataVolume\n+genclient\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object\n+kubebuilder:object:root=true\n+kubebuilder:storageversion\n+kubebuilder:resource:shortName=das,categories=all",
+	}
+}
+
+func (DataSourceSpec) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":       "DataSourceSpec defines specification for DataSource",
+		"source": "Source is the source of the data referenced by the DataSource",
+	}
+}
+
+func (DataSourceSource) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":    "DataSourceSource represents the source for our DataSource",
+		"pvc": "+optional",
+	}
+}
+
+func (DataSourceStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"": "DataSourceStatus provides the most recently observed status of the DataSource",
+	}
+}
+
+func (DataSourceCondition) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"": "DataSourceCondition represents the state of a data source condition",
+	}
+}
+
+func (DataSourceList) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":      "DataSourceList provides the needed parameters to do request a list of Data Sources from the system\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object",
+		"items": "Items provides a list of DataSources",
+	}
+}
+
+func (DataImportCron) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"": "DataImportCron defines a cron job for recurring polling/importing disk images as PVCs\n+genclient\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object\n+kubebuilder:object:root=true\n+kubebuilder:storageversion\n+kubebuilder:resource:shortName=dic;dics,categories=all",
+	}
+}
+
+func (DataImportCronSpec) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                  "DataImportCronSpec defines specification for DataImportCron",
+		"template":          "Template specifies template for the DVs to be created, its source has to be registry or http",
+		"schedule":          "Schedule specifies in cron format when and how often to look for new imports",
+		"garbageCollect":    "GarbageCollect specifies whether old PVCs should be cleaned up after a new PVC is imported.\nOptions are currently \"Outdated\" and \"Never\", defaults to \"Outdated\".\n+optional",
+		"importsToKeep":     "Number of import PVCs to keep when garbage collecting. Default is 3.\n+optional",
+		"managedDataSource": "ManagedDataSource specifies the name of the DataSource this cron will point to the last imported PVC.\nThe DataSource is in the same namespace, and is created if it doesn't exist.\n+optional",
+	}
+}
+
+func (DataImportCronStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                       "DataImportCronStatus provides the most recently observed status of the DataImportCron",
+		"currentImports":         "CurrentImports are the imports in progress. Currently only a single import is supported.",
+		"lastImportedPVC":        "LastImportedPVC is the last imported PVC",
+		"lastExecutionTimestamp": "LastExecutionTimestamp is the time of the last polling",
+		"lastImportTimestamp":    "LastImportTimestamp is the time of the last import",
+	}
+}
+
+func (ImportStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":               "ImportStatus of a currently in progress import",
+		"dataVolumeName": "DataVolumeName is the currently in progress import DataVolume",
+		"digest":         "Digest of the currently imported image",
+	}
+}
+
+func (DataImportCronCondition) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"": "DataImportCronCondition represents the state of a data import cron condition",
+	}
+}
+
+func (DataImportCronList) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":      "DataImportCronList provides the needed parameters to do request a list of DataImportCrons from the system\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object",
+		"items": "Items provides a list of DataImportCrons",
+	}
+}
+
+func (VolumeExport) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"": "VolumeExport makes the data of a PVC or DataVolume available for download through the upload proxy\n+genclient\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object\n+kubebuilder:object:root=true\n+kubebuilder:storageversion\n+kubebuilder:resource:shortName=vex;vexs,categories=all",
+	}
+}
+
+func (VolumeExportSpec) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":       "VolumeExportSpec defines specification for VolumeExport",
+		"source": "Source is the PVC or DataVolume to export, in the namespace of the VolumeExport",
+	}
+}
+
+func (VolumeExportSource) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":     "VolumeExportSource is the PVC or DataVolume exported by a VolumeExport",
+		"kind": "The kind of the source, PersistentVolumeClaim or DataVolume",
+		"name": "The name of the source",
+	}
+}
+
+func (VolumeExportStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":      "VolumeExportStatus provides the most recently observed status of the VolumeExport",
+		"phase": "Phase is the current phase of the export",
+	}
+}
+
+func (VolumeExportList) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":      "VolumeExportList provides the needed parameters to do request a list of VolumeExports from the system\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object",
+		"items": "Items provides a list of VolumeExports",
+	}
+}
+
+func (StorageProfile) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"": "StorageProfile provides a CDI specific recommendation for storage parameters\n+genclient\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object\n+kubebuilder:object:root=true\n+kubebuilder:storageversion\n+kubebuilder:resource:scope=Cluster",
+	}
+}
+
+func (StorageProfileSpec) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                  "StorageProfileSpec defines specification for StorageProfile",
+		"claimPropertySets": "ClaimPropertySets is a provided set of properties applicable to PVC, overriding the recommended ones",
+	}
+}
+
+func (StorageProfileStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                  "StorageProfileStatus provides the most recently observed status of the StorageProfile",
+		"storageClass":      "The StorageClass name for which capabilities are defined",
+		"provisioner":       "The Storage class provisioner plugin name",
+		"claimPropertySets": "ClaimPropertySets computed from the spec and detected in the system, the first one is used to fill in a DataVolume",
+	}
+}
+
+func (ClaimPropertySet) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":            "ClaimPropertySet is a set of properties applicable to PVC",
+		"accessModes": "AccessModes contains the desired access modes the volume should have.\nMore info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#access-modes-1\n+optional",
+		"volumeMode":  "VolumeMode defines what type of volume is required by the claim.\nValue of Filesystem is implied when not included in claim spec.\n+optional",
+	}
+}
+
+func (StorageProfileList) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":      "StorageProfileList provides the needed parameters to request a list of StorageProfile from the system\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object",
+		"items": "Items provides a list of StorageProfile",
+	}
+}
+
 func (CDI) SwaggerDoc() map[string]string {
 	return map[string]string{
 		"":       "CDI is the CDI Operator CRD\n+genclient\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object\n+kubebuilder:object:root=true\n+kubebuilder:storageversion\n+kubebuilder:resource:shortName=cdi;cdis,scope=Cluster\n+kubebuilder:printcolumn:name=\"Age\",type=\"date\",JSONPath=\".metadata.creationTimestamp\"\n+kubebuilder:printcolumn:name=\"Phase\",type=\"string\",JSONPath=\".status.phase\"",
@@ -168,22 +385,30 @@ func (FilesystemOverhead) SwaggerDoc() map[string]string {
 
 func (CDIConfigSpec) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":                         "CDIConfigSpec defines specification for user configuration",
-		"uploadProxyURLOverride":   "Override the URL used when uploading to a DataVolume",
-		"scratchSpaceStorageClass": "Override the storage class to used for scratch space during transfer operations. The scratch space storage class is determined in the following order: 1. value of scratchSpaceStorageClass, if that doesn't exist, use the default storage class, if there is no default storage class, use the storage class of the DataVolume, if no storage class specified, use no storage class for scratch space",
-		"podResourceRequirements":  "ResourceRequirements describes the compute resource requirements.",
-		"featureGates":             "FeatureGates are a list of specific enabled feature gates",
-		"filesystemOverhead":       "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A value is between 0 and 1, if not defined it is 0.055 (5.5% overhead)",
+		"":                                "CDIConfigSpec defines specification for user configuration",
+		"uploadProxyURLOverride":          "Override the URL used when uploading to a DataVolume",
+		"scratchSpaceStorageClass":        "Override the storage class to used for scratch space during transfer operations. The scratch space storage class is determined in the following order: 1. value of scratchSpaceStorageClass, if that doesn't exist, use the default storage class, if there is no default storage class, use the storage class of the DataVolume, if no storage class specified, use no storage class for scratch space",
+		"podResourceRequirements":         "ResourceRequirements describes the compute resource requirements.",
+		"featureGates":                    "FeatureGates are a list of specific enabled feature gates",
+		"filesystemOverhead":              "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A value is between 0 and 1, if not defined it is 0.055 (5.5% overhead)",
+		"preallocation":                   "Preallocation controls whether storage for DataVolumes should be allocated in advance, unless the DataVolume overrides it.",
+		"maxDownloadConcurrency":          "MaxDownloadConcurrency caps the number of parallel ranged requests a DataVolume may use to download an http or S3 source, if not defined it is 4",
+		"cloneCodec":                      "CloneCodec selects the compression of the stream of host-assisted clones, unless the DataVolume overrides it. If not defined it is snappy",
+		"uploadTokenMaxExpirationSeconds": "UploadTokenMaxExpirationSeconds caps the lifetime clients may request for upload tokens, if not defined it is 3600, and it is at most 86400",
 	}
 }
 
 func (CDIConfigStatus) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":                               "CDIConfigStatus provides the most recently observed status of the CDI Config resource",
-		"uploadProxyURL":                 "The calculated upload proxy URL",
-		"scratchSpaceStorageClass":       "The calculated storage class to be used for scratch space",
-		"defaultPodResourceRequirements": "ResourceRequirements describes the compute resource requirements.",
-		"filesystemOverhead":             "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A percentage value is between 0 and 1",
+		"":                                "CDIConfigStatus provides the most recently observed status of the CDI Config resource",
+		"uploadProxyURL":                  "The calculated upload proxy URL",
+		"scratchSpaceStorageClass":        "The calculated storage class to be used for scratch space",
+		"defaultPodResourceRequirements":  "ResourceRequirements describes the compute resource requirements.",
+		"filesystemOverhead":              "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A percentage value is between 0 and 1",
+		"preallocation":                   "Preallocation controls whether storage for DataVolumes should be allocated in advance.",
+		"maxDownloadConcurrency":          "MaxDownloadConcurrency is the maximum number of parallel ranged requests a DataVolume may use to download an http or S3 source",
+		"cloneCodec":                      "CloneCodec is the compression of the stream of host-assisted clones",
+		"uploadTokenMaxExpirationSeconds": "UploadTokenMaxExpirationSeconds is the maximum lifetime of upload tokens",
 	}
 }
 
@@ -22,6 +22,7 @@ package v1beta1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -142,6 +143,21 @@ func (in *CDIConfigSpec) DeepCopyInto(out *CDIConfigSpec) {
 		*out = new(FilesystemOverhead)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MaxDownloadConcurrency != nil {
+		in, out := &in.MaxDownloadConcurrency, &out.MaxDownloadConcurrency
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CloneCodec != nil {
+		in, out := &in.CloneCodec, &out.CloneCodec
+		*out = new(CloneCodec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UploadTokenMaxExpirationSeconds != nil {
+		in, out := &in.UploadTokenMaxExpirationSeconds, &out.UploadTokenMaxExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -173,6 +189,11 @@ func (in *CDIConfigStatus) DeepCopyInto(out *CDIConfigStatus) {
 		*out = new(FilesystemOverhead)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CloneCodec != nil {
+		in, out := &in.CloneCodec, &out.CloneCodec
+		*out = new(CloneCodec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -265,91 +286,106 @@ func (in *CDIStatus) DeepCopy() *CDIStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolume) DeepCopyInto(out *DataVolume) {
+func (in *ClaimPropertySet) DeepCopyInto(out *ClaimPropertySet) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeMode != nil {
+		in, out := &in.VolumeMode, &out.VolumeMode
+		*out = new(v1.PersistentVolumeMode)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolume.
-func (in *DataVolume) DeepCopy() *DataVolume {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaimPropertySet.
+func (in *ClaimPropertySet) DeepCopy() *ClaimPropertySet {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolume)
+	out := new(ClaimPropertySet)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataVolume) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeBlankImage) DeepCopyInto(out *DataVolumeBlankImage) {
+func (in *CloneCodec) DeepCopyInto(out *CloneCodec) {
 	*out = *in
+	if in.Level != nil {
+		in, out := &in.Level, &out.Level
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeBlankImage.
-func (in *DataVolumeBlankImage) DeepCopy() *DataVolumeBlankImage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneCodec.
+func (in *CloneCodec) DeepCopy() *CloneCodec {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeBlankImage)
+	out := new(CloneCodec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeCheckpoint) DeepCopyInto(out *DataVolumeCheckpoint) {
+func (in *DataImportCron) DeepCopyInto(out *DataImportCron) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeCheckpoint.
-func (in *DataVolumeCheckpoint) DeepCopy() *DataVolumeCheckpoint {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataImportCron.
+func (in *DataImportCron) DeepCopy() *DataImportCron {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeCheckpoint)
+	out := new(DataImportCron)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataImportCron) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeCondition) DeepCopyInto(out *DataVolumeCondition) {
+func (in *DataImportCronCondition) DeepCopyInto(out *DataImportCronCondition) {
 	*out = *in
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeCondition.
-func (in *DataVolumeCondition) DeepCopy() *DataVolumeCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataImportCronCondition.
+func (in *DataImportCronCondition) DeepCopy() *DataImportCronCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeCondition)
+	out := new(DataImportCronCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeList) DeepCopyInto(out *DataVolumeList) {
+func (in *DataImportCronList) DeepCopyInto(out *DataImportCronList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DataVolume, len(*in))
+		*out = make([]DataImportCron, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -357,18 +393,18 @@ func (in *DataVolumeList) DeepCopyInto(out *DataVolumeList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeList.
-func (in *DataVolumeList) DeepCopy() *DataVolumeList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataImportCronList.
+func (in *DataImportCronList) DeepCopy() *DataImportCronList {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeList)
+	out := new(DataImportCronList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataVolumeList) DeepCopyObject() runtime.Object {
+func (in *DataImportCronList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -376,242 +412,949 @@ func (in *DataVolumeList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeSource) DeepCopyInto(out *DataVolumeSource) {
+func (in *DataImportCronSpec) DeepCopyInto(out *DataImportCronSpec) {
 	*out = *in
-	if in.HTTP != nil {
-		in, out := &in.HTTP, &out.HTTP
-		*out = new(DataVolumeSourceHTTP)
+	in.Template.DeepCopyInto(&out.Template)
+	if in.GarbageCollect != nil {
+		in, out := &in.GarbageCollect, &out.GarbageCollect
+		*out = new(DataImportCronGarbageCollect)
 		**out = **in
 	}
-	if in.S3 != nil {
-		in, out := &in.S3, &out.S3
-		*out = new(DataVolumeSourceS3)
+	if in.ImportsToKeep != nil {
+		in, out := &in.ImportsToKeep, &out.ImportsToKeep
+		*out = new(int32)
 		**out = **in
 	}
-	if in.Registry != nil {
-		in, out := &in.Registry, &out.Registry
-		*out = new(DataVolumeSourceRegistry)
-		**out = **in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataImportCronSpec.
+func (in *DataImportCronSpec) DeepCopy() *DataImportCronSpec {
+	if in == nil {
+		return nil
 	}
-	if in.PVC != nil {
-		in, out := &in.PVC, &out.PVC
-		*out = new(DataVolumeSourcePVC)
-		**out = **in
+	out := new(DataImportCronSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataImportCronStatus) DeepCopyInto(out *DataImportCronStatus) {
+	*out = *in
+	if in.CurrentImports != nil {
+		in, out := &in.CurrentImports, &out.CurrentImports
+		*out = make([]ImportStatus, len(*in))
+		copy(*out, *in)
 	}
-	if in.Upload != nil {
-		in, out := &in.Upload, &out.Upload
-		*out = new(DataVolumeSourceUpload)
+	if in.LastImportedPVC != nil {
+		in, out := &in.LastImportedPVC, &out.LastImportedPVC
+		*out = new(DataVolumeSourcePVC)
 		**out = **in
 	}
-	if in.Blank != nil {
-		in, out := &in.Blank, &out.Blank
-		*out = new(DataVolumeBlankImage)
-		**out = **in
+	if in.LastExecutionTimestamp != nil {
+		in, out := &in.LastExecutionTimestamp, &out.LastExecutionTimestamp
+		*out = (*in).DeepCopy()
 	}
-	if in.Imageio != nil {
-		in, out := &in.Imageio, &out.Imageio
-		*out = new(DataVolumeSourceImageIO)
-		**out = **in
+	if in.LastImportTimestamp != nil {
+		in, out := &in.LastImportTimestamp, &out.LastImportTimestamp
+		*out = (*in).DeepCopy()
 	}
-	if in.VDDK != nil {
-		in, out := &in.VDDK, &out.VDDK
-		*out = new(DataVolumeSourceVDDK)
-		**out = **in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]DataImportCronCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSource.
-func (in *DataVolumeSource) DeepCopy() *DataVolumeSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataImportCronStatus.
+func (in *DataImportCronStatus) DeepCopy() *DataImportCronStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeSource)
+	out := new(DataImportCronStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeSourceHTTP) DeepCopyInto(out *DataVolumeSourceHTTP) {
+func (in *DataSource) DeepCopyInto(out *DataSource) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceHTTP.
-func (in *DataVolumeSourceHTTP) DeepCopy() *DataVolumeSourceHTTP {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSource.
+func (in *DataSource) DeepCopy() *DataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeSourceHTTP)
+	out := new(DataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeSourceImageIO) DeepCopyInto(out *DataVolumeSourceImageIO) {
+func (in *DataSourceCondition) DeepCopyInto(out *DataSourceCondition) {
 	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceImageIO.
-func (in *DataVolumeSourceImageIO) DeepCopy() *DataVolumeSourceImageIO {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSourceCondition.
+func (in *DataSourceCondition) DeepCopy() *DataSourceCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeSourceImageIO)
+	out := new(DataSourceCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeSourcePVC) DeepCopyInto(out *DataVolumeSourcePVC) {
+func (in *DataSourceList) DeepCopyInto(out *DataSourceList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourcePVC.
-func (in *DataVolumeSourcePVC) DeepCopy() *DataVolumeSourcePVC {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSourceList.
+func (in *DataSourceList) DeepCopy() *DataSourceList {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeSourcePVC)
+	out := new(DataSourceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeSourceRegistry) DeepCopyInto(out *DataVolumeSourceRegistry) {
+func (in *DataSourceSource) DeepCopyInto(out *DataSourceSource) {
 	*out = *in
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		*out = new(DataVolumeSourcePVC)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceRegistry.
-func (in *DataVolumeSourceRegistry) DeepCopy() *DataVolumeSourceRegistry {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSourceSource.
+func (in *DataSourceSource) DeepCopy() *DataSourceSource {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeSourceRegistry)
+	out := new(DataSourceSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeSourceS3) DeepCopyInto(out *DataVolumeSourceS3) {
+func (in *DataSourceSpec) DeepCopyInto(out *DataSourceSpec) {
 	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceS3.
-func (in *DataVolumeSourceS3) DeepCopy() *DataVolumeSourceS3 {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSourceSpec.
+func (in *DataSourceSpec) DeepCopy() *DataSourceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeSourceS3)
+	out := new(DataSourceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeSourceUpload) DeepCopyInto(out *DataVolumeSourceUpload) {
+func (in *DataSourceStatus) DeepCopyInto(out *DataSourceStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]DataSourceCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceUpload.
-func (in *DataVolumeSourceUpload) DeepCopy() *DataVolumeSourceUpload {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSourceStatus.
+func (in *DataSourceStatus) DeepCopy() *DataSourceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeSourceUpload)
+	out := new(DataSourceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeSourceVDDK) DeepCopyInto(out *DataVolumeSourceVDDK) {
+func (in *DataVolume) DeepCopyInto(out *DataVolume) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceVDDK.
-func (in *DataVolumeSourceVDDK) DeepCopy() *DataVolumeSourceVDDK {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolume.
+func (in *DataVolume) DeepCopy() *DataVolume {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeSourceVDDK)
+	out := new(DataVolume)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataVolume) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeSpec) DeepCopyInto(out *DataVolumeSpec) {
+func (in *DataVolumeBlankImage) DeepCopyInto(out *DataVolumeBlankImage) {
 	*out = *in
-	in.Source.DeepCopyInto(&out.Source)
-	if in.PVC != nil {
-		in, out := &in.PVC, &out.PVC
-		*out = new(v1.PersistentVolumeClaimSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Checkpoints != nil {
-		in, out := &in.Checkpoints, &out.Checkpoints
-		*out = make([]DataVolumeCheckpoint, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSpec.
-func (in *DataVolumeSpec) DeepCopy() *DataVolumeSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeBlankImage.
+func (in *DataVolumeBlankImage) DeepCopy() *DataVolumeBlankImage {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeSpec)
+	out := new(DataVolumeBlankImage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataVolumeStatus) DeepCopyInto(out *DataVolumeStatus) {
+func (in *DataVolumeCheckpoint) DeepCopyInto(out *DataVolumeCheckpoint) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]DataVolumeCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeStatus.
-func (in *DataVolumeStatus) DeepCopy() *DataVolumeStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeCheckpoint.
+func (in *DataVolumeCheckpoint) DeepCopy() *DataVolumeCheckpoint {
 	if in == nil {
 		return nil
 	}
-	out := new(DataVolumeStatus)
+	out := new(DataVolumeCheckpoint)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FilesystemOverhead) DeepCopyInto(out *FilesystemOverhead) {
+func (in *DataVolumeCondition) DeepCopyInto(out *DataVolumeCondition) {
 	*out = *in
-	if in.StorageClass != nil {
-		in, out := &in.StorageClass, &out.StorageClass
-		*out = make(map[string]Percent, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemOverhead.
-func (in *FilesystemOverhead) DeepCopy() *FilesystemOverhead {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeCondition.
+func (in *DataVolumeCondition) DeepCopy() *DataVolumeCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(FilesystemOverhead)
+	out := new(DataVolumeCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeList) DeepCopyInto(out *DataVolumeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeList.
+func (in *DataVolumeList) DeepCopy() *DataVolumeList {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataVolumeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSource) DeepCopyInto(out *DataVolumeSource) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(DataVolumeSourceHTTP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(DataVolumeSourceS3)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Registry != nil {
+		in, out := &in.Registry, &out.Registry
+		*out = new(DataVolumeSourceRegistry)
+		**out = **in
+	}
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		*out = new(DataVolumeSourcePVC)
+		**out = **in
+	}
+	if in.Upload != nil {
+		in, out := &in.Upload, &out.Upload
+		*out = new(DataVolumeSourceUpload)
+		**out = **in
+	}
+	if in.Blank != nil {
+		in, out := &in.Blank, &out.Blank
+		*out = new(DataVolumeBlankImage)
+		**out = **in
+	}
+	if in.Imageio != nil {
+		in, out := &in.Imageio, &out.Imageio
+		*out = new(DataVolumeSourceImageIO)
+		**out = **in
+	}
+	if in.VDDK != nil {
+		in, out := &in.VDDK, &out.VDDK
+		*out = new(DataVolumeSourceVDDK)
+		**out = **in
+	}
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(DataVolumeSourceSnapshot)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSource.
+func (in *DataVolumeSource) DeepCopy() *DataVolumeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceHTTP) DeepCopyInto(out *DataVolumeSourceHTTP) {
+	*out = *in
+	if in.OVA != nil {
+		in, out := &in.OVA, &out.OVA
+		*out = new(DataVolumeSourceOVA)
+		**out = **in
+	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceHTTP.
+func (in *DataVolumeSourceHTTP) DeepCopy() *DataVolumeSourceHTTP {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceHTTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceImageIO) DeepCopyInto(out *DataVolumeSourceImageIO) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceImageIO.
+func (in *DataVolumeSourceImageIO) DeepCopy() *DataVolumeSourceImageIO {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceImageIO)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceOVA) DeepCopyInto(out *DataVolumeSourceOVA) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceOVA.
+func (in *DataVolumeSourceOVA) DeepCopy() *DataVolumeSourceOVA {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceOVA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourcePVC) DeepCopyInto(out *DataVolumeSourcePVC) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourcePVC.
+func (in *DataVolumeSourcePVC) DeepCopy() *DataVolumeSourcePVC {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourcePVC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceRef) DeepCopyInto(out *DataVolumeSourceRef) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceRef.
+func (in *DataVolumeSourceRef) DeepCopy() *DataVolumeSourceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceRegistry) DeepCopyInto(out *DataVolumeSourceRegistry) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceRegistry.
+func (in *DataVolumeSourceRegistry) DeepCopy() *DataVolumeSourceRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceS3) DeepCopyInto(out *DataVolumeSourceS3) {
+	*out = *in
+	if in.OVA != nil {
+		in, out := &in.OVA, &out.OVA
+		*out = new(DataVolumeSourceOVA)
+		**out = **in
+	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceS3.
+func (in *DataVolumeSourceS3) DeepCopy() *DataVolumeSourceS3 {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceS3)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceSnapshot) DeepCopyInto(out *DataVolumeSourceSnapshot) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceSnapshot.
+func (in *DataVolumeSourceSnapshot) DeepCopy() *DataVolumeSourceSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceUpload) DeepCopyInto(out *DataVolumeSourceUpload) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceUpload.
+func (in *DataVolumeSourceUpload) DeepCopy() *DataVolumeSourceUpload {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceUpload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceVDDK) DeepCopyInto(out *DataVolumeSourceVDDK) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceVDDK.
+func (in *DataVolumeSourceVDDK) DeepCopy() *DataVolumeSourceVDDK {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceVDDK)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSpec) DeepCopyInto(out *DataVolumeSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	if in.SourceRef != nil {
+		in, out := &in.SourceRef, &out.SourceRef
+		*out = new(DataVolumeSourceRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		*out = new(v1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(StorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Checkpoints != nil {
+		in, out := &in.Checkpoints, &out.Checkpoints
+		*out = make([]DataVolumeCheckpoint, len(*in))
+		copy(*out, *in)
+	}
+	if in.Preallocation != nil {
+		in, out := &in.Preallocation, &out.Preallocation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CloneCodec != nil {
+		in, out := &in.CloneCodec, &out.CloneCodec
+		*out = new(CloneCodec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSpec.
+func (in *DataVolumeSpec) DeepCopy() *DataVolumeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeStatus) DeepCopyInto(out *DataVolumeStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]DataVolumeCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllocatedBytes != nil {
+		in, out := &in.AllocatedBytes, &out.AllocatedBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.InferredSize != nil {
+		in, out := &in.InferredSize, &out.InferredSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeStatus.
+func (in *DataVolumeStatus) DeepCopy() *DataVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemOverhead) DeepCopyInto(out *FilesystemOverhead) {
+	*out = *in
+	if in.StorageClass != nil {
+		in, out := &in.StorageClass, &out.StorageClass
+		*out = make(map[string]Percent, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemOverhead.
+func (in *FilesystemOverhead) DeepCopy() *FilesystemOverhead {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemOverhead)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportStatus) DeepCopyInto(out *ImportStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportStatus.
+func (in *ImportStatus) DeepCopy() *ImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageProfile) DeepCopyInto(out *StorageProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageProfile.
+func (in *StorageProfile) DeepCopy() *StorageProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StorageProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageProfileList) DeepCopyInto(out *StorageProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StorageProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageProfileList.
+func (in *StorageProfileList) DeepCopy() *StorageProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StorageProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageProfileSpec) DeepCopyInto(out *StorageProfileSpec) {
+	*out = *in
+	if in.ClaimPropertySets != nil {
+		in, out := &in.ClaimPropertySets, &out.ClaimPropertySets
+		*out = make([]ClaimPropertySet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageProfileSpec.
+func (in *StorageProfileSpec) DeepCopy() *StorageProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageProfileStatus) DeepCopyInto(out *StorageProfileStatus) {
+	*out = *in
+	if in.StorageClass != nil {
+		in, out := &in.StorageClass, &out.StorageClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.Provisioner != nil {
+		in, out := &in.Provisioner, &out.Provisioner
+		*out = new(string)
+		**out = **in
+	}
+	if in.ClaimPropertySets != nil {
+		in, out := &in.ClaimPropertySets, &out.ClaimPropertySets
+		*out = make([]ClaimPropertySet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageProfileStatus.
+func (in *StorageProfileStatus) DeepCopy() *StorageProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageSpec) DeepCopyInto(out *StorageSpec) {
+	*out = *in
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.VolumeMode != nil {
+		in, out := &in.VolumeMode, &out.VolumeMode
+		*out = new(v1.PersistentVolumeMode)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSpec.
+func (in *StorageSpec) DeepCopy() *StorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeExport) DeepCopyInto(out *VolumeExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeExport.
+func (in *VolumeExport) DeepCopy() *VolumeExport {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeExport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeExportList) DeepCopyInto(out *VolumeExportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VolumeExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeExportList.
+func (in *VolumeExportList) DeepCopy() *VolumeExportList {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeExportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeExportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeExportSource) DeepCopyInto(out *VolumeExportSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeExportSource.
+func (in *VolumeExportSource) DeepCopy() *VolumeExportSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeExportSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeExportSpec) DeepCopyInto(out *VolumeExportSpec) {
+	*out = *in
+	out.Source = in.Source
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeExportSpec.
+func (in *VolumeExportSpec) DeepCopy() *VolumeExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeExportStatus) DeepCopyInto(out *VolumeExportStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeExportStatus.
+func (in *VolumeExportStatus) DeepCopy() *VolumeExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeExportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
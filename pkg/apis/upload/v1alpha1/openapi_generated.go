@@ -13363,6 +13363,20 @@ func schema_pkg_apis_upload_v1alpha1_UploadTokenRequestSpec(ref common.Reference
 							Format:      "",
 						},
 					},
+					"expirationSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpirationSeconds is the requested lifetime of the token, bounded by the uploadTokenMaxExpirationSeconds of the CDIConfig. If not defined it is 300",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"singleUse": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SingleUse requests a token the upload server rejects once an upload with it succeeded",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"pvcName"},
 			},
@@ -13384,8 +13398,23 @@ func schema_pkg_apis_upload_v1alpha1_UploadTokenRequestStatus(ref common.Referen
 							Format:      "",
 						},
 					},
+					"expirationTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpirationTimestamp is the time the token expires",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"singleUse": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SingleUse is true when the token is rejected once an upload with it succeeded",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
@@ -38,12 +38,21 @@ type UploadTokenRequest struct {
 type UploadTokenRequestSpec struct {
 	// PvcName is the name of the PVC to upload to
 	PvcName string `json:"pvcName"`
+	// ExpirationSeconds is the requested lifetime of the token, bounded by the uploadTokenMaxExpirationSeconds of the
+	// CDIConfig. If not defined it is 300
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+	// SingleUse requests a token the upload server rejects once an upload with it succeeded
+	SingleUse bool `json:"singleUse,omitempty"`
 }
 
 // UploadTokenRequestStatus stores the status of a token request
 type UploadTokenRequestStatus struct {
 	// Token is a JWT token to be inserted in "Authentication Bearer header"
 	Token string `json:"token,omitempty"`
+	// ExpirationTimestamp is the time the token expires
+	ExpirationTimestamp *metav1.Time `json:"expirationTimestamp,omitempty"`
+	// SingleUse is true when the token is rejected once an upload with it succeeded
+	SingleUse bool `json:"singleUse,omitempty"`
 }
 
 // UploadTokenRequestList contains a list of UploadTokenRequests
@@ -12,15 +12,19 @@ func (UploadTokenRequest) SwaggerDoc() map[string]string {
 
 func (UploadTokenRequestSpec) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":        "UploadTokenRequestSpec defines the parameters of the token request",
-		"pvcName": "PvcName is the name of the PVC to upload to",
+		"":                  "UploadTokenRequestSpec defines the parameters of the token request",
+		"pvcName":           "PvcName is the name of the PVC to upload to",
+		"expirationSeconds": "ExpirationSeconds is the requested lifetime of the token, bounded by the uploadTokenMaxExpirationSeconds of the\nCDIConfig. If not defined it is 300",
+		"singleUse":         "SingleUse requests a token the upload server rejects once an upload with it succeeded",
 	}
 }
 
 func (UploadTokenRequestStatus) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":      "UploadTokenRequestStatus stores the status of a token request",
-		"token": "Token is a JWT token to be inserted in \"Authentication Bearer header\"",
+		"":                    "UploadTokenRequestStatus stores the status of a token request",
+		"token":               "Token is a JWT token to be inserted in \"Authentication Bearer header\"",
+		"expirationTimestamp": "ExpirationTimestamp is the time the token expires",
+		"singleUse":           "SingleUse is true when the token is rejected once an upload with it succeeded",
 	}
 }
 
@@ -29,8 +29,8 @@ func (in *UploadTokenRequest) DeepCopyInto(out *UploadTokenRequest) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -88,6 +88,11 @@ func (in *UploadTokenRequestList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UploadTokenRequestSpec) DeepCopyInto(out *UploadTokenRequestSpec) {
 	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -104,6 +109,10 @@ func (in *UploadTokenRequestSpec) DeepCopy() *UploadTokenRequestSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UploadTokenRequestStatus) DeepCopyInto(out *UploadTokenRequestStatus) {
 	*out = *in
+	if in.ExpirationTimestamp != nil {
+		in, out := &in.ExpirationTimestamp, &out.ExpirationTimestamp
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
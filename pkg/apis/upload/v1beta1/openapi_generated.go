@@ -280,6 +280,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"k8s.io/apimachinery/pkg/apis/meta/v1.TypeMeta":                                            schema_pkg_apis_meta_v1_TypeMeta(ref),
 		"k8s.io/apimachinery/pkg/apis/meta/v1.UpdateOptions":                                       schema_pkg_apis_meta_v1_UpdateOptions(ref),
 		"k8s.io/apimachinery/pkg/apis/meta/v1.WatchEvent":                                          schema_pkg_apis_meta_v1_WatchEvent(ref),
+		"kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequest":       schema_pkg_apis_upload_v1beta1_ExportTokenRequest(ref),
+		"kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequestList":   schema_pkg_apis_upload_v1beta1_ExportTokenRequestList(ref),
+		"kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequestSpec":   schema_pkg_apis_upload_v1beta1_ExportTokenRequestSpec(ref),
+		"kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequestStatus": schema_pkg_apis_upload_v1beta1_ExportTokenRequestStatus(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.UploadTokenRequest":       schema_pkg_apis_upload_v1beta1_UploadTokenRequest(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.UploadTokenRequestList":   schema_pkg_apis_upload_v1beta1_UploadTokenRequestList(ref),
 		"kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.UploadTokenRequestSpec":   schema_pkg_apis_upload_v1beta1_UploadTokenRequestSpec(ref),
@@ -13254,6 +13258,142 @@ func schema_pkg_apis_meta_v1_WatchEvent(ref common.ReferenceCallback) common.Ope
 	}
 }
 
+func schema_pkg_apis_upload_v1beta1_ExportTokenRequest(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExportTokenRequest is the CR used to get a token to download a CDI VolumeExport",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spec contains the parameters of the request",
+							Ref:         ref("kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequestSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status contains the status of the request",
+							Ref:         ref("kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequestStatus"),
+						},
+					},
+				},
+				Required: []string{"metadata", "spec", "status"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta", "kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequestSpec", "kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequestStatus"},
+	}
+}
+
+func schema_pkg_apis_upload_v1beta1_ExportTokenRequestList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExportTokenRequestList contains a list of ExportTokenRequests",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Items contains a list of ExportTokenRequests",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequest"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta", "kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1.ExportTokenRequest"},
+	}
+}
+
+func schema_pkg_apis_upload_v1beta1_ExportTokenRequestSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExportTokenRequestSpec defines the parameters of the token request",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"volumeExportName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VolumeExportName is the name of the VolumeExport to download",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"volumeExportName"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_upload_v1beta1_ExportTokenRequestStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExportTokenRequestStatus stores the status of a token request",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"token": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Token is a JWT token to be inserted in \"Authentication Bearer header\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_upload_v1beta1_UploadTokenRequest(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -13363,6 +13503,20 @@ func schema_pkg_apis_upload_v1beta1_UploadTokenRequestSpec(ref common.ReferenceC
 							Format:      "",
 						},
 					},
+					"expirationSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpirationSeconds is the requested lifetime of the token, bounded by the uploadTokenMaxExpirationSeconds of the CDIConfig. If not defined it is 300",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"singleUse": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SingleUse requests a token the upload server rejects once an upload with it succeeded",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"pvcName"},
 			},
@@ -13384,8 +13538,23 @@ func schema_pkg_apis_upload_v1beta1_UploadTokenRequestStatus(ref common.Referenc
 							Format:      "",
 						},
 					},
+					"expirationTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpirationTimestamp is the time the token expires",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"singleUse": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SingleUse is true when the token is rejected once an upload with it succeeded",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
@@ -38,12 +38,21 @@ type UploadTokenRequest struct {
 type UploadTokenRequestSpec struct {
 	// PvcName is the name of the PVC to upload to
 	PvcName string `json:"pvcName"`
+	// ExpirationSeconds is the requested lifetime of the token, bounded by the uploadTokenMaxExpirationSeconds of the
+	// CDIConfig. If not defined it is 300
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+	// SingleUse requests a token the upload server rejects once an upload with it succeeded
+	SingleUse bool `json:"singleUse,omitempty"`
 }
 
 // UploadTokenRequestStatus stores the status of a token request
 type UploadTokenRequestStatus struct {
 	// Token is a JWT token to be inserted in "Authentication Bearer header"
 	Token string `json:"token,omitempty"`
+	// ExpirationTimestamp is the time the token expires
+	ExpirationTimestamp *metav1.Time `json:"expirationTimestamp,omitempty"`
+	// SingleUse is true when the token is rejected once an upload with it succeeded
+	SingleUse bool `json:"singleUse,omitempty"`
 }
 
 // UploadTokenRequestList contains a list of UploadTokenRequests
@@ -55,3 +64,39 @@ type UploadTokenRequestList struct {
 	// Items contains a list of UploadTokenRequests
 	Items []UploadTokenRequest `json:"items"`
 }
+
+// ExportTokenRequest is the CR used to get a token to download a CDI VolumeExport
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ExportTokenRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec contains the parameters of the request
+	Spec ExportTokenRequestSpec `json:"spec"`
+
+	// Status contains the status of the request
+	Status ExportTokenRequestStatus `json:"status"`
+}
+
+// ExportTokenRequestSpec defines the parameters of the token request
+type ExportTokenRequestSpec struct {
+	// VolumeExportName is the name of the VolumeExport to download
+	VolumeExportName string `json:"volumeExportName"`
+}
+
+// ExportTokenRequestStatus stores the status of a token request
+type ExportTokenRequestStatus struct {
+	// Token is a JWT token to be inserted in "Authentication Bearer header"
+	Token string `json:"token,omitempty"`
+}
+
+// ExportTokenRequestList contains a list of ExportTokenRequests
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ExportTokenRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items contains a list of ExportTokenRequests
+	Items []ExportTokenRequest `json:"items"`
+}
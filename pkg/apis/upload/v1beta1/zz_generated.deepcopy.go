@@ -25,7 +25,7 @@ import (
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *UploadTokenRequest) DeepCopyInto(out *UploadTokenRequest) {
+func (in *ExportTokenRequest) DeepCopyInto(out *ExportTokenRequest) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -34,6 +34,99 @@ func (in *UploadTokenRequest) DeepCopyInto(out *UploadTokenRequest) {
 	return
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportTokenRequest.
+func (in *ExportTokenRequest) DeepCopy() *ExportTokenRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportTokenRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExportTokenRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportTokenRequestList) DeepCopyInto(out *ExportTokenRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ExportTokenRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportTokenRequestList.
+func (in *ExportTokenRequestList) DeepCopy() *ExportTokenRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportTokenRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExportTokenRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportTokenRequestSpec) DeepCopyInto(out *ExportTokenRequestSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportTokenRequestSpec.
+func (in *ExportTokenRequestSpec) DeepCopy() *ExportTokenRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportTokenRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportTokenRequestStatus) DeepCopyInto(out *ExportTokenRequestStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportTokenRequestStatus.
+func (in *ExportTokenRequestStatus) DeepCopy() *ExportTokenRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportTokenRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UploadTokenRequest) DeepCopyInto(out *UploadTokenRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UploadTokenRequest.
 func (in *UploadTokenRequest) DeepCopy() *UploadTokenRequest {
 	if in == nil {
@@ -88,6 +181,11 @@ func (in *UploadTokenRequestList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UploadTokenRequestSpec) DeepCopyInto(out *UploadTokenRequestSpec) {
 	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -104,6 +202,10 @@ func (in *UploadTokenRequestSpec) DeepCopy() *UploadTokenRequestSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UploadTokenRequestStatus) DeepCopyInto(out *UploadTokenRequestStatus) {
 	*out = *in
+	if in.ExpirationTimestamp != nil {
+		in, out := &in.ExpirationTimestamp, &out.ExpirationTimestamp
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -21,7 +21,6 @@ package apiserver
 
 import (
 	"context"
-	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -38,7 +37,9 @@ import (
 	restful "github.com/emicklei/go-restful"
 	"github.com/go-openapi/spec"
 	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	aggregatorclient "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
@@ -57,6 +58,9 @@ const (
 	// selfsigned cert secret name
 	apiSigningKeySecretName = "cdi-api-signing-key"
 
+	// how often the signing key is reloaded from its secret
+	signingKeyReloadInterval = time.Minute
+
 	uploadTokenGroup = "upload.cdi.kubevirt.io"
 
 	dvValidatePath = "/datavolume-validate"
@@ -65,6 +69,8 @@ const (
 
 	cdiValidatePath = "/cdi-validate"
 
+	dataImportCronValidatePath = "/dataimportcron-validate"
+
 	healthzPath = "/healthz"
 )
 
@@ -88,7 +94,7 @@ type cdiAPIApp struct {
 	aggregatorClient aggregatorclient.Interface
 	cdiClient        cdiclient.Interface
 
-	privateSigningKey *rsa.PrivateKey
+	signingKeyWatcher *keys.SigningKeyWatcher
 
 	container *restful.Container
 
@@ -97,7 +103,8 @@ type cdiAPIApp struct {
 
 	certWarcher CertWatcher
 
-	tokenGenerator token.Generator
+	tokenGenerator       token.Generator
+	exportTokenGenerator token.Generator
 }
 
 // UploadTokenRequestAPI returns web service for swagger generation
@@ -171,28 +178,40 @@ func NewCdiAPIServer(bindAddress string,
 		return nil, errors.Errorf("failed to create CDI validating webhook: %s", err)
 	}
 
+	err = app.createDataImportCronValidatingWebhook()
+	if err != nil {
+		return nil, errors.Errorf("failed to create DataImportCron validating webhook: %s", err)
+	}
+
 	return app, nil
 }
 
-func newUploadTokenGenerator(key *rsa.PrivateKey) token.Generator {
-	return token.NewGenerator(common.UploadTokenIssuer, key, 5*time.Minute)
+func newUploadTokenGenerator(signingKeys token.SigningKeySource) token.Generator {
+	return token.NewRotatingGenerator(common.UploadTokenIssuer, signingKeys, 5*time.Minute)
+}
+
+func newExportTokenGenerator(signingKeys token.SigningKeySource) token.Generator {
+	return token.NewRotatingGenerator(common.ExportTokenIssuer, signingKeys, 5*time.Minute)
 }
 
 func (app *cdiAPIApp) Start(ch <-chan struct{}) error {
+	// pick up the signing key rotated by the operator
+	go app.signingKeyWatcher.Run(signingKeyReloadInterval, ch)
 	return app.startTLS(ch)
 }
 
 func (app *cdiAPIApp) getKeysAndCerts() error {
 	namespace := util.GetNamespace()
 
-	privateKey, err := keys.GetOrCreatePrivateKey(app.client, namespace, apiSigningKeySecretName)
+	signingKeyWatcher, err := keys.NewSigningKeyWatcher(app.client, namespace, apiSigningKeySecretName)
 	if err != nil {
 		return errors.Wrap(err, "Error getting/creating signing key")
 	}
 
-	app.privateSigningKey = privateKey
+	app.signingKeyWatcher = signingKeyWatcher
 
-	app.tokenGenerator = newUploadTokenGenerator(privateKey)
+	app.tokenGenerator = newUploadTokenGenerator(signingKeyWatcher)
+	app.exportTokenGenerator = newExportTokenGenerator(signingKeyWatcher)
 
 	return nil
 }
@@ -291,6 +310,24 @@ func (app *cdiAPIApp) uploadHandler(request *restful.Request, response *restful.
 		return
 	}
 
+	lifetime, err := app.uploadTokenLifetime(uploadToken.Spec.ExpirationSeconds)
+	if err != nil {
+		klog.Error(err)
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	pvc, err := app.client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), uploadToken.Spec.PvcName, metav1.GetOptions{})
+	if err != nil {
+		klog.Error(err)
+		if k8serrors.IsNotFound(err) {
+			response.WriteError(http.StatusNotFound, err)
+			return
+		}
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+
 	tokenData := &token.Payload{
 		Operation: token.OperationUpload,
 		Name:      uploadToken.Spec.PvcName,
@@ -300,9 +337,13 @@ func (app *cdiAPIApp) uploadHandler(request *restful.Request, response *restful.
 			Version:  "v1",
 			Resource: "persistentvolumeclaims",
 		},
+		UID: pvc.UID,
+	}
+	if uploadToken.Spec.SingleUse {
+		tokenData.ID = string(uuid.NewUUID())
 	}
 
-	token, err := app.tokenGenerator.Generate(tokenData)
+	token, expiry, err := app.tokenGenerator.GenerateWithLifetime(tokenData, lifetime)
 	if err != nil {
 		klog.Error(err)
 		response.WriteError(http.StatusInternalServerError, err)
@@ -310,10 +351,89 @@ func (app *cdiAPIApp) uploadHandler(request *restful.Request, response *restful.
 	}
 
 	uploadToken.Status.Token = token
+	uploadToken.Status.ExpirationTimestamp = &metav1.Time{Time: expiry}
+	uploadToken.Status.SingleUse = uploadToken.Spec.SingleUse
 	response.WriteAsJson(uploadToken)
 
 }
 
+// uploadTokenLifetime returns the requested lifetime of an upload token, or the default one, capped to the maximum
+// of the CDIConfig
+func (app *cdiAPIApp) uploadTokenLifetime(expirationSeconds *int64) (time.Duration, error) {
+	seconds := int64(common.DefaultUploadTokenExpirationSeconds)
+	if expirationSeconds != nil {
+		if *expirationSeconds <= 0 {
+			return 0, errors.Errorf("invalid expirationSeconds %d", *expirationSeconds)
+		}
+		seconds = *expirationSeconds
+	}
+
+	max := int64(common.DefaultUploadTokenMaxExpirationSeconds)
+	config, err := app.cdiClient.CdiV1beta1().CDIConfigs().Get(context.TODO(), common.ConfigName, metav1.GetOptions{})
+	if err != nil {
+		klog.Warningf("Unable to get CDIConfig, using the default upload token max expiration: %v", err)
+	} else if config.Status.UploadTokenMaxExpirationSeconds > 0 {
+		max = config.Status.UploadTokenMaxExpirationSeconds
+	}
+	if seconds > max {
+		seconds = max
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func (app *cdiAPIApp) exportHandler(request *restful.Request, response *restful.Response) {
+	allowed, reason, err := app.authorizer.Authorize(request)
+
+	if err != nil {
+		klog.Error(err)
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	} else if !allowed {
+		klog.Infof("Rejected Request: %s", reason)
+		response.WriteErrorString(http.StatusUnauthorized, reason)
+		return
+	}
+
+	namespace := request.PathParameter("namespace")
+	defer request.Request.Body.Close()
+	body, err := ioutil.ReadAll(request.Request.Body)
+	if err != nil {
+		klog.Error(err)
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	exportToken := &cdiuploadv1.ExportTokenRequest{}
+	err = json.Unmarshal(body, exportToken)
+	if err != nil {
+		klog.Error(err)
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	tokenData := &token.Payload{
+		Operation: token.OperationExport,
+		Name:      exportToken.Spec.VolumeExportName,
+		Namespace: namespace,
+		Resource: metav1.GroupVersionResource{
+			Group:    "cdi.kubevirt.io",
+			Version:  "v1beta1",
+			Resource: "volumeexports",
+		},
+	}
+
+	token, err := app.exportTokenGenerator.Generate(tokenData)
+	if err != nil {
+		klog.Error(err)
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+
+	exportToken.Status.Token = token
+	response.WriteAsJson(exportToken)
+}
+
 func uploadTokenAPIGroup() metav1.APIGroup {
 	apiGroup := metav1.APIGroup{
 		Name: uploadTokenGroup,
@@ -343,6 +463,7 @@ func (app *cdiAPIApp) composeUploadTokenAPI() {
 	objExample := reflect.ValueOf(objPointer).Elem().Interface()
 	objKind := "UploadTokenRequest"
 	resource := "uploadtokenrequests"
+	exportResource := "exporttokenrequests"
 
 	groupPath := fmt.Sprintf("/apis/%s", uploadTokenGroup)
 	createPath := fmt.Sprintf("/namespaces/{namespace:[a-z0-9][a-z0-9\\-]*}/%s", resource)
@@ -372,6 +493,23 @@ func (app *cdiAPIApp) composeUploadTokenAPI() {
 			Returns(http.StatusUnauthorized, "Unauthorized", "").
 			Param(uploadTokenWs.PathParameter("namespace", "Object name and auth scope, such as for teams and projects").Required(true)))
 
+		// export tokens are only served by the current version
+		if uploadTokenVersion == uploadTokenVersions[0] {
+			exportObjExample := reflect.ValueOf(&cdiuploadv1.ExportTokenRequest{}).Elem().Interface()
+			exportCreatePath := fmt.Sprintf("/namespaces/{namespace:[a-z0-9][a-z0-9\\-]*}/%s", exportResource)
+			uploadTokenWs.Route(uploadTokenWs.POST(exportCreatePath).
+				Produces("application/json").
+				Consumes("application/json").
+				Operation("createNamespacedExportTokenRequest-"+v).
+				To(app.exportHandler).Reads(exportObjExample).Writes(exportObjExample).
+				Doc("Create an ExportTokenRequest object.").
+				Returns(http.StatusOK, "OK", exportObjExample).
+				Returns(http.StatusCreated, "Created", exportObjExample).
+				Returns(http.StatusAccepted, "Accepted", exportObjExample).
+				Returns(http.StatusUnauthorized, "Unauthorized", "").
+				Param(uploadTokenWs.PathParameter("namespace", "Object name and auth scope, such as for teams and projects").Required(true)))
+		}
+
 		uploadTokenWs.Route(uploadTokenWs.GET("/").
 			Produces("application/json").Writes(metav1.APIResourceList{}).
 			To(func(request *restful.Request, response *restful.Response) {
@@ -389,6 +527,18 @@ func (app *cdiAPIApp) composeUploadTokenAPI() {
 					Verbs:        []string{"create"},
 					ShortNames:   []string{"utr", "utrs"},
 				})
+				if uploadTokenVersion == uploadTokenVersions[0] {
+					list.APIResources = append(list.APIResources, metav1.APIResource{
+						Name:         exportResource,
+						SingularName: "exporttokenrequest",
+						Namespaced:   true,
+						Group:        uploadTokenGroup,
+						Version:      uploadTokenVersion,
+						Kind:         "ExportTokenRequest",
+						Verbs:        []string{"create"},
+						ShortNames:   []string{"etr", "etrs"},
+					})
+				}
 				response.WriteAsJson(list)
 			}).
 			Operation("getAPIResources-"+v).
@@ -472,12 +622,12 @@ func (app *cdiAPIApp) healthzHandler(req *restful.Request, resp *restful.Respons
 }
 
 func (app *cdiAPIApp) createDataVolumeValidatingWebhook() error {
-	app.container.ServeMux.Handle(dvValidatePath, webhooks.NewDataVolumeValidatingWebhook(app.client))
+	app.container.ServeMux.Handle(dvValidatePath, webhooks.NewDataVolumeValidatingWebhook(app.client, app.cdiClient))
 	return nil
 }
 
 func (app *cdiAPIApp) createDataVolumeMutatingWebhook() error {
-	app.container.ServeMux.Handle(dvMutatePath, webhooks.NewDataVolumeMutatingWebhook(app.client, app.privateSigningKey))
+	app.container.ServeMux.Handle(dvMutatePath, webhooks.NewDataVolumeMutatingWebhook(app.client, app.cdiClient, app.signingKeyWatcher))
 	return nil
 }
 
@@ -485,3 +635,8 @@ func (app *cdiAPIApp) createCDIValidatingWebhook() error {
 	app.container.ServeMux.Handle(cdiValidatePath, webhooks.NewCDIValidatingWebhook(app.cdiClient))
 	return nil
 }
+
+func (app *cdiAPIApp) createDataImportCronValidatingWebhook() error {
+	app.container.ServeMux.Handle(dataImportCronValidatePath, webhooks.NewDataImportCronValidatingWebhook(app.client, app.cdiClient))
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
@@ -41,8 +42,12 @@ import (
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
 	cdiuploadv1 "kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1"
+	cdiclientfake "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned/fake"
+	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/keys/keystest"
+	"kubevirt.io/containerized-data-importer/pkg/token"
 )
 
 type testAuthorizer struct {
@@ -226,7 +231,9 @@ var _ = Describe("API server tests", func() {
 		actions := []core.Action{}
 		actions = append(actions, signingKeySecretGetAction())
 		actions = append(actions, cdiConfigGetAction())
-		actions = append(actions, signingKeySecretCreateAction(app.privateSigningKey))
+		signingKey, err := app.signingKeyWatcher.SigningKey()
+		Expect(err).ToNot(HaveOccurred())
+		actions = append(actions, signingKeySecretCreateAction(signingKey))
 
 		checkActions(actions, client.Actions())
 	})
@@ -257,6 +264,18 @@ var _ = Describe("API server tests", func() {
 				},
 			},
 		}
+		if version == "v1beta1" {
+			expectedResourceList.APIResources = append(expectedResourceList.APIResources, metav1.APIResource{
+				Name:         "exporttokenrequests",
+				SingularName: "exporttokenrequest",
+				Namespaced:   true,
+				Group:        "upload.cdi.kubevirt.io",
+				Version:      version,
+				Kind:         "ExportTokenRequest",
+				Verbs:        []string{"create"},
+				ShortNames:   []string{"etr", "etrs"},
+			})
+		}
 
 		Expect(reflect.DeepEqual(expectedResourceList, resourceList)).To(BeTrue())
 	},
@@ -349,6 +368,7 @@ var _ = Describe("API server tests", func() {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-pvc",
 			Namespace: "default",
+			UID:       "test-pvc-uid",
 		},
 	}
 
@@ -367,9 +387,9 @@ var _ = Describe("API server tests", func() {
 		client := k8sfake.NewSimpleClientset(kubeobjects...)
 
 		app := &cdiAPIApp{client: client,
-			privateSigningKey: signingKey,
-			authorizer:        args.authorizer,
-			tokenGenerator:    newUploadTokenGenerator(signingKey)}
+			cdiClient:      cdiclientfake.NewSimpleClientset(),
+			authorizer:     args.authorizer,
+			tokenGenerator: newUploadTokenGenerator(token.StaticSigningKey(signingKey))}
 		app.composeUploadTokenAPI()
 
 		req, err := http.NewRequest("POST",
@@ -389,6 +409,13 @@ var _ = Describe("API server tests", func() {
 			err := json.Unmarshal(rr.Body.Bytes(), &uploadTokenRequest)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(uploadTokenRequest.Status.Token).To(Not(Equal("")))
+
+			validator := token.NewValidator(common.UploadTokenIssuer, &signingKey.PublicKey, 0)
+			payload, err := validator.Validate(uploadTokenRequest.Status.Token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(payload.Name).To(Equal("test-pvc"))
+			Expect(payload.UID).To(Equal(pvc.UID))
+			Expect(payload.ID).To(BeEmpty())
 		}
 	},
 		table.Entry("authoriser error",
@@ -409,7 +436,7 @@ var _ = Describe("API server tests", func() {
 			args{
 				authorizer: authorizeSuccess,
 			},
-			http.StatusOK,
+			http.StatusNotFound,
 			false),
 
 		table.Entry("upload possible",
@@ -420,4 +447,134 @@ var _ = Describe("API server tests", func() {
 			http.StatusOK,
 			true),
 	)
+
+	requestUploadToken := func(app *cdiAPIApp, spec cdiuploadv1.UploadTokenRequestSpec) *httptest.ResponseRecorder {
+		app.composeUploadTokenAPI()
+		serializedRequest, err := json.Marshal(&cdiuploadv1.UploadTokenRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-token",
+				Namespace: "default",
+			},
+			Spec: spec,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		req, err := http.NewRequest("POST",
+			"/apis/upload.cdi.kubevirt.io/v1beta1/namespaces/default/uploadtokenrequests",
+			bytes.NewReader(serializedRequest))
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.container.ServeHTTP(rr, req)
+		return rr
+	}
+
+	table.DescribeTable("Get token with lifetime", func(expirationSeconds *int64, maxExpirationSeconds int64, expectedStatus int, expectedLifetime time.Duration) {
+		cdiConfig := &cdiv1.CDIConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: common.ConfigName},
+			Status: cdiv1.CDIConfigStatus{
+				UploadTokenMaxExpirationSeconds: maxExpirationSeconds,
+			},
+		}
+		app := &cdiAPIApp{client: k8sfake.NewSimpleClientset(pvc),
+			cdiClient:      cdiclientfake.NewSimpleClientset(cdiConfig),
+			authorizer:     authorizeSuccess,
+			tokenGenerator: newUploadTokenGenerator(token.StaticSigningKey(signingKey))}
+
+		start := time.Now().Truncate(time.Second)
+		rr := requestUploadToken(app, cdiuploadv1.UploadTokenRequestSpec{
+			PvcName:           "test-pvc",
+			ExpirationSeconds: expirationSeconds,
+		})
+		Expect(rr.Code).To(Equal(expectedStatus))
+		if expectedStatus != http.StatusOK {
+			return
+		}
+
+		uploadTokenRequest := &cdiuploadv1.UploadTokenRequest{}
+		Expect(json.Unmarshal(rr.Body.Bytes(), &uploadTokenRequest)).To(Succeed())
+		Expect(uploadTokenRequest.Status.ExpirationTimestamp).ToNot(BeNil())
+		expiry := uploadTokenRequest.Status.ExpirationTimestamp.Time
+		Expect(expiry).To(BeTemporally(">=", start.Add(expectedLifetime)))
+		Expect(expiry).To(BeTemporally("<=", time.Now().Add(expectedLifetime)))
+	},
+		table.Entry("default lifetime", nil, int64(0), http.StatusOK, 5*time.Minute),
+		table.Entry("requested lifetime", &[]int64{1800}[0], int64(0), http.StatusOK, 30*time.Minute),
+		table.Entry("requested lifetime capped to the default max", &[]int64{7200}[0], int64(0), http.StatusOK, time.Hour),
+		table.Entry("requested lifetime capped to the CDIConfig max", &[]int64{7200}[0], int64(600), http.StatusOK, 10*time.Minute),
+		table.Entry("default lifetime capped to the CDIConfig max", nil, int64(60), http.StatusOK, time.Minute),
+		table.Entry("requested lifetime longer than the default", &[]int64{7200}[0], int64(86400), http.StatusOK, 2*time.Hour),
+		table.Entry("invalid lifetime", &[]int64{0}[0], int64(0), http.StatusBadRequest, time.Duration(0)),
+	)
+
+	It("Get single-use token", func() {
+		app := &cdiAPIApp{client: k8sfake.NewSimpleClientset(pvc),
+			cdiClient:      cdiclientfake.NewSimpleClientset(),
+			authorizer:     authorizeSuccess,
+			tokenGenerator: newUploadTokenGenerator(token.StaticSigningKey(signingKey))}
+		validator := token.NewValidator(common.UploadTokenIssuer, &signingKey.PublicKey, 0)
+
+		var ids []string
+		for i := 0; i < 2; i++ {
+			rr := requestUploadToken(app, cdiuploadv1.UploadTokenRequestSpec{PvcName: "test-pvc", SingleUse: true})
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			uploadTokenRequest := &cdiuploadv1.UploadTokenRequest{}
+			Expect(json.Unmarshal(rr.Body.Bytes(), &uploadTokenRequest)).To(Succeed())
+			Expect(uploadTokenRequest.Status.SingleUse).To(BeTrue())
+
+			payload, err := validator.Validate(uploadTokenRequest.Status.Token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(payload.UID).To(Equal(pvc.UID))
+			Expect(payload.ID).ToNot(BeEmpty())
+			ids = append(ids, payload.ID)
+		}
+		Expect(ids[0]).ToNot(Equal(ids[1]))
+	})
+
+	table.DescribeTable("Get export token", func(authorizer CdiAPIAuthorizer, expectedStatus int, checkToken bool) {
+		app := &cdiAPIApp{client: k8sfake.NewSimpleClientset(),
+			authorizer:           authorizer,
+			exportTokenGenerator: newExportTokenGenerator(token.StaticSigningKey(signingKey))}
+		app.composeUploadTokenAPI()
+
+		exportRequest := &cdiuploadv1.ExportTokenRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-token",
+				Namespace: "default",
+			},
+			Spec: cdiuploadv1.ExportTokenRequestSpec{
+				VolumeExportName: "test-export",
+			},
+		}
+		serializedExportRequest, err := json.Marshal(exportRequest)
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err := http.NewRequest("POST",
+			"/apis/upload.cdi.kubevirt.io/v1beta1/namespaces/default/exporttokenrequests",
+			bytes.NewReader(serializedExportRequest))
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		app.container.ServeHTTP(rr, req)
+
+		Expect(rr.Code).To(Equal(expectedStatus))
+
+		if checkToken {
+			exportTokenRequest := &cdiuploadv1.ExportTokenRequest{}
+			err := json.Unmarshal(rr.Body.Bytes(), &exportTokenRequest)
+			Expect(err).ToNot(HaveOccurred())
+
+			validator := token.NewValidator(common.ExportTokenIssuer, &signingKey.PublicKey, 0)
+			payload, err := validator.Validate(exportTokenRequest.Status.Token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(payload.Operation).To(Equal(token.OperationExport))
+			Expect(payload.Name).To(Equal("test-export"))
+			Expect(payload.Namespace).To(Equal("default"))
+			Expect(payload.Resource.Resource).To(Equal("volumeexports"))
+		}
+	},
+		table.Entry("authoriser error", &testAuthorizer{allowed: false, reason: "", err: fmt.Errorf("Error")}, http.StatusInternalServerError, false),
+		table.Entry("authoriser not allowed", &testAuthorizer{allowed: false, reason: "bad person", err: nil}, http.StatusUnauthorized, false),
+		table.Entry("export possible", authorizeSuccess, http.StatusOK, true),
+	)
 })
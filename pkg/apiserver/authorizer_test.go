@@ -103,6 +103,15 @@ var _ = Describe("Authorizer test", func() {
 		Expect(authReview).ToNot(BeNil())
 	})
 
+	It("Generate access review for export tokens", func() {
+		app := newAuthorizor()
+		req := fakeRequest()
+		req.Request.URL.Path = "/apis/upload.cdi.kubevirt.io/v1beta1/namespaces/default/exporttokenrequests"
+		authReview, err := app.generateAccessReview(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(authReview.Spec.ResourceAttributes.Resource).To(Equal("exporttokenrequests"))
+	})
+
 	It("Generate access review path err group", func() {
 		app := newAuthorizor()
 		req := fakeRequest()
@@ -0,0 +1,142 @@
+/*
+ * This file is part of the CDI project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kvalidation "k8s.io/apimachinery/pkg/util/validation"
+	k8sfield "k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+)
+
+// cronFieldItem matches an item of a cron schedule field, i.e. "*", "5", "1-5", "*/10", "MON-FRI"
+var cronFieldItem = regexp.MustCompile(`^(\*|\?|[0-9A-Za-z]+(-[0-9A-Za-z]+)?)(/[0-9]+)?$`)
+
+var cronMacros = map[string]bool{
+	"@yearly":   true,
+	"@annually": true,
+	"@monthly":  true,
+	"@weekly":   true,
+	"@daily":    true,
+	"@midnight": true,
+	"@hourly":   true,
+}
+
+type dataImportCronValidatingWebhook struct {
+	dataVolumeValidatingWebhook
+}
+
+func (wh *dataImportCronValidatingWebhook) Admit(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	if err := validateDataImportCronResource(ar); err != nil {
+		return toAdmissionResponseError(err)
+	}
+
+	raw := ar.Request.Object.Raw
+	cron := cdiv1.DataImportCron{}
+
+	err := json.Unmarshal(raw, &cron)
+	if err != nil {
+		return toAdmissionResponseError(err)
+	}
+
+	causes := wh.validateDataImportCronSpec(ar.Request, k8sfield.NewPath("spec"), &cron.Spec)
+	if len(causes) > 0 {
+		klog.Infof("rejected DataImportCron admission")
+		return toRejectedAdmissionResponse(causes)
+	}
+
+	return allowedAdmissionResponse()
+}
+
+func (wh *dataImportCronValidatingWebhook) validateDataImportCronSpec(request *v1beta1.AdmissionRequest, field *k8sfield.Path, spec *cdiv1.DataImportCronSpec) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+
+	if spec.Template.Spec.Source.Registry == nil && spec.Template.Spec.Source.HTTP == nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("DataImportCron template source has to be registry or http"),
+			Field:   field.Child("template", "spec", "source").String(),
+		})
+		return causes
+	}
+	causes = wh.validateDataVolumeSpec(request, field.Child("template", "spec"), &spec.Template.Spec)
+	if len(causes) > 0 {
+		return causes
+	}
+
+	if err := validateCronSchedule(spec.Schedule); err != nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("Invalid schedule %q: %v", spec.Schedule, err),
+			Field:   field.Child("schedule").String(),
+		})
+	}
+	if spec.GarbageCollect != nil && *spec.GarbageCollect != cdiv1.DataImportCronGarbageCollectOutdated && *spec.GarbageCollect != cdiv1.DataImportCronGarbageCollectNever {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("Illegal GarbageCollect value: %s", *spec.GarbageCollect),
+			Field:   field.Child("garbageCollect").String(),
+		})
+	}
+	if spec.ImportsToKeep != nil && *spec.ImportsToKeep < 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("Illegal ImportsToKeep value: %d", *spec.ImportsToKeep),
+			Field:   field.Child("importsToKeep").String(),
+		})
+	}
+	if spec.ManagedDataSource != "" {
+		for _, msg := range kvalidation.IsDNS1123Subdomain(spec.ManagedDataSource) {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("Illegal ManagedDataSource name: %s", msg),
+				Field:   field.Child("managedDataSource").String(),
+			})
+		}
+	}
+	return causes
+}
+
+// validateCronSchedule checks the schedule is a standard five field cron expression or a predefined macro
+func validateCronSchedule(schedule string) error {
+	if cronMacros[schedule] {
+		return nil
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return fmt.Errorf("expected 5 fields, found %d", len(fields))
+	}
+	for _, f := range fields {
+		for _, item := range strings.Split(f, ",") {
+			if !cronFieldItem.MatchString(item) {
+				return fmt.Errorf("invalid field %q", f)
+			}
+		}
+	}
+	return nil
+}
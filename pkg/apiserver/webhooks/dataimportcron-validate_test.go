@@ -0,0 +1,128 @@
+/*
+ * This file is part of the CDI project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package webhooks
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+)
+
+var _ = Describe("Validating Webhook", func() {
+	Context("with DataImportCron admission review", func() {
+		It("should accept DataImportCron with registry source", func() {
+			cron := newDataImportCron(newRegistryDataVolume("testDV", "docker://quay.io/kubevirt/fedora:latest"))
+			resp := validateDataImportCronCreate(cron)
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("should accept DataImportCron with http source", func() {
+			cron := newDataImportCron(newHTTPDataVolume("testDV", "http://www.example.com/disk.img"))
+			resp := validateDataImportCronCreate(cron)
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("should reject DataImportCron with blank source", func() {
+			cron := newDataImportCron(newBlankDataVolume("testDV"))
+			resp := validateDataImportCronCreate(cron)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+
+		It("should reject DataImportCron with invalid template", func() {
+			cron := newDataImportCron(newHTTPDataVolume("testDV", "ftp://www.example.com/disk.img"))
+			resp := validateDataImportCronCreate(cron)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+
+		It("should reject DataImportCron with illegal garbage collection", func() {
+			cron := newDataImportCron(newHTTPDataVolume("testDV", "http://www.example.com/disk.img"))
+			garbageCollect := cdiv1.DataImportCronGarbageCollect("Sometimes")
+			cron.Spec.GarbageCollect = &garbageCollect
+			resp := validateDataImportCronCreate(cron)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+
+		It("should reject DataImportCron with negative imports to keep", func() {
+			cron := newDataImportCron(newHTTPDataVolume("testDV", "http://www.example.com/disk.img"))
+			importsToKeep := int32(-1)
+			cron.Spec.ImportsToKeep = &importsToKeep
+			resp := validateDataImportCronCreate(cron)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+
+		DescribeTable("should validate the schedule", func(schedule string, allowed bool) {
+			cron := newDataImportCron(newHTTPDataVolume("testDV", "http://www.example.com/disk.img"))
+			cron.Spec.Schedule = schedule
+			resp := validateDataImportCronCreate(cron)
+			Expect(resp.Allowed).To(Equal(allowed))
+		},
+			Entry("accept every minute", "* * * * *", true),
+			Entry("accept ranges, lists and steps", "*/15 0-6 1,15 * MON-FRI", true),
+			Entry("accept macros", "@daily", true),
+			Entry("reject empty schedule", "", false),
+			Entry("reject too few fields", "0 0 * *", false),
+			Entry("reject unknown macro", "@sometimes", false),
+			Entry("reject invalid characters", "0 0 * * $", false),
+		)
+	})
+})
+
+func newDataImportCron(template *cdiv1.DataVolume) *cdiv1.DataImportCron {
+	return &cdiv1.DataImportCron{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testCron",
+			Namespace: template.Namespace,
+		},
+		Spec: cdiv1.DataImportCronSpec{
+			Template: *template,
+			Schedule: "0 0 * * *",
+		},
+	}
+}
+
+func validateDataImportCronCreate(cron *cdiv1.DataImportCron, objects ...runtime.Object) *v1beta1.AdmissionResponse {
+	client, cdiClient := newFakeClients(objects...)
+	wh := NewDataImportCronValidatingWebhook(client, cdiClient)
+
+	cronBytes, _ := json.Marshal(cron)
+	ar := &v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			Operation: v1beta1.Create,
+			Resource: metav1.GroupVersionResource{
+				Group:    cdiv1.SchemeGroupVersion.Group,
+				Version:  cdiv1.SchemeGroupVersion.Version,
+				Resource: "dataimportcrons",
+			},
+			Object: runtime.RawExtension{
+				Raw: cronBytes,
+			},
+		},
+	}
+
+	return serve(ar, wh)
+}
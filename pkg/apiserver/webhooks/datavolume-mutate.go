@@ -25,12 +25,14 @@ import (
 
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	authv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sfield "k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
 	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned"
 	"kubevirt.io/containerized-data-importer/pkg/clone"
 	"kubevirt.io/containerized-data-importer/pkg/controller"
 	"kubevirt.io/containerized-data-importer/pkg/token"
@@ -38,6 +40,7 @@ import (
 
 type dataVolumeMutatingWebhook struct {
 	client         kubernetes.Interface
+	cdiClient      cdiclient.Interface
 	tokenGenerator token.Generator
 	proxy          clone.SubjectAccessReviewsProxy
 }
@@ -52,6 +55,12 @@ var (
 		Version:  "v1",
 		Resource: "persistentvolumeclaims",
 	}
+
+	snapshotTokenResource = metav1.GroupVersionResource{
+		Group:    "snapshot.storage.k8s.io",
+		Version:  "v1beta1",
+		Resource: "volumesnapshots",
+	}
 )
 
 func (p *sarProxy) Create(sar *authv1.SubjectAccessReview) (*authv1.SubjectAccessReview, error) {
@@ -81,12 +90,29 @@ func (wh *dataVolumeMutatingWebhook) Admit(ar admissionv1beta1.AdmissionReview)
 		targetName = ar.Request.Name
 	}
 
-	if pvcSource == nil {
+	sourceField := k8sfield.NewPath("spec", "source", "PVC", "namespace")
+	if dataVolume.Spec.SourceRef != nil {
+		var err error
+		if pvcSource, err = wh.sourceRefPVC(dataVolume.Spec.SourceRef, targetNamespace); err != nil {
+			return toAdmissionResponseError(err)
+		}
+		sourceField = k8sfield.NewPath("spec", "sourceRef")
+	}
+
+	var sourceNamespace, sourceName string
+	sourceResource := tokenResource
+	if pvcSource != nil {
+		sourceNamespace, sourceName = pvcSource.Namespace, pvcSource.Name
+	} else if snapshotSource := dataVolume.Spec.Source.Snapshot; snapshotSource != nil {
+		// the snapshot may be restored to a temporary PVC in its namespace and cloned, which is authorized like a PVC clone
+		sourceNamespace, sourceName = snapshotSource.Namespace, snapshotSource.Name
+		sourceResource = snapshotTokenResource
+		sourceField = k8sfield.NewPath("spec", "source", "snapshot", "namespace")
+	} else {
 		klog.V(3).Infof("DataVolume %s/%s not cloning", targetNamespace, targetName)
 		return allowedAdmissionResponse()
 	}
 
-	sourceNamespace, sourceName := pvcSource.Namespace, pvcSource.Name
 	if sourceNamespace == "" {
 		sourceNamespace = targetNamespace
 	}
@@ -113,7 +139,7 @@ func (wh *dataVolumeMutatingWebhook) Admit(ar admissionv1beta1.AdmissionReview)
 			{
 				Type:    metav1.CauseTypeFieldValueInvalid,
 				Message: reason,
-				Field:   k8sfield.NewPath("spec", "source", "PVC", "namespace").String(),
+				Field:   sourceField.String(),
 			},
 		}
 		return toRejectedAdmissionResponse(causes)
@@ -123,7 +149,7 @@ func (wh *dataVolumeMutatingWebhook) Admit(ar admissionv1beta1.AdmissionReview)
 		Operation: token.OperationClone,
 		Name:      sourceName,
 		Namespace: sourceNamespace,
-		Resource:  tokenResource,
+		Resource:  sourceResource,
 		Params: map[string]string{
 			"targetNamespace": targetNamespace,
 			"targetName":      targetName,
@@ -141,8 +167,38 @@ func (wh *dataVolumeMutatingWebhook) Admit(ar admissionv1beta1.AdmissionReview)
 	}
 
 	modifiedDataVolume.Annotations[controller.AnnCloneToken] = token
+	if dataVolume.Spec.SourceRef != nil {
+		// The controller clones the PVC the token was issued for instead of resolving the DataSource again
+		modifiedDataVolume.Annotations[controller.AnnSourceRefPVC] = sourceNamespace + "/" + sourceName
+	}
 
 	klog.V(3).Infof("Sending patch response...")
 
 	return toPatchResponse(dataVolume, modifiedDataVolume)
 }
+
+// sourceRefPVC returns the PVC currently referenced by the DataSource of sourceRef, nil if there is none.
+// The validating webhook rejects the DataVolumes with a missing or invalid DataSource.
+func (wh *dataVolumeMutatingWebhook) sourceRefPVC(sourceRef *cdiv1.DataVolumeSourceRef, namespace string) (*cdiv1.DataVolumeSourcePVC, error) {
+	if sourceRef.Kind != cdiv1.DataVolumeDataSource {
+		return nil, nil
+	}
+	if sourceRef.Namespace != nil && *sourceRef.Namespace != "" {
+		namespace = *sourceRef.Namespace
+	}
+	dataSource, err := wh.cdiClient.CdiV1beta1().DataSources(namespace).Get(context.TODO(), sourceRef.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			klog.V(3).Infof("DataSource %s/%s not found", namespace, sourceRef.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+	pvcSource := dataSource.Spec.Source.PVC
+	if pvcSource != nil && pvcSource.Namespace == "" {
+		// The PVC is in the namespace of the DataSource, like the controller resolves it
+		pvcSource = pvcSource.DeepCopy()
+		pvcSource.Namespace = namespace
+	}
+	return pvcSource, nil
+}
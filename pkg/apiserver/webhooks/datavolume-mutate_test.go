@@ -24,6 +24,7 @@ import (
 	"crypto/rsa"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -34,11 +35,12 @@ import (
 	authorization "k8s.io/api/authorization/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	fakeclient "k8s.io/client-go/kubernetes/fake"
 	k8stesting "k8s.io/client-go/testing"
 
 	cdicorev1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/controller"
+	"kubevirt.io/containerized-data-importer/pkg/token"
 )
 
 var _ = Describe("Mutating DataVolume Webhook", func() {
@@ -129,6 +131,164 @@ var _ = Describe("Mutating DataVolume Webhook", func() {
 			Expect(resp.Patch).To(BeNil())
 		})
 
+		It("should add a clone token for the PVC of a DataSource sourceRef", func() {
+			namespace := "testNamespace"
+			dataVolume := newDataSourceDataVolume("testDV", &namespace, "test")
+			dataSource := newDataSource(namespace, "test", "goldenNamespace", "golden")
+			dvBytes, _ := json.Marshal(&dataVolume)
+
+			ar := &v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Resource: metav1.GroupVersionResource{
+						Group:    cdicorev1.SchemeGroupVersion.Group,
+						Version:  cdicorev1.SchemeGroupVersion.Version,
+						Resource: "datavolumes",
+					},
+					Object: runtime.RawExtension{
+						Raw: dvBytes,
+					},
+				},
+			}
+
+			resp := mutateDVs(key, ar, true, dataSource)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patch).ToNot(BeNil())
+
+			var patchObjs []jsonpatch.Operation
+			err := json.Unmarshal(resp.Patch, &patchObjs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(patchObjs).Should(HaveLen(1))
+			Expect(patchObjs[0].Path).Should(Equal("/metadata/annotations"))
+
+			annotations := patchObjs[0].Value.(map[string]interface{})
+			tokenString := annotations[controller.AnnCloneToken].(string)
+			payload, err := token.NewValidator(common.CloneTokenIssuer, &key.PublicKey, time.Minute).Validate(tokenString)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(payload.Namespace).To(Equal("goldenNamespace"))
+			Expect(payload.Name).To(Equal("golden"))
+			Expect(annotations[controller.AnnSourceRefPVC]).To(Equal("goldenNamespace/golden"))
+		})
+
+		It("should add a clone token for the PVC of a DataSource in another namespace", func() {
+			namespace := "goldenNamespace"
+			dataVolume := newDataSourceDataVolume("testDV", &namespace, "test")
+			// The PVC has no namespace, it is in the namespace of the DataSource
+			dataSource := newDataSource(namespace, "test", "", "golden")
+			dvBytes, _ := json.Marshal(&dataVolume)
+
+			ar := &v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Resource: metav1.GroupVersionResource{
+						Group:    cdicorev1.SchemeGroupVersion.Group,
+						Version:  cdicorev1.SchemeGroupVersion.Version,
+						Resource: "datavolumes",
+					},
+					Object: runtime.RawExtension{
+						Raw: dvBytes,
+					},
+				},
+			}
+
+			resp := mutateDVs(key, ar, true, dataSource)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patch).ToNot(BeNil())
+
+			var patchObjs []jsonpatch.Operation
+			err := json.Unmarshal(resp.Patch, &patchObjs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(patchObjs).Should(HaveLen(1))
+
+			annotations := patchObjs[0].Value.(map[string]interface{})
+			tokenString := annotations[controller.AnnCloneToken].(string)
+			payload, err := token.NewValidator(common.CloneTokenIssuer, &key.PublicKey, time.Minute).Validate(tokenString)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(payload.Namespace).To(Equal("goldenNamespace"))
+			Expect(payload.Name).To(Equal("golden"))
+			Expect(annotations[controller.AnnSourceRefPVC]).To(Equal("goldenNamespace/golden"))
+			Expect(payload.Params["targetNamespace"]).To(Equal(dataVolume.Namespace))
+		})
+
+		It("should reject a DataSource sourceRef clone if not authorized", func() {
+			namespace := "testNamespace"
+			dataVolume := newDataSourceDataVolume("testDV", &namespace, "test")
+			dataSource := newDataSource(namespace, "test", "goldenNamespace", "golden")
+			dvBytes, _ := json.Marshal(&dataVolume)
+
+			ar := &v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Resource: metav1.GroupVersionResource{
+						Group:    cdicorev1.SchemeGroupVersion.Group,
+						Version:  cdicorev1.SchemeGroupVersion.Version,
+						Resource: "datavolumes",
+					},
+					Object: runtime.RawExtension{
+						Raw: dvBytes,
+					},
+				},
+			}
+
+			resp := mutateDVs(key, ar, false, dataSource)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+
+		It("should add a clone token for a snapshot source", func() {
+			dataVolume := newSnapshotDataVolume("testDV", "goldenNamespace", "golden")
+			dvBytes, _ := json.Marshal(&dataVolume)
+
+			ar := &v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Resource: metav1.GroupVersionResource{
+						Group:    cdicorev1.SchemeGroupVersion.Group,
+						Version:  cdicorev1.SchemeGroupVersion.Version,
+						Resource: "datavolumes",
+					},
+					Object: runtime.RawExtension{
+						Raw: dvBytes,
+					},
+				},
+			}
+
+			resp := mutateDVs(key, ar, true)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patch).ToNot(BeNil())
+
+			var patchObjs []jsonpatch.Operation
+			err := json.Unmarshal(resp.Patch, &patchObjs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(patchObjs).Should(HaveLen(1))
+			Expect(patchObjs[0].Path).Should(Equal("/metadata/annotations"))
+
+			annotations := patchObjs[0].Value.(map[string]interface{})
+			tokenString := annotations[controller.AnnCloneToken].(string)
+			payload, err := token.NewValidator(common.CloneTokenIssuer, &key.PublicKey, time.Minute).Validate(tokenString)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(payload.Namespace).To(Equal("goldenNamespace"))
+			Expect(payload.Name).To(Equal("golden"))
+			Expect(annotations).ToNot(HaveKey(controller.AnnSourceRefPVC))
+			Expect(payload.Resource.Resource).To(Equal("volumesnapshots"))
+		})
+
+		It("should reject a snapshot source if not authorized", func() {
+			dataVolume := newSnapshotDataVolume("testDV", "goldenNamespace", "golden")
+			dvBytes, _ := json.Marshal(&dataVolume)
+
+			ar := &v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Resource: metav1.GroupVersionResource{
+						Group:    cdicorev1.SchemeGroupVersion.Group,
+						Version:  cdicorev1.SchemeGroupVersion.Version,
+						Resource: "datavolumes",
+					},
+					Object: runtime.RawExtension{
+						Raw: dvBytes,
+					},
+				},
+			}
+
+			resp := mutateDVs(key, ar, false)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+
 		DescribeTable("should", func(srcNamespace string) {
 			dataVolume := newPVCDataVolume("testDV", srcNamespace, "test")
 			dvBytes, _ := json.Marshal(&dataVolume)
@@ -165,8 +325,8 @@ var _ = Describe("Mutating DataVolume Webhook", func() {
 	})
 })
 
-func mutateDVs(key *rsa.PrivateKey, ar *v1beta1.AdmissionReview, isAuthorized bool) *v1beta1.AdmissionResponse {
-	client := fakeclient.NewSimpleClientset()
+func mutateDVs(key *rsa.PrivateKey, ar *v1beta1.AdmissionReview, isAuthorized bool, objects ...runtime.Object) *v1beta1.AdmissionResponse {
+	client, cdiClient := newFakeClients(objects...)
 	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
 		if action.GetResource().Resource != "subjectaccessreviews" {
 			return false, nil, nil
@@ -180,6 +340,6 @@ func mutateDVs(key *rsa.PrivateKey, ar *v1beta1.AdmissionReview, isAuthorized bo
 		}
 		return true, sar, nil
 	})
-	wh := NewDataVolumeMutatingWebhook(client, key)
+	wh := NewDataVolumeMutatingWebhook(client, cdiClient, token.StaticSigningKey(key))
 	return serve(ar, wh)
 }
@@ -37,11 +37,15 @@ import (
 	"k8s.io/klog/v2"
 
 	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned"
 	"kubevirt.io/containerized-data-importer/pkg/controller"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+	"kubevirt.io/containerized-data-importer/pkg/util/clonecodec"
 )
 
 type dataVolumeValidatingWebhook struct {
-	client kubernetes.Interface
+	client    kubernetes.Interface
+	cdiClient cdiclient.Interface
 }
 
 func validateSourceURL(sourceURL string) string {
@@ -58,6 +62,35 @@ func validateSourceURL(sourceURL string) string {
 	return ""
 }
 
+func validateChecksum(field *k8sfield.Path, spec *cdiv1.DataVolumeSpec) []metav1.StatusCause {
+	var checksum string
+	var checksumField *k8sfield.Path
+	if spec.Source.HTTP != nil {
+		checksum = spec.Source.HTTP.Checksum
+		checksumField = field.Child("source", "HTTP", "checksum")
+	} else if spec.Source.S3 != nil {
+		checksum = spec.Source.S3.Checksum
+		checksumField = field.Child("source", "S3", "checksum")
+	} else if spec.Source.Registry != nil {
+		checksum = spec.Source.Registry.Checksum
+		checksumField = field.Child("source", "Registry", "checksum")
+	} else if spec.Source.Upload != nil {
+		checksum = spec.Source.Upload.Checksum
+		checksumField = field.Child("source", "Upload", "checksum")
+	}
+	if checksum == "" {
+		return nil
+	}
+	if err := util.ValidateChecksum(checksum); err != nil {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s is not valid: %v", checksumField.String(), err),
+			Field:   checksumField.String(),
+		}}
+	}
+	return nil
+}
+
 func validateDataVolumeName(name string) []metav1.StatusCause {
 	var causes []metav1.StatusCause
 	if len(name) > kvalidation.DNS1123SubdomainMaxLength {
@@ -82,7 +115,19 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *v1beta1.A
 			numberOfSources++
 		}
 	}
-	if numberOfSources == 0 {
+	if spec.SourceRef != nil {
+		if numberOfSources > 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("Data volume source and sourceRef are mutually exclusive"),
+				Field:   field.Child("sourceRef").String(),
+			})
+			return causes
+		}
+		if causes := wh.validateSourceRef(request, field, spec); causes != nil {
+			return causes
+		}
+	} else if numberOfSources == 0 {
 		causes = append(causes, metav1.StatusCause{
 			Type:    metav1.CauseTypeFieldValueInvalid,
 			Message: fmt.Sprintf("Missing Data volume source"),
@@ -155,6 +200,54 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *v1beta1.A
 		return causes
 	}
 
+	if (spec.Source.HTTP != nil && spec.Source.HTTP.OVA != nil) || (spec.Source.S3 != nil && spec.Source.S3.OVA != nil) {
+		if spec.ContentType != "" && string(spec.ContentType) != string(cdiv1.DataVolumeKubeVirt) {
+			sourceType = field.Child("contentType").String()
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("ContentType must be " + string(cdiv1.DataVolumeKubeVirt) + " when importing an OVA"),
+				Field:   sourceType,
+			})
+			return causes
+		}
+	}
+
+	var concurrency *int32
+	if spec.Source.HTTP != nil {
+		concurrency = spec.Source.HTTP.Concurrency
+		sourceType = field.Child("source", "HTTP", "concurrency").String()
+	} else if spec.Source.S3 != nil {
+		concurrency = spec.Source.S3.Concurrency
+		sourceType = field.Child("source", "S3", "concurrency").String()
+	}
+	if concurrency != nil && *concurrency < 1 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s must be at least 1, got %d", sourceType, *concurrency),
+			Field:   sourceType,
+		})
+		return causes
+	}
+
+	if codec := spec.CloneCodec; codec != nil {
+		if err := clonecodec.Validate(string(codec.Name)); err != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: err.Error(),
+				Field:   field.Child("cloneCodec", "name").String(),
+			})
+			return causes
+		}
+		if codec.Level != nil && (codec.Name != cdiv1.CloneCodecZstd || *codec.Level < 1 || *codec.Level > 22) {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s must be between 1 and 22, and is only supported by zstd", field.Child("cloneCodec", "level").String()),
+				Field:   field.Child("cloneCodec", "level").String(),
+			})
+			return causes
+		}
+	}
+
 	if spec.Source.Imageio != nil {
 		if spec.Source.Imageio.SecretRef == "" || spec.Source.Imageio.CertConfigMap == "" || spec.Source.Imageio.DiskID == "" {
 			causes = append(causes, metav1.StatusCause{
@@ -177,6 +270,28 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *v1beta1.A
 		}
 	}
 
+	if causes := validateChecksum(field, spec); causes != nil {
+		return causes
+	}
+
+	if spec.PVC != nil && spec.Storage != nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("Data volume PVC and storage are mutually exclusive"),
+			Field:   field.Child("storage").String(),
+		})
+		return causes
+	}
+	pvcField, pvcSpec := getTargetPvcSpec(field, spec)
+	if pvcSpec == nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("Missing Data volume PVC"),
+			Field:   field.Child("PVC").String(),
+		})
+		return causes
+	}
+
 	if spec.Source.PVC != nil {
 		if spec.Source.PVC.Namespace == "" || spec.Source.PVC.Name == "" {
 			causes = append(causes, metav1.StatusCause{
@@ -188,61 +303,59 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *v1beta1.A
 		}
 
 		if request.Operation == v1beta1.Create {
-			sourcePVC, err := wh.client.CoreV1().PersistentVolumeClaims(spec.Source.PVC.Namespace).Get(context.TODO(), spec.Source.PVC.Name, metav1.GetOptions{})
-			if err != nil {
-				if k8serrors.IsNotFound(err) {
-					causes = append(causes, metav1.StatusCause{
-						Type:    metav1.CauseTypeFieldValueNotFound,
-						Message: fmt.Sprintf("Source PVC %s/%s doesn't exist", spec.Source.PVC.Namespace, spec.Source.PVC.Name),
-						Field:   field.Child("source", "PVC").String(),
-					})
-					return causes
-				}
-			}
-			err = controller.ValidateCanCloneSourceAndTargetSpec(&sourcePVC.Spec, spec.PVC)
-			if err != nil {
-				causes = append(causes, metav1.StatusCause{
-					Type:    metav1.CauseTypeFieldValueInvalid,
-					Message: err.Error(),
-					Field:   field.Child("PVC").String(),
-				})
+			if causes := wh.validateCloneSourcePVC(pvcField, field.Child("source", "PVC"), spec.Source.PVC, pvcSpec); causes != nil {
 				return causes
 			}
 		}
 	}
 
-	if spec.PVC == nil {
-		causes = append(causes, metav1.StatusCause{
-			Type:    metav1.CauseTypeFieldValueInvalid,
-			Message: fmt.Sprintf("Missing Data volume PVC"),
-			Field:   field.Child("PVC").String(),
-		})
-		return causes
+	if spec.Source.Snapshot != nil {
+		if spec.Source.Snapshot.Namespace == "" || spec.Source.Snapshot.Name == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s source snapshot is not valid", field.Child("source", "snapshot").String()),
+				Field:   field.Child("source", "snapshot").String(),
+			})
+			return causes
+		}
 	}
-	if pvcSize, ok := spec.PVC.Resources.Requests["storage"]; ok {
+
+	if pvcSize, ok := pvcSpec.Resources.Requests["storage"]; ok {
 		if pvcSize.IsZero() || pvcSize.Value() < 0 {
 			causes = append(causes, metav1.StatusCause{
 				Type:    metav1.CauseTypeFieldValueInvalid,
 				Message: fmt.Sprintf("PVC size can't be equal or less than zero"),
-				Field:   field.Child("PVC", "resources", "requests", "size").String(),
+				Field:   pvcField.Child("resources", "requests", "size").String(),
 			})
 			return causes
 		}
-	} else {
+	} else if spec.Storage == nil {
 		causes = append(causes, metav1.StatusCause{
 			Type:    metav1.CauseTypeFieldValueInvalid,
 			Message: fmt.Sprintf("PVC size is missing"),
-			Field:   field.Child("PVC", "resources", "requests", "size").String(),
+			Field:   pvcField.Child("resources", "requests", "size").String(),
+		})
+		return causes
+	} else if spec.Source.HTTP == nil && spec.Source.S3 == nil && spec.Source.PVC == nil && spec.Source.Snapshot == nil && spec.SourceRef == nil {
+		// the DataVolume controller infers the size of these sources only
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("Storage size is missing, it can only be inferred for HTTP, S3, PVC and snapshot sources"),
+			Field:   pvcField.Child("resources", "requests", "size").String(),
 		})
 		return causes
 	}
 
-	accessModes := spec.PVC.AccessModes
+	accessModes := pvcSpec.AccessModes
 	if len(accessModes) == 0 {
+		// the DataVolume controller fills in the access modes recommended by the StorageProfile
+		if len(wh.getStorageProfileAccessModes(pvcSpec.StorageClassName)) > 0 {
+			return causes
+		}
 		causes = append(causes, metav1.StatusCause{
 			Type:    metav1.CauseTypeFieldValueInvalid,
 			Message: fmt.Sprintf("Required value: at least 1 access mode is required"),
-			Field:   field.Child("PVC", "accessModes").String(),
+			Field:   pvcField.Child("accessModes").String(),
 		})
 		return causes
 	}
@@ -250,7 +363,7 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *v1beta1.A
 		causes = append(causes, metav1.StatusCause{
 			Type:    metav1.CauseTypeFieldValueInvalid,
 			Message: fmt.Sprintf("PVC multiple accessModes"),
-			Field:   field.Child("PVC", "accessModes").String(),
+			Field:   pvcField.Child("accessModes").String(),
 		})
 		return causes
 	}
@@ -259,13 +372,150 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *v1beta1.A
 		causes = append(causes, metav1.StatusCause{
 			Type:    metav1.CauseTypeFieldValueInvalid,
 			Message: fmt.Sprintf("Unsupported value: \"%s\": supported values: \"ReadOnlyMany\", \"ReadWriteMany\", \"ReadWriteOnce\"", string(accessModes[0])),
-			Field:   field.Child("PVC", "accessModes").String(),
+			Field:   pvcField.Child("accessModes").String(),
 		})
 		return causes
 	}
 	return causes
 }
 
+// getTargetPvcSpec returns the field and the spec of the PVC requested by the DataVolume, either through its PVC or
+// its storage spec
+func getTargetPvcSpec(field *k8sfield.Path, spec *cdiv1.DataVolumeSpec) (*k8sfield.Path, *v1.PersistentVolumeClaimSpec) {
+	if spec.Storage != nil {
+		return field.Child("storage"), controller.StorageSpecToPvcSpec(spec.Storage)
+	}
+	return field.Child("PVC"), spec.PVC
+}
+
+// getStorageProfileAccessModes returns the access modes recommended by the StorageProfile of the storage class,
+// or of the default storage class if no name is given
+func (wh *dataVolumeValidatingWebhook) getStorageProfileAccessModes(storageClassName *string) []v1.PersistentVolumeAccessMode {
+	if storageClassName == nil {
+		storageClasses, err := wh.client.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			klog.V(3).Infof("Unable to list storage classes: %v", err)
+			return nil
+		}
+		for i := range storageClasses.Items {
+			if storageClasses.Items[i].Annotations[controller.AnnDefaultStorageClass] == "true" {
+				storageClassName = &storageClasses.Items[i].Name
+				break
+			}
+		}
+		if storageClassName == nil {
+			return nil
+		}
+	}
+	storageProfile, err := wh.cdiClient.CdiV1beta1().StorageProfiles().Get(context.TODO(), *storageClassName, metav1.GetOptions{})
+	if err != nil || len(storageProfile.Status.ClaimPropertySets) == 0 {
+		return nil
+	}
+	return storageProfile.Status.ClaimPropertySets[0].AccessModes
+}
+
+// validateCloneSourcePVC checks that the source PVC exists and can be cloned to a PVC of the target spec, a target
+// without storage request gets the size of the source
+func (wh *dataVolumeValidatingWebhook) validateCloneSourcePVC(targetField, sourceField *k8sfield.Path, source *cdiv1.DataVolumeSourcePVC, targetSpec *v1.PersistentVolumeClaimSpec) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+	sourcePVC, err := wh.client.CoreV1().PersistentVolumeClaims(source.Namespace).Get(context.TODO(), source.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueNotFound,
+				Message: fmt.Sprintf("Source PVC %s/%s doesn't exist", source.Namespace, source.Name),
+				Field:   sourceField.String(),
+			})
+			return causes
+		}
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: err.Error(),
+			Field:   sourceField.String(),
+		})
+		return causes
+	}
+	if _, ok := targetSpec.Resources.Requests[v1.ResourceStorage]; !ok {
+		targetSpec = targetSpec.DeepCopy()
+		if targetSpec.Resources.Requests == nil {
+			targetSpec.Resources.Requests = v1.ResourceList{}
+		}
+		targetSpec.Resources.Requests[v1.ResourceStorage] = sourcePVC.Spec.Resources.Requests[v1.ResourceStorage]
+	}
+	err = controller.ValidateCanCloneSourceAndTargetSpec(&sourcePVC.Spec, targetSpec)
+	if err != nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: err.Error(),
+			Field:   targetField.String(),
+		})
+		return causes
+	}
+	return nil
+}
+
+// validateSourceRef checks the DataSource referenced by the DataVolume, and on creation, that its PVC can be cloned
+func (wh *dataVolumeValidatingWebhook) validateSourceRef(request *v1beta1.AdmissionRequest, field *k8sfield.Path, spec *cdiv1.DataVolumeSpec) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+	sourceRefField := field.Child("sourceRef")
+	if spec.SourceRef.Kind != cdiv1.DataVolumeDataSource {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("Unsupported sourceRef kind %s, currently only %s is supported", spec.SourceRef.Kind, cdiv1.DataVolumeDataSource),
+			Field:   sourceRefField.Child("kind").String(),
+		})
+		return causes
+	}
+	if spec.SourceRef.Name == "" {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s name is empty", sourceRefField.String()),
+			Field:   sourceRefField.Child("name").String(),
+		})
+		return causes
+	}
+	targetField, targetSpec := getTargetPvcSpec(field, spec)
+	if request.Operation != v1beta1.Create || targetSpec == nil {
+		return nil
+	}
+	namespace := request.Namespace
+	if spec.SourceRef.Namespace != nil && *spec.SourceRef.Namespace != "" {
+		namespace = *spec.SourceRef.Namespace
+	}
+	dataSource, err := wh.cdiClient.CdiV1beta1().DataSources(namespace).Get(context.TODO(), spec.SourceRef.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueNotFound,
+				Message: fmt.Sprintf("DataSource %s/%s doesn't exist", namespace, spec.SourceRef.Name),
+				Field:   sourceRefField.String(),
+			})
+			return causes
+		}
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: err.Error(),
+			Field:   sourceRefField.String(),
+		})
+		return causes
+	}
+	if dataSource.Spec.Source.PVC == nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("DataSource %s/%s has no source", namespace, spec.SourceRef.Name),
+			Field:   sourceRefField.String(),
+		})
+		return causes
+	}
+	pvcSource := dataSource.Spec.Source.PVC
+	if pvcSource.Namespace == "" {
+		// The PVC is in the namespace of the DataSource, like the controller resolves it
+		pvcSource = pvcSource.DeepCopy()
+		pvcSource.Namespace = namespace
+	}
+	return wh.validateCloneSourcePVC(targetField, sourceRefField, pvcSource, targetSpec)
+}
+
 func (wh *dataVolumeValidatingWebhook) Admit(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
 	if err := validateDataVolumeResource(ar); err != nil {
 		return toAdmissionResponseError(err)
@@ -27,17 +27,20 @@ import (
 	"net/http/httptest"
 
 	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	"k8s.io/api/admission/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	k8sv1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	fakeclient "k8s.io/client-go/kubernetes/fake"
 
 	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	cdiclientfake "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned/fake"
 )
 
 var _ = Describe("Validating Webhook", func() {
@@ -102,6 +105,68 @@ var _ = Describe("Validating Webhook", func() {
 			Expect(resp.Allowed).To(Equal(false))
 		})
 
+		It("should accept DataVolume with DataSource sourceRef on create", func() {
+			namespace := "testNamespace"
+			dataVolume := newDataSourceDataVolume("testDV", &namespace, "test")
+			dataSource := newDataSource(namespace, "test", "goldenNamespace", "golden")
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "golden",
+					Namespace: "goldenNamespace",
+				},
+				Spec: *dataVolume.Spec.PVC,
+			}
+			resp := validateDataVolumeCreate(dataVolume, dataSource, pvc)
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should look up the PVC of a DataSource in another namespace in the DataSource namespace", func() {
+			namespace := "goldenNamespace"
+			dataVolume := newDataSourceDataVolume("testDV", &namespace, "test")
+			dataSource := newDataSource(namespace, "test", "", "golden")
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "golden",
+					Namespace: namespace,
+				},
+				Spec: *dataVolume.Spec.PVC,
+			}
+			resp := validateDataVolumeCreate(dataVolume, dataSource, pvc)
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should reject DataVolume with DataSource sourceRef on create if DataSource does not exist", func() {
+			namespace := "testNamespace"
+			dataVolume := newDataSourceDataVolume("testDV", &namespace, "test")
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should reject DataVolume with DataSource sourceRef on create if the PVC does not exist", func() {
+			namespace := "testNamespace"
+			dataVolume := newDataSourceDataVolume("testDV", &namespace, "test")
+			dataSource := newDataSource(namespace, "test", "goldenNamespace", "golden")
+			resp := validateDataVolumeCreate(dataVolume, dataSource)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should reject DataVolume with an unsupported sourceRef kind on create", func() {
+			namespace := "testNamespace"
+			dataVolume := newDataSourceDataVolume("testDV", &namespace, "test")
+			dataVolume.Spec.SourceRef.Kind = "PersistentVolumeClaim"
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should reject DataVolume with both source and sourceRef on create", func() {
+			namespace := "testNamespace"
+			dataVolume := newDataSourceDataVolume("testDV", &namespace, "test")
+			dataVolume.Spec.Source.HTTP = &cdiv1.DataVolumeSourceHTTP{URL: "http://www.example.com"}
+			dataSource := newDataSource(namespace, "test", "goldenNamespace", "golden")
+			resp := validateDataVolumeCreate(dataVolume, dataSource)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
 		It("should reject invalid DataVolume source PVC namespace on create", func() {
 			dataVolume := newPVCDataVolume("testDV", "", "test")
 			resp := validateDataVolumeCreate(dataVolume)
@@ -131,6 +196,64 @@ var _ = Describe("Validating Webhook", func() {
 			Expect(resp.Allowed).To(Equal(false))
 		})
 
+		It("should accept DataVolume with a valid checksum on create", func() {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com")
+			dataVolume.Spec.Source.HTTP.Checksum = "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should reject DataVolume with an invalid checksum on create", func() {
+			dataVolume := newRegistryDataVolume("testDV", "docker://registry:5000/test")
+			dataVolume.Spec.Source.Registry.Checksum = "crc32:0d4a1185"
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should accept an OVA DataVolume on create", func() {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com/appliance.ova")
+			dataVolume.Spec.Source.HTTP.OVA = &cdiv1.DataVolumeSourceOVA{Disk: "1"}
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should reject an OVA DataVolume with archive content type on create", func() {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com/appliance.ova")
+			dataVolume.Spec.Source.HTTP.OVA = &cdiv1.DataVolumeSourceOVA{}
+			dataVolume.Spec.ContentType = cdiv1.DataVolumeArchive
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should accept a DataVolume downloading with parallel requests on create", func() {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com/disk.img")
+			concurrency := int32(4)
+			dataVolume.Spec.Source.HTTP.Concurrency = &concurrency
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should reject a DataVolume with a concurrency below 1 on create", func() {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com/disk.img")
+			concurrency := int32(0)
+			dataVolume.Spec.Source.HTTP.Concurrency = &concurrency
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		table.DescribeTable("should validate the clone codec on create", func(name cdiv1.CloneCodecName, level *int32, allowed bool) {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com/disk.img")
+			dataVolume.Spec.CloneCodec = &cdiv1.CloneCodec{Name: name, Level: level}
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(allowed))
+		},
+			table.Entry("accepting zstd with a level", cdiv1.CloneCodecZstd, &[]int32{19}[0], true),
+			table.Entry("accepting no compression", cdiv1.CloneCodecNone, nil, true),
+			table.Entry("rejecting an unknown codec", cdiv1.CloneCodecName("lz4"), nil, false),
+			table.Entry("rejecting a level out of range", cdiv1.CloneCodecZstd, &[]int32{23}[0], false),
+			table.Entry("rejecting a level for snappy", cdiv1.CloneCodecSnappy, &[]int32{3}[0], false),
+		)
+
 		It("should reject DataVolume with multiple sources on create", func() {
 			dataVolume := newDataVolumeWithMultipleSources("testDV")
 			resp := validateDataVolumeCreate(dataVolume)
@@ -149,6 +272,95 @@ var _ = Describe("Validating Webhook", func() {
 			Expect(resp.Allowed).To(Equal(false))
 		})
 
+		It("should reject DataVolume without accessModes if there is no StorageProfile", func() {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com")
+			dataVolume.Spec.PVC.AccessModes = nil
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should accept DataVolume without accessModes if the StorageProfile of the storage class recommends them", func() {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com")
+			dataVolume.Spec.PVC.AccessModes = nil
+			storageClassName := "test-sc"
+			dataVolume.Spec.PVC.StorageClassName = &storageClassName
+			resp := validateDataVolumeCreate(dataVolume, newStorageProfile(storageClassName, corev1.ReadWriteMany))
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should accept DataVolume without accessModes if the StorageProfile of the default storage class recommends them", func() {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com")
+			dataVolume.Spec.PVC.AccessModes = nil
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "default-sc",
+					Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+				},
+			}
+			resp := validateDataVolumeCreate(dataVolume, storageClass, newStorageProfile("default-sc", corev1.ReadWriteOnce))
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should reject DataVolume without accessModes if the StorageProfile has no recommendation", func() {
+			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com")
+			dataVolume.Spec.PVC.AccessModes = nil
+			storageClassName := "test-sc"
+			dataVolume.Spec.PVC.StorageClassName = &storageClassName
+			resp := validateDataVolumeCreate(dataVolume, &cdiv1.StorageProfile{ObjectMeta: metav1.ObjectMeta{Name: storageClassName}})
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should accept DataVolume with storage without size and HTTP source", func() {
+			dataVolume := newStorageDataVolume(newHTTPDataVolume("testDV", "http://www.example.com"))
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should accept DataVolume with storage without size and PVC source", func() {
+			dataVolume := newStorageDataVolume(newPVCDataVolume("testDV", "testNamespace", "test"))
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "testNamespace",
+				},
+				Spec: *newPVCSpec(pvcSizeDefault),
+			}
+			resp := validateDataVolumeCreate(dataVolume, pvc)
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should accept DataVolume with storage without size and snapshot source", func() {
+			dataVolume := newStorageDataVolume(newSnapshotDataVolume("testDV", "testNamespace", "test"))
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(true))
+		})
+
+		It("should reject DataVolume with snapshot source without name", func() {
+			dataVolume := newSnapshotDataVolume("testDV", "testNamespace", "")
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should reject DataVolume with storage without size and Blank source", func() {
+			dataVolume := newStorageDataVolume(newBlankDataVolume("testDV"))
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should reject DataVolume with storage size 0", func() {
+			dataVolume := newStorageDataVolume(newHTTPDataVolume("testDV", "http://www.example.com"))
+			dataVolume.Spec.Storage.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("0")}
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
+		It("should reject DataVolume with both PVC and storage", func() {
+			dataVolume := newStorageDataVolume(newHTTPDataVolume("testDV", "http://www.example.com"))
+			dataVolume.Spec.PVC = newPVCSpec(pvcSizeDefault)
+			resp := validateDataVolumeCreate(dataVolume)
+			Expect(resp.Allowed).To(Equal(false))
+		})
+
 		It("should accept DataVolume with Blank source and no content type", func() {
 			dataVolume := newBlankDataVolume("blank")
 			resp := validateDataVolumeCreate(dataVolume)
@@ -351,6 +563,66 @@ func newPVCDataVolume(name, pvcNamespace, pvcName string) *cdiv1.DataVolume {
 	return newDataVolume(name, pvcSource, pvc)
 }
 
+func newSnapshotDataVolume(name, snapshotNamespace, snapshotName string) *cdiv1.DataVolume {
+	snapshotSource := cdiv1.DataVolumeSource{
+		Snapshot: &cdiv1.DataVolumeSourceSnapshot{
+			Namespace: snapshotNamespace,
+			Name:      snapshotName,
+		},
+	}
+	pvc := newPVCSpec(pvcSizeDefault)
+	return newDataVolume(name, snapshotSource, pvc)
+}
+
+func newDataSourceDataVolume(name string, namespace *string, dataSourceName string) *cdiv1.DataVolume {
+	dv := newDataVolume(name, cdiv1.DataVolumeSource{}, newPVCSpec(pvcSizeDefault))
+	dv.Spec.SourceRef = &cdiv1.DataVolumeSourceRef{
+		Kind:      cdiv1.DataVolumeDataSource,
+		Namespace: namespace,
+		Name:      dataSourceName,
+	}
+	return dv
+}
+
+func newDataSource(namespace, name, pvcNamespace, pvcName string) *cdiv1.DataSource {
+	return &cdiv1.DataSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: cdiv1.DataSourceSpec{
+			Source: cdiv1.DataSourceSource{
+				PVC: &cdiv1.DataVolumeSourcePVC{
+					Namespace: pvcNamespace,
+					Name:      pvcName,
+				},
+			},
+		},
+	}
+}
+
+func newStorageProfile(name string, accessMode corev1.PersistentVolumeAccessMode) *cdiv1.StorageProfile {
+	return &cdiv1.StorageProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: cdiv1.StorageProfileStatus{
+			ClaimPropertySets: []cdiv1.ClaimPropertySet{
+				{AccessModes: []corev1.PersistentVolumeAccessMode{accessMode}},
+			},
+		},
+	}
+}
+
+// newStorageDataVolume moves the access modes of the DataVolume PVC to a storage spec without size
+func newStorageDataVolume(dv *cdiv1.DataVolume) *cdiv1.DataVolume {
+	dv.Spec.Storage = &cdiv1.StorageSpec{
+		AccessModes: dv.Spec.PVC.AccessModes,
+	}
+	dv.Spec.PVC = nil
+	return dv
+}
+
 func newDataVolumeWithEmptyPVCSpec(name, url string) *cdiv1.DataVolume {
 
 	httpSource := cdiv1.DataVolumeSource{
@@ -420,9 +692,23 @@ func newPVCSpec(sizeValue int64) *corev1.PersistentVolumeClaimSpec {
 	return pvc
 }
 
+// newFakeClients returns a kubernetes client with the kubernetes objects and a CDI client with the CDI objects
+func newFakeClients(objects ...runtime.Object) (*fakeclient.Clientset, *cdiclientfake.Clientset) {
+	var k8sObjects, cdiObjects []runtime.Object
+	for _, obj := range objects {
+		switch obj.(type) {
+		case *cdiv1.DataSource, *cdiv1.StorageProfile:
+			cdiObjects = append(cdiObjects, obj)
+		default:
+			k8sObjects = append(k8sObjects, obj)
+		}
+	}
+	return fakeclient.NewSimpleClientset(k8sObjects...), cdiclientfake.NewSimpleClientset(cdiObjects...)
+}
+
 func validateDataVolumeCreate(dv *cdiv1.DataVolume, objects ...runtime.Object) *v1beta1.AdmissionResponse {
-	client := fakeclient.NewSimpleClientset(objects...)
-	wh := NewDataVolumeValidatingWebhook(client)
+	client, cdiClient := newFakeClients(objects...)
+	wh := NewDataVolumeValidatingWebhook(client, cdiClient)
 
 	dvBytes, _ := json.Marshal(dv)
 	ar := &v1beta1.AdmissionReview{
@@ -443,8 +729,8 @@ func validateDataVolumeCreate(dv *cdiv1.DataVolume, objects ...runtime.Object) *
 }
 
 func validateAdmissionReview(ar *v1beta1.AdmissionReview, objects ...runtime.Object) *v1beta1.AdmissionResponse {
-	client := fakeclient.NewSimpleClientset(objects...)
-	wh := NewDataVolumeValidatingWebhook(client)
+	client, cdiClient := newFakeClients(objects...)
+	wh := NewDataVolumeValidatingWebhook(client, cdiClient)
 	return serve(ar, wh)
 }
 
@@ -20,7 +20,6 @@
 package webhooks
 
 import (
-	"crypto/rsa"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -52,14 +51,19 @@ type admissionHandler struct {
 }
 
 // NewDataVolumeValidatingWebhook creates a new DataVolumeValidation webhook
-func NewDataVolumeValidatingWebhook(client kubernetes.Interface) http.Handler {
-	return newAdmissionHandler(&dataVolumeValidatingWebhook{client: client})
+func NewDataVolumeValidatingWebhook(client kubernetes.Interface, cdiClient cdiclient.Interface) http.Handler {
+	return newAdmissionHandler(&dataVolumeValidatingWebhook{client: client, cdiClient: cdiClient})
 }
 
 // NewDataVolumeMutatingWebhook creates a new DataVolumeMutation webhook
-func NewDataVolumeMutatingWebhook(client kubernetes.Interface, key *rsa.PrivateKey) http.Handler {
-	generator := newCloneTokenGenerator(key)
-	return newAdmissionHandler(&dataVolumeMutatingWebhook{client: client, tokenGenerator: generator, proxy: &sarProxy{client: client}})
+func NewDataVolumeMutatingWebhook(client kubernetes.Interface, cdiClient cdiclient.Interface, signingKeys token.SigningKeySource) http.Handler {
+	generator := newCloneTokenGenerator(signingKeys)
+	return newAdmissionHandler(&dataVolumeMutatingWebhook{client: client, cdiClient: cdiClient, tokenGenerator: generator, proxy: &sarProxy{client: client}})
+}
+
+// NewDataImportCronValidatingWebhook creates a new DataImportCron validating webhook
+func NewDataImportCronValidatingWebhook(client kubernetes.Interface, cdiClient cdiclient.Interface) http.Handler {
+	return newAdmissionHandler(&dataImportCronValidatingWebhook{dataVolumeValidatingWebhook{client: client, cdiClient: cdiClient}})
 }
 
 // NewCDIValidatingWebhook creates a new CDI validating webhook
@@ -67,8 +71,8 @@ func NewCDIValidatingWebhook(client cdiclient.Interface) http.Handler {
 	return newAdmissionHandler(&cdiValidatingWebhook{client: client})
 }
 
-func newCloneTokenGenerator(key *rsa.PrivateKey) token.Generator {
-	return token.NewGenerator(common.CloneTokenIssuer, key, 5*time.Minute)
+func newCloneTokenGenerator(signingKeys token.SigningKeySource) token.Generator {
+	return token.NewRotatingGenerator(common.CloneTokenIssuer, signingKeys, 5*time.Minute)
 }
 
 func newAdmissionHandler(a Admitter) http.Handler {
@@ -182,6 +186,20 @@ func validateDataVolumeResource(ar v1beta1.AdmissionReview) error {
 	return fmt.Errorf("expect resource to be '%s'", resources[0].Resource)
 }
 
+func validateDataImportCronResource(ar v1beta1.AdmissionReview) error {
+	resource := metav1.GroupVersionResource{
+		Group:    cdiv1.SchemeGroupVersion.Group,
+		Version:  cdiv1.SchemeGroupVersion.Version,
+		Resource: "dataimportcrons",
+	}
+	if ar.Request.Resource == resource {
+		return nil
+	}
+
+	klog.Errorf("resource is %s but request is: %s", resource, ar.Request.Resource)
+	return fmt.Errorf("expect resource to be '%s'", resource.Resource)
+}
+
 func toPatchResponse(original, current interface{}) *admissionv1beta1.AdmissionResponse {
 	patchType := admissionv1beta1.PatchTypeJSONPatch
 
@@ -28,7 +28,11 @@ type CdiV1beta1Interface interface {
 	RESTClient() rest.Interface
 	CDIsGetter
 	CDIConfigsGetter
+	DataImportCronsGetter
+	DataSourcesGetter
 	DataVolumesGetter
+	StorageProfilesGetter
+	VolumeExportsGetter
 }
 
 // CdiV1beta1Client is used to interact with features provided by the cdi.kubevirt.io group.
@@ -44,10 +48,26 @@ func (c *CdiV1beta1Client) CDIConfigs() CDIConfigInterface {
 	return newCDIConfigs(c)
 }
 
+func (c *CdiV1beta1Client) DataImportCrons(namespace string) DataImportCronInterface {
+	return newDataImportCrons(c, namespace)
+}
+
+func (c *CdiV1beta1Client) DataSources(namespace string) DataSourceInterface {
+	return newDataSources(c, namespace)
+}
+
 func (c *CdiV1beta1Client) DataVolumes(namespace string) DataVolumeInterface {
 	return newDataVolumes(c, namespace)
 }
 
+func (c *CdiV1beta1Client) StorageProfiles() StorageProfileInterface {
+	return newStorageProfiles(c)
+}
+
+func (c *CdiV1beta1Client) VolumeExports(namespace string) VolumeExportInterface {
+	return newVolumeExports(c, namespace)
+}
+
 // NewForConfig creates a new CdiV1beta1Client for the given config.
 func NewForConfig(c *rest.Config) (*CdiV1beta1Client, error) {
 	config := *c
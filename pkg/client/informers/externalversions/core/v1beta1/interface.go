@@ -28,8 +28,16 @@ type Interface interface {
 	CDIs() CDIInformer
 	// CDIConfigs returns a CDIConfigInformer.
 	CDIConfigs() CDIConfigInformer
+	// DataImportCrons returns a DataImportCronInformer.
+	DataImportCrons() DataImportCronInformer
+	// DataSources returns a DataSourceInformer.
+	DataSources() DataSourceInformer
 	// DataVolumes returns a DataVolumeInformer.
 	DataVolumes() DataVolumeInformer
+	// StorageProfiles returns a StorageProfileInformer.
+	StorageProfiles() StorageProfileInformer
+	// VolumeExports returns a VolumeExportInformer.
+	VolumeExports() VolumeExportInformer
 }
 
 type version struct {
@@ -53,7 +61,27 @@ func (v *version) CDIConfigs() CDIConfigInformer {
 	return &cDIConfigInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// DataImportCrons returns a DataImportCronInformer.
+func (v *version) DataImportCrons() DataImportCronInformer {
+	return &dataImportCronInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// DataSources returns a DataSourceInformer.
+func (v *version) DataSources() DataSourceInformer {
+	return &dataSourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // DataVolumes returns a DataVolumeInformer.
 func (v *version) DataVolumes() DataVolumeInformer {
 	return &dataVolumeInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// StorageProfiles returns a StorageProfileInformer.
+func (v *version) StorageProfiles() StorageProfileInformer {
+	return &storageProfileInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// VolumeExports returns a VolumeExportInformer.
+func (v *version) VolumeExports() VolumeExportInformer {
+	return &volumeExportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -68,14 +68,24 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cdi().V1beta1().CDIs().Informer()}, nil
 	case v1beta1.SchemeGroupVersion.WithResource("cdiconfigs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cdi().V1beta1().CDIConfigs().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("dataimportcrons"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cdi().V1beta1().DataImportCrons().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("datasources"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cdi().V1beta1().DataSources().Informer()}, nil
 	case v1beta1.SchemeGroupVersion.WithResource("datavolumes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cdi().V1beta1().DataVolumes().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("storageprofiles"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cdi().V1beta1().StorageProfiles().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("volumeexports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cdi().V1beta1().VolumeExports().Informer()}, nil
 
 		// Group=upload.cdi.kubevirt.io, Version=v1alpha1
 	case uploadv1alpha1.SchemeGroupVersion.WithResource("uploadtokenrequests"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Upload().V1alpha1().UploadTokenRequests().Informer()}, nil
 
 		// Group=upload.cdi.kubevirt.io, Version=v1beta1
+	case uploadv1beta1.SchemeGroupVersion.WithResource("exporttokenrequests"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Upload().V1beta1().ExportTokenRequests().Informer()}, nil
 	case uploadv1beta1.SchemeGroupVersion.WithResource("uploadtokenrequests"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Upload().V1beta1().UploadTokenRequests().Informer()}, nil
 
@@ -26,6 +26,22 @@ type CDIListerExpansion interface{}
 // CDIConfigLister.
 type CDIConfigListerExpansion interface{}
 
+// DataImportCronListerExpansion allows custom methods to be added to
+// DataImportCronLister.
+type DataImportCronListerExpansion interface{}
+
+// DataImportCronNamespaceListerExpansion allows custom methods to be added to
+// DataImportCronNamespaceLister.
+type DataImportCronNamespaceListerExpansion interface{}
+
+// DataSourceListerExpansion allows custom methods to be added to
+// DataSourceLister.
+type DataSourceListerExpansion interface{}
+
+// DataSourceNamespaceListerExpansion allows custom methods to be added to
+// DataSourceNamespaceLister.
+type DataSourceNamespaceListerExpansion interface{}
+
 // DataVolumeListerExpansion allows custom methods to be added to
 // DataVolumeLister.
 type DataVolumeListerExpansion interface{}
@@ -33,3 +49,15 @@ type DataVolumeListerExpansion interface{}
 // DataVolumeNamespaceListerExpansion allows custom methods to be added to
 // DataVolumeNamespaceLister.
 type DataVolumeNamespaceListerExpansion interface{}
+
+// StorageProfileListerExpansion allows custom methods to be added to
+// StorageProfileLister.
+type StorageProfileListerExpansion interface{}
+
+// VolumeExportListerExpansion allows custom methods to be added to
+// VolumeExportLister.
+type VolumeExportListerExpansion interface{}
+
+// VolumeExportNamespaceListerExpansion allows custom methods to be added to
+// VolumeExportNamespaceLister.
+type VolumeExportNamespaceListerExpansion interface{}
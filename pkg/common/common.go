@@ -77,6 +77,24 @@ const (
 	ImporterBackingFile = "IMPORTER_BACKING_FILE"
 	// ImporterThumbprint provides a constant to capture our env variable "IMPORTER_THUMBPRINT"
 	ImporterThumbprint = "IMPORTER_THUMBPRINT"
+	// ImporterCurrentCheckpoint provides a constant to capture our env variable "IMPORTER_CURRENT_CHECKPOINT"
+	ImporterCurrentCheckpoint = "IMPORTER_CURRENT_CHECKPOINT"
+	// ImporterPreviousCheckpoint provides a constant to capture our env variable "IMPORTER_PREVIOUS_CHECKPOINT"
+	ImporterPreviousCheckpoint = "IMPORTER_PREVIOUS_CHECKPOINT"
+	// ImporterFinalCheckpoint provides a constant to capture our env variable "IMPORTER_FINAL_CHECKPOINT"
+	ImporterFinalCheckpoint = "IMPORTER_FINAL_CHECKPOINT"
+	// Preallocation provides a constant to capture our env variable "PREALLOCATION"
+	Preallocation = "PREALLOCATION"
+	// Checksum provides a constant to capture our env variable "CHECKSUM"
+	Checksum = "CHECKSUM"
+	// ImporterOVADisk provides a constant to capture our env variable "IMPORTER_OVA_DISK"
+	ImporterOVADisk = "IMPORTER_OVA_DISK"
+	// ImporterConcurrency provides a constant to capture our env variable "IMPORTER_CONCURRENCY"
+	ImporterConcurrency = "IMPORTER_CONCURRENCY"
+	// ImporterPollSourceDigest provides a constant to capture our env variable "IMPORTER_POLL_SOURCE_DIGEST"
+	ImporterPollSourceDigest = "IMPORTER_POLL_SOURCE_DIGEST"
+	// ImporterProbeVirtualSize provides a constant to capture our env variable "IMPORTER_PROBE_VIRTUAL_SIZE"
+	ImporterProbeVirtualSize = "IMPORTER_PROBE_VIRTUAL_SIZE"
 
 	// CloningLabelValue provides a constant to use as a label value for pod affinity (controller pkg only)
 	CloningLabelValue = "host-assisted-cloning"
@@ -97,6 +115,9 @@ const (
 	// SmartClonerCDILabel is the label applied to resources created by the smart-clone controller
 	SmartClonerCDILabel = "cdi-smart-clone"
 
+	// SnapshotRestoreCDILabel is the label applied to the temporary PVCs restored from a DataVolume snapshot source
+	SnapshotRestoreCDILabel = "cdi-snapshot-restore"
+
 	// UploadServerCDILabel is the label applied to upload server resources
 	UploadServerCDILabel = "cdi-upload-server"
 	// UploadServerPodname is name of the upload server pod container
@@ -108,10 +129,27 @@ const (
 	// UploadImageSize provides a constant to capture our env variable "UPLOAD_IMAGE_SIZE"
 	UploadImageSize = "UPLOAD_IMAGE_SIZE"
 
+	// ExportServerCDILabel is the label applied to export server resources
+	ExportServerCDILabel = "cdi-export-server"
+	// ExportSource provides a constant to capture our env variable "EXPORT_SOURCE", the path of the exported data
+	// which runs the upload server image as an export server
+	ExportSource = "EXPORT_SOURCE"
+	// ExportServerScratchDir is the directory where the export server keeps the converted images
+	ExportServerScratchDir = "/scratch"
+
 	// FilesystemOverheadVar provides a constant to capture our env variable "FILESYSTEM_OVERHEAD"
 	FilesystemOverheadVar = "FILESYSTEM_OVERHEAD"
 	// DefaultGlobalOverhead is the amount of space reserved on Filesystem volumes by default
 	DefaultGlobalOverhead = "0.055"
+	// DefaultMaxDownloadConcurrency is the default cap on the parallel ranged requests of a DataVolume
+	DefaultMaxDownloadConcurrency = 4
+	// DefaultUploadTokenExpirationSeconds is the lifetime of upload tokens when the client does not request one
+	DefaultUploadTokenExpirationSeconds = 300
+	// DefaultUploadTokenMaxExpirationSeconds is the default cap on the lifetime clients may request for upload tokens
+	DefaultUploadTokenMaxExpirationSeconds = 3600
+	// MaxUploadTokenMaxExpirationSeconds is the highest cap on the lifetime of upload tokens, it is shorter than the
+	// grace period of the key they are signed with, so the tokens expire before the key is dropped
+	MaxUploadTokenMaxExpirationSeconds = 86400
 
 	// ConfigName is the name of default CDI Config
 	ConfigName = "config"
@@ -119,6 +157,16 @@ const (
 	// OwnerUID provides the UID of the owner entity (either PVC or DV)
 	OwnerUID = "OWNER_UID"
 
+	// CloneCodec provides a constant to capture our env variable "CLONE_CODEC"
+	CloneCodec = "CLONE_CODEC"
+	// CloneCodecLevel provides a constant to capture our env variable "CLONE_CODEC_LEVEL"
+	CloneCodecLevel = "CLONE_CODEC_LEVEL"
+	// CloneTargets provides a constant to capture our env variable "CLONE_TARGETS", the number of targets of a fan-out clone
+	CloneTargets = "CLONE_TARGETS"
+	// CloneTargetEnvFormat formats the env variables describing each target of a fan-out clone, from its index and
+	// the name of the variable of a single target clone, e.g. CLONE_TARGET_0_UPLOAD_URL
+	CloneTargetEnvFormat = "CLONE_TARGET_%d_%s"
+
 	// KeyAccess provides a constant to the accessKeyId label using in controller pkg and transport_test.go
 	KeyAccess = "accessKeyId"
 	// KeySecret provides a constant to the secretKey label using in controller pkg and transport_test.go
@@ -131,6 +179,8 @@ const (
 
 	// ScratchSpaceNeededExitCode is the exit code that indicates the importer pod requires scratch space to function properly.
 	ScratchSpaceNeededExitCode = 42
+	// ChecksumMismatchExitCode is the exit code that indicates the imported data does not match the expected checksum.
+	ChecksumMismatchExitCode = 43
 
 	// ScratchNameSuffix (controller pkg only)
 	ScratchNameSuffix = "scratch"
@@ -141,6 +191,9 @@ const (
 	// CloneTokenIssuer is the JWT issuer for clone tokens
 	CloneTokenIssuer = "cdi-apiserver"
 
+	// ExportTokenIssuer is the JWT issuer of export tokens
+	ExportTokenIssuer = "cdi-apiserver"
+
 	// QemuSubGid is the gid used as the qemu group in fsGroup
 	QemuSubGid = int64(107)
 
@@ -155,12 +208,19 @@ const (
 	// UploadContentTypeHeader is the header upload clients may use to set the content type explicitly
 	UploadContentTypeHeader = "x-cdi-content-type"
 
+	// UploadChecksumHeader is the header upload clients may use to set the checksum the uploaded data must match, in
+	// the <algorithm>:<hex digest> format, when the DataVolume does not have one
+	UploadChecksumHeader = "x-cdi-checksum"
+
 	// FilesystemCloneContentType is the content type when cloning a filesystem
 	FilesystemCloneContentType = "filesystem-clone"
 
 	// BlockdeviceClone is the content type when cloning a block device
 	BlockdeviceClone = "blockdevice-clone"
 
+	// BlockdeviceCloneSparse is the content type when cloning a block device as an extent stream skipping zero ranges
+	BlockdeviceCloneSparse = "blockdevice-clone-sparse"
+
 	// UploadPathSync is the path to POST CDI uploads
 	UploadPathSync = "/v1beta1/upload"
 
@@ -173,14 +233,37 @@ const (
 	// UploadFormAsync is the path to POST CDI uploads as form data in async mode
 	UploadFormAsync = "/v1beta1/upload-form-async"
 
+	// UploadPathChunked is the path to HEAD and PATCH the chunks of resumable CDI uploads
+	UploadPathChunked = "/v1beta1/upload-chunked"
+
+	// UploadOffsetHeader is the header holding the offset of a chunk of a resumable upload, and the offset the upload
+	// server received so far in its replies
+	UploadOffsetHeader = "Upload-Offset"
+
+	// UploadLengthHeader is the header holding the total size of a resumable upload
+	UploadLengthHeader = "Upload-Length"
+
+	// UploadTokenIDHeader is the header the upload proxy sets to the ID of the single-use token of a request, the
+	// upload server rejects the ID once an upload with it succeeded
+	UploadTokenIDHeader = "X-Cdi-Upload-Token-Id"
+
+	// UploadPathStatus is the path to GET the status of CDI uploads
+	UploadPathStatus = "/v1beta1/upload-status"
+
+	// ExportPath is the path to GET CDI exports
+	ExportPath = "/v1beta1/export"
+
 	//
 	CSICloneCDILabel = "csi-volume-clone"
 )
 
 // ProxyPaths are all supported paths
 var ProxyPaths = append(
-	append(SyncUploadPaths, AsyncUploadPaths...),
-	append(SyncUploadFormPaths, AsyncUploadFormPaths...)...,
+	append(
+		append(SyncUploadPaths, AsyncUploadPaths...),
+		append(SyncUploadFormPaths, AsyncUploadFormPaths...)...,
+	),
+	append(ChunkedUploadPaths, UploadPathStatus)...,
 )
 
 // SyncUploadPaths are paths to POST CDI uploads
@@ -206,3 +289,8 @@ var AsyncUploadFormPaths = []string{
 	UploadFormAsync,
 	"/v1alpha1/upload-form-async",
 }
+
+// ChunkedUploadPaths are paths to HEAD and PATCH the chunks of resumable CDI uploads
+var ChunkedUploadPaths = []string{
+	UploadPathChunked,
+}
@@ -2,7 +2,6 @@ package controller
 
 import (
 	"context"
-	"crypto/rsa"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -30,6 +29,7 @@ import (
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/token"
+	"kubevirt.io/containerized-data-importer/pkg/util"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/fetcher"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/generator"
 )
@@ -43,11 +43,19 @@ const (
 	AnnCloneOf = "k8s.io/CloneOf"
 	// AnnCloneToken is the annotation containing the clone token
 	AnnCloneToken = "cdi.kubevirt.io/storage.clone.token"
+	// AnnCloneCodec is the annotation with the codec compressing the clone stream
+	AnnCloneCodec = "cdi.kubevirt.io/storage.clone.codec"
+	// AnnCloneCodecLevel is the annotation with the compression level of the clone codec
+	AnnCloneCodecLevel = "cdi.kubevirt.io/storage.clone.codec.level"
 
 	//CloneUniqueID is used as a special label to be used when we search for the pod
 	CloneUniqueID = "cdi.kubevirt.io/storage.clone.cloneUniqeId"
 	// AnnCloneSourcePod name of the source clone pod
 	AnnCloneSourcePod = "cdi.kubevirt.io/storage.sourceClonePodName"
+	// AnnCloneTargets lists the namespace/name of the target PVCs a fan-out clone source pod streams to
+	AnnCloneTargets = "cdi.kubevirt.io/storage.clone.targets"
+	// AnnCloneFanOutWaitStart is when a target PVC ready to clone started waiting for the other targets of its source
+	AnnCloneFanOutWaitStart = "cdi.kubevirt.io/storage.clone.fanOutWaitStart"
 
 	// ErrIncompatiblePVC provides a const to indicate a clone is not possible due to an incompatible PVC
 	ErrIncompatiblePVC = "ErrIncompatiblePVC"
@@ -58,6 +66,9 @@ const (
 	// APIServerPublicKeyPath is the path to the apiserver public key
 	APIServerPublicKeyPath = APIServerPublicKeyDir + "/id_rsa.pub"
 
+	// APIServerPublicKeyBundlePath is the path to the bundle of apiserver public keys
+	APIServerPublicKeyBundlePath = APIServerPublicKeyDir + "/id_rsa.pub.bundle"
+
 	// CloneSucceededPVC provides a const to indicate a clone to the PVC succeeded
 	CloneSucceededPVC = "CloneSucceeded"
 
@@ -71,6 +82,19 @@ const (
 	uploadClientCertDuration = 365 * 24 * time.Hour
 
 	cloneComplete = "Clone Complete"
+
+	// cloneFanOutPodNameSuffix is added to the name of the source pods streaming to several targets
+	cloneFanOutPodNameSuffix = "-fanout"
+	// cloneFanOutMaxTargets is the maximum number of targets a single source pod streams to
+	cloneFanOutMaxTargets = 32
+	// cloneFanOutBatchDelay is how long a target PVC ready to clone waits for the other targets of the same source
+	// that are not ready yet, so they share its source pod
+	cloneFanOutBatchDelay = 30 * time.Second
+
+	// cloneRequestField indexes the target PVCs by the source PVC of their clone request
+	cloneRequestField = "metadata.annotations.cloneRequest"
+	// cloneSourcePodField indexes the target PVCs by the name of their source pod
+	cloneSourcePodField = "metadata.annotations.cloneSourcePod"
 )
 
 // CloneReconciler members
@@ -94,12 +118,12 @@ func NewCloneController(mgr manager.Manager,
 	verbose string,
 	clientCertGenerator generator.CertGenerator,
 	serverCAFetcher fetcher.CertBundleFetcher,
-	apiServerKey *rsa.PublicKey) (controller.Controller, error) {
+	apiServerKeys token.PublicKeySource) (controller.Controller, error) {
 	reconciler := &CloneReconciler{
 		client:              mgr.GetClient(),
 		scheme:              mgr.GetScheme(),
 		log:                 log.WithName("clone-controller"),
-		tokenValidator:      newCloneTokenValidator(apiServerKey),
+		tokenValidator:      newCloneTokenValidator(apiServerKeys),
 		image:               image,
 		verbose:             verbose,
 		pullPolicy:          pullPolicy,
@@ -121,6 +145,19 @@ func NewCloneController(mgr manager.Manager,
 
 // addConfigControllerWatches sets up the watches used by the config controller.
 func addCloneControllerWatches(mgr manager.Manager, cloneController controller.Controller) error {
+	// The targets of a fan-out clone are found by their source PVC and their source pod
+	for field, annotation := range map[string]string{cloneRequestField: AnnCloneRequest, cloneSourcePodField: AnnCloneSourcePod} {
+		annotation := annotation
+		if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &corev1.PersistentVolumeClaim{}, field, func(obj runtime.Object) []string {
+			if value, ok := obj.(*corev1.PersistentVolumeClaim).Annotations[annotation]; ok {
+				return []string{value}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
 	// Setup watches
 	if err := cloneController.Watch(&source.Kind{Type: &corev1.PersistentVolumeClaim{}}, &handler.EnqueueRequestForObject{}); err != nil {
 		return err
@@ -137,18 +174,25 @@ func addCloneControllerWatches(mgr manager.Manager, cloneController controller.C
 			if !ok {
 				return nil
 			}
-			namespace, name, err := cache.SplitMetaNamespaceKey(target)
-			if err != nil {
-				return nil
+			targets := sets.NewString(target)
+			// a fan-out source pod reports to all of its targets
+			if fanOutTargets, ok := obj.Meta.GetAnnotations()[AnnCloneTargets]; ok {
+				targets.Insert(strings.Split(fanOutTargets, ",")...)
 			}
-			return []reconcile.Request{
-				{
+			var requests []reconcile.Request
+			for _, target := range targets.List() {
+				namespace, name, err := cache.SplitMetaNamespaceKey(target)
+				if err != nil {
+					continue
+				}
+				requests = append(requests, reconcile.Request{
 					NamespacedName: types.NamespacedName{
 						Namespace: namespace,
 						Name:      name,
 					},
-				},
+				})
 			}
+			return requests
 		}),
 	}); err != nil {
 		return err
@@ -156,8 +200,8 @@ func addCloneControllerWatches(mgr manager.Manager, cloneController controller.C
 	return nil
 }
 
-func newCloneTokenValidator(key *rsa.PublicKey) token.Validator {
-	return token.NewValidator(common.CloneTokenIssuer, key, cloneTokenLeeway)
+func newCloneTokenValidator(keys token.PublicKeySource) token.Validator {
+	return token.NewBundleValidator(common.CloneTokenIssuer, keys, cloneTokenLeeway)
 }
 
 func (r *CloneReconciler) shouldReconcile(pvc *corev1.PersistentVolumeClaim, log logr.Logger) bool {
@@ -210,17 +254,14 @@ func (r *CloneReconciler) Reconcile(req reconcile.Request) (reconcile.Result, er
 
 	_, nameExists := pvc.Annotations[AnnCloneSourcePod]
 	if !nameExists && sourcePod == nil {
-		pvc.Annotations[AnnCloneSourcePod] = createCloneSourcePodName(pvc)
-
-		// add finalizer before creating clone source pod
-		pvc = r.addFinalizer(pvc, cloneSourcePodFinalizer)
-
-		if err := r.updatePVC(pvc); err != nil {
-			return reconcile.Result{}, err
-		}
+		// will reconcile again after PVC update notification, or once done waiting for the other targets
+		requeueAfter, err := r.assignCloneSourcePod(pvc, log)
+		return reconcile.Result{RequeueAfter: requeueAfter}, err
+	}
 
+	if sourcePod != nil && cloneFanOutTargetFailed(sourcePod, pvc) {
 		// will reconcile again after PVC update notification
-		return reconcile.Result{}, nil
+		return reconcile.Result{}, r.leaveCloneFanOut(sourcePod, pvc, log)
 	}
 
 	if requeue, err := r.reconcileSourcePod(sourcePod, pvc, log); requeue || err != nil {
@@ -274,7 +315,11 @@ func (r *CloneReconciler) reconcileSourcePod(sourcePod *corev1.Pod, targetPvc *c
 			return true, nil
 		}
 
-		sourcePod, err := r.CreateCloneSourcePod(r.image, r.pullPolicy, clientName, targetPvc, log)
+		if isCloneFanOutSourcePod(targetPvc.Annotations[AnnCloneSourcePod]) {
+			sourcePod, err = r.createCloneFanOutSourcePod(sourcePvc, targetPvc, log)
+		} else {
+			sourcePod, err = r.CreateCloneSourcePod(r.image, r.pullPolicy, clientName, sourcePvc, targetPvc, log)
+		}
 		if err != nil {
 			return false, err
 		}
@@ -375,6 +420,9 @@ func (r *CloneReconciler) validateSourceAndTarget(sourcePvc, targetPvc *corev1.P
 	}
 
 	err := ValidateCanCloneSourceAndTargetSpec(&sourcePvc.Spec, &targetPvc.Spec)
+	if err == nil {
+		err = r.validateVolumeModeConversion(sourcePvc, targetPvc)
+	}
 	if err == nil {
 		// Validation complete, put source PVC bound status in annotation
 		setBoundConditionFromPVC(targetPvc.GetAnnotations(), AnnBoundCondition, sourcePvc)
@@ -383,6 +431,30 @@ func (r *CloneReconciler) validateSourceAndTarget(sourcePvc, targetPvc *corev1.P
 	return err
 }
 
+// validateVolumeModeConversion verifies a filesystem target can hold the raw disk image of a block source, once the
+// filesystem overhead is taken out of the target size
+func (r *CloneReconciler) validateVolumeModeConversion(sourcePvc, targetPvc *corev1.PersistentVolumeClaim) error {
+	if getVolumeMode(sourcePvc) != corev1.PersistentVolumeBlock || getVolumeMode(targetPvc) != corev1.PersistentVolumeFilesystem {
+		return nil
+	}
+
+	overhead, err := GetFilesystemOverhead(r.client, targetPvc)
+	if err != nil {
+		return err
+	}
+	fsOverhead, err := strconv.ParseFloat(string(overhead), 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid filesystem overhead %s", overhead)
+	}
+
+	sourceRequest := sourcePvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	targetRequest := targetPvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if usable := int64(float64(targetRequest.Value()) * (1 - fsOverhead)); usable < sourceRequest.Value() {
+		return errors.Errorf("target resources requests storage size minus the filesystem overhead (%s) is smaller than the block source", overhead)
+	}
+	return nil
+}
+
 func (r *CloneReconciler) addFinalizer(pvc *corev1.PersistentVolumeClaim, name string) *corev1.PersistentVolumeClaim {
 	if r.hasFinalizer(pvc, name) {
 		return pvc
@@ -439,14 +511,22 @@ func (r *CloneReconciler) cleanup(pvc *corev1.PersistentVolumeClaim, log logr.Lo
 	}
 
 	if pod != nil && pod.DeletionTimestamp == nil {
-		if podSucceededFromPVC(pvc) && pod.Status.Phase == corev1.PodRunning {
-			log.V(3).Info("Clone succeeded, waiting for source pod to stop running", "pod.Namespace", pod.Namespace, "pod.Name", pod.Name)
-			return nil
+		inUse, err := r.isCloneSourcePodInUse(pod, pvc)
+		if err != nil {
+			return err
 		}
+		if inUse {
+			log.V(3).Info("Source pod still streaming to other targets", "pod.Namespace", pod.Namespace, "pod.Name", pod.Name)
+		} else {
+			if podSucceededFromPVC(pvc) && pod.Status.Phase == corev1.PodRunning {
+				log.V(3).Info("Clone succeeded, waiting for source pod to stop running", "pod.Namespace", pod.Namespace, "pod.Name", pod.Name)
+				return nil
+			}
 
-		if err = r.client.Delete(context.TODO(), pod); err != nil {
-			if !k8serrors.IsNotFound(err) {
-				return errors.Wrap(err, "error deleting clone source pod")
+			if err = r.client.Delete(context.TODO(), pod); err != nil {
+				if !k8serrors.IsNotFound(err) {
+					return errors.Wrap(err, "error deleting clone source pod")
+				}
 			}
 		}
 	}
@@ -454,13 +534,174 @@ func (r *CloneReconciler) cleanup(pvc *corev1.PersistentVolumeClaim, log logr.Lo
 	return r.updatePVC(r.removeFinalizer(pvc, cloneSourcePodFinalizer))
 }
 
-// CreateCloneSourcePod creates our cloning src pod which will be used for out of band cloning to read the contents of the src PVC
-func (r *CloneReconciler) CreateCloneSourcePod(image, pullPolicy, clientName string, pvc *corev1.PersistentVolumeClaim, log logr.Logger) (*corev1.Pod, error) {
-	exists, sourcePvcNamespace, sourcePvcName := ParseCloneRequestAnnotation(pvc)
-	if !exists {
-		return nil, errors.Errorf("bad CloneRequest Annotation")
+// assignCloneSourcePod names the source pod of the target PVC. Other target PVCs ready to clone the same source
+// that have no source pod yet are assigned the same fan-out source pod, which reads the source once for all of them.
+// While other targets of the same source are not ready yet, the assignment waits for them up to
+// cloneFanOutBatchDelay, and returns how long to wait before trying again.
+func (r *CloneReconciler) assignCloneSourcePod(pvc *corev1.PersistentVolumeClaim, log logr.Logger) (time.Duration, error) {
+	candidates, pending, err := r.findCloneFanOutCandidates(pvc, log)
+	if err != nil {
+		return 0, err
+	}
+	if pending && len(candidates) < cloneFanOutMaxTargets-1 {
+		if wait, err := r.waitForCloneFanOutTargets(pvc, log); wait > 0 || err != nil {
+			return wait, err
+		}
 	}
 
+	podName := createCloneSourcePodName(pvc)
+	if len(candidates) > 0 {
+		podName = createCloneFanOutSourcePodName(pvc)
+	}
+	// The candidates are assigned first, so they are all known once this PVC gets to create the pod. A candidate
+	// updated concurrently is left to clone on its own.
+	for _, candidate := range candidates {
+		candidate.Annotations[AnnCloneSourcePod] = podName
+		if err := r.updatePVC(r.addFinalizer(candidate, cloneSourcePodFinalizer)); err != nil {
+			log.V(3).Info("Unable to add target to fan-out clone", "namespace", candidate.Namespace, "name", candidate.Name, "error", err.Error())
+		}
+	}
+
+	pvc.Annotations[AnnCloneSourcePod] = podName
+	delete(pvc.Annotations, AnnCloneFanOutWaitStart)
+	// add finalizer before creating clone source pod
+	return 0, r.updatePVC(r.addFinalizer(pvc, cloneSourcePodFinalizer))
+}
+
+// waitForCloneFanOutTargets records when the target PVC started waiting for the other targets of its source, and
+// returns how long it still has to wait for them.
+func (r *CloneReconciler) waitForCloneFanOutTargets(pvc *corev1.PersistentVolumeClaim, log logr.Logger) (time.Duration, error) {
+	waitStart, err := time.Parse(time.RFC3339, pvc.Annotations[AnnCloneFanOutWaitStart])
+	if err != nil {
+		log.V(3).Info("Waiting for the other targets of the source to share a source pod")
+		pvc.Annotations[AnnCloneFanOutWaitStart] = time.Now().Format(time.RFC3339)
+		return cloneFanOutBatchDelay, r.updatePVC(pvc)
+	}
+	if wait := cloneFanOutBatchDelay - time.Since(waitStart); wait > 0 {
+		return wait, nil
+	}
+	return 0, nil
+}
+
+// findCloneFanOutCandidates returns the other target PVCs that can share a source pod with the target PVC: they clone
+// the same source with the same stream, their upload pod is ready and they have no source pod yet. It also returns
+// true if other targets that could share the source pod are not ready yet.
+func (r *CloneReconciler) findCloneFanOutCandidates(pvc *corev1.PersistentVolumeClaim, log logr.Logger) ([]*corev1.PersistentVolumeClaim, bool, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.client.List(context.TODO(), pvcList, client.MatchingFields{cloneRequestField: pvc.Annotations[AnnCloneRequest]}); err != nil {
+		return nil, false, errors.Wrap(err, "error listing PVCs")
+	}
+
+	var candidates []*corev1.PersistentVolumeClaim
+	pending := false
+	for i := range pvcList.Items {
+		candidate := &pvcList.Items[i]
+		if len(candidates) == cloneFanOutMaxTargets-1 {
+			break
+		}
+		if candidate.UID == pvc.UID ||
+			candidate.DeletionTimestamp != nil ||
+			candidate.Annotations[AnnCloneRequest] != pvc.Annotations[AnnCloneRequest] ||
+			metav1.HasAnnotation(candidate.ObjectMeta, AnnCloneSourcePod) ||
+			metav1.HasAnnotation(candidate.ObjectMeta, AnnCloneOf) ||
+			getVolumeMode(candidate) != getVolumeMode(pvc) ||
+			candidate.Annotations[AnnCloneCodec] != pvc.Annotations[AnnCloneCodec] ||
+			candidate.Annotations[AnnCloneCodecLevel] != pvc.Annotations[AnnCloneCodecLevel] ||
+			podSucceededFromPVC(candidate) {
+			continue
+		}
+		if !r.shouldReconcile(candidate, log) {
+			pending = true
+			continue
+		}
+		if ready, err := r.waitTargetPodRunningOrSucceeded(candidate, log); err != nil || !ready {
+			pending = pending || err == nil
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, pending, nil
+}
+
+// getCloneFanOutTargets returns the target PVCs assigned to the fan-out source pod that still have to be cloned
+func (r *CloneReconciler) getCloneFanOutTargets(podName string, log logr.Logger) ([]*corev1.PersistentVolumeClaim, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.client.List(context.TODO(), pvcList, client.MatchingFields{cloneSourcePodField: podName}); err != nil {
+		return nil, errors.Wrap(err, "error listing PVCs")
+	}
+
+	var targets []*corev1.PersistentVolumeClaim
+	for i := range pvcList.Items {
+		target := &pvcList.Items[i]
+		if target.Annotations[AnnCloneSourcePod] != podName ||
+			target.DeletionTimestamp != nil ||
+			!r.hasFinalizer(target, cloneSourcePodFinalizer) ||
+			!r.shouldReconcile(target, log) ||
+			podSucceededFromPVC(target) {
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// isCloneSourcePodInUse returns true if the source pod is a fan-out source pod still needed by targets other than pvc
+func (r *CloneReconciler) isCloneSourcePodInUse(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	if !isCloneFanOutSourcePod(pod.Name) {
+		return false, nil
+	}
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.client.List(context.TODO(), pvcList, client.MatchingFields{cloneSourcePodField: pod.Name}); err != nil {
+		return false, errors.Wrap(err, "error listing PVCs")
+	}
+	for _, target := range pvcList.Items {
+		if target.UID != pvc.UID && target.Annotations[AnnCloneSourcePod] == pod.Name && r.hasFinalizer(&target, cloneSourcePodFinalizer) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// leaveCloneFanOut assigns a source pod of its own to a target PVC the fan-out source pod did not clone to
+func (r *CloneReconciler) leaveCloneFanOut(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, log logr.Logger) error {
+	log.V(1).Info("Fan-out source pod did not clone to PVC, falling back to a source pod of its own", "pod.Namespace", pod.Namespace, "pod.Name", pod.Name)
+	pvc.Annotations[AnnCloneSourcePod] = createCloneSourcePodName(pvc)
+	if err := r.updatePVC(pvc); err != nil {
+		return err
+	}
+
+	inUse, err := r.isCloneSourcePodInUse(pod, pvc)
+	if err != nil || inUse {
+		return err
+	}
+	if err := r.client.Delete(context.TODO(), pod); err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "error deleting clone source pod")
+	}
+	return nil
+}
+
+// cloneFanOutTargetFailed returns true if the fan-out source pod does not clone to the target PVC, either because the
+// PVC was assigned to the pod after it was created, or because the pod reported it failed to stream to it
+func cloneFanOutTargetFailed(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim) bool {
+	if !isCloneFanOutSourcePod(pod.Name) {
+		return false
+	}
+	key, err := cache.MetaNamespaceKeyFunc(pvc)
+	if err != nil {
+		return false
+	}
+	if !sets.NewString(strings.Split(pod.Annotations[AnnCloneTargets], ",")...).Has(key) {
+		return true
+	}
+	if len(pod.Status.ContainerStatuses) == 0 || pod.Status.ContainerStatuses[0].State.Terminated == nil {
+		return false
+	}
+	termMsg := util.ParseTerminationMessage(pod.Status.ContainerStatuses[0].State.Terminated.Message)
+	return sets.NewString(termMsg.FailedTargets...).Has(key)
+}
+
+// CreateCloneSourcePod creates our cloning src pod which will be used for out of band cloning to read the contents of the src PVC
+func (r *CloneReconciler) CreateCloneSourcePod(image, pullPolicy, clientName string, sourcePvc, pvc *corev1.PersistentVolumeClaim, log logr.Logger) (*corev1.Pod, error) {
 	ownerKey, err := cache.MetaNamespaceKeyFunc(pvc)
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting cache key")
@@ -486,7 +727,7 @@ func (r *CloneReconciler) CreateCloneSourcePod(image, pullPolicy, clientName str
 		return nil, err
 	}
 
-	pod := MakeCloneSourcePodSpec(image, pullPolicy, sourcePvcName, sourcePvcNamespace, ownerKey, clientKey, clientCert, serverCABundle, pvc, podResourceRequirements, workloadNodePlacement)
+	pod := MakeCloneSourcePodSpec(image, pullPolicy, ownerKey, clientKey, clientCert, serverCABundle, sourcePvc, pvc, podResourceRequirements, workloadNodePlacement)
 
 	if err := r.client.Create(context.TODO(), pod); err != nil {
 		return nil, errors.Wrap(err, "source pod API create errored")
@@ -497,13 +738,128 @@ func (r *CloneReconciler) CreateCloneSourcePod(image, pullPolicy, clientName str
 	return pod, nil
 }
 
+// createCloneFanOutSourcePod creates the source pod streaming to all the targets assigned to it. Targets that fail
+// validation are left out, they fall back to a source pod of their own.
+func (r *CloneReconciler) createCloneFanOutSourcePod(sourcePvc, pvc *corev1.PersistentVolumeClaim, log logr.Logger) (*corev1.Pod, error) {
+	ownerKey, err := cache.MetaNamespaceKeyFunc(pvc)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting cache key")
+	}
+
+	targetPvcs, err := r.getCloneFanOutTargets(pvc.Annotations[AnnCloneSourcePod], log)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []cloneFanOutTarget
+	for _, targetPvc := range targetPvcs {
+		clientName, ok := targetPvc.Annotations[AnnUploadClientName]
+		if !ok || r.validateSourceAndTarget(sourcePvc, targetPvc) != nil {
+			log.V(3).Info("Leaving invalid target out of fan-out clone", "namespace", targetPvc.Namespace, "name", targetPvc.Name)
+			continue
+		}
+		clientCert, clientKey, err := r.clientCertGenerator.MakeClientCert(clientName, nil, uploadClientCertDuration)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, cloneFanOutTarget{pvc: targetPvc, clientKey: clientKey, clientCert: clientCert})
+	}
+	if len(targets) == 0 {
+		return nil, errors.Errorf("no valid target for fan-out source pod %s", pvc.Annotations[AnnCloneSourcePod])
+	}
+
+	serverCABundle, err := r.serverCAFetcher.BundleBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	podResourceRequirements, err := GetDefaultPodResourceRequirements(r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	workloadNodePlacement, err := GetWorkloadNodePlacement(r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	pod := MakeCloneSourcePodSpec(r.image, r.pullPolicy, ownerKey, nil, nil, serverCABundle, sourcePvc, pvc, podResourceRequirements, workloadNodePlacement)
+	setCloneFanOutTargets(pod, targets)
+
+	if err := r.client.Create(context.TODO(), pod); err != nil {
+		return nil, errors.Wrap(err, "source pod API create errored")
+	}
+
+	log.V(1).Info("fan-out cloning source pod created\n", "pod.Namespace", pod.Namespace, "pod.Name", pod.Name, "targets", len(targets))
+
+	return pod, nil
+}
+
 func createCloneSourcePodName(targetPvc *corev1.PersistentVolumeClaim) string {
 	return string(targetPvc.GetUID()) + common.ClonerSourcePodNameSuffix
 }
 
-// MakeCloneSourcePodSpec creates and returns the clone source pod spec based on the target pvc.
-func MakeCloneSourcePodSpec(image, pullPolicy, sourcePvcName, sourcePvcNamespace, ownerRefAnno string,
-	clientKey, clientCert, serverCACert []byte, targetPvc *corev1.PersistentVolumeClaim, resourceRequirements *corev1.ResourceRequirements,
+func createCloneFanOutSourcePodName(targetPvc *corev1.PersistentVolumeClaim) string {
+	return string(targetPvc.GetUID()) + cloneFanOutPodNameSuffix + common.ClonerSourcePodNameSuffix
+}
+
+func isCloneFanOutSourcePod(podName string) bool {
+	return strings.HasSuffix(podName, cloneFanOutPodNameSuffix+common.ClonerSourcePodNameSuffix)
+}
+
+// cloneFanOutTarget is a target PVC of a fan-out source pod, with the client certificate used to upload to it
+type cloneFanOutTarget struct {
+	pvc        *corev1.PersistentVolumeClaim
+	clientKey  []byte
+	clientCert []byte
+}
+
+// setCloneFanOutTargets replaces the upload settings of the single target of the source pod by the settings of each
+// target of the fan-out clone
+func setCloneFanOutTargets(pod *corev1.Pod, targets []cloneFanOutTarget) {
+	singleTargetVars := sets.NewString("CLIENT_KEY", "CLIENT_CERT", "UPLOAD_URL", common.OwnerUID)
+	var env []corev1.EnvVar
+	for _, envVar := range pod.Spec.Containers[0].Env {
+		if !singleTargetVars.Has(envVar.Name) {
+			env = append(env, envVar)
+		}
+	}
+	env = append(env, corev1.EnvVar{
+		Name:  common.CloneTargets,
+		Value: strconv.Itoa(len(targets)),
+	})
+
+	var keys []string
+	for i, target := range targets {
+		var ownerID string
+		if pvcOwner := metav1.GetControllerOf(target.pvc); pvcOwner != nil && pvcOwner.Kind == "DataVolume" {
+			ownerID = string(pvcOwner.UID)
+		}
+		key := target.pvc.Namespace + "/" + target.pvc.Name
+		keys = append(keys, key)
+		targetVars := []struct{ name, value string }{
+			{"NAME", key},
+			{"CLIENT_KEY", string(target.clientKey)},
+			{"CLIENT_CERT", string(target.clientCert)},
+			{"UPLOAD_URL", GetUploadServerURL(target.pvc.Namespace, target.pvc.Name, common.UploadPathSync)},
+			{common.OwnerUID, ownerID},
+		}
+		for _, v := range targetVars {
+			env = append(env, corev1.EnvVar{
+				Name:  fmt.Sprintf(common.CloneTargetEnvFormat, i, v.name),
+				Value: v.value,
+			})
+		}
+	}
+	pod.Spec.Containers[0].Env = env
+	pod.Annotations[AnnCloneTargets] = strings.Join(keys, ",")
+}
+
+// MakeCloneSourcePodSpec creates and returns the clone source pod spec based on the source and target pvcs.
+// The source is mounted according to its own volume mode, the target volume mode tells the cloner which stream
+// the upload server expects.
+func MakeCloneSourcePodSpec(image, pullPolicy, ownerRefAnno string,
+	clientKey, clientCert, serverCACert []byte, sourcePvc, targetPvc *corev1.PersistentVolumeClaim, resourceRequirements *corev1.ResourceRequirements,
 	workloadNodePlacement *sdkapi.NodePlacement) *corev1.Pod {
 
 	var ownerID string
@@ -517,7 +873,7 @@ func MakeCloneSourcePodSpec(image, pullPolicy, sourcePvcName, sourcePvcNamespace
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cloneSourcePodName,
-			Namespace: sourcePvcNamespace,
+			Namespace: sourcePvc.Namespace,
 			Annotations: map[string]string{
 				AnnCreatedBy: "yes",
 				AnnOwnerRef:  ownerRefAnno,
@@ -580,7 +936,7 @@ func MakeCloneSourcePodSpec(image, pullPolicy, sourcePvcName, sourcePvcNamespace
 					Name: DataVolName,
 					VolumeSource: corev1.VolumeSource{
 						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-							ClaimName: sourcePvcName,
+							ClaimName: sourcePvc.Name,
 							// Seems to be problematic with k8s-1.17 provider
 							// with SELinux enabled.  Why?  I do not know right now.
 							//ReadOnly:  true,
@@ -626,16 +982,9 @@ func MakeCloneSourcePodSpec(image, pullPolicy, sourcePvcName, sourcePvcNamespace
 		pod.Spec.Containers[0].Resources = *resourceRequirements
 	}
 
-	var volumeMode corev1.PersistentVolumeMode
 	var addVars []corev1.EnvVar
 
-	if targetPvc.Spec.VolumeMode != nil {
-		volumeMode = *targetPvc.Spec.VolumeMode
-	} else {
-		volumeMode = corev1.PersistentVolumeFilesystem
-	}
-
-	if volumeMode == corev1.PersistentVolumeBlock {
+	if getVolumeMode(sourcePvc) == corev1.PersistentVolumeBlock {
 		pod.Spec.Containers[0].VolumeDevices = addVolumeDevices()
 		addVars = []corev1.EnvVar{
 			{
@@ -666,6 +1015,28 @@ func MakeCloneSourcePodSpec(image, pullPolicy, sourcePvcName, sourcePvcNamespace
 		}
 	}
 
+	if codec, ok := targetPvc.Annotations[AnnCloneCodec]; ok {
+		addVars = append(addVars, corev1.EnvVar{
+			Name:  common.CloneCodec,
+			Value: codec,
+		})
+	}
+	if level, ok := targetPvc.Annotations[AnnCloneCodecLevel]; ok {
+		addVars = append(addVars, corev1.EnvVar{
+			Name:  common.CloneCodecLevel,
+			Value: level,
+		})
+	}
+
+	targetVolumeMode := "filesystem"
+	if getVolumeMode(targetPvc) == corev1.PersistentVolumeBlock {
+		targetVolumeMode = "block"
+	}
+	addVars = append(addVars, corev1.EnvVar{
+		Name:  "TARGET_VOLUME_MODE",
+		Value: targetVolumeMode,
+	})
+
 	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, addVars...)
 	SetPodPvcAnnotations(pod, targetPvc)
 	return pod
@@ -683,9 +1054,7 @@ func validateCloneToken(validator token.Validator, source, target *corev1.Persis
 	}
 
 	if tokenData.Operation != token.OperationClone ||
-		tokenData.Name != source.Name ||
-		tokenData.Namespace != source.Namespace ||
-		tokenData.Resource.Resource != "persistentvolumeclaims" ||
+		!isCloneTokenSource(tokenData, source) ||
 		tokenData.Params["targetNamespace"] != target.Namespace ||
 		tokenData.Params["targetName"] != target.Name {
 		return errors.New("invalid token")
@@ -694,6 +1063,23 @@ func validateCloneToken(validator token.Validator, source, target *corev1.Persis
 	return nil
 }
 
+// isCloneTokenSource checks the token was issued for the source PVC, or for the snapshot the source PVC was restored
+// from when cloning from a snapshot
+func isCloneTokenSource(tokenData *token.Payload, source *corev1.PersistentVolumeClaim) bool {
+	if tokenData.Namespace != source.Namespace {
+		return false
+	}
+	switch tokenData.Resource.Resource {
+	case "persistentvolumeclaims":
+		return tokenData.Name == source.Name
+	case "volumesnapshots":
+		dataSource := source.Spec.DataSource
+		return dataSource != nil && dataSource.Kind == "VolumeSnapshot" && dataSource.Name == tokenData.Name &&
+			source.Labels[common.CDIComponentLabel] == common.SnapshotRestoreCDILabel
+	}
+	return false
+}
+
 // ParseCloneRequestAnnotation parses the clone request annotation
 func ParseCloneRequestAnnotation(pvc *corev1.PersistentVolumeClaim) (exists bool, namespace, name string) {
 	var ann string
@@ -713,6 +1099,7 @@ func ParseCloneRequestAnnotation(pvc *corev1.PersistentVolumeClaim) (exists bool
 }
 
 // ValidateCanCloneSourceAndTargetSpec validates the specs passed in are compatible for cloning.
+// Source and target volume modes may differ, the host-assisted clone then streams a raw disk image.
 func ValidateCanCloneSourceAndTargetSpec(sourceSpec, targetSpec *corev1.PersistentVolumeClaimSpec) error {
 	sourceRequest := sourceSpec.Resources.Requests[corev1.ResourceStorage]
 	targetRequest := targetSpec.Resources.Requests[corev1.ResourceStorage]
@@ -720,19 +1107,6 @@ func ValidateCanCloneSourceAndTargetSpec(sourceSpec, targetSpec *corev1.Persiste
 	if sourceRequest.Value() > targetRequest.Value() {
 		return errors.New("target resources requests storage size is smaller than the source")
 	}
-	// Verify that the source and target volume modes are the same.
-	sourceVolumeMode := corev1.PersistentVolumeFilesystem
-	if sourceSpec.VolumeMode != nil && *sourceSpec.VolumeMode == corev1.PersistentVolumeBlock {
-		sourceVolumeMode = corev1.PersistentVolumeBlock
-	}
-	targetVolumeMode := corev1.PersistentVolumeFilesystem
-	if targetSpec.VolumeMode != nil && *targetSpec.VolumeMode == corev1.PersistentVolumeBlock {
-		targetVolumeMode = corev1.PersistentVolumeBlock
-	}
-	if sourceVolumeMode != targetVolumeMode {
-		return fmt.Errorf("source volumeMode (%s) and target volumeMode (%s) do not match",
-			sourceVolumeMode, targetVolumeMode)
-	}
 	// Can clone.
 	return nil
 }
@@ -71,6 +71,8 @@ type FakeValidator struct {
 	Namespace string
 	Resource  metav1.GroupVersionResource
 	Params    map[string]string
+	// targetParams are the params of additional valid tokens, for clones to several targets
+	targetParams map[string]map[string]string
 }
 
 var _ = Describe("Clone controller reconcile loop", func() {
@@ -407,25 +409,46 @@ var _ = Describe("Clone controller reconcile loop", func() {
 		Expect(sourcePod).To(BeNil())
 	})
 
-	It("Should error when source and target volume modes do not match (fs->block)", func() {
-		testPvc := createBlockPvc("testPvc1", "default", map[string]string{
-			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "foobaz", AnnUploadClientName: "uploadclient", AnnCloneSourcePod: "default-testPvc1-source-pod"}, nil)
-		reconciler = createCloneReconciler(testPvc, createPvc("source", "default", map[string]string{}, nil))
+	DescribeTable("Should create a source pod converting the volume mode", func(sourcePvc, targetPvc *corev1.PersistentVolumeClaim, sourceVolumeMode, targetVolumeMode string) {
+		reconciler = createCloneReconciler(targetPvc, sourcePvc)
 		By("Setting up the match token")
 		reconciler.tokenValidator.(*FakeValidator).match = "foobaz"
 		reconciler.tokenValidator.(*FakeValidator).Name = "source"
 		reconciler.tokenValidator.(*FakeValidator).Namespace = "default"
 		reconciler.tokenValidator.(*FakeValidator).Params["targetNamespace"] = "default"
 		reconciler.tokenValidator.(*FakeValidator).Params["targetName"] = "testPvc1"
-		By("Verifying no source pod exists")
-		sourcePod, err := reconciler.findCloneSourcePod(testPvc)
-		Expect(sourcePod).To(BeNil())
-		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
-		Expect(err).To(HaveOccurred())
-		Expect(err.Error()).To(ContainSubstring("source volumeMode (Filesystem) and target volumeMode (Block) do not match"))
-	})
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		By("Verifying the source pod mounts the source by its own volume mode")
+		sourcePod, err := reconciler.findCloneSourcePod(targetPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sourcePod).ToNot(BeNil())
+		container := sourcePod.Spec.Containers[0]
+		if sourceVolumeMode == "block" {
+			Expect(container.VolumeDevices).To(HaveLen(1))
+			Expect(container.VolumeMounts).To(BeEmpty())
+		} else {
+			Expect(container.VolumeDevices).To(BeEmpty())
+			Expect(container.VolumeMounts[0].MountPath).To(Equal(common.ClonerMountPath))
+		}
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "VOLUME_MODE", Value: sourceVolumeMode}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "TARGET_VOLUME_MODE", Value: targetVolumeMode}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: common.CloneCodec, Value: "zstd"}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: common.CloneCodecLevel, Value: "5"}))
+	},
+		Entry("fs->block", createPvc("source", "default", map[string]string{}, nil),
+			createBlockPvc("testPvc1", "default", map[string]string{
+				AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "foobaz", AnnUploadClientName: "uploadclient", AnnCloneSourcePod: "default-testPvc1-source-pod",
+				AnnCloneCodec: "zstd", AnnCloneCodecLevel: "5"}, nil),
+			"filesystem", "block"),
+		Entry("block->fs", createBlockPvc("source", "default", map[string]string{}, nil),
+			createPvc("testPvc1", "default", map[string]string{
+				AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "foobaz", AnnUploadClientName: "uploadclient", AnnCloneSourcePod: "default-testPvc1-source-pod",
+				AnnCloneCodec: "zstd", AnnCloneCodecLevel: "5"}, nil),
+			"block", "filesystem"),
+	)
 
-	It("Should error when source and target volume modes do not match (block->fs)", func() {
+	It("Should error when the filesystem target cannot hold the block source with the filesystem overhead", func() {
 		testPvc := createPvc("testPvc1", "default", map[string]string{
 			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "foobaz", AnnUploadClientName: "uploadclient", AnnCloneSourcePod: "default-testPvc1-source-pod"}, nil)
 		reconciler = createCloneReconciler(testPvc, createBlockPvc("source", "default", map[string]string{}, nil))
@@ -435,14 +458,173 @@ var _ = Describe("Clone controller reconcile loop", func() {
 		reconciler.tokenValidator.(*FakeValidator).Namespace = "default"
 		reconciler.tokenValidator.(*FakeValidator).Params["targetNamespace"] = "default"
 		reconciler.tokenValidator.(*FakeValidator).Params["targetName"] = "testPvc1"
-		By("Verifying no source pod exists")
+		By("Setting a filesystem overhead")
+		cdiConfig := &cdiv1.CDIConfig{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		cdiConfig.Status.FilesystemOverhead = &cdiv1.FilesystemOverhead{Global: "0.055"}
+		Expect(reconciler.client.Update(context.TODO(), cdiConfig)).To(Succeed())
+		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("smaller than the block source"))
 		sourcePod, err := reconciler.findCloneSourcePod(testPvc)
+		Expect(err).ToNot(HaveOccurred())
 		Expect(sourcePod).To(BeNil())
+	})
+
+	It("Should create a single fan-out source pod for the targets cloning the same source", func() {
+		testPvc1 := createPvc("testPvc1", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "token1", AnnUploadClientName: "uploadclient1"}, nil)
+		testPvc2 := createPvc("testPvc2", "other", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "token2", AnnUploadClientName: "uploadclient2"}, nil)
+		notReadyPvc := createPvc("testPvc3", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "false", AnnCloneToken: "token3", AnnUploadClientName: "uploadclient3"}, nil)
+		otherCodecPvc := createPvc("testPvc4", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "token4", AnnUploadClientName: "uploadclient4", AnnCloneCodec: "zstd"}, nil)
+		reconciler = createCloneReconciler(testPvc1, testPvc2, notReadyPvc, otherCodecPvc, createPvc("source", "default", map[string]string{}, nil))
+		By("Setting up the match tokens")
+		reconciler.tokenValidator.(*FakeValidator).Name = "source"
+		reconciler.tokenValidator.(*FakeValidator).Namespace = "default"
+		reconciler.tokenValidator.(*FakeValidator).targetParams = map[string]map[string]string{
+			"token1": {"targetNamespace": "default", "targetName": "testPvc1"},
+			"token2": {"targetNamespace": "other", "targetName": "testPvc2"},
+		}
+		result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		By("Verifying the target waits for the target that is not ready")
+		Expect(result.RequeueAfter).To(Equal(cloneFanOutBatchDelay))
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, testPvc1)).To(Succeed())
+		Expect(testPvc1.Annotations).ToNot(HaveKey(AnnCloneSourcePod))
+		Expect(testPvc1.Annotations).To(HaveKey(AnnCloneFanOutWaitStart))
+		testPvc1.Annotations[AnnCloneFanOutWaitStart] = time.Now().Add(-cloneFanOutBatchDelay).Format(time.RFC3339)
+		Expect(reconciler.client.Update(context.TODO(), testPvc1)).To(Succeed())
 		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
-		Expect(err).To(HaveOccurred())
-		Expect(err.Error()).To(ContainSubstring("source volumeMode (Block) and target volumeMode (Filesystem) do not match"))
+		Expect(err).ToNot(HaveOccurred())
+		By("Verifying the ready targets are assigned the fan-out source pod once done waiting")
+		for _, key := range []types.NamespacedName{{Name: "testPvc1", Namespace: "default"}, {Name: "testPvc2", Namespace: "other"}} {
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(reconciler.client.Get(context.TODO(), key, pvc)).To(Succeed())
+			Expect(pvc.Annotations[AnnCloneSourcePod]).To(Equal("default-testPvc1-fanout-source-pod"))
+			Expect(pvc.Annotations).ToNot(HaveKey(AnnCloneFanOutWaitStart))
+			Expect(reconciler.hasFinalizer(pvc, cloneSourcePodFinalizer)).To(BeTrue())
+		}
+		for _, key := range []types.NamespacedName{{Name: "testPvc3", Namespace: "default"}, {Name: "testPvc4", Namespace: "default"}} {
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(reconciler.client.Get(context.TODO(), key, pvc)).To(Succeed())
+			Expect(pvc.Annotations).ToNot(HaveKey(AnnCloneSourcePod))
+		}
+		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc2", Namespace: "other"}})
+		Expect(err).ToNot(HaveOccurred())
+		By("Verifying the source pod streams to both targets")
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, testPvc1)).To(Succeed())
+		sourcePod, err := reconciler.findCloneSourcePod(testPvc1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sourcePod).ToNot(BeNil())
+		Expect(sourcePod.Name).To(Equal("default-testPvc1-fanout-source-pod"))
+		Expect(strings.Split(sourcePod.Annotations[AnnCloneTargets], ",")).To(ConsistOf("default/testPvc1", "other/testPvc2"))
+		env := map[string]string{}
+		for _, envVar := range sourcePod.Spec.Containers[0].Env {
+			env[envVar.Name] = envVar.Value
+		}
+		Expect(env).ToNot(HaveKey("UPLOAD_URL"))
+		Expect(env).ToNot(HaveKey("CLIENT_CERT"))
+		Expect(env[common.CloneTargets]).To(Equal("2"))
+		Expect([]string{env["CLONE_TARGET_0_UPLOAD_URL"], env["CLONE_TARGET_1_UPLOAD_URL"]}).To(ConsistOf(
+			GetUploadServerURL("default", "testPvc1", common.UploadPathSync),
+			GetUploadServerURL("other", "testPvc2", common.UploadPathSync)))
+		Expect(env["CLONE_TARGET_0_CLIENT_CERT"]).To(Equal("foo"))
+		Expect(env["SERVER_CA_CERT"]).To(Equal("baz"))
+		Expect(cloneFanOutTargetFailed(sourcePod, testPvc1)).To(BeFalse())
+	})
+
+	It("Should share the source pod with a target that became ready while waiting", func() {
+		testPvc1 := createPvc("testPvc1", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "token1", AnnUploadClientName: "uploadclient1"}, nil)
+		testPvc2 := createPvc("testPvc2", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "false", AnnCloneToken: "token2", AnnUploadClientName: "uploadclient2"}, nil)
+		reconciler = createCloneReconciler(testPvc1, testPvc2, createPvc("source", "default", map[string]string{}, nil))
+		result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(cloneFanOutBatchDelay))
+		By("Making the other target ready")
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc2", Namespace: "default"}, testPvc2)).To(Succeed())
+		testPvc2.Annotations[AnnPodReady] = "true"
+		Expect(reconciler.client.Update(context.TODO(), testPvc2)).To(Succeed())
+		result, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		for _, name := range []string{"testPvc1", "testPvc2"} {
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, pvc)).To(Succeed())
+			Expect(pvc.Annotations[AnnCloneSourcePod]).To(Equal("default-testPvc1-fanout-source-pod"))
+		}
+	})
+
+	It("Should not wait for the targets of other sources", func() {
+		testPvc1 := createPvc("testPvc1", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "token1", AnnUploadClientName: "uploadclient1"}, nil)
+		testPvc2 := createPvc("testPvc2", "default", map[string]string{
+			AnnCloneRequest: "default/other", AnnPodReady: "false", AnnCloneToken: "token2", AnnUploadClientName: "uploadclient2"}, nil)
+		reconciler = createCloneReconciler(testPvc1, testPvc2, createPvc("source", "default", map[string]string{}, nil))
+		result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, testPvc1)).To(Succeed())
+		Expect(testPvc1.Annotations[AnnCloneSourcePod]).To(Equal("default-testPvc1-source-pod"))
+		Expect(testPvc1.Annotations).ToNot(HaveKey(AnnCloneFanOutWaitStart))
 	})
 
+	It("Should fall back to a source pod of its own for a target the fan-out pod failed to clone to", func() {
+		fanOutPodName := "default-testPvc1-fanout-source-pod"
+		testPvc1 := createPvc("testPvc1", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "token1", AnnUploadClientName: "uploadclient1", AnnCloneSourcePod: fanOutPodName}, nil)
+		testPvc1.Finalizers = []string{cloneSourcePodFinalizer}
+		testPvc2 := createPvc("testPvc2", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneToken: "token2", AnnUploadClientName: "uploadclient2", AnnCloneSourcePod: fanOutPodName}, nil)
+		testPvc2.Finalizers = []string{cloneSourcePodFinalizer}
+		reconciler = createCloneReconciler(testPvc1, testPvc2, createPvc("source", "default", map[string]string{}, nil),
+			createFanOutSourcePod(fanOutPodName, "default/testPvc1,default/testPvc2", `{"message":"Clone Complete","failedTargets":["default/testPvc2"]}`))
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc2", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc2", Namespace: "default"}, testPvc2)).To(Succeed())
+		Expect(testPvc2.Annotations[AnnCloneSourcePod]).To(Equal("default-testPvc2-source-pod"))
+		Expect(reconciler.hasFinalizer(testPvc2, cloneSourcePodFinalizer)).To(BeTrue())
+		By("Verifying the fan-out pod is kept for the other target")
+		sourcePod, err := reconciler.findCloneSourcePod(testPvc1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sourcePod).ToNot(BeNil())
+		Expect(cloneFanOutTargetFailed(sourcePod, testPvc1)).To(BeFalse())
+	})
+
+	It("Should keep the fan-out source pod until its last target completes", func() {
+		fanOutPodName := "default-testPvc1-fanout-source-pod"
+		testPvc1 := createPvc("testPvc1", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneSourcePod: fanOutPodName, AnnCloneOf: "true", AnnPodPhase: string(corev1.PodSucceeded)}, nil)
+		testPvc1.Finalizers = []string{cloneSourcePodFinalizer}
+		testPvc2 := createPvc("testPvc2", "default", map[string]string{
+			AnnCloneRequest: "default/source", AnnPodReady: "true", AnnCloneSourcePod: fanOutPodName}, nil)
+		testPvc2.Finalizers = []string{cloneSourcePodFinalizer}
+		reconciler = createCloneReconciler(testPvc1, testPvc2, createFanOutSourcePod(fanOutPodName, "default/testPvc1,default/testPvc2", cloneComplete))
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		pvc := &corev1.PersistentVolumeClaim{}
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, pvc)).To(Succeed())
+		Expect(reconciler.hasFinalizer(pvc, cloneSourcePodFinalizer)).To(BeFalse())
+		sourcePod, err := reconciler.findCloneSourcePod(testPvc2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sourcePod).ToNot(BeNil())
+
+		By("Completing the last target")
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc2", Namespace: "default"}, testPvc2)).To(Succeed())
+		testPvc2.Annotations[AnnCloneOf] = "true"
+		testPvc2.Annotations[AnnPodPhase] = string(corev1.PodSucceeded)
+		Expect(reconciler.client.Update(context.TODO(), testPvc2)).To(Succeed())
+		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc2", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		sourcePod, err = reconciler.findCloneSourcePod(testPvc2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sourcePod).To(BeNil())
+	})
 })
 
 var _ = Describe("ParseCloneRequestAnnotation", func() {
@@ -572,7 +754,7 @@ var _ = Describe("Update PVC", func() {
 
 var _ = Describe("TokenValidation", func() {
 	g := token.NewGenerator(common.CloneTokenIssuer, getAPIServerKey(), 5*time.Minute)
-	v := newCloneTokenValidator(&getAPIServerKey().PublicKey)
+	v := newCloneTokenValidator(token.PublicKeys{&getAPIServerKey().PublicKey})
 
 	goodTokenData := func() *token.Payload {
 		return &token.Payload{
@@ -650,6 +832,48 @@ var _ = Describe("TokenValidation", func() {
 		Entry("fail on bad targetNamespace", badTargetNamespace, false),
 		Entry("fail on bad missing parameters", missingParams, false),
 	)
+
+	DescribeTable("should validate a snapshot token against the PVC restored from the snapshot", func(restoreLabel string, expectedSuccess bool) {
+		p := goodTokenData()
+		p.Name = "snapshot"
+		p.Resource.Resource = "volumesnapshots"
+		tokenString, err := g.Generate(p)
+		Expect(err).ToNot(HaveOccurred())
+
+		restored := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "restored",
+				Namespace: "sourcens",
+				Labels: map[string]string{
+					common.CDIComponentLabel: restoreLabel,
+				},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				DataSource: &corev1.TypedLocalObjectReference{
+					Kind: "VolumeSnapshot",
+					Name: "snapshot",
+				},
+			},
+		}
+		target := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "target",
+				Namespace: "targetns",
+				Annotations: map[string]string{
+					AnnCloneToken: tokenString,
+				},
+			},
+		}
+		err = validateCloneToken(v, restored, target)
+		if expectedSuccess {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("succeed for a CDI restored PVC", common.SnapshotRestoreCDILabel, true),
+		Entry("fail for another PVC restored from the snapshot", "", false),
+	)
 })
 
 func createCloneReconciler(objects ...runtime.Object) *CloneReconciler {
@@ -696,9 +920,13 @@ func getAPIServerKey() *rsa.PrivateKey {
 }
 
 func (v *FakeValidator) Validate(value string) (*token.Payload, error) {
-	if value != v.match {
+	params, ok := v.targetParams[value]
+	if !ok && value != v.match {
 		return nil, fmt.Errorf("Token does not match expected")
 	}
+	if !ok {
+		params = v.Params
+	}
 	resource := metav1.GroupVersionResource{
 		Resource: "persistentvolumeclaims",
 	}
@@ -707,10 +935,38 @@ func (v *FakeValidator) Validate(value string) (*token.Payload, error) {
 		Namespace: v.Namespace,
 		Operation: token.OperationClone,
 		Resource:  resource,
-		Params:    v.Params,
+		Params:    params,
 	}, nil
 }
 
+func createFanOutSourcePod(name, targets, terminationMessage string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				CloneUniqueID: name,
+			},
+			Annotations: map[string]string{
+				AnnOwnerRef:     "default/testPvc1",
+				AnnCloneTargets: targets,
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							Message: terminationMessage,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func createClonePvc(sourceNamespace, sourceName, targetNamespace, targetName string, annotations, labels map[string]string) *corev1.PersistentVolumeClaim {
 	return createClonePvcWithSize(sourceNamespace, sourceName, targetNamespace, targetName, annotations, labels, "1G")
 }
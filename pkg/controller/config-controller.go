@@ -29,6 +29,7 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/operator"
 	"kubevirt.io/containerized-data-importer/pkg/util"
+	"kubevirt.io/containerized-data-importer/pkg/util/clonecodec"
 )
 
 // AnnConfigAuthority is the annotation specifying a resource as the CDIConfig authority
@@ -81,6 +82,10 @@ func (r *CDIConfigReconciler) Reconcile(req reconcile.Request) (reconcile.Result
 		return reconcile.Result{}, err
 	}
 
+	if err := r.reconcileStorageProfiles(); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	if err := r.reconcileDefaultPodResourceRequirements(config); err != nil {
 		return reconcile.Result{}, err
 	}
@@ -89,6 +94,11 @@ func (r *CDIConfigReconciler) Reconcile(req reconcile.Request) (reconcile.Result
 		return reconcile.Result{}, err
 	}
 
+	r.reconcilePreallocation(config)
+	r.reconcileMaxDownloadConcurrency(config)
+	r.reconcileCloneCodec(config)
+	r.reconcileUploadTokenMaxExpiration(config)
+
 	if !reflect.DeepEqual(currentConfigCopy, config) {
 		// Updates have happened, update CDIConfig.
 		log.Info("Updating CDIConfig", "CDIConfig.Name", config.Name, "config", config)
@@ -248,6 +258,38 @@ func (r *CDIConfigReconciler) reconcileDefaultPodResourceRequirements(config *cd
 	return nil
 }
 
+func (r *CDIConfigReconciler) reconcilePreallocation(config *cdiv1.CDIConfig) {
+	config.Status.Preallocation = config.Spec.Preallocation
+}
+
+func (r *CDIConfigReconciler) reconcileMaxDownloadConcurrency(config *cdiv1.CDIConfig) {
+	config.Status.MaxDownloadConcurrency = common.DefaultMaxDownloadConcurrency
+	if config.Spec.MaxDownloadConcurrency != nil && *config.Spec.MaxDownloadConcurrency > 0 {
+		config.Status.MaxDownloadConcurrency = *config.Spec.MaxDownloadConcurrency
+	}
+}
+
+func (r *CDIConfigReconciler) reconcileCloneCodec(config *cdiv1.CDIConfig) {
+	config.Status.CloneCodec = &cdiv1.CloneCodec{Name: cdiv1.CloneCodecSnappy}
+	if config.Spec.CloneCodec != nil {
+		if err := clonecodec.Validate(string(config.Spec.CloneCodec.Name)); err != nil {
+			r.log.Error(err, "Invalid clone codec, using the default")
+			return
+		}
+		config.Status.CloneCodec = config.Spec.CloneCodec.DeepCopy()
+	}
+}
+
+func (r *CDIConfigReconciler) reconcileUploadTokenMaxExpiration(config *cdiv1.CDIConfig) {
+	config.Status.UploadTokenMaxExpirationSeconds = common.DefaultUploadTokenMaxExpirationSeconds
+	if config.Spec.UploadTokenMaxExpirationSeconds != nil && *config.Spec.UploadTokenMaxExpirationSeconds > 0 {
+		config.Status.UploadTokenMaxExpirationSeconds = *config.Spec.UploadTokenMaxExpirationSeconds
+	}
+	if config.Status.UploadTokenMaxExpirationSeconds > common.MaxUploadTokenMaxExpirationSeconds {
+		config.Status.UploadTokenMaxExpirationSeconds = common.MaxUploadTokenMaxExpirationSeconds
+	}
+}
+
 func (r *CDIConfigReconciler) reconcileFilesystemOverhead(config *cdiv1.CDIConfig) error {
 	var globalOverhead cdiv1.Percent = common.DefaultGlobalOverhead
 	var perStorageConfig = make(map[string]cdiv1.Percent)
@@ -412,6 +454,16 @@ func addConfigControllerWatches(mgr manager.Manager, configController controller
 	if err != nil {
 		return err
 	}
+	err = configController.Watch(&source.Kind{Type: &cdiv1.StorageProfile{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			return []reconcile.Request{{
+				NamespacedName: types.NamespacedName{Name: configName},
+			}}
+		}),
+	})
+	if err != nil {
+		return err
+	}
 	err = configController.Watch(&source.Kind{Type: &extensionsv1beta1.Ingress{}}, &handler.EnqueueRequestsFromMapFunc{
 		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
 			return []reconcile.Request{{
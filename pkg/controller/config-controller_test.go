@@ -65,6 +65,68 @@ var _ = Describe("CDIConfig Controller reconcile loop", func() {
 		Expect(err).ToNot(HaveOccurred())
 	})
 
+	DescribeTable("Should set the maximum download concurrency", func(max *int32, expected int32) {
+		reconciler, cdiConfig := createConfigReconciler(createConfigMap(operator.ConfigMapName, testNamespace))
+		cdi, err := GetActiveCDI(reconciler.client)
+		Expect(err).ToNot(HaveOccurred())
+		cdi.Spec.Config = &cdiv1.CDIConfigSpec{
+			MaxDownloadConcurrency: max,
+		}
+		err = reconciler.client.Update(context.TODO(), cdi)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = reconciler.Reconcile(reconcile.Request{})
+		Expect(err).ToNot(HaveOccurred())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: reconciler.configName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cdiConfig.Status.MaxDownloadConcurrency).To(Equal(expected))
+	},
+		Entry("to the default", nil, int32(common.DefaultMaxDownloadConcurrency)),
+		Entry("to the override", func() *int32 { max := int32(10); return &max }(), int32(10)),
+		Entry("to the default with an invalid override", func() *int32 { max := int32(0); return &max }(), int32(common.DefaultMaxDownloadConcurrency)),
+	)
+
+	DescribeTable("Should set the clone codec", func(codec *cdiv1.CloneCodec, expected cdiv1.CloneCodec) {
+		reconciler, cdiConfig := createConfigReconciler(createConfigMap(operator.ConfigMapName, testNamespace))
+		cdi, err := GetActiveCDI(reconciler.client)
+		Expect(err).ToNot(HaveOccurred())
+		cdi.Spec.Config = &cdiv1.CDIConfigSpec{
+			CloneCodec: codec,
+		}
+		err = reconciler.client.Update(context.TODO(), cdi)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = reconciler.Reconcile(reconcile.Request{})
+		Expect(err).ToNot(HaveOccurred())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: reconciler.configName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cdiConfig.Status.CloneCodec).ToNot(BeNil())
+		Expect(*cdiConfig.Status.CloneCodec).To(Equal(expected))
+	},
+		Entry("to the default", nil, cdiv1.CloneCodec{Name: cdiv1.CloneCodecSnappy}),
+		Entry("to the override", &cdiv1.CloneCodec{Name: cdiv1.CloneCodecZstd, Level: &[]int32{5}[0]}, cdiv1.CloneCodec{Name: cdiv1.CloneCodecZstd, Level: &[]int32{5}[0]}),
+		Entry("to the default with an invalid override", &cdiv1.CloneCodec{Name: "lz4"}, cdiv1.CloneCodec{Name: cdiv1.CloneCodecSnappy}),
+	)
+
+	DescribeTable("Should set the upload token max expiration", func(max *int64, expected int64) {
+		reconciler, cdiConfig := createConfigReconciler(createConfigMap(operator.ConfigMapName, testNamespace))
+		cdi, err := GetActiveCDI(reconciler.client)
+		Expect(err).ToNot(HaveOccurred())
+		cdi.Spec.Config = &cdiv1.CDIConfigSpec{
+			UploadTokenMaxExpirationSeconds: max,
+		}
+		err = reconciler.client.Update(context.TODO(), cdi)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = reconciler.Reconcile(reconcile.Request{})
+		Expect(err).ToNot(HaveOccurred())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: reconciler.configName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cdiConfig.Status.UploadTokenMaxExpirationSeconds).To(Equal(expected))
+	},
+		Entry("to the default", nil, int64(common.DefaultUploadTokenMaxExpirationSeconds)),
+		Entry("to the override", &[]int64{86400}[0], int64(86400)),
+		Entry("to the default with an invalid override", &[]int64{-1}[0], int64(common.DefaultUploadTokenMaxExpirationSeconds)),
+		Entry("to the maximum with a longer override", &[]int64{7 * 86400}[0], int64(common.MaxUploadTokenMaxExpirationSeconds)),
+	)
+
 	DescribeTable("Should set proxyURL to override if no ingress or route exists", func(authority bool) {
 		reconciler, cdiConfig := createConfigReconciler(createConfigMap(operator.ConfigMapName, testNamespace))
 		_, err := reconciler.Reconcile(reconcile.Request{})
@@ -0,0 +1,597 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+	"kubevirt.io/containerized-data-importer/pkg/util/naming"
+)
+
+const (
+	// LabelDataImportCron is the label of the DataVolumes and poller pods of a DataImportCron, set to its name
+	LabelDataImportCron = AnnAPIGroup + "/dataImportCron"
+
+	// defaultImportsToKeep is the number of imported DataVolumes kept by the garbage collection, unless the DataImportCron overrides it
+	defaultImportsToKeep = 3
+
+	dataImportCronPollerName = "poller"
+
+	dataImportCronNoDigest   = "NoDigest"
+	dataImportCronNoImport   = "NoImport"
+	dataImportCronInProgress = "ImportProgressing"
+	dataImportCronUpToDate   = "UpToDate"
+	// MessageDataImportCronNoDigest is the message of the UpToDate condition of a DataImportCron which source was not polled yet
+	MessageDataImportCronNoDigest = "Waiting for the first poll of the source"
+	// MessageDataImportCronInProgress is the message of the conditions of a DataImportCron while it imports a new source digest
+	MessageDataImportCronInProgress = "Importing source digest %s into DataVolume %s"
+)
+
+// DataImportCronReconciler members
+type DataImportCronReconciler struct {
+	client         client.Client
+	uncachedClient client.Client
+	scheme         *runtime.Scheme
+	log            logr.Logger
+	image          string
+	verbose        string
+	pullPolicy     string
+}
+
+// Reconcile polls the source of a DataImportCron on its schedule, imports new source digests into DataVolumes,
+// points its managed DataSource to the last import and garbage collects the outdated ones
+func (r *DataImportCronReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("DataImportCron", req.NamespacedName)
+
+	dataImportCron := &cdiv1.DataImportCron{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, dataImportCron); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if dataImportCron.DeletionTimestamp != nil {
+		log.Info("DataImportCron marked for deletion, skipping")
+		return reconcile.Result{}, nil
+	}
+	if getDataImportCronSourceURL(dataImportCron) == "" {
+		log.Info("DataImportCron template has no registry or http source, skipping")
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.reconcileCronJob(dataImportCron); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	dataImportCronCopy := dataImportCron.DeepCopy()
+	if err := r.updatePolledDigest(dataImportCronCopy); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.reconcileImport(dataImportCronCopy); err != nil {
+		return reconcile.Result{}, err
+	}
+	if !reflect.DeepEqual(dataImportCron, dataImportCronCopy) {
+		log.V(3).Info("Updating DataImportCron", "digest", dataImportCronCopy.Annotations[AnnSourceDesiredDigest])
+		if err := r.client.Update(context.TODO(), dataImportCronCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// reconcileCronJob creates the CronJob polling the source of the DataImportCron, and keeps it in sync with the DataImportCron
+func (r *DataImportCronReconciler) reconcileCronJob(dataImportCron *cdiv1.DataImportCron) error {
+	desired, err := r.newCronJob(dataImportCron)
+	if err != nil {
+		return err
+	}
+	cronJob := &batchv1beta1.CronJob{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, cronJob); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		r.log.V(1).Info("Creating CronJob", "name", desired.Name)
+		return r.client.Create(context.TODO(), desired)
+	}
+	if reflect.DeepEqual(cronJob.Spec.Schedule, desired.Spec.Schedule) &&
+		reflect.DeepEqual(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers, desired.Spec.JobTemplate.Spec.Template.Spec.Containers) &&
+		reflect.DeepEqual(cronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes, desired.Spec.JobTemplate.Spec.Template.Spec.Volumes) {
+		return nil
+	}
+	cronJobCopy := cronJob.DeepCopy()
+	cronJobCopy.Spec.Schedule = desired.Spec.Schedule
+	cronJobCopy.Spec.JobTemplate.Spec.Template.Spec.Containers = desired.Spec.JobTemplate.Spec.Template.Spec.Containers
+	cronJobCopy.Spec.JobTemplate.Spec.Template.Spec.Volumes = desired.Spec.JobTemplate.Spec.Template.Spec.Volumes
+	r.log.V(1).Info("Updating CronJob", "name", desired.Name)
+	return r.client.Update(context.TODO(), cronJobCopy)
+}
+
+// newCronJob creates the CronJob running the importer in polling mode, its pods report the source digest in their
+// termination message
+func (r *DataImportCronReconciler) newCronJob(dataImportCron *cdiv1.DataImportCron) (*batchv1beta1.CronJob, error) {
+	var sourceType, secretName, certConfigMap string
+	source := dataImportCron.Spec.Template.Spec.Source
+	switch {
+	case source.Registry != nil:
+		sourceType, secretName, certConfigMap = SourceRegistry, source.Registry.SecretRef, source.Registry.CertConfigMap
+	case source.HTTP != nil:
+		sourceType, secretName, certConfigMap = SourceHTTP, source.HTTP.SecretRef, source.HTTP.CertConfigMap
+	}
+	endpoint := getDataImportCronSourceURL(dataImportCron)
+	insecureTLS, err := isInsecureTLS(r.uncachedClient, r.log, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	container := corev1.Container{
+		Name:            "cdi-source-update-" + dataImportCronPollerName,
+		Image:           r.image,
+		ImagePullPolicy: corev1.PullPolicy(r.pullPolicy),
+		Args:            []string{"-v=" + r.verbose},
+		Env: []corev1.EnvVar{
+			{
+				Name:  common.ImporterPollSourceDigest,
+				Value: "true",
+			},
+			{
+				Name:  common.ImporterSource,
+				Value: sourceType,
+			},
+			{
+				Name:  common.ImporterEndpoint,
+				Value: endpoint,
+			},
+			{
+				Name:  common.InsecureTLSVar,
+				Value: strconv.FormatBool(insecureTLS),
+			},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+	}
+	var volumes []corev1.Volume
+	if secretName != "" {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name: common.ImporterAccessKeyID,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: secretName,
+					},
+					Key: common.KeyAccess,
+				},
+			},
+		}, corev1.EnvVar{
+			Name: common.ImporterSecretKey,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: secretName,
+					},
+					Key: common.KeySecret,
+				},
+			},
+		})
+	}
+	if certConfigMap != "" {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  common.ImporterCertDirVar,
+			Value: common.ImporterCertDir,
+		})
+		container.VolumeMounts = []corev1.VolumeMount{
+			{
+				Name:      CertVolName,
+				MountPath: common.ImporterCertDir,
+			},
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: CertVolName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: certConfigMap,
+					},
+				},
+			},
+		})
+	}
+
+	historyLimit := int32(1)
+	backoffLimit := int32(2)
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      naming.GetCronJobName(dataImportCron.Name, dataImportCronPollerName),
+			Namespace: dataImportCron.Namespace,
+			Labels: map[string]string{
+				common.CDILabelKey:       common.CDILabelValue,
+				common.CDIComponentLabel: dataImportCronPollerName,
+				LabelDataImportCron:      dataImportCron.Name,
+			},
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   dataImportCron.Spec.Schedule,
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &historyLimit,
+			FailedJobsHistoryLimit:     &historyLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								common.CDILabelKey:       common.CDILabelValue,
+								common.CDIComponentLabel: dataImportCronPollerName,
+								LabelDataImportCron:      dataImportCron.Name,
+							},
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers:    []corev1.Container{container},
+							Volumes:       volumes,
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(dataImportCron, cronJob, r.scheme); err != nil {
+		return nil, err
+	}
+	return cronJob, nil
+}
+
+// updatePolledDigest records the source digest reported by the last succeeded poller pod of the DataImportCron
+func (r *DataImportCronReconciler) updatePolledDigest(dataImportCron *cdiv1.DataImportCron) error {
+	pods := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), pods, client.InNamespace(dataImportCron.Namespace), client.MatchingLabels{LabelDataImportCron: dataImportCron.Name}); err != nil {
+		return err
+	}
+	var lastPoll *metav1.Time
+	digest := ""
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if terminated == nil || terminated.ExitCode != 0 {
+				continue
+			}
+			termMsg := util.ParseTerminationMessage(terminated.Message)
+			if termMsg.SourceDigest == "" {
+				continue
+			}
+			if lastPoll == nil || terminated.FinishedAt.After(lastPoll.Time) {
+				finishedAt := terminated.FinishedAt
+				lastPoll = &finishedAt
+				digest = termMsg.SourceDigest
+			}
+		}
+	}
+	if lastPoll == nil {
+		return nil
+	}
+	last := dataImportCron.Status.LastExecutionTimestamp
+	if last != nil && !lastPoll.After(last.Time) {
+		return nil
+	}
+	dataImportCron.Status.LastExecutionTimestamp = lastPoll
+	if dataImportCron.Annotations == nil {
+		dataImportCron.Annotations = make(map[string]string)
+	}
+	dataImportCron.Annotations[AnnSourceDesiredDigest] = digest
+	return nil
+}
+
+// reconcileImport imports the desired source digest if it wasn't imported yet, and updates the status of the
+// DataImportCron from the state of the import
+func (r *DataImportCronReconciler) reconcileImport(dataImportCron *cdiv1.DataImportCron) error {
+	status := &dataImportCron.Status
+	digest := dataImportCron.Annotations[AnnSourceDesiredDigest]
+	if digest == "" {
+		status.Conditions = updateDataImportCronCondition(status.Conditions, cdiv1.DataImportCronProgressing, corev1.ConditionFalse, "", dataImportCronNoImport)
+		status.Conditions = updateDataImportCronCondition(status.Conditions, cdiv1.DataImportCronUpToDate, corev1.ConditionFalse, MessageDataImportCronNoDigest, dataImportCronNoDigest)
+		return nil
+	}
+
+	dvName := getDataImportCronDataVolumeName(dataImportCron, digest)
+	dv := &cdiv1.DataVolume{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dataImportCron.Namespace, Name: dvName}, dv); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		dv = newDataImportCronDataVolume(dataImportCron, dvName, digest)
+		r.log.V(1).Info("Importing new source digest", "digest", digest, "DataVolume", dvName)
+		if err := r.client.Create(context.TODO(), dv); err != nil {
+			return err
+		}
+	}
+
+	if dv.Status.Phase != cdiv1.Succeeded {
+		status.CurrentImports = []cdiv1.ImportStatus{{DataVolumeName: dvName, Digest: digest}}
+		message := fmt.Sprintf(MessageDataImportCronInProgress, digest, dvName)
+		status.Conditions = updateDataImportCronCondition(status.Conditions, cdiv1.DataImportCronProgressing, corev1.ConditionTrue, message, dataImportCronInProgress)
+		status.Conditions = updateDataImportCronCondition(status.Conditions, cdiv1.DataImportCronUpToDate, corev1.ConditionFalse, message, dataImportCronInProgress)
+		return nil
+	}
+
+	status.CurrentImports = nil
+	if status.LastImportedPVC == nil || status.LastImportedPVC.Name != dvName {
+		now := metav1.Now()
+		status.LastImportedPVC = &cdiv1.DataVolumeSourcePVC{Namespace: dataImportCron.Namespace, Name: dvName}
+		status.LastImportTimestamp = &now
+	}
+	status.Conditions = updateDataImportCronCondition(status.Conditions, cdiv1.DataImportCronProgressing, corev1.ConditionFalse, "", dataImportCronNoImport)
+	status.Conditions = updateDataImportCronCondition(status.Conditions, cdiv1.DataImportCronUpToDate, corev1.ConditionTrue, "", dataImportCronUpToDate)
+
+	if err := r.updateDataSource(dataImportCron); err != nil {
+		return err
+	}
+	return r.garbageCollectOldImports(dataImportCron)
+}
+
+// updateDataSource points the DataSource managed by the DataImportCron to its last imported PVC
+func (r *DataImportCronReconciler) updateDataSource(dataImportCron *cdiv1.DataImportCron) error {
+	name := dataImportCron.Spec.ManagedDataSource
+	if name == "" {
+		return nil
+	}
+	dataSource := &cdiv1.DataSource{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dataImportCron.Namespace, Name: name}, dataSource); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		dataSource = &cdiv1.DataSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: dataImportCron.Namespace,
+				Labels: map[string]string{
+					LabelDataImportCron: dataImportCron.Name,
+				},
+			},
+			Spec: cdiv1.DataSourceSpec{
+				Source: cdiv1.DataSourceSource{
+					PVC: dataImportCron.Status.LastImportedPVC.DeepCopy(),
+				},
+			},
+		}
+		r.log.V(1).Info("Creating managed DataSource", "name", name)
+		return r.client.Create(context.TODO(), dataSource)
+	}
+	if reflect.DeepEqual(dataSource.Spec.Source.PVC, dataImportCron.Status.LastImportedPVC) {
+		return nil
+	}
+	dataSourceCopy := dataSource.DeepCopy()
+	dataSourceCopy.Spec.Source.PVC = dataImportCron.Status.LastImportedPVC.DeepCopy()
+	r.log.V(1).Info("Updating managed DataSource", "name", name, "PVC", dataImportCron.Status.LastImportedPVC.Name)
+	return r.client.Update(context.TODO(), dataSourceCopy)
+}
+
+// garbageCollectOldImports deletes the oldest DataVolumes imported by the DataImportCron, keeping the last ImportsToKeep
+func (r *DataImportCronReconciler) garbageCollectOldImports(dataImportCron *cdiv1.DataImportCron) error {
+	gc := dataImportCron.Spec.GarbageCollect
+	if gc != nil && *gc == cdiv1.DataImportCronGarbageCollectNever {
+		return nil
+	}
+	importsToKeep := int32(defaultImportsToKeep)
+	if dataImportCron.Spec.ImportsToKeep != nil {
+		importsToKeep = *dataImportCron.Spec.ImportsToKeep
+	}
+	dvs := &cdiv1.DataVolumeList{}
+	if err := r.client.List(context.TODO(), dvs, client.InNamespace(dataImportCron.Namespace), client.MatchingLabels{LabelDataImportCron: dataImportCron.Name}); err != nil {
+		return err
+	}
+	if int32(len(dvs.Items)) <= importsToKeep {
+		return nil
+	}
+	sort.Slice(dvs.Items, func(i, j int) bool {
+		ti, tj := dvs.Items[i].CreationTimestamp, dvs.Items[j].CreationTimestamp
+		if ti.Equal(&tj) {
+			return dvs.Items[i].Name > dvs.Items[j].Name
+		}
+		return tj.Before(&ti)
+	})
+	for i := range dvs.Items {
+		dv := &dvs.Items[i]
+		if int32(i) < importsToKeep || dv.Name == dataImportCron.Status.LastImportedPVC.Name {
+			continue
+		}
+		r.log.V(1).Info("Deleting outdated import", "DataVolume", dv.Name)
+		if err := r.client.Delete(context.TODO(), dv); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDataImportCronSourceURL returns the URL of the registry or http source of the DataImportCron template
+func getDataImportCronSourceURL(dataImportCron *cdiv1.DataImportCron) string {
+	source := dataImportCron.Spec.Template.Spec.Source
+	switch {
+	case source.Registry != nil:
+		return source.Registry.URL
+	case source.HTTP != nil:
+		return source.HTTP.URL
+	}
+	return ""
+}
+
+// getDataImportCronDataVolumeName returns the name of the DataVolume importing the source digest, derived from the digest
+// so every new digest gets its own DataVolume
+func getDataImportCronDataVolumeName(dataImportCron *cdiv1.DataImportCron, digest string) string {
+	hash := strings.TrimPrefix(digest, "sha256:")
+	if hash == digest || len(hash) < 12 {
+		sum := sha256.Sum256([]byte(digest))
+		hash = hex.EncodeToString(sum[:])
+	}
+	return naming.GetResourceName(dataImportCron.Name, hash[:12])
+}
+
+// getPinnedRegistryURL replaces the tag or digest of the registry URL by the digest
+func getPinnedRegistryURL(url, digest string) string {
+	repository := url
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	} else if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	return repository + "@" + digest
+}
+
+// newDataImportCronDataVolume creates the DataVolume importing the source digest from the DataImportCron template.
+// The DataVolume is not owned by the DataImportCron, so the imported images outlive it.
+func newDataImportCronDataVolume(dataImportCron *cdiv1.DataImportCron, name, digest string) *cdiv1.DataVolume {
+	template := dataImportCron.Spec.Template.DeepCopy()
+	dv := &cdiv1.DataVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   dataImportCron.Namespace,
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+		},
+		Spec: template.Spec,
+	}
+	if dv.Labels == nil {
+		dv.Labels = make(map[string]string)
+	}
+	dv.Labels[LabelDataImportCron] = dataImportCron.Name
+	if dv.Annotations == nil {
+		dv.Annotations = make(map[string]string)
+	}
+	dv.Annotations[AnnImportDigest] = digest
+	if registry := dv.Spec.Source.Registry; registry != nil && strings.HasPrefix(digest, "sha256:") {
+		registry.URL = getPinnedRegistryURL(registry.URL, digest)
+	}
+	return dv
+}
+
+func findDataImportCronConditionByType(conditionType cdiv1.DataImportCronConditionType, conditions []cdiv1.DataImportCronCondition) *cdiv1.DataImportCronCondition {
+	for i, condition := range conditions {
+		if condition.Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func updateDataImportCronCondition(conditions []cdiv1.DataImportCronCondition, conditionType cdiv1.DataImportCronConditionType, status corev1.ConditionStatus, message, reason string) []cdiv1.DataImportCronCondition {
+	condition := findDataImportCronConditionByType(conditionType, conditions)
+	if condition == nil {
+		conditions = append(conditions, cdiv1.DataImportCronCondition{
+			Type: conditionType,
+		})
+		condition = findDataImportCronConditionByType(conditionType, conditions)
+	}
+	if condition.Status != status {
+		condition.LastTransitionTime = metav1.Now()
+		condition.Message = message
+		condition.Reason = reason
+		condition.LastHeartbeatTime = condition.LastTransitionTime
+	} else if condition.Message != message || condition.Reason != reason {
+		condition.Message = message
+		condition.Reason = reason
+		condition.LastHeartbeatTime = metav1.Now()
+	}
+	condition.Status = status
+	return conditions
+}
+
+// NewDataImportCronController creates a new instance of the DataImportCron controller
+func NewDataImportCronController(mgr manager.Manager, log logr.Logger, importerImage, pullPolicy, verbose string) (controller.Controller, error) {
+	uncachedClient, err := client.New(mgr.GetConfig(), client.Options{
+		Scheme: mgr.GetScheme(),
+		Mapper: mgr.GetRESTMapper(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	reconciler := &DataImportCronReconciler{
+		client:         mgr.GetClient(),
+		uncachedClient: uncachedClient,
+		scheme:         mgr.GetScheme(),
+		log:            log.WithName("dataimportcron-controller"),
+		image:          importerImage,
+		verbose:        verbose,
+		pullPolicy:     pullPolicy,
+	}
+	dataImportCronController, err := controller.New("dataimportcron-controller", mgr, controller.Options{
+		Reconciler: reconciler,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := addDataImportCronControllerWatches(mgr, dataImportCronController); err != nil {
+		return nil, err
+	}
+	return dataImportCronController, nil
+}
+
+func addDataImportCronControllerWatches(mgr manager.Manager, dataImportCronController controller.Controller) error {
+	if err := cdiv1.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+
+	// Setup watches
+	if err := dataImportCronController.Watch(&source.Kind{Type: &cdiv1.DataImportCron{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	if err := dataImportCronController.Watch(&source.Kind{Type: &batchv1beta1.CronJob{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &cdiv1.DataImportCron{},
+		IsController: true,
+	}); err != nil {
+		return err
+	}
+	// The poller pods and the imported DataVolumes are labeled with the name of their DataImportCron
+	mapToDataImportCron := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			name, ok := obj.Meta.GetLabels()[LabelDataImportCron]
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.Meta.GetNamespace(), Name: name}}}
+		}),
+	}
+	if err := dataImportCronController.Watch(&source.Kind{Type: &corev1.Pod{}}, mapToDataImportCron); err != nil {
+		return err
+	}
+	if err := dataImportCronController.Watch(&source.Kind{Type: &cdiv1.DataVolume{}}, mapToDataImportCron); err != nil {
+		return err
+	}
+	return nil
+}
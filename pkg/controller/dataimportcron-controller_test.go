@@ -0,0 +1,289 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+	"kubevirt.io/containerized-data-importer/pkg/util/naming"
+)
+
+var (
+	dicLog = logf.Log.WithName("dataimportcron-controller-test")
+)
+
+const (
+	testDigest       = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testRegistryURL  = "docker://quay.io/kubevirt/fedora-cloud-container-disk-demo:latest"
+	testCronSchedule = "0 0 * * *"
+)
+
+var _ = Describe("DataImportCron controller reconcile loop", func() {
+	cronKey := types.NamespacedName{Name: "test-cron", Namespace: metav1.NamespaceDefault}
+
+	reconcileCron := func(reconciler *DataImportCronReconciler) *cdiv1.DataImportCron {
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: cronKey})
+		Expect(err).ToNot(HaveOccurred())
+		dataImportCron := &cdiv1.DataImportCron{}
+		err = reconciler.client.Get(context.TODO(), cronKey, dataImportCron)
+		Expect(err).ToNot(HaveOccurred())
+		return dataImportCron
+	}
+
+	getCronJob := func(reconciler *DataImportCronReconciler) *batchv1beta1.CronJob {
+		cronJob := &batchv1beta1.CronJob{}
+		name := naming.GetCronJobName(cronKey.Name, dataImportCronPollerName)
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cronKey.Namespace}, cronJob)
+		Expect(err).ToNot(HaveOccurred())
+		return cronJob
+	}
+
+	It("Should create a CronJob polling the source on the schedule", func() {
+		reconciler := createDataImportCronReconciler(newDataImportCron(cronKey.Name, testRegistryURL))
+		dataImportCron := reconcileCron(reconciler)
+		cronJob := getCronJob(reconciler)
+		Expect(cronJob.Spec.Schedule).To(Equal(testCronSchedule))
+		Expect(metav1.IsControlledBy(cronJob, dataImportCron)).To(BeTrue())
+		pod := cronJob.Spec.JobTemplate.Spec.Template
+		Expect(pod.Labels[LabelDataImportCron]).To(Equal(cronKey.Name))
+		Expect(pod.Spec.Containers[0].Image).To(Equal("test/myimage"))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: common.ImporterPollSourceDigest, Value: "true"}))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: common.ImporterSource, Value: SourceRegistry}))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: common.ImporterEndpoint, Value: testRegistryURL}))
+
+		condition := findDataImportCronConditionByType(cdiv1.DataImportCronUpToDate, dataImportCron.Status.Conditions)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(dataImportCronNoDigest))
+	})
+
+	It("Should update the CronJob schedule", func() {
+		dataImportCron := newDataImportCron(cronKey.Name, testRegistryURL)
+		reconciler := createDataImportCronReconciler(dataImportCron)
+		dataImportCron = reconcileCron(reconciler)
+		dataImportCron.Spec.Schedule = "*/5 * * * *"
+		Expect(reconciler.client.Update(context.TODO(), dataImportCron)).To(Succeed())
+		reconcileCron(reconciler)
+		Expect(getCronJob(reconciler).Spec.Schedule).To(Equal("*/5 * * * *"))
+	})
+
+	It("Should import the digest reported by the poller pod", func() {
+		reconciler := createDataImportCronReconciler(newDataImportCron(cronKey.Name, testRegistryURL), newPollerPod(cronKey.Name, testDigest, time.Now()))
+		dataImportCron := reconcileCron(reconciler)
+		Expect(dataImportCron.Annotations[AnnSourceDesiredDigest]).To(Equal(testDigest))
+		Expect(dataImportCron.Status.LastExecutionTimestamp).ToNot(BeNil())
+
+		dvName := getDataImportCronDataVolumeName(dataImportCron, testDigest)
+		Expect(dvName).To(Equal("test-cron-0123456789ab"))
+		Expect(dataImportCron.Status.CurrentImports).To(Equal([]cdiv1.ImportStatus{{DataVolumeName: dvName, Digest: testDigest}}))
+		condition := findDataImportCronConditionByType(cdiv1.DataImportCronProgressing, dataImportCron.Status.Conditions)
+		Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+
+		dv := &cdiv1.DataVolume{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: dvName, Namespace: cronKey.Namespace}, dv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dv.Labels[LabelDataImportCron]).To(Equal(cronKey.Name))
+		Expect(dv.Annotations[AnnImportDigest]).To(Equal(testDigest))
+		Expect(dv.Spec.Source.Registry.URL).To(Equal("docker://quay.io/kubevirt/fedora-cloud-container-disk-demo@" + testDigest))
+	})
+
+	It("Should ignore poller pods older than the last execution", func() {
+		dataImportCron := newDataImportCron(cronKey.Name, testRegistryURL)
+		lastExecution := metav1.NewTime(time.Now().Truncate(time.Second))
+		dataImportCron.Status.LastExecutionTimestamp = &lastExecution
+		reconciler := createDataImportCronReconciler(dataImportCron, newPollerPod(cronKey.Name, testDigest, lastExecution.Add(-time.Hour)))
+		dataImportCron = reconcileCron(reconciler)
+		Expect(dataImportCron.Annotations).ToNot(HaveKey(AnnSourceDesiredDigest))
+	})
+
+	It("Should name the DataVolumes of an http source by the hash of the ETag", func() {
+		dataImportCron := newDataImportCron(cronKey.Name, "")
+		dataImportCron.Spec.Template.Spec.Source = cdiv1.DataVolumeSource{HTTP: &cdiv1.DataVolumeSourceHTTP{URL: "http://example.com/disk.img"}}
+		reconciler := createDataImportCronReconciler(dataImportCron, newPollerPod(cronKey.Name, `"5f3a-1b2c"`, time.Now()))
+		dataImportCron = reconcileCron(reconciler)
+		Expect(dataImportCron.Status.CurrentImports).To(HaveLen(1))
+		dvName := dataImportCron.Status.CurrentImports[0].DataVolumeName
+		Expect(dvName).To(MatchRegexp("^test-cron-[0-9a-f]{12}$"))
+		dv := &cdiv1.DataVolume{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: dvName, Namespace: cronKey.Namespace}, dv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dv.Spec.Source.HTTP.URL).To(Equal("http://example.com/disk.img"))
+	})
+
+	It("Should point the managed DataSource to the last import once it succeeded", func() {
+		dataImportCron := newDataImportCron(cronKey.Name, testRegistryURL)
+		dataImportCron.Annotations = map[string]string{AnnSourceDesiredDigest: testDigest}
+		dvName := getDataImportCronDataVolumeName(dataImportCron, testDigest)
+		reconciler := createDataImportCronReconciler(dataImportCron, newDataImportCronDataVolumeInPhase(dvName, cdiv1.Succeeded, time.Now()))
+		dataImportCron = reconcileCron(reconciler)
+		Expect(dataImportCron.Status.CurrentImports).To(BeEmpty())
+		Expect(dataImportCron.Status.LastImportedPVC).To(Equal(&cdiv1.DataVolumeSourcePVC{Namespace: cronKey.Namespace, Name: dvName}))
+		Expect(dataImportCron.Status.LastImportTimestamp).ToNot(BeNil())
+		condition := findDataImportCronConditionByType(cdiv1.DataImportCronUpToDate, dataImportCron.Status.Conditions)
+		Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+
+		dataSource := &cdiv1.DataSource{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-datasource", Namespace: cronKey.Namespace}, dataSource)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dataSource.Spec.Source.PVC).To(Equal(dataImportCron.Status.LastImportedPVC))
+	})
+
+	DescribeTable("Should garbage collect outdated imports", func(garbageCollect cdiv1.DataImportCronGarbageCollect, remaining []string) {
+		dataImportCron := newDataImportCron(cronKey.Name, testRegistryURL)
+		dataImportCron.Annotations = map[string]string{AnnSourceDesiredDigest: testDigest}
+		dataImportCron.Spec.GarbageCollect = &garbageCollect
+		importsToKeep := int32(2)
+		dataImportCron.Spec.ImportsToKeep = &importsToKeep
+		dvName := getDataImportCronDataVolumeName(dataImportCron, testDigest)
+		now := time.Now()
+		reconciler := createDataImportCronReconciler(dataImportCron,
+			newDataImportCronDataVolumeInPhase("oldest", cdiv1.Succeeded, now.Add(-3*time.Hour)),
+			newDataImportCronDataVolumeInPhase("older", cdiv1.Succeeded, now.Add(-2*time.Hour)),
+			newDataImportCronDataVolumeInPhase("old", cdiv1.Succeeded, now.Add(-time.Hour)),
+			newDataImportCronDataVolumeInPhase(dvName, cdiv1.Succeeded, now))
+		reconcileCron(reconciler)
+		dvs := &cdiv1.DataVolumeList{}
+		err := reconciler.client.List(context.TODO(), dvs, client.InNamespace(cronKey.Namespace))
+		Expect(err).ToNot(HaveOccurred())
+		var names []string
+		for _, dv := range dvs.Items {
+			names = append(names, dv.Name)
+		}
+		Expect(names).To(ConsistOf(remaining))
+	},
+		Entry("keeping the last ImportsToKeep", cdiv1.DataImportCronGarbageCollectOutdated, []string{"old", "test-cron-0123456789ab"}),
+		Entry("unless disabled", cdiv1.DataImportCronGarbageCollectNever, []string{"oldest", "older", "old", "test-cron-0123456789ab"}),
+	)
+
+	It("Should not garbage collect while the new import is in progress", func() {
+		dataImportCron := newDataImportCron(cronKey.Name, testRegistryURL)
+		dataImportCron.Annotations = map[string]string{AnnSourceDesiredDigest: testDigest}
+		importsToKeep := int32(1)
+		dataImportCron.Spec.ImportsToKeep = &importsToKeep
+		dvName := getDataImportCronDataVolumeName(dataImportCron, testDigest)
+		reconciler := createDataImportCronReconciler(dataImportCron,
+			newDataImportCronDataVolumeInPhase("old", cdiv1.Succeeded, time.Now().Add(-time.Hour)),
+			newDataImportCronDataVolumeInPhase(dvName, cdiv1.ImportInProgress, time.Now()))
+		reconcileCron(reconciler)
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "old", Namespace: cronKey.Namespace}, &cdiv1.DataVolume{})
+		Expect(k8serrors.IsNotFound(err)).To(BeFalse())
+	})
+
+	DescribeTable("Should pin the registry URL to the digest", func(url, expected string) {
+		Expect(getPinnedRegistryURL(url, testDigest)).To(Equal(expected))
+	},
+		Entry("with a tag", "docker://quay.io/kubevirt/fedora:latest", "docker://quay.io/kubevirt/fedora@"+testDigest),
+		Entry("without a tag", "docker://quay.io/kubevirt/fedora", "docker://quay.io/kubevirt/fedora@"+testDigest),
+		Entry("with a registry port", "docker://registry:5000/fedora:33", "docker://registry:5000/fedora@"+testDigest),
+		Entry("with a digest", "docker://quay.io/kubevirt/fedora@sha256:1234", "docker://quay.io/kubevirt/fedora@"+testDigest),
+	)
+})
+
+func createDataImportCronReconciler(objects ...runtime.Object) *DataImportCronReconciler {
+	s := scheme.Scheme
+	cdiv1.AddToScheme(s)
+	cl := fake.NewFakeClientWithScheme(s, objects...)
+	return &DataImportCronReconciler{
+		client:         cl,
+		uncachedClient: cl,
+		scheme:         s,
+		log:            dicLog,
+		image:          "test/myimage",
+		verbose:        "5",
+		pullPolicy:     "Always",
+	}
+}
+
+func newDataImportCron(name, url string) *cdiv1.DataImportCron {
+	return &cdiv1.DataImportCron{
+		TypeMeta: metav1.TypeMeta{APIVersion: cdiv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			UID:       types.UID(metav1.NamespaceDefault + "-" + name),
+		},
+		Spec: cdiv1.DataImportCronSpec{
+			Template: cdiv1.DataVolume{
+				Spec: cdiv1.DataVolumeSpec{
+					Source: cdiv1.DataVolumeSource{
+						Registry: &cdiv1.DataVolumeSourceRegistry{
+							URL: url,
+						},
+					},
+					PVC: &corev1.PersistentVolumeClaimSpec{},
+				},
+			},
+			Schedule:          testCronSchedule,
+			ManagedDataSource: "test-datasource",
+		},
+	}
+}
+
+func newPollerPod(cronName, digest string, finishedAt time.Time) *corev1.Pod {
+	message, err := json.Marshal(util.TerminationMessage{Message: "Source polled", SourceDigest: digest})
+	Expect(err).ToNot(HaveOccurred())
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cronName + "-poller",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				LabelDataImportCron: cronName,
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode:   0,
+							Message:    string(message),
+							FinishedAt: metav1.NewTime(finishedAt),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newDataImportCronDataVolumeInPhase(name string, phase cdiv1.DataVolumePhase, created time.Time) *cdiv1.DataVolume {
+	dv := newDataVolumeInPhase(name, phase)
+	dv.CreationTimestamp = metav1.NewTime(created)
+	dv.Labels = map[string]string{LabelDataImportCron: "test-cron"}
+	return dv
+}
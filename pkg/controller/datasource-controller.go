@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+)
+
+const (
+	// dataSourcePvcField is the field index of the DataSources by the namespace/name of their source PVC
+	dataSourcePvcField = "spec.source.pvc"
+
+	dataSourceReady    = "Ready"
+	dataSourceNoSource = "NoSource"
+	// MessageDataSourceNoSource is the message of the Ready condition of a DataSource without source
+	MessageDataSourceNoSource = "No source PVC set"
+	// MessageDataSourcePvcNotFound is the message of the Ready condition of a DataSource when its PVC doesn't exist
+	MessageDataSourcePvcNotFound = "Source PVC %s/%s not found"
+	// MessageDataSourceDataVolumeNotReady is the message of the Ready condition of a DataSource when the DataVolume of its PVC is not done
+	MessageDataSourceDataVolumeNotReady = "DataVolume %s/%s is in phase %s"
+)
+
+// DataSourceReconciler members
+type DataSourceReconciler struct {
+	client client.Client
+	scheme *runtime.Scheme
+	log    logr.Logger
+}
+
+// Reconcile updates the Ready condition of a DataSource from the state of its source PVC
+func (r *DataSourceReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("DataSource", req.NamespacedName)
+
+	dataSource := &cdiv1.DataSource{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, dataSource); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if dataSource.DeletionTimestamp != nil {
+		log.Info("DataSource marked for deletion, skipping")
+		return reconcile.Result{}, nil
+	}
+
+	status, reason, message, err := r.getReadyState(dataSource)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	dataSourceCopy := dataSource.DeepCopy()
+	dataSourceCopy.Status.Conditions = updateDataSourceCondition(dataSourceCopy.Status.Conditions, cdiv1.DataSourceReady, status, message, reason)
+	if !reflect.DeepEqual(dataSource, dataSourceCopy) {
+		log.V(3).Info("Updating DataSource Ready condition", "status", status, "reason", reason)
+		if err := r.client.Update(context.TODO(), dataSourceCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// getReadyState returns the state of the Ready condition of the DataSource. The DataSource is ready when its PVC
+// exists, and when the PVC belongs to a DataVolume, once the DataVolume succeeded.
+func (r *DataSourceReconciler) getReadyState(dataSource *cdiv1.DataSource) (corev1.ConditionStatus, string, string, error) {
+	sourcePVC := dataSource.Spec.Source.PVC
+	if sourcePVC == nil {
+		return corev1.ConditionFalse, dataSourceNoSource, MessageDataSourceNoSource, nil
+	}
+	namespace := getDataSourcePvcNamespace(dataSource)
+	key := types.NamespacedName{Namespace: namespace, Name: sourcePVC.Name}
+
+	dv := &cdiv1.DataVolume{}
+	if err := r.client.Get(context.TODO(), key, dv); err == nil {
+		if dv.Status.Phase != cdiv1.Succeeded {
+			return corev1.ConditionFalse, string(dv.Status.Phase), fmt.Sprintf(MessageDataSourceDataVolumeNotReady, namespace, dv.Name, dv.Status.Phase), nil
+		}
+		return corev1.ConditionTrue, dataSourceReady, "", nil
+	} else if !k8serrors.IsNotFound(err) {
+		return "", "", "", err
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(context.TODO(), key, pvc); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return corev1.ConditionFalse, notFound, fmt.Sprintf(MessageDataSourcePvcNotFound, namespace, sourcePVC.Name), nil
+		}
+		return "", "", "", err
+	}
+	return corev1.ConditionTrue, dataSourceReady, "", nil
+}
+
+func getDataSourcePvcNamespace(dataSource *cdiv1.DataSource) string {
+	if dataSource.Spec.Source.PVC.Namespace != "" {
+		return dataSource.Spec.Source.PVC.Namespace
+	}
+	return dataSource.Namespace
+}
+
+func findDataSourceConditionByType(conditionType cdiv1.DataSourceConditionType, conditions []cdiv1.DataSourceCondition) *cdiv1.DataSourceCondition {
+	for i, condition := range conditions {
+		if condition.Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func updateDataSourceCondition(conditions []cdiv1.DataSourceCondition, conditionType cdiv1.DataSourceConditionType, status corev1.ConditionStatus, message, reason string) []cdiv1.DataSourceCondition {
+	condition := findDataSourceConditionByType(conditionType, conditions)
+	if condition == nil {
+		conditions = append(conditions, cdiv1.DataSourceCondition{
+			Type: conditionType,
+		})
+		condition = findDataSourceConditionByType(conditionType, conditions)
+	}
+	if condition.Status != status {
+		condition.LastTransitionTime = metav1.Now()
+		condition.Message = message
+		condition.Reason = reason
+		condition.LastHeartbeatTime = condition.LastTransitionTime
+	} else if condition.Message != message || condition.Reason != reason {
+		condition.Message = message
+		condition.Reason = reason
+		condition.LastHeartbeatTime = metav1.Now()
+	}
+	condition.Status = status
+	return conditions
+}
+
+// NewDataSourceController creates a new instance of the DataSource controller
+func NewDataSourceController(mgr manager.Manager, log logr.Logger) (controller.Controller, error) {
+	reconciler := &DataSourceReconciler{
+		client: mgr.GetClient(),
+		scheme: mgr.GetScheme(),
+		log:    log.WithName("datasource-controller"),
+	}
+	dataSourceController, err := controller.New("datasource-controller", mgr, controller.Options{
+		Reconciler: reconciler,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := addDataSourceControllerWatches(mgr, dataSourceController); err != nil {
+		return nil, err
+	}
+	return dataSourceController, nil
+}
+
+func addDataSourceControllerWatches(mgr manager.Manager, dataSourceController controller.Controller) error {
+	if err := cdiv1.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &cdiv1.DataSource{}, dataSourcePvcField, func(obj runtime.Object) []string {
+		dataSource := obj.(*cdiv1.DataSource)
+		if dataSource.Spec.Source.PVC == nil {
+			return nil
+		}
+		return []string{getDataSourcePvcNamespace(dataSource) + "/" + dataSource.Spec.Source.PVC.Name}
+	}); err != nil {
+		return err
+	}
+
+	// Setup watches
+	if err := dataSourceController.Watch(&source.Kind{Type: &cdiv1.DataSource{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	// The DataSources referencing a PVC are reconciled when the PVC or its DataVolume change
+	mapToDataSources := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			return mapPvcToDataSources(mgr.GetClient(), obj.Meta.GetNamespace(), obj.Meta.GetName())
+		}),
+	}
+	if err := dataSourceController.Watch(&source.Kind{Type: &cdiv1.DataVolume{}}, mapToDataSources); err != nil {
+		return err
+	}
+	if err := dataSourceController.Watch(&source.Kind{Type: &corev1.PersistentVolumeClaim{}}, mapToDataSources); err != nil {
+		return err
+	}
+	return nil
+}
+
+func mapPvcToDataSources(c client.Client, namespace, name string) []reconcile.Request {
+	var dataSources cdiv1.DataSourceList
+	if err := c.List(context.TODO(), &dataSources, client.MatchingFields{dataSourcePvcField: namespace + "/" + name}); err != nil {
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, dataSource := range dataSources.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: dataSource.Namespace, Name: dataSource.Name}})
+	}
+	return reqs
+}
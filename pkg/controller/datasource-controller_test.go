@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+)
+
+var (
+	dsLog = logf.Log.WithName("datasource-controller-test")
+)
+
+var _ = Describe("DataSource controller reconcile loop", func() {
+	getReadyCondition := func(reconciler *DataSourceReconciler) *cdiv1.DataSourceCondition {
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-datasource", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		dataSource := &cdiv1.DataSource{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-datasource", Namespace: metav1.NamespaceDefault}, dataSource)
+		Expect(err).ToNot(HaveOccurred())
+		return findDataSourceConditionByType(cdiv1.DataSourceReady, dataSource.Status.Conditions)
+	}
+
+	DescribeTable("Should set the Ready condition", func(sourcePvc string, objects []runtime.Object, status corev1.ConditionStatus, reason string) {
+		dataSource := newDataSource("test-datasource", sourcePvc)
+		if sourcePvc == "" {
+			dataSource.Spec.Source.PVC = nil
+		}
+		reconciler := createDataSourceReconciler(append(objects, dataSource)...)
+		condition := getReadyCondition(reconciler)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(status))
+		Expect(condition.Reason).To(Equal(reason))
+	},
+		Entry("to false without source PVC", "", nil, corev1.ConditionFalse, dataSourceNoSource),
+		Entry("to false if the PVC doesn't exist", "test", nil, corev1.ConditionFalse, notFound),
+		Entry("to true if the PVC exists", "test",
+			[]runtime.Object{createPvc("test", metav1.NamespaceDefault, nil, nil)}, corev1.ConditionTrue, dataSourceReady),
+		Entry("to false while the DataVolume of the PVC is in progress", "test",
+			[]runtime.Object{createPvc("test", metav1.NamespaceDefault, nil, nil), newDataVolumeInPhase("test", cdiv1.ImportInProgress)},
+			corev1.ConditionFalse, string(cdiv1.ImportInProgress)),
+		Entry("to true once the DataVolume of the PVC succeeded", "test",
+			[]runtime.Object{createPvc("test", metav1.NamespaceDefault, nil, nil), newDataVolumeInPhase("test", cdiv1.Succeeded)},
+			corev1.ConditionTrue, dataSourceReady),
+	)
+
+	It("Should update the Ready condition when the PVC goes away", func() {
+		pvc := createPvc("test", metav1.NamespaceDefault, nil, nil)
+		reconciler := createDataSourceReconciler(newDataSource("test-datasource", "test"), pvc)
+		condition := getReadyCondition(reconciler)
+		Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+		Expect(reconciler.client.Delete(context.TODO(), pvc)).To(Succeed())
+		condition = getReadyCondition(reconciler)
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(notFound))
+	})
+})
+
+func createDataSourceReconciler(objects ...runtime.Object) *DataSourceReconciler {
+	s := scheme.Scheme
+	cdiv1.AddToScheme(s)
+	cl := fake.NewFakeClientWithScheme(s, objects...)
+	return &DataSourceReconciler{
+		client: cl,
+		scheme: s,
+		log:    dsLog,
+	}
+}
+
+func newDataSource(name, pvcName string) *cdiv1.DataSource {
+	return &cdiv1.DataSource{
+		TypeMeta: metav1.TypeMeta{APIVersion: cdiv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: cdiv1.DataSourceSpec{
+			Source: cdiv1.DataSourceSource{
+				PVC: &cdiv1.DataVolumeSourcePVC{
+					Namespace: metav1.NamespaceDefault,
+					Name:      pvcName,
+				},
+			},
+		},
+	}
+}
+
+func newDataVolumeInPhase(name string, phase cdiv1.DataVolumePhase) *cdiv1.DataVolume {
+	dv := newImportDataVolume(name)
+	dv.Status.Phase = phase
+	return dv
+}
@@ -164,6 +164,10 @@ type DatavolumeReconciler struct {
 	scheme       *runtime.Scheme
 	log          logr.Logger
 	featureGates featuregates.FeatureGates
+	// image, verbose and pullPolicy are used by the pods probing the size of the source
+	image      string
+	verbose    string
+	pullPolicy string
 }
 
 func pvcIsPopulated(pvc *corev1.PersistentVolumeClaim, dv *cdiv1.DataVolume) bool {
@@ -172,7 +176,7 @@ func pvcIsPopulated(pvc *corev1.PersistentVolumeClaim, dv *cdiv1.DataVolume) boo
 }
 
 // NewDatavolumeController creates a new instance of the datavolume controller.
-func NewDatavolumeController(mgr manager.Manager, extClientSet extclientset.Interface, log logr.Logger) (controller.Controller, error) {
+func NewDatavolumeController(mgr manager.Manager, extClientSet extclientset.Interface, log logr.Logger, importerImage, pullPolicy, verbose string) (controller.Controller, error) {
 	client := mgr.GetClient()
 	reconciler := &DatavolumeReconciler{
 		client:       client,
@@ -181,6 +185,9 @@ func NewDatavolumeController(mgr manager.Manager, extClientSet extclientset.Inte
 		log:          log.WithName("datavolume-controller"),
 		recorder:     mgr.GetEventRecorderFor("datavolume-controller"),
 		featureGates: featuregates.NewFeatureGates(client),
+		image:        importerImage,
+		verbose:      verbose,
+		pullPolicy:   pullPolicy,
 	}
 	datavolumeController, err := controller.New("datavolume-controller", mgr, controller.Options{
 		Reconciler: reconciler,
@@ -216,6 +223,13 @@ func addDatavolumeControllerWatches(mgr manager.Manager, datavolumeController co
 	}); err != nil {
 		return err
 	}
+	// The pods probing the size of the source are owned by the DataVolume
+	if err := datavolumeController.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &cdiv1.DataVolume{},
+		IsController: true,
+	}); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -248,6 +262,11 @@ func (r *DatavolumeReconciler) isCSICloneCapable(dv *cdiv1.DataVolume) (bool, er
 		return false, nil
 	}
 
+	if isVolumeModeConversion(sourcePvc, dv.Spec.PVC) {
+		r.log.V(3).Info("Source PVC and target PVC have different volume modes, falling back to host assisted clone")
+		return false, nil
+	}
+
 	ann := targetStorageClass.Annotations[AnnCSICloneCapable]
 
 	if s, err := strconv.ParseBool(ann); s && err == nil {
@@ -257,6 +276,12 @@ func (r *DatavolumeReconciler) isCSICloneCapable(dv *cdiv1.DataVolume) (bool, er
 	return false, nil
 }
 
+// isVolumeModeConversion returns true if the target spec volume mode differs from the one of the source PVC, only the
+// host assisted clone can convert between volume modes
+func isVolumeModeConversion(sourcePvc *corev1.PersistentVolumeClaim, targetSpec *corev1.PersistentVolumeClaimSpec) bool {
+	return getVolumeMode(sourcePvc) != getVolumeMode(&corev1.PersistentVolumeClaim{Spec: *targetSpec})
+}
+
 func NewVolumeClonePVC(dv *cdiv1.DataVolume, pvcStorageClassName string, pvcAccessModes []corev1.PersistentVolumeAccessMode, csiClonePvcType CSIClonePVCType) *corev1.PersistentVolumeClaim {
 	annotations := make(map[string]string)
 	for ann, v := range dv.GetAnnotations() {
@@ -317,7 +342,8 @@ func (r *DatavolumeReconciler) Reconcile(req reconcile.Request) (reconcile.Resul
 	datavolume := &cdiv1.DataVolume{}
 	if err := r.client.Get(context.TODO(), req.NamespacedName, datavolume); err != nil {
 		if k8serrors.IsNotFound(err) {
-			return reconcile.Result{}, nil
+			// the temporary PVCs restored from a snapshot source can't be owned by the DataVolume
+			return reconcile.Result{}, r.cleanupSnapshotRestorePVCs(req.Namespace, req.Name)
 		}
 		return reconcile.Result{}, err
 	}
@@ -327,6 +353,10 @@ func (r *DatavolumeReconciler) Reconcile(req reconcile.Request) (reconcile.Resul
 		return reconcile.Result{}, nil
 	}
 
+	if err := r.resolveSourceRef(datavolume); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	pvcExists := true
 	// Get the pvc with the name specified in DataVolume.spec
 	pvc := &corev1.PersistentVolumeClaim{}
@@ -360,6 +390,27 @@ func (r *DatavolumeReconciler) Reconcile(req reconcile.Request) (reconcile.Resul
 		}
 	}
 
+	if !pvcExists && datavolume.Spec.Storage != nil {
+		if inferred, requeueAfter, err := r.inferStorageSize(datavolume); !inferred || err != nil {
+			return reconcile.Result{RequeueAfter: requeueAfter}, err
+		}
+	}
+	populateStoragePVC(datavolume)
+
+	if datavolume.Spec.Source.Snapshot != nil {
+		if !pvcExists {
+			newPvc, err := r.createPvcFromSnapshot(datavolume)
+			if newPvc == nil || err != nil {
+				return reconcile.Result{Requeue: err == nil}, err
+			}
+			pvc, pvcExists = newPvc, true
+		} else if pvc.Annotations[AnnPodPhase] == string(cdiv1.Succeeded) {
+			if err := r.cleanupSnapshotRestorePVCs(datavolume.Namespace, datavolume.Name); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
 	// Check if CSIClone is possible
 	if isCSICap, err := r.isCSICloneCapable(datavolume); isCSICap && datavolume.Spec.PVC != nil && err == nil {
 		if !pvcExists {
@@ -422,10 +473,14 @@ func (r *DatavolumeReconciler) Reconcile(req reconcile.Request) (reconcile.Resul
 			return reconcile.Result{}, r.updateSmartCloneStatusPhase(cdiv1.SnapshotForSmartCloneInProgress, datavolume)
 		}
 		log.Info("Creating PVC for datavolume")
-		newPvc, err := newPersistentVolumeClaim(datavolume)
+		newPvc, err := newPersistentVolumeClaim(r.client, datavolume)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
+		newPvc.Annotations[AnnPreallocationRequested] = strconv.FormatBool(GetPreallocation(r.client, datavolume))
+		if concurrency := GetDownloadConcurrency(r.client, datavolume); concurrency > 1 {
+			newPvc.Annotations[AnnConcurrency] = strconv.Itoa(int(concurrency))
+		}
 		if err := r.client.Create(context.TODO(), newPvc); err != nil {
 			return reconcile.Result{}, err
 		}
@@ -443,6 +498,48 @@ func (r *DatavolumeReconciler) Reconcile(req reconcile.Request) (reconcile.Resul
 	return r.reconcileDataVolumeStatus(datavolume, pvc)
 }
 
+// resolveSourceRef resolves the sourceRef of the DataVolume to the PVC currently referenced by its DataSource, and
+// records it on the DataVolume the first time, so the source doesn't change if the DataSource is updated later on.
+// The mutating webhook records the PVC it issued the clone token for, the DataSource is only resolved here when the
+// DataVolume was admitted without it. The resolved PVC is set as the source of the DataVolume in memory only.
+func (r *DatavolumeReconciler) resolveSourceRef(dv *cdiv1.DataVolume) error {
+	if dv.Spec.SourceRef == nil || getSourceRefPVC(dv) != nil {
+		populateSourceRefPVC(dv)
+		return nil
+	}
+	if dv.Spec.SourceRef.Kind != cdiv1.DataVolumeDataSource {
+		return errors.Errorf("unsupported sourceRef kind %s, currently only %s is supported", dv.Spec.SourceRef.Kind, cdiv1.DataVolumeDataSource)
+	}
+	namespace := dv.Namespace
+	if dv.Spec.SourceRef.Namespace != nil && *dv.Spec.SourceRef.Namespace != "" {
+		namespace = *dv.Spec.SourceRef.Namespace
+	}
+	dataSource := &cdiv1.DataSource{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: dv.Spec.SourceRef.Name}, dataSource); err != nil {
+		return err
+	}
+	pvcSource := dataSource.Spec.Source.PVC
+	if pvcSource == nil {
+		return errors.Errorf("DataSource %s/%s has no source", namespace, dataSource.Name)
+	}
+	pvcNamespace := pvcSource.Namespace
+	if pvcNamespace == "" {
+		pvcNamespace = namespace
+	}
+	dvCopy := dv.DeepCopy()
+	if dvCopy.Annotations == nil {
+		dvCopy.Annotations = make(map[string]string)
+	}
+	dvCopy.Annotations[AnnSourceRefPVC] = pvcNamespace + "/" + pvcSource.Name
+	if err := r.client.Update(context.TODO(), dvCopy); err != nil {
+		return err
+	}
+	r.log.V(1).Info("Resolved DataVolume sourceRef", "DataVolume", dv.Name, "PVC", dvCopy.Annotations[AnnSourceRefPVC])
+	*dv = *dvCopy
+	populateSourceRefPVC(dv)
+	return nil
+}
+
 func (r *DatavolumeReconciler) setMultistageImportAnnotations(dataVolume *cdiv1.DataVolume, pvc *corev1.PersistentVolumeClaim) error {
 	pvcCopy := pvc.DeepCopy()
 	numCheckpoints := len(dataVolume.Spec.Checkpoints)
@@ -514,7 +611,7 @@ func (r *DatavolumeReconciler) getStorageClassBindingMode(dataVolume *cdiv1.Data
 	return &volumeBindingImmediate, nil
 }
 
-func (r *DatavolumeReconciler) reconcileProgressUpdate(datavolume *cdiv1.DataVolume, pvcUID types.UID) (reconcile.Result, error) {
+func (r *DatavolumeReconciler) reconcileProgressUpdate(datavolume *cdiv1.DataVolume, pvc *corev1.PersistentVolumeClaim) (reconcile.Result, error) {
 	var podNamespace string
 	if datavolume.Status.Progress == "" {
 		datavolume.Status.Progress = "N/A"
@@ -522,6 +619,8 @@ func (r *DatavolumeReconciler) reconcileProgressUpdate(datavolume *cdiv1.DataVol
 
 	if datavolume.Spec.Source.PVC != nil {
 		podNamespace = datavolume.Spec.Source.PVC.Namespace
+	} else if datavolume.Spec.Source.Snapshot != nil {
+		podNamespace = datavolume.Spec.Source.Snapshot.Namespace
 	} else {
 		podNamespace = datavolume.Namespace
 	}
@@ -531,7 +630,7 @@ func (r *DatavolumeReconciler) reconcileProgressUpdate(datavolume *cdiv1.DataVol
 		r.log.Info("Datavolume finished, no longer updating progress", "Namespace", datavolume.Namespace, "Name", datavolume.Name, "Phase", datavolume.Status.Phase)
 		return reconcile.Result{}, nil
 	}
-	pod, err := r.getPodFromPvc(podNamespace, pvcUID)
+	pod, err := r.getPodFromPvc(podNamespace, pvc)
 	if err == nil {
 		if err := updateProgressUsingPod(datavolume, pod); err != nil {
 			return reconcile.Result{}, err
@@ -594,6 +693,12 @@ func (r *DatavolumeReconciler) getSnapshotClassForSmartClone(dataVolume *cdiv1.D
 		return "", errors.New("source PVC and target PVC belong to different namespaces")
 	}
 
+	// Compare source and target volume modes
+	if isVolumeModeConversion(pvc, dataVolume.Spec.PVC) {
+		r.log.V(3).Info("Source PVC and target PVC have different volume modes, falling back to host assisted clone")
+		return "", errors.New("source PVC and target PVC have different volume modes, falling back to host assisted clone")
+	}
+
 	// Fetch the source storage class
 	srcStorageClass := &storagev1.StorageClass{}
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: *sourcePvcStorageClassName}, srcStorageClass); err != nil {
@@ -736,35 +841,45 @@ func (r *DatavolumeReconciler) updateCSICloneDataVolumeStatus(phase cdiv1.DataVo
 func (r *DatavolumeReconciler) updateCloneStatusPhase(pvc *corev1.PersistentVolumeClaim, dataVolumeCopy *cdiv1.DataVolume, event *DataVolumeEvent) {
 	phase, ok := pvc.Annotations[AnnPodPhase]
 	if ok {
+		sourceNamespace, sourceName := getCloneSourceName(dataVolumeCopy)
 		switch phase {
 		case string(corev1.PodPending):
 			// TODO: Use a more generic Scheduled, like maybe TransferScheduled.
 			dataVolumeCopy.Status.Phase = cdiv1.CloneScheduled
 			event.eventType = corev1.EventTypeNormal
 			event.reason = CloneScheduled
-			event.message = fmt.Sprintf(MessageCloneScheduled, dataVolumeCopy.Spec.Source.PVC.Namespace, dataVolumeCopy.Spec.Source.PVC.Name, pvc.Namespace, pvc.Name)
+			event.message = fmt.Sprintf(MessageCloneScheduled, sourceNamespace, sourceName, pvc.Namespace, pvc.Name)
 		case string(corev1.PodRunning):
 			// TODO: Use a more generic In Progess, like maybe TransferInProgress.
 			dataVolumeCopy.Status.Phase = cdiv1.CloneInProgress
 			event.eventType = corev1.EventTypeNormal
 			event.reason = CloneInProgress
-			event.message = fmt.Sprintf(MessageCloneInProgress, dataVolumeCopy.Spec.Source.PVC.Namespace, dataVolumeCopy.Spec.Source.PVC.Name, pvc.Namespace, pvc.Name)
+			event.message = fmt.Sprintf(MessageCloneInProgress, sourceNamespace, sourceName, pvc.Namespace, pvc.Name)
 		case string(corev1.PodFailed):
 			dataVolumeCopy.Status.Phase = cdiv1.Failed
 			event.eventType = corev1.EventTypeWarning
 			event.reason = CloneFailed
-			event.message = fmt.Sprintf(MessageCloneFailed, dataVolumeCopy.Spec.Source.PVC.Namespace, dataVolumeCopy.Spec.Source.PVC.Name, pvc.Namespace, pvc.Name)
+			event.message = fmt.Sprintf(MessageCloneFailed, sourceNamespace, sourceName, pvc.Namespace, pvc.Name)
 		case string(corev1.PodSucceeded):
 			dataVolumeCopy.Status.Phase = cdiv1.Succeeded
 			dataVolumeCopy.Status.Progress = cdiv1.DataVolumeProgress("100.0%")
 			event.eventType = corev1.EventTypeNormal
 			event.reason = CloneSucceeded
-			event.message = fmt.Sprintf(MessageCloneSucceeded, dataVolumeCopy.Spec.Source.PVC.Namespace, dataVolumeCopy.Spec.Source.PVC.Name, pvc.Namespace, pvc.Name)
+			event.message = fmt.Sprintf(MessageCloneSucceeded, sourceNamespace, sourceName, pvc.Namespace, pvc.Name)
 		}
 
 	}
 }
 
+// getCloneSourceName returns the namespace and name of the source of a DataVolume cloned by the host assisted clone,
+// either a PVC or a snapshot restored to a temporary PVC
+func getCloneSourceName(dv *cdiv1.DataVolume) (string, string) {
+	if dv.Spec.Source.Snapshot != nil {
+		return dv.Spec.Source.Snapshot.Namespace, dv.Spec.Source.Snapshot.Name
+	}
+	return dv.Spec.Source.PVC.Namespace, dv.Spec.Source.PVC.Name
+}
+
 func (r *DatavolumeReconciler) updateUploadStatusPhase(pvc *corev1.PersistentVolumeClaim, dataVolumeCopy *cdiv1.DataVolume, event *DataVolumeEvent) {
 	phase, ok := pvc.Annotations[AnnPodPhase]
 	if ok {
@@ -893,6 +1008,13 @@ func (r *DatavolumeReconciler) reconcileDataVolumeStatus(dataVolume *cdiv1.DataV
 						dataVolumeCopy.Status.Phase = cdiv1.UploadScheduled
 						r.updateUploadStatusPhase(pvc, dataVolumeCopy, &event)
 					}
+					snapshot, ok := pvc.Annotations[AnnSnapshotRestore]
+					if ok {
+						dataVolumeCopy.Status.Phase = cdiv1.Succeeded
+						event.eventType = corev1.EventTypeNormal
+						event.reason = SnapshotRestoreSucceeded
+						event.message = fmt.Sprintf(MessageSnapshotRestoreSucceeded, snapshot, pvc.Namespace, pvc.Name)
+					}
 				}
 
 			case corev1.ClaimLost:
@@ -909,7 +1031,10 @@ func (r *DatavolumeReconciler) reconcileDataVolumeStatus(dataVolume *cdiv1.DataV
 		if i, err := strconv.Atoi(pvc.Annotations[AnnPodRestarts]); err == nil && i >= 0 {
 			dataVolumeCopy.Status.RestartCount = int32(i)
 		}
-		result, err = r.reconcileProgressUpdate(dataVolumeCopy, pvc.GetUID())
+		if i, err := strconv.ParseInt(pvc.Annotations[AnnAllocatedBytes], 10, 64); err == nil && i >= 0 {
+			dataVolumeCopy.Status.AllocatedBytes = &i
+		}
+		result, err = r.reconcileProgressUpdate(dataVolumeCopy, pvc)
 		if err != nil {
 			return result, err
 		}
@@ -981,6 +1106,8 @@ func (r *DatavolumeReconciler) emitFailureConditionEvent(dataVolume *cdiv1.DataV
 func (r *DatavolumeReconciler) emitEvent(dataVolume *cdiv1.DataVolume, dataVolumeCopy *cdiv1.DataVolume, curPhase cdiv1.DataVolumePhase, originalCond []cdiv1.DataVolumeCondition, event *DataVolumeEvent) error {
 	// Only update the object if something actually changed in the status.
 	if !reflect.DeepEqual(dataVolume, dataVolumeCopy) {
+		clearSourceRefPVC(dataVolumeCopy)
+		clearStoragePVC(dataVolumeCopy)
 		if err := r.client.Update(context.TODO(), dataVolumeCopy); err != nil {
 			r.log.Error(err, "Unable to update datavolume", "name", dataVolumeCopy.Name)
 			return err
@@ -994,8 +1121,9 @@ func (r *DatavolumeReconciler) emitEvent(dataVolume *cdiv1.DataVolume, dataVolum
 	return nil
 }
 
-// getPodFromPvc determines the pod associated with the pvc UID passed in.
-func (r *DatavolumeReconciler) getPodFromPvc(namespace string, pvcUID types.UID) (*corev1.Pod, error) {
+// getPodFromPvc determines the pod associated with the pvc passed in.
+func (r *DatavolumeReconciler) getPodFromPvc(namespace string, pvc *corev1.PersistentVolumeClaim) (*corev1.Pod, error) {
+	pvcUID := pvc.GetUID()
 	l, _ := labels.Parse(common.PrometheusLabel)
 	pods := &corev1.PodList{}
 	listOptions := client.ListOptions{
@@ -1014,7 +1142,8 @@ func (r *DatavolumeReconciler) getPodFromPvc(namespace string, pvcUID types.UID)
 
 		// TODO: check this
 		val, exists := pod.Labels[CloneUniqueID]
-		if exists && val == string(pvcUID)+common.ClonerSourcePodNameSuffix {
+		// a fan-out source pod streaming to several PVCs is named after one of them
+		if exists && (val == string(pvcUID)+common.ClonerSourcePodNameSuffix || val == pvc.Annotations[AnnCloneSourcePod]) {
 			return &pod, nil
 		}
 	}
@@ -1104,8 +1233,9 @@ func buildHTTPClient() *http.Client {
 // newPersistentVolumeClaim creates a new PVC the DataVolume resource.
 // It also sets the appropriate OwnerReferences on the resource
 // which allows handleObject to discover the DataVolume resource
-// that 'owns' it.
-func newPersistentVolumeClaim(dataVolume *cdiv1.DataVolume) (*corev1.PersistentVolumeClaim, error) {
+// that 'owns' it. Access modes and volume mode missing in the DataVolume
+// are filled in from the StorageProfile of the storage class.
+func newPersistentVolumeClaim(c client.Client, dataVolume *cdiv1.DataVolume) (*corev1.PersistentVolumeClaim, error) {
 	labels := map[string]string{
 		"app": "containerized-data-importer",
 	}
@@ -1116,6 +1246,11 @@ func newPersistentVolumeClaim(dataVolume *cdiv1.DataVolume) (*corev1.PersistentV
 		return nil, errors.Errorf("datavolume.pvc field is required")
 	}
 
+	pvcSpec, err := renderPvcSpec(c, dataVolume.Spec.PVC)
+	if err != nil {
+		return nil, err
+	}
+
 	annotations := make(map[string]string)
 
 	for k, v := range dataVolume.ObjectMeta.Annotations {
@@ -1137,12 +1272,24 @@ func newPersistentVolumeClaim(dataVolume *cdiv1.DataVolume) (*corev1.PersistentV
 		if dataVolume.Spec.Source.HTTP.CertConfigMap != "" {
 			annotations[AnnCertConfigMap] = dataVolume.Spec.Source.HTTP.CertConfigMap
 		}
+		if dataVolume.Spec.Source.HTTP.Checksum != "" {
+			annotations[AnnChecksum] = dataVolume.Spec.Source.HTTP.Checksum
+		}
+		if dataVolume.Spec.Source.HTTP.OVA != nil {
+			annotations[AnnOVADisk] = ovaDisk(dataVolume.Spec.Source.HTTP.OVA)
+		}
 	} else if dataVolume.Spec.Source.S3 != nil {
 		annotations[AnnEndpoint] = dataVolume.Spec.Source.S3.URL
 		annotations[AnnSource] = SourceS3
 		if dataVolume.Spec.Source.S3.SecretRef != "" {
 			annotations[AnnSecret] = dataVolume.Spec.Source.S3.SecretRef
 		}
+		if dataVolume.Spec.Source.S3.Checksum != "" {
+			annotations[AnnChecksum] = dataVolume.Spec.Source.S3.Checksum
+		}
+		if dataVolume.Spec.Source.S3.OVA != nil {
+			annotations[AnnOVADisk] = ovaDisk(dataVolume.Spec.Source.S3.OVA)
+		}
 	} else if dataVolume.Spec.Source.Registry != nil {
 		annotations[AnnSource] = SourceRegistry
 		annotations[AnnEndpoint] = dataVolume.Spec.Source.Registry.URL
@@ -1153,6 +1300,9 @@ func newPersistentVolumeClaim(dataVolume *cdiv1.DataVolume) (*corev1.PersistentV
 		if dataVolume.Spec.Source.Registry.CertConfigMap != "" {
 			annotations[AnnCertConfigMap] = dataVolume.Spec.Source.Registry.CertConfigMap
 		}
+		if dataVolume.Spec.Source.Registry.Checksum != "" {
+			annotations[AnnChecksum] = dataVolume.Spec.Source.Registry.Checksum
+		}
 	} else if dataVolume.Spec.Source.PVC != nil {
 		sourceNamespace := dataVolume.Spec.Source.PVC.Namespace
 		if sourceNamespace == "" {
@@ -1164,8 +1314,14 @@ func newPersistentVolumeClaim(dataVolume *cdiv1.DataVolume) (*corev1.PersistentV
 		}
 		annotations[AnnCloneToken] = token
 		annotations[AnnCloneRequest] = sourceNamespace + "/" + dataVolume.Spec.Source.PVC.Name
+		setCloneCodecAnnotations(c, dataVolume, annotations)
+	} else if dataVolume.Spec.Source.Snapshot != nil {
+		// the PVC is either restored from the snapshot, or cloned from a PVC restored from it, see createPvcFromSnapshot
 	} else if dataVolume.Spec.Source.Upload != nil {
 		annotations[AnnUploadRequest] = ""
+		if dataVolume.Spec.Source.Upload.Checksum != "" {
+			annotations[AnnChecksum] = dataVolume.Spec.Source.Upload.Checksum
+		}
 	} else if dataVolume.Spec.Source.Blank != nil {
 		annotations[AnnSource] = SourceNone
 		annotations[AnnContentType] = string(cdiv1.DataVolumeKubeVirt)
@@ -1200,6 +1356,36 @@ func newPersistentVolumeClaim(dataVolume *cdiv1.DataVolume) (*corev1.PersistentV
 				}),
 			},
 		},
-		Spec: *dataVolume.Spec.PVC,
+		Spec: *pvcSpec,
 	}, nil
 }
+
+// renderPvcSpec returns a copy of the PVC spec with missing access modes and volume mode
+// taken from the StorageProfile of the storage class
+func renderPvcSpec(c client.Client, spec *corev1.PersistentVolumeClaimSpec) (*corev1.PersistentVolumeClaimSpec, error) {
+	pvcSpec := spec.DeepCopy()
+	if len(pvcSpec.AccessModes) > 0 && pvcSpec.VolumeMode != nil {
+		return pvcSpec, nil
+	}
+	claimPropertySet, err := getClaimPropertySet(c, pvcSpec.StorageClassName)
+	if err != nil {
+		return nil, err
+	}
+	if claimPropertySet != nil {
+		if len(pvcSpec.AccessModes) == 0 {
+			pvcSpec.AccessModes = claimPropertySet.AccessModes
+		}
+		if pvcSpec.VolumeMode == nil {
+			pvcSpec.VolumeMode = claimPropertySet.VolumeMode
+		}
+	}
+	return pvcSpec, nil
+}
+
+// ovaDisk returns the disk selector of the OVA source, defaulting to the first disk
+func ovaDisk(ova *cdiv1.DataVolumeSourceOVA) string {
+	if ova.Disk == "" {
+		return "0"
+	}
+	return ova.Disk
+}
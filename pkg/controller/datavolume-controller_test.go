@@ -186,6 +186,175 @@ var _ = Describe("Datavolume controller reconcile loop", func() {
 		Expect(dv.Status.RestartCount).To(Equal(int32(2)))
 	})
 
+	DescribeTable("Should request preallocation on the PVC", func(dvPreallocation string, configPreallocation bool, expected string) {
+		dv := newImportDataVolume("test-dv")
+		if dvPreallocation != "" {
+			preallocation := dvPreallocation == "true"
+			dv.Spec.Preallocation = &preallocation
+		}
+		reconciler = createDatavolumeReconciler(dv)
+		cdiConfig := &cdiv1.CDIConfig{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		cdiConfig.Status.Preallocation = configPreallocation
+		err = reconciler.client.Update(context.TODO(), cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Annotations[AnnPreallocationRequested]).To(Equal(expected))
+	},
+		Entry("with the CDIConfig default", "", true, "true"),
+		Entry("with no preallocation anywhere", "", false, "false"),
+		Entry("with the DataVolume overriding the CDIConfig", "false", true, "false"),
+		Entry("with the DataVolume requesting it", "true", false, "true"),
+	)
+
+	It("Should report the bytes allocated on the PVC", func() {
+		reconciler = createDatavolumeReconciler(newImportDataVolume("test-dv"))
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(err).ToNot(HaveOccurred())
+
+		dv := &cdiv1.DataVolume{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dv.Status.AllocatedBytes).To(BeNil())
+
+		pvc.Annotations[AnnAllocatedBytes] = "1048576"
+		err = reconciler.client.Update(context.TODO(), pvc)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+
+		dv = &cdiv1.DataVolume{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dv.Status.AllocatedBytes).ToNot(BeNil())
+		Expect(*dv.Status.AllocatedBytes).To(Equal(int64(1048576)))
+	})
+
+	DescribeTable("Should select the disk of an OVA source on the PVC", func(dv *cdiv1.DataVolume, expected string) {
+		reconciler = createDatavolumeReconciler(dv)
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Annotations[AnnOVADisk]).To(Equal(expected))
+	},
+		Entry("defaulting to the first disk", func() *cdiv1.DataVolume {
+			dv := newImportDataVolume("test-dv")
+			dv.Spec.Source.HTTP.OVA = &cdiv1.DataVolumeSourceOVA{}
+			return dv
+		}(), "0"),
+		Entry("by file reference", func() *cdiv1.DataVolume {
+			dv := newS3ImportDataVolume("test-dv")
+			dv.Spec.Source.S3.OVA = &cdiv1.DataVolumeSourceOVA{Disk: "disk2.vmdk"}
+			return dv
+		}(), "disk2.vmdk"),
+	)
+
+	DescribeTable("Should set the download concurrency on the PVC", func(dv *cdiv1.DataVolume, max int32, expected string) {
+		reconciler = createDatavolumeReconciler(dv)
+		cdiConfig := &cdiv1.CDIConfig{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		cdiConfig.Status.MaxDownloadConcurrency = max
+		err = reconciler.client.Update(context.TODO(), cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Annotations[AnnConcurrency]).To(Equal(expected))
+	},
+		Entry("not by default", newImportDataVolume("test-dv"), int32(4), ""),
+		Entry("as requested by an http source", func() *cdiv1.DataVolume {
+			dv := newImportDataVolume("test-dv")
+			concurrency := int32(3)
+			dv.Spec.Source.HTTP.Concurrency = &concurrency
+			return dv
+		}(), int32(4), "3"),
+		Entry("capped by the CDIConfig", func() *cdiv1.DataVolume {
+			dv := newS3ImportDataVolume("test-dv")
+			concurrency := int32(16)
+			dv.Spec.Source.S3.Concurrency = &concurrency
+			return dv
+		}(), int32(8), "8"),
+		Entry("capped by the default without a CDIConfig maximum", func() *cdiv1.DataVolume {
+			dv := newImportDataVolume("test-dv")
+			concurrency := int32(16)
+			dv.Spec.Source.HTTP.Concurrency = &concurrency
+			return dv
+		}(), int32(0), "4"),
+	)
+
+	DescribeTable("Should set the clone codec on the PVC", func(codec, configCodec *cdiv1.CloneCodec, expectedCodec, expectedLevel string) {
+		dv := newCloneDataVolume("test-dv")
+		dv.Spec.CloneCodec = codec
+		reconciler = createDatavolumeReconciler(dv, createPvc("test", metav1.NamespaceDefault, nil, nil))
+		cdiConfig := &cdiv1.CDIConfig{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		cdiConfig.Status.CloneCodec = configCodec
+		err = reconciler.client.Update(context.TODO(), cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Annotations[AnnCloneCodec]).To(Equal(expectedCodec))
+		Expect(pvc.Annotations[AnnCloneCodecLevel]).To(Equal(expectedLevel))
+	},
+		Entry("to snappy by default", nil, nil, "snappy", ""),
+		Entry("from the CDIConfig", nil, &cdiv1.CloneCodec{Name: cdiv1.CloneCodecNone}, "none", ""),
+		Entry("from the DataVolume over the CDIConfig", &cdiv1.CloneCodec{Name: cdiv1.CloneCodecZstd, Level: &[]int32{9}[0]},
+			&cdiv1.CloneCodec{Name: cdiv1.CloneCodecNone}, "zstd", "9"),
+	)
+
+	It("Should fail with a checksum mismatch reason, if the imported data doesn't match the checksum", func() {
+		dv := newImportDataVolume("test-dv")
+		dv.Spec.Source.HTTP.Checksum = "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+		reconciler = createDatavolumeReconciler(dv)
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Annotations[AnnChecksum]).To(Equal(dv.Spec.Source.HTTP.Checksum))
+
+		pvc.Status.Phase = corev1.ClaimBound
+		pvc.Annotations[AnnImportPod] = "importer-test-dv"
+		pvc.Annotations[AnnPodPhase] = string(corev1.PodFailed)
+		pvc.Annotations[AnnRunningCondition] = "false"
+		pvc.Annotations[AnnRunningConditionMessage] = "checksum mismatch"
+		pvc.Annotations[AnnRunningConditionReason] = ChecksumMismatch
+		err = reconciler.client.Update(context.TODO(), pvc)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		dv = &cdiv1.DataVolume{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dv.Status.Phase).To(Equal(cdiv1.Failed))
+		runningCondition := findConditionByType(cdiv1.DataVolumeRunning, dv.Status.Conditions)
+		Expect(runningCondition).ToNot(BeNil())
+		Expect(runningCondition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(runningCondition.Reason).To(Equal(ChecksumMismatch))
+	})
+
 	It("Should error if a PVC with same name already exists that is not owned by us", func() {
 		reconciler = createDatavolumeReconciler(createPvc("test-dv", metav1.NamespaceDefault, map[string]string{}, nil), newImportDataVolume("test-dv"))
 		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
@@ -238,6 +407,60 @@ var _ = Describe("Datavolume controller reconcile loop", func() {
 		Expect(dv.Status.Phase).To(Equal(cdiv1.SnapshotForSmartCloneInProgress))
 	})
 
+	It("Should clone the PVC of a DataSource sourceRef and record it", func() {
+		dv := newDataSourceDataVolume("test-dv", "test-datasource")
+		dataSource := newDataSource("test-datasource", "test")
+		pvc := createPvc("test", metav1.NamespaceDefault, nil, nil)
+		reconciler = createDatavolumeReconciler(dv, dataSource, pvc)
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		targetPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, targetPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targetPvc.Annotations[AnnCloneRequest]).To(Equal("default/test"))
+		dv = &cdiv1.DataVolume{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dv.Annotations[AnnSourceRefPVC]).To(Equal("default/test"))
+		Expect(dv.Spec.Source.PVC).To(BeNil())
+	})
+
+	It("Should keep cloning the recorded PVC of a sourceRef after the DataSource changes", func() {
+		dv := newDataSourceDataVolume("test-dv", "test-datasource")
+		dv.Annotations[AnnSourceRefPVC] = "default/test"
+		dataSource := newDataSource("test-datasource", "test-new")
+		pvc := createPvc("test", metav1.NamespaceDefault, nil, nil)
+		reconciler = createDatavolumeReconciler(dv, dataSource, pvc)
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		targetPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, targetPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targetPvc.Annotations[AnnCloneRequest]).To(Equal("default/test"))
+	})
+
+	It("Should clone the PVC of a sourceRef recorded by the webhook without resolving the DataSource", func() {
+		dv := newDataSourceDataVolume("test-dv", "test-datasource")
+		dv.Annotations[AnnSourceRefPVC] = "default/test"
+		pvc := createPvc("test", metav1.NamespaceDefault, nil, nil)
+		reconciler = createDatavolumeReconciler(dv, pvc)
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		targetPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, targetPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targetPvc.Annotations[AnnCloneRequest]).To(Equal("default/test"))
+	})
+
+	It("Should fail to reconcile a sourceRef if the DataSource doesn't exist", func() {
+		reconciler = createDatavolumeReconciler(newDataSourceDataVolume("test-dv", "test-datasource"))
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).To(HaveOccurred())
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
 	DescribeTable("Should NOT create a snapshot if source PVC mounted", func(podFunc func(*cdiv1.DataVolume) *corev1.Pod) {
 		dv := newCloneDataVolume("test-dv")
 		scName := "testsc"
@@ -925,6 +1148,25 @@ var _ = Describe("Smart clone", func() {
 		Expect(snapclass).To(BeEmpty())
 	})
 
+	It("Should not return storage class, if source and target volume modes do not match", func() {
+		dv := newCloneDataVolume("test-dv")
+		scName := "testsc"
+		sc := createStorageClassWithProvisioner(scName, map[string]string{
+			AnnDefaultStorageClass: "true",
+		}, "csi-plugin")
+		dv.Spec.PVC.StorageClassName = &scName
+		volumeMode := corev1.PersistentVolumeBlock
+		dv.Spec.PVC.VolumeMode = &volumeMode
+		pvc := createPvcInStorageClass("test", metav1.NamespaceDefault, &scName, nil, nil, corev1.ClaimBound)
+		snapClass := createSnapshotClass("snap-class", nil, "csi-plugin")
+		reconciler := createDatavolumeReconciler(sc, dv, pvc, snapClass)
+		reconciler.extClientSet = extfake.NewSimpleClientset(createVolumeSnapshotContentCrd(), createVolumeSnapshotClassCrd(), createVolumeSnapshotCrd())
+		snapclass, err := reconciler.getSnapshotClassForSmartClone(dv)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("source PVC and target PVC have different volume modes"))
+		Expect(snapclass).To(BeEmpty())
+	})
+
 	It("Should not return storage class, if storage class does not exist", func() {
 		dv := newCloneDataVolume("test-dv")
 		scName := "testsc"
@@ -987,7 +1229,7 @@ var _ = Describe("Get Pod from PVC", func() {
 	})
 
 	It("Should return error if no pods can be found", func() {
-		_, err := reconciler.getPodFromPvc(metav1.NamespaceDefault, pvc.GetUID())
+		_, err := reconciler.getPodFromPvc(metav1.NamespaceDefault, pvc)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("Unable to find pod owned by UID: %s, in namespace: %s", string(pvc.GetUID()), metav1.NamespaceDefault)))
 	})
@@ -998,7 +1240,7 @@ var _ = Describe("Get Pod from PVC", func() {
 		pod.GetLabels()[common.PrometheusLabel] = ""
 		err := reconciler.client.Create(context.TODO(), pod)
 		Expect(err).ToNot(HaveOccurred())
-		foundPod, err := reconciler.getPodFromPvc(metav1.NamespaceDefault, pvc.GetUID())
+		foundPod, err := reconciler.getPodFromPvc(metav1.NamespaceDefault, pvc)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(foundPod.Name).To(Equal(pod.Name))
 	})
@@ -1011,7 +1253,21 @@ var _ = Describe("Get Pod from PVC", func() {
 		pod.OwnerReferences = nil
 		err := reconciler.client.Create(context.TODO(), pod)
 		Expect(err).ToNot(HaveOccurred())
-		foundPod, err := reconciler.getPodFromPvc(metav1.NamespaceDefault, pvc.GetUID())
+		foundPod, err := reconciler.getPodFromPvc(metav1.NamespaceDefault, pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(foundPod.Name).To(Equal(pod.Name))
+	})
+
+	It("Should return the fan-out source pod the pvc is assigned to", func() {
+		pod := createImporterTestPod(pvc, "test-dv", nil)
+		pod.SetLabels(make(map[string]string))
+		pod.GetLabels()[common.PrometheusLabel] = ""
+		pod.GetLabels()[CloneUniqueID] = "other-uid-fanout-source-pod"
+		pod.OwnerReferences = nil
+		err := reconciler.client.Create(context.TODO(), pod)
+		Expect(err).ToNot(HaveOccurred())
+		pvc.Annotations[AnnCloneSourcePod] = "other-uid-fanout-source-pod"
+		foundPod, err := reconciler.getPodFromPvc(metav1.NamespaceDefault, pvc)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(foundPod.Name).To(Equal(pod.Name))
 	})
@@ -1024,7 +1280,7 @@ var _ = Describe("Get Pod from PVC", func() {
 		pod.OwnerReferences = nil
 		err := reconciler.client.Create(context.TODO(), pod)
 		Expect(err).ToNot(HaveOccurred())
-		_, err = reconciler.getPodFromPvc(metav1.NamespaceDefault, pvc.GetUID())
+		_, err = reconciler.getPodFromPvc(metav1.NamespaceDefault, pvc)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("Unable to find pod owned by UID: %s, in namespace: %s", string(pvc.GetUID()), metav1.NamespaceDefault)))
 	})
@@ -1194,6 +1450,26 @@ func newCloneDataVolumeWithPVCNS(name string, pvcNamespace string) *cdiv1.DataVo
 	}
 }
 
+func newDataSourceDataVolume(name, dataSourceName string) *cdiv1.DataVolume {
+	return &cdiv1.DataVolume{
+		TypeMeta: metav1.TypeMeta{APIVersion: cdiv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			Annotations: map[string]string{
+				AnnCloneToken: "foobar",
+			},
+		},
+		Spec: cdiv1.DataVolumeSpec{
+			SourceRef: &cdiv1.DataVolumeSourceRef{
+				Kind: cdiv1.DataVolumeDataSource,
+				Name: dataSourceName,
+			},
+			PVC: &corev1.PersistentVolumeClaimSpec{},
+		},
+	}
+}
+
 func newUploadDataVolume(name string) *cdiv1.DataVolume {
 	return &cdiv1.DataVolume{
 		TypeMeta: metav1.TypeMeta{APIVersion: cdiv1.SchemeGroupVersion.String()},
@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/v2/pkg/apis/volumesnapshot/v1beta1"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util/naming"
+)
+
+const (
+	snapshotRestorePrefix = "cdi-restore"
+
+	// SnapshotSourceNotFound provides a const to indicate the snapshot source of the DataVolume doesn't exist
+	SnapshotSourceNotFound = "SnapshotSourceNotFound"
+	// SnapshotSourceTooLarge provides a const to indicate the snapshot source doesn't fit in the DataVolume
+	SnapshotSourceTooLarge = "SnapshotSourceTooLarge"
+	// SnapshotSourceNoStorageClass provides a const to indicate no storage class can restore the snapshot source
+	SnapshotSourceNoStorageClass = "SnapshotSourceNoStorageClass"
+	// SnapshotRestoreSucceeded provides a const to indicate the restore from the snapshot source has succeeded
+	SnapshotRestoreSucceeded = "SnapshotRestoreSucceeded"
+	// MessageSnapshotSourceNotFound provides a const to form the snapshot source doesn't exist message
+	MessageSnapshotSourceNotFound = "Source snapshot %s/%s doesn't exist"
+	// MessageSnapshotSourceTooLarge provides a const to form the snapshot source doesn't fit message
+	MessageSnapshotSourceTooLarge = "Source snapshot %s/%s restore size %s is larger than the requested storage %s"
+	// MessageSnapshotSourceNoStorageClass provides a const to form the no storage class for the snapshot source message
+	MessageSnapshotSourceNoStorageClass = "No storage class found to restore source snapshot %s/%s"
+	// MessageSnapshotRestoreSucceeded provides a const to form the restore from the snapshot source has succeeded message
+	MessageSnapshotRestoreSucceeded = "Successfully restored snapshot %s into %s/%s"
+)
+
+// createPvcFromSnapshot creates the PVC of a DataVolume with a snapshot source. The PVC is restored directly from the
+// snapshot when it's in the same namespace and storage class, and has the same size. Otherwise a temporary PVC is
+// restored from the snapshot in the snapshot namespace and the PVC is cloned from it. Returns nil if the PVC can't be
+// created yet.
+func (r *DatavolumeReconciler) createPvcFromSnapshot(dv *cdiv1.DataVolume) (*corev1.PersistentVolumeClaim, error) {
+	snapshot, err := r.getSnapshotSource(dv)
+	if snapshot == nil || err != nil {
+		return nil, err
+	}
+	if snapshot.Status == nil || snapshot.Status.ReadyToUse == nil || !*snapshot.Status.ReadyToUse {
+		r.log.V(3).Info("Source snapshot not ready to use", "namespace", snapshot.Namespace, "name", snapshot.Name)
+		return nil, nil
+	}
+
+	newPvc, err := newPersistentVolumeClaim(r.client, dv)
+	if err != nil {
+		return nil, err
+	}
+	targetSize := newPvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	restoreSize := snapshot.Status.RestoreSize
+	if restoreSize != nil && restoreSize.Cmp(targetSize) > 0 {
+		r.recorder.Eventf(dv, corev1.EventTypeWarning, SnapshotSourceTooLarge, MessageSnapshotSourceTooLarge,
+			snapshot.Namespace, snapshot.Name, restoreSize.String(), targetSize.String())
+		return nil, nil
+	}
+
+	snapshotStorageClass, err := r.getSnapshotStorageClass(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if snapshotStorageClass == nil {
+		r.recorder.Eventf(dv, corev1.EventTypeWarning, SnapshotSourceNoStorageClass, MessageSnapshotSourceNoStorageClass,
+			snapshot.Namespace, snapshot.Name)
+		return nil, nil
+	}
+	targetStorageClass, err := GetStorageClassByName(r.client, newPvc.Spec.StorageClassName)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot.Namespace == dv.Namespace &&
+		targetStorageClass != nil && targetStorageClass.Name == snapshotStorageClass.Name &&
+		(restoreSize == nil || restoreSize.Cmp(targetSize) == 0) {
+		r.log.V(3).Info("Restoring PVC from snapshot", "namespace", snapshot.Namespace, "name", snapshot.Name)
+		newPvc.Spec.DataSource = newSnapshotDataSource(snapshot)
+		newPvc.Annotations[AnnSnapshotRestore] = snapshot.Namespace + "/" + snapshot.Name
+		newPvc.Annotations[AnnRunningCondition] = string(corev1.ConditionFalse)
+		newPvc.Annotations[AnnRunningConditionReason] = "Completed"
+	} else {
+		if _, ok := newPvc.Annotations[AnnCloneToken]; !ok {
+			return nil, errors.Errorf("no clone token")
+		}
+		restorePvc := newSnapshotRestorePVC(dv, snapshot, snapshotStorageClass, newPvc)
+		r.log.V(3).Info("Restoring temporary PVC from snapshot", "namespace", restorePvc.Namespace, "name", restorePvc.Name)
+		if err := r.client.Create(context.TODO(), restorePvc); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		newPvc.Annotations[AnnCloneRequest] = restorePvc.Namespace + "/" + restorePvc.Name
+		setCloneCodecAnnotations(r.client, dv, newPvc.Annotations)
+	}
+
+	if err := r.client.Create(context.TODO(), newPvc); err != nil {
+		return nil, err
+	}
+	return newPvc, nil
+}
+
+func (r *DatavolumeReconciler) getSnapshotSource(dv *cdiv1.DataVolume) (*snapshotv1.VolumeSnapshot, error) {
+	namespace := dv.Spec.Source.Snapshot.Namespace
+	if namespace == "" {
+		namespace = dv.Namespace
+	}
+	snapshot := &snapshotv1.VolumeSnapshot{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: dv.Spec.Source.Snapshot.Name}, snapshot); err != nil {
+		if k8serrors.IsNotFound(err) {
+			r.recorder.Eventf(dv, corev1.EventTypeWarning, SnapshotSourceNotFound, MessageSnapshotSourceNotFound, namespace, dv.Spec.Source.Snapshot.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// getSnapshotStorageClass returns the storage class of the PVC the snapshot was taken from, or if it's gone, a storage
+// class of the CSI driver which took the snapshot
+func (r *DatavolumeReconciler) getSnapshotStorageClass(snapshot *snapshotv1.VolumeSnapshot) (*storagev1.StorageClass, error) {
+	if pvcName := snapshot.Spec.Source.PersistentVolumeClaimName; pvcName != nil {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: snapshot.Namespace, Name: *pvcName}, pvc)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil && pvc.Spec.StorageClassName != nil {
+			storageClass := &storagev1.StorageClass{}
+			err := r.client.Get(context.TODO(), types.NamespacedName{Name: *pvc.Spec.StorageClassName}, storageClass)
+			if err == nil {
+				return storageClass, nil
+			}
+			if !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+		}
+	}
+
+	if snapshot.Status == nil || snapshot.Status.BoundVolumeSnapshotContentName == nil {
+		return nil, nil
+	}
+	content := &snapshotv1.VolumeSnapshotContent{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: *snapshot.Status.BoundVolumeSnapshotContentName}, content); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	storageClasses := &storagev1.StorageClassList{}
+	if err := r.client.List(context.TODO(), storageClasses); err != nil {
+		return nil, err
+	}
+	for i := range storageClasses.Items {
+		if storageClasses.Items[i].Provisioner == content.Spec.Driver {
+			return &storageClasses.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// cleanupSnapshotRestorePVCs deletes the temporary PVCs restored from snapshots for the DataVolume
+func (r *DatavolumeReconciler) cleanupSnapshotRestorePVCs(namespace, name string) error {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.client.List(context.TODO(), pvcs, client.MatchingLabels{common.CDIComponentLabel: common.SnapshotRestoreCDILabel}); err != nil {
+		return err
+	}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if pvc.Annotations[AnnSnapshotRestoreFor] != namespace+"/"+name {
+			continue
+		}
+		r.log.V(3).Info("Deleting temporary PVC restored from snapshot", "namespace", pvc.Namespace, "name", pvc.Name)
+		if err := r.client.Delete(context.TODO(), pvc); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// newSnapshotRestorePVC returns the temporary PVC restored from the snapshot in the snapshot namespace, which is cloned
+// to the target PVC. It can't be owned by the DataVolume as it may be in another namespace.
+func newSnapshotRestorePVC(dv *cdiv1.DataVolume, snapshot *snapshotv1.VolumeSnapshot, storageClass *storagev1.StorageClass, targetPvc *corev1.PersistentVolumeClaim) *corev1.PersistentVolumeClaim {
+	size := targetPvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if snapshot.Status.RestoreSize != nil {
+		size = *snapshot.Status.RestoreSize
+	}
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      naming.GetResourceName(snapshotRestorePrefix, string(dv.UID)),
+			Namespace: snapshot.Namespace,
+			Labels: map[string]string{
+				common.CDILabelKey:       common.CDILabelValue,
+				common.CDIComponentLabel: common.SnapshotRestoreCDILabel,
+			},
+			Annotations: map[string]string{
+				AnnSnapshotRestoreFor: dv.Namespace + "/" + dv.Name,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			DataSource:       newSnapshotDataSource(snapshot),
+			AccessModes:      targetPvc.Spec.AccessModes,
+			VolumeMode:       targetPvc.Spec.VolumeMode,
+			StorageClassName: &storageClass.Name,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+}
+
+func newSnapshotDataSource(snapshot *snapshotv1.VolumeSnapshot) *corev1.TypedLocalObjectReference {
+	return &corev1.TypedLocalObjectReference{
+		Name:     snapshot.Name,
+		Kind:     "VolumeSnapshot",
+		APIGroup: &snapshotv1.SchemeGroupVersion.Group,
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/v2/pkg/apis/volumesnapshot/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util/naming"
+)
+
+var _ = Describe("Datavolume controller snapshot source", func() {
+	var (
+		reconciler *DatavolumeReconciler
+	)
+	AfterEach(func() {
+		if reconciler != nil {
+			close(reconciler.recorder.(*record.FakeRecorder).Events)
+			reconciler = nil
+		}
+	})
+
+	reconcileDataVolume := func() reconcile.Result {
+		result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	getTargetPvc := func() (*corev1.PersistentVolumeClaim, error) {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		return pvc, err
+	}
+
+	getRestorePvc := func(dv *cdiv1.DataVolume, namespace string) (*corev1.PersistentVolumeClaim, error) {
+		pvc := &corev1.PersistentVolumeClaim{}
+		name := naming.GetResourceName(snapshotRestorePrefix, string(dv.UID))
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, pvc)
+		return pvc, err
+	}
+
+	It("Should restore the PVC directly from a snapshot of the same storage class and size", func() {
+		dv := newSnapshotDataVolume("test-dv", metav1.NamespaceDefault, "sc")
+		snapshot := newReadySnapshot(metav1.NamespaceDefault, "1G")
+		reconciler = createDatavolumeReconciler(dv, snapshot, createStorageClass("sc", nil),
+			createPvcInStorageClass("source", metav1.NamespaceDefault, &scName, nil, nil, corev1.ClaimBound))
+		reconcileDataVolume()
+
+		pvc, err := getTargetPvc()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Spec.DataSource).ToNot(BeNil())
+		Expect(pvc.Spec.DataSource.Kind).To(Equal("VolumeSnapshot"))
+		Expect(pvc.Spec.DataSource.Name).To(Equal(snapshot.Name))
+		Expect(pvc.Annotations[AnnSnapshotRestore]).To(Equal(metav1.NamespaceDefault + "/" + snapshot.Name))
+		Expect(pvc.Annotations).ToNot(HaveKey(AnnCloneRequest))
+		_, err = getRestorePvc(dv, metav1.NamespaceDefault)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+		pvc.Status.Phase = corev1.ClaimBound
+		Expect(reconciler.client.Update(context.TODO(), pvc)).To(Succeed())
+		reconcileDataVolume()
+		dv = &cdiv1.DataVolume{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dv.Status.Phase).To(Equal(cdiv1.Succeeded))
+	})
+
+	It("Should restore a temporary PVC and clone it when the storage class differs", func() {
+		dv := newSnapshotDataVolume("test-dv", "source-ns", "other-sc")
+		snapshot := newReadySnapshot("source-ns", "1G")
+		reconciler = createDatavolumeReconciler(dv, snapshot, createStorageClass("sc", nil), createStorageClass("other-sc", nil),
+			createPvcInStorageClass("source", "source-ns", &scName, nil, nil, corev1.ClaimBound))
+		reconcileDataVolume()
+
+		restorePvc, err := getRestorePvc(dv, "source-ns")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restorePvc.Labels[common.CDIComponentLabel]).To(Equal(common.SnapshotRestoreCDILabel))
+		Expect(restorePvc.Annotations[AnnSnapshotRestoreFor]).To(Equal(metav1.NamespaceDefault + "/test-dv"))
+		Expect(restorePvc.Spec.DataSource.Name).To(Equal(snapshot.Name))
+		Expect(*restorePvc.Spec.StorageClassName).To(Equal("sc"))
+
+		pvc, err := getTargetPvc()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Spec.DataSource).To(BeNil())
+		Expect(pvc.Annotations[AnnCloneRequest]).To(Equal("source-ns/" + restorePvc.Name))
+		Expect(pvc.Annotations[AnnCloneToken]).To(Equal("foobar"))
+
+		pvc.Annotations[AnnPodPhase] = string(cdiv1.Succeeded)
+		Expect(reconciler.client.Update(context.TODO(), pvc)).To(Succeed())
+		reconcileDataVolume()
+		_, err = getRestorePvc(dv, "source-ns")
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should delete the temporary PVC when the DataVolume is deleted", func() {
+		dv := newSnapshotDataVolume("test-dv", "source-ns", "other-sc")
+		reconciler = createDatavolumeReconciler(newSnapshotRestorePVC(dv, newReadySnapshot("source-ns", "1G"), createStorageClass("sc", nil), createPvc("test-dv", metav1.NamespaceDefault, nil, nil)))
+		reconcileDataVolume()
+		_, err := getRestorePvc(dv, "source-ns")
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should wait for the snapshot to be ready", func() {
+		dv := newSnapshotDataVolume("test-dv", metav1.NamespaceDefault, "sc")
+		snapshot := newReadySnapshot(metav1.NamespaceDefault, "1G")
+		ready := false
+		snapshot.Status.ReadyToUse = &ready
+		reconciler = createDatavolumeReconciler(dv, snapshot, createStorageClass("sc", nil))
+		result := reconcileDataVolume()
+		Expect(result.Requeue).To(BeTrue())
+		_, err := getTargetPvc()
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should report a snapshot larger than the requested storage", func() {
+		dv := newSnapshotDataVolume("test-dv", metav1.NamespaceDefault, "sc")
+		reconciler = createDatavolumeReconciler(dv, newReadySnapshot(metav1.NamespaceDefault, "2G"), createStorageClass("sc", nil))
+		reconcileDataVolume()
+		_, err := getTargetPvc()
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(SnapshotSourceTooLarge))
+	})
+
+	It("Should report a missing snapshot", func() {
+		dv := newSnapshotDataVolume("test-dv", metav1.NamespaceDefault, "sc")
+		reconciler = createDatavolumeReconciler(dv)
+		reconcileDataVolume()
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(SnapshotSourceNotFound))
+	})
+})
+
+var scName = "sc"
+
+func newSnapshotDataVolume(name, snapshotNamespace, storageClassName string) *cdiv1.DataVolume {
+	return &cdiv1.DataVolume{
+		TypeMeta: metav1.TypeMeta{APIVersion: cdiv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			UID:       types.UID(metav1.NamespaceDefault + "-" + name),
+			Annotations: map[string]string{
+				AnnCloneToken: "foobar",
+			},
+		},
+		Spec: cdiv1.DataVolumeSpec{
+			Source: cdiv1.DataVolumeSource{
+				Snapshot: &cdiv1.DataVolumeSourceSnapshot{
+					Namespace: snapshotNamespace,
+					Name:      "snapshot",
+				},
+			},
+			PVC: &corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: &storageClassName,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse("1G"),
+					},
+				},
+			},
+		},
+	}
+}
+
+func newReadySnapshot(namespace, restoreSize string) *snapshotv1.VolumeSnapshot {
+	ready := true
+	sourcePvc := "source"
+	size := resource.MustParse(restoreSize)
+	return &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "snapshot",
+			Namespace: namespace,
+		},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			Source: snapshotv1.VolumeSnapshotSource{
+				PersistentVolumeClaimName: &sourcePvc,
+			},
+		},
+		Status: &snapshotv1.VolumeSnapshotStatus{
+			ReadyToUse:  &ready,
+			RestoreSize: &size,
+		},
+	}
+}
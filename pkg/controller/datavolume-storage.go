@@ -0,0 +1,374 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+	"kubevirt.io/containerized-data-importer/pkg/util/naming"
+)
+
+const (
+	sizeProbePodPrefix = "size-probe"
+
+	// SizeProbeFailed provides a const to indicate probing the size of the source failed
+	SizeProbeFailed = "SizeProbeFailed"
+	// MessageSizeProbeFailed provides a const to form the size probe failure message
+	MessageSizeProbeFailed = "Unable to infer the size of the source, please specify the storage size: %s"
+	// MessageSizeProbeGaveUp provides a const to form the message of the last size probe failure
+	MessageSizeProbeGaveUp = "Unable to infer the size of the source after %d attempts, please specify the storage size: %s"
+
+	// AnnSizeProbeRetries is the number of size probe pods of a DataVolume that failed
+	AnnSizeProbeRetries = AnnAPIGroup + "/storage.sizeProbe.retries"
+
+	// sizeProbeInitialBackoff is how long to wait before probing the size again after the first failure, doubled on
+	// every failure up to sizeProbeMaxBackoff
+	sizeProbeInitialBackoff = 10 * time.Second
+	sizeProbeMaxBackoff     = 5 * time.Minute
+	// sizeProbeMaxAttempts is the number of size probe pods that may fail before giving up, the last failed pod is
+	// kept and deleting it probes the size once more
+	sizeProbeMaxAttempts = 5
+)
+
+// inferStorageSize records the size inferred from the source in the status of a DataVolume using the storage API
+// without a storage request. Returns true once the size is known, false while the source is still being probed, along
+// with how long to wait before probing it again after a failure.
+func (r *DatavolumeReconciler) inferStorageSize(dv *cdiv1.DataVolume) (bool, time.Duration, error) {
+	if _, ok := dv.Spec.Storage.Resources.Requests[corev1.ResourceStorage]; ok || dv.Status.InferredSize != nil {
+		return true, 0, nil
+	}
+
+	source := dv.Spec.Source
+	switch {
+	case source.PVC != nil:
+		size, err := r.getSourcePVCSize(dv)
+		if err != nil {
+			return false, 0, err
+		}
+		return false, 0, r.updateInferredSize(dv, size)
+	case source.Snapshot != nil:
+		snapshot, err := r.getSnapshotSource(dv)
+		if snapshot == nil || err != nil {
+			return false, 0, err
+		}
+		if snapshot.Status == nil || snapshot.Status.RestoreSize == nil {
+			r.log.V(3).Info("Source snapshot has no restore size yet", "namespace", snapshot.Namespace, "name", snapshot.Name)
+			return false, 0, nil
+		}
+		return false, 0, r.updateInferredSize(dv, *snapshot.Status.RestoreSize)
+	case source.HTTP != nil || source.S3 != nil:
+		requeueAfter, err := r.probeSourceSize(dv)
+		return false, requeueAfter, err
+	}
+	return false, 0, errors.Errorf("unable to infer the size of DataVolume %s/%s source, storage size is required", dv.Namespace, dv.Name)
+}
+
+// getSourcePVCSize returns the storage requested by the source PVC of a clone DataVolume
+func (r *DatavolumeReconciler) getSourcePVCSize(dv *cdiv1.DataVolume) (resource.Quantity, error) {
+	namespace := dv.Spec.Source.PVC.Namespace
+	if namespace == "" {
+		namespace = dv.Namespace
+	}
+	sourcePvc := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: dv.Spec.Source.PVC.Name}, sourcePvc); err != nil {
+		return resource.Quantity{}, err
+	}
+	size, ok := sourcePvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return resource.Quantity{}, errors.Errorf("source PVC %s/%s has no storage request", namespace, sourcePvc.Name)
+	}
+	if getVolumeMode(sourcePvc) == corev1.PersistentVolumeBlock {
+		// the whole device is cloned as a raw disk image, which needs room for the overhead of a filesystem target
+		return r.getRequiredSize(dv, size.Value())
+	}
+	return size, nil
+}
+
+// probeSourceSize runs a pod reading the virtual size of the source image, and once it reports it, records the size
+// needed to hold the image on the target storage. A failed pod is recreated with an exponential backoff, returned as
+// the time to wait before recreating it, up to sizeProbeMaxAttempts pods.
+func (r *DatavolumeReconciler) probeSourceSize(dv *cdiv1.DataVolume) (time.Duration, error) {
+	pod := &corev1.Pod{}
+	podName := naming.GetResourceName(sizeProbePodPrefix, dv.Name)
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dv.Namespace, Name: podName}, pod); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return 0, err
+		}
+		newPod, err := r.newSizeProbePod(dv, podName)
+		if err != nil {
+			return 0, err
+		}
+		r.log.V(1).Info("Creating size probe pod", "DataVolume", dv.Name, "pod", podName)
+		return 0, r.client.Create(context.TODO(), newPod)
+	}
+	if len(pod.Status.ContainerStatuses) == 0 || pod.Status.ContainerStatuses[0].State.Terminated == nil {
+		return 0, nil
+	}
+	terminated := pod.Status.ContainerStatuses[0].State.Terminated
+	termMsg := util.ParseTerminationMessage(terminated.Message)
+	if terminated.ExitCode != 0 || termMsg.VirtualSize == nil {
+		return r.handleSizeProbeFailure(dv, pod, terminated.FinishedAt.Time, termMsg.Message)
+	}
+
+	size, err := r.getRequiredSize(dv, *termMsg.VirtualSize)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.updateInferredSize(dv, size); err != nil {
+		return 0, err
+	}
+	return 0, r.client.Delete(context.TODO(), pod)
+}
+
+// handleSizeProbeFailure reports the failure of the size probe pod in the Running condition of the DataVolume, and
+// deletes the pod once the backoff for the number of failures elapsed, so it is created again. After
+// sizeProbeMaxAttempts failures the pod is kept and the size is not probed again.
+func (r *DatavolumeReconciler) handleSizeProbeFailure(dv *cdiv1.DataVolume, pod *corev1.Pod, finishedAt time.Time, message string) (time.Duration, error) {
+	retries, _ := strconv.Atoi(dv.Annotations[AnnSizeProbeRetries])
+	gaveUp := retries+1 >= sizeProbeMaxAttempts
+	if gaveUp {
+		message = fmt.Sprintf(MessageSizeProbeGaveUp, retries+1, message)
+	} else {
+		message = fmt.Sprintf(MessageSizeProbeFailed, message)
+	}
+	dvCopy := dv.DeepCopy()
+	if condition := findConditionByType(cdiv1.DataVolumeRunning, dv.Status.Conditions); condition == nil ||
+		condition.Reason != SizeProbeFailed || condition.Message != message {
+		r.recorder.Event(dv, corev1.EventTypeWarning, SizeProbeFailed, message)
+		dvCopy.Status.Conditions = updateCondition(dvCopy.Status.Conditions, cdiv1.DataVolumeRunning, corev1.ConditionFalse, message, SizeProbeFailed)
+	}
+
+	var wait time.Duration
+	if !gaveUp {
+		wait = sizeProbeBackoff(retries) - time.Since(finishedAt)
+	}
+	if !gaveUp && wait <= 0 {
+		r.log.V(1).Info("Deleting failed size probe pod to probe again", "DataVolume", dv.Name, "pod", pod.Name, "retries", retries)
+		if err := r.client.Delete(context.TODO(), pod); err != nil && !k8serrors.IsNotFound(err) {
+			return 0, err
+		}
+		if dvCopy.Annotations == nil {
+			dvCopy.Annotations = make(map[string]string)
+		}
+		dvCopy.Annotations[AnnSizeProbeRetries] = strconv.Itoa(retries + 1)
+		wait = 0
+	}
+	if !reflect.DeepEqual(dv, dvCopy) {
+		if err := r.client.Update(context.TODO(), dvCopy); err != nil {
+			return 0, err
+		}
+	}
+	return wait, nil
+}
+
+// sizeProbeBackoff returns how long to wait after a size probe pod failed before creating it again
+func sizeProbeBackoff(retries int) time.Duration {
+	backoff := sizeProbeInitialBackoff
+	for i := 0; i < retries && backoff < sizeProbeMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > sizeProbeMaxBackoff {
+		return sizeProbeMaxBackoff
+	}
+	return backoff
+}
+
+// getRequiredSize returns the size of a PVC on the target storage able to hold an image of the passed virtual size,
+// accounting for the filesystem overhead
+func (r *DatavolumeReconciler) getRequiredSize(dv *cdiv1.DataVolume, virtualSize int64) (resource.Quantity, error) {
+	pvcSpec, err := renderPvcSpec(r.client, StorageSpecToPvcSpec(dv.Spec.Storage))
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	pvc := &corev1.PersistentVolumeClaim{Spec: *pvcSpec}
+	overhead, err := GetFilesystemOverhead(r.client, pvc)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	fsOverhead, err := strconv.ParseFloat(string(overhead), 64)
+	if err != nil {
+		return resource.Quantity{}, errors.Wrapf(err, "invalid filesystem overhead %s", overhead)
+	}
+	return inflateSizeWithOverhead(virtualSize, fsOverhead), nil
+}
+
+// inflateSizeWithOverhead returns the size of a volume with the passed overhead ratio able to hold virtualSize bytes,
+// rounded up to a whole MiB
+func inflateSizeWithOverhead(virtualSize int64, overhead float64) resource.Quantity {
+	const mib = 1024 * 1024
+	size := int64(math.Ceil(float64(virtualSize) / (1 - overhead)))
+	size = (size + mib - 1) / mib * mib
+	return *resource.NewQuantity(size, resource.BinarySI)
+}
+
+func (r *DatavolumeReconciler) updateInferredSize(dv *cdiv1.DataVolume, size resource.Quantity) error {
+	dvCopy := dv.DeepCopy()
+	dvCopy.Status.InferredSize = &size
+	clearSourceRefPVC(dvCopy)
+	clearStoragePVC(dvCopy)
+	if err := r.client.Update(context.TODO(), dvCopy); err != nil {
+		return err
+	}
+	r.log.V(1).Info("Inferred DataVolume storage size", "DataVolume", dv.Name, "size", size.String())
+	return nil
+}
+
+// newSizeProbePod returns a pod running the importer to probe the size of the source, with the credentials, certs and
+// TLS settings of the import pod
+func (r *DatavolumeReconciler) newSizeProbePod(dv *cdiv1.DataVolume, podName string) (*corev1.Pod, error) {
+	var sourceType, endpoint, secretName, certConfigMap string
+	switch {
+	case dv.Spec.Source.HTTP != nil:
+		sourceType, endpoint, secretName = SourceHTTP, dv.Spec.Source.HTTP.URL, dv.Spec.Source.HTTP.SecretRef
+		certConfigMap = dv.Spec.Source.HTTP.CertConfigMap
+	case dv.Spec.Source.S3 != nil:
+		sourceType, endpoint, secretName = SourceS3, dv.Spec.Source.S3.URL, dv.Spec.Source.S3.SecretRef
+	}
+	insecureTLS, err := isInsecureTLS(r.client, r.log, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	podResourceRequirements, err := GetDefaultPodResourceRequirements(r.client)
+	if err != nil {
+		return nil, err
+	}
+	workloadNodePlacement, err := GetWorkloadNodePlacement(r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	container := corev1.Container{
+		Name:            sizeProbePodPrefix,
+		Image:           r.image,
+		ImagePullPolicy: corev1.PullPolicy(r.pullPolicy),
+		Args:            []string{"-v=" + r.verbose},
+		Env: []corev1.EnvVar{
+			{
+				Name:  common.ImporterProbeVirtualSize,
+				Value: "true",
+			},
+			{
+				Name:  common.ImporterSource,
+				Value: sourceType,
+			},
+			{
+				Name:  common.ImporterEndpoint,
+				Value: endpoint,
+			},
+			{
+				Name:  common.InsecureTLSVar,
+				Value: strconv.FormatBool(insecureTLS),
+			},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+	}
+	if podResourceRequirements != nil {
+		container.Resources = *podResourceRequirements
+	}
+	if secretName != "" {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name: common.ImporterAccessKeyID,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: secretName,
+					},
+					Key: common.KeyAccess,
+				},
+			},
+		}, corev1.EnvVar{
+			Name: common.ImporterSecretKey,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: secretName,
+					},
+					Key: common.KeySecret,
+				},
+			},
+		})
+	}
+
+	var volumes []corev1.Volume
+	if certConfigMap != "" {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  common.ImporterCertDirVar,
+			Value: common.ImporterCertDir,
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      CertVolName,
+			MountPath: common.ImporterCertDir,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: CertVolName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: certConfigMap,
+					},
+				},
+			},
+		})
+	}
+
+	blockOwnerDeletion := true
+	isController := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: dv.Namespace,
+			Labels: map[string]string{
+				common.CDILabelKey:       common.CDILabelValue,
+				common.CDIComponentLabel: sizeProbePodPrefix,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         cdiv1.SchemeGroupVersion.String(),
+					Kind:               "DataVolume",
+					Name:               dv.Name,
+					UID:                dv.UID,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+					Controller:         &isController,
+				},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers:    []corev1.Container{container},
+			Volumes:       volumes,
+			RestartPolicy: corev1.RestartPolicyNever,
+			NodeSelector:  workloadNodePlacement.NodeSelector,
+			Tolerations:   workloadNodePlacement.Tolerations,
+			Affinity:      workloadNodePlacement.Affinity,
+		},
+	}, nil
+}
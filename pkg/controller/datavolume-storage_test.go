@@ -0,0 +1,314 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util/naming"
+)
+
+var _ = Describe("Datavolume controller storage size inference", func() {
+	var (
+		reconciler *DatavolumeReconciler
+	)
+	AfterEach(func() {
+		if reconciler != nil {
+			close(reconciler.recorder.(*record.FakeRecorder).Events)
+			reconciler = nil
+		}
+	})
+
+	reconcileDataVolume := func() *cdiv1.DataVolume {
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		dv := &cdiv1.DataVolume{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+		Expect(err).ToNot(HaveOccurred())
+		return dv
+	}
+
+	It("Should create the PVC with the requested storage size", func() {
+		dv := newStorageDataVolume(newImportDataVolume("test-dv"))
+		dv.Spec.Storage.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}
+		reconciler = createDatavolumeReconciler(dv)
+		dv = reconcileDataVolume()
+		Expect(dv.Spec.PVC).To(BeNil())
+		Expect(dv.Status.InferredSize).To(BeNil())
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("1Gi")))
+	})
+
+	It("Should infer the size of a clone from the source PVC", func() {
+		dv := newStorageDataVolume(newCloneDataVolume("test-dv"))
+		reconciler = createDatavolumeReconciler(dv, createPvc("test", metav1.NamespaceDefault, nil, nil))
+		dv = reconcileDataVolume()
+		Expect(dv.Spec.PVC).To(BeNil())
+		Expect(dv.Status.InferredSize).ToNot(BeNil())
+		Expect(dv.Status.InferredSize.Cmp(resource.MustParse("1G"))).To(BeZero())
+
+		dv = reconcileDataVolume()
+		Expect(dv.Spec.PVC).To(BeNil())
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("1G")))
+	})
+
+	It("Should add the filesystem overhead to the size of a block source PVC", func() {
+		dv := newStorageDataVolume(newCloneDataVolume("test-dv"))
+		sourcePvc := createBlockPvc("test", metav1.NamespaceDefault, nil, nil)
+		sourcePvc.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("1Gi")
+		reconciler = createDatavolumeReconciler(dv, sourcePvc)
+		cdiConfig := &cdiv1.CDIConfig{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		cdiConfig.Status.FilesystemOverhead = &cdiv1.FilesystemOverhead{Global: "0.5"}
+		Expect(reconciler.client.Update(context.TODO(), cdiConfig)).To(Succeed())
+
+		dv = reconcileDataVolume()
+		Expect(dv.Status.InferredSize).ToNot(BeNil())
+		Expect(dv.Status.InferredSize.Cmp(resource.MustParse("2Gi"))).To(BeZero())
+	})
+
+	It("Should create a size probe pod for an HTTP source without creating the PVC", func() {
+		dv := newStorageDataVolume(newImportDataVolume("test-dv"))
+		dv.Spec.Source.HTTP.SecretRef = "test-secret"
+		reconciler = createDatavolumeReconciler(dv)
+		cdiConfig := &cdiv1.CDIConfig{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		cdiConfig.Status.DefaultPodResourceRequirements = createDefaultPodResourceRequirements(1, 1024, 1, 1024)
+		Expect(reconciler.client.Update(context.TODO(), cdiConfig)).To(Succeed())
+		cdi, err := GetActiveCDI(reconciler.client)
+		Expect(err).ToNot(HaveOccurred())
+		cdi.Spec.Workloads.NodeSelector = map[string]string{"kubernetes.io/arch": "amd64"}
+		Expect(reconciler.client.Update(context.TODO(), cdi)).To(Succeed())
+		dv = reconcileDataVolume()
+		Expect(dv.Status.InferredSize).To(BeNil())
+
+		pod := &corev1.Pod{}
+		podName := naming.GetResourceName(sizeProbePodPrefix, dv.Name)
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metav1.IsControlledBy(pod, dv)).To(BeTrue())
+		Expect(pod.Spec.Containers[0].Resources.Limits.Memory().Value()).To(Equal(int64(1024)))
+		Expect(pod.Spec.Containers[0].Resources.Requests.Cpu().Value()).To(Equal(int64(1)))
+		Expect(pod.Spec.NodeSelector).To(Equal(cdi.Spec.Workloads.NodeSelector))
+		env := pod.Spec.Containers[0].Env
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: common.ImporterProbeVirtualSize, Value: "true"}))
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: common.ImporterSource, Value: SourceHTTP}))
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: common.ImporterEndpoint, Value: dv.Spec.Source.HTTP.URL}))
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: common.InsecureTLSVar, Value: "false"}))
+		Expect(env).To(HaveLen(6))
+		Expect(pod.Spec.Volumes).To(BeEmpty())
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should infer the size from the probed virtual size and the filesystem overhead", func() {
+		dv := newStorageDataVolume(newImportDataVolume("test-dv"))
+		reconciler = createDatavolumeReconciler(dv)
+		cdiConfig := &cdiv1.CDIConfig{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		cdiConfig.Status.FilesystemOverhead = &cdiv1.FilesystemOverhead{Global: "0.5"}
+		Expect(reconciler.client.Update(context.TODO(), cdiConfig)).To(Succeed())
+		dv = reconcileDataVolume()
+
+		pod := &corev1.Pod{}
+		podName := naming.GetResourceName(sizeProbePodPrefix, dv.Name)
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{newProbeTerminatedStatus(0, `{"virtualSize": 1073741824}`)}
+		Expect(reconciler.client.Update(context.TODO(), pod)).To(Succeed())
+
+		dv = reconcileDataVolume()
+		Expect(dv.Spec.PVC).To(BeNil())
+		Expect(dv.Status.InferredSize).ToNot(BeNil())
+		Expect(dv.Status.InferredSize.Cmp(resource.MustParse("2Gi"))).To(BeZero())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+		dv = reconcileDataVolume()
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("2Gi")))
+	})
+
+	It("Should mount the cert config map of an HTTP source in the size probe pod", func() {
+		dv := newStorageDataVolume(newImportDataVolume("test-dv"))
+		dv.Spec.Source.HTTP.CertConfigMap = "test-certs"
+		reconciler = createDatavolumeReconciler(dv)
+		dv = reconcileDataVolume()
+
+		pod := &corev1.Pod{}
+		podName := naming.GetResourceName(sizeProbePodPrefix, dv.Name)
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: common.ImporterCertDirVar, Value: common.ImporterCertDir}))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: CertVolName, MountPath: common.ImporterCertDir}))
+		Expect(pod.Spec.Volumes).To(HaveLen(1))
+		Expect(pod.Spec.Volumes[0].Name).To(Equal(CertVolName))
+		Expect(pod.Spec.Volumes[0].ConfigMap).ToNot(BeNil())
+		Expect(pod.Spec.Volumes[0].ConfigMap.Name).To(Equal("test-certs"))
+	})
+
+	It("Should report a failure to probe the size and probe again", func() {
+		dv := newStorageDataVolume(newImportDataVolume("test-dv"))
+		reconciler = createDatavolumeReconciler(dv)
+		dv = reconcileDataVolume()
+
+		pod := &corev1.Pod{}
+		podName := naming.GetResourceName(sizeProbePodPrefix, dv.Name)
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{newProbeTerminatedStatus(1, "Unable to probe source")}
+		Expect(reconciler.client.Update(context.TODO(), pod)).To(Succeed())
+
+		dv = reconcileDataVolume()
+		Expect(dv.Status.InferredSize).To(BeNil())
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(SizeProbeFailed))
+		Expect(event).To(ContainSubstring("Unable to probe source"))
+		condition := findConditionByType(cdiv1.DataVolumeRunning, dv.Status.Conditions)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(SizeProbeFailed))
+		Expect(condition.Message).To(ContainSubstring("Unable to probe source"))
+		By("Deleting the failed pod once the backoff elapsed")
+		Expect(dv.Annotations[AnnSizeProbeRetries]).To(Equal("1"))
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+		dv = reconcileDataVolume()
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Should keep the failed size probe pod until the backoff elapsed", func() {
+		dv := newStorageDataVolume(newImportDataVolume("test-dv"))
+		dv.Annotations = map[string]string{AnnSizeProbeRetries: "1"}
+		reconciler = createDatavolumeReconciler(dv)
+		dv = reconcileDataVolume()
+
+		pod := &corev1.Pod{}
+		podName := naming.GetResourceName(sizeProbePodPrefix, dv.Name)
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		status := newProbeTerminatedStatus(1, "Unable to probe source")
+		status.State.Terminated.FinishedAt = metav1.Now()
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{status}
+		Expect(reconciler.client.Update(context.TODO(), pod)).To(Succeed())
+
+		result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", sizeProbeInitialBackoff))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", 2*sizeProbeInitialBackoff))
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		dv = reconcileDataVolume()
+		Expect(dv.Annotations[AnnSizeProbeRetries]).To(Equal("1"))
+	})
+
+	It("Should stop probing the size after the maximum number of attempts", func() {
+		dv := newStorageDataVolume(newImportDataVolume("test-dv"))
+		dv.Annotations = map[string]string{AnnSizeProbeRetries: strconv.Itoa(sizeProbeMaxAttempts - 1)}
+		reconciler = createDatavolumeReconciler(dv)
+		dv = reconcileDataVolume()
+
+		pod := &corev1.Pod{}
+		podName := naming.GetResourceName(sizeProbePodPrefix, dv.Name)
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{newProbeTerminatedStatus(1, "Unable to probe source")}
+		Expect(reconciler.client.Update(context.TODO(), pod)).To(Succeed())
+
+		result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(fmt.Sprintf("after %d attempts", sizeProbeMaxAttempts)))
+		Expect(event).To(ContainSubstring("Unable to probe source"))
+		dv = reconcileDataVolume()
+		condition := findConditionByType(cdiv1.DataVolumeRunning, dv.Status.Conditions)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Reason).To(Equal(SizeProbeFailed))
+		Expect(condition.Message).To(ContainSubstring("Unable to probe source"))
+		Expect(dv.Annotations[AnnSizeProbeRetries]).To(Equal(strconv.Itoa(sizeProbeMaxAttempts - 1)))
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: metav1.NamespaceDefault}, pod)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	DescribeTable("Should back off exponentially before probing the size again", func(retries int, expected time.Duration) {
+		Expect(sizeProbeBackoff(retries)).To(Equal(expected))
+	},
+		Entry("after the first failure", 0, 10*time.Second),
+		Entry("after the second failure", 1, 20*time.Second),
+		Entry("up to the maximum", 10, 5*time.Minute),
+	)
+
+	DescribeTable("Should inflate the virtual size with the filesystem overhead", func(virtualSize int64, overhead float64, expected string) {
+		size := inflateSizeWithOverhead(virtualSize, overhead)
+		Expect(size.Cmp(resource.MustParse(expected))).To(BeZero())
+	},
+		Entry("without overhead", int64(1024*1024*1024), 0.0, "1Gi"),
+		Entry("rounding up to a MiB", int64(1024*1024+1), 0.0, "2Mi"),
+		Entry("with overhead", int64(1024*1024*1024), 0.5, "2Gi"),
+		Entry("with the default overhead", int64(1000*1024*1024), 0.055, "1059Mi"),
+	)
+})
+
+// newStorageDataVolume replaces the PVC spec of the DataVolume with a storage spec without size
+func newStorageDataVolume(dv *cdiv1.DataVolume) *cdiv1.DataVolume {
+	dv.Spec.PVC = nil
+	dv.Spec.Storage = &cdiv1.StorageSpec{}
+	return dv
+}
+
+func newProbeTerminatedStatus(exitCode int32, message string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: exitCode,
+				Message:  message,
+			},
+		},
+	}
+}
@@ -0,0 +1,515 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util/cert/fetcher"
+	"kubevirt.io/containerized-data-importer/pkg/util/cert/generator"
+	"kubevirt.io/containerized-data-importer/pkg/util/naming"
+)
+
+const (
+	// ExportSourceInUse is reason for event created when the exported PVC is being written by another pod
+	ExportSourceInUse = "ExportSourceInUse"
+
+	// ExportSourceNotFound is reason for event created when the exported PVC or DataVolume does not exist
+	ExportSourceNotFound = "ExportSourceNotFound"
+
+	// ExportServerReady is reason for event created when the export server can serve downloads
+	ExportServerReady = "ExportServerReady"
+
+	exportServerCertDuration = 365 * 24 * time.Hour
+
+	exportServerPodName = "cdi-export-server"
+)
+
+// ExportReconciler members
+type ExportReconciler struct {
+	client              client.Client
+	recorder            record.EventRecorder
+	scheme              *runtime.Scheme
+	log                 logr.Logger
+	image               string
+	verbose             string
+	pullPolicy          string
+	serverCertGenerator generator.CertGenerator
+	clientCAFetcher     fetcher.CertBundleFetcher
+}
+
+// Reconcile starts an export server for the PVC of a VolumeExport and reports when it is ready to serve downloads
+func (r *ExportReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("VolumeExport", req.NamespacedName)
+
+	volumeExport := &cdiv1.VolumeExport{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, volumeExport); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if volumeExport.DeletionTimestamp != nil {
+		log.Info("VolumeExport marked for deletion, skipping")
+		return reconcile.Result{}, nil
+	}
+
+	pvc, err := r.getExportSourcePvc(volumeExport)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if pvc == nil {
+		log.V(3).Info("Export source not available yet")
+		return reconcile.Result{}, r.updateExportPhase(volumeExport, cdiv1.VolumeExportPending)
+	}
+
+	podName := createExportResourceName(volumeExport.Name)
+	pod := &corev1.Pod{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: volumeExport.Namespace}, pod); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		// Serving a volume while another pod writes to it would produce a corrupted image
+		podsUsingPVC, err := getPodsUsingPVCs(r.client, pvc.Namespace, sets.NewString(pvc.Name), true)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if len(podsUsingPVC) > 0 {
+			for _, pod := range podsUsingPVC {
+				log.V(1).Info("can't create export pod, pvc in use by other pod", "pod", pod.Name)
+				r.recorder.Eventf(volumeExport, corev1.EventTypeWarning, ExportSourceInUse,
+					"pod %s/%s using PersistentVolumeClaim %s", pod.Namespace, pod.Name, pvc.Name)
+			}
+			return reconcile.Result{Requeue: true}, r.updateExportPhase(volumeExport, cdiv1.VolumeExportPending)
+		}
+		if pod, err = r.createExportPod(volumeExport, pvc, podName); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if !metav1.IsControlledBy(pod, volumeExport) {
+		return reconcile.Result{}, errors.Errorf("%s pod not controlled by VolumeExport %s", podName, volumeExport.Name)
+	}
+
+	if err := r.getOrCreateExportScratchPvc(pvc, pod); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.getOrCreateExportService(volumeExport, naming.GetServiceNameFromResourceName(podName)); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	phase := cdiv1.VolumeExportPending
+	if isPodReady(pod) {
+		phase = cdiv1.VolumeExportReady
+	}
+	if phase == cdiv1.VolumeExportReady && volumeExport.Status.Phase != cdiv1.VolumeExportReady {
+		r.recorder.Event(volumeExport, corev1.EventTypeNormal, ExportServerReady, "Export server ready")
+	}
+	return reconcile.Result{}, r.updateExportPhase(volumeExport, phase)
+}
+
+// getExportSourcePvc returns the PVC to export, or nil if it does not exist or is not populated yet
+func (r *ExportReconciler) getExportSourcePvc(volumeExport *cdiv1.VolumeExport) (*corev1.PersistentVolumeClaim, error) {
+	source := volumeExport.Spec.Source
+	key := types.NamespacedName{Name: source.Name, Namespace: volumeExport.Namespace}
+
+	switch source.Kind {
+	case cdiv1.VolumeExportSourcePVC:
+	case cdiv1.VolumeExportSourceDataVolume:
+		dv := &cdiv1.DataVolume{}
+		if err := r.client.Get(context.TODO(), key, dv); err != nil {
+			if k8serrors.IsNotFound(err) {
+				r.recorder.Eventf(volumeExport, corev1.EventTypeWarning, ExportSourceNotFound, "DataVolume %s not found", source.Name)
+				return nil, nil
+			}
+			return nil, err
+		}
+		if dv.Status.Phase != cdiv1.Succeeded {
+			return nil, nil
+		}
+	default:
+		return nil, errors.Errorf("unsupported export source kind %q", source.Kind)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(context.TODO(), key, pvc); err != nil {
+		if k8serrors.IsNotFound(err) {
+			r.recorder.Eventf(volumeExport, corev1.EventTypeWarning, ExportSourceNotFound, "PersistentVolumeClaim %s not found", source.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return nil, nil
+	}
+	return pvc, nil
+}
+
+func (r *ExportReconciler) updateExportPhase(volumeExport *cdiv1.VolumeExport, phase cdiv1.VolumeExportPhase) error {
+	if volumeExport.Status.Phase == phase {
+		return nil
+	}
+	volumeExportCopy := volumeExport.DeepCopy()
+	volumeExportCopy.Status.Phase = phase
+	return r.client.Update(context.TODO(), volumeExportCopy)
+}
+
+func (r *ExportReconciler) createExportPod(volumeExport *cdiv1.VolumeExport, pvc *corev1.PersistentVolumeClaim, podName string) (*corev1.Pod, error) {
+	serverCert, serverKey, err := r.serverCertGenerator.MakeServerCert(volumeExport.Namespace, naming.GetServiceNameFromResourceName(podName), exportServerCertDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCA, err := r.clientCAFetcher.BundleBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceRequirements, err := GetDefaultPodResourceRequirements(r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	workloadNodePlacement, err := GetWorkloadNodePlacement(r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: volumeExport.Namespace,
+			Labels: map[string]string{
+				common.CDILabelKey:              common.CDILabelValue,
+				common.CDIComponentLabel:        common.ExportServerCDILabel,
+				common.UploadServerServiceLabel: naming.GetServiceNameFromResourceName(podName),
+			},
+		},
+		Spec: corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsUser: &[]int64{0}[0],
+			},
+			Containers: []corev1.Container{
+				{
+					Name:            exportServerPodName,
+					Image:           r.image,
+					ImagePullPolicy: corev1.PullPolicy(r.pullPolicy),
+					Env: []corev1.EnvVar{
+						{
+							Name:  "TLS_KEY",
+							Value: string(serverKey),
+						},
+						{
+							Name:  "TLS_CERT",
+							Value: string(serverCert),
+						},
+						{
+							Name:  "CLIENT_CERT",
+							Value: string(clientCA),
+						},
+						{
+							Name: "CLIENT_NAME",
+							// downloads are proxied with the upload proxy client certificate
+							Value: uploadServerClientName,
+						},
+					},
+					Args: []string{"-v=" + r.verbose},
+					ReadinessProbe: &corev1.Probe{
+						Handler: corev1.Handler{
+							HTTPGet: &corev1.HTTPGetAction{
+								Path: "/healthz",
+								Port: intstr.IntOrString{
+									Type:   intstr.Int,
+									IntVal: 8080,
+								},
+							},
+						},
+						InitialDelaySeconds: 2,
+						PeriodSeconds:       5,
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      ScratchVolName,
+							MountPath: common.ExportServerScratchDir,
+						},
+					},
+				},
+			},
+			RestartPolicy: corev1.RestartPolicyOnFailure,
+			Volumes: []corev1.Volume{
+				{
+					Name: DataVolName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvc.Name,
+							ReadOnly:  true,
+						},
+					},
+				},
+				{
+					Name: ScratchVolName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: naming.GetResourceName(podName, common.ScratchNameSuffix),
+						},
+					},
+				},
+			},
+			NodeSelector: workloadNodePlacement.NodeSelector,
+			Tolerations:  workloadNodePlacement.Tolerations,
+			Affinity:     workloadNodePlacement.Affinity,
+		},
+	}
+
+	if resourceRequirements != nil {
+		pod.Spec.Containers[0].Resources = *resourceRequirements
+	}
+
+	exportSource := common.WriteBlockPath
+	if getVolumeMode(pvc) == corev1.PersistentVolumeBlock {
+		pod.Spec.Containers[0].VolumeDevices = []corev1.VolumeDevice{
+			{
+				Name:       DataVolName,
+				DevicePath: common.WriteBlockPath,
+			},
+		}
+	} else {
+		exportSource = common.UploadServerDataDir + "/" + common.DiskImageName
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      DataVolName,
+			MountPath: common.UploadServerDataDir,
+			ReadOnly:  true,
+		})
+	}
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  common.ExportSource,
+		Value: exportSource,
+	})
+
+	if err := controllerutil.SetControllerReference(volumeExport, pod, r.scheme); err != nil {
+		return nil, err
+	}
+	if err := r.client.Create(context.TODO(), pod); err != nil {
+		return nil, err
+	}
+	r.log.V(1).Info("export pod created", "Namespace", pod.Namespace, "Name", pod.Name, "Image name", r.image)
+	return pod, nil
+}
+
+// getOrCreateExportScratchPvc creates the scratch PVC of the export pod, where the gzip and qcow2 images are converted.
+// It is sized to hold both images of the whole volume on the scratch storage class.
+func (r *ExportReconciler) getOrCreateExportScratchPvc(pvc *corev1.PersistentVolumeClaim, pod *corev1.Pod) error {
+	var name string
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Name == ScratchVolName && vol.PersistentVolumeClaim != nil {
+			name = vol.PersistentVolumeClaim.ClaimName
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	scratchPvc := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: pod.Namespace}, scratchPvc); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return errors.Wrap(err, "error getting export scratch PVC")
+		}
+		sourceSize, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if !ok {
+			return errors.Errorf("PersistentVolumeClaim %s has no storage request", pvc.Name)
+		}
+		scratchPvc = newScratchPersistentVolumeClaimSpec(pvc, pod, name, GetScratchPvcStorageClass(r.client, pvc))
+		overhead, err := GetFilesystemOverhead(r.client, scratchPvc)
+		if err != nil {
+			return err
+		}
+		fsOverhead, err := strconv.ParseFloat(string(overhead), 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid filesystem overhead %s", overhead)
+		}
+		scratchPvc.Spec.Resources = corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceStorage: inflateSizeWithOverhead(2*sourceSize.Value(), fsOverhead),
+			},
+		}
+		if err := r.client.Create(context.TODO(), scratchPvc); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return errors.Wrap(err, "error creating export scratch PVC")
+		}
+		r.log.V(1).Info("export scratch PVC created", "Namespace", scratchPvc.Namespace, "Name", scratchPvc.Name)
+		return nil
+	}
+	if !metav1.IsControlledBy(scratchPvc, pod) {
+		return errors.Errorf("%s scratch PVC not controlled by pod %s", scratchPvc.Name, pod.Name)
+	}
+	return nil
+}
+
+func (r *ExportReconciler) getOrCreateExportService(volumeExport *cdiv1.VolumeExport, name string) error {
+	service := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: volumeExport.Namespace}, service); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return errors.Wrap(err, "error getting export service")
+		}
+		service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: volumeExport.Namespace,
+				Labels: map[string]string{
+					common.CDILabelKey:       common.CDILabelValue,
+					common.CDIComponentLabel: common.ExportServerCDILabel,
+				},
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{
+						Protocol: "TCP",
+						Port:     443,
+						TargetPort: intstr.IntOrString{
+							Type:   intstr.Int,
+							IntVal: 8443,
+						},
+					},
+				},
+				Selector: map[string]string{
+					common.UploadServerServiceLabel: name,
+				},
+			},
+		}
+		if err := controllerutil.SetControllerReference(volumeExport, service, r.scheme); err != nil {
+			return err
+		}
+		if err := r.client.Create(context.TODO(), service); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return errors.Wrap(err, "export service API create errored")
+		}
+		return nil
+	}
+
+	if !metav1.IsControlledBy(service, volumeExport) {
+		return errors.Errorf("%s service not controlled by VolumeExport %s", name, volumeExport.Name)
+	}
+	return nil
+}
+
+// createExportResourceName returns the name given to export resources
+func createExportResourceName(name string) string {
+	return naming.GetResourceName("cdi-export", name)
+}
+
+// GetExportServerURL returns the url the proxy should forward downloads of a particular VolumeExport to
+func GetExportServerURL(namespace, volumeExport, exportPath string) string {
+	serviceName := naming.GetServiceNameFromResourceName(createExportResourceName(volumeExport))
+	return fmt.Sprintf("https://%s.%s.svc%s", serviceName, namespace, exportPath)
+}
+
+// GetExportServerPodName returns the name of the export server pod of a particular VolumeExport
+func GetExportServerPodName(volumeExport string) string {
+	return createExportResourceName(volumeExport)
+}
+
+// NewExportController creates a new instance of the export controller.
+func NewExportController(mgr manager.Manager, log logr.Logger, uploadImage, pullPolicy, verbose string, serverCertGenerator generator.CertGenerator, clientCAFetcher fetcher.CertBundleFetcher) (controller.Controller, error) {
+	reconciler := &ExportReconciler{
+		client:              mgr.GetClient(),
+		scheme:              mgr.GetScheme(),
+		log:                 log.WithName("export-controller"),
+		image:               uploadImage,
+		verbose:             verbose,
+		pullPolicy:          pullPolicy,
+		recorder:            mgr.GetEventRecorderFor("export-controller"),
+		serverCertGenerator: serverCertGenerator,
+		clientCAFetcher:     clientCAFetcher,
+	}
+	exportController, err := controller.New("export-controller", mgr, controller.Options{
+		Reconciler: reconciler,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := addExportControllerWatches(mgr, exportController); err != nil {
+		return nil, err
+	}
+	return exportController, nil
+}
+
+func addExportControllerWatches(mgr manager.Manager, exportController controller.Controller) error {
+	if err := cdiv1.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+
+	// Setup watches
+	if err := exportController.Watch(&source.Kind{Type: &cdiv1.VolumeExport{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	if err := exportController.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &cdiv1.VolumeExport{},
+		IsController: true,
+	}); err != nil {
+		return err
+	}
+	if err := exportController.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &cdiv1.VolumeExport{},
+		IsController: true,
+	}); err != nil {
+		return err
+	}
+	// PVCs and DataVolumes are mapped to the VolumeExports of their namespace exporting them
+	mapToVolumeExport := func(kind string) *handler.EnqueueRequestsFromMapFunc {
+		return &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+				volumeExports := &cdiv1.VolumeExportList{}
+				if err := mgr.GetClient().List(context.TODO(), volumeExports, &client.ListOptions{Namespace: obj.Meta.GetNamespace()}); err != nil {
+					return nil
+				}
+				var reqs []reconcile.Request
+				for _, volumeExport := range volumeExports.Items {
+					if volumeExport.Spec.Source.Kind == kind && volumeExport.Spec.Source.Name == obj.Meta.GetName() {
+						reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: volumeExport.Namespace, Name: volumeExport.Name}})
+					}
+				}
+				return reqs
+			}),
+		}
+	}
+	if err := exportController.Watch(&source.Kind{Type: &corev1.PersistentVolumeClaim{}}, mapToVolumeExport(cdiv1.VolumeExportSourcePVC)); err != nil {
+		return err
+	}
+	if err := exportController.Watch(&source.Kind{Type: &cdiv1.DataVolume{}}, mapToVolumeExport(cdiv1.VolumeExportSourceDataVolume)); err != nil {
+		return err
+	}
+	return nil
+}
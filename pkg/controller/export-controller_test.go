@@ -0,0 +1,248 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util/cert/fetcher"
+)
+
+var (
+	exportLog = logf.Log.WithName("export-controller-test")
+)
+
+var _ = Describe("Export controller reconcile loop", func() {
+	var (
+		reconciler *ExportReconciler
+	)
+	AfterEach(func() {
+		if reconciler != nil {
+			close(reconciler.recorder.(*record.FakeRecorder).Events)
+			reconciler = nil
+		}
+	})
+
+	reconcileExport := func() reconcile.Result {
+		result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-export", Namespace: metav1.NamespaceDefault}})
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	getVolumeExport := func() *cdiv1.VolumeExport {
+		volumeExport := &cdiv1.VolumeExport{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-export", Namespace: metav1.NamespaceDefault}, volumeExport)
+		Expect(err).ToNot(HaveOccurred())
+		return volumeExport
+	}
+
+	getExportPod := func() (*corev1.Pod, error) {
+		pod := &corev1.Pod{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "cdi-export-test-export", Namespace: metav1.NamespaceDefault}, pod)
+		return pod, err
+	}
+
+	getExportEnv := func(pod *corev1.Pod, name string) string {
+		for _, env := range pod.Spec.Containers[0].Env {
+			if env.Name == name {
+				return env.Value
+			}
+		}
+		return ""
+	}
+
+	It("Should create a read-only export pod and service for a PVC", func() {
+		reconciler = createExportReconciler(newVolumeExport(cdiv1.VolumeExportSourcePVC, "test-pvc"), createPvc("test-pvc", metav1.NamespaceDefault, nil, nil))
+		reconcileExport()
+
+		pod, err := getExportPod()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Labels[common.CDIComponentLabel]).To(Equal(common.ExportServerCDILabel))
+		Expect(pod.OwnerReferences).To(HaveLen(1))
+		Expect(pod.OwnerReferences[0].Kind).To(Equal("VolumeExport"))
+		Expect(pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("test-pvc"))
+		Expect(pod.Spec.Volumes[0].PersistentVolumeClaim.ReadOnly).To(BeTrue())
+		Expect(pod.Spec.Containers[0].Image).To(Equal("test/myimage"))
+		Expect(getExportEnv(pod, common.ExportSource)).To(Equal(common.UploadServerDataDir + "/" + common.DiskImageName))
+		Expect(getExportEnv(pod, "CLIENT_NAME")).To(Equal(uploadServerClientName))
+		Expect(pod.Spec.Volumes[1].Name).To(Equal(ScratchVolName))
+		Expect(pod.Spec.Volumes[1].PersistentVolumeClaim.ClaimName).To(Equal("cdi-export-test-export-scratch"))
+		for _, mount := range pod.Spec.Containers[0].VolumeMounts {
+			if mount.Name == DataVolName {
+				Expect(mount.ReadOnly).To(BeTrue())
+			}
+		}
+
+		service := &corev1.Service{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "cdi-export-test-export", Namespace: metav1.NamespaceDefault}, service)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(service.Spec.Selector[common.UploadServerServiceLabel]).To(Equal(pod.Labels[common.UploadServerServiceLabel]))
+		Expect(getVolumeExport().Status.Phase).To(Equal(cdiv1.VolumeExportPending))
+	})
+
+	It("Should create a scratch PVC big enough for the converted images", func() {
+		sourcePvc := createPvc("test-pvc", metav1.NamespaceDefault, nil, nil)
+		reconciler = createExportReconciler(newVolumeExport(cdiv1.VolumeExportSourcePVC, "test-pvc"), sourcePvc)
+		reconcileExport()
+
+		pod, err := getExportPod()
+		Expect(err).ToNot(HaveOccurred())
+		scratchPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "cdi-export-test-export-scratch", Namespace: metav1.NamespaceDefault}, scratchPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metav1.IsControlledBy(scratchPvc, pod)).To(BeTrue())
+		sourceSize := sourcePvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		scratchSize := scratchPvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		Expect(scratchSize.Value()).To(BeNumerically(">=", 2*sourceSize.Value()))
+		Expect(scratchPvc.Spec.VolumeMode).To(BeNil())
+	})
+
+	It("Should export the device of a block PVC", func() {
+		reconciler = createExportReconciler(newVolumeExport(cdiv1.VolumeExportSourcePVC, "test-pvc"), createBlockPvc("test-pvc", metav1.NamespaceDefault, nil, nil))
+		reconcileExport()
+
+		pod, err := getExportPod()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Containers[0].VolumeDevices).To(HaveLen(1))
+		Expect(getExportEnv(pod, common.ExportSource)).To(Equal(common.WriteBlockPath))
+	})
+
+	It("Should be ready when the export pod is ready", func() {
+		reconciler = createExportReconciler(newVolumeExport(cdiv1.VolumeExportSourcePVC, "test-pvc"), createPvc("test-pvc", metav1.NamespaceDefault, nil, nil))
+		reconcileExport()
+
+		pod, err := getExportPod()
+		Expect(err).ToNot(HaveOccurred())
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: exportServerPodName, Ready: true}}
+		Expect(reconciler.client.Update(context.TODO(), pod)).To(Succeed())
+		reconcileExport()
+		Expect(getVolumeExport().Status.Phase).To(Equal(cdiv1.VolumeExportReady))
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(ExportServerReady))
+	})
+
+	It("Should wait for the DataVolume to succeed", func() {
+		dv := newImportDataVolume("test-dv")
+		dv.Status.Phase = cdiv1.ImportInProgress
+		reconciler = createExportReconciler(newVolumeExport(cdiv1.VolumeExportSourceDataVolume, "test-dv"), dv, createPvc("test-dv", metav1.NamespaceDefault, nil, nil))
+		reconcileExport()
+		_, err := getExportPod()
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		Expect(getVolumeExport().Status.Phase).To(Equal(cdiv1.VolumeExportPending))
+
+		dv.Status.Phase = cdiv1.Succeeded
+		Expect(reconciler.client.Update(context.TODO(), dv)).To(Succeed())
+		reconcileExport()
+		pod, err := getExportPod()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("test-dv"))
+	})
+
+	It("Should not export a PVC written by another pod", func() {
+		writer := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "writer", Namespace: metav1.NamespaceDefault},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{
+					{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "test-pvc"},
+						},
+					},
+				},
+			},
+		}
+		reconciler = createExportReconciler(newVolumeExport(cdiv1.VolumeExportSourcePVC, "test-pvc"), createPvc("test-pvc", metav1.NamespaceDefault, nil, nil), writer)
+		result := reconcileExport()
+		Expect(result.Requeue).To(BeTrue())
+		_, err := getExportPod()
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(ExportSourceInUse))
+	})
+
+	It("Should report a missing PVC", func() {
+		reconciler = createExportReconciler(newVolumeExport(cdiv1.VolumeExportSourcePVC, "test-pvc"))
+		reconcileExport()
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(ExportSourceNotFound))
+	})
+
+	It("Should return the export server URL", func() {
+		Expect(GetExportServerURL("ns", "test-export", common.ExportPath)).To(Equal("https://cdi-export-test-export.ns.svc" + common.ExportPath))
+	})
+})
+
+func createExportReconciler(objects ...runtime.Object) *ExportReconciler {
+	objs := []runtime.Object{}
+	objs = append(objs, objects...)
+	objs = append(objs, MakeEmptyCDICR())
+	cdiConfig := MakeEmptyCDIConfigSpec(common.ConfigName)
+	cdiConfig.Status = cdiv1.CDIConfigStatus{
+		DefaultPodResourceRequirements: createDefaultPodResourceRequirements(int64(0), int64(0), int64(0), int64(0)),
+	}
+	objs = append(objs, cdiConfig)
+
+	s := scheme.Scheme
+	cdiv1.AddToScheme(s)
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+
+	return &ExportReconciler{
+		client:              cl,
+		scheme:              s,
+		log:                 exportLog,
+		image:               "test/myimage",
+		verbose:             "5",
+		pullPolicy:          "Always",
+		serverCertGenerator: &fakeCertGenerator{},
+		clientCAFetcher:     &fetcher.MemCertBundleFetcher{Bundle: []byte("baz")},
+		recorder:            record.NewFakeRecorder(10),
+	}
+}
+
+func newVolumeExport(kind, name string) *cdiv1.VolumeExport {
+	return &cdiv1.VolumeExport{
+		TypeMeta: metav1.TypeMeta{APIVersion: cdiv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-export",
+			Namespace: metav1.NamespaceDefault,
+			UID:       types.UID(metav1.NamespaceDefault + "-test-export"),
+		},
+		Spec: cdiv1.VolumeExportSpec{
+			Source: cdiv1.VolumeExportSource{
+				Kind: kind,
+				Name: name,
+			},
+		},
+	}
+}
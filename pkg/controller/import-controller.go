@@ -73,6 +73,10 @@ const (
 	AnnBackingFile = AnnAPIGroup + "/storage.import.backingFile"
 	// AnnThumbprint provides a const for our PVC backing thumbprint annotation
 	AnnThumbprint = AnnAPIGroup + "/storage.import.vddk.thumbprint"
+	// AnnOVADisk provides a const for our PVC annotation selecting the disk to import from an OVA archive
+	AnnOVADisk = AnnAPIGroup + "/storage.import.ova.disk"
+	// AnnConcurrency provides a const for our PVC annotation setting the number of parallel ranged requests of a download
+	AnnConcurrency = AnnAPIGroup + "/storage.import.concurrency"
 
 	//LabelImportPvc is a pod label used to find the import pod that was created by the relevant PVC
 	LabelImportPvc = AnnAPIGroup + "/storage.import.importPvcName"
@@ -121,6 +125,10 @@ type importPodEnvVar struct {
 	currentCheckpoint  string
 	previousCheckpoint string
 	finalCheckpoint    string
+	preallocation      bool
+	checksum           string
+	ovaDisk            string
+	concurrency        string
 }
 
 // NewImportController creates a new instance of the import controller.
@@ -187,6 +195,13 @@ func isPVCComplete(pvc *corev1.PersistentVolumeClaim) bool {
 	return exists && (phase == string(corev1.PodSucceeded))
 }
 
+// isPVCChecksumMismatch returns true if the import failed because the data didn't match the expected checksum,
+// retrying would not help in that case.
+func isPVCChecksumMismatch(pvc *corev1.PersistentVolumeClaim) bool {
+	return pvc.ObjectMeta.Annotations[AnnPodPhase] == string(corev1.PodFailed) &&
+		pvc.ObjectMeta.Annotations[AnnRunningConditionReason] == ChecksumMismatch
+}
+
 // Reconcile the reconcile loop for the CDIConfig object.
 func (r *ImportReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
 	log := r.log.WithValues("PVC", req.NamespacedName)
@@ -214,9 +229,10 @@ func (r *ImportReconciler) Reconcile(req reconcile.Request) (reconcile.Result, e
 	}
 
 	// In case this is a request to create a blank disk on a block device, we do not create a pod.
-	// we just mark the DV as successful
+	// we just mark the DV as successful, unless the device has to be preallocated by the importer.
 	volumeMode := getVolumeMode(pvc)
-	if volumeMode == corev1.PersistentVolumeBlock && pvc.GetAnnotations()[AnnSource] == SourceNone {
+	if volumeMode == corev1.PersistentVolumeBlock && pvc.GetAnnotations()[AnnSource] == SourceNone &&
+		pvc.GetAnnotations()[AnnPreallocationRequested] != "true" {
 		log.V(1).Info("attempting to create blank disk for block mode, this is a no-op, marking pvc with pod-phase succeeded")
 		if pvc.GetAnnotations() == nil {
 			pvc.SetAnnotations(make(map[string]string, 0))
@@ -259,6 +275,9 @@ func (r *ImportReconciler) reconcilePvc(pvc *corev1.PersistentVolumeClaim, log l
 		if isPVCComplete(pvc) {
 			// Don't create the POD if the PVC is completed already
 			log.V(1).Info("PVC is already complete")
+		} else if isPVCChecksumMismatch(pvc) {
+			// Don't create the POD if the data is known not to match the checksum
+			log.V(1).Info("PVC import failed checksum verification")
 		} else if pvc.DeletionTimestamp == nil {
 			podsUsingPVC, err := getPodsUsingPVCs(r.client, pvc.Namespace, sets.NewString(pvc.Name), false)
 			if err != nil {
@@ -302,7 +321,7 @@ func (r *ImportReconciler) reconcilePvc(pvc *corev1.PersistentVolumeClaim, log l
 		}
 	}
 
-	if !isPVCComplete(pvc) {
+	if !isPVCComplete(pvc) && !isPVCChecksumMismatch(pvc) {
 		// We are not done yet, force a re-reconcile in 2 seconds to get an update.
 		log.V(1).Info("Force Reconcile pvc import not finished", "pvc.Name", pvc.Name)
 
@@ -350,17 +369,30 @@ func (r *ImportReconciler) updatePvcFromPod(pvc *corev1.PersistentVolumeClaim, p
 			log.V(1).Info("Pod requires scratch space, terminating pod, and restarting with scratch space", "pod.Name", pod.Name)
 			scratchExitCode = true
 			anno[AnnRequiresScratch] = "true"
-		} else {
+		} else if pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.ExitCode != common.ChecksumMismatchExitCode {
 			r.recorder.Event(pvc, corev1.EventTypeWarning, ErrImportFailedPVC, pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.Message)
 		}
 	}
 
+	checksumMismatch := false
+	if terminated := getTerminatedWithExitCode(pod, common.ChecksumMismatchExitCode); terminated != nil {
+		log.V(1).Info("Imported data does not match the checksum, failing the import", "pod.Name", pod.Name)
+		checksumMismatch = true
+		anno[AnnRunningCondition] = "false"
+		anno[AnnRunningConditionMessage] = terminated.Message
+		anno[AnnRunningConditionReason] = ChecksumMismatch
+		r.recorder.Event(pvc, corev1.EventTypeWarning, ChecksumMismatch, terminated.Message)
+	}
+
 	if pod.Status.ContainerStatuses != nil {
 		anno[AnnPodRestarts] = strconv.Itoa(int(pod.Status.ContainerStatuses[0].RestartCount))
 	}
 
 	anno[AnnImportPod] = string(pod.Name)
-	if !scratchExitCode {
+	if checksumMismatch {
+		// The pod would be restarted, but the data is not going to change, so fail the import.
+		anno[AnnPodPhase] = string(corev1.PodFailed)
+	} else if !scratchExitCode {
 		// No scratch exit code, update the phase based on the pod. If we do have scratch exit code we don't want to update the
 		// phase, because the pod might terminate cleanly and mistakenly mark the import complete.
 		anno[AnnPodPhase] = string(pod.Status.Phase)
@@ -394,8 +426,8 @@ func (r *ImportReconciler) updatePvcFromPod(pvc *corev1.PersistentVolumeClaim, p
 		log.V(1).Info("Updated PVC", "pvc.anno.Phase", anno[AnnPodPhase], "pvc.anno.Restarts", anno[AnnPodRestarts])
 	}
 
-	if isPVCComplete(pvc) || scratchExitCode {
-		if !scratchExitCode {
+	if isPVCComplete(pvc) || scratchExitCode || checksumMismatch {
+		if !scratchExitCode && !checksumMismatch {
 			r.recorder.Event(pvc, corev1.EventTypeNormal, ImportSucceededPVC, "Import Successful")
 			log.V(1).Info("Completed successfully, deleting POD", "pod.Name", pod.Name)
 		}
@@ -406,6 +438,20 @@ func (r *ImportReconciler) updatePvcFromPod(pvc *corev1.PersistentVolumeClaim, p
 	return nil
 }
 
+// getTerminatedWithExitCode returns the current or last termination state of the pod, if it exited with the passed in code
+func getTerminatedWithExitCode(pod *corev1.Pod, exitCode int32) *corev1.ContainerStateTerminated {
+	if pod.Status.ContainerStatuses == nil {
+		return nil
+	}
+	status := pod.Status.ContainerStatuses[0]
+	for _, terminated := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+		if terminated != nil && terminated.ExitCode == exitCode {
+			return terminated
+		}
+	}
+	return nil
+}
+
 func (r *ImportReconciler) updatePVC(pvc *corev1.PersistentVolumeClaim, log logr.Logger) error {
 	log.V(1).Info("Annotations are now", "pvc.anno", pvc.GetAnnotations())
 	if err := r.client.Update(context.TODO(), pvc); err != nil {
@@ -490,6 +536,10 @@ func (r *ImportReconciler) createImportEnvVar(pvc *corev1.PersistentVolumeClaim)
 		podEnvVar.currentCheckpoint = getValueFromAnnotation(pvc, AnnCurrentCheckpoint)
 		podEnvVar.finalCheckpoint = getValueFromAnnotation(pvc, AnnFinalCheckpoint)
 	}
+	podEnvVar.preallocation = getValueFromAnnotation(pvc, AnnPreallocationRequested) == "true"
+	podEnvVar.checksum = getValueFromAnnotation(pvc, AnnChecksum)
+	podEnvVar.ovaDisk = getValueFromAnnotation(pvc, AnnOVADisk)
+	podEnvVar.concurrency = getValueFromAnnotation(pvc, AnnConcurrency)
 	//get the requested image size.
 	podEnvVar.imageSize, err = getRequestedImageSize(pvc)
 	if err != nil {
@@ -499,14 +549,18 @@ func (r *ImportReconciler) createImportEnvVar(pvc *corev1.PersistentVolumeClaim)
 }
 
 func (r *ImportReconciler) isInsecureTLS(pvc *corev1.PersistentVolumeClaim) (bool, error) {
-	var configMapName string
-
 	value, ok := pvc.Annotations[AnnEndpoint]
 	if !ok || value == "" {
 		return false, nil
 	}
+	return isInsecureTLS(r.uncachedClient, r.log, value)
+}
+
+// isInsecureTLS checks whether the host of the endpoint is listed in the insecure registries config map
+func isInsecureTLS(c client.Client, log logr.Logger, endpoint string) (bool, error) {
+	var configMapName string
 
-	url, err := url.Parse(value)
+	url, err := url.Parse(endpoint)
 	if err != nil {
 		return false, err
 	}
@@ -518,19 +572,19 @@ func (r *ImportReconciler) isInsecureTLS(pvc *corev1.PersistentVolumeClaim) (boo
 		return false, nil
 	}
 
-	r.log.V(1).Info("Checking configmap for host", "configMapName", configMapName, "host URL", url.Host)
+	log.V(1).Info("Checking configmap for host", "configMapName", configMapName, "host URL", url.Host)
 
 	cm := &corev1.ConfigMap{}
-	if err := r.uncachedClient.Get(context.TODO(), types.NamespacedName{Name: configMapName, Namespace: util.GetNamespace()}, cm); err != nil {
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: configMapName, Namespace: util.GetNamespace()}, cm); err != nil {
 		if k8serrors.IsNotFound(err) {
-			r.log.V(1).Info("Configmap does not exist", "configMapName", configMapName)
+			log.V(1).Info("Configmap does not exist", "configMapName", configMapName)
 			return false, nil
 		}
 		return false, err
 	}
 
 	for key, value := range cm.Data {
-		r.log.V(1).Info("Checking host against key, value pair", "host", url.Host, "Key", key, "Value", value)
+		log.V(1).Info("Checking host against key, value pair", "host", url.Host, "Key", key, "Value", value)
 
 		if value == url.Host {
 			return true, nil
@@ -966,6 +1020,34 @@ func makeImportEnv(podEnvVar *importPodEnvVar, uid types.UID) []corev1.EnvVar {
 			Name:  common.ImporterThumbprint,
 			Value: podEnvVar.thumbprint,
 		},
+		{
+			Name:  common.ImporterCurrentCheckpoint,
+			Value: podEnvVar.currentCheckpoint,
+		},
+		{
+			Name:  common.ImporterPreviousCheckpoint,
+			Value: podEnvVar.previousCheckpoint,
+		},
+		{
+			Name:  common.ImporterFinalCheckpoint,
+			Value: podEnvVar.finalCheckpoint,
+		},
+		{
+			Name:  common.Preallocation,
+			Value: strconv.FormatBool(podEnvVar.preallocation),
+		},
+		{
+			Name:  common.Checksum,
+			Value: podEnvVar.checksum,
+		},
+		{
+			Name:  common.ImporterOVADisk,
+			Value: podEnvVar.ovaDisk,
+		},
+		{
+			Name:  common.ImporterConcurrency,
+			Value: podEnvVar.concurrency,
+		},
 	}
 	if podEnvVar.secretName != "" {
 		env = append(env, corev1.EnvVar{
@@ -134,6 +134,16 @@ var _ = Describe("ImportConfig Controller reconcile loop", func() {
 		Expect(resultPvc.GetAnnotations()[AnnPodPhase]).To(BeEquivalentTo(corev1.PodSucceeded))
 	})
 
+	It("Should not mark a block PVC with source none complete, if preallocation is requested", func() {
+		reconciler = createImportReconciler(createBlockPvc("testPvc1", "block", map[string]string{AnnSource: SourceNone, AnnPreallocationRequested: "true"}, nil))
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "block"}})
+		Expect(err).ToNot(HaveOccurred())
+		resultPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "block"}, resultPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resultPvc.GetAnnotations()[AnnPodPhase]).ToNot(BeEquivalentTo(corev1.PodSucceeded))
+	})
+
 	It("should do nothing and not error, if a PVC that is completed is passed", func() {
 		orgPvc := createPvc("testPvc1", "default", map[string]string{AnnEndpoint: testEndPoint, AnnPodPhase: string(corev1.PodSucceeded)}, nil)
 		orgPvc.TypeMeta.APIVersion = "v1"
@@ -554,6 +564,47 @@ var _ = Describe("Update PVC from POD", func() {
 		Expect(resPvc.GetAnnotations()[AnnRunningConditionMessage]).To(Equal("I went poof"))
 		Expect(resPvc.GetAnnotations()[AnnRunningConditionReason]).To(Equal("Explosion"))
 	})
+
+	It("Should fail the import and delete the pod, if pod exited with checksum mismatch exit", func() {
+		pvc := createPvc("testPvc1", "default", map[string]string{AnnEndpoint: testEndPoint, AnnPodPhase: string(corev1.PodRunning), AnnChecksum: "sha256:abc"}, nil)
+		pod := createImporterTestPod(pvc, "testPvc1", nil)
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: common.ChecksumMismatchExitCode,
+							Message:  "Unable to process data: checksum mismatch",
+						},
+					},
+					State: v1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+				},
+			},
+		}
+		reconciler = createImportReconciler(pvc, pod)
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.GetAnnotations()[AnnPodPhase]).To(BeEquivalentTo(corev1.PodFailed))
+		Expect(resPvc.GetAnnotations()[AnnRunningCondition]).To(Equal("false"))
+		Expect(resPvc.GetAnnotations()[AnnRunningConditionMessage]).To(Equal("Unable to process data: checksum mismatch"))
+		Expect(resPvc.GetAnnotations()[AnnRunningConditionReason]).To(Equal(ChecksumMismatch))
+		By("Checking the pod was deleted")
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: "default"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		By("Checking the pod is not recreated")
+		result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		podList := &corev1.PodList{}
+		Expect(reconciler.client.List(context.TODO(), podList, &client.ListOptions{})).To(Succeed())
+		Expect(podList.Items).To(BeEmpty())
+	})
 })
 
 var _ = Describe("Create Importer Pod", func() {
@@ -614,7 +665,7 @@ var _ = Describe("Import test env", func() {
 	const mockUID = "1111-1111-1111-1111"
 
 	It("Should create import env", func() {
-		testEnvVar := &importPodEnvVar{"myendpoint", "mysecret", SourceHTTP, string(cdiv1.DataVolumeKubeVirt), "1G", "", "", "", "", "", "0.055", false, "", "", ""}
+		testEnvVar := &importPodEnvVar{"myendpoint", "mysecret", SourceHTTP, string(cdiv1.DataVolumeKubeVirt), "1G", "", "", "", "", "", "0.055", false, "", "", "", false, "sha256:abc", "1", "4"}
 		Expect(reflect.DeepEqual(makeImportEnv(testEnvVar, mockUID), createImportTestEnv(testEnvVar, mockUID))).To(BeTrue())
 	})
 })
@@ -856,6 +907,34 @@ func createImportTestEnv(podEnvVar *importPodEnvVar, uid string) []corev1.EnvVar
 			Name:  common.ImporterThumbprint,
 			Value: podEnvVar.thumbprint,
 		},
+		{
+			Name:  common.ImporterCurrentCheckpoint,
+			Value: podEnvVar.currentCheckpoint,
+		},
+		{
+			Name:  common.ImporterPreviousCheckpoint,
+			Value: podEnvVar.previousCheckpoint,
+		},
+		{
+			Name:  common.ImporterFinalCheckpoint,
+			Value: podEnvVar.finalCheckpoint,
+		},
+		{
+			Name:  common.Preallocation,
+			Value: strconv.FormatBool(podEnvVar.preallocation),
+		},
+		{
+			Name:  common.Checksum,
+			Value: podEnvVar.checksum,
+		},
+		{
+			Name:  common.ImporterOVADisk,
+			Value: podEnvVar.ovaDisk,
+		},
+		{
+			Name:  common.ImporterConcurrency,
+			Value: podEnvVar.concurrency,
+		},
 	}
 
 	if podEnvVar.secretName != "" {
@@ -170,6 +170,8 @@ func (r *SmartCloneReconciler) reconcilePvc(log logr.Logger, pvc *corev1.Persist
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: snapshotName, Namespace: pvc.Namespace}, datavolume); err != nil {
 		return reconcile.Result{}, err
 	}
+	populateSourceRefPVC(datavolume)
+	populateStoragePVC(datavolume)
 
 	// Update DV phase and emit PVC in progress event
 	if err := r.updateSmartCloneStatusPhase(cdiv1.Succeeded, datavolume, pvc); err != nil {
@@ -207,6 +209,8 @@ func (r *SmartCloneReconciler) reconcileSnapshot(log logr.Logger, snapshot *snap
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: snapshot.Name, Namespace: snapshot.Namespace}, datavolume); err != nil {
 		return reconcile.Result{}, err
 	}
+	populateSourceRefPVC(datavolume)
+	populateStoragePVC(datavolume)
 
 	// Update DV phase and emit PVC in progress event
 	if err := r.updateSmartCloneStatusPhase(SmartClonePVCInProgress, datavolume, nil); err != nil {
@@ -258,6 +262,8 @@ func (r *SmartCloneReconciler) updateSmartCloneStatusPhase(phase cdiv1.DataVolum
 func (r *SmartCloneReconciler) emitEvent(dataVolume *cdiv1.DataVolume, dataVolumeCopy *cdiv1.DataVolume, event *DataVolumeEvent, newPVC *corev1.PersistentVolumeClaim) error {
 	// Only update the object if something actually changed in the status.
 	if !reflect.DeepEqual(dataVolume.Status, dataVolumeCopy.Status) {
+		clearSourceRefPVC(dataVolumeCopy)
+		clearStoragePVC(dataVolumeCopy)
 		if err := r.client.Update(context.TODO(), dataVolumeCopy); err == nil {
 			// Emit the event only when the status change happens, not every time
 			if event.eventType != "" {
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+)
+
+var (
+	blockMode      = v1.PersistentVolumeBlock
+	filesystemMode = v1.PersistentVolumeFilesystem
+
+	rwxBlock = []cdiv1.ClaimPropertySet{{
+		AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteMany},
+		VolumeMode:  &blockMode,
+	}}
+	rwxFilesystem = []cdiv1.ClaimPropertySet{{
+		AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteMany},
+		VolumeMode:  &filesystemMode,
+	}}
+	rwoBlock = []cdiv1.ClaimPropertySet{{
+		AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+		VolumeMode:  &blockMode,
+	}}
+	rwoFilesystem = []cdiv1.ClaimPropertySet{{
+		AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+		VolumeMode:  &filesystemMode,
+	}}
+)
+
+// storageCapabilities holds the recommended claim properties of known provisioners, used when the
+// StorageProfile spec doesn't provide any
+var storageCapabilities = map[string][]cdiv1.ClaimPropertySet{
+	// ceph-rbd
+	"rbd.csi.ceph.com":                   rwxBlock,
+	"rook-ceph.rbd.csi.ceph.com":         rwxBlock,
+	"openshift-storage.rbd.csi.ceph.com": rwxBlock,
+	"kubernetes.io/rbd":                  rwoBlock,
+	// ceph-fs
+	"cephfs.csi.ceph.com":                   rwxFilesystem,
+	"rook-ceph.cephfs.csi.ceph.com":         rwxFilesystem,
+	"openshift-storage.cephfs.csi.ceph.com": rwxFilesystem,
+	// cloud block storage
+	"kubernetes.io/aws-ebs":    rwoBlock,
+	"ebs.csi.aws.com":          rwoBlock,
+	"kubernetes.io/gce-pd":     rwoBlock,
+	"pd.csi.storage.gke.io":    rwoBlock,
+	"kubernetes.io/azure-disk": rwoBlock,
+	"disk.csi.azure.com":       rwoBlock,
+	"kubernetes.io/cinder":     rwoBlock,
+	"cinder.csi.openstack.org": rwoBlock,
+	// local storage
+	"kubernetes.io/host-path":          rwoFilesystem,
+	"kubevirt.io/hostpath-provisioner": rwoFilesystem,
+	"kubernetes.io/no-provisioner":     rwoFilesystem,
+	"rancher.io/local-path":            rwoFilesystem,
+}
+
+// reconcileStorageProfiles makes sure every StorageClass has a StorageProfile with an up to date status
+func (r *CDIConfigReconciler) reconcileStorageProfiles() error {
+	storageClassList := &storagev1.StorageClassList{}
+	if err := r.client.List(context.TODO(), storageClassList, &client.ListOptions{}); err != nil {
+		return err
+	}
+	for i := range storageClassList.Items {
+		if err := r.reconcileStorageProfile(&storageClassList.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *CDIConfigReconciler) reconcileStorageProfile(storageClass *storagev1.StorageClass) error {
+	log := r.log.WithName("CDIconfig").WithName("StorageProfileReconcile")
+	storageProfile := &cdiv1.StorageProfile{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: storageClass.Name}, storageProfile); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		storageProfile = newStorageProfile(storageClass)
+		log.Info("Creating StorageProfile", "StorageProfile.Name", storageProfile.Name)
+		if err := r.client.Create(context.TODO(), storageProfile); err != nil {
+			return err
+		}
+	}
+	storageProfileCopy := storageProfile.DeepCopy()
+
+	storageProfile.Status.StorageClass = &storageClass.Name
+	storageProfile.Status.Provisioner = &storageClass.Provisioner
+	if len(storageProfile.Spec.ClaimPropertySets) > 0 {
+		storageProfile.Status.ClaimPropertySets = storageProfile.Spec.ClaimPropertySets
+	} else {
+		storageProfile.Status.ClaimPropertySets = storageCapabilities[storageClass.Provisioner]
+	}
+
+	if !reflect.DeepEqual(storageProfileCopy, storageProfile) {
+		log.Info("Updating StorageProfile", "StorageProfile.Name", storageProfile.Name)
+		return r.client.Update(context.TODO(), storageProfile)
+	}
+	return nil
+}
+
+func newStorageProfile(storageClass *storagev1.StorageClass) *cdiv1.StorageProfile {
+	return &cdiv1.StorageProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: storageClass.Name,
+			Labels: map[string]string{
+				common.CDILabelKey: common.CDILabelValue,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "storage.k8s.io/v1",
+					Kind:       "StorageClass",
+					Name:       storageClass.Name,
+					UID:        storageClass.UID,
+				},
+			},
+		},
+	}
+}
+
+// getClaimPropertySet returns the claim properties recommended by the StorageProfile of the storage class,
+// the default storage class is used if no name is given. It returns nil if there is no recommendation.
+func getClaimPropertySet(c client.Client, storageClassName *string) (*cdiv1.ClaimPropertySet, error) {
+	if storageClassName == nil {
+		storageClass, err := GetStorageClassByName(c, nil)
+		if err != nil || storageClass == nil {
+			return nil, err
+		}
+		storageClassName = &storageClass.Name
+	}
+	storageProfile := &cdiv1.StorageProfile{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: *storageClassName}, storageProfile); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(storageProfile.Status.ClaimPropertySets) == 0 {
+		return nil, nil
+	}
+	return &storageProfile.Status.ClaimPropertySets[0], nil
+}
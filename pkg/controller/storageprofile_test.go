@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+)
+
+var _ = Describe("Controller storage profile reconcile loop", func() {
+	getStorageProfile := func(reconciler *CDIConfigReconciler, name string) *cdiv1.StorageProfile {
+		storageProfile := &cdiv1.StorageProfile{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: name}, storageProfile)
+		Expect(err).ToNot(HaveOccurred())
+		return storageProfile
+	}
+
+	It("Should create a StorageProfile with the recommended defaults for each storage class", func() {
+		reconciler, _ := createConfigReconciler(
+			createStorageClassWithProvisioner("ceph-sc", nil, "rook-ceph.rbd.csi.ceph.com"),
+			createStorageClassWithProvisioner("unknown-sc", nil, "example.com/unknown"),
+		)
+		Expect(reconciler.reconcileStorageProfiles()).To(Succeed())
+
+		storageProfile := getStorageProfile(reconciler, "ceph-sc")
+		Expect(*storageProfile.Status.StorageClass).To(Equal("ceph-sc"))
+		Expect(*storageProfile.Status.Provisioner).To(Equal("rook-ceph.rbd.csi.ceph.com"))
+		Expect(storageProfile.Status.ClaimPropertySets).To(HaveLen(1))
+		Expect(storageProfile.Status.ClaimPropertySets[0].AccessModes).To(ConsistOf(corev1.ReadWriteMany))
+		Expect(*storageProfile.Status.ClaimPropertySets[0].VolumeMode).To(Equal(corev1.PersistentVolumeBlock))
+		Expect(storageProfile.OwnerReferences).To(HaveLen(1))
+		Expect(storageProfile.OwnerReferences[0].Kind).To(Equal("StorageClass"))
+
+		storageProfile = getStorageProfile(reconciler, "unknown-sc")
+		Expect(*storageProfile.Status.Provisioner).To(Equal("example.com/unknown"))
+		Expect(storageProfile.Status.ClaimPropertySets).To(BeEmpty())
+	})
+
+	It("Should prefer the claim property sets provided in the spec", func() {
+		reconciler, _ := createConfigReconciler(createStorageClassWithProvisioner("ceph-sc", nil, "rook-ceph.rbd.csi.ceph.com"))
+		Expect(reconciler.reconcileStorageProfiles()).To(Succeed())
+
+		storageProfile := getStorageProfile(reconciler, "ceph-sc")
+		storageProfile.Spec.ClaimPropertySets = []cdiv1.ClaimPropertySet{
+			{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, VolumeMode: &filesystemMode},
+		}
+		Expect(reconciler.client.Update(context.TODO(), storageProfile)).To(Succeed())
+		Expect(reconciler.reconcileStorageProfiles()).To(Succeed())
+
+		storageProfile = getStorageProfile(reconciler, "ceph-sc")
+		Expect(storageProfile.Status.ClaimPropertySets).To(Equal(storageProfile.Spec.ClaimPropertySets))
+	})
+
+	It("Should create StorageProfiles on reconcile", func() {
+		reconciler, cdiConfig := createConfigReconciler(createStorageClassWithProvisioner("test-sc", nil, "kubernetes.io/aws-ebs"))
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: cdiConfig.Name}})
+		Expect(err).ToNot(HaveOccurred())
+		storageProfile := getStorageProfile(reconciler, "test-sc")
+		Expect(storageProfile.Status.ClaimPropertySets).To(Equal(rwoBlock))
+	})
+})
+
+var _ = Describe("Rendering the PVC spec of a DataVolume", func() {
+	newStorageProfile := func(name string, claimPropertySets []cdiv1.ClaimPropertySet) *cdiv1.StorageProfile {
+		return &cdiv1.StorageProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     cdiv1.StorageProfileStatus{ClaimPropertySets: claimPropertySets},
+		}
+	}
+
+	It("Should fill in missing accessModes and volumeMode from the StorageProfile", func() {
+		storageClassName := "test-sc"
+		reconciler := createDatavolumeReconciler(newStorageProfile(storageClassName, rwxBlock))
+		defer close(reconciler.recorder.(*record.FakeRecorder).Events)
+		dv := newImportDataVolume("test-dv")
+		dv.Spec.PVC.StorageClassName = &storageClassName
+
+		pvc, err := newPersistentVolumeClaim(reconciler.client, dv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteMany))
+		Expect(*pvc.Spec.VolumeMode).To(Equal(corev1.PersistentVolumeBlock))
+		// the DataVolume spec itself is left untouched
+		Expect(dv.Spec.PVC.AccessModes).To(BeEmpty())
+		Expect(dv.Spec.PVC.VolumeMode).To(BeNil())
+	})
+
+	It("Should use the StorageProfile of the default storage class", func() {
+		reconciler := createDatavolumeReconciler(
+			createStorageClass("default-sc", map[string]string{AnnDefaultStorageClass: "true"}),
+			newStorageProfile("default-sc", rwoFilesystem),
+		)
+		defer close(reconciler.recorder.(*record.FakeRecorder).Events)
+
+		pvc, err := newPersistentVolumeClaim(reconciler.client, newImportDataVolume("test-dv"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+		Expect(*pvc.Spec.VolumeMode).To(Equal(corev1.PersistentVolumeFilesystem))
+	})
+
+	It("Should keep accessModes and volumeMode set in the DataVolume", func() {
+		storageClassName := "test-sc"
+		reconciler := createDatavolumeReconciler(newStorageProfile(storageClassName, rwxBlock))
+		defer close(reconciler.recorder.(*record.FakeRecorder).Events)
+		dv := newImportDataVolume("test-dv")
+		dv.Spec.PVC.StorageClassName = &storageClassName
+		dv.Spec.PVC.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+		dv.Spec.PVC.VolumeMode = &filesystemMode
+
+		pvc, err := newPersistentVolumeClaim(reconciler.client, dv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+		Expect(*pvc.Spec.VolumeMode).To(Equal(corev1.PersistentVolumeFilesystem))
+	})
+
+	It("Should leave the spec alone without a StorageProfile", func() {
+		reconciler := createDatavolumeReconciler()
+		defer close(reconciler.recorder.(*record.FakeRecorder).Events)
+
+		pvc, err := newPersistentVolumeClaim(reconciler.client, newImportDataVolume("test-dv"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Spec.AccessModes).To(BeEmpty())
+		Expect(pvc.Spec.VolumeMode).To(BeNil())
+	})
+})
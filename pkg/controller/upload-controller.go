@@ -95,6 +95,8 @@ type UploadPodArgs struct {
 	ScratchPVCName                  string
 	ClientName                      string
 	FilesystemOverhead              string
+	Preallocation                   string
+	Checksum                        string
 	ServerCert, ServerKey, ClientCA []byte
 }
 
@@ -279,9 +281,6 @@ func (r *UploadReconciler) updatePVC(pvc *corev1.PersistentVolumeClaim) error {
 }
 
 func (r *UploadReconciler) getCloneRequestSourcePVC(targetPvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
-	sourceVolumeMode := corev1.PersistentVolumeFilesystem
-	targetVolumeMode := corev1.PersistentVolumeFilesystem
-
 	exists, namespace, name := ParseCloneRequestAnnotation(targetPvc)
 	if !exists {
 		return nil, errors.New("error parsing clone request annotation")
@@ -290,15 +289,6 @@ func (r *UploadReconciler) getCloneRequestSourcePVC(targetPvc *corev1.Persistent
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, sourcePvc); err != nil {
 		return nil, errors.Wrap(err, "error getting clone source PVC")
 	}
-	if sourcePvc.Spec.VolumeMode != nil {
-		sourceVolumeMode = *sourcePvc.Spec.VolumeMode
-	}
-	if targetPvc.Spec.VolumeMode != nil {
-		targetVolumeMode = *targetPvc.Spec.VolumeMode
-	}
-	if sourceVolumeMode != targetVolumeMode {
-		return nil, errors.New("Source and target volume Modes do not match")
-	}
 	return sourcePvc, nil
 }
 
@@ -367,6 +357,8 @@ func (r *UploadReconciler) createUploadPodForPvc(pvc *v1.PersistentVolumeClaim,
 		ScratchPVCName:     scratchPVCName,
 		ClientName:         clientName,
 		FilesystemOverhead: string(fsOverhead),
+		Preallocation:      strconv.FormatBool(getValueFromAnnotation(pvc, AnnPreallocationRequested) == "true"),
+		Checksum:           getValueFromAnnotation(pvc, AnnChecksum),
 		ServerCert:         serverCert,
 		ServerKey:          serverKey,
 		ClientCA:           clientCA,
@@ -683,6 +675,14 @@ func (r *UploadReconciler) makeUploadPodSpec(args UploadPodArgs, resourceRequire
 							Name:  common.FilesystemOverheadVar,
 							Value: args.FilesystemOverhead,
 						},
+						{
+							Name:  common.Preallocation,
+							Value: args.Preallocation,
+						},
+						{
+							Name:  common.Checksum,
+							Value: args.Checksum,
+						},
 						{
 							Name:  common.UploadImageSize,
 							Value: requestImageSize,
@@ -215,17 +215,19 @@ var _ = Describe("Upload controller reconcile loop", func() {
 
 	})
 
-	It("Should return err and not clone if validation error occurs", func() {
+	It("Should create a pod when the clone source and target volume modes differ", func() {
 		storageClassName := "test"
-		testPvc := createPvcInStorageClass("testPvc1", "default", &storageClassName, map[string]string{cloneRequestAnnotation: "default/sourcePvc"}, nil, corev1.ClaimBound)
+		testPvc := createPvcInStorageClass("testPvc1", "default", &storageClassName, map[string]string{AnnCloneRequest: "default/sourcePvc", AnnUploadPod: createUploadResourceName("testPvc1")}, nil, corev1.ClaimBound)
 		sourcePvc := createPvcInStorageClass("sourcePvc", "default", &storageClassName, nil, nil, corev1.ClaimBound)
 		vm := corev1.PersistentVolumeBlock
 		sourcePvc.Spec.VolumeMode = &vm
 		reconciler := createUploadReconciler(testPvc, sourcePvc)
 
 		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
-		Expect(err).To(HaveOccurred())
-		Expect(err.Error()).To(ContainSubstring("Source and target volume Modes do not match"))
+		Expect(err).ToNot(HaveOccurred())
+		uploadPod := &corev1.Pod{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: createUploadResourceName("testPvc1"), Namespace: "default"}, uploadPod)
+		Expect(err).ToNot(HaveOccurred())
 	})
 
 	It("Should return nil and create a pod and service when a clone pvc", func() {
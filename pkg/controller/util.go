@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rsa"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -22,6 +23,7 @@ import (
 	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
 	cdiv1utils "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1/utils"
 	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert"
 	"kubevirt.io/containerized-data-importer/pkg/util/naming"
 	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
@@ -57,6 +59,25 @@ const (
 	AnnPopulatedFor = AnnAPIGroup + "/storage.populatedFor"
 	// AnnPrePopulated is a PVC annotation telling the datavolume controller that the PVC is already populated
 	AnnPrePopulated = AnnAPIGroup + "/storage.prePopulated"
+	// AnnAllocatedBytes is a PVC annotation reporting how many bytes the populating pod allocated on the volume
+	AnnAllocatedBytes = AnnAPIGroup + "/storage.allocatedBytes"
+	// AnnPreallocationRequested provides a const for PVC preallocation request
+	AnnPreallocationRequested = AnnAPIGroup + "/storage.preallocation.requested"
+	// AnnPreallocationApplied provides a const for PVC preallocation annotation
+	AnnPreallocationApplied = AnnAPIGroup + "/storage.preallocation"
+	// AnnChecksum provides a const for the checksum the data populating the PVC is expected to match
+	AnnChecksum = AnnAPIGroup + "/storage.checksum"
+	// AnnSourceRefPVC is a DataVolume annotation recording the namespace/name of the PVC its sourceRef resolved to
+	AnnSourceRefPVC = AnnAPIGroup + "/storage.sourceRef.pvc"
+	// AnnSnapshotRestore is a PVC annotation with the namespace/name of the DataVolume snapshot source it is restored from
+	AnnSnapshotRestore = AnnAPIGroup + "/storage.snapshot.restore"
+	// AnnSnapshotRestoreFor is an annotation of the temporary PVC restored from a snapshot, with the namespace/name of
+	// the DataVolume cloning it
+	AnnSnapshotRestoreFor = AnnAPIGroup + "/storage.snapshot.restoreFor"
+	// AnnSourceDesiredDigest is a DataImportCron annotation with the source digest found by the last poll
+	AnnSourceDesiredDigest = AnnAPIGroup + "/storage.import.sourceDesiredDigest"
+	// AnnImportDigest is a DataVolume annotation with the source digest imported by its DataImportCron
+	AnnImportDigest = AnnAPIGroup + "/storage.import.sourceDigest"
 
 	// AnnPreviousCheckpoint provides a const to indicate the previous snapshot for a multistage import
 	AnnPreviousCheckpoint = AnnAPIGroup + "/storage.checkpoint.previous"
@@ -88,6 +109,9 @@ const (
 
 	// PodRunningReason is const that defines the pod was started as a reason
 	podRunningReason = "Pod is running"
+
+	// ChecksumMismatch provides a const to indicate the data did not match the expected checksum
+	ChecksumMismatch = "ChecksumMismatch"
 )
 
 const (
@@ -306,6 +330,84 @@ func GetFilesystemOverhead(client client.Client, pvc *v1.PersistentVolumeClaim)
 	return cdiConfig.Status.FilesystemOverhead.Global, nil
 }
 
+// GetPreallocation determines whether the storage of a DataVolume should be preallocated, the DataVolume
+// setting takes precedence over the default in CDIConfig.
+func GetPreallocation(client client.Client, dataVolume *cdiv1.DataVolume) bool {
+	if dataVolume.Spec.Preallocation != nil {
+		return *dataVolume.Spec.Preallocation
+	}
+
+	cdiConfig := &cdiv1.CDIConfig{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig); err != nil {
+		if k8serrors.IsNotFound(err) {
+			klog.V(1).Info("CDIConfig does not exist, not preallocating")
+		} else {
+			klog.Errorf("Unable to get CDIConfig, not preallocating: %v", err)
+		}
+		return false
+	}
+	return cdiConfig.Status.Preallocation
+}
+
+// GetCloneCodec determines the codec compressing the stream of a host-assisted clone, the DataVolume setting takes
+// precedence over the default in CDIConfig.
+func GetCloneCodec(client client.Client, dataVolume *cdiv1.DataVolume) cdiv1.CloneCodec {
+	if dataVolume.Spec.CloneCodec != nil {
+		return *dataVolume.Spec.CloneCodec
+	}
+
+	cdiConfig := &cdiv1.CDIConfig{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig); err != nil {
+		if k8serrors.IsNotFound(err) {
+			klog.V(1).Info("CDIConfig does not exist, using the default clone codec")
+		} else {
+			klog.Errorf("Unable to get CDIConfig, using the default clone codec: %v", err)
+		}
+	} else if cdiConfig.Status.CloneCodec != nil {
+		return *cdiConfig.Status.CloneCodec
+	}
+	return cdiv1.CloneCodec{Name: cdiv1.CloneCodecSnappy}
+}
+
+// setCloneCodecAnnotations records the clone codec of a DataVolume on its target PVC, for the clone source pod
+func setCloneCodecAnnotations(client client.Client, dataVolume *cdiv1.DataVolume, annotations map[string]string) {
+	codec := GetCloneCodec(client, dataVolume)
+	annotations[AnnCloneCodec] = string(codec.Name)
+	if codec.Level != nil {
+		annotations[AnnCloneCodecLevel] = strconv.Itoa(int(*codec.Level))
+	}
+}
+
+// GetDownloadConcurrency determines the number of parallel ranged requests an http or S3 DataVolume uses to download
+// its source, as requested by the DataVolume and capped by CDIConfig.
+func GetDownloadConcurrency(client client.Client, dataVolume *cdiv1.DataVolume) int32 {
+	var requested *int32
+	if dataVolume.Spec.Source.HTTP != nil {
+		requested = dataVolume.Spec.Source.HTTP.Concurrency
+	} else if dataVolume.Spec.Source.S3 != nil {
+		requested = dataVolume.Spec.Source.S3.Concurrency
+	}
+	if requested == nil || *requested <= 1 {
+		return 1
+	}
+
+	max := int32(common.DefaultMaxDownloadConcurrency)
+	cdiConfig := &cdiv1.CDIConfig{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig); err != nil {
+		if k8serrors.IsNotFound(err) {
+			klog.V(1).Info("CDIConfig does not exist, using the default maximum download concurrency")
+		} else {
+			klog.Errorf("Unable to get CDIConfig, using the default maximum download concurrency: %v", err)
+		}
+	} else if cdiConfig.Status.MaxDownloadConcurrency > 0 {
+		max = cdiConfig.Status.MaxDownloadConcurrency
+	}
+	if *requested > max {
+		return max
+	}
+	return *requested
+}
+
 // GetScratchPvcStorageClass tries to determine which storage class to use for use with a scratch persistent
 // volume claim. The order of preference is the following:
 // 1. Defined value in CDI Config field scratchSpaceStorageClass.
@@ -475,7 +577,14 @@ func setConditionFromPodWithPrefix(anno map[string]string, prefix string, pod *v
 				anno[prefix+".message"] = pod.Status.ContainerStatuses[0].State.Waiting.Message
 				anno[prefix+".reason"] = pod.Status.ContainerStatuses[0].State.Waiting.Reason
 			} else if pod.Status.ContainerStatuses[0].State.Terminated != nil {
-				anno[prefix+".message"] = pod.Status.ContainerStatuses[0].State.Terminated.Message
+				termMsg := util.ParseTerminationMessage(pod.Status.ContainerStatuses[0].State.Terminated.Message)
+				anno[prefix+".message"] = termMsg.Message
+				if termMsg.AllocatedBytes != nil {
+					anno[AnnAllocatedBytes] = strconv.FormatInt(*termMsg.AllocatedBytes, 10)
+				}
+				if termMsg.PreallocationApplied {
+					anno[AnnPreallocationApplied] = "true"
+				}
 				anno[prefix+".reason"] = pod.Status.ContainerStatuses[0].State.Terminated.Reason
 			}
 		}
@@ -616,3 +725,67 @@ func SetPodPvcAnnotations(pod *v1.Pod, pvc *v1.PersistentVolumeClaim) {
 		}
 	}
 }
+
+// getSourceRefPVC returns the PVC the sourceRef of the DataVolume was resolved to, nil if it was not resolved yet
+func getSourceRefPVC(dv *cdiv1.DataVolume) *cdiv1.DataVolumeSourcePVC {
+	if dv.Spec.SourceRef == nil {
+		return nil
+	}
+	ref, ok := dv.Annotations[AnnSourceRefPVC]
+	if !ok {
+		return nil
+	}
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	return &cdiv1.DataVolumeSourcePVC{Namespace: parts[0], Name: parts[1]}
+}
+
+// populateSourceRefPVC sets the source of the DataVolume to the PVC its sourceRef was resolved to
+func populateSourceRefPVC(dv *cdiv1.DataVolume) {
+	if pvcSource := getSourceRefPVC(dv); pvcSource != nil {
+		dv.Spec.Source.PVC = pvcSource
+	}
+}
+
+// StorageSpecToPvcSpec returns the PVC spec matching the storage spec of a DataVolume
+func StorageSpecToPvcSpec(storage *cdiv1.StorageSpec) *v1.PersistentVolumeClaimSpec {
+	return &v1.PersistentVolumeClaimSpec{
+		AccessModes:      storage.AccessModes,
+		Selector:         storage.Selector,
+		Resources:        *storage.Resources.DeepCopy(),
+		VolumeName:       storage.VolumeName,
+		StorageClassName: storage.StorageClassName,
+		VolumeMode:       storage.VolumeMode,
+	}
+}
+
+// populateStoragePVC sets the PVC spec of the DataVolume from its storage spec, requesting the inferred size if the
+// storage spec doesn't request one
+func populateStoragePVC(dv *cdiv1.DataVolume) {
+	if dv.Spec.Storage == nil || dv.Spec.PVC != nil {
+		return
+	}
+	dv.Spec.PVC = StorageSpecToPvcSpec(dv.Spec.Storage)
+	if _, ok := dv.Spec.PVC.Resources.Requests[v1.ResourceStorage]; !ok && dv.Status.InferredSize != nil {
+		if dv.Spec.PVC.Resources.Requests == nil {
+			dv.Spec.PVC.Resources.Requests = v1.ResourceList{}
+		}
+		dv.Spec.PVC.Resources.Requests[v1.ResourceStorage] = *dv.Status.InferredSize
+	}
+}
+
+// clearStoragePVC removes the PVC spec populated from the storage spec of the DataVolume, it is not part of the stored spec
+func clearStoragePVC(dv *cdiv1.DataVolume) {
+	if dv.Spec.Storage != nil {
+		dv.Spec.PVC = nil
+	}
+}
+
+// clearSourceRefPVC removes the source populated from the sourceRef of the DataVolume, it is not part of the stored spec
+func clearSourceRefPVC(dv *cdiv1.DataVolume) {
+	if dv.Spec.SourceRef != nil {
+		dv.Spec.Source.PVC = nil
+	}
+}
@@ -233,6 +233,48 @@ var _ = Describe("setConditionFromPod", func() {
 		Expect(result[AnnRunningConditionReason]).To(Equal("Completed"))
 	})
 
+	It("Should follow pod container status, completed with allocated bytes", func() {
+		result := make(map[string]string)
+		testPod := createImporterTestPod(createPvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: `{"message":"Import Complete","allocatedBytes":4096}`,
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setConditionFromPodWithPrefix(result, AnnRunningCondition, testPod)
+		Expect(result[AnnRunningCondition]).To(Equal("false"))
+		Expect(result[AnnRunningConditionMessage]).To(Equal("Import Complete"))
+		Expect(result[AnnAllocatedBytes]).To(Equal("4096"))
+		Expect(result).ToNot(HaveKey(AnnPreallocationApplied))
+	})
+
+	It("Should follow pod container status, completed with preallocation", func() {
+		result := make(map[string]string)
+		testPod := createImporterTestPod(createPvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: `{"message":"Import Complete","preallocationApplied":true}`,
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setConditionFromPodWithPrefix(result, AnnRunningCondition, testPod)
+		Expect(result[AnnRunningConditionMessage]).To(Equal("Import Complete"))
+		Expect(result[AnnPreallocationApplied]).To(Equal("true"))
+	})
+
 	It("Should follow pod container status, pending", func() {
 		result := make(map[string]string)
 		testPod := createImporterTestPod(createPvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
@@ -0,0 +1,302 @@
+/*
+ * This file is part of the CDI project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package exportserver
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+)
+
+const (
+	healthzPort = 8080
+	healthzPath = "/healthz"
+
+	formatRaw   = "raw"
+	formatGzip  = "gzip"
+	formatQcow2 = "qcow2"
+)
+
+// converters produce the exported image of a format from the raw source, they are replaced in tests
+var converters = map[string]func(src, dest string) error{
+	formatGzip:  convertToGzip,
+	formatQcow2: image.ConvertToQcow2,
+}
+
+// ExportServer is the interface to exportServerApp
+type ExportServer interface {
+	Run() error
+}
+
+// exportedImage is an image converted once in the scratch space and served to every following request
+type exportedImage struct {
+	mutex       sync.Mutex
+	path        string
+	contentType string
+	converted   bool
+}
+
+type exportServerApp struct {
+	bindAddress string
+	bindPort    int
+	source      string
+	tlsKey      string
+	tlsCert     string
+	clientCert  string
+	clientName  string
+	keyFile     string
+	certFile    string
+	mux         *http.ServeMux
+	images      map[string]*exportedImage
+}
+
+// NewExportServer returns a new instance of ExportServer serving source, raw or converted in scratchDir
+func NewExportServer(bindAddress string, bindPort int, source, scratchDir, tlsKey, tlsCert, clientCert, clientName string) ExportServer {
+	server := &exportServerApp{
+		bindAddress: bindAddress,
+		bindPort:    bindPort,
+		source:      source,
+		tlsKey:      tlsKey,
+		tlsCert:     tlsCert,
+		clientCert:  clientCert,
+		clientName:  clientName,
+		mux:         http.NewServeMux(),
+		images: map[string]*exportedImage{
+			formatRaw: {
+				path:        source,
+				contentType: "application/octet-stream",
+				converted:   true,
+			},
+			formatGzip: {
+				path:        filepath.Join(scratchDir, common.DiskImageName+".gz"),
+				contentType: "application/gzip",
+			},
+			formatQcow2: {
+				path:        filepath.Join(scratchDir, "disk.qcow2"),
+				contentType: "application/octet-stream",
+			},
+		},
+	}
+
+	server.mux.HandleFunc(common.ExportPath, server.exportHandler)
+
+	return server
+}
+
+func (app *exportServerApp) Run() error {
+	exportServer, err := app.createExportServer()
+	if err != nil {
+		return errors.Wrap(err, "Error creating export http server")
+	}
+
+	healthzServer := &http.Server{Handler: http.HandlerFunc(app.healthzHandler)}
+
+	exportListener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", app.bindAddress, app.bindPort))
+	if err != nil {
+		return errors.Wrap(err, "Error creating export listener")
+	}
+
+	healthzListener, err := net.Listen("tcp", fmt.Sprintf(":%d", healthzPort))
+	if err != nil {
+		return errors.Wrap(err, "Error creating healthz listener")
+	}
+
+	errChan := make(chan error)
+
+	go func() {
+		defer exportListener.Close()
+
+		if app.keyFile != "" && app.certFile != "" {
+			errChan <- exportServer.ServeTLS(exportListener, app.certFile, app.keyFile)
+			return
+		}
+
+		errChan <- exportServer.Serve(exportListener)
+	}()
+
+	go func() {
+		defer healthzServer.Close()
+
+		errChan <- healthzServer.Serve(healthzListener)
+	}()
+
+	err = <-errChan
+	klog.Errorf("HTTP server returned error %s", err.Error())
+	return err
+}
+
+func (app *exportServerApp) createExportServer() (*http.Server, error) {
+	server := &http.Server{
+		Handler: app,
+	}
+
+	if app.tlsKey != "" && app.tlsCert != "" {
+		certDir, err := ioutil.TempDir("", "exportserver-tls")
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating cert dir")
+		}
+
+		app.keyFile = filepath.Join(certDir, "tls.key")
+		app.certFile = filepath.Join(certDir, "tls.crt")
+
+		err = ioutil.WriteFile(app.keyFile, []byte(app.tlsKey), 0600)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating key file")
+		}
+
+		err = ioutil.WriteFile(app.certFile, []byte(app.tlsCert), 0600)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating cert file")
+		}
+	}
+
+	if app.clientCert != "" {
+		caCertPool := x509.NewCertPool()
+		if ok := caCertPool.AppendCertsFromPEM([]byte(app.clientCert)); !ok {
+			return nil, errors.Errorf("Invalid ca cert file %s", app.clientCert)
+		}
+
+		server.TLSConfig = &tls.Config{
+			ClientCAs:  caCertPool,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+	}
+
+	return server, nil
+}
+
+func (app *exportServerApp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	app.mux.ServeHTTP(w, r)
+}
+
+func (app *exportServerApp) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	io.WriteString(w, "OK")
+}
+
+func (app *exportServerApp) exportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.TLS != nil {
+		found := false
+
+		for _, cert := range r.TLS.PeerCertificates {
+			if cert.Subject.CommonName == app.clientName {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	} else {
+		klog.V(3).Infof("Handling HTTP connection")
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatRaw
+	}
+	exported, ok := app.images[format]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf("unsupported export format %q", format)))
+		return
+	}
+
+	path, err := app.convert(format, exported)
+	if err != nil {
+		klog.Errorf("Error converting %s to %s: %+v", app.source, format, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		klog.Errorf("Error opening %s: %+v", path, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", exported.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(exported.path)))
+	// ServeContent handles HEAD and Range requests, it finds the size of block devices by seeking to their end
+	http.ServeContent(w, r, "", time.Time{}, f)
+}
+
+// convert returns the path of the image in the given format, converting the source on the first request
+func (app *exportServerApp) convert(format string, exported *exportedImage) (string, error) {
+	exported.mutex.Lock()
+	defer exported.mutex.Unlock()
+
+	if !exported.converted {
+		klog.Infof("Converting %s to %s", app.source, format)
+		if err := converters[format](app.source, exported.path); err != nil {
+			os.Remove(exported.path)
+			return "", err
+		}
+		exported.converted = true
+	}
+
+	return exported.path, nil
+}
+
+func convertToGzip(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrap(err, "could not open source image")
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return errors.Wrap(err, "could not create gzip image")
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	if _, err = io.Copy(gw, in); err != nil {
+		return errors.Wrap(err, "could not compress source image")
+	}
+	if err = gw.Close(); err != nil {
+		return errors.Wrap(err, "could not compress source image")
+	}
+	return out.Close()
+}
@@ -0,0 +1,149 @@
+/*
+ * This file is part of the CDI project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package exportserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+)
+
+var sourceData = []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+
+var _ = Describe("Export server", func() {
+	var (
+		tmpDir string
+		app    *exportServerApp
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "exportserver")
+		Expect(err).ToNot(HaveOccurred())
+		source := filepath.Join(tmpDir, common.DiskImageName)
+		Expect(ioutil.WriteFile(source, sourceData, 0600)).To(Succeed())
+		app = NewExportServer("127.0.0.1", 0, source, tmpDir, "", "", "", "client").(*exportServerApp)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	doRequest := func(method, url string, headers map[string]string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, nil)
+		Expect(err).ToNot(HaveOccurred())
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, req)
+		return rr
+	}
+
+	table.DescribeTable("should serve the raw image", func(url string) {
+		rr := doRequest(http.MethodGet, url, nil)
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Body.Bytes()).To(Equal(sourceData))
+		Expect(rr.Header().Get("Accept-Ranges")).To(Equal("bytes"))
+	},
+		table.Entry("by default", common.ExportPath),
+		table.Entry("when asked for", common.ExportPath+"?format=raw"),
+	)
+
+	It("should serve a range of the image", func() {
+		rr := doRequest(http.MethodGet, common.ExportPath, map[string]string{"Range": "bytes=10-15"})
+		Expect(rr.Code).To(Equal(http.StatusPartialContent))
+		Expect(rr.Body.Bytes()).To(Equal(sourceData[10:16]))
+		Expect(rr.Header().Get("Content-Range")).To(Equal("bytes 10-15/36"))
+	})
+
+	It("should report the image size on HEAD", func() {
+		rr := doRequest(http.MethodHead, common.ExportPath, nil)
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Header().Get("Content-Length")).To(Equal("36"))
+		Expect(rr.Body.Len()).To(BeZero())
+	})
+
+	It("should serve a gzip image", func() {
+		rr := doRequest(http.MethodGet, common.ExportPath+"?format=gzip", nil)
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Header().Get("Content-Type")).To(Equal("application/gzip"))
+		gr, err := gzip.NewReader(bytes.NewReader(rr.Body.Bytes()))
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadAll(gr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(sourceData))
+	})
+
+	It("should convert the qcow2 image once", func() {
+		conversions := 0
+		convertToQcow2 := converters[formatQcow2]
+		defer func() {
+			converters[formatQcow2] = convertToQcow2
+		}()
+		converters[formatQcow2] = func(src, dest string) error {
+			conversions++
+			return ioutil.WriteFile(dest, []byte("qcow2"), 0600)
+		}
+
+		for i := 0; i < 2; i++ {
+			rr := doRequest(http.MethodGet, common.ExportPath+"?format=qcow2", nil)
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(Equal("qcow2"))
+			Expect(rr.Header().Get("Content-Disposition")).To(ContainSubstring("disk.qcow2"))
+		}
+		Expect(conversions).To(Equal(1))
+	})
+
+	table.DescribeTable("should reject", func(method, url string, status int) {
+		rr := doRequest(method, url, nil)
+		Expect(rr.Code).To(Equal(status))
+	},
+		table.Entry("unknown formats", http.MethodGet, common.ExportPath+"?format=vmdk", http.StatusBadRequest),
+		table.Entry("uploads", http.MethodPost, common.ExportPath, http.StatusMethodNotAllowed),
+	)
+
+	table.DescribeTable("should check the client certificate", func(clientName string, status int) {
+		req, err := http.NewRequest(http.MethodGet, common.ExportPath, nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: clientName}}},
+		}
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, req)
+		Expect(rr.Code).To(Equal(status))
+	},
+		table.Entry("accepting the proxy", "client", http.StatusOK),
+		table.Entry("rejecting other clients", "other", http.StatusUnauthorized),
+	)
+})
@@ -18,6 +18,13 @@ const MaxExpectedHdrSize = 512
 type Headers map[string]Header
 
 var knownHeaders = Headers{
+	"bz2": Header{
+		Format:      "bz2",
+		magicNumber: []byte{'B', 'Z', 'h'},
+		// TODO: size not in hdr
+		SizeOff: 0,
+		SizeLen: 0,
+	},
 	"gz": Header{
 		Format:      "gz",
 		magicNumber: []byte{0x1F, 0x8B},
@@ -25,6 +32,13 @@ var knownHeaders = Headers{
 		SizeOff: 0,
 		SizeLen: 0,
 	},
+	"lz4": Header{
+		Format:      "lz4",
+		magicNumber: []byte{0x04, 0x22, 0x4D, 0x18},
+		// TODO: size is optional and little endian
+		SizeOff: 0,
+		SizeLen: 0,
+	},
 	"qcow2": Header{
 		Format:      "qcow2",
 		magicNumber: []byte{'Q', 'F', 'I', 0xfb},
@@ -39,6 +53,39 @@ var knownHeaders = Headers{
 		SizeOff:     124,
 		SizeLen:     8,
 	},
+	"vdi": Header{
+		Format:      "vdi",
+		magicNumber: []byte{0x7f, 0x10, 0xda, 0xbe},
+		mgOffset:    0x40,
+		// TODO: size is little endian
+		SizeOff: 0,
+		SizeLen: 0,
+	},
+	"vhd": Header{
+		Format:      "vhd",
+		magicNumber: []byte("conectix"),
+		mgOffset:    0,
+		// Note: only dynamic and differencing images start with a copy of the footer, fixed images are raw images
+		//   with a footer at the end and are detected as raw.
+		SizeOff: 48,
+		SizeLen: 8,
+	},
+	"vhdx": Header{
+		Format:      "vhdx",
+		magicNumber: []byte("vhdxfile"),
+		mgOffset:    0,
+		// TODO: size is in the metadata region, not in hdr
+		SizeOff: 0,
+		SizeLen: 0,
+	},
+	"vmdk": Header{
+		Format:      "vmdk",
+		magicNumber: []byte{'K', 'D', 'M', 'V'},
+		mgOffset:    0,
+		// TODO: size is little endian and in sectors
+		SizeOff: 0,
+		SizeLen: 0,
+	},
 	"xz": Header{
 		Format:      "xz",
 		magicNumber: []byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00},
@@ -46,6 +93,13 @@ var knownHeaders = Headers{
 		SizeOff: 0,
 		SizeLen: 0,
 	},
+	"zst": Header{
+		Format:      "zst",
+		magicNumber: []byte{0x28, 0xB5, 0x2F, 0xFD},
+		// TODO: size is optional and little endian
+		SizeOff: 0,
+		SizeLen: 0,
+	},
 }
 
 // Header represents our parameters for a file format header
@@ -56,6 +56,26 @@ var _ = Describe("File format tests", func() {
 			fields{"xz", []byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00}, 0, 0, 0},
 			[]byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00},
 			true),
+		table.Entry("match zst",
+			fields{"zst", []byte{0x28, 0xB5, 0x2F, 0xFD}, 0, 0, 0},
+			[]byte{0x28, 0xB5, 0x2F, 0xFD},
+			true),
+		table.Entry("match bz2",
+			fields{"bz2", []byte{'B', 'Z', 'h'}, 0, 0, 0},
+			[]byte{'B', 'Z', 'h', '9'},
+			true),
+		table.Entry("match lz4",
+			fields{"lz4", []byte{0x04, 0x22, 0x4D, 0x18}, 0, 0, 0},
+			[]byte{0x04, 0x22, 0x4D, 0x18},
+			true),
+		table.Entry("match vmdk",
+			fields{"vmdk", []byte{'K', 'D', 'M', 'V'}, 0, 0, 0},
+			[]byte{'K', 'D', 'M', 'V', 0x01, 0x00, 0x00, 0x00},
+			true),
+		table.Entry("match vdi",
+			fields{"vdi", []byte{0x7f, 0x10, 0xda, 0xbe}, 0x40, 0, 0},
+			append(make([]byte, 0x40), 0x7f, 0x10, 0xda, 0xbe),
+			true),
 		table.Entry("failed match",
 			fields{"gz", []byte{0x1F, 0x8B}, 0, 0, 0},
 			[]byte{'Q', 'F', 'I', 0xfb},
@@ -90,6 +110,11 @@ var _ = Describe("File format tests", func() {
 			qcowbyte,
 			int64(3544391413610329398),
 			false),
+		table.Entry("get size of vhd",
+			fields{"vhd", []byte("conectix"), 0, 48, 8},
+			append(append([]byte("conectix"), make([]byte, 40)...), 0, 0, 0, 0, 0x40, 0, 0, 0),
+			int64(1<<30),
+			false),
 		table.Entry("does not implement size",
 			fields{"gz", []byte{0x1F, 0x8B}, 0, 0, 0},
 			[]byte{0x1F, 0x8B},
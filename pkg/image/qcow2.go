@@ -0,0 +1,625 @@
+/*
+Copyright 2020 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	qcow2Magic          = 0x514649fb
+	qcow2MinClusterBits = 9
+	qcow2MaxClusterBits = 21
+	// qcow2MaxL1Size is the largest L1 table accepted, in bytes, as in qemu
+	qcow2MaxL1Size = 32 * 1024 * 1024
+
+	qcow2IncompatDirty       = 1 << 0
+	qcow2IncompatCorrupt     = 1 << 1
+	qcow2IncompatDataFile    = 1 << 2
+	qcow2IncompatCompression = 1 << 3
+	qcow2IncompatExtendedL2  = 1 << 4
+
+	qcow2CompressionDeflate = 0
+	qcow2CompressionZstd    = 1
+
+	// qcow2OffsetMask extracts the host offset of L1 entries and standard L2 entries
+	qcow2OffsetMask     = 0x00fffffffffffe00
+	qcow2FlagCompressed = 1 << 62
+	qcow2FlagZero       = 1
+)
+
+// qcow2StreamMemoryLimit is the memory used to buffer the clusters read before the tables mapping them, may be
+// overridden in tests
+var qcow2StreamMemoryLimit = int64(64 * 1024 * 1024)
+
+// ErrQcow2NeedsScratch is returned by ConvertQcow2Stream when the tables of the image come after more data than can
+// be buffered in memory, and no directory was passed to buffer the rest of the data.
+var ErrQcow2NeedsScratch = errors.New("the qcow2 image stores its tables after too much data to buffer in memory, scratch space is needed")
+
+// Qcow2Header holds the fields of a qcow2 header needed to convert the image.
+type Qcow2Header struct {
+	Version     uint32
+	ClusterBits uint32
+	// Size is the virtual size of the image, in bytes
+	Size                 uint64
+	L1Size               uint32
+	L1TableOffset        uint64
+	IncompatibleFeatures uint64
+	CompressionType      uint8
+}
+
+// RawImageWriter is where ConvertQcow2Stream writes the raw image.
+type RawImageWriter interface {
+	io.WriterAt
+	// ZeroRange makes sure the given range reads back as zeroes.
+	ZeroRange(off, length int64) error
+}
+
+// ParseQcow2Header parses the qcow2 header at the start of b, and fails if the image uses a feature ConvertQcow2Stream
+// does not support: a backing file, encryption, an external data file or extended L2 entries.
+func ParseQcow2Header(b []byte) (*Qcow2Header, error) {
+	be := binary.BigEndian
+	if len(b) < 72 || be.Uint32(b) != qcow2Magic {
+		return nil, errors.New("not a qcow2 header")
+	}
+	h := &Qcow2Header{
+		Version:       be.Uint32(b[4:]),
+		ClusterBits:   be.Uint32(b[20:]),
+		Size:          be.Uint64(b[24:]),
+		L1Size:        be.Uint32(b[36:]),
+		L1TableOffset: be.Uint64(b[40:]),
+	}
+	switch h.Version {
+	case 2:
+	case 3:
+		if len(b) < 104 {
+			return nil, errors.New("qcow2 v3 header is too short")
+		}
+		h.IncompatibleFeatures = be.Uint64(b[72:])
+		if headerLength := be.Uint32(b[100:]); headerLength > 104 && len(b) > 104 {
+			h.CompressionType = b[104]
+		}
+	default:
+		return nil, errors.Errorf("unsupported qcow2 version %d", h.Version)
+	}
+	if be.Uint64(b[8:]) != 0 {
+		return nil, errors.New("qcow2 image has a backing file")
+	}
+	if be.Uint32(b[32:]) != 0 {
+		return nil, errors.New("qcow2 image is encrypted")
+	}
+	if h.ClusterBits < qcow2MinClusterBits || h.ClusterBits > qcow2MaxClusterBits {
+		return nil, errors.Errorf("invalid qcow2 cluster bits %d", h.ClusterBits)
+	}
+	if unsupported := h.IncompatibleFeatures &^ (qcow2IncompatDirty | qcow2IncompatCompression); unsupported != 0 {
+		return nil, errors.Errorf("qcow2 image uses unsupported incompatible features %#x", unsupported)
+	}
+	if h.CompressionType != qcow2CompressionDeflate && h.CompressionType != qcow2CompressionZstd {
+		return nil, errors.Errorf("unsupported qcow2 compression type %d", h.CompressionType)
+	}
+	clusterSize := uint64(1) << h.ClusterBits
+	if h.L1TableOffset%clusterSize != 0 {
+		return nil, errors.Errorf("qcow2 L1 table offset %d is not aligned to a cluster", h.L1TableOffset)
+	}
+	if uint64(h.L1Size)*8 > qcow2MaxL1Size {
+		return nil, errors.Errorf("qcow2 L1 table of %d entries is too large", h.L1Size)
+	}
+	l2Coverage := clusterSize * clusterSize / 8
+	if uint64(h.L1Size) < (h.Size+l2Coverage-1)/l2Coverage {
+		return nil, errors.Errorf("qcow2 L1 table of %d entries is too small for a virtual size of %d", h.L1Size, h.Size)
+	}
+	if h.L1Size > 0 && h.L1TableOffset == 0 {
+		return nil, errors.New("qcow2 image has no L1 table")
+	}
+	return h, nil
+}
+
+// ConvertQcow2Stream reads a qcow2 image sequentially from r, starting with its header, and writes the raw image to w.
+// The clusters are written as soon as the L2 table mapping them has been read. The clusters read before the tables
+// mapping them are buffered, in memory, then in a file of spillDir. If spillDir is empty and the memory buffer is full,
+// ErrQcow2NeedsScratch is returned. The stream is read to its end, so it can be verified by the caller.
+func ConvertQcow2Stream(r io.Reader, w RawImageWriter, spillDir string) error {
+	hdrBuf := make([]byte, MaxExpectedHdrSize)
+	if _, err := io.ReadFull(r, hdrBuf); err != nil {
+		return errors.Wrap(err, "unable to read qcow2 header")
+	}
+	hdr, err := ParseQcow2Header(hdrBuf)
+	if err != nil {
+		return err
+	}
+	s := newQcow2Stream(hdr, w, spillDir)
+	defer s.close()
+	// The rest of the first cluster holds the header extensions
+	if _, err := io.CopyN(ioutil.Discard, r, s.clusterSize-int64(len(hdrBuf))); err != nil {
+		return errors.Wrap(err, "unable to read qcow2 header extensions")
+	}
+	if hdr.L1Size > 0 {
+		if err := s.addTable(0, int64(hdr.L1TableOffset), int64(hdr.L1Size)*8, s.parseL1); err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, s.clusterSize)
+	for cluster := int64(1); ; cluster++ {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil && readErr != io.ErrUnexpectedEOF {
+			return errors.Wrap(readErr, "unable to read qcow2 image")
+		}
+		for i := n; i < len(buf); i++ {
+			buf[i] = 0
+		}
+		if err := s.process(cluster, buf); err != nil {
+			return err
+		}
+		if readErr == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+	return s.finish()
+}
+
+// qcow2Stream converts a qcow2 image read sequentially, one host cluster at a time.
+type qcow2Stream struct {
+	hdr         *Qcow2Header
+	w           RawImageWriter
+	clusterSize int64
+	// tables maps the host clusters not read yet to the L1 or L2 table they are part of
+	tables map[int64]*qcow2Table
+	// pendingTables is the number of tables not completely read yet
+	pendingTables int
+	// data maps the host clusters not read yet to the guest offsets of their data
+	data map[int64][]int64
+	// compressed maps the host clusters not read yet to the compressed clusters they hold a part of
+	compressed map[int64][]*qcow2CompressedCluster
+	// buffer holds the clusters read while some tables are pending, one of them may map the clusters
+	buffer *qcow2Buffer
+	// written has a bit set for each guest cluster written
+	written []uint64
+	// out holds a decompressed cluster
+	out  []byte
+	zstd *zstd.Decoder
+}
+
+// qcow2Table is an L1 or L2 table, parsed once all its clusters are read.
+type qcow2Table struct {
+	first   int64
+	data    []byte
+	missing int
+	parse   func(current int64, data []byte) error
+}
+
+// qcow2CompressedCluster is a compressed guest cluster, decompressed once all its data is read.
+type qcow2CompressedCluster struct {
+	offset  int64
+	length  int64
+	data    []byte
+	missing int
+	guest   int64
+}
+
+func newQcow2Stream(hdr *Qcow2Header, w RawImageWriter, spillDir string) *qcow2Stream {
+	clusterSize := int64(1) << hdr.ClusterBits
+	guestClusters := (int64(hdr.Size) + clusterSize - 1) / clusterSize
+	return &qcow2Stream{
+		hdr:         hdr,
+		w:           w,
+		clusterSize: clusterSize,
+		tables:      make(map[int64]*qcow2Table),
+		data:        make(map[int64][]int64),
+		compressed:  make(map[int64][]*qcow2CompressedCluster),
+		buffer:      newQcow2Buffer(clusterSize, spillDir),
+		written:     make([]uint64, (guestClusters+63)/64),
+		out:         make([]byte, clusterSize),
+	}
+}
+
+// complete returns true once all the tables are read, any cluster read afterwards is either mapped or unused.
+func (s *qcow2Stream) complete() bool {
+	return s.pendingTables == 0
+}
+
+// process handles the host cluster read from the stream, current is its index.
+func (s *qcow2Stream) process(current int64, buf []byte) error {
+	if t, ok := s.tables[current]; ok {
+		delete(s.tables, current)
+		copy(t.data[(current-t.first)*s.clusterSize:], buf)
+		t.missing--
+		if t.missing > 0 {
+			return nil
+		}
+		s.pendingTables--
+		if err := t.parse(current, t.data); err != nil {
+			return err
+		}
+		if s.complete() {
+			klog.V(3).Infof("All the qcow2 tables were read at offset %d", (current+1)*s.clusterSize)
+			s.buffer.reset()
+		}
+		return nil
+	}
+	if guests, ok := s.data[current]; ok {
+		delete(s.data, current)
+		for _, guest := range guests {
+			if err := s.writeCluster(guest, buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if clusters, ok := s.compressed[current]; ok {
+		delete(s.compressed, current)
+		for _, cc := range clusters {
+			if err := s.fillCompressed(cc, current, buf); err != nil {
+				return err
+			}
+		}
+	}
+	if !s.complete() {
+		// A table read later may map this cluster, or the rest of it if it holds compressed clusters
+		return s.buffer.put(current, buf)
+	}
+	return nil
+}
+
+// addTable registers the table at offset, its clusters already read are taken from the buffer.
+func (s *qcow2Stream) addTable(current, offset, length int64, parse func(current int64, data []byte) error) error {
+	first := offset / s.clusterSize
+	count := (length + s.clusterSize - 1) / s.clusterSize
+	t := &qcow2Table{first: first, data: make([]byte, count*s.clusterSize), parse: parse}
+	for c := first; c < first+count; c++ {
+		if c > current {
+			s.tables[c] = t
+			t.missing++
+			continue
+		}
+		data, err := s.buffer.take(c)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read the qcow2 table at offset %d", offset)
+		}
+		copy(t.data[(c-first)*s.clusterSize:], data)
+	}
+	if t.missing > 0 {
+		s.pendingTables++
+		return nil
+	}
+	return t.parse(current, t.data)
+}
+
+func (s *qcow2Stream) parseL1(current int64, data []byte) error {
+	l2Coverage := s.clusterSize * s.clusterSize / 8
+	for i := int64(0); i < int64(s.hdr.L1Size); i++ {
+		guest := i * l2Coverage
+		if guest >= int64(s.hdr.Size) {
+			break
+		}
+		offset := int64(binary.BigEndian.Uint64(data[i*8:]) & qcow2OffsetMask)
+		if offset == 0 {
+			// No L2 table, the range reads as zeroes
+			continue
+		}
+		if offset%s.clusterSize != 0 {
+			return errors.Errorf("qcow2 L2 table offset %d is not aligned to a cluster", offset)
+		}
+		parse := func(current int64, data []byte) error {
+			return s.parseL2(current, guest, data)
+		}
+		if err := s.addTable(current, offset, s.clusterSize, parse); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *qcow2Stream) parseL2(current, guestBase int64, data []byte) error {
+	for j := int64(0); j < s.clusterSize/8; j++ {
+		guest := guestBase + j*s.clusterSize
+		if guest >= int64(s.hdr.Size) {
+			break
+		}
+		entry := binary.BigEndian.Uint64(data[j*8:])
+		if entry&qcow2FlagCompressed != 0 {
+			if err := s.addCompressed(current, guest, entry); err != nil {
+				return err
+			}
+			continue
+		}
+		offset := int64(entry & qcow2OffsetMask)
+		if entry&qcow2FlagZero != 0 || offset == 0 {
+			// Zero or unallocated cluster, it reads as zeroes
+			continue
+		}
+		if offset%s.clusterSize != 0 {
+			return errors.Errorf("qcow2 data cluster offset %d is not aligned to a cluster", offset)
+		}
+		if err := s.addData(current, offset/s.clusterSize, guest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addData registers the host cluster holding the data of the guest cluster at guest.
+func (s *qcow2Stream) addData(current, cluster, guest int64) error {
+	if cluster > current {
+		s.data[cluster] = append(s.data[cluster], guest)
+		return nil
+	}
+	data, err := s.buffer.take(cluster)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the qcow2 data cluster at offset %d", cluster*s.clusterSize)
+	}
+	return s.writeCluster(guest, data)
+}
+
+// addCompressed registers the compressed cluster described by the L2 entry, its data already read is taken from the
+// buffer.
+func (s *qcow2Stream) addCompressed(current, guest int64, entry uint64) error {
+	sizeShift := 62 - (s.hdr.ClusterBits - 8)
+	offset := int64(entry & (1<<sizeShift - 1))
+	sectors := int64(entry>>sizeShift&(1<<(s.hdr.ClusterBits-8)-1)) + 1
+	cc := &qcow2CompressedCluster{
+		offset: offset,
+		length: sectors*512 - offset&511,
+		guest:  guest,
+	}
+	for c := offset / s.clusterSize; c <= (offset+cc.length-1)/s.clusterSize; c++ {
+		if c > current {
+			s.compressed[c] = append(s.compressed[c], cc)
+			cc.missing++
+			continue
+		}
+		// The cluster may hold other compressed clusters, keep it buffered
+		data, err := s.buffer.get(c)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read the qcow2 compressed cluster at offset %d", offset)
+		}
+		cc.fill(c, s.clusterSize, data)
+	}
+	if cc.missing > 0 {
+		return nil
+	}
+	return s.decompress(cc)
+}
+
+func (s *qcow2Stream) fillCompressed(cc *qcow2CompressedCluster, cluster int64, buf []byte) error {
+	cc.fill(cluster, s.clusterSize, buf)
+	cc.missing--
+	if cc.missing > 0 {
+		return nil
+	}
+	return s.decompress(cc)
+}
+
+// fill copies the part of the compressed data in the host cluster, a nil buf is a cluster of zeroes.
+func (cc *qcow2CompressedCluster) fill(cluster, clusterSize int64, buf []byte) {
+	if cc.data == nil {
+		// Allocated on first use, as all the clusters of an L2 table are registered at once
+		cc.data = make([]byte, cc.length)
+	}
+	if buf == nil {
+		return
+	}
+	start, end := cluster*clusterSize, (cluster+1)*clusterSize
+	if cc.offset > start {
+		start = cc.offset
+	}
+	if cc.offset+cc.length < end {
+		end = cc.offset + cc.length
+	}
+	copy(cc.data[start-cc.offset:end-cc.offset], buf[start-cluster*clusterSize:])
+}
+
+func (s *qcow2Stream) decompress(cc *qcow2CompressedCluster) error {
+	var r io.Reader
+	switch s.hdr.CompressionType {
+	case qcow2CompressionZstd:
+		if s.zstd == nil {
+			var err error
+			if s.zstd, err = zstd.NewReader(nil); err != nil {
+				return errors.Wrap(err, "could not create zstd decoder")
+			}
+		}
+		if err := s.zstd.Reset(bytes.NewReader(cc.data)); err != nil {
+			return errors.Wrap(err, "could not reset zstd decoder")
+		}
+		r = s.zstd
+	default:
+		r = flate.NewReader(bytes.NewReader(cc.data))
+	}
+	if _, err := io.ReadFull(r, s.out); err != nil {
+		return errors.Wrapf(err, "unable to decompress the qcow2 cluster at offset %d", cc.offset)
+	}
+	cc.data = nil
+	return s.writeCluster(cc.guest, s.out)
+}
+
+// writeCluster writes the data of the guest cluster at guest, a nil data is a cluster of zeroes.
+func (s *qcow2Stream) writeCluster(guest int64, data []byte) error {
+	if data == nil {
+		return nil
+	}
+	n := s.clusterSize
+	if remaining := int64(s.hdr.Size) - guest; remaining < n {
+		n = remaining
+	}
+	if _, err := s.w.WriteAt(data[:n], guest); err != nil {
+		return errors.Wrap(err, "unable to write the raw image")
+	}
+	index := guest / s.clusterSize
+	s.written[index/64] |= 1 << uint(index%64)
+	return nil
+}
+
+// finish checks all the image was read, and zeroes out the guest clusters not written.
+func (s *qcow2Stream) finish() error {
+	// The size of the last compressed clusters may be rounded up past the end of the image
+	pending := make(map[*qcow2CompressedCluster]bool)
+	for _, clusters := range s.compressed {
+		for _, cc := range clusters {
+			pending[cc] = true
+		}
+	}
+	for cc := range pending {
+		cc.fill(0, s.clusterSize, nil)
+		if err := s.decompress(cc); err != nil {
+			return err
+		}
+	}
+	if !s.complete() || len(s.data) > 0 {
+		return errors.New("the qcow2 image is truncated")
+	}
+	guestClusters := (int64(s.hdr.Size) + s.clusterSize - 1) / s.clusterSize
+	for index := int64(0); index < guestClusters; {
+		if s.written[index/64]&(1<<uint(index%64)) != 0 {
+			index++
+			continue
+		}
+		start := index
+		for index < guestClusters && s.written[index/64]&(1<<uint(index%64)) == 0 {
+			index++
+		}
+		end := index * s.clusterSize
+		if end > int64(s.hdr.Size) {
+			end = int64(s.hdr.Size)
+		}
+		if err := s.w.ZeroRange(start*s.clusterSize, end-start*s.clusterSize); err != nil {
+			return errors.Wrap(err, "unable to zero the raw image")
+		}
+	}
+	return nil
+}
+
+func (s *qcow2Stream) close() {
+	s.buffer.reset()
+	if s.zstd != nil {
+		s.zstd.Close()
+	}
+}
+
+// qcow2Buffer holds host clusters read before the tables mapping them, in memory up to qcow2StreamMemoryLimit, then
+// in a file of spillDir.
+type qcow2Buffer struct {
+	clusterSize int64
+	spillDir    string
+	clusters    map[int64]qcow2BufferedCluster
+	memory      int64
+	spill       *os.File
+	spillSize   int64
+}
+
+// qcow2BufferedCluster is either in memory, in the spill file, or a cluster of zeroes if neither
+type qcow2BufferedCluster struct {
+	data        []byte
+	spillOffset int64
+}
+
+func newQcow2Buffer(clusterSize int64, spillDir string) *qcow2Buffer {
+	return &qcow2Buffer{
+		clusterSize: clusterSize,
+		spillDir:    spillDir,
+		clusters:    make(map[int64]qcow2BufferedCluster),
+	}
+}
+
+func (b *qcow2Buffer) put(cluster int64, buf []byte) error {
+	if isZeroCluster(buf) {
+		b.clusters[cluster] = qcow2BufferedCluster{spillOffset: -1}
+		return nil
+	}
+	if b.memory+b.clusterSize <= qcow2StreamMemoryLimit {
+		b.clusters[cluster] = qcow2BufferedCluster{data: append([]byte(nil), buf...), spillOffset: -1}
+		b.memory += b.clusterSize
+		return nil
+	}
+	if b.spillDir == "" {
+		return ErrQcow2NeedsScratch
+	}
+	if b.spill == nil {
+		var err error
+		if b.spill, err = ioutil.TempFile(b.spillDir, "qcow2-buffer"); err != nil {
+			return errors.Wrap(err, "could not create qcow2 buffer file")
+		}
+		klog.V(1).Infof("Buffering the qcow2 clusters read before their tables in %s", b.spill.Name())
+	}
+	if _, err := b.spill.WriteAt(buf, b.spillSize); err != nil {
+		return errors.Wrap(err, "unable to write qcow2 buffer file")
+	}
+	b.clusters[cluster] = qcow2BufferedCluster{spillOffset: b.spillSize}
+	b.spillSize += b.clusterSize
+	return nil
+}
+
+// get returns the buffered cluster, nil if it only contains zeroes.
+func (b *qcow2Buffer) get(cluster int64) ([]byte, error) {
+	bc, ok := b.clusters[cluster]
+	if !ok {
+		return nil, errors.Errorf("the cluster at offset %d was not buffered, the image is invalid", cluster*b.clusterSize)
+	}
+	if bc.spillOffset < 0 {
+		return bc.data, nil
+	}
+	data := make([]byte, b.clusterSize)
+	if _, err := b.spill.ReadAt(data, bc.spillOffset); err != nil {
+		return nil, errors.Wrap(err, "unable to read qcow2 buffer file")
+	}
+	return data, nil
+}
+
+// take returns the buffered cluster like get, and removes it from the buffer.
+func (b *qcow2Buffer) take(cluster int64) ([]byte, error) {
+	data, err := b.get(cluster)
+	if err != nil {
+		return nil, err
+	}
+	if b.clusters[cluster].data != nil {
+		b.memory -= b.clusterSize
+	}
+	delete(b.clusters, cluster)
+	return data, nil
+}
+
+// reset empties the buffer and removes the spill file.
+func (b *qcow2Buffer) reset() {
+	b.clusters = make(map[int64]qcow2BufferedCluster)
+	b.memory = 0
+	if b.spill != nil {
+		b.spill.Close()
+		os.Remove(b.spill.Name())
+		b.spill = nil
+		b.spillSize = 0
+	}
+}
+
+func isZeroCluster(buf []byte) bool {
+	for _, b := range buf {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
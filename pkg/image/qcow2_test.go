@@ -0,0 +1,322 @@
+package image
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+// qcow2TestImage builds a qcow2 image of the guest data.
+type qcow2TestImage struct {
+	version         uint32
+	clusterBits     uint32
+	compressionType uint8
+	guest           []byte
+	// compress stores the clusters with data compressed
+	compress bool
+	// zeroFlag stores the clusters of zeroes as allocated clusters of garbage with the zero flag set
+	zeroFlag bool
+	// tablesLast stores the L2 tables and the L1 table after the data
+	tablesLast bool
+	host       []byte
+}
+
+// alloc appends an aligned cluster to the image and returns its offset
+func (t *qcow2TestImage) alloc() int64 {
+	return t.allocTable(1 << t.clusterBits)
+}
+
+// allocTable appends the aligned clusters needed for length bytes to the image and returns their offset
+func (t *qcow2TestImage) allocTable(length int64) int64 {
+	clusterSize := int64(1) << t.clusterBits
+	for int64(len(t.host))%clusterSize != 0 {
+		t.host = append(t.host, 0)
+	}
+	offset := int64(len(t.host))
+	t.host = append(t.host, make([]byte, (length+clusterSize-1)/clusterSize*clusterSize)...)
+	return offset
+}
+
+func (t *qcow2TestImage) compressCluster(data []byte) []byte {
+	if t.compressionType == qcow2CompressionZstd {
+		enc, err := zstd.NewWriter(nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer enc.Close()
+		return enc.EncodeAll(data, nil)
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	Expect(err).ToNot(HaveOccurred())
+	_, err = w.Write(data)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(w.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+func (t *qcow2TestImage) build() []byte {
+	be := binary.BigEndian
+	clusterSize := int64(1) << t.clusterBits
+	l2Entries := clusterSize / 8
+	guestClusters := (int64(len(t.guest)) + clusterSize - 1) / clusterSize
+	l1Size := (guestClusters + l2Entries - 1) / l2Entries
+	t.host = nil
+	t.alloc()
+	var l1Offset int64
+	if !t.tablesLast {
+		l1Offset = t.allocTable(l1Size * 8)
+	}
+	l2Tables := make([][]byte, l1Size)
+	l2Offsets := make([]int64, l1Size)
+	for i := range l2Tables {
+		l2Tables[i] = make([]byte, clusterSize)
+		if !t.tablesLast {
+			l2Offsets[i] = t.alloc()
+		}
+		for j := int64(0); j < l2Entries; j++ {
+			guest := (int64(i)*l2Entries + j) * clusterSize
+			if guest >= int64(len(t.guest)) {
+				break
+			}
+			data := make([]byte, clusterSize)
+			copy(data, t.guest[guest:])
+			var entry uint64
+			switch {
+			case isZeroCluster(data):
+				if t.zeroFlag {
+					offset := t.alloc()
+					copy(t.host[offset:], bytes.Repeat([]byte{0xaa}, int(clusterSize)))
+					entry = uint64(offset) | qcow2FlagZero
+				}
+			case t.compress:
+				compressed := t.compressCluster(data)
+				offset := int64(len(t.host))
+				sectors := (offset&511 + int64(len(compressed)) + 511) / 512
+				t.host = append(t.host, compressed...)
+				entry = qcow2FlagCompressed | uint64(sectors-1)<<(62-(t.clusterBits-8)) | uint64(offset)
+			default:
+				offset := t.alloc()
+				copy(t.host[offset:], data)
+				entry = uint64(offset) | 1<<63
+			}
+			be.PutUint64(l2Tables[i][j*8:], entry)
+		}
+	}
+	if t.tablesLast {
+		for i := range l2Tables {
+			l2Offsets[i] = t.alloc()
+		}
+		l1Offset = t.allocTable(l1Size * 8)
+	}
+	for i, table := range l2Tables {
+		copy(t.host[l2Offsets[i]:], table)
+		be.PutUint64(t.host[l1Offset+int64(i)*8:], uint64(l2Offsets[i])|1<<63)
+	}
+	hdr := t.host[:clusterSize]
+	be.PutUint32(hdr, qcow2Magic)
+	be.PutUint32(hdr[4:], t.version)
+	be.PutUint32(hdr[20:], t.clusterBits)
+	be.PutUint64(hdr[24:], uint64(len(t.guest)))
+	be.PutUint32(hdr[36:], uint32(l1Size))
+	be.PutUint64(hdr[40:], uint64(l1Offset))
+	if t.version == 3 {
+		if t.compressionType != qcow2CompressionDeflate {
+			be.PutUint64(hdr[72:], qcow2IncompatCompression)
+		}
+		be.PutUint32(hdr[96:], 4)
+		be.PutUint32(hdr[100:], 112)
+		hdr[104] = t.compressionType
+	}
+	return t.host
+}
+
+// qcow2TestGuest returns 5MiB and a bit of data, with every third 4KiB cluster zeroed
+func qcow2TestGuest() []byte {
+	data := make([]byte, 5*1024*1024+1000)
+	for i := range data {
+		if (i/4096)%3 != 0 {
+			data[i] = byte((i/4096)*7 + i%251)
+		}
+	}
+	return data
+}
+
+// memWriter is a RawImageWriter keeping the image in memory, initially filled with garbage
+type memWriter struct {
+	data []byte
+}
+
+func newMemWriter(size int) *memWriter {
+	return &memWriter{data: bytes.Repeat([]byte{0xff}, size)}
+}
+
+func (m *memWriter) WriteAt(p []byte, off int64) (int, error) {
+	return copy(m.data[off:], p), nil
+}
+
+func (m *memWriter) ZeroRange(off, length int64) error {
+	copy(m.data[off:off+length], make([]byte, length))
+	return nil
+}
+
+var _ = Describe("Qcow2 stream conversion", func() {
+	var (
+		guest       []byte
+		oldLimit    int64
+		tmpDir      string
+		imageConfig *qcow2TestImage
+	)
+
+	BeforeEach(func() {
+		var err error
+		guest = qcow2TestGuest()
+		oldLimit = qcow2StreamMemoryLimit
+		tmpDir, err = ioutil.TempDir("", "qcow2")
+		Expect(err).ToNot(HaveOccurred())
+		imageConfig = &qcow2TestImage{version: 3, clusterBits: 12, guest: guest}
+	})
+
+	AfterEach(func() {
+		qcow2StreamMemoryLimit = oldLimit
+		os.RemoveAll(tmpDir)
+	})
+
+	table.DescribeTable("should convert", func(configure func(*qcow2TestImage)) {
+		configure(imageConfig)
+		w := newMemWriter(len(guest))
+		Expect(ConvertQcow2Stream(bytes.NewReader(imageConfig.build()), w, "")).To(Succeed())
+		Expect(w.data).To(Equal(guest))
+	},
+		table.Entry("a v2 image with the tables first", func(t *qcow2TestImage) {
+			t.version = 2
+		}),
+		table.Entry("an image with the tables last", func(t *qcow2TestImage) {
+			t.tablesLast = true
+		}),
+		table.Entry("an image with 512 bytes clusters", func(t *qcow2TestImage) {
+			t.clusterBits = 9
+		}),
+		table.Entry("an image with zero clusters", func(t *qcow2TestImage) {
+			t.zeroFlag = true
+		}),
+		table.Entry("an image with deflate compressed clusters", func(t *qcow2TestImage) {
+			t.compress = true
+		}),
+		table.Entry("an image with zstd compressed clusters", func(t *qcow2TestImage) {
+			t.compress = true
+			t.compressionType = qcow2CompressionZstd
+		}),
+		table.Entry("an image with compressed clusters and the tables last", func(t *qcow2TestImage) {
+			t.compress = true
+			t.tablesLast = true
+		}),
+	)
+
+	It("should buffer the clusters read before the tables in the spill directory", func() {
+		qcow2StreamMemoryLimit = 16 * 4096
+		imageConfig.tablesLast = true
+		w := newMemWriter(len(guest))
+		Expect(ConvertQcow2Stream(bytes.NewReader(imageConfig.build()), w, tmpDir)).To(Succeed())
+		Expect(w.data).To(Equal(guest))
+		files, err := ioutil.ReadDir(tmpDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+
+	It("should need scratch space when the memory buffer is full", func() {
+		qcow2StreamMemoryLimit = 16 * 4096
+		imageConfig.tablesLast = true
+		err := ConvertQcow2Stream(bytes.NewReader(imageConfig.build()), newMemWriter(len(guest)), "")
+		Expect(errors.Cause(err)).To(Equal(ErrQcow2NeedsScratch))
+	})
+
+	It("should fail to convert a truncated image", func() {
+		image := imageConfig.build()
+		err := ConvertQcow2Stream(bytes.NewReader(image[:len(image)/2]), newMemWriter(len(guest)), "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	table.DescribeTable("should reject a header", func(configure func(hdr []byte)) {
+		image := imageConfig.build()
+		configure(image)
+		_, err := ParseQcow2Header(image)
+		Expect(err).To(HaveOccurred())
+		err = ConvertQcow2Stream(bytes.NewReader(image), newMemWriter(len(guest)), "")
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("with an unsupported version", func(hdr []byte) {
+			binary.BigEndian.PutUint32(hdr[4:], 4)
+		}),
+		table.Entry("with a backing file", func(hdr []byte) {
+			binary.BigEndian.PutUint64(hdr[8:], 1024)
+		}),
+		table.Entry("of an encrypted image", func(hdr []byte) {
+			binary.BigEndian.PutUint32(hdr[32:], 1)
+		}),
+		table.Entry("with an external data file", func(hdr []byte) {
+			binary.BigEndian.PutUint64(hdr[72:], qcow2IncompatDataFile)
+		}),
+		table.Entry("with extended L2 entries", func(hdr []byte) {
+			binary.BigEndian.PutUint64(hdr[72:], qcow2IncompatExtendedL2)
+		}),
+		table.Entry("with an L1 table too small for the virtual size", func(hdr []byte) {
+			binary.BigEndian.PutUint32(hdr[36:], 1)
+		}),
+	)
+
+	Context("with images produced by qemu-img", func() {
+		var qemuImg string
+
+		BeforeEach(func() {
+			var err error
+			qemuImg, err = exec.LookPath("qemu-img")
+			if err != nil {
+				Skip("qemu-img is not available")
+			}
+			// qemu-img rounds the virtual size of raw images up to a sector
+			guest = guest[:5*1024*1024]
+		})
+
+		table.DescribeTable("should convert", func(options ...string) {
+			rawPath := filepath.Join(tmpDir, "guest.raw")
+			imagePath := filepath.Join(tmpDir, "guest.qcow2")
+			Expect(ioutil.WriteFile(rawPath, guest, 0644)).To(Succeed())
+			args := append([]string{"convert", "-f", "raw", "-O", "qcow2"}, options...)
+			out, err := exec.Command(qemuImg, append(args, rawPath, imagePath)...).CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(out))
+			image, err := ioutil.ReadFile(imagePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			hdr, err := ParseQcow2Header(image)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hdr.Size).To(Equal(uint64(len(guest))))
+			w := newMemWriter(len(guest))
+			Expect(ConvertQcow2Stream(bytes.NewReader(image), w, "")).To(Succeed())
+			Expect(w.data).To(Equal(guest))
+		},
+			table.Entry("with compressed clusters and 8 bits refcounts", "-c", "-o", "refcount_bits=8"),
+			table.Entry("with compressed 512 bytes clusters and 64 bits refcounts", "-c", "-o", "cluster_size=512,refcount_bits=64"),
+			table.Entry("in the v2 format with compressed clusters", "-c", "-o", "compat=0.10"),
+			table.Entry("with 2MiB clusters and 1 bit refcounts", "-o", "cluster_size=2M,refcount_bits=1"),
+		)
+	})
+
+	It("should parse the header", func() {
+		imageConfig.compressionType = qcow2CompressionZstd
+		hdr, err := ParseQcow2Header(imageConfig.build())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hdr.Version).To(Equal(uint32(3)))
+		Expect(hdr.ClusterBits).To(Equal(uint32(12)))
+		Expect(hdr.Size).To(Equal(uint64(len(guest))))
+		Expect(hdr.CompressionType).To(Equal(uint8(qcow2CompressionZstd)))
+	})
+})
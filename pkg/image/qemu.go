@@ -23,6 +23,7 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -52,15 +53,42 @@ type ImgInfo struct {
 	VirtualSize int64 `json:"virtual-size"`
 	// ActualSize is the size of the qcow2 image
 	ActualSize int64 `json:"actual-size"`
+	// FormatSpecific contains the information specific to the format of the image
+	FormatSpecific *ImgFormatSpecific `json:"format-specific,omitempty"`
+}
+
+// ImgFormatSpecific contains the format specific image information used to validate the image.
+type ImgFormatSpecific struct {
+	// Type is the format the information applies to
+	Type string `json:"type"`
+	// Data is the format specific information
+	Data ImgFormatSpecificData `json:"data"`
+}
+
+// ImgFormatSpecificData contains the format specific fields that may reference files outside of the image.
+type ImgFormatSpecificData struct {
+	// CreateType is the vmdk subformat
+	CreateType string `json:"create-type,omitempty"`
+	// Extents are the files holding the data of a vmdk image
+	Extents []ImgExtent `json:"extents,omitempty"`
+	// DataFile is the external data file of a qcow2 image
+	DataFile string `json:"data-file,omitempty"`
+}
+
+// ImgExtent is an extent of a vmdk image.
+type ImgExtent struct {
+	// Filename is the file holding the extent data
+	Filename string `json:"filename"`
 }
 
 // QEMUOperations defines the interface for executing qemu subprocesses
 type QEMUOperations interface {
-	ConvertToRawStream(*url.URL, string) error
+	ConvertToRawStream(*url.URL, string, bool) error
 	Resize(string, resource.Quantity) error
 	Info(url *url.URL) (*ImgInfo, error)
 	Validate(*url.URL, int64, float64) error
-	CreateBlankImage(string, resource.Quantity) error
+	CreateBlankImage(string, resource.Quantity, bool) error
+	ConvertToQcow2(string, string) error
 }
 
 type qemuOperations struct{}
@@ -99,8 +127,31 @@ func NewQEMUOperations() QEMUOperations {
 	return &qemuOperations{}
 }
 
-func convertToRaw(src, dest string) error {
-	_, err := qemuExecFunction(nil, nil, "qemu-img", "convert", "-t", "none", "-p", "-O", "raw", src, dest)
+// preallocationMethods are the qemu-img options that fully allocate the target image, tried in order as not every
+// filesystem supports fallocate
+var preallocationMethods = [][]string{{"-o", "preallocation=falloc"}, {"-o", "preallocation=full"}}
+
+// execQemuImg runs qemu-img with args followed by targetArgs. When preallocation is requested the options of each
+// preallocation method are inserted before targetArgs, until a method succeeds.
+func execQemuImg(progress func(string), preallocation bool, args []string, targetArgs ...string) error {
+	if !preallocation {
+		_, err := qemuExecFunction(nil, progress, "qemu-img", append(args, targetArgs...)...)
+		return err
+	}
+	var err error
+	for _, method := range preallocationMethods {
+		methodArgs := append(append(append([]string{}, args...), method...), targetArgs...)
+		if _, err = qemuExecFunction(nil, progress, "qemu-img", methodArgs...); err == nil {
+			return nil
+		}
+		klog.Warningf("qemu-img failed with %s: %v", strings.Join(method, " "), err)
+	}
+	return err
+}
+
+func convertToRaw(src, dest string, preallocation bool) error {
+	args := []string{"convert", "-t", "none", "-p", "-O", "raw"}
+	err := execQemuImg(nil, preallocation, args, src, dest)
 	if err != nil {
 		os.Remove(dest)
 		return errors.Wrap(err, "could not convert image to raw")
@@ -109,15 +160,16 @@ func convertToRaw(src, dest string) error {
 	return nil
 }
 
-func (o *qemuOperations) ConvertToRawStream(url *url.URL, dest string) error {
+func (o *qemuOperations) ConvertToRawStream(url *url.URL, dest string, preallocation bool) error {
 	if len(url.Scheme) == 0 {
 		// File, instead of URL
-		return convertToRaw(url.String(), dest)
+		return convertToRaw(url.String(), dest, preallocation)
 	}
 
 	jsonArg := fmt.Sprintf("json: {\"file.driver\": \"%s\", \"file.url\": \"%s\", \"file.timeout\": %d}", url.Scheme, url, networkTimeoutSecs)
 
-	_, err := qemuExecFunction(nil, reportProgress, "qemu-img", "convert", "-t", "none", "-p", "-O", "raw", jsonArg, dest)
+	args := []string{"convert", "-t", "none", "-p", "-O", "raw"}
+	err := execQemuImg(reportProgress, preallocation, args, jsonArg, dest)
 	if err != nil {
 		// TODO: Determine what to do here, the conversion failed, and we need to clean up the mess, but we could be writing to a block device
 		os.Remove(dest)
@@ -145,6 +197,15 @@ func (o *qemuOperations) Resize(image string, size resource.Quantity) error {
 	return nil
 }
 
+func (o *qemuOperations) ConvertToQcow2(src, dest string) error {
+	_, err := qemuExecFunction(nil, reportProgress, "qemu-img", "convert", "-p", "-f", "raw", "-O", "qcow2", src, dest)
+	if err != nil {
+		os.Remove(dest)
+		return errors.Wrap(err, "could not convert raw image to qcow2")
+	}
+	return nil
+}
+
 func (o *qemuOperations) Info(url *url.URL) (*ImgInfo, error) {
 	var output []byte
 	var err error
@@ -168,15 +229,48 @@ func (o *qemuOperations) Info(url *url.URL) (*ImgInfo, error) {
 	return &info, nil
 }
 
+// isSupportedFormat returns true for the formats qemu-img can convert without reading anything but the image
+// itself. Note: vpc is the qemu name of the VHD format.
 func isSupportedFormat(value string) bool {
 	switch value {
-	case "raw", "qcow2":
+	case "raw", "qcow2", "vmdk", "vpc", "vhdx", "vdi":
 		return true
 	default:
 		return false
 	}
 }
 
+// isSupportedVmdkCreateType returns true for the vmdk subformats storing the descriptor and the data in the image
+// file, other subformats reference extents in separate files.
+func isSupportedVmdkCreateType(value string) bool {
+	switch value {
+	case "monolithicSparse", "streamOptimized":
+		return true
+	default:
+		return false
+	}
+}
+
+// validateExternalFiles makes sure the image does not reference files that qemu-img would open during the conversion.
+func validateExternalFiles(url *url.URL, info *ImgInfo) error {
+	if info.FormatSpecific == nil {
+		return nil
+	}
+	data := info.FormatSpecific.Data
+	if len(data.DataFile) > 0 {
+		return errors.Errorf("Image %s is invalid because it has external data file %s", url.String(), data.DataFile)
+	}
+	if info.Format == "vmdk" {
+		if !isSupportedVmdkCreateType(data.CreateType) {
+			return errors.Errorf("Invalid vmdk subformat %s for image %s, only monolithicSparse and streamOptimized are supported", data.CreateType, url.String())
+		}
+		if len(data.Extents) > 1 {
+			return errors.Errorf("Image %s is invalid because it has %d extents", url.String(), len(data.Extents))
+		}
+	}
+	return nil
+}
+
 func (o *qemuOperations) Validate(url *url.URL, availableSize int64, filesystemOverhead float64) error {
 	info, err := o.Info(url)
 	if err != nil {
@@ -191,6 +285,10 @@ func (o *qemuOperations) Validate(url *url.URL, availableSize int64, filesystemO
 		return errors.Errorf("Image %s is invalid because it has backing file %s", url.String(), info.BackingFile)
 	}
 
+	if err := validateExternalFiles(url, info); err != nil {
+		return err
+	}
+
 	if int64(float64(availableSize)*(1-filesystemOverhead)) < info.VirtualSize {
 		return errors.Errorf("Virtual image size %d is larger than available size %d (PVC size %d, reserved overhead %f%%). A larger PVC is required.", info.VirtualSize, int64((1-filesystemOverhead)*float64(availableSize)), info.VirtualSize, filesystemOverhead)
 	}
@@ -198,8 +296,13 @@ func (o *qemuOperations) Validate(url *url.URL, availableSize int64, filesystemO
 }
 
 // ConvertToRawStream converts an http accessible image to raw format without locally caching the image
-func ConvertToRawStream(url *url.URL, dest string) error {
-	return qemuIterface.ConvertToRawStream(url, dest)
+func ConvertToRawStream(url *url.URL, dest string, preallocation bool) error {
+	return qemuIterface.ConvertToRawStream(url, dest, preallocation)
+}
+
+// ConvertToQcow2 converts a local raw image or block device to a qcow2 image
+func ConvertToQcow2(src, dest string) error {
+	return qemuIterface.ConvertToQcow2(src, dest)
 }
 
 // Validate does basic validation of a qemu image
@@ -223,15 +326,16 @@ func reportProgress(line string) {
 }
 
 // CreateBlankImage creates empty raw image
-func CreateBlankImage(dest string, size resource.Quantity) error {
-	klog.V(1).Infof("creating raw image with size %s", size.String())
-	return qemuIterface.CreateBlankImage(dest, size)
+func CreateBlankImage(dest string, size resource.Quantity, preallocation bool) error {
+	klog.V(1).Infof("creating raw image with size %s, preallocation %v", size.String(), preallocation)
+	return qemuIterface.CreateBlankImage(dest, size, preallocation)
 }
 
 // CreateBlankImage creates a raw image with a given size
-func (o *qemuOperations) CreateBlankImage(dest string, size resource.Quantity) error {
+func (o *qemuOperations) CreateBlankImage(dest string, size resource.Quantity, preallocation bool) error {
 	klog.V(3).Infof("image size is %s", size.String())
-	_, err := qemuExecFunction(nil, nil, "qemu-img", "create", "-f", "raw", dest, convertQuantityToQemuSize(size))
+	args := []string{"create", "-f", "raw"}
+	err := execQemuImg(nil, preallocation, args, dest, convertQuantityToQemuSize(size))
 	if err != nil {
 		os.Remove(dest)
 		return errors.Wrap(err, fmt.Sprintf("could not create raw image with size %s in %s", size.String(), dest))
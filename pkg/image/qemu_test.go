@@ -119,6 +119,78 @@ const backingFileValidateJSON = `
 }
 `
 
+const vmdkValidateJSON = `
+{
+    "virtual-size": 4294967296,
+    "filename": "myimage.vmdk",
+    "cluster-size": 65536,
+    "format": "vmdk",
+    "actual-size": 262152192,
+    "format-specific": {
+        "type": "vmdk",
+        "data": {
+            "cid": 1595734946,
+            "parent-cid": 4294967295,
+            "create-type": "streamOptimized",
+            "extents": [
+                {
+                    "compressed": true,
+                    "virtual-size": 4294967296,
+                    "filename": "myimage.vmdk",
+                    "cluster-size": 65536,
+                    "format": ""
+                }
+            ]
+        }
+    },
+    "dirty-flag": false
+}
+`
+
+const vmdkFlatValidateJSON = `
+{
+    "virtual-size": 4294967296,
+    "filename": "myimage.vmdk",
+    "format": "vmdk",
+    "actual-size": 4096,
+    "format-specific": {
+        "type": "vmdk",
+        "data": {
+            "cid": 1595734946,
+            "parent-cid": 4294967295,
+            "create-type": "monolithicFlat",
+            "extents": [
+                {
+                    "virtual-size": 4294967296,
+                    "filename": "/etc/shadow",
+                    "format": "FLAT"
+                }
+            ]
+        }
+    },
+    "dirty-flag": false
+}
+`
+
+const dataFileValidateJSON = `
+{
+    "virtual-size": 4294967296,
+    "filename": "myimage.qcow2",
+    "cluster-size": 65536,
+    "format": "qcow2",
+    "actual-size": 262152192,
+    "format-specific": {
+        "type": "qcow2",
+        "data": {
+            "compat": "1.1",
+            "data-file": "/dev/sda",
+            "refcount-bits": 16
+        }
+    },
+    "dirty-flag": false
+}
+`
+
 type execFunctionType func(*system.ProcessLimitValues, func(string), string, ...string) ([]byte, error)
 
 func init() {
@@ -130,14 +202,14 @@ var expectedLimits = &system.ProcessLimitValues{AddressSpaceLimit: 1 << 30, CPUT
 var _ = Describe("Convert to Raw", func() {
 	It("should return no error if exec function returns no error", func() {
 		replaceExecFunction(mockExecFunction("", "", nil, "convert", "-p", "-O", "raw", "source", "dest"), func() {
-			err := convertToRaw("source", "dest")
+			err := convertToRaw("source", "dest", false)
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
 
 	It("should return conversion error if exec function returns error", func() {
 		replaceExecFunction(mockExecFunction("", "exit 1", nil, "convert", "-p", "-O", "raw", "source", "dest"), func() {
-			err := convertToRaw("source", "dest")
+			err := convertToRaw("source", "dest", false)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.Contains(err.Error(), "could not convert image to raw")).To(BeTrue())
 		})
@@ -147,7 +219,7 @@ var _ = Describe("Convert to Raw", func() {
 		replaceExecFunction(mockExecFunction("", "", nil, "convert", "-p", "-O", "raw", "/somefile/somewhere", "dest"), func() {
 			ep, err := url.Parse("/somefile/somewhere")
 			Expect(err).NotTo(HaveOccurred())
-			err = ConvertToRawStream(ep, "dest")
+			err = ConvertToRawStream(ep, "dest", false)
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -157,7 +229,16 @@ var _ = Describe("Convert to Raw", func() {
 		Expect(err).NotTo(HaveOccurred())
 		jsonArg := fmt.Sprintf("json: {\"file.driver\": \"%s\", \"file.url\": \"%s\", \"file.timeout\": %d}", ep.Scheme, ep, networkTimeoutSecs)
 		replaceExecFunction(mockExecFunction("", "", nil, "convert", "-p", "-O", "raw", jsonArg, "dest"), func() {
-			err = ConvertToRawStream(ep, "dest")
+			err = ConvertToRawStream(ep, "dest", false)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	It("should preallocate the destination when requested", func() {
+		replaceExecFunction(mockExecFunction("", "", nil, "convert", "-p", "-O", "raw", "-o", "preallocation=falloc", "/somefile/somewhere", "dest"), func() {
+			ep, err := url.Parse("/somefile/somewhere")
+			Expect(err).NotTo(HaveOccurred())
+			err = ConvertToRawStream(ep, "dest", true)
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -167,7 +248,7 @@ var _ = Describe("Convert to Raw", func() {
 		Expect(err).NotTo(HaveOccurred())
 		jsonArg := fmt.Sprintf("json: {\"file.driver\": \"%s\", \"file.url\": \"%s\", \"file.timeout\": %d}", ep.Scheme, ep, networkTimeoutSecs)
 		replaceExecFunction(mockExecFunction("", "exit 1", nil, "convert", "-p", "-O", "raw", jsonArg, "dest"), func() {
-			err := ConvertToRawStream(ep, "dest")
+			err := ConvertToRawStream(ep, "dest", false)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.Contains(err.Error(), "could not stream/convert image to raw")).To(BeTrue())
 		})
@@ -187,6 +268,24 @@ var _ = Describe("Resize", func() {
 		})
 	})
 
+	It("Should fall back to full preallocation when falloc fails", func() {
+		quantity, err := resource.ParseQuantity("10Gi")
+		Expect(err).NotTo(HaveOccurred())
+		var methods []string
+		replaceExecFunction(func(limits *system.ProcessLimitValues, f func(string), cmd string, args ...string) ([]byte, error) {
+			Expect(args[len(args)-2:]).To(Equal([]string{"image", convertQuantityToQemuSize(quantity)}))
+			methods = append(methods, args[len(args)-3])
+			if args[len(args)-3] == "preallocation=falloc" {
+				return nil, errors.New("Operation not supported")
+			}
+			return nil, nil
+		}, func() {
+			err = CreateBlankImage("image", quantity, true)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		Expect(methods).To(Equal([]string{"preallocation=falloc", "preallocation=full"}))
+	})
+
 	It("Should fail if qemu-img resize fails", func() {
 		quantity, err := resource.ParseQuantity("10Gi")
 		Expect(err).NotTo(HaveOccurred())
@@ -200,6 +299,23 @@ var _ = Describe("Resize", func() {
 	})
 })
 
+var _ = Describe("Convert to qcow2", func() {
+	It("Should complete successfully if qemu-img convert succeeds", func() {
+		replaceExecFunction(mockExecFunction("", "", nil, "convert", "-O", "qcow2", "source", "dest"), func() {
+			err := ConvertToQcow2("source", "dest")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	It("Should fail if qemu-img convert fails", func() {
+		replaceExecFunction(mockExecFunction("", "exit 1", nil, "convert", "-O", "qcow2", "source", "dest"), func() {
+			err := ConvertToQcow2("source", "dest")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not convert raw image to qcow2"))
+		})
+	})
+})
+
 var _ = Describe("Validate", func() {
 	imageName, _ := url.Parse("myimage.qcow2")
 	httpImage, _ := url.Parse("http://someurl/somewhere")
@@ -226,6 +342,9 @@ var _ = Describe("Validate", func() {
 		table.Entry("should return error on bad json", mockExecFunction(badValidateJSON, "", expectedLimits), "unexpected end of JSON input", imageName, 0.0),
 		table.Entry("should return error on bad format", mockExecFunction(badFormatValidateJSON, "", expectedLimits), fmt.Sprintf("Invalid format raw2 for image %s", imageName), imageName, 0.0),
 		table.Entry("should return error on invalid backing file", mockExecFunction(backingFileValidateJSON, "", expectedLimits), fmt.Sprintf("Image %s is invalid because it has backing file backing-file.qcow2", imageName), imageName, 0.0),
+		table.Entry("should return success on a vmdk image", mockExecFunction(vmdkValidateJSON, "", expectedLimits), "", imageName, 0.0),
+		table.Entry("should return error on a vmdk image with external extents", mockExecFunction(vmdkFlatValidateJSON, "", expectedLimits), fmt.Sprintf("Invalid vmdk subformat monolithicFlat for image %s, only monolithicSparse and streamOptimized are supported", imageName), imageName, 0.0),
+		table.Entry("should return error on external data file", mockExecFunction(dataFileValidateJSON, "", expectedLimits), fmt.Sprintf("Image %s is invalid because it has external data file /dev/sda", imageName), imageName, 0.0),
 		table.Entry("should return error when PVC is too small", mockExecFunction(hugeValidateJSON, "", expectedLimits), fmt.Sprintf("Virtual image size %d is larger than available size %d (PVC size %d, reserved overhead %f%%). A larger PVC is required.", 52949672960, 42949672960, 52949672960, 0.0), imageName, 0.0),
 		table.Entry("should return error when PVC is too small with overhead", mockExecFunction(hugeValidateJSON, "", expectedLimits), fmt.Sprintf("Virtual image size %d is larger than available size %d (PVC size %d, reserved overhead %f%%). A larger PVC is required.", 52949672960, 34359738368, 52949672960, 0.2), imageName, 0.2),
 	)
@@ -291,7 +410,17 @@ var _ = Describe("Create blank image", func() {
 		Expect(err).NotTo(HaveOccurred())
 		size := convertQuantityToQemuSize(quantity)
 		replaceExecFunction(mockExecFunction("", "", nil, "create", "-f", "raw", "image", size), func() {
-			err = CreateBlankImage("image", quantity)
+			err = CreateBlankImage("image", quantity, false)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	It("Should preallocate the image when requested", func() {
+		quantity, err := resource.ParseQuantity("10Gi")
+		Expect(err).NotTo(HaveOccurred())
+		size := convertQuantityToQemuSize(quantity)
+		replaceExecFunction(mockExecFunction("", "", nil, "create", "-f", "raw", "-o", "preallocation=falloc", "image", size), func() {
+			err = CreateBlankImage("image", quantity, true)
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -301,7 +430,7 @@ var _ = Describe("Create blank image", func() {
 		Expect(err).NotTo(HaveOccurred())
 		size := convertQuantityToQemuSize(quantity)
 		replaceExecFunction(mockExecFunction("", "exit 1", nil, "create", "-f", "raw", "image", size), func() {
-			err = CreateBlankImage("image", quantity)
+			err = CreateBlankImage("image", quantity, false)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.Contains(err.Error(), "could not create raw image with size ")).To(BeTrue())
 		})
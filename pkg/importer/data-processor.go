@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 
 	"github.com/pkg/errors"
 
@@ -75,6 +76,9 @@ var ErrInvalidPath = fmt.Errorf("invalid transfer path")
 // may be overridden in tests
 var getAvailableSpaceBlockFunc = util.GetAvailableSpaceBlock
 var getAvailableSpaceFunc = util.GetAvailableSpace
+var preallocateFileFunc = util.PreallocateFile
+var preallocateBlockFunc = util.PreallocateBlock
+var getBlockWritesFunc = util.GetBlockWrites
 
 // DataSourceInterface is the interface all data sources should implement.
 type DataSourceInterface interface {
@@ -90,6 +94,49 @@ type DataSourceInterface interface {
 	Close() error
 }
 
+// ResumableTransferDataSource is the interface of the data sources able to resume a transfer to scratch space
+// interrupted by a restart, using the files left in scratch space by the previous attempt.
+type ResumableTransferDataSource interface {
+	DataSourceInterface
+	// GetResumeFiles returns the names of the files in scratch space needed to resume the transfer.
+	GetResumeFiles() []string
+}
+
+// ResumableTargetTransferDataSource is the interface of the data sources able to resume a transfer to the target file
+// interrupted by a restart, using the partial target file and the files left next to it by the previous attempt.
+type ResumableTargetTransferDataSource interface {
+	DataSourceInterface
+	// GetTargetResumeFiles returns the names of the files in the directory of the target file dataFile needed to
+	// resume the transfer, none if the transfer can't be resumed.
+	GetTargetResumeFiles(dataFile string) []string
+}
+
+// StreamConvertDataSource is the interface of the data sources able to convert qcow2 data to raw while transferring it
+// to the target, instead of transferring it to scratch space for qemu-img to convert it.
+type StreamConvertDataSource interface {
+	DataSourceInterface
+	// GetQcow2Header returns the header of the qcow2 data if it can be converted while it is transferred, nil otherwise.
+	GetQcow2Header() *image.Qcow2Header
+	// TransferConvert is called to convert the data while transferring it to the file passed in. The data read before
+	// the qcow2 tables mapping it is buffered in spillDir, if not empty, once the memory buffer is full.
+	TransferConvert(fileName, spillDir string) (ProcessingPhase, error)
+}
+
+// FormatDataSource is the interface of the data sources reporting the format of the data they detected.
+type FormatDataSource interface {
+	DataSourceInterface
+	// GetFormat returns the format of the data, once detected by Info.
+	GetFormat() string
+}
+
+// ProcessingObserver is notified of the progress of a DataProcessor.
+type ProcessingObserver interface {
+	// PhaseChanged is called when the processor enters a phase, with ProcessingPhaseError if the processing fails.
+	PhaseChanged(phase ProcessingPhase)
+	// FormatDetected is called with the format of the data, if the data source reports it.
+	FormatDetected(format string)
+}
+
 //ResumableDataSource is the interface all resumeable data sources should implement
 type ResumableDataSource interface {
 	DataSourceInterface
@@ -108,16 +155,22 @@ type DataProcessor struct {
 	dataDir string
 	// scratchDataDir path to the scratch space.
 	scratchDataDir string
+	// observer is notified of the progress of the processing, if set.
+	observer ProcessingObserver
 	// requestImageSize is the size we want the resulting image to be.
 	requestImageSize string
 	// available space is the available space before downloading the image
 	availableSpace int64
 	// storage overhead is the amount of overhead of the storage used
 	filesystemOverhead float64
+	// preallocation is used to control whether the target should be fully allocated.
+	preallocation bool
+	// preallocationApplied is set when the target was fully allocated.
+	preallocationApplied bool
 }
 
 // NewDataProcessor create a new instance of a data processor using the passed in data provider.
-func NewDataProcessor(dataSource DataSourceInterface, dataFile, dataDir, scratchDataDir, requestImageSize string, filesystemOverhead float64) *DataProcessor {
+func NewDataProcessor(dataSource DataSourceInterface, dataFile, dataDir, scratchDataDir, requestImageSize string, filesystemOverhead float64, preallocation bool) *DataProcessor {
 	dp := &DataProcessor{
 		currentPhase:       ProcessingPhaseInfo,
 		source:             dataSource,
@@ -126,6 +179,7 @@ func NewDataProcessor(dataSource DataSourceInterface, dataFile, dataDir, scratch
 		scratchDataDir:     scratchDataDir,
 		requestImageSize:   requestImageSize,
 		filesystemOverhead: filesystemOverhead,
+		preallocation:      preallocation,
 	}
 	// Calculate available space before doing anything.
 	dp.availableSpace = dp.calculateTargetSize()
@@ -133,19 +187,35 @@ func NewDataProcessor(dataSource DataSourceInterface, dataFile, dataDir, scratch
 }
 
 // ProcessData is the main synchronous processing loop
-func (dp *DataProcessor) ProcessData() error {
+func (dp *DataProcessor) ProcessData() (err error) {
 	if size, _ := util.GetAvailableSpace(dp.scratchDataDir); size > int64(0) {
-		// Clean up before trying to write, in case a previous attempt left a mess. Note the deferred cleanup is intentional.
-		if err := CleanDir(dp.scratchDataDir); err != nil {
+		var keep []string
+		if rds, ok := dp.source.(ResumableTransferDataSource); ok {
+			keep = rds.GetResumeFiles()
+		}
+		// Clean up before trying to write, in case a previous attempt left a mess, but keep what is needed to resume
+		// an interrupted transfer. Note the deferred cleanup is intentional.
+		if err := cleanDirExcept(dp.scratchDataDir, keep...); err != nil {
 			return errors.Wrap(err, "Failure cleaning up temporary scratch space")
 		}
-		// Attempt to be a good citizen and clean up my mess at the end.
-		defer CleanDir(dp.scratchDataDir)
+		// Attempt to be a good citizen and clean up my mess at the end, unless the next attempt can resume the transfer.
+		defer func() {
+			if err != nil {
+				cleanDirExcept(dp.scratchDataDir, keep...)
+			} else {
+				CleanDir(dp.scratchDataDir)
+			}
+		}()
 	}
 
 	if size, _ := util.GetAvailableSpace(dp.dataDir); size > int64(0) {
-		// Clean up data dir before trying to write in case a previous attempt failed and left some stuff behind.
-		if err := CleanDir(dp.dataDir); err != nil {
+		var keep []string
+		if rds, ok := dp.source.(ResumableTargetTransferDataSource); ok && filepath.Dir(dp.dataFile) == filepath.Clean(dp.dataDir) {
+			keep = rds.GetTargetResumeFiles(dp.dataFile)
+		}
+		// Clean up data dir before trying to write in case a previous attempt failed and left some stuff behind, but
+		// keep what is needed to resume an interrupted transfer to the target.
+		if err := cleanDirExcept(dp.dataDir, keep...); err != nil {
 			return errors.Wrap(err, "Failure cleaning up target space")
 		}
 	}
@@ -167,13 +237,20 @@ func (dp *DataProcessor) ProcessDataResume() error {
 func (dp *DataProcessor) ProcessDataWithPause() error {
 	var err error
 	for dp.currentPhase != ProcessingPhaseComplete && dp.currentPhase != ProcessingPhasePause {
+		dp.notifyPhase(dp.currentPhase)
 		switch dp.currentPhase {
 		case ProcessingPhaseInfo:
 			dp.currentPhase, err = dp.source.Info()
 			if err != nil {
 				err = errors.Wrap(err, "Unable to obtain information about data source")
+			} else if source, ok := dp.source.(FormatDataSource); ok && dp.observer != nil {
+				dp.observer.FormatDetected(source.GetFormat())
 			}
 		case ProcessingPhaseTransferScratch:
+			if source, hdr := dp.streamConvertSource(); hdr != nil {
+				dp.currentPhase, err = dp.transferConvert(source, hdr)
+				break
+			}
 			dp.currentPhase, err = dp.source.Transfer(dp.scratchDataDir)
 			if err == ErrInvalidPath {
 				// Passed in invalid scratch space path, return scratch space needed error.
@@ -213,13 +290,70 @@ func (dp *DataProcessor) ProcessDataWithPause() error {
 		}
 		if err != nil {
 			klog.Errorf("%+v", err)
+			dp.notifyPhase(ProcessingPhaseError)
 			return err
 		}
 		klog.V(1).Infof("New phase: %s\n", dp.currentPhase)
 	}
+	if dp.currentPhase == ProcessingPhaseComplete && dp.preallocation {
+		err = dp.preallocate()
+		if err != nil {
+			klog.Errorf("%+v", err)
+			dp.notifyPhase(ProcessingPhaseError)
+			return err
+		}
+	}
+	dp.notifyPhase(dp.currentPhase)
 	return err
 }
 
+// SetObserver sets the observer notified of the progress of the processing.
+func (dp *DataProcessor) SetObserver(observer ProcessingObserver) {
+	dp.observer = observer
+}
+
+func (dp *DataProcessor) notifyPhase(phase ProcessingPhase) {
+	if dp.observer != nil {
+		dp.observer.PhaseChanged(phase)
+	}
+}
+
+// PreallocationApplied returns true if the target was fully allocated.
+func (dp *DataProcessor) PreallocationApplied() bool {
+	return dp.preallocationApplied
+}
+
+// preallocate allocates the holes left in the target file. On block devices the range written with a SparseWriter is
+// provisioned by the data and the zeroes written, the rest of the device is zeroed out.
+func (dp *DataProcessor) preallocate() error {
+	if size, _ := getAvailableSpaceBlockFunc(dp.dataFile); size >= int64(0) {
+		writes, ok := getBlockWritesFunc(dp.dataFile)
+		if !ok {
+			// qemu-img wrote the data, the zero ranges it skipped may not be provisioned.
+			klog.V(1).Infof("Unable to determine the range written to %s, skipping preallocation", dp.dataFile)
+			return nil
+		}
+		klog.V(1).Infof("Preallocating %s from %d", dp.dataFile, writes.End)
+		if err := preallocateBlockFunc(dp.dataFile, writes.End); err != nil {
+			return errors.Wrap(err, "Unable to preallocate target block device")
+		}
+		dp.preallocationApplied = true
+		return nil
+	}
+	info, err := os.Stat(dp.dataFile)
+	if err != nil || !info.Mode().IsRegular() {
+		// Nothing to preallocate, like the content of an archive.
+		klog.V(3).Infof("Skipping preallocation of %s", dp.dataFile)
+		return nil
+	}
+	klog.V(1).Infof("Preallocating %s", dp.dataFile)
+	if err := preallocateFileFunc(dp.dataFile); err != nil {
+		return errors.Wrap(err, "Unable to preallocate target file")
+	}
+	dp.preallocationApplied = true
+	return nil
+}
+
 func (dp *DataProcessor) validate(url *url.URL) error {
 	klog.V(1).Infoln("Validating image")
 	err := qemuOperations.Validate(url, dp.availableSpace, dp.filesystemOverhead)
@@ -229,6 +363,47 @@ func (dp *DataProcessor) validate(url *url.URL) error {
 	return nil
 }
 
+// streamConvertSource returns the data source and the header of its qcow2 data, if the data can be converted while it is
+// transferred to the target.
+func (dp *DataProcessor) streamConvertSource() (StreamConvertDataSource, *image.Qcow2Header) {
+	source, ok := dp.source.(StreamConvertDataSource)
+	if !ok {
+		return nil, nil
+	}
+	return source, source.GetQcow2Header()
+}
+
+// transferConvert converts the qcow2 data while transferring it to the target, so it doesn't need to be transferred to
+// scratch space first. The data read before the qcow2 tables mapping it is buffered in memory, and in scratch space if
+// it is available. When the memory buffer is full and there is no scratch space, scratch space is requested.
+func (dp *DataProcessor) transferConvert(source StreamConvertDataSource, hdr *image.Qcow2Header) (ProcessingPhase, error) {
+	if err := dp.validateSize(int64(hdr.Size)); err != nil {
+		return ProcessingPhaseError, err
+	}
+	spillDir := ""
+	if size, _ := util.GetAvailableSpace(dp.scratchDataDir); size > int64(0) {
+		spillDir = dp.scratchDataDir
+	}
+	klog.V(1).Infoln("Converting qcow2 data to raw while transferring it")
+	phase, err := source.TransferConvert(dp.dataFile, spillDir)
+	if err != nil {
+		if errors.Cause(err) == image.ErrQcow2NeedsScratch && spillDir == "" {
+			return ProcessingPhaseError, ErrRequiresScratchSpace
+		}
+		return ProcessingPhaseError, errors.Wrap(err, "Unable to convert source data to target format while transferring it")
+	}
+	return phase, nil
+}
+
+// validateSize checks the virtual size of the image fits in the target, like the validation of qemu-img converted images.
+func (dp *DataProcessor) validateSize(virtualSize int64) error {
+	available := int64(float64(dp.availableSpace) * (1 - dp.filesystemOverhead))
+	if available < virtualSize {
+		return ValidationSizeError{err: errors.Errorf("Virtual image size %d is larger than available size %d (PVC size %d, reserved overhead %f%%). A larger PVC is required.", virtualSize, available, dp.availableSpace, dp.filesystemOverhead)}
+	}
+	return nil
+}
+
 // convert is called when convert the image from the url to a RAW disk image. Source formats include RAW/QCOW2 (Raw to raw conversion is a copy)
 func (dp *DataProcessor) convert(url *url.URL) (ProcessingPhase, error) {
 	err := dp.validate(url)
@@ -236,7 +411,7 @@ func (dp *DataProcessor) convert(url *url.URL) (ProcessingPhase, error) {
 		return ProcessingPhaseError, err
 	}
 	klog.V(3).Infoln("Converting to Raw")
-	err = qemuOperations.ConvertToRawStream(url, dp.dataFile)
+	err = qemuOperations.ConvertToRawStream(url, dp.dataFile, dp.preallocation)
 	if err != nil {
 		return ProcessingPhaseError, errors.Wrap(err, "Conversion to Raw failed")
 	}
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"net/url"
 	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
@@ -14,6 +15,7 @@ import (
 	"github.com/pkg/errors"
 
 	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
 type fakeInfoOpRetVal struct {
@@ -95,6 +97,69 @@ func (m *MockDataProvider) Close() error {
 	return nil
 }
 
+type MockResumableTransferDataProvider struct {
+	MockDataProvider
+}
+
+// GetResumeFiles returns the files needed to resume the transfer.
+func (m *MockResumableTransferDataProvider) GetResumeFiles() []string {
+	return []string{"partial"}
+}
+
+type MockResumableTargetTransferDataProvider struct {
+	MockDataProvider
+}
+
+// GetTargetResumeFiles returns the files needed to resume the transfer to the target.
+func (m *MockResumableTargetTransferDataProvider) GetTargetResumeFiles(dataFile string) []string {
+	return []string{filepath.Base(dataFile), filepath.Base(dataFile) + ".state"}
+}
+
+type MockStreamConvertDataProvider struct {
+	MockDataProvider
+	qcow2Header *image.Qcow2Header
+	convertErr  error
+	spillDir    string
+}
+
+// GetQcow2Header returns the header of the qcow2 data if it can be converted while it is transferred.
+func (m *MockStreamConvertDataProvider) GetQcow2Header() *image.Qcow2Header {
+	return m.qcow2Header
+}
+
+// TransferConvert is called to convert the data while transferring it to the passed in file.
+func (m *MockStreamConvertDataProvider) TransferConvert(fileName, spillDir string) (ProcessingPhase, error) {
+	m.calledPhases = append(m.calledPhases, ProcessingPhaseTransferScratch)
+	m.transferFile = fileName
+	m.spillDir = spillDir
+	if m.convertErr != nil {
+		return ProcessingPhaseError, m.convertErr
+	}
+	return m.transferResponse, nil
+}
+
+type MockFormatDataProvider struct {
+	MockDataProvider
+}
+
+// GetFormat returns the format of the data.
+func (m *MockFormatDataProvider) GetFormat() string {
+	return "qcow2"
+}
+
+type fakeProcessingObserver struct {
+	phases []ProcessingPhase
+	format string
+}
+
+func (o *fakeProcessingObserver) PhaseChanged(phase ProcessingPhase) {
+	o.phases = append(o.phases, phase)
+}
+
+func (o *fakeProcessingObserver) FormatDetected(format string) {
+	o.format = format
+}
+
 type MockAsyncDataProvider struct {
 	MockDataProvider
 	ResumePhase ProcessingPhase
@@ -136,7 +201,7 @@ var _ = Describe("Data Processor", func() {
 			infoResponse:     ProcessingPhaseTransferScratch,
 			transferResponse: ProcessingPhaseComplete,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		err := dp.ProcessData()
 		Expect(err).ToNot(HaveOccurred())
 		Expect(2).To(Equal(len(mdp.calledPhases)))
@@ -150,7 +215,7 @@ var _ = Describe("Data Processor", func() {
 			infoResponse:     ProcessingPhaseTransferDataDir,
 			transferResponse: ProcessingPhaseComplete,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		err := dp.ProcessData()
 		Expect(err).ToNot(HaveOccurred())
 		Expect(2).To(Equal(len(mdp.calledPhases)))
@@ -164,7 +229,7 @@ var _ = Describe("Data Processor", func() {
 			infoResponse:     ProcessingPhaseTransferScratch,
 			transferResponse: ProcessingPhaseError,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		err := dp.ProcessData()
 		Expect(err).To(HaveOccurred())
 		Expect(2).To(Equal(len(mdp.calledPhases)))
@@ -172,13 +237,88 @@ var _ = Describe("Data Processor", func() {
 		Expect(ProcessingPhaseTransferScratch).To(Equal(mdp.calledPhases[1]))
 	})
 
+	It("should notify the observer of the phases and the format", func() {
+		mdp := &MockFormatDataProvider{
+			MockDataProvider: MockDataProvider{
+				infoResponse:     ProcessingPhaseTransferScratch,
+				transferResponse: ProcessingPhaseComplete,
+			},
+		}
+		observer := &fakeProcessingObserver{}
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
+		dp.SetObserver(observer)
+		Expect(dp.ProcessData()).To(Succeed())
+		Expect(observer.phases).To(Equal([]ProcessingPhase{ProcessingPhaseInfo, ProcessingPhaseTransferScratch, ProcessingPhaseComplete}))
+		Expect(observer.format).To(Equal("qcow2"))
+	})
+
+	It("should notify the observer of the failure", func() {
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferScratch,
+			transferResponse: ProcessingPhaseError,
+		}
+		observer := &fakeProcessingObserver{}
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
+		dp.SetObserver(observer)
+		Expect(dp.ProcessData()).ToNot(Succeed())
+		Expect(observer.phases).To(Equal([]ProcessingPhase{ProcessingPhaseInfo, ProcessingPhaseTransferScratch, ProcessingPhaseError}))
+		Expect(observer.format).To(BeEmpty())
+	})
+
+	It("should keep the files needed to resume the transfer when Transfer fails", func() {
+		scratchDir, err := ioutil.TempDir("", "scratch")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(scratchDir)
+		for _, name := range []string{"partial", "other"} {
+			Expect(ioutil.WriteFile(filepath.Join(scratchDir, name), []byte("data"), 0644)).To(Succeed())
+		}
+		mdp := &MockResumableTransferDataProvider{
+			MockDataProvider: MockDataProvider{
+				infoResponse:     ProcessingPhaseTransferScratch,
+				transferResponse: ProcessingPhaseError,
+			},
+		}
+		dp := NewDataProcessor(mdp, "dest", "dataDir", scratchDir, "1G", 0.055, false)
+		err = dp.ProcessData()
+		Expect(err).To(HaveOccurred())
+		Expect(scratchDir).To(Equal(mdp.transferPath))
+		_, err = os.Stat(filepath.Join(scratchDir, "partial"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = os.Stat(filepath.Join(scratchDir, "other"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should keep the files needed to resume the transfer to the target", func() {
+		dataDir, err := ioutil.TempDir("", "data")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dataDir)
+		for _, name := range []string{"disk.img", "disk.img.state", "other"} {
+			Expect(ioutil.WriteFile(filepath.Join(dataDir, name), []byte("data"), 0644)).To(Succeed())
+		}
+		mdp := &MockResumableTargetTransferDataProvider{
+			MockDataProvider: MockDataProvider{
+				infoResponse:     ProcessingPhaseTransferDataFile,
+				transferResponse: ProcessingPhaseError,
+			},
+		}
+		dp := NewDataProcessor(mdp, filepath.Join(dataDir, "disk.img"), dataDir, "scratchDataDir", "1G", 0.055, false)
+		err = dp.ProcessData()
+		Expect(err).To(HaveOccurred())
+		for _, name := range []string{"disk.img", "disk.img.state"} {
+			_, err = os.Stat(filepath.Join(dataDir, name))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		_, err = os.Stat(filepath.Join(dataDir, "other"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
 	It("should error on Transfer phase if scratch space is required", func() {
 		mdp := &MockDataProvider{
 			infoResponse:     ProcessingPhaseTransferScratch,
 			transferResponse: ProcessingPhaseError,
 			needsScratch:     true,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		err := dp.ProcessData()
 		Expect(err).To(HaveOccurred())
 		Expect(ErrRequiresScratchSpace).To(Equal(err))
@@ -187,12 +327,76 @@ var _ = Describe("Data Processor", func() {
 		Expect(ProcessingPhaseTransferScratch).To(Equal(mdp.calledPhases[1]))
 	})
 
+	Context("with a source converting qcow2 data while transferring it", func() {
+		var mdp *MockStreamConvertDataProvider
+
+		BeforeEach(func() {
+			mdp = &MockStreamConvertDataProvider{
+				MockDataProvider: MockDataProvider{
+					infoResponse:     ProcessingPhaseTransferScratch,
+					transferResponse: ProcessingPhaseComplete,
+				},
+				qcow2Header: &image.Qcow2Header{Size: 1024 * 1024},
+			}
+		})
+
+		newDataProcessor := func(scratchDir string) *DataProcessor {
+			var dp *DataProcessor
+			replaceAvailableSpaceBlockFunc(func(dataDir string) (int64, error) {
+				return int64(1024 * 1024 * 1024), nil
+			}, func() {
+				dp = NewDataProcessor(mdp, "dest", "dataDir", scratchDir, "", 0.055, false)
+			})
+			return dp
+		}
+
+		It("should convert the data without scratch space", func() {
+			dp := newDataProcessor("scratchDataDir")
+			Expect(dp.ProcessData()).To(Succeed())
+			Expect(mdp.calledPhases).To(Equal([]ProcessingPhase{ProcessingPhaseInfo, ProcessingPhaseTransferScratch}))
+			Expect(mdp.transferFile).To(Equal("dest"))
+			Expect(mdp.transferPath).To(BeEmpty())
+			Expect(mdp.spillDir).To(BeEmpty())
+		})
+
+		It("should buffer the data in scratch space when it is available", func() {
+			scratchDir, err := ioutil.TempDir("", "scratch")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(scratchDir)
+			dp := newDataProcessor(scratchDir)
+			Expect(dp.ProcessData()).To(Succeed())
+			Expect(mdp.spillDir).To(Equal(scratchDir))
+		})
+
+		It("should require scratch space when the data can't be buffered in memory", func() {
+			mdp.convertErr = errors.Wrap(image.ErrQcow2NeedsScratch, "unable to convert")
+			dp := newDataProcessor("scratchDataDir")
+			Expect(dp.ProcessData()).To(Equal(ErrRequiresScratchSpace))
+		})
+
+		It("should fail if the virtual size is larger than the target", func() {
+			mdp.qcow2Header.Size = 2 * 1024 * 1024 * 1024
+			dp := newDataProcessor("scratchDataDir")
+			err := dp.ProcessData()
+			Expect(err).To(BeAssignableToTypeOf(ValidationSizeError{}))
+			Expect(mdp.calledPhases).To(Equal([]ProcessingPhase{ProcessingPhaseInfo}))
+		})
+
+		It("should transfer the data to scratch space if it can't be converted while it is transferred", func() {
+			mdp.qcow2Header = nil
+			mdp.transferResponse = ProcessingPhaseError
+			dp := newDataProcessor("scratchDataDir")
+			Expect(dp.ProcessData()).ToNot(Succeed())
+			Expect(mdp.transferPath).To(Equal("scratchDataDir"))
+		})
+	})
+
 	It("should call the right phases based on the responses from the provider, TransferDataFile should pass the data file", func() {
 		mdp := &MockDataProvider{
 			infoResponse:     ProcessingPhaseTransferDataFile,
 			transferResponse: ProcessingPhaseComplete,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, errors.New("Scratch space required, and none found ")}, nil, nil, nil)
 		replaceQEMUOperations(qemuOperations, func() {
 			err := dp.ProcessData()
@@ -208,7 +412,7 @@ var _ = Describe("Data Processor", func() {
 			infoResponse:     ProcessingPhaseTransferDataFile,
 			transferResponse: ProcessingPhaseError,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		qemuOperations := NewQEMUAllErrors()
 		replaceQEMUOperations(qemuOperations, func() {
 			err := dp.ProcessData()
@@ -223,7 +427,7 @@ var _ = Describe("Data Processor", func() {
 		mdp := &MockDataProvider{
 			infoResponse: ProcessingPhase("invalidphase"),
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		err := dp.ProcessData()
 		Expect(err).To(HaveOccurred())
 		Expect(1).To(Equal(len(mdp.calledPhases)))
@@ -242,7 +446,7 @@ var _ = Describe("Data Processor", func() {
 			transferResponse: ProcessingPhaseConvert,
 			url:              url,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", tmpDir, "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", tmpDir, "1G", 0.055, false)
 		dp.availableSpace = int64(1500)
 		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoRet, nil, nil, resource.NewScaledQuantity(int64(1500), 0))
 		replaceQEMUOperations(qemuOperations, func() {
@@ -263,7 +467,7 @@ var _ = Describe("Convert", func() {
 		mdp := &MockDataProvider{
 			url: url,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, errors.New("Scratch space required, and none found ")}, nil, nil, nil)
 		replaceQEMUOperations(qemuOperations, func() {
 			nextPhase, err := dp.convert(mdp.GetURL())
@@ -278,7 +482,7 @@ var _ = Describe("Convert", func() {
 		mdp := &MockDataProvider{
 			url: url,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, errors.New("Scratch space required, and none found ")}, errors.New("Validation failure"), nil, nil)
 		replaceQEMUOperations(qemuOperations, func() {
 			nextPhase, err := dp.convert(mdp.GetURL())
@@ -293,7 +497,7 @@ var _ = Describe("Convert", func() {
 		mdp := &MockDataProvider{
 			url: url,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 		qemuOperations := NewFakeQEMUOperations(errors.New("Conversion failure"), nil, fakeInfoOpRetVal{&fakeZeroImageInfo, errors.New("Scratch space required, and none found ")}, nil, nil, nil)
 		replaceQEMUOperations(qemuOperations, func() {
 			nextPhase, err := dp.convert(mdp.GetURL())
@@ -310,7 +514,7 @@ var _ = Describe("Resize", func() {
 		mdp := &MockDataProvider{
 			url: url,
 		}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055, false)
 		nextPhase, err := dp.resize()
 		Expect(err).ToNot(HaveOccurred())
 		Expect(ProcessingPhaseComplete).To(Equal(nextPhase))
@@ -326,7 +530,7 @@ var _ = Describe("Resize", func() {
 			mdp := &MockDataProvider{
 				url: url,
 			}
-			dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055)
+			dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
 			nextPhase, err := dp.resize()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(ProcessingPhaseComplete).To(Equal(nextPhase))
@@ -341,7 +545,7 @@ var _ = Describe("Resize", func() {
 		mdp := &MockDataProvider{
 			url: url,
 		}
-		dp := NewDataProcessor(mdp, "dest", tmpDir, "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", tmpDir, "scratchDataDir", "1G", 0.055, false)
 		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, nil}, nil, nil, nil)
 		replaceQEMUOperations(qemuOperations, func() {
 			nextPhase, err := dp.resize()
@@ -358,7 +562,7 @@ var _ = Describe("Resize", func() {
 		mdp := &MockDataProvider{
 			url: url,
 		}
-		dp := NewDataProcessor(mdp, "dest", tmpDir, "scratchDataDir", "1G", 0.055)
+		dp := NewDataProcessor(mdp, "dest", tmpDir, "scratchDataDir", "1G", 0.055, false)
 		qemuOperations := NewQEMUAllErrors()
 		replaceQEMUOperations(qemuOperations, func() {
 			nextPhase, err := dp.resize()
@@ -372,7 +576,7 @@ var _ = Describe("Resize", func() {
 			return int64(100000), nil
 		}, func() {
 			mdp := &MockDataProvider{}
-			dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055)
+			dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055, false)
 			Expect(int64(100000)).To(Equal(dp.calculateTargetSize()))
 		})
 	})
@@ -382,7 +586,7 @@ var _ = Describe("Resize", func() {
 			return int64(-1), errors.New("error")
 		}, func() {
 			mdp := &MockDataProvider{}
-			dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055)
+			dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055, false)
 			// We just log the error if one happens.
 			Expect(int64(-1)).To(Equal(dp.calculateTargetSize()))
 
@@ -390,6 +594,132 @@ var _ = Describe("Resize", func() {
 	})
 })
 
+var _ = Describe("Preallocation", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "data")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Should preallocate the target file when requested", func() {
+		dataFile := filepath.Join(tmpDir, "disk.img")
+		Expect(ioutil.WriteFile(dataFile, []byte("data"), 0644)).To(Succeed())
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataFile,
+			transferResponse: ProcessingPhaseComplete,
+		}
+		preallocated := ""
+		replacePreallocateFileFunc(func(path string) error {
+			preallocated = path
+			return nil
+		}, func() {
+			dp := NewDataProcessor(mdp, dataFile, tmpDir, "scratchDataDir", "", 0.055, true)
+			err := dp.ProcessDataWithPause()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(preallocated).To(Equal(dataFile))
+			Expect(dp.PreallocationApplied()).To(BeTrue())
+		})
+	})
+
+	It("Should not preallocate the target file unless requested", func() {
+		dataFile := filepath.Join(tmpDir, "disk.img")
+		Expect(ioutil.WriteFile(dataFile, []byte("data"), 0644)).To(Succeed())
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataFile,
+			transferResponse: ProcessingPhaseComplete,
+		}
+		replacePreallocateFileFunc(func(path string) error {
+			Fail("preallocation was not requested")
+			return nil
+		}, func() {
+			dp := NewDataProcessor(mdp, dataFile, tmpDir, "scratchDataDir", "", 0.055, false)
+			err := dp.ProcessDataWithPause()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dp.PreallocationApplied()).To(BeFalse())
+		})
+	})
+
+	It("Should not apply preallocation if the target is not a file", func() {
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataDir,
+			transferResponse: ProcessingPhaseComplete,
+		}
+		dp := NewDataProcessor(mdp, filepath.Join(tmpDir, "disk.img"), tmpDir, "scratchDataDir", "", 0.055, true)
+		err := dp.ProcessDataWithPause()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dp.PreallocationApplied()).To(BeFalse())
+	})
+
+	It("Should zero out the block device past the written range", func() {
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataFile,
+			transferResponse: ProcessingPhaseComplete,
+		}
+		var preallocatedFrom int64 = -1
+		replaceAvailableSpaceBlockFunc(func(string) (int64, error) {
+			return 1024, nil
+		}, func() {
+			replacePreallocateBlockFuncs(func(string) (util.BlockWrites, bool) {
+				return util.BlockWrites{BytesWritten: 100, End: 512}, true
+			}, func(path string, offset int64) error {
+				preallocatedFrom = offset
+				return nil
+			}, func() {
+				dp := NewDataProcessor(mdp, "/dev/cdi-block-volume", tmpDir, "scratchDataDir", "", 0.055, true)
+				err := dp.ProcessDataWithPause()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(preallocatedFrom).To(Equal(int64(512)))
+				Expect(dp.PreallocationApplied()).To(BeTrue())
+			})
+		})
+	})
+
+	It("Should not apply preallocation to a block device written by qemu-img", func() {
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataFile,
+			transferResponse: ProcessingPhaseComplete,
+		}
+		replaceAvailableSpaceBlockFunc(func(string) (int64, error) {
+			return 1024, nil
+		}, func() {
+			replacePreallocateBlockFuncs(func(string) (util.BlockWrites, bool) {
+				return util.BlockWrites{}, false
+			}, func(path string, offset int64) error {
+				Fail("the written range is unknown")
+				return nil
+			}, func() {
+				dp := NewDataProcessor(mdp, "/dev/cdi-block-volume", tmpDir, "scratchDataDir", "", 0.055, true)
+				err := dp.ProcessDataWithPause()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(dp.PreallocationApplied()).To(BeFalse())
+			})
+		})
+	})
+
+	It("Should fail if preallocation fails", func() {
+		dataFile := filepath.Join(tmpDir, "disk.img")
+		Expect(ioutil.WriteFile(dataFile, []byte("data"), 0644)).To(Succeed())
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataFile,
+			transferResponse: ProcessingPhaseComplete,
+		}
+		replacePreallocateFileFunc(func(path string) error {
+			return errors.New("no space left")
+		}, func() {
+			dp := NewDataProcessor(mdp, dataFile, tmpDir, "scratchDataDir", "", 0.055, true)
+			err := dp.ProcessDataWithPause()
+			Expect(err).To(HaveOccurred())
+			Expect(dp.PreallocationApplied()).To(BeFalse())
+		})
+	})
+})
+
 var _ = Describe("ResizeImage", func() {
 	//fakeInfoRet has info.VirtualSize=1024
 	table.DescribeTable("calling ResizeImage", func(qemuOperations image.QEMUOperations, imageSize string, totalSpace int64, wantErr bool) {
@@ -413,7 +743,7 @@ var _ = Describe("ResizeImage", func() {
 var _ = Describe("DataProcessorResume", func() {
 	It("Should fail with an error if the data provider cannot resume", func() {
 		mdp := &MockDataProvider{}
-		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055, false)
 		err := dp.ProcessDataResume()
 		Expect(err).To(HaveOccurred())
 	})
@@ -422,7 +752,7 @@ var _ = Describe("DataProcessorResume", func() {
 		amdp := &MockAsyncDataProvider{
 			ResumePhase: ProcessingPhaseComplete,
 		}
-		dp := NewDataProcessor(amdp, "dest", "dataDir", "scratchDataDir", "", 0.055)
+		dp := NewDataProcessor(amdp, "dest", "dataDir", "scratchDataDir", "", 0.055, false)
 		err := dp.ProcessDataResume()
 		Expect(err).ToNot(HaveOccurred())
 	})
@@ -441,7 +771,7 @@ func NewFakeQEMUOperations(e2, e3 error, ret4 fakeInfoOpRetVal, e5 error, e6 err
 	return &fakeQEMUOperations{e2, e3, ret4, e5, e6, targetResize}
 }
 
-func (o *fakeQEMUOperations) ConvertToRawStream(*url.URL, string) error {
+func (o *fakeQEMUOperations) ConvertToRawStream(*url.URL, string, bool) error {
 	return o.e2
 }
 
@@ -460,10 +790,14 @@ func (o *fakeQEMUOperations) Info(url *url.URL) (*image.ImgInfo, error) {
 	return o.ret4.imgInfo, o.ret4.e
 }
 
-func (o *fakeQEMUOperations) CreateBlankImage(dest string, size resource.Quantity) error {
+func (o *fakeQEMUOperations) CreateBlankImage(dest string, size resource.Quantity, preallocate bool) error {
 	return o.e6
 }
 
+func (o *fakeQEMUOperations) ConvertToQcow2(src, dest string) error {
+	return nil
+}
+
 func NewQEMUAllErrors() image.QEMUOperations {
 	err := errors.New("qemu should not be called from this test override with replaceQEMUOperations")
 	return NewFakeQEMUOperations(err, err, fakeInfoOpRetVal{nil, err}, err, err, nil)
@@ -486,3 +820,21 @@ func replaceAvailableSpaceFunc(replacement func(string) (int64, error), f func()
 	}()
 	f()
 }
+
+func replacePreallocateFileFunc(replacement func(string) error, f func()) {
+	origFunc := preallocateFileFunc
+	preallocateFileFunc = replacement
+	defer func() {
+		preallocateFileFunc = origFunc
+	}()
+	f()
+}
+
+func replacePreallocateBlockFuncs(getWrites func(string) (util.BlockWrites, bool), preallocate func(string, int64) error, f func()) {
+	origGetWrites, origPreallocate := getBlockWritesFunc, preallocateBlockFunc
+	getBlockWritesFunc, preallocateBlockFunc = getWrites, preallocate
+	defer func() {
+		getBlockWritesFunc, preallocateBlockFunc = origGetWrites, origPreallocate
+	}()
+	f()
+}
@@ -18,12 +18,15 @@ package importer
 
 import (
 	"bytes"
+	"compress/bzip2"
 	"compress/gzip"
 	"encoding/hex"
 	"io"
 	"io/ioutil"
 	"strconv"
+	"strings"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/pkg/errors"
 	"github.com/ulikunitz/xz"
 
@@ -73,6 +76,13 @@ type FormatReaders struct {
 	Convert        bool
 	Archived       bool
 	progressReader *prometheusutil.ProgressReader
+	checksumReader *util.ChecksumReader
+	// qcow2Header is the header of the qcow2 image read, if it can be converted as it is read.
+	qcow2Header *image.Qcow2Header
+	// formats are the formats of the headers found, outermost first.
+	formats []string
+	// imageSize is the virtual size read from the header of the disk image, 0 if it is unknown.
+	imageSize int64
 }
 
 const (
@@ -80,6 +90,9 @@ const (
 	rdrMulti
 	rdrXz
 	rdrStream
+	rdrZst
+	rdrBz2
+	rdrLz4
 )
 
 // map scheme and format to rdrType
@@ -87,14 +100,25 @@ var rdrTypM = map[string]int{
 	"gz":     rdrGz,
 	"xz":     rdrXz,
 	"stream": rdrStream,
+	"zst":    rdrZst,
+	"bz2":    rdrBz2,
+	"lz4":    rdrLz4,
 }
 
 // NewFormatReaders creates a new instance of FormatReaders using the input stream and content type passed in.
-func NewFormatReaders(stream io.ReadCloser, total uint64) (*FormatReaders, error) {
+// If checksum is not empty, the raw stream is hashed as it is read, so it can be checked with VerifyChecksum.
+func NewFormatReaders(stream io.ReadCloser, total uint64, checksum string) (*FormatReaders, error) {
 	var err error
 	readers := &FormatReaders{
 		buf: make([]byte, image.MaxExpectedHdrSize),
 	}
+	if checksum != "" {
+		readers.checksumReader, err = util.NewChecksumReader(stream, checksum)
+		if err != nil {
+			return readers, err
+		}
+		stream = readers.checksumReader
+	}
 	if total > uint64(0) {
 		readers.progressReader = prometheusutil.NewProgressReader(stream, total, progress, ownerUID)
 		err = readers.constructReaders(readers.progressReader)
@@ -119,8 +143,8 @@ func (fr *FormatReaders) constructReaders(r io.ReadCloser) error {
 		klog.V(2).Infof("found header of type %q\n", hdr.Format)
 		// create format-specific reader and append it to dataStream readers stack
 		fr.fileFormatSelector(hdr)
-		// exit loop if hdr is qcow2
-		if hdr.Format == "qcow2" {
+		// exit loop if hdr is a disk image format that needs conversion, its content is not an archive
+		if fr.Convert {
 			break
 		}
 	}
@@ -155,11 +179,13 @@ func (fr *FormatReaders) TopReader() io.ReadCloser {
 }
 
 // Based on the passed in header, append the format-specific reader to the readers stack,
-// and update the receiver Size field. Note: a bool is set in the receiver for qcow2 files.
+// and update the receiver Size field. Note: a bool is set in the receiver for disk image formats
+// that qemu-img needs to convert, qcow2, vmdk, vhd, vhdx and vdi.
 func (fr *FormatReaders) fileFormatSelector(hdr *image.Header) {
 	var r io.Reader
 	var err error
 	fFmt := hdr.Format
+	fr.formats = append(fr.formats, fFmt)
 	switch fFmt {
 	case "gz":
 		r, err = fr.gzReader()
@@ -169,15 +195,34 @@ func (fr *FormatReaders) fileFormatSelector(hdr *image.Header) {
 	case "qcow2":
 		r, err = fr.qcow2NopReader(hdr)
 		fr.Convert = true
+	case "vmdk", "vhd", "vhdx", "vdi":
+		// No reader is needed, qemu-img reads these formats directly.
+		fr.Convert = true
 	case "xz":
 		r, err = fr.xzReader()
 		if err == nil {
 			fr.Archived = true
 		}
+	case "zst":
+		r, err = fr.zstReader()
+		if err == nil {
+			fr.Archived = true
+		}
+	case "bz2":
+		r = fr.bz2Reader()
+		fr.Archived = true
+	case "lz4":
+		r = fr.lz4Reader()
+		fr.Archived = true
 	}
 	if err == nil && r != nil {
 		fr.appendReader(rdrTypM[fFmt], r)
 	}
+	if fr.Convert {
+		if fr.imageSize, err = hdr.Size(fr.buf); err != nil {
+			klog.V(2).Infof("unable to read the virtual size of the %s image: %v\n", fFmt, err)
+		}
+	}
 }
 
 // Return the gz reader and the size of the endpoint "through the eye" of the previous reader.
@@ -203,6 +248,9 @@ func (fr *FormatReaders) qcow2NopReader(h *image.Header) (io.Reader, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to determine original qcow2 file size from %+v", s)
 	}
+	if fr.qcow2Header, err = image.ParseQcow2Header(fr.buf); err != nil {
+		klog.V(2).Infof("qcow2 image can't be converted as it is read: %v\n", err)
+	}
 	return nil, nil
 }
 
@@ -220,6 +268,29 @@ func (fr *FormatReaders) xzReader() (io.Reader, error) {
 	return xz, nil
 }
 
+// Return the zstd reader "through the eye" of the previous reader. The decoder is wrapped so closing the reader
+// releases its resources.
+//NOTE: the size may be stored in the zstd frame header, but it is optional. For now 0 is returned.
+func (fr *FormatReaders) zstReader() (io.ReadCloser, error) {
+	zst, err := zstd.NewReader(fr.TopReader())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create zstd reader")
+	}
+	return zst.IOReadCloser(), nil
+}
+
+// Return the bzip2 reader "through the eye" of the previous reader.
+//NOTE: size is not stored in the bzip2 header. For now 0 is returned.
+func (fr *FormatReaders) bz2Reader() io.Reader {
+	return bzip2.NewReader(fr.TopReader())
+}
+
+// Return the lz4 reader "through the eye" of the previous reader.
+//NOTE: the size may be stored in the lz4 frame header, but it is optional. For now 0 is returned.
+func (fr *FormatReaders) lz4Reader() io.Reader {
+	return newLz4Reader(fr.TopReader())
+}
+
 // Return the matching header, if one is found, from the passed-in map of known headers. After a
 // successful read append a multi-reader to the receiver's reader stack.
 // Note: .iso files are not detected here but rather in the Size() function.
@@ -229,8 +300,9 @@ func (fr *FormatReaders) matchHeader(knownHdrs *image.Headers) (*image.Header, e
 	if err != nil {
 		return nil, err
 	}
-	// append multi-reader so that the header data can be re-read by subsequent readers
-	fr.appendReader(rdrMulti, bytes.NewReader(fr.buf))
+	// append multi-reader so that the header data can be re-read by subsequent readers. The header is copied since
+	// fr.buf is reused for the next header, while decompressors reading ahead in the background may not be done with it.
+	fr.appendReader(rdrMulti, bytes.NewReader(append([]byte{}, fr.buf...)))
 
 	// loop through known headers until a match
 	for format, kh := range *knownHdrs {
@@ -261,6 +333,38 @@ func (fr *FormatReaders) Close() (rtnerr error) {
 	return rtnerr
 }
 
+// HasChecksum returns true if the raw stream has to be verified against a checksum.
+func (fr *FormatReaders) HasChecksum() bool {
+	return fr.checksumReader != nil
+}
+
+// VerifyChecksum reads the rest of the raw stream, and returns a util.ChecksumMismatchError if it doesn't match the
+// expected checksum. It is a no-op if no checksum was passed in.
+func (fr *FormatReaders) VerifyChecksum() error {
+	if fr.checksumReader == nil {
+		return nil
+	}
+	if err := fr.checksumReader.Verify(); err != nil {
+		return err
+	}
+	klog.V(1).Infoln("Checksum of the data matches")
+	return nil
+}
+
+// Qcow2Header returns the header of the qcow2 image read if image.ConvertQcow2Stream can convert it, nil otherwise.
+func (fr *FormatReaders) Qcow2Header() *image.Qcow2Header {
+	return fr.qcow2Header
+}
+
+// Format returns the formats of the data read, outermost first and separated by "+", like "xz+qcow2", or "raw" if
+// the data has no known header.
+func (fr *FormatReaders) Format() string {
+	if len(fr.formats) == 0 {
+		return "raw"
+	}
+	return strings.Join(fr.formats, "+")
+}
+
 // StartProgressUpdate starts the go routine to automatically update the progress on a set interval.
 func (fr *FormatReaders) StartProgressUpdate() {
 	if fr.progressReader != nil {
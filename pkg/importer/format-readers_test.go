@@ -1,6 +1,10 @@
 package importer
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -13,6 +17,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/pkg/util"
 	"kubevirt.io/containerized-data-importer/tests/utils"
 )
 
@@ -47,7 +52,7 @@ var _ = Describe("Format Readers", func() {
 		Expect(err).ToNot(HaveOccurred())
 		defer f.Close()
 
-		fr, err = NewFormatReaders(f, uint64(0))
+		fr, err = NewFormatReaders(f, uint64(0), "")
 		if wantErr {
 			Expect(err).To(HaveOccurred())
 		} else {
@@ -67,11 +72,34 @@ var _ = Describe("Format Readers", func() {
 		table.Entry("successfully construct .iso reader", tinyCoreFilePath, 2, false, false, false),               // [stream, multi-r] convert = false
 	)
 
+	table.DescribeTable("should report the format", func(data func() []byte, format string) {
+		var err error
+		fr, err = NewFormatReaders(ioutil.NopCloser(bytes.NewReader(data())), uint64(0), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Format()).To(Equal(format))
+	},
+		table.Entry("raw", func() []byte { return []byte(strings.Repeat("raw data", 1024)) }, "raw"),
+		table.Entry("qcow2", func() []byte { return qcow2TestStream(qcow2TestGuestData()) }, "qcow2"),
+		table.Entry("zstd", func() []byte {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", "image.raw.zst"))
+			Expect(err).ToNot(HaveOccurred())
+			return data
+		}, "zst"),
+		table.Entry("gzipped qcow2", func() []byte {
+			var buf bytes.Buffer
+			w := gzip.NewWriter(&buf)
+			_, err := w.Write(qcow2TestStream(qcow2TestGuestData()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+			return buf.Bytes()
+		}, "gz+qcow2"),
+	)
+
 	table.DescribeTable("can append readers", func(rType int, r interface{}, numRdrs int, isCloser bool) {
 		f, err := os.Open(cirrosFilePath)
 		Expect(err).ToNot(HaveOccurred())
 		defer f.Close()
-		fr, err = NewFormatReaders(f, uint64(0))
+		fr, err = NewFormatReaders(f, uint64(0), "")
 		Expect(err).ToNot(HaveOccurred())
 		By("Verifying there are currently 2 readers")
 		Expect(len(fr.readers)).To(Equal(2))
@@ -93,11 +121,97 @@ var _ = Describe("Format Readers", func() {
 
 	It("should not crash on no progress reader", func() {
 		stringReader := ioutil.NopCloser(strings.NewReader("This is a test string"))
-		testReader, err := NewFormatReaders(stringReader, uint64(0))
+		testReader, err := NewFormatReaders(stringReader, uint64(0), "")
 		// Not passing a real string, so the header checking will fail.
 		Expect(err).To(HaveOccurred())
 		Expect(testReader.progressReader).To(BeNil())
 		// This should not crash
 		testReader.StartProgressUpdate()
 	})
+
+	table.DescribeTable("should detect disk images that need conversion", func(magic []byte, offset int) {
+		data := make([]byte, 4096)
+		copy(data[offset:], magic)
+		var err error
+		fr, err = NewFormatReaders(ioutil.NopCloser(bytes.NewReader(data)), uint64(0), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Convert).To(BeTrue())
+		Expect(fr.Archived).To(BeFalse())
+		// [stream, multi-r] the image is passed unchanged to qemu-img
+		Expect(fr.readers).To(HaveLen(2))
+		got, err := ioutil.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(Equal(data))
+	},
+		table.Entry("vmdk", []byte("KDMV"), 0),
+		table.Entry("vhd", []byte("conectix"), 0),
+		table.Entry("vhdx", []byte("vhdxfile"), 0),
+		table.Entry("vdi", []byte{0x7f, 0x10, 0xda, 0xbe}, 0x40),
+	)
+
+	table.DescribeTable("should decompress", func(filename string) {
+		// The same content compressed with the command line tools
+		var want bytes.Buffer
+		for i := 0; i < 20000; i++ {
+			fmt.Fprintf(&want, "%04d\n", i%200)
+		}
+		compressed, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+		Expect(err).ToNot(HaveOccurred())
+		fr, err = NewFormatReaders(ioutil.NopCloser(bytes.NewReader(compressed)), uint64(len(compressed)), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Archived).To(BeTrue())
+		Expect(fr.Convert).To(BeFalse())
+		// [stream, multi-r, decompressor, multi-r]
+		Expect(fr.readers).To(HaveLen(4))
+		got, err := ioutil.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(Equal(want.Bytes()))
+		// Progress is reported against the compressed size
+		Expect(fr.progressReader.Current).To(Equal(uint64(len(compressed))))
+	},
+		table.Entry("zstd with several frames", "image.raw.zst"),
+		table.Entry("bzip2", "image.raw.bz2"),
+		table.Entry("lz4 with dependent blocks and checksums", "image.raw.lz4"),
+		table.Entry("lz4 with independent blocks", "image.raw.independent.lz4"),
+	)
+
+	Context("with a checksum", func() {
+		var gzData []byte
+
+		BeforeEach(func() {
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			_, err := gz.Write(bytes.Repeat([]byte("checksum test data"), 10000))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gz.Close()).To(Succeed())
+			gzData = buf.Bytes()
+		})
+
+		readAll := func(checksum string) error {
+			var err error
+			fr, err = NewFormatReaders(ioutil.NopCloser(bytes.NewReader(gzData)), uint64(0), checksum)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fr.HasChecksum()).To(BeTrue())
+			_, err = io.Copy(ioutil.Discard, fr.TopReader())
+			Expect(err).ToNot(HaveOccurred())
+			return fr.VerifyChecksum()
+		}
+
+		It("should verify the raw stream", func() {
+			sum := sha256.Sum256(gzData)
+			Expect(readAll("sha256:" + hex.EncodeToString(sum[:]))).To(Succeed())
+		})
+
+		It("should report a mismatch", func() {
+			sum := sha256.Sum256([]byte("other data"))
+			err := readAll("sha256:" + hex.EncodeToString(sum[:]))
+			Expect(util.IsChecksumMismatch(err)).To(BeTrue())
+		})
+
+		It("should fail on an invalid checksum", func() {
+			var err error
+			fr, err = NewFormatReaders(ioutil.NopCloser(bytes.NewReader(gzData)), uint64(0), "sha256")
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -25,10 +25,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"path/filepath"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -36,6 +38,7 @@ import (
 	"k8s.io/klog/v2"
 
 	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
@@ -46,11 +49,13 @@ const (
 // HTTPDataSource is the data provider for http(s) endpoints.
 // Sequence of phases:
 // 1a. Info -> Convert (In Info phase the format readers are configured), if the source Reader image is not archived, and no custom CA is used, and can be converted by QEMU-IMG (RAW/QCOW2)
+//     Note: the disks of an OVA archive are always transferred, the endpoint can't be passed to QEMU-IMG
 // 1b. Info -> TransferArchive if the content type is archive
 // 1c. Info -> Transfer in all other cases.
 // 2a. Transfer -> Process if content type is kube virt
 // 2b. Transfer -> Complete if content type is archive (Transfer is called with the target instead of the scratch space). Non block PVCs only.
 // 3. Process -> Convert
+// qcow2 data is converted while it is transferred to the target instead of scratch space when possible, then Resize.
 type HTTPDataSource struct {
 	httpReader io.ReadCloser
 	ctx        context.Context
@@ -70,10 +75,20 @@ type HTTPDataSource struct {
 	brokenForQemuImg bool
 	// the content length reported by the http server.
 	contentLength uint64
+	// checksum the downloaded data is expected to match, if not empty.
+	checksum string
+	// ovaDisk selects the disk to import when the endpoint is an OVA archive, empty otherwise.
+	ovaDisk string
+	// rangeReader reads the http response, resuming the download when it is interrupted.
+	rangeReader *rangeReader
+	// countingReader counts the bytes downloaded, to detect a stalled download.
+	countingReader *util.CountingReader
+	// concurrency is the number of parallel ranged requests used to download raw data.
+	concurrency int
 }
 
 // NewHTTPDataSource creates a new instance of the http data provider.
-func NewHTTPDataSource(endpoint, accessKey, secKey, certDir string, contentType cdiv1.DataVolumeContentType) (*HTTPDataSource, error) {
+func NewHTTPDataSource(endpoint, accessKey, secKey, certDir string, contentType cdiv1.DataVolumeContentType, checksum, ovaDisk string, concurrency int) (*HTTPDataSource, error) {
 	ep, err := ParseEndpoint(endpoint)
 	if err != nil {
 		return nil, errors.Wrapf(err, fmt.Sprintf("unable to parse endpoint %q", endpoint))
@@ -97,9 +112,14 @@ func NewHTTPDataSource(endpoint, accessKey, secKey, certDir string, contentType
 		customCA:         certDir != "",
 		brokenForQemuImg: brokenForQemuImg,
 		contentLength:    contentLength,
+		checksum:         checksum,
+		ovaDisk:          ovaDisk,
+		concurrency:      concurrency,
 	}
 	// We know this is a counting reader, so no need to check.
 	countingReader := httpReader.(*util.CountingReader)
+	httpSource.countingReader = countingReader
+	httpSource.rangeReader = countingReader.Reader.(*rangeReader)
 	go httpSource.pollProgress(countingReader, 10*time.Minute, time.Second)
 	return httpSource, nil
 }
@@ -107,7 +127,17 @@ func NewHTTPDataSource(endpoint, accessKey, secKey, certDir string, contentType
 // Info is called to get initial information about the data.
 func (hs *HTTPDataSource) Info() (ProcessingPhase, error) {
 	var err error
-	hs.readers, err = NewFormatReaders(hs.httpReader, hs.contentLength)
+	if hs.ovaDisk != "" {
+		var diskReader *ovaDiskReader
+		diskReader, err = newOVADiskReader(hs.httpReader, hs.ovaDisk)
+		if err != nil {
+			klog.Errorf("Error reading OVA archive: %v", err)
+			return ProcessingPhaseError, err
+		}
+		hs.readers, err = NewFormatReaders(diskReader, uint64(diskReader.size), hs.checksum)
+	} else {
+		hs.readers, err = NewFormatReaders(hs.httpReader, hs.contentLength, hs.checksum)
+	}
 	if hs.contentType == cdiv1.DataVolumeArchive {
 		return ProcessingPhaseTransferDataDir, nil
 	}
@@ -117,7 +147,8 @@ func (hs *HTTPDataSource) Info() (ProcessingPhase, error) {
 	}
 	// The readers now contain all the information needed to determine if we can stream directly or if we need scratch space to download
 	// the file to, before converting.
-	if !hs.readers.Archived && !hs.customCA && !hs.brokenForQemuImg && hs.readers.Convert {
+	// When a checksum is set the data has to flow through the readers, so it can't be streamed directly either.
+	if !hs.readers.Archived && !hs.customCA && !hs.brokenForQemuImg && !hs.readers.HasChecksum() && hs.ovaDisk == "" && hs.readers.Convert {
 		// We can pass straight to conversion from the endpoint. No scratch required.
 		hs.url = hs.endpoint
 		return ProcessingPhaseConvert, nil
@@ -137,10 +168,17 @@ func (hs *HTTPDataSource) Transfer(path string) (ProcessingPhase, error) {
 			return ProcessingPhaseError, ErrInvalidPath
 		}
 		file := filepath.Join(path, tempFile)
-		err = util.StreamDataToFile(hs.readers.TopReader(), file)
+		if hs.resumable() {
+			err = hs.streamResumableDataToFile(file)
+		} else {
+			err = util.StreamDataToFile(hs.readers.TopReader(), file)
+		}
 		if err != nil {
 			return ProcessingPhaseError, err
 		}
+		if err := hs.readers.VerifyChecksum(); err != nil {
+			return ProcessingPhaseError, err
+		}
 		// If we successfully wrote to the file, then the parse will succeed.
 		hs.url, _ = url.Parse(file)
 		return ProcessingPhaseConvert, nil
@@ -148,22 +186,148 @@ func (hs *HTTPDataSource) Transfer(path string) (ProcessingPhase, error) {
 		if err := util.UnArchiveTar(hs.readers.TopReader(), path); err != nil {
 			return ProcessingPhaseError, errors.Wrap(err, "unable to untar files from endpoint")
 		}
+		if err := hs.readers.VerifyChecksum(); err != nil {
+			return ProcessingPhaseError, err
+		}
 		hs.url = nil
 		return ProcessingPhaseComplete, nil
 	}
 	return ProcessingPhaseError, errors.Errorf("Unknown content type: %s", hs.contentType)
 }
 
-// TransferFile is called to transfer the data from the source to the passed in file.
+// resumable returns true if a transfer to a file interrupted by a restart can be resumed: the data written to the
+// file is the data downloaded, and the server supports conditional Range requests.
+// Note: the data is hashed as it is read, so a transfer with a checksum to verify always starts over.
+func (hs *HTTPDataSource) resumable() bool {
+	return hs.rangeReader.canResume() && hs.ovaDisk == "" && !hs.readers.Archived && !hs.readers.HasChecksum()
+}
+
+// streamResumableDataToFile downloads the data to file, resuming the download of a previous attempt if one was
+// interrupted. The state of the download is stored next to the file until the download completes.
+func (hs *HTTPDataSource) streamResumableDataToFile(file string) error {
+	stateFile := file + resumeStateSuffix
+	state := hs.rangeReader.state(hs.endpoint)
+	offset := int64(0)
+	if previous := readRangeState(stateFile); previous != nil && *previous == *state {
+		if info, err := os.Stat(file); err == nil {
+			offset = info.Size()
+		}
+	}
+	// The header was read by the format readers, so a download can only resume after it.
+	if offset <= int64(image.MaxExpectedHdrSize) || (state.Total > 0 && offset > state.Total) {
+		offset = 0
+	}
+	reader := hs.readers.TopReader()
+	if offset > 0 {
+		klog.Infof("Resuming the download of %s at offset %d", state.URL, offset)
+		if _, err := io.CopyN(ioutil.Discard, reader, int64(image.MaxExpectedHdrSize)); err != nil {
+			return errors.Wrap(err, "unable to skip the image header")
+		}
+		if err := hs.rangeReader.seek(offset); err != nil {
+			// Discard the partial download, so the next attempt starts over.
+			os.Remove(stateFile)
+			os.Remove(file)
+			return errors.Wrap(err, "unable to resume the download")
+		}
+		if hs.readers.progressReader != nil {
+			hs.readers.progressReader.Current = uint64(offset)
+		}
+	} else if err := state.write(stateFile); err != nil {
+		return errors.Wrap(err, "unable to store the state of the download")
+	}
+	if err := util.StreamDataToFileAt(reader, file, offset); err != nil {
+		return err
+	}
+	os.Remove(stateFile)
+	return nil
+}
+
+// GetResumeFiles returns the files an interrupted transfer to scratch space leaves to be resumed by the next attempt.
+func (hs *HTTPDataSource) GetResumeFiles() []string {
+	return []string{tempFile, tempFile + resumeStateSuffix}
+}
+
+// GetTargetResumeFiles returns the partial target file and the state of its download left by an interrupted transfer,
+// if they are from a download of the same object.
+func (hs *HTTPDataSource) GetTargetResumeFiles(dataFile string) []string {
+	stateFile := dataFile + resumeStateSuffix
+	if !hs.rangeReader.canResume() {
+		return nil
+	}
+	if previous := readRangeState(stateFile); previous == nil || *previous != *hs.rangeReader.state(hs.endpoint) {
+		return nil
+	}
+	return []string{filepath.Base(dataFile), filepath.Base(stateFile)}
+}
+
+// TransferFile is called to transfer the data from the source to the passed in file. The transfer to a file resumes
+// the download of a previous attempt, the transfer to a block device starts over as its size is not the size of the
+// data already written.
 func (hs *HTTPDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
 	hs.readers.StartProgressUpdate()
-	err := util.StreamDataToFile(hs.readers.TopReader(), fileName)
+	var err error
+	if hs.parallel() {
+		err = hs.downloadRanges(fileName)
+	} else if blockSize, _ := util.GetAvailableSpaceBlock(fileName); hs.resumable() && blockSize < 0 {
+		err = hs.streamResumableDataToFile(fileName)
+	} else {
+		err = util.StreamDataToFile(hs.readers.TopReader(), fileName)
+	}
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
+	if err := hs.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseResize, nil
+}
+
+// GetQcow2Header returns the header of the qcow2 data if it can be converted while it is transferred, nil otherwise.
+func (hs *HTTPDataSource) GetQcow2Header() *image.Qcow2Header {
+	return hs.readers.Qcow2Header()
+}
+
+// TransferConvert is called to convert the qcow2 data to raw while transferring it to the passed in file.
+func (hs *HTTPDataSource) TransferConvert(fileName, spillDir string) (ProcessingPhase, error) {
+	hs.readers.StartProgressUpdate()
+	if err := convertQcow2StreamToFile(hs.readers.TopReader(), fileName, spillDir); err != nil {
+		return ProcessingPhaseError, err
+	}
+	if err := hs.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
 	return ProcessingPhaseResize, nil
 }
 
+// parallel returns true if the data can be downloaded with parallel ranged requests and written as is: it is neither
+// archived nor an OVA archive, and it has no checksum to compute over the sequential stream.
+func (hs *HTTPDataSource) parallel() bool {
+	return hs.concurrency > 1 && hs.rangeReader.total > 0 && hs.rangeReader.canResume() && hs.ovaDisk == "" &&
+		!hs.readers.Archived && !hs.readers.Convert && !hs.readers.HasChecksum()
+}
+
+// downloadRanges downloads the data to fileName with parallel ranged requests, instead of the initial response.
+func (hs *HTTPDataSource) downloadRanges(fileName string) error {
+	klog.Infof("Downloading %d bytes with %d parallel requests", hs.rangeReader.total, hs.concurrency)
+	// Only the header was read from the initial response, stop its transfer.
+	hs.rangeReader.Close()
+	progress := func(n int64) {
+		atomic.AddUint64(&hs.countingReader.Current, uint64(n))
+		if hs.readers.progressReader != nil {
+			atomic.AddUint64(&hs.readers.progressReader.Current, uint64(n))
+		}
+	}
+	if hs.readers.progressReader != nil {
+		// The header was read from the initial response, the ranges download it again.
+		atomic.StoreUint64(&hs.readers.progressReader.Current, 0)
+	}
+	if err := downloadRanges(hs.rangeReader.fetchRange, hs.rangeReader.total, hs.concurrency, fileName, progress); err != nil {
+		return err
+	}
+	// The ranges are conditional on the validator of the initial response, make sure the last one is still valid.
+	return hs.rangeReader.checkValidators()
+}
+
 // GetURL returns the URI that the data processor can use when converting the data.
 func (hs *HTTPDataSource) GetURL() *url.URL {
 	return hs.url
@@ -250,15 +414,18 @@ func createHTTPReader(ctx context.Context, ep *url.URL, accessKey, secKey, certD
 	if err != nil {
 		brokenForQemuImg = true
 	}
-	// http.NewRequest can only return error on invalid METHOD, or invalid url. Here the METHOD is always GET, and the url is always valid, thus error cannot happen.
-	req, _ := http.NewRequest("GET", ep.String(), nil)
+	newRequest := func(method string) *http.Request {
+		// http.NewRequest can only return error on invalid METHOD, or invalid url. Here the METHOD is always GET or HEAD, and the url is always valid, thus error cannot happen.
+		req, _ := http.NewRequest(method, ep.String(), nil)
 
-	req = req.WithContext(ctx)
-	if len(accessKey) > 0 && len(secKey) > 0 {
-		req.SetBasicAuth(accessKey, secKey)
+		req = req.WithContext(ctx)
+		if len(accessKey) > 0 && len(secKey) > 0 {
+			req.SetBasicAuth(accessKey, secKey)
+		}
+		return req
 	}
 	klog.V(2).Infof("Attempting to get object %q via http client\n", ep.String())
-	resp, err := client.Do(req)
+	resp, err := client.Do(newRequest("GET"))
 	if err != nil {
 		return nil, uint64(0), true, errors.Wrap(err, "HTTP request errored")
 	}
@@ -278,7 +445,7 @@ func createHTTPReader(ctx context.Context, ep *url.URL, accessKey, secKey, certD
 		total = parseHTTPHeader(resp)
 	}
 	countingReader := &util.CountingReader{
-		Reader:  resp.Body,
+		Reader:  newRangeReader(ctx, client, newRequest, resp),
 		Current: 0,
 	}
 	return countingReader, total, brokenForQemuImg, nil
@@ -344,6 +511,55 @@ func getContentLength(client *http.Client, ep *url.URL, accessKey, secKey string
 	return total, nil
 }
 
+// GetHTTPEtag returns the ETag of the http source, without downloading it.
+func GetHTTPEtag(endpoint, accessKey, secKey, certDir string) (string, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return "", errors.Wrapf(err, fmt.Sprintf("unable to parse endpoint %q", endpoint))
+	}
+	client, err := createHTTPClient(certDir)
+	if err != nil {
+		return "", errors.Wrap(err, "could not create http client")
+	}
+	req, err := http.NewRequest("HEAD", ep.String(), nil)
+	if err != nil {
+		return "", errors.Wrap(err, "could not create HTTP request")
+	}
+	if len(accessKey) > 0 && len(secKey) > 0 {
+		req.SetBasicAuth(accessKey, secKey)
+	}
+
+	klog.V(2).Infof("Attempting to HEAD %q via http client\n", ep.String())
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "HTTP request errored")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("expected status code 200, got %d. Status: %s", resp.StatusCode, resp.Status)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", errors.Errorf("http source %q does not provide an ETag", ep.String())
+	}
+	return etag, nil
+}
+
+// GetHTTPVirtualSize returns the virtual size of the image of the http source, reading only the headers of the data.
+func GetHTTPVirtualSize(endpoint, accessKey, secKey, certDir string) (int64, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return 0, errors.Wrapf(err, fmt.Sprintf("unable to parse endpoint %q", endpoint))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	httpReader, contentLength, _, err := createHTTPReader(ctx, ep, accessKey, secKey, certDir)
+	if err != nil {
+		return 0, err
+	}
+	return probeVirtualSize(httpReader, contentLength)
+}
+
 func parseHTTPHeader(resp *http.Response) uint64 {
 	var err error
 	total := uint64(0)
@@ -1,10 +1,16 @@
 package importer
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -12,6 +18,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +27,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/triple"
@@ -70,14 +78,14 @@ var _ = Describe("Http data source", func() {
 	})
 
 	It("NewHTTPDataSource should fail when called with an invalid endpoint", func() {
-		_, err = NewHTTPDataSource("httpd://!@#$%^&*()dgsdd&3r53/invalid", "", "", "", cdiv1.DataVolumeKubeVirt)
+		_, err = NewHTTPDataSource("httpd://!@#$%^&*()dgsdd&3r53/invalid", "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
 		Expect(err).To(HaveOccurred())
 		Expect(strings.Contains(err.Error(), "unable to parse endpoint")).To(BeTrue())
 	})
 
 	It("endpoint User object should be set when accessKey and secKey are not blank", func() {
 		image := ts.URL + "/" + cirrosFileName
-		dp, err = NewHTTPDataSource(image, "user", "password", "", cdiv1.DataVolumeKubeVirt)
+		dp, err = NewHTTPDataSource(image, "user", "password", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		user := dp.endpoint.User
 		Expect("user").To(Equal(user.Username()))
@@ -88,7 +96,7 @@ var _ = Describe("Http data source", func() {
 
 	It("NewHTTPDataSource should fail when called with an invalid certdir", func() {
 		image := ts.URL + "/" + cirrosFileName
-		_, err = NewHTTPDataSource(image, "", "", "/invaliddir", cdiv1.DataVolumeKubeVirt)
+		_, err = NewHTTPDataSource(image, "", "", "/invaliddir", cdiv1.DataVolumeKubeVirt, "", "", 1)
 		Expect(err).To(HaveOccurred())
 	})
 
@@ -97,7 +105,7 @@ var _ = Describe("Http data source", func() {
 		if image != "" {
 			image = ts.URL + "/" + image
 		}
-		dp, err = NewHTTPDataSource(image, "", "", "", contentType)
+		dp, err = NewHTTPDataSource(image, "", "", "", contentType, "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		newPhase, err := dp.Info()
 		if !wantErr {
@@ -118,7 +126,7 @@ var _ = Describe("Http data source", func() {
 	)
 
 	It("calling info with raw image should return TransferDataFile", func() {
-		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreGz, "", "", "", cdiv1.DataVolumeKubeVirt)
+		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreGz, "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		newPhase, err := dp.Info()
 		Expect(err).NotTo(HaveOccurred())
@@ -133,7 +141,7 @@ var _ = Describe("Http data source", func() {
 		if image != "" {
 			image = ts.URL + "/" + image
 		}
-		dp, err = NewHTTPDataSource(image, "", "", "", contentType)
+		dp, err = NewHTTPDataSource(image, "", "", "", contentType, "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		_, err := dp.Info()
 		Expect(err).NotTo(HaveOccurred())
@@ -164,7 +172,7 @@ var _ = Describe("Http data source", func() {
 	)
 
 	It("TransferFile should succeed when writing to valid file, and reading raw gz", func() {
-		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreGz, "", "", "", cdiv1.DataVolumeKubeVirt)
+		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreGz, "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		result, err := dp.Info()
 		Expect(err).NotTo(HaveOccurred())
@@ -175,7 +183,7 @@ var _ = Describe("Http data source", func() {
 	})
 
 	It("TransferFile should succeed when writing to valid file and reading raw xz", func() {
-		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreXz, "", "", "", cdiv1.DataVolumeKubeVirt)
+		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreXz, "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		result, err := dp.Info()
 		Expect(err).NotTo(HaveOccurred())
@@ -186,7 +194,7 @@ var _ = Describe("Http data source", func() {
 	})
 
 	It("TransferFile should fail on streaming error", func() {
-		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreGz, "", "", "", cdiv1.DataVolumeKubeVirt)
+		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreGz, "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		result, err := dp.Info()
 		Expect(err).NotTo(HaveOccurred())
@@ -195,6 +203,33 @@ var _ = Describe("Http data source", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(ProcessingPhaseError).To(Equal(result))
 	})
+
+	It("TransferConvert should convert gzipped qcow2 data while writing it to the file", func() {
+		guest := qcow2TestGuestData()
+		var gz bytes.Buffer
+		w := gzip.NewWriter(&gz)
+		_, err := w.Write(qcow2TestStream(guest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		sum := sha256.Sum256(gz.Bytes())
+		qcow2Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(gz.Bytes())
+		}))
+		defer qcow2Server.Close()
+		dp, err = NewHTTPDataSource(qcow2Server.URL+"/disk.qcow2.gz", "", "", "", cdiv1.DataVolumeKubeVirt, "sha256:"+hex.EncodeToString(sum[:]), "", 1)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseTransferScratch))
+		Expect(dp.GetQcow2Header()).NotTo(BeNil())
+		target := filepath.Join(tmpDir, "disk.img")
+		result, err = dp.TransferConvert(target, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseResize))
+		got, err := ioutil.ReadFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(guest))
+	})
 })
 
 var _ = Describe("Http client", func() {
@@ -390,6 +425,91 @@ var _ = Describe("Http reader", func() {
 	})
 })
 
+var _ = Describe("Http ETag", func() {
+	It("should return the ETag of the source without downloading it", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal("HEAD"))
+			w.Header().Set("ETag", `"5f3a-1b2c"`)
+		}))
+		defer ts.Close()
+		etag, err := GetHTTPEtag(ts.URL, "", "", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(etag).To(Equal(`"5f3a-1b2c"`))
+	})
+
+	It("should fail if the source has no ETag", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(200)
+		}))
+		defer ts.Close()
+		_, err := GetHTTPEtag(ts.URL, "", "", "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("does not provide an ETag"))
+	})
+})
+
+var _ = Describe("Http virtual size", func() {
+	const virtualSize = 1024 * 1024
+
+	// rawData is not compressed much, so the compressed data is larger than the headers read
+	rawData := func() []byte {
+		data := make([]byte, 4096)
+		rand.New(rand.NewSource(1)).Read(data[image.MaxExpectedHdrSize:])
+		return data
+	}
+	qcow2Data := func() []byte {
+		data := rawData()
+		copy(data, []byte{'Q', 'F', 'I', 0xfb})
+		binary.BigEndian.PutUint64(data[24:], virtualSize)
+		return data
+	}
+	gzipData := func(data []byte) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(data)
+		gz.Close()
+		return buf.Bytes()
+	}
+	serve := func(data []byte) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			if r.Method == http.MethodGet {
+				w.Write(data)
+			}
+		}))
+	}
+
+	table.DescribeTable("should return the virtual size read from the headers of", func(data []byte, expected int64) {
+		ts := serve(data)
+		defer ts.Close()
+		size, err := GetHTTPVirtualSize(ts.URL, "", "", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(Equal(expected))
+	},
+		table.Entry("a qcow2 image", qcow2Data(), int64(virtualSize)),
+		table.Entry("a compressed qcow2 image", gzipData(qcow2Data()), int64(virtualSize)),
+		table.Entry("uncompressed raw data", rawData(), int64(4096)),
+	)
+
+	It("should fail if the size of compressed raw data can't be determined", func() {
+		ts := serve(gzipData(rawData()))
+		defer ts.Close()
+		_, err := GetHTTPVirtualSize(ts.URL, "", "", "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unable to determine the virtual size of the gz data"))
+	})
+
+	It("should fail if the source can't be read", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer ts.Close()
+		_, err := GetHTTPVirtualSize(ts.URL, "", "", "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("expected status code 200"))
+	})
+})
+
 var _ = Describe("http pollprogress", func() {
 	It("Should properly finish with valid reader", func() {
 		By("Creating context for the transfer, we have the ability to cancel it")
@@ -0,0 +1,266 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxRangeRetries is the number of times in a row a download is resumed without reading any data, before giving up.
+	maxRangeRetries = 5
+	// resumeStateSuffix is appended to the name of a partially downloaded file, to name the file describing the download.
+	resumeStateSuffix = ".resume"
+)
+
+// rangeRetryDelay is the delay before resuming a download, may be overridden in tests
+var rangeRetryDelay = time.Second
+
+// rangeReader reads the body of the response to an http GET request, and when reading fails midway, resumes the
+// download where it stopped with a Range request, if the server advertised support for them. The Range requests are
+// conditional on the ETag, or the Last-Modified date, of the first response, so data of a modified object is never
+// appended to the data already read. Once a resumed download completes, the validators are checked again with a
+// HEAD request, to make sure the whole object did not change.
+type rangeReader struct {
+	ctx    context.Context
+	client *http.Client
+	// newRequest creates a request for the object, with the needed authentication
+	newRequest func(method string) *http.Request
+	body       io.ReadCloser
+	// offset is the offset in the object of the next byte read
+	offset int64
+	// total is the size of the object, 0 if unknown
+	total        int64
+	acceptRanges bool
+	etag         string
+	lastModified string
+	// retries counts the attempts to resume the download since data was last read
+	retries int
+	// resumed is true if the download was resumed at least once
+	resumed bool
+}
+
+// rangeState is what is needed to resume a partial download after a restart, it is stored next to the partial file.
+type rangeState struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Total        int64  `json:"total"`
+}
+
+func newRangeReader(ctx context.Context, client *http.Client, newRequest func(method string) *http.Request, resp *http.Response) *rangeReader {
+	r := &rangeReader{
+		ctx:          ctx,
+		client:       client,
+		newRequest:   newRequest,
+		body:         resp.Body,
+		acceptRanges: resp.Header.Get("Accept-Ranges") == "bytes",
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+	if resp.ContentLength > 0 {
+		r.total = resp.ContentLength
+	}
+	return r
+}
+
+// validator returns the value of the If-Range header of the Range requests. Weak ETags can't be used in If-Range.
+func (r *rangeReader) validator() string {
+	if r.etag != "" && !strings.HasPrefix(r.etag, "W/") {
+		return r.etag
+	}
+	return r.lastModified
+}
+
+// canResume returns true if the server supports conditional Range requests for the object.
+func (r *rangeReader) canResume() bool {
+	return r.acceptRanges && r.validator() != ""
+}
+
+// Read reads the object, resuming the download if reading the body fails.
+func (r *rangeReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			r.retries = 0
+		}
+		if err == io.EOF && r.total > 0 && r.offset < r.total {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil {
+			return n, nil
+		}
+		if err == io.EOF {
+			if verr := r.validate(); verr != nil {
+				return n, verr
+			}
+			return n, io.EOF
+		}
+		if !r.canResume() || r.ctx.Err() != nil || r.retries >= maxRangeRetries {
+			return n, err
+		}
+		klog.Warningf("Error reading http response at offset %d, resuming the download: %v", r.offset, err)
+		if rerr := r.reopen(); rerr != nil {
+			return n, errors.Wrapf(rerr, "unable to resume the download after: %v", err)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// seek resumes the download at offset, the data before offset is already available.
+func (r *rangeReader) seek(offset int64) error {
+	if offset == r.offset {
+		return nil
+	}
+	if !r.canResume() {
+		return errors.New("the server does not support resuming the download")
+	}
+	r.offset = offset
+	return r.reopen()
+}
+
+// reopen requests the object from the current offset, waiting between the attempts.
+func (r *rangeReader) reopen() error {
+	r.body.Close()
+	r.body = ioutil.NopCloser(strings.NewReader(""))
+	var err error
+	for r.retries < maxRangeRetries {
+		r.retries++
+		select {
+		case <-time.After(rangeRetryDelay):
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		}
+		var retry bool
+		if retry, err = r.requestRange(); err == nil || !retry {
+			return err
+		}
+		klog.Warningf("Unable to resume the download at offset %d: %v", r.offset, err)
+	}
+	return err
+}
+
+// requestRange sends the Range request for the rest of the object, retry is true if the request may succeed later.
+func (r *rangeReader) requestRange() (bool, error) {
+	body, retry, err := r.getRange(r.offset, 0)
+	if err != nil {
+		return retry, err
+	}
+	klog.V(1).Infof("Resumed the download at offset %d", r.offset)
+	r.body = body
+	r.resumed = true
+	return false, nil
+}
+
+// fetchRange returns the data of the object from start to end, excluded.
+func (r *rangeReader) fetchRange(start, end int64) (io.ReadCloser, error) {
+	body, _, err := r.getRange(start, end)
+	return body, err
+}
+
+// getRange requests the data of the object from start to end, excluded, or to the end of the object if end is 0.
+// The request is conditional on the validator of the object, retry is true if the request may succeed later.
+func (r *rangeReader) getRange(start, end int64) (io.ReadCloser, bool, error) {
+	req := r.newRequest("GET")
+	if end > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+	}
+	req.Header.Set("If-Range", r.validator())
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, true, errors.Wrap(err, "HTTP request errored")
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var rangeStart int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &rangeStart); err != nil || rangeStart != start {
+			resp.Body.Close()
+			return nil, false, errors.Errorf("unexpected Content-Range %q requesting the object at offset %d", resp.Header.Get("Content-Range"), start)
+		}
+	case http.StatusOK:
+		// The If-Range condition failed, the server sent the whole object
+		resp.Body.Close()
+		return nil, false, errors.New("the object changed since the download started")
+	default:
+		resp.Body.Close()
+		return nil, resp.StatusCode >= 500, errors.Errorf("expected status code 206, got %d. Status: %s", resp.StatusCode, resp.Status)
+	}
+	return resp.Body, false, nil
+}
+
+// validate checks that the object did not change during a resumed download.
+func (r *rangeReader) validate() error {
+	if !r.resumed {
+		return nil
+	}
+	return r.checkValidators()
+}
+
+// checkValidators checks with a HEAD request that the ETag and Last-Modified date of the object did not change.
+func (r *rangeReader) checkValidators() error {
+	resp, err := r.client.Do(r.newRequest("HEAD"))
+	if err != nil {
+		return errors.Wrap(err, "unable to validate the downloaded object")
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unable to validate the downloaded object, expected status code 200, got %d. Status: %s", resp.StatusCode, resp.Status)
+	}
+	if resp.Header.Get("ETag") != r.etag || resp.Header.Get("Last-Modified") != r.lastModified {
+		return errors.New("the object changed since the download started")
+	}
+	return nil
+}
+
+// Close closes the current response body
+func (r *rangeReader) Close() error {
+	return r.body.Close()
+}
+
+// state returns the state needed to resume the download of the object at ep after a restart.
+func (r *rangeReader) state(ep *url.URL) *rangeState {
+	// Never store the credentials
+	u := *ep
+	u.User = nil
+	return &rangeState{URL: u.String(), ETag: r.etag, LastModified: r.lastModified, Total: r.total}
+}
+
+func (s *rangeState) write(fileName string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, data, 0644)
+}
+
+// readRangeState reads the state of a previous download, nil if there is none or it can't be read.
+func readRangeState(fileName string) *rangeState {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.Warningf("Unable to read the state of the previous download: %v", err)
+		}
+		return nil
+	}
+	s := &rangeState{}
+	if err := json.Unmarshal(data, s); err != nil {
+		klog.Warningf("Unable to parse the state of the previous download: %v", err)
+		return nil
+	}
+	return s
+}
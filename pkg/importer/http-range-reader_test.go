@@ -0,0 +1,329 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// rangeTestServer serves an object with http.ServeContent, and can abort the first complete GET midway.
+type rangeTestServer struct {
+	*httptest.Server
+	lock         sync.Mutex
+	data         []byte
+	etag         string
+	headETag     string
+	lastModified time.Time
+	abortAt      int
+	ranges       []string
+}
+
+func newRangeTestServer(data []byte, etag string) *rangeTestServer {
+	s := &rangeTestServer{data: data, etag: etag, abortAt: -1}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *rangeTestServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	etag := s.etag
+	if r.Method == "HEAD" && s.headETag != "" {
+		etag = s.headETag
+	}
+	abortAt := s.abortAt
+	if r.Method == "GET" && r.Header.Get("Range") == "" {
+		s.abortAt = -1
+	} else {
+		abortAt = -1
+	}
+	if r.Header.Get("Range") != "" {
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+	}
+	s.lock.Unlock()
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if abortAt >= 0 {
+		w.Header().Set("Accept-Ranges", "bytes")
+		if !s.lastModified.IsZero() {
+			w.Header().Set("Last-Modified", s.lastModified.Format(http.TimeFormat))
+		}
+		w.Write(s.data[:abortAt])
+		w.(http.Flusher).Flush()
+		// Drop the connection without completing the response
+		panic(http.ErrAbortHandler)
+	}
+	http.ServeContent(w, r, "image", s.lastModified, bytes.NewReader(s.data))
+}
+
+func (s *rangeTestServer) abort(offset int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.abortAt = offset
+}
+
+func (s *rangeTestServer) setETag(etag string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.etag = etag
+}
+
+func (s *rangeTestServer) requestedRanges() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.ranges...)
+}
+
+func rangeTestData() []byte {
+	// A qcow2 header, so the data is transferred to scratch space before conversion
+	data := make([]byte, 1024*1024)
+	copy(data, []byte{'Q', 'F', 'I', 0xfb})
+	for i := 512; i < len(data); i++ {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+var _ = Describe("Http range reader", func() {
+	var (
+		data     []byte
+		ts       *rangeTestServer
+		oldDelay time.Duration
+	)
+
+	BeforeEach(func() {
+		data = rangeTestData()
+		ts = newRangeTestServer(data, `"v1"`)
+		oldDelay = rangeRetryDelay
+		rangeRetryDelay = time.Millisecond
+	})
+
+	AfterEach(func() {
+		rangeRetryDelay = oldDelay
+		ts.Close()
+	})
+
+	readAll := func() ([]byte, error) {
+		ep, err := url.Parse(ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		r, _, _, err := createHTTPReader(context.Background(), ep, "", "", "")
+		Expect(err).ToNot(HaveOccurred())
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	}
+
+	It("should resume the download when the connection drops", func() {
+		ts.abort(300000)
+		got, err := readAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(Equal(data))
+		Expect(ts.requestedRanges()).To(Equal([]string{"bytes=300000-"}))
+	})
+
+	It("should resume the download using the last modified date without ETag", func() {
+		ts.setETag("")
+		ts.lastModified = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		ts.abort(300000)
+		got, err := readAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(Equal(data))
+		Expect(ts.requestedRanges()).To(Equal([]string{"bytes=300000-"}))
+	})
+
+	table.DescribeTable("should fail the download", func(prepare func()) {
+		ts.abort(300000)
+		prepare()
+		_, err := readAll()
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("if the server does not identify the object", func() {
+			ts.setETag("")
+		}),
+		table.Entry("if the server only sends a weak ETag", func() {
+			ts.setETag(`W/"v1"`)
+		}),
+		table.Entry("if the object changes before resuming", func() {
+			ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Range") != "" {
+					ts.setETag(`"v2"`)
+				}
+				ts.serve(w, r)
+			})
+		}),
+		table.Entry("if the object changes after resuming", func() {
+			ts.headETag = `"v2"`
+		}),
+	)
+
+	Context("with an http data source", func() {
+		var (
+			tmpDir string
+			dp     *HTTPDataSource
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "scratch")
+			Expect(err).NotTo(HaveOccurred())
+			dp = nil
+		})
+
+		AfterEach(func() {
+			if dp != nil {
+				dp.Close()
+			}
+			os.RemoveAll(tmpDir)
+		})
+
+		transfer := func() {
+			var err error
+			dp, err = NewHTTPDataSource(ts.URL+"/image.qcow2", "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
+			Expect(err).NotTo(HaveOccurred())
+			// The endpoint could be converted directly, transfer it to scratch space as with a custom CA.
+			phase, err := dp.Info()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(ProcessingPhaseConvert))
+			phase, err = dp.Transfer(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(ProcessingPhaseConvert))
+			got, err := ioutil.ReadFile(filepath.Join(tmpDir, tempFile))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(data))
+			_, err = os.Stat(filepath.Join(tmpDir, tempFile+resumeStateSuffix))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		}
+
+		writePartial := func(size int, etag string) {
+			Expect(util.StreamDataToFileAt(bytes.NewReader(data[:size]), filepath.Join(tmpDir, tempFile), 0)).To(Succeed())
+			ep, err := url.Parse(ts.URL + "/image.qcow2")
+			Expect(err).NotTo(HaveOccurred())
+			state := &rangeState{URL: ep.String(), ETag: etag, Total: int64(len(data))}
+			Expect(state.write(filepath.Join(tmpDir, tempFile+resumeStateSuffix))).To(Succeed())
+		}
+
+		It("should resume the transfer of a previous attempt", func() {
+			writePartial(400000, `"v1"`)
+			transfer()
+			Expect(ts.requestedRanges()).To(Equal([]string{"bytes=400000-"}))
+		})
+
+		table.DescribeTable("should start the transfer over", func(size int, etag string) {
+			writePartial(size, etag)
+			transfer()
+			Expect(ts.requestedRanges()).To(BeEmpty())
+		},
+			table.Entry("if the object changed", 400000, `"v0"`),
+			table.Entry("if the previous attempt did not get past the header", 100, `"v1"`),
+		)
+
+		It("should keep the partial transfer when the download fails", func() {
+			ts.setETag(`"v1"`)
+			var err error
+			dp, err = NewHTTPDataSource(ts.URL+"/image.qcow2", "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = dp.Info()
+			Expect(err).NotTo(HaveOccurred())
+			// Fail all the attempts to resume
+			ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			dp.rangeReader.body.Close()
+			_, err = dp.Transfer(tmpDir)
+			Expect(err).To(HaveOccurred())
+			state := readRangeState(filepath.Join(tmpDir, tempFile+resumeStateSuffix))
+			Expect(state).ToNot(BeNil())
+			Expect(state.ETag).To(Equal(`"v1"`))
+			_, err = os.Stat(filepath.Join(tmpDir, tempFile))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("with an http data source writing to the target", func() {
+		var (
+			tmpDir string
+			dp     *HTTPDataSource
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "data")
+			Expect(err).NotTo(HaveOccurred())
+			dp = nil
+			// Raw data, transferred straight to the target
+			copy(data, make([]byte, 4))
+		})
+
+		AfterEach(func() {
+			if dp != nil {
+				dp.Close()
+			}
+			os.RemoveAll(tmpDir)
+		})
+
+		target := func() string {
+			return filepath.Join(tmpDir, "disk.img")
+		}
+
+		writePartial := func(size int, etag string) {
+			Expect(util.StreamDataToFileAt(bytes.NewReader(data[:size]), target(), 0)).To(Succeed())
+			ep, err := url.Parse(ts.URL + "/image.img")
+			Expect(err).NotTo(HaveOccurred())
+			state := &rangeState{URL: ep.String(), ETag: etag, Total: int64(len(data))}
+			Expect(state.write(target() + resumeStateSuffix)).To(Succeed())
+		}
+
+		transferFile := func() {
+			var err error
+			dp, err = NewHTTPDataSource(ts.URL+"/image.img", "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
+			Expect(err).NotTo(HaveOccurred())
+			phase, err := dp.Info()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(ProcessingPhaseTransferDataFile))
+			phase, err = dp.TransferFile(target())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(ProcessingPhaseResize))
+			got, err := ioutil.ReadFile(target())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(data))
+			_, err = os.Stat(target() + resumeStateSuffix)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		}
+
+		It("should resume the transfer of a previous attempt", func() {
+			writePartial(400000, `"v1"`)
+			transferFile()
+			Expect(ts.requestedRanges()).To(Equal([]string{"bytes=400000-"}))
+		})
+
+		It("should start the transfer over if the object changed", func() {
+			writePartial(400000, `"v0"`)
+			transferFile()
+			Expect(ts.requestedRanges()).To(BeEmpty())
+		})
+
+		table.DescribeTable("should keep the files of a previous attempt", func(etag string, keep []string) {
+			writePartial(400000, etag)
+			var err error
+			dp, err = NewHTTPDataSource(ts.URL+"/image.img", "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dp.GetTargetResumeFiles(target())).To(Equal(keep))
+		},
+			table.Entry("of the same object", `"v1"`, []string{"disk.img", "disk.img" + resumeStateSuffix}),
+			table.Entry("unless the object changed", `"v0"`, nil),
+		)
+	})
+})
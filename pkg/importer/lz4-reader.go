@@ -0,0 +1,382 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	lz4FrameMagic         = 0x184D2204
+	lz4SkippableMagicMask = 0xFFFFFFF0
+	lz4SkippableMagic     = 0x184D2A50
+	// lz4WindowSize is the maximum match offset, so the history kept between dependent blocks.
+	lz4WindowSize = 64 * 1024
+	lz4MinMatch   = 4
+
+	lz4FlagVersionMask      = 0xC0
+	lz4FlagVersion          = 0x40
+	lz4FlagBlockIndependent = 0x20
+	lz4FlagBlockChecksum    = 0x10
+	lz4FlagContentSize      = 0x08
+	lz4FlagContentChecksum  = 0x04
+	lz4FlagDictID           = 0x01
+
+	lz4BlockUncompressed = 0x80000000
+
+	xxh32Prime1 uint32 = 2654435761
+	xxh32Prime2 uint32 = 2246822519
+	xxh32Prime3 uint32 = 3266489917
+	xxh32Prime4 uint32 = 668265263
+	xxh32Prime5 uint32 = 374761393
+)
+
+// lz4Reader decompresses a stream of lz4 frames, as written by the lz4 command line tool. The legacy frame format
+// and frames compressed with a dictionary are not supported. The header checksum and the optional block and content
+// checksums are verified.
+type lz4Reader struct {
+	src *bufio.Reader
+	// inFrame is true when the header of the current frame has been read
+	inFrame         bool
+	independent     bool
+	blockChecksum   bool
+	contentChecksum bool
+	maxBlockSize    int
+	// out holds the history needed by dependent blocks followed by the decoded data not read yet, starting at pos
+	out []byte
+	pos int
+	// compressed is the buffer of the current compressed block
+	compressed []byte
+	// content is the checksum of the data decoded from the current frame
+	content xxh32
+}
+
+func newLz4Reader(r io.Reader) *lz4Reader {
+	return &lz4Reader{src: bufio.NewReader(r)}
+}
+
+// Read returns the decompressed data
+func (r *lz4Reader) Read(p []byte) (int, error) {
+	for r.pos == len(r.out) {
+		if err := r.nextBlock(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+// nextBlock decodes the next block in r.out, reading frame headers and end marks as needed.
+func (r *lz4Reader) nextBlock() error {
+	if !r.inFrame {
+		if err := r.readFrameHeader(); err != nil {
+			return err
+		}
+	}
+	size, err := r.readUint32()
+	if err != nil {
+		return errors.Wrap(unexpectedEOF(err), "unable to read lz4 block size")
+	}
+	if size == 0 {
+		// End mark of the frame
+		if r.contentChecksum {
+			checksum, err := r.readUint32()
+			if err != nil {
+				return errors.Wrap(unexpectedEOF(err), "unable to read lz4 content checksum")
+			}
+			if checksum != r.content.sum32() {
+				return errors.New("lz4 content checksum mismatch")
+			}
+		}
+		r.inFrame = false
+		return nil
+	}
+	uncompressed := size&lz4BlockUncompressed != 0
+	size &^= lz4BlockUncompressed
+	if int(size) > r.maxBlockSize {
+		return errors.Errorf("lz4 block size %d larger than the maximum %d", size, r.maxBlockSize)
+	}
+	if cap(r.compressed) < int(size) {
+		r.compressed = make([]byte, size)
+	}
+	block := r.compressed[:size]
+	if _, err := io.ReadFull(r.src, block); err != nil {
+		return errors.Wrap(unexpectedEOF(err), "unable to read lz4 block")
+	}
+	if r.blockChecksum {
+		checksum, err := r.readUint32()
+		if err != nil {
+			return errors.Wrap(unexpectedEOF(err), "unable to read lz4 block checksum")
+		}
+		if checksum != xxh32Sum(block) {
+			return errors.New("lz4 block checksum mismatch")
+		}
+	}
+
+	// Keep the end of the previous blocks as history for the matches of dependent blocks.
+	history := 0
+	if !r.independent {
+		history = len(r.out)
+		if history > lz4WindowSize {
+			history = lz4WindowSize
+		}
+	}
+	out := make([]byte, history, history+r.maxBlockSize)
+	copy(out, r.out[len(r.out)-history:])
+	if uncompressed {
+		out = append(out, block...)
+	} else {
+		out, err = lz4DecodeBlock(out, block, history+r.maxBlockSize)
+		if err != nil {
+			return err
+		}
+	}
+	if r.contentChecksum {
+		r.content.write(out[history:])
+	}
+	r.out = out
+	r.pos = history
+	return nil
+}
+
+func (r *lz4Reader) readFrameHeader() error {
+	for {
+		magic, err := r.readUint32()
+		if err != nil {
+			if err == io.EOF && r.out != nil {
+				// Clean end of the stream after at least one frame
+				return io.EOF
+			}
+			return errors.Wrap(unexpectedEOF(err), "unable to read lz4 frame header")
+		}
+		if magic&lz4SkippableMagicMask == lz4SkippableMagic {
+			size, err := r.readUint32()
+			if err != nil {
+				return errors.Wrap(unexpectedEOF(err), "unable to read lz4 skippable frame")
+			}
+			if err := r.skip(int64(size)); err != nil {
+				return errors.Wrap(unexpectedEOF(err), "unable to read lz4 skippable frame")
+			}
+			continue
+		}
+		if magic != lz4FrameMagic {
+			return errors.Errorf("invalid lz4 frame magic number %#x", magic)
+		}
+		break
+	}
+	descriptor := make([]byte, 2)
+	if _, err := io.ReadFull(r.src, descriptor); err != nil {
+		return errors.Wrap(unexpectedEOF(err), "unable to read lz4 frame descriptor")
+	}
+	flags, bd := descriptor[0], descriptor[1]
+	if flags&lz4FlagVersionMask != lz4FlagVersion {
+		return errors.Errorf("unsupported lz4 frame version in flags %#x", flags)
+	}
+	if flags&lz4FlagDictID != 0 {
+		return errors.New("lz4 frames compressed with a dictionary are not supported")
+	}
+	if flags&lz4FlagContentSize != 0 {
+		contentSize := make([]byte, 8)
+		if _, err := io.ReadFull(r.src, contentSize); err != nil {
+			return errors.Wrap(unexpectedEOF(err), "unable to read lz4 frame descriptor")
+		}
+		descriptor = append(descriptor, contentSize...)
+	}
+	headerChecksum, err := r.src.ReadByte()
+	if err != nil {
+		return errors.Wrap(unexpectedEOF(err), "unable to read lz4 frame descriptor")
+	}
+	if headerChecksum != byte(xxh32Sum(descriptor)>>8) {
+		return errors.New("lz4 frame header checksum mismatch")
+	}
+	blockSizeID := (bd >> 4) & 0x7
+	if blockSizeID < 4 {
+		return errors.Errorf("invalid lz4 block maximum size in %#x", bd)
+	}
+	r.maxBlockSize = 1 << (8 + 2*blockSizeID)
+	r.independent = flags&lz4FlagBlockIndependent != 0
+	r.blockChecksum = flags&lz4FlagBlockChecksum != 0
+	r.contentChecksum = flags&lz4FlagContentChecksum != 0
+	r.content = newXxh32()
+	if r.out == nil {
+		r.out = []byte{}
+	}
+	// Blocks never reference the data of a previous frame.
+	r.out = r.out[:0]
+	r.pos = 0
+	r.inFrame = true
+	return nil
+}
+
+func (r *lz4Reader) readUint32() (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r.src, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+func (r *lz4Reader) skip(n int64) error {
+	_, err := io.CopyN(ioutil.Discard, r.src, n)
+	return err
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// lz4DecodeBlock appends the decompressed content of the lz4 block src to dst, matches may reference the data
+// already in dst. The block is corrupt if dst would grow over limit bytes.
+func lz4DecodeBlock(dst, src []byte, limit int) ([]byte, error) {
+	errCorrupt := errors.New("corrupt lz4 block")
+	i := 0
+	for {
+		if i >= len(src) {
+			return nil, errCorrupt
+		}
+		token := src[i]
+		i++
+		literals := int(token >> 4)
+		if literals == 0xF {
+			for {
+				if i >= len(src) {
+					return nil, errCorrupt
+				}
+				b := src[i]
+				i++
+				literals += int(b)
+				if b != 0xFF {
+					break
+				}
+			}
+		}
+		if literals > len(src)-i || literals > limit-len(dst) {
+			return nil, errCorrupt
+		}
+		dst = append(dst, src[i:i+literals]...)
+		i += literals
+		if i == len(src) {
+			// The last sequence only contains literals
+			return dst, nil
+		}
+		if i+2 > len(src) {
+			return nil, errCorrupt
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, errCorrupt
+		}
+		length := int(token & 0xF)
+		if length == 0xF {
+			for {
+				if i >= len(src) {
+					return nil, errCorrupt
+				}
+				b := src[i]
+				i++
+				length += int(b)
+				if b != 0xFF {
+					break
+				}
+			}
+		}
+		length += lz4MinMatch
+		if length > limit-len(dst) {
+			return nil, errCorrupt
+		}
+		// The match may overlap the data it produces, so copy it in chunks of at most offset bytes.
+		start := len(dst) - offset
+		for length > 0 {
+			n := length
+			if n > offset {
+				n = offset
+			}
+			dst = append(dst, dst[start:start+n]...)
+			start += n
+			length -= n
+		}
+	}
+}
+
+// xxh32 is the streaming state of the 32 bits xxHash the lz4 checksums are computed with, with a zero seed.
+type xxh32 struct {
+	v     [4]uint32
+	total uint64
+	// buf holds the data not processed yet, less than a 16 bytes stripe
+	buf  [16]byte
+	nbuf int
+}
+
+func newXxh32() xxh32 {
+	// the accumulators wrap around, which constant expressions don't
+	prime1, prime2 := xxh32Prime1, xxh32Prime2
+	return xxh32{v: [4]uint32{prime1 + prime2, prime2, 0, -prime1}}
+}
+
+// xxh32Sum returns the xxHash of p
+func xxh32Sum(p []byte) uint32 {
+	h := newXxh32()
+	h.write(p)
+	return h.sum32()
+}
+
+func xxh32Round(acc, input uint32) uint32 {
+	return bits.RotateLeft32(acc+input*xxh32Prime2, 13) * xxh32Prime1
+}
+
+func (h *xxh32) write(p []byte) {
+	h.total += uint64(len(p))
+	if h.nbuf > 0 {
+		n := copy(h.buf[h.nbuf:], p)
+		h.nbuf += n
+		p = p[n:]
+		if h.nbuf < len(h.buf) {
+			return
+		}
+		h.stripe(h.buf[:])
+		h.nbuf = 0
+	}
+	for ; len(p) >= len(h.buf); p = p[len(h.buf):] {
+		h.stripe(p)
+	}
+	h.nbuf = copy(h.buf[:], p)
+}
+
+func (h *xxh32) stripe(p []byte) {
+	for i := range h.v {
+		h.v[i] = xxh32Round(h.v[i], binary.LittleEndian.Uint32(p[4*i:]))
+	}
+}
+
+func (h *xxh32) sum32() uint32 {
+	var sum uint32
+	if h.total >= uint64(len(h.buf)) {
+		sum = bits.RotateLeft32(h.v[0], 1) + bits.RotateLeft32(h.v[1], 7) + bits.RotateLeft32(h.v[2], 12) +
+			bits.RotateLeft32(h.v[3], 18)
+	} else {
+		sum = h.v[2] + xxh32Prime5
+	}
+	sum += uint32(h.total)
+	p := h.buf[:h.nbuf]
+	for ; len(p) >= 4; p = p[4:] {
+		sum = bits.RotateLeft32(sum+binary.LittleEndian.Uint32(p)*xxh32Prime3, 17) * xxh32Prime4
+	}
+	for _, b := range p {
+		sum = bits.RotateLeft32(sum+uint32(b)*xxh32Prime5, 11) * xxh32Prime1
+	}
+	sum ^= sum >> 15
+	sum *= xxh32Prime2
+	sum ^= sum >> 13
+	sum *= xxh32Prime3
+	sum ^= sum >> 16
+	return sum
+}
@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("lz4 reader", func() {
+	var compressed []byte
+
+	BeforeEach(func() {
+		var err error
+		compressed, err = ioutil.ReadFile(filepath.Join("testdata", "image.raw.lz4"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should read concatenated frames", func() {
+		single, err := ioutil.ReadAll(newLz4Reader(bytes.NewReader(compressed)))
+		Expect(err).ToNot(HaveOccurred())
+		// A skippable frame between two frames is ignored
+		skippable := []byte{0x5A, 0x2A, 0x4D, 0x18, 0x02, 0x00, 0x00, 0x00, 0xAB, 0xCD}
+		data := append(append(append([]byte{}, compressed...), skippable...), compressed...)
+		got, err := ioutil.ReadAll(newLz4Reader(bytes.NewReader(data)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(Equal(append(single, single...)))
+	})
+
+	table.DescribeTable("should fail", func(corrupt func([]byte) []byte) {
+		_, err := ioutil.ReadAll(newLz4Reader(bytes.NewReader(corrupt(append([]byte{}, compressed...)))))
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("on an empty stream", func(b []byte) []byte { return nil }),
+		table.Entry("on a truncated stream", func(b []byte) []byte { return b[:len(b)/2] }),
+		table.Entry("on an invalid magic number", func(b []byte) []byte {
+			b[0] = 0
+			return b
+		}),
+		table.Entry("on an unsupported version", func(b []byte) []byte {
+			b[4] = 0x80
+			return b
+		}),
+		table.Entry("on a block larger than the maximum size", func(b []byte) []byte {
+			// The first block size follows the 15 bytes of the frame header, with the content size
+			b[15], b[16], b[17], b[18] = 0xFF, 0xFF, 0xFF, 0x00
+			return b
+		}),
+	)
+
+	table.DescribeTable("should verify the checksum", func(corrupt func([]byte) []byte) {
+		_, err := ioutil.ReadAll(newLz4Reader(bytes.NewReader(corrupt(append([]byte{}, compressed...)))))
+		Expect(err).To(MatchError(ContainSubstring("checksum mismatch")))
+	},
+		table.Entry("of the frame header", func(b []byte) []byte {
+			b[14]++
+			return b
+		}),
+		table.Entry("of a block", func(b []byte) []byte {
+			// The first block follows the 15 bytes of the frame header and its size
+			b[19]++
+			return b
+		}),
+		table.Entry("of the content", func(b []byte) []byte {
+			b[len(b)-1]++
+			return b
+		}),
+	)
+
+	table.DescribeTable("should detect corrupt blocks", func(block []byte) {
+		_, err := lz4DecodeBlock(nil, block, 1024)
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("with a match before the start of the data", []byte{0x10, 'a', 0x02, 0x00}),
+		table.Entry("with a zero offset", []byte{0x10, 'a', 0x00, 0x00}),
+		table.Entry("with literals past the end of the block", []byte{0x50, 'a'}),
+		table.Entry("with a match past the limit", []byte{0x1F, 'a', 0x01, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0x00}),
+	)
+
+	table.DescribeTable("should compute the xxHash of", func(data string, sum uint32) {
+		Expect(xxh32Sum([]byte(data))).To(Equal(sum))
+		h := newXxh32()
+		for i := range data {
+			h.write([]byte{data[i]})
+		}
+		Expect(h.sum32()).To(Equal(sum))
+	},
+		table.Entry("empty data", "", uint32(0x02CC5D05)),
+		table.Entry("less than a stripe", "abc", uint32(0x32D153FF)),
+		table.Entry("several stripes", "Nobody inspects the spammish repetition", uint32(0xE2293B2F)),
+	)
+
+	It("should decode overlapping matches", func() {
+		// One literal, repeated by a match of 4+3 bytes at offset 1, then two final literals
+		got, err := lz4DecodeBlock(nil, []byte{0x13, 'a', 0x01, 0x00, 0x20, 'b', 'c'}, 1024)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(got)).To(Equal("aaaaaaaabc"))
+	})
+})
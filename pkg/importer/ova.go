@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"archive/tar"
+	"encoding/xml"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// ovfEnvelope is the part of the OVF descriptor needed to locate the disks in an OVA archive.
+// Note: encoding/xml matches the ovf:, rasd: etc. prefixed names by their local name.
+type ovfEnvelope struct {
+	Files []ovfFile `xml:"References>File"`
+	Disks []ovfDisk `xml:"DiskSection>Disk"`
+}
+
+type ovfFile struct {
+	ID        string `xml:"id,attr"`
+	Href      string `xml:"href,attr"`
+	ChunkSize int64  `xml:"chunkSize,attr"`
+}
+
+type ovfDisk struct {
+	DiskID  string `xml:"diskId,attr"`
+	FileRef string `xml:"fileRef,attr"`
+}
+
+// ovaDiskReader reads one disk file from the stream of an OVA archive.
+type ovaDiskReader struct {
+	*tar.Reader
+	stream io.ReadCloser
+	// size is the size of the disk file in the archive
+	size int64
+}
+
+// Close closes the OVA stream
+func (r *ovaDiskReader) Close() error {
+	return r.stream.Close()
+}
+
+// newOVADiskReader reads the OVF descriptor at the start of the OVA stream, and skips the archive up to the disk
+// file selected by disk, which is either the index of the disk in the DiskSection or the href of its file. Only the
+// tar headers are parsed, the other files are read and discarded, so nothing but the selected disk is stored.
+func newOVADiskReader(stream io.ReadCloser, disk string) (*ovaDiskReader, error) {
+	tarReader := tar.NewReader(stream)
+	hdr, err := tarReader.Next()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the OVA archive")
+	}
+	// The OVF specification requires the descriptor to be the first file of the archive.
+	if path.Ext(hdr.Name) != ".ovf" {
+		return nil, errors.Errorf("the OVA archive does not start with an OVF descriptor, found %q", hdr.Name)
+	}
+	envelope := &ovfEnvelope{}
+	if err := xml.NewDecoder(tarReader).Decode(envelope); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse the OVF descriptor %q", hdr.Name)
+	}
+	file, err := envelope.diskFile(disk)
+	if err != nil {
+		return nil, err
+	}
+	if file.ChunkSize > 0 {
+		return nil, errors.Errorf("disk file %q is split in chunks, which is not supported", file.Href)
+	}
+	klog.V(1).Infof("Importing disk file %q from the OVA archive", file.Href)
+	for {
+		hdr, err = tarReader.Next()
+		if err == io.EOF {
+			return nil, errors.Errorf("disk file %q not found in the OVA archive", file.Href)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the OVA archive")
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Clean(hdr.Name) == path.Clean(file.Href) {
+			return &ovaDiskReader{Reader: tarReader, stream: stream, size: hdr.Size}, nil
+		}
+		klog.V(3).Infof("Skipping %q in the OVA archive", hdr.Name)
+	}
+}
+
+// diskFile returns the file of the selected disk, the selector is either the index of the disk or the href of its file.
+func (e *ovfEnvelope) diskFile(disk string) (*ovfFile, error) {
+	if len(e.Disks) == 0 {
+		return nil, errors.New("the OVF descriptor does not contain any disk")
+	}
+	if index, err := strconv.Atoi(disk); err == nil {
+		if index < 0 || index >= len(e.Disks) {
+			return nil, errors.Errorf("disk index %d out of range, the OVF descriptor contains %d disks", index, len(e.Disks))
+		}
+		return e.file(e.Disks[index].FileRef)
+	}
+	for _, d := range e.Disks {
+		file, err := e.file(d.FileRef)
+		if err == nil && strings.TrimPrefix(file.Href, "./") == strings.TrimPrefix(disk, "./") {
+			return file, nil
+		}
+	}
+	return nil, errors.Errorf("disk %q not found in the OVF descriptor", disk)
+}
+
+func (e *ovfEnvelope) file(id string) (*ovfFile, error) {
+	for i := range e.Files {
+		if e.Files[i].ID == id {
+			return &e.Files[i], nil
+		}
+	}
+	return nil, errors.Errorf("file %q referenced by a disk not found in the OVF descriptor", id)
+}
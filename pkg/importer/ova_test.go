@@ -0,0 +1,156 @@
+package importer
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+)
+
+const testOVF = `<?xml version="1.0" encoding="UTF-8"?>
+<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1">
+  <References>
+    <File ovf:href="appliance-disk1.vmdk" ovf:id="file1" ovf:size="4096"/>
+    <File ovf:href="appliance-disk2.img" ovf:id="file2" ovf:size="4096"/>
+  </References>
+  <DiskSection>
+    <Info>Virtual disk information</Info>
+    <Disk ovf:capacity="1" ovf:capacityAllocationUnits="byte * 2^30" ovf:diskId="vmdisk1" ovf:fileRef="file1" ovf:format="http://www.vmware.com/interfaces/specifications/vmdk.html#streamOptimized"/>
+    <Disk ovf:capacity="1" ovf:capacityAllocationUnits="byte * 2^30" ovf:diskId="vmdisk2" ovf:fileRef="file2"/>
+  </DiskSection>
+</Envelope>
+`
+
+type ovaEntry struct {
+	name string
+	data []byte
+}
+
+func createOVA(entries ...ovaEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		err := tw.WriteHeader(&tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.data)), Typeflag: tar.TypeReg})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = tw.Write(e.data)
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+var _ = Describe("OVA disk reader", func() {
+	vmdkDisk := append([]byte("KDMV"), bytes.Repeat([]byte{1}, 4092)...)
+	rawDisk := bytes.Repeat([]byte("raw disk"), 512)
+	ova := func() []byte {
+		return createOVA(
+			ovaEntry{"appliance.ovf", []byte(testOVF)},
+			ovaEntry{"appliance.mf", []byte("SHA256(appliance-disk1.vmdk)= 0000")},
+			ovaEntry{"appliance-disk1.vmdk", vmdkDisk},
+			ovaEntry{"appliance-disk2.img", rawDisk},
+		)
+	}
+
+	table.DescribeTable("should read the selected disk", func(disk string, want []byte) {
+		reader, err := newOVADiskReader(ioutil.NopCloser(bytes.NewReader(ova())), disk)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reader.size).To(Equal(int64(len(want))))
+		got, err := ioutil.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(Equal(want))
+		Expect(reader.Close()).To(Succeed())
+	},
+		table.Entry("by index", "0", vmdkDisk),
+		table.Entry("by index of a later disk", "1", rawDisk),
+		table.Entry("by file reference", "appliance-disk2.img", rawDisk),
+		table.Entry("by relative file reference", "./appliance-disk1.vmdk", vmdkDisk),
+	)
+
+	table.DescribeTable("should fail", func(data func() []byte, disk string) {
+		_, err := newOVADiskReader(ioutil.NopCloser(bytes.NewReader(data())), disk)
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("with an index out of range", ova, "2"),
+		table.Entry("with a negative index", ova, "-1"),
+		table.Entry("with an unknown file reference", ova, "appliance-disk3.vmdk"),
+		table.Entry("if the disk file is missing", func() []byte {
+			return createOVA(ovaEntry{"appliance.ovf", []byte(testOVF)}, ovaEntry{"appliance-disk2.img", rawDisk})
+		}, "0"),
+		table.Entry("if the archive does not start with the descriptor", func() []byte {
+			return createOVA(ovaEntry{"appliance-disk1.vmdk", vmdkDisk}, ovaEntry{"appliance.ovf", []byte(testOVF)})
+		}, "0"),
+		table.Entry("if the descriptor is invalid", func() []byte {
+			return createOVA(ovaEntry{"appliance.ovf", []byte("<Envelope>")})
+		}, "0"),
+		table.Entry("if the data is not a tar archive", func() []byte {
+			return []byte("not an archive")
+		}, "0"),
+	)
+
+	Context("with an http source", func() {
+		var (
+			ts     *httptest.Server
+			tmpDir string
+			dp     *HTTPDataSource
+		)
+
+		BeforeEach(func() {
+			data := ova()
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(data)
+			}))
+			var err error
+			tmpDir, err = ioutil.TempDir("", "ova")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			if dp != nil {
+				dp.Close()
+			}
+			ts.Close()
+			os.RemoveAll(tmpDir)
+		})
+
+		It("should transfer a vmdk disk to scratch space for conversion", func() {
+			var err error
+			dp, err = NewHTTPDataSource(ts.URL+"/appliance.ova", "", "", "", cdiv1.DataVolumeKubeVirt, "", "0", 1)
+			Expect(err).NotTo(HaveOccurred())
+			phase, err := dp.Info()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(ProcessingPhaseTransferScratch))
+			phase, err = dp.Transfer(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(ProcessingPhaseConvert))
+			Expect(dp.GetURL().String()).To(Equal(filepath.Join(tmpDir, tempFile)))
+			got, err := ioutil.ReadFile(filepath.Join(tmpDir, tempFile))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(vmdkDisk))
+		})
+
+		It("should write a raw disk directly to the target", func() {
+			var err error
+			dp, err = NewHTTPDataSource(ts.URL+"/appliance.ova", "", "", "", cdiv1.DataVolumeKubeVirt, "", "appliance-disk2.img", 1)
+			Expect(err).NotTo(HaveOccurred())
+			phase, err := dp.Info()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(ProcessingPhaseTransferDataFile))
+			target := filepath.Join(tmpDir, "disk.img")
+			phase, err = dp.TransferFile(target)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(ProcessingPhaseResize))
+			got, err := ioutil.ReadFile(target)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(rawDisk))
+		})
+	})
+})
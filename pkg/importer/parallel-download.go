@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// parallelChunkSize is the size of the ranges downloaded in parallel, may be overridden in tests
+var parallelChunkSize = int64(32 * 1024 * 1024)
+
+// rangeFetcher returns the data of an object from start to end, excluded.
+type rangeFetcher func(start, end int64) (io.ReadCloser, error)
+
+// downloadRanges downloads the size bytes of an object with up to concurrency parallel ranged requests, and writes
+// each range at its offset in the file or block device fileName. A range failing midway is requested again from
+// where it stopped. progress is called with the number of bytes received, from several goroutines.
+func downloadRanges(fetch rangeFetcher, size int64, concurrency int, fileName string, progress func(int64)) error {
+	outFile, err := util.OpenFileOrBlockDevice(fileName)
+	if err != nil {
+		return err
+	}
+	isBlock := false
+	if info, err := outFile.Stat(); err == nil {
+		isBlock = info.Mode()&os.ModeDevice != 0
+	}
+	writer, err := util.NewSparseWriter(outFile)
+	if err != nil {
+		outFile.Close()
+		return err
+	}
+	klog.V(1).Infof("Writing data with %d parallel requests...\n", concurrency)
+	if err := writeRanges(fetch, size, concurrency, &lockedWriterAt{w: writer}, progress); err != nil {
+		klog.Errorf("Unable to write file from ranges: %v\n", err)
+		outFile.Close()
+		if !isBlock {
+			os.Remove(outFile.Name())
+		}
+		return errors.Wrapf(err, "unable to write to file")
+	}
+	klog.V(1).Infof("Wrote %d non-zero bytes to %s\n", writer.BytesWritten(), fileName)
+	return writer.Close()
+}
+
+// writeRanges splits the object in chunks downloaded by concurrency workers, and returns the first error.
+func writeRanges(fetch rangeFetcher, size int64, concurrency int, w io.WriterAt, progress func(int64)) error {
+	chunks := make(chan int64)
+	failed := make(chan struct{})
+	var (
+		wg       sync.WaitGroup
+		failOnce sync.Once
+		firstErr error
+	)
+	go func() {
+		defer close(chunks)
+		for start := int64(0); start < size; start += parallelChunkSize {
+			select {
+			case chunks <- start:
+			case <-failed:
+				return
+			}
+		}
+	}()
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := start + parallelChunkSize
+				if end > size {
+					end = size
+				}
+				if err := writeRange(fetch, start, end, w, progress); err != nil {
+					failOnce.Do(func() {
+						firstErr = err
+						close(failed)
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// writeRange downloads one range, requesting the rest of the range again when reading fails.
+func writeRange(fetch rangeFetcher, start, end int64, w io.WriterAt, progress func(int64)) error {
+	retries := 0
+	for {
+		n, err := copyRange(fetch, start, end, w, progress)
+		start += n
+		if err == nil {
+			return nil
+		}
+		if n > 0 {
+			retries = 0
+		}
+		if retries >= maxRangeRetries {
+			return err
+		}
+		retries++
+		klog.Warningf("Error downloading range at offset %d, retrying: %v", start, err)
+		time.Sleep(rangeRetryDelay)
+	}
+}
+
+func copyRange(fetch rangeFetcher, start, end int64, w io.WriterAt, progress func(int64)) (int64, error) {
+	body, err := fetch(start, end)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	n, err := io.CopyBuffer(&offsetWriter{w: w, off: start, progress: progress}, io.LimitReader(body, end-start), make([]byte, 256*1024))
+	if err == nil && n < end-start {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// offsetWriter writes sequentially to an io.WriterAt, from an initial offset.
+type offsetWriter struct {
+	w        io.WriterAt
+	off      int64
+	progress func(int64)
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.off)
+	o.off += int64(n)
+	o.progress(int64(n))
+	return n, err
+}
+
+// lockedWriterAt serializes the writes of the parallel downloads, the SparseWriter keeps track of the written ranges.
+type lockedWriterAt struct {
+	lock sync.Mutex
+	w    io.WriterAt
+}
+
+func (l *lockedWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.w.WriteAt(p, off)
+}
@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+)
+
+// failingReader returns the data, then fails instead of returning io.EOF.
+type failingReader struct {
+	io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		err = errors.New("connection reset")
+	}
+	return n, err
+}
+
+func (r *failingReader) Close() error {
+	return nil
+}
+
+// rangeS3Client serves ranges of an object
+type rangeS3Client struct {
+	data   []byte
+	lock   sync.Mutex
+	ranges []string
+}
+
+func (c *rangeS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	if input.Range == nil {
+		return &s3.GetObjectOutput{
+			Body:          ioutil.NopCloser(bytes.NewReader(c.data)),
+			ContentLength: aws.Int64(int64(len(c.data))),
+			ETag:          aws.String(`"v1"`),
+		}, nil
+	}
+	c.lock.Lock()
+	c.ranges = append(c.ranges, *input.Range)
+	c.lock.Unlock()
+	if aws.StringValue(input.IfMatch) != `"v1"` {
+		return nil, errors.New("PreconditionFailed")
+	}
+	var start, end int
+	if _, err := fmt.Sscanf(*input.Range, "bytes=%d-%d", &start, &end); err != nil {
+		return nil, err
+	}
+	return &s3.GetObjectOutput{
+		Body:         ioutil.NopCloser(bytes.NewReader(c.data[start : end+1])),
+		ContentRange: aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, len(c.data))),
+	}, nil
+}
+
+func rawRangeTestData() []byte {
+	data := rangeTestData()
+	copy(data, make([]byte, 4))
+	return data
+}
+
+var _ = Describe("Parallel download", func() {
+	var (
+		tmpDir    string
+		data      []byte
+		oldChunk  int64
+		oldDelay  time.Duration
+		oldClient func(endpoint, accessKey, secKey string) (S3Client, error)
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "parallel")
+		Expect(err).NotTo(HaveOccurred())
+		data = rawRangeTestData()
+		oldChunk = parallelChunkSize
+		parallelChunkSize = 100000
+		oldDelay = rangeRetryDelay
+		rangeRetryDelay = time.Millisecond
+		oldClient = newClientFunc
+	})
+
+	AfterEach(func() {
+		parallelChunkSize = oldChunk
+		rangeRetryDelay = oldDelay
+		newClientFunc = oldClient
+		os.RemoveAll(tmpDir)
+	})
+
+	It("should write the ranges at their offset", func() {
+		var received int64
+		var lock sync.Mutex
+		fetch := func(start, end int64) (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(data[start:end])), nil
+		}
+		target := filepath.Join(tmpDir, "disk.img")
+		err := downloadRanges(fetch, int64(len(data)), 4, target, func(n int64) {
+			lock.Lock()
+			defer lock.Unlock()
+			received += n
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(Equal(int64(len(data))))
+		got, err := ioutil.ReadFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(data))
+	})
+
+	It("should request the rest of a range failing midway", func() {
+		var lock sync.Mutex
+		failed := map[int64]bool{}
+		fetch := func(start, end int64) (io.ReadCloser, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			chunk := start / parallelChunkSize
+			if !failed[chunk] {
+				failed[chunk] = true
+				return &failingReader{bytes.NewReader(data[start : start+(end-start)/2])}, nil
+			}
+			return ioutil.NopCloser(bytes.NewReader(data[start:end])), nil
+		}
+		target := filepath.Join(tmpDir, "disk.img")
+		Expect(downloadRanges(fetch, int64(len(data)), 3, target, func(int64) {})).To(Succeed())
+		got, err := ioutil.ReadFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(data))
+	})
+
+	It("should fail and remove the file if a range keeps failing", func() {
+		fetch := func(start, end int64) (io.ReadCloser, error) {
+			if start >= 500000 {
+				return nil, errors.New("range failed")
+			}
+			return ioutil.NopCloser(bytes.NewReader(data[start:end])), nil
+		}
+		target := filepath.Join(tmpDir, "disk.img")
+		Expect(downloadRanges(fetch, int64(len(data)), 4, target, func(int64) {})).ToNot(Succeed())
+		_, err := os.Stat(target)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should download an http source with parallel requests", func() {
+		ts := newRangeTestServer(data, `"v1"`)
+		defer ts.Close()
+		dp, err := NewHTTPDataSource(ts.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 4)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		phase, err := dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferDataFile))
+		target := filepath.Join(tmpDir, "disk.img")
+		phase, err = dp.TransferFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseResize))
+		got, err := ioutil.ReadFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(data))
+		Expect(ts.requestedRanges()).To(HaveLen(11))
+		Expect(ts.requestedRanges()).To(ContainElement("bytes=1000000-1048575"))
+	})
+
+	It("should fail an http download with parallel requests if the object changes", func() {
+		ts := newRangeTestServer(data, `"v1"`)
+		defer ts.Close()
+		dp, err := NewHTTPDataSource(ts.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt, "", "", 4)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		_, err = dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		ts.setETag(`"v2"`)
+		_, err = dp.TransferFile(filepath.Join(tmpDir, "disk.img"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should download an S3 source with parallel requests", func() {
+		client := &rangeS3Client{data: data}
+		newClientFunc = func(endpoint, accessKey, secKey string) (S3Client, error) {
+			return client, nil
+		}
+		sd, err := NewS3DataSource("http://region.amazon.com/bucket-1/disk.img", "", "", "", "", 4)
+		Expect(err).NotTo(HaveOccurred())
+		defer sd.Close()
+		phase, err := sd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferDataFile))
+		target := filepath.Join(tmpDir, "disk.img")
+		phase, err = sd.TransferFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseResize))
+		got, err := ioutil.ReadFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(data))
+		Expect(client.ranges).To(HaveLen(11))
+		// The progress counts the data of the ranges
+		Expect(sd.readers.progressReader).ToNot(BeNil())
+		Expect(sd.readers.progressReader.Current).To(Equal(uint64(len(data))))
+	})
+})
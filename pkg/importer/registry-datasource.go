@@ -47,18 +47,21 @@ type RegistryDataSource struct {
 	certDir     string
 	insecureTLS bool
 	imageDir    string
+	// checksum the disk image file is expected to match, if not empty.
+	checksum string
 	//The discovered image file in scratch space.
 	url *url.URL
 }
 
 // NewRegistryDataSource creates a new instance of the Registry Data Source.
-func NewRegistryDataSource(endpoint, accessKey, secKey, certDir string, insecureTLS bool) *RegistryDataSource {
+func NewRegistryDataSource(endpoint, accessKey, secKey, certDir string, insecureTLS bool, checksum string) *RegistryDataSource {
 	return &RegistryDataSource{
 		endpoint:    endpoint,
 		accessKey:   accessKey,
 		secKey:      secKey,
 		certDir:     certDir,
 		insecureTLS: insecureTLS,
+		checksum:    checksum,
 	}
 }
 
@@ -80,7 +83,7 @@ func (rd *RegistryDataSource) Transfer(path string) (ProcessingPhase, error) {
 	rd.imageDir = filepath.Join(path, containerDiskImageDir)
 
 	klog.V(1).Infof("Copying registry image to scratch space.")
-	err = CopyRegistryImage(rd.endpoint, path, containerDiskImageDir, rd.accessKey, rd.secKey, rd.certDir, rd.insecureTLS)
+	err = CopyRegistryImage(rd.endpoint, path, containerDiskImageDir, rd.accessKey, rd.secKey, rd.certDir, rd.insecureTLS, rd.checksum)
 	if err != nil {
 		return ProcessingPhaseError, errors.Wrapf(err, "Failed to read registry image")
 	}
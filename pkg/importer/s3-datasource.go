@@ -11,11 +11,13 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 
 	"k8s.io/klog/v2"
 
+	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
@@ -34,6 +36,7 @@ var newClientFunc = getS3Client
 // 1. Info -> Transfer
 // 2. Transfer -> Process
 // 3. Process -> Convert
+// qcow2 data is converted while it is transferred to the target instead of scratch space when possible, then Resize.
 type S3DataSource struct {
 	// S3 end point
 	ep *url.URL
@@ -47,30 +50,55 @@ type S3DataSource struct {
 	readers *FormatReaders
 	// The image file in scratch space.
 	url *url.URL
+	// checksum the downloaded data is expected to match, if not empty.
+	checksum string
+	// ovaDisk selects the disk to import when the object is an OVA archive, empty otherwise.
+	ovaDisk string
+	// client, bucket and object locate the object, to download ranges of it.
+	client S3Client
+	bucket string
+	object string
+	// size and etag of the object
+	size int64
+	etag string
+	// concurrency is the number of parallel ranged requests used to download raw data.
+	concurrency int
 }
 
 // NewS3DataSource creates a new instance of the S3DataSource
-func NewS3DataSource(endpoint, accessKey, secKey string) (*S3DataSource, error) {
+func NewS3DataSource(endpoint, accessKey, secKey, checksum, ovaDisk string, concurrency int) (*S3DataSource, error) {
 	ep, err := ParseEndpoint(endpoint)
 	if err != nil {
 		return nil, errors.Wrapf(err, fmt.Sprintf("unable to parse endpoint %q", endpoint))
 	}
-	s3Reader, err := createS3Reader(ep, accessKey, secKey)
-	if err != nil {
+	sd := &S3DataSource{
+		ep:          ep,
+		accessKey:   accessKey,
+		secKey:      secKey,
+		checksum:    checksum,
+		ovaDisk:     ovaDisk,
+		concurrency: concurrency,
+	}
+	if err := sd.getObject(); err != nil {
 		return nil, err
 	}
-	return &S3DataSource{
-		ep:        ep,
-		accessKey: accessKey,
-		secKey:    secKey,
-		s3Reader:  s3Reader,
-	}, nil
+	return sd, nil
 }
 
 // Info is called to get initial information about the data.
 func (sd *S3DataSource) Info() (ProcessingPhase, error) {
 	var err error
-	sd.readers, err = NewFormatReaders(sd.s3Reader, uint64(0))
+	if sd.ovaDisk != "" {
+		var diskReader *ovaDiskReader
+		diskReader, err = newOVADiskReader(sd.s3Reader, sd.ovaDisk)
+		if err != nil {
+			klog.Errorf("Error reading OVA archive: %v", err)
+			return ProcessingPhaseError, err
+		}
+		sd.readers, err = NewFormatReaders(diskReader, uint64(diskReader.size), sd.checksum)
+	} else {
+		sd.readers, err = NewFormatReaders(sd.s3Reader, uint64(sd.size), sd.checksum)
+	}
 	if err != nil {
 		klog.Errorf("Error creating readers: %v", err)
 		return ProcessingPhaseError, err
@@ -95,6 +123,9 @@ func (sd *S3DataSource) Transfer(path string) (ProcessingPhase, error) {
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
+	if err := sd.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
 	// If streaming succeeded, then parsing the file into URL will also succeed, no need to check error status
 	sd.url, _ = url.Parse(file)
 	return ProcessingPhaseConvert, nil
@@ -102,13 +133,87 @@ func (sd *S3DataSource) Transfer(path string) (ProcessingPhase, error) {
 
 // TransferFile is called to transfer the data from the source to the passed in file.
 func (sd *S3DataSource) TransferFile(fileName string) (ProcessingPhase, error) {
-	err := util.StreamDataToFile(sd.readers.TopReader(), fileName)
+	sd.readers.StartProgressUpdate()
+	var err error
+	if sd.parallel() {
+		err = sd.downloadRanges(fileName)
+	} else {
+		err = util.StreamDataToFile(sd.readers.TopReader(), fileName)
+	}
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
+	if err := sd.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseResize, nil
+}
+
+// GetQcow2Header returns the header of the qcow2 data if it can be converted while it is transferred, nil otherwise.
+func (sd *S3DataSource) GetQcow2Header() *image.Qcow2Header {
+	return sd.readers.Qcow2Header()
+}
+
+// TransferConvert is called to convert the qcow2 data to raw while transferring it to the passed in file.
+func (sd *S3DataSource) TransferConvert(fileName, spillDir string) (ProcessingPhase, error) {
+	if err := convertQcow2StreamToFile(sd.readers.TopReader(), fileName, spillDir); err != nil {
+		return ProcessingPhaseError, err
+	}
+	if err := sd.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
 	return ProcessingPhaseResize, nil
 }
 
+// parallel returns true if the data can be downloaded with parallel ranged requests and written as is: it is neither
+// archived nor an OVA archive, and it has no checksum to compute over the sequential stream.
+func (sd *S3DataSource) parallel() bool {
+	return sd.concurrency > 1 && sd.size > 0 && sd.ovaDisk == "" &&
+		!sd.readers.Archived && !sd.readers.Convert && !sd.readers.HasChecksum()
+}
+
+// downloadRanges downloads the data to fileName with parallel ranged requests, instead of the initial response.
+func (sd *S3DataSource) downloadRanges(fileName string) error {
+	klog.Infof("Downloading %d bytes with %d parallel requests", sd.size, sd.concurrency)
+	// Only the header was read from the initial response, stop its transfer.
+	sd.s3Reader.Close()
+	progress := func(n int64) {
+		if sd.readers.progressReader != nil {
+			atomic.AddUint64(&sd.readers.progressReader.Current, uint64(n))
+		}
+	}
+	if sd.readers.progressReader != nil {
+		// The header was read from the initial response, the ranges download it again.
+		atomic.StoreUint64(&sd.readers.progressReader.Current, 0)
+	}
+	return downloadRanges(sd.fetchRange, sd.size, sd.concurrency, fileName, progress)
+}
+
+// fetchRange returns the data of the object from start to end, excluded. The request fails if the object changed
+// since the initial request.
+func (sd *S3DataSource) fetchRange(start, end int64) (io.ReadCloser, error) {
+	objInput := &s3.GetObjectInput{
+		Bucket: aws.String(sd.bucket),
+		Key:    aws.String(sd.object),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", start, end-1)),
+	}
+	if sd.etag != "" {
+		objInput.IfMatch = aws.String(sd.etag)
+	}
+	objOutput, err := sd.client.GetObject(objInput)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get range %d-%d of s3 object: \"%s/%s\"", start, end-1, sd.bucket, sd.object)
+	}
+	var rangeStart int64
+	if objOutput.ContentRange != nil {
+		if _, err := fmt.Sscanf(*objOutput.ContentRange, "bytes %d-", &rangeStart); err != nil || rangeStart != start {
+			objOutput.Body.Close()
+			return nil, errors.Errorf("unexpected Content-Range %q requesting the s3 object at offset %d", *objOutput.ContentRange, start)
+		}
+	}
+	return objOutput.Body, nil
+}
+
 // GetURL returns the url that the data processor can use when converting the data.
 func (sd *S3DataSource) GetURL() *url.URL {
 	return sd.url
@@ -123,31 +228,35 @@ func (sd *S3DataSource) Close() error {
 	return err
 }
 
-func createS3Reader(ep *url.URL, accessKey, secKey string) (io.ReadCloser, error) {
+// getObject sends the initial request for the object, its body is the stream of the data source.
+func (sd *S3DataSource) getObject() error {
 	klog.V(3).Infoln("Using S3 client to get data")
 
-	endpoint := ep.Host
+	endpoint := sd.ep.Host
 	klog.Infof("Endpoint %s", endpoint)
-	path := strings.Trim(ep.Path, "/")
-	bucket, object := extractBucketAndObject(path)
+	path := strings.Trim(sd.ep.Path, "/")
+	sd.bucket, sd.object = extractBucketAndObject(path)
 
-	klog.V(1).Infof("bucket %s", bucket)
-	klog.V(1).Infof("object %s", object)
-	svc, err := newClientFunc(endpoint, accessKey, secKey)
+	klog.V(1).Infof("bucket %s", sd.bucket)
+	klog.V(1).Infof("object %s", sd.object)
+	svc, err := newClientFunc(endpoint, sd.accessKey, sd.secKey)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not build s3 client for %q", ep.Host)
+		return errors.Wrapf(err, "could not build s3 client for %q", sd.ep.Host)
 	}
+	sd.client = svc
 
 	objInput := &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(object),
+		Bucket: aws.String(sd.bucket),
+		Key:    aws.String(sd.object),
 	}
 	objOutput, err := svc.GetObject(objInput)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not get s3 object: \"%s/%s\"", bucket, object)
+		return errors.Wrapf(err, "could not get s3 object: \"%s/%s\"", sd.bucket, sd.object)
 	}
-	objectReader := objOutput.Body
-	return objectReader, nil
+	sd.s3Reader = objOutput.Body
+	sd.size = aws.Int64Value(objOutput.ContentLength)
+	sd.etag = aws.StringValue(objOutput.ETag)
+	return nil
 }
 
 func getS3Client(endpoint, accessKey, secKey string) (S3Client, error) {
@@ -168,6 +277,16 @@ func getS3Client(endpoint, accessKey, secKey string) (S3Client, error) {
 	return svc, nil
 }
 
+// GetS3VirtualSize returns the virtual size of the image of the S3 source, reading only the headers of the object.
+func GetS3VirtualSize(endpoint, accessKey, secKey string) (int64, error) {
+	sd, err := NewS3DataSource(endpoint, accessKey, secKey, "", "", 0)
+	if err != nil {
+		return 0, err
+	}
+	defer sd.Close()
+	return probeVirtualSize(sd.s3Reader, uint64(sd.size))
+}
+
 func extractRegion(s string) string {
 	var region string
 	r, _ := regexp.Compile("s3\\.(.+)\\.amazonaws\\.com")
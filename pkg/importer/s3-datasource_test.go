@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"encoding/binary"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"io/ioutil"
 	"os"
@@ -37,19 +38,19 @@ var _ = Describe("S3 data source", func() {
 	})
 
 	It("NewS3DataSource should Error, when passed in an invalid endpoint", func() {
-		sd, err = NewS3DataSource("thisisinvalid#$%#ep", "", "")
+		sd, err = NewS3DataSource("thisisinvalid#$%#ep", "", "", "", "", 1)
 		Expect(err).To(HaveOccurred())
 	})
 
 	It("NewS3DataSource should Error, when failing to create S3 client", func() {
 		newClientFunc = failMockS3Client
-		sd, err = NewS3DataSource("http://amazon.com", "", "")
+		sd, err = NewS3DataSource("http://amazon.com", "", "", "", "", 1)
 		Expect(err).To(HaveOccurred())
 	})
 
 	It("NewS3DataSource should Error, when failing to get object", func() {
 		newClientFunc = createErrMockS3Client
-		sd, err = NewS3DataSource("http://amazon.com", "", "")
+		sd, err = NewS3DataSource("http://amazon.com", "", "", "", "", 1)
 		Expect(err).To(HaveOccurred())
 	})
 
@@ -59,7 +60,7 @@ var _ = Describe("S3 data source", func() {
 		Expect(err).NotTo(HaveOccurred())
 		err = file.Close()
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = file
@@ -72,7 +73,7 @@ var _ = Describe("S3 data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(cirrosFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = file
@@ -85,7 +86,7 @@ var _ = Describe("S3 data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = file
@@ -101,7 +102,7 @@ var _ = Describe("S3 data source", func() {
 		sourceFile, err := os.Open(fileName)
 		Expect(err).NotTo(HaveOccurred())
 
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = sourceFile
@@ -135,7 +136,7 @@ var _ = Describe("S3 data source", func() {
 		sourceFile, err := os.Open(cirrosFilePath)
 		Expect(err).NotTo(HaveOccurred())
 
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = sourceFile
@@ -153,7 +154,7 @@ var _ = Describe("S3 data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = file
@@ -169,7 +170,7 @@ var _ = Describe("S3 data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", "", 1)
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = file
@@ -198,6 +199,32 @@ var _ = Describe("S3 data source", func() {
 })
 
 // MockS3Client is a mock AWS S3 client
+var _ = Describe("S3 virtual size", func() {
+	AfterEach(func() {
+		newClientFunc = getS3Client
+	})
+
+	It("should return the virtual size read from the header of the object", func() {
+		data := make([]byte, 1024)
+		copy(data, []byte{'Q', 'F', 'I', 0xfb})
+		binary.BigEndian.PutUint64(data[24:], 1024*1024)
+		newClientFunc = func(endpoint, accessKey, secKey string) (S3Client, error) {
+			Expect(endpoint).To(Equal("s3.example.com"))
+			return &rangeS3Client{data: data}, nil
+		}
+		size, err := GetS3VirtualSize("http://s3.example.com/bucket/disk.qcow2", "access", "secret")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(Equal(int64(1024 * 1024)))
+	})
+
+	It("should fail if the object can't be read", func() {
+		newClientFunc = createErrMockS3Client
+		_, err := GetS3VirtualSize("http://s3.example.com/bucket/disk.qcow2", "access", "secret")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Failed to get object"))
+	})
+})
+
 type MockS3Client struct {
 	endpoint string
 	accKey   string
@@ -20,17 +20,21 @@ import (
 	"archive/tar"
 	"context"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/containers/image/v5/docker"
 	"github.com/containers/image/v5/image"
+	"github.com/containers/image/v5/manifest"
 	"github.com/containers/image/v5/oci/archive"
 	"github.com/containers/image/v5/pkg/blobinfocache"
 	"github.com/containers/image/v5/types"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
 const (
@@ -98,8 +102,8 @@ func closeImage(src types.ImageSource) {
 	}
 }
 
-func copyFile(tarReader *tar.Reader, dstFile *os.File) error {
-	if _, err := io.Copy(dstFile, tarReader); err != nil {
+func copyFile(reader io.Reader, dstFile *os.File) error {
+	if _, err := io.Copy(dstFile, reader); err != nil {
 		klog.Errorf("Error copying file: %v", err)
 		return errors.Wrap(err, "Error retrieving image")
 	}
@@ -127,7 +131,8 @@ func processLayer(ctx context.Context,
 	destDir string,
 	pathPrefix string,
 	cache types.BlobInfoCache,
-	stopAtFirst bool) (bool, error) {
+	stopAtFirst bool,
+	checksum string) (bool, error) {
 
 	var reader io.ReadCloser
 	reader, _, err := src.GetBlob(ctx, layer, cache)
@@ -135,7 +140,7 @@ func processLayer(ctx context.Context,
 		klog.Errorf("Could not read layer: %v", err)
 		return false, errors.Wrap(err, "Could not read layer")
 	}
-	fr, err := NewFormatReaders(reader, 0)
+	fr, err := NewFormatReaders(reader, 0, "")
 	if err != nil {
 		return false, errors.Wrap(err, "Could not read layer")
 	}
@@ -168,11 +173,29 @@ func processLayer(ctx context.Context,
 				return false, errors.Wrap(err, "Error creating output file")
 			}
 
-			if err := copyFile(tarReader, dstFile); err != nil {
+			// The checksum applies to the extracted file, the layers themselves are verified against their digest.
+			var fileReader io.Reader = tarReader
+			var checksumReader *util.ChecksumReader
+			if checksum != "" {
+				checksumReader, err = util.NewChecksumReader(ioutil.NopCloser(tarReader), checksum)
+				if err != nil {
+					return false, err
+				}
+				fileReader = checksumReader
+			}
+
+			if err := copyFile(fileReader, dstFile); err != nil {
 				klog.Errorf("Could not copy file to scratch space: %v", err)
 				return false, errors.Wrap(err, "Could not copy file to scratch space")
 			}
 
+			if checksumReader != nil {
+				if err := checksumReader.Verify(); err != nil {
+					klog.Errorf("Could not verify file: %v", err)
+					return false, err
+				}
+			}
+
 			found = true
 			if stopAtFirst {
 				return found, nil
@@ -183,7 +206,7 @@ func processLayer(ctx context.Context,
 	return found, nil
 }
 
-func copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir string, insecureRegistry, stopAtFirst bool) error {
+func copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir string, insecureRegistry, stopAtFirst bool, checksum string) error {
 	klog.Infof("Downloading image from '%v', copying file from '%v' to '%v'", url, pathPrefix, destDir)
 
 	ctx, cancel := commandTimeoutContext()
@@ -210,10 +233,13 @@ func copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir stri
 	for _, layer := range layers {
 		klog.Infof("Processing layer %+v", layer)
 
-		found, err = processLayer(ctx, srcCtx, src, layer, destDir, pathPrefix, cache, stopAtFirst)
+		found, err = processLayer(ctx, srcCtx, src, layer, destDir, pathPrefix, cache, stopAtFirst, checksum)
 		if found {
 			break
 		}
+		if util.IsChecksumMismatch(err) {
+			return err
+		}
 		if err != nil {
 			// Skipping layer and trying the next one.
 			// Error already logged in processLayer
@@ -237,8 +263,9 @@ func copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir stri
 // secKey: secretKey for the registry described in url.
 // certDir: directory public CA keys are stored for registry identity verification
 // insecureRegistry: boolean if true will allow insecure registries.
-func CopyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir string, insecureRegistry bool) error {
-	return copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir, insecureRegistry, true)
+// checksum: checksum the extracted file is expected to match, if not empty.
+func CopyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir string, insecureRegistry bool, checksum string) error {
+	return copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir, insecureRegistry, true, checksum)
 }
 
 // CopyRegistryImageAll download image from registry with docker image API. It will extract all files under the pathPrefix
@@ -250,5 +277,36 @@ func CopyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir stri
 // certDir: directory public CA keys are stored for registry identity verification
 // insecureRegistry: boolean if true will allow insecure registries.
 func CopyRegistryImageAll(url, destDir, pathPrefix, accessKey, secKey, certDir string, insecureRegistry bool) error {
-	return copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir, insecureRegistry, false)
+	return copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir, insecureRegistry, false, "")
+}
+
+// GetImageDigest returns the digest of the manifest of the image in the registry, without pulling its layers.
+// url: source registry url.
+// accessKey: accessKey for the registry described in url.
+// secKey: secretKey for the registry described in url.
+// certDir: directory public CA keys are stored for registry identity verification
+// insecureRegistry: boolean if true will allow insecure registries.
+func GetImageDigest(url, accessKey, secKey, certDir string, insecureRegistry bool) (string, error) {
+	klog.Infof("Inspecting image from '%v'", url)
+
+	ctx, cancel := commandTimeoutContext()
+	defer cancel()
+	srcCtx := buildSourceContext(accessKey, secKey, certDir, insecureRegistry)
+
+	src, err := readImageSource(ctx, srcCtx, url)
+	if err != nil {
+		return "", err
+	}
+	defer closeImage(src)
+
+	manifestBlob, _, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		klog.Errorf("Error retrieving image manifest: %v", err)
+		return "", errors.Wrap(err, "Error retrieving image manifest")
+	}
+	digest, err := manifest.Digest(manifestBlob)
+	if err != nil {
+		return "", errors.Wrap(err, "Error computing image manifest digest")
+	}
+	return digest.String(), nil
 }
@@ -40,7 +40,7 @@ var _ = Describe("Registry Importer", func() {
 	})
 
 	It("Should extract a single file", func() {
-		err := CopyRegistryImage(source, tmpDir, "disk/cirros-0.3.4-x86_64-disk.img", "", "", "", false)
+		err := CopyRegistryImage(source, tmpDir, "disk/cirros-0.3.4-x86_64-disk.img", "", "", "", false, "")
 		Expect(err).ToNot(HaveOccurred())
 
 		file := filepath.Join(tmpDir, "disk/cirros-0.3.4-x86_64-disk.img")
@@ -57,7 +57,7 @@ var _ = Describe("Registry Importer", func() {
 		Expect(file).To(BeARegularFile())
 	})
 	It("Should return an error if a single file is not found", func() {
-		err := CopyRegistryImage(source, tmpDir, "disk/invalid.img", "", "", "", false)
+		err := CopyRegistryImage(source, tmpDir, "disk/invalid.img", "", "", "", false, "")
 		Expect(err).To(HaveOccurred())
 
 		file := filepath.Join(tmpDir, "disk/cirros-0.3.4-x86_64-disk.img")
@@ -5,8 +5,10 @@ import (
 	"net/url"
 	"path/filepath"
 
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
+	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
@@ -15,6 +17,7 @@ import (
 // 1a. ProcessingPhaseInfo -> ProcessingPhaseTransferScratch (In Info phase the format readers are configured) In case the readers don't contain a raw file.
 // 1b. ProcessingPhaseInfo -> ProcessingPhaseTransferDataFile, in the case the readers contain a raw file.
 // 2a. ProcessingPhaseTransferScratch -> ProcessingPhaseConvert
+//     Note: qcow2 data is converted while it is transferred to the target instead, then ProcessingPhaseResize
 // 2b. ProcessingPhaseTransferDataFile -> ProcessingPhaseResize
 type UploadDataSource struct {
 	// Data strean
@@ -23,12 +26,15 @@ type UploadDataSource struct {
 	readers *FormatReaders
 	// url to a file in scratch space.
 	url *url.URL
+	// checksum the uploaded data is expected to match, if not empty.
+	checksum string
 }
 
 // NewUploadDataSource creates a new instance of an UploadDataSource
-func NewUploadDataSource(stream io.ReadCloser) *UploadDataSource {
+func NewUploadDataSource(stream io.ReadCloser, checksum string) *UploadDataSource {
 	return &UploadDataSource{
-		stream: stream,
+		stream:   stream,
+		checksum: checksum,
 	}
 }
 
@@ -36,7 +42,7 @@ func NewUploadDataSource(stream io.ReadCloser) *UploadDataSource {
 func (ud *UploadDataSource) Info() (ProcessingPhase, error) {
 	var err error
 	// Hardcoded to only accept kubevirt content type.
-	ud.readers, err = NewFormatReaders(ud.stream, uint64(0))
+	ud.readers, err = NewFormatReaders(ud.stream, uint64(0), ud.checksum)
 	if err != nil {
 		klog.Errorf("Error creating readers: %v", err)
 		return ProcessingPhaseError, err
@@ -63,6 +69,9 @@ func (ud *UploadDataSource) Transfer(path string) (ProcessingPhase, error) {
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
+	if err := ud.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
 	// If we successfully wrote to the file, then the parse will succeed.
 	ud.url, _ = url.Parse(file)
 	return ProcessingPhaseConvert, nil
@@ -74,11 +83,38 @@ func (ud *UploadDataSource) TransferFile(fileName string) (ProcessingPhase, erro
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
+	if err := ud.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
 	// If we successfully wrote to the file, then the parse will succeed.
 	ud.url, _ = url.Parse(fileName)
 	return ProcessingPhaseResize, nil
 }
 
+// GetQcow2Header returns the header of the qcow2 data if it can be converted while it is transferred, nil otherwise.
+func (ud *UploadDataSource) GetQcow2Header() *image.Qcow2Header {
+	return ud.readers.Qcow2Header()
+}
+
+// TransferConvert is called to convert the qcow2 data to raw while transferring it to the passed in file.
+func (ud *UploadDataSource) TransferConvert(fileName, spillDir string) (ProcessingPhase, error) {
+	if err := convertQcow2StreamToFile(ud.readers.TopReader(), fileName, spillDir); err != nil {
+		return ProcessingPhaseError, err
+	}
+	if err := ud.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseResize, nil
+}
+
+// GetFormat returns the format of the uploaded data, detected by Info.
+func (ud *UploadDataSource) GetFormat() string {
+	if ud.readers == nil {
+		return ""
+	}
+	return ud.readers.Format()
+}
+
 // GetURL returns the url that the data processor can use when converting the data.
 func (ud *UploadDataSource) GetURL() *url.URL {
 	return ud.url
@@ -92,6 +128,82 @@ func (ud *UploadDataSource) Close() error {
 	return nil
 }
 
+// StagedUploadDataSource is an UploadDataSource reading data staged in scratch space by a chunked upload. The staged
+// files are kept when the processing fails, so it can be retried without uploading the data again.
+type StagedUploadDataSource struct {
+	UploadDataSource
+	// names of the staged files in scratch space.
+	stagedFiles []string
+}
+
+// NewStagedUploadDataSource creates a new instance of a StagedUploadDataSource
+func NewStagedUploadDataSource(stream io.ReadCloser, checksum string, stagedFiles ...string) *StagedUploadDataSource {
+	return &StagedUploadDataSource{
+		UploadDataSource: UploadDataSource{
+			stream:   stream,
+			checksum: checksum,
+		},
+		stagedFiles: stagedFiles,
+	}
+}
+
+// GetResumeFiles returns the staged files, needed to retry the processing if it fails.
+func (sd *StagedUploadDataSource) GetResumeFiles() []string {
+	return sd.stagedFiles
+}
+
+// ExtentUploadDataSource writes the extent stream of a sparse block device clone to the target, zeroing the ranges
+// the clone source skipped instead of receiving their data.
+// Sequence of phases:
+// 1. ProcessingPhaseInfo -> ProcessingPhaseTransferDataFile
+// 2. ProcessingPhaseTransferDataFile -> ProcessingPhaseResize
+type ExtentUploadDataSource struct {
+	// Data stream
+	stream io.ReadCloser
+	// url to the target file.
+	url *url.URL
+}
+
+// NewExtentUploadDataSource creates a new instance of an ExtentUploadDataSource
+func NewExtentUploadDataSource(stream io.ReadCloser) *ExtentUploadDataSource {
+	return &ExtentUploadDataSource{
+		stream: stream,
+	}
+}
+
+// Info is called to get initial information about the data, the extents are always written directly to the target.
+func (ed *ExtentUploadDataSource) Info() (ProcessingPhase, error) {
+	return ProcessingPhaseTransferDataFile, nil
+}
+
+// Transfer is not supported, the extent stream holds raw data that never needs scratch space.
+func (ed *ExtentUploadDataSource) Transfer(path string) (ProcessingPhase, error) {
+	return ProcessingPhaseError, errors.New("extent streams cannot be transferred to scratch space")
+}
+
+// TransferFile is called to transfer the data from the source to the passed in file.
+func (ed *ExtentUploadDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
+	if err := util.StreamExtentsToFile(ed.stream, fileName); err != nil {
+		return ProcessingPhaseError, err
+	}
+	// If we successfully wrote to the file, then the parse will succeed.
+	ed.url, _ = url.Parse(fileName)
+	return ProcessingPhaseResize, nil
+}
+
+// GetURL returns the url that the data processor can use when converting the data.
+func (ed *ExtentUploadDataSource) GetURL() *url.URL {
+	return ed.url
+}
+
+// Close closes any readers or other open resources.
+func (ed *ExtentUploadDataSource) Close() error {
+	if ed.stream != nil {
+		return ed.stream.Close()
+	}
+	return nil
+}
+
 // AsyncUploadDataSource is an asynchronouse version of an upload data source, that returns finished phase instead
 // of going to post upload processing phases.
 type AsyncUploadDataSource struct {
@@ -101,10 +213,11 @@ type AsyncUploadDataSource struct {
 }
 
 // NewAsyncUploadDataSource creates a new instance of an UploadDataSource
-func NewAsyncUploadDataSource(stream io.ReadCloser) *AsyncUploadDataSource {
+func NewAsyncUploadDataSource(stream io.ReadCloser, checksum string) *AsyncUploadDataSource {
 	return &AsyncUploadDataSource{
 		uploadDataSource: UploadDataSource{
-			stream: stream,
+			stream:   stream,
+			checksum: checksum,
 		},
 		ResumePhase: ProcessingPhaseInfo,
 	}
@@ -130,6 +243,9 @@ func (aud *AsyncUploadDataSource) Transfer(path string) (ProcessingPhase, error)
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
+	if err := aud.uploadDataSource.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
 	// If we successfully wrote to the file, then the parse will succeed.
 	aud.uploadDataSource.url, _ = url.Parse(file)
 	aud.ResumePhase = ProcessingPhaseConvert
@@ -142,12 +258,36 @@ func (aud *AsyncUploadDataSource) TransferFile(fileName string) (ProcessingPhase
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
+	if err := aud.uploadDataSource.readers.VerifyChecksum(); err != nil {
+		return ProcessingPhaseError, err
+	}
 	// If we successfully wrote to the file, then the parse will succeed.
 	aud.uploadDataSource.url, _ = url.Parse(fileName)
 	aud.ResumePhase = ProcessingPhaseResize
 	return ProcessingPhaseValidatePause, nil
 }
 
+// GetQcow2Header returns the header of the qcow2 data if it can be converted while it is transferred, nil otherwise.
+func (aud *AsyncUploadDataSource) GetQcow2Header() *image.Qcow2Header {
+	return aud.uploadDataSource.GetQcow2Header()
+}
+
+// TransferConvert is called to convert the qcow2 data to raw while transferring it to the passed in file.
+func (aud *AsyncUploadDataSource) TransferConvert(fileName, spillDir string) (ProcessingPhase, error) {
+	if _, err := aud.uploadDataSource.TransferConvert(fileName, spillDir); err != nil {
+		return ProcessingPhaseError, err
+	}
+	// If we successfully wrote to the file, then the parse will succeed.
+	aud.uploadDataSource.url, _ = url.Parse(fileName)
+	aud.ResumePhase = ProcessingPhaseResize
+	return ProcessingPhaseValidatePause, nil
+}
+
+// GetFormat returns the format of the uploaded data, detected by Info.
+func (aud *AsyncUploadDataSource) GetFormat() string {
+	return aud.uploadDataSource.GetFormat()
+}
+
 // Close closes any readers or other open resources.
 func (aud *AsyncUploadDataSource) Close() error {
 	return aud.uploadDataSource.Close()
@@ -1,6 +1,8 @@
 package importer
 
 import (
+	"bytes"
+	"encoding/binary"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -9,8 +11,53 @@ import (
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
+// qcow2TestStream returns a qcow2 image of the guest data with 512 bytes clusters, its tables stored first.
+func qcow2TestStream(guest []byte) []byte {
+	const clusterSize = 512
+	be := binary.BigEndian
+	guestClusters := (len(guest) + clusterSize - 1) / clusterSize
+	l2Tables := (guestClusters + clusterSize/8 - 1) / (clusterSize / 8)
+	l1Clusters := (l2Tables*8 + clusterSize - 1) / clusterSize
+	host := make([]byte, (1+l1Clusters)*clusterSize)
+	be.PutUint32(host, 0x514649fb)
+	be.PutUint32(host[4:], 2)
+	be.PutUint32(host[20:], 9)
+	be.PutUint64(host[24:], uint64(len(guest)))
+	be.PutUint32(host[36:], uint32(l2Tables))
+	be.PutUint64(host[40:], clusterSize)
+	for i := 0; i < l2Tables; i++ {
+		l2Offset := len(host)
+		host = append(host, make([]byte, clusterSize)...)
+		be.PutUint64(host[clusterSize+i*8:], uint64(l2Offset))
+		for j := 0; j < clusterSize/8; j++ {
+			start := (i*clusterSize/8 + j) * clusterSize
+			if start >= len(guest) {
+				break
+			}
+			data := make([]byte, clusterSize)
+			copy(data, guest[start:])
+			if bytes.Equal(data, make([]byte, clusterSize)) {
+				continue
+			}
+			be.PutUint64(host[l2Offset+j*8:], uint64(len(host)))
+			host = append(host, data...)
+		}
+	}
+	return host
+}
+
+func qcow2TestGuestData() []byte {
+	guest := make([]byte, 100*1024)
+	for i := 20 * 1024; i < len(guest); i++ {
+		guest[i] = byte(i % 253)
+	}
+	return guest
+}
+
 var _ = Describe("Upload data source", func() {
 	var (
 		ud     *UploadDataSource
@@ -37,7 +84,7 @@ var _ = Describe("Upload data source", func() {
 		Expect(err).NotTo(HaveOccurred())
 		err = file.Close()
 		Expect(err).NotTo(HaveOccurred())
-		ud = NewUploadDataSource(file)
+		ud = NewUploadDataSource(file, "")
 		result, err := ud.Info()
 		Expect(err).To(HaveOccurred())
 		Expect(ProcessingPhaseError).To(Equal(result))
@@ -47,7 +94,7 @@ var _ = Describe("Upload data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(cirrosFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		ud = NewUploadDataSource(file)
+		ud = NewUploadDataSource(file, "")
 		result, err := ud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferScratch).To(Equal(result))
@@ -57,7 +104,7 @@ var _ = Describe("Upload data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		ud = NewUploadDataSource(file)
+		ud = NewUploadDataSource(file, "")
 		result, err := ud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferDataFile).To(Equal(result))
@@ -70,7 +117,7 @@ var _ = Describe("Upload data source", func() {
 		sourceFile, err := os.Open(fileName)
 		Expect(err).NotTo(HaveOccurred())
 
-		ud = NewUploadDataSource(sourceFile)
+		ud = NewUploadDataSource(sourceFile, "")
 		nextPhase, err := ud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferScratch).To(Equal(nextPhase))
@@ -100,7 +147,7 @@ var _ = Describe("Upload data source", func() {
 		sourceFile, err := os.Open(cirrosFilePath)
 		Expect(err).NotTo(HaveOccurred())
 
-		ud = NewUploadDataSource(sourceFile)
+		ud = NewUploadDataSource(sourceFile, "")
 		nextPhase, err := ud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferScratch).To(Equal(nextPhase))
@@ -115,7 +162,7 @@ var _ = Describe("Upload data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		sourceFile, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		ud = NewUploadDataSource(sourceFile)
+		ud = NewUploadDataSource(sourceFile, "")
 		result, err := ud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferDataFile).To(Equal(result))
@@ -128,7 +175,7 @@ var _ = Describe("Upload data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		sourceFile, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		ud = NewUploadDataSource(sourceFile)
+		ud = NewUploadDataSource(sourceFile, "")
 		result, err := ud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferDataFile).To(Equal(result))
@@ -137,8 +184,24 @@ var _ = Describe("Upload data source", func() {
 		Expect(ProcessingPhaseError).To(Equal(result))
 	})
 
+	It("TransferConvert should convert qcow2 data while writing it to the file", func() {
+		guest := qcow2TestGuestData()
+		ud = NewUploadDataSource(ioutil.NopCloser(bytes.NewReader(qcow2TestStream(guest))), "")
+		result, err := ud.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseTransferScratch))
+		Expect(ud.GetQcow2Header()).NotTo(BeNil())
+		target := filepath.Join(tmpDir, "disk.img")
+		result, err = ud.TransferConvert(target, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseResize))
+		got, err := ioutil.ReadFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(guest))
+	})
+
 	It("Close with nil stream should not fail", func() {
-		ud = NewUploadDataSource(nil)
+		ud = NewUploadDataSource(nil, "")
 		err := ud.Close()
 		Expect(err).NotTo(HaveOccurred())
 	})
@@ -170,7 +233,7 @@ var _ = Describe("Async Upload data source", func() {
 		Expect(err).NotTo(HaveOccurred())
 		err = file.Close()
 		Expect(err).NotTo(HaveOccurred())
-		aud = NewAsyncUploadDataSource(file)
+		aud = NewAsyncUploadDataSource(file, "")
 		result, err := aud.Info()
 		Expect(err).To(HaveOccurred())
 		Expect(ProcessingPhaseError).To(Equal(result))
@@ -180,7 +243,7 @@ var _ = Describe("Async Upload data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(cirrosFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		aud = NewAsyncUploadDataSource(file)
+		aud = NewAsyncUploadDataSource(file, "")
 		result, err := aud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferScratch).To(Equal(result))
@@ -190,7 +253,7 @@ var _ = Describe("Async Upload data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		aud = NewAsyncUploadDataSource(file)
+		aud = NewAsyncUploadDataSource(file, "")
 		result, err := aud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferDataFile).To(Equal(result))
@@ -203,7 +266,7 @@ var _ = Describe("Async Upload data source", func() {
 		sourceFile, err := os.Open(fileName)
 		Expect(err).NotTo(HaveOccurred())
 
-		aud = NewAsyncUploadDataSource(sourceFile)
+		aud = NewAsyncUploadDataSource(sourceFile, "")
 		nextPhase, err := aud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferScratch).To(Equal(nextPhase))
@@ -224,7 +287,7 @@ var _ = Describe("Async Upload data source", func() {
 		sourceFile, err := os.Open(cirrosFilePath)
 		Expect(err).NotTo(HaveOccurred())
 
-		aud = NewAsyncUploadDataSource(sourceFile)
+		aud = NewAsyncUploadDataSource(sourceFile, "")
 		nextPhase, err := aud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferScratch).To(Equal(nextPhase))
@@ -239,7 +302,7 @@ var _ = Describe("Async Upload data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		sourceFile, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		aud = NewAsyncUploadDataSource(sourceFile)
+		aud = NewAsyncUploadDataSource(sourceFile, "")
 		result, err := aud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferDataFile).To(Equal(result))
@@ -253,7 +316,7 @@ var _ = Describe("Async Upload data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		sourceFile, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		aud = NewAsyncUploadDataSource(sourceFile)
+		aud = NewAsyncUploadDataSource(sourceFile, "")
 		result, err := aud.Info()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ProcessingPhaseTransferDataFile).To(Equal(result))
@@ -263,8 +326,99 @@ var _ = Describe("Async Upload data source", func() {
 	})
 
 	It("Close with nil stream should not fail", func() {
-		aud = NewAsyncUploadDataSource(nil)
+		aud = NewAsyncUploadDataSource(nil, "")
 		err := aud.Close()
 		Expect(err).NotTo(HaveOccurred())
 	})
 })
+
+var _ = Describe("Extent upload data source", func() {
+	var (
+		ed     *ExtentUploadDataSource
+		tmpDir string
+		err    error
+	)
+
+	BeforeEach(func() {
+		tmpDir, err = ioutil.TempDir("", "scratch")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if ed != nil {
+			ed.Close()
+		}
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Should write the extents directly to the target file", func() {
+		source := filepath.Join(tmpDir, "source.img")
+		content := append(make([]byte, 256*1024), bytes.Repeat([]byte{1}, 1024)...)
+		Expect(ioutil.WriteFile(source, content, 0644)).To(Succeed())
+		sourceFile, err := os.Open(source)
+		Expect(err).NotTo(HaveOccurred())
+		reader, err := util.NewExtentReader(sourceFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		ed = NewExtentUploadDataSource(reader)
+		result, err := ed.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseTransferDataFile))
+		result, err = ed.TransferFile(filepath.Join(tmpDir, "target.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseResize))
+		Expect(ed.GetURL().Path).To(Equal(filepath.Join(tmpDir, "target.img")))
+
+		written, err := ioutil.ReadFile(filepath.Join(tmpDir, "target.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written).To(Equal(content))
+	})
+
+	It("Should fail a stream that is not an extent stream", func() {
+		ed = NewExtentUploadDataSource(ioutil.NopCloser(bytes.NewReader([]byte("raw data, no extents"))))
+		result, err := ed.TransferFile(filepath.Join(tmpDir, "target.img"))
+		Expect(err).To(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseError))
+	})
+
+	It("Should not transfer to scratch space", func() {
+		ed = NewExtentUploadDataSource(nil)
+		result, err := ed.Transfer(tmpDir)
+		Expect(err).To(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseError))
+	})
+})
+
+var _ = Describe("Staged upload data source", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "staged")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Should keep the staged files in scratch space when the processing fails", func() {
+		scratchDir := filepath.Join(tmpDir, "scratch")
+		dataDir := filepath.Join(tmpDir, "data")
+		Expect(os.MkdirAll(filepath.Join(scratchDir, "staged"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(dataDir, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(scratchDir, "staged", "data"), []byte("staged"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(scratchDir, "leftover"), []byte("leftover"), 0644)).To(Succeed())
+
+		file, err := os.Open(filepath.Join(imageDir, "content.tar"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+		sd := NewStagedUploadDataSource(file, "", "staged")
+		Expect(sd.GetResumeFiles()).To(Equal([]string{"staged"}))
+
+		dp := NewDataProcessor(sd, filepath.Join(dataDir, "disk.img"), dataDir, scratchDir, "", 0.055, false)
+		Expect(dp.ProcessData()).ToNot(Succeed())
+		Expect(filepath.Join(scratchDir, "staged", "data")).To(BeAnExistingFile())
+		Expect(filepath.Join(scratchDir, "leftover")).ToNot(BeAnExistingFile())
+	})
+})
@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"io"
 	"io/ioutil"
 	"net/url"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
@@ -28,12 +30,25 @@ func ParseEndpoint(endpt string) (*url.URL, error) {
 // CleanDir cleans the contents of a directory including its sub directories, but does NOT remove the
 // directory itself.
 func CleanDir(dest string) error {
+	return cleanDirExcept(dest)
+}
+
+// cleanDirExcept cleans the contents of a directory, except the files with the passed in names.
+func cleanDirExcept(dest string, keep ...string) error {
 	dir, err := ioutil.ReadDir(dest)
 	if err != nil {
 		klog.Errorf("Unable read directory to clean: %s, %v", dest, err)
 		return err
 	}
+	keepNames := make(map[string]bool)
+	for _, name := range keep {
+		keepNames[name] = true
+	}
 	for _, d := range dir {
+		if keepNames[d.Name()] {
+			klog.V(1).Infoln("keeping file: " + filepath.Join(dest, d.Name()))
+			continue
+		}
 		klog.V(1).Infoln("deleting file: " + filepath.Join(dest, d.Name()))
 		err = os.RemoveAll(filepath.Join(dest, d.Name()))
 		if err != nil {
@@ -43,3 +58,50 @@ func CleanDir(dest string) error {
 	}
 	return nil
 }
+
+// convertQcow2StreamToFile converts the qcow2 image read from r to raw, in the file or block device fileName. The data
+// read before the tables mapping it is buffered in spillDir, if not empty, once the memory buffer is full.
+func convertQcow2StreamToFile(r io.Reader, fileName, spillDir string) error {
+	outFile, err := util.OpenFileOrBlockDevice(fileName)
+	if err != nil {
+		return err
+	}
+	isBlock := false
+	if info, err := outFile.Stat(); err == nil {
+		isBlock = info.Mode()&os.ModeDevice != 0
+	}
+	writer, err := util.NewSparseWriter(outFile)
+	if err != nil {
+		outFile.Close()
+		return err
+	}
+	klog.V(1).Infof("Converting qcow2 data to raw...\n")
+	if err := image.ConvertQcow2Stream(r, writer, spillDir); err != nil {
+		klog.Errorf("Unable to convert qcow2 data: %v\n", err)
+		outFile.Close()
+		if !isBlock {
+			os.Remove(outFile.Name())
+		}
+		return err
+	}
+	klog.V(1).Infof("Wrote %d non-zero bytes to %s\n", writer.BytesWritten(), fileName)
+	return writer.Close()
+}
+
+// probeVirtualSize returns the virtual size of the disk image in the stream, read from its header after decompressing
+// the stream as needed. The size of raw data is its content length, and is only known if it is not compressed.
+func probeVirtualSize(stream io.ReadCloser, contentLength uint64) (int64, error) {
+	fr, err := NewFormatReaders(stream, 0, "")
+	if err != nil {
+		stream.Close()
+		return 0, errors.Wrap(err, "could not read the image headers")
+	}
+	defer fr.Close()
+	switch {
+	case fr.imageSize > 0:
+		return fr.imageSize, nil
+	case fr.Format() == "raw" && contentLength > 0:
+		return int64(contentLength), nil
+	}
+	return 0, errors.Errorf("unable to determine the virtual size of the %s data", fr.Format())
+}
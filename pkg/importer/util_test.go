@@ -119,4 +119,17 @@ var _ = Describe("Clean dir", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(0).To(Equal(len(dir)))
 	})
+
+	It("Should keep the passed in files when cleaning a directory", func() {
+		_, err = os.Create(filepath.Join(tmpDir, "newfile1"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = os.Create(filepath.Join(tmpDir, "newfile2"))
+		Expect(err).NotTo(HaveOccurred())
+		err = cleanDirExcept(tmpDir, "newfile2", "missing")
+		Expect(err).NotTo(HaveOccurred())
+		dir, err := ioutil.ReadDir(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(1).To(Equal(len(dir)))
+		Expect("newfile2").To(Equal(dir[0].Name()))
+	})
 })
@@ -33,6 +33,8 @@ import (
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 	"golang.org/x/sys/unix"
 	"k8s.io/klog/v2"
 	"kubevirt.io/containerized-data-importer/pkg/common"
@@ -40,11 +42,12 @@ import (
 )
 
 const (
-	destinationFile       = "/data/disk.img"
 	nbdUnixSocket         = "/var/run/nbd.sock"
 	nbdPidFile            = "/var/run/nbd.pid"
 	nbdLibraryPath        = "/opt/vmware-vix-disklib-distrib/lib64"
 	startupTimeoutSeconds = 15
+	maxBlockStatusLength  = 1 << 30
+	maxPreadLength        = 1 << 20
 )
 
 // May be overridden in tests
@@ -66,35 +69,42 @@ func getVddkPluginPath() string {
 type NbdOperations interface {
 	GetSize() (uint64, error)
 	Pread(buf []byte, offset uint64, optargs *libnbd.PreadOptargs) error
+	BlockStatus(count uint64, offset uint64, callback libnbd.ExtentCallback, optargs *libnbd.BlockStatusOptargs) error
 	Close() *libnbd.LibnbdError
 }
 
 // VDDKDataSink provides a mockable interface for saving data from the source.
 type VDDKDataSink interface {
 	Write(buf []byte) (int, error)
+	Pwrite(buf []byte, offset uint64) (int, error)
+	ZeroRange(offset uint64, length uint64) error
 	Close()
 }
 
-// VDDKFileSink writes the source disk data to a local file.
+// VDDKFileSink writes the source disk data to a local file or block device, without allocating zero blocks.
 type VDDKFileSink struct {
-	file   *os.File
-	writer *bufio.Writer
+	writer *util.SparseWriter
 }
 
 func (sink *VDDKFileSink) Write(buf []byte) (int, error) {
-	written, err := sink.writer.Write(buf)
-	if err != nil {
-		return written, err
-	}
-	err = sink.writer.Flush()
-	return written, err
+	return sink.writer.Write(buf)
+}
+
+// Pwrite writes the given buffer at the given offset in the destination, used for delta copies.
+func (sink *VDDKFileSink) Pwrite(buf []byte, offset uint64) (int, error) {
+	return sink.writer.WriteAt(buf, int64(offset))
+}
+
+// ZeroRange fills the given range of the destination with zeroes.
+func (sink *VDDKFileSink) ZeroRange(offset uint64, length uint64) error {
+	return sink.writer.ZeroRange(int64(offset), int64(length))
 }
 
 // Close closes the file after a transfer is complete.
 func (sink *VDDKFileSink) Close() {
-	sink.writer.Flush()
-	sink.file.Sync()
-	sink.file.Close()
+	if err := sink.writer.Close(); err != nil {
+		klog.Errorf("Unable to close destination: %v", err)
+	}
 }
 
 // VDDKDataSource is the data provider for vddk.
@@ -102,6 +112,14 @@ type VDDKDataSource struct {
 	Command   *exec.Cmd
 	NbdSocket *url.URL
 	NbdHandle NbdOperations
+	// CurrentSnapshot is the snapshot being copied in a multistage import, empty for a single stage import.
+	CurrentSnapshot string
+	// PreviousSnapshot is the snapshot copied by the previous stage, empty for the first stage.
+	PreviousSnapshot string
+	// FinalCheckpoint indicates whether this is the last stage of a multistage import.
+	FinalCheckpoint bool
+	// ChangedBlocks lists the areas changed between PreviousSnapshot and CurrentSnapshot, as reported by VMware Changed Block Tracking.
+	ChangedBlocks []types.DiskChangeExtent
 }
 
 func init() {
@@ -117,34 +135,47 @@ func init() {
 	ownerUID, _ = util.ParseEnvVar(common.OwnerUID, false)
 }
 
-// FindMoRef takes the UUID of the VM to migrate and finds its MOref from the given VMware URL.
-func FindMoRef(uuid string, sdkURL string) (string, error) {
+// vmwareClient holds a vCenter/ESXi session and the VM being migrated.
+type vmwareClient struct {
+	conn   *govmomi.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	moref  string
+	vm     *object.VirtualMachine
+}
+
+// newVMwareClient logs in to the given VMware URL and finds the VM with the given UUID.
+func newVMwareClient(uuid string, sdkURL string) (*vmwareClient, error) {
 	vmwURL, err := url.Parse(sdkURL)
 	if err != nil {
 		klog.Errorf("Unable to create VMware URL: %v", err)
-		return "", err
+		return nil, err
 	}
 
 	// Log in to vCenter
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 	conn, err := govmomi.NewClient(ctx, vmwURL, true)
 	if err != nil {
 		klog.Errorf("Unable to connect to vCenter: %v", err)
-		return "", err
+		cancel()
+		return nil, err
+	}
+	client := &vmwareClient{
+		conn:   conn,
+		ctx:    ctx,
+		cancel: cancel,
 	}
-	defer conn.Logout(ctx)
 
 	// Get the list of datacenters to search for VM UUID
 	finder := find.NewFinder(conn.Client, true)
 	datacenters, err := finder.DatacenterList(ctx, "*")
 	if err != nil {
 		klog.Errorf("Unable to retrieve datacenter list: %v", err)
-		return "", err
+		client.Close()
+		return nil, err
 	}
 
 	// Search for VM matching given UUID, and save the MOref
-	var moref string
 	var instanceUUID bool
 	searcher := object.NewSearchIndex(conn.Client)
 	for _, datacenter := range datacenters {
@@ -152,10 +183,10 @@ func FindMoRef(uuid string, sdkURL string) (string, error) {
 		if err != nil || ref == nil {
 			klog.Infof("VM %s not found in datacenter %s.", uuid, datacenter)
 		} else {
-			moref = ref.Reference().Value
-			klog.Infof("VM %s found in datacenter %s: %s", uuid, datacenter, moref)
-			vm := object.NewVirtualMachine(conn.Client, ref.Reference())
-			state, err := vm.PowerState(ctx)
+			client.moref = ref.Reference().Value
+			klog.Infof("VM %s found in datacenter %s: %s", uuid, datacenter, client.moref)
+			client.vm = object.NewVirtualMachine(conn.Client, ref.Reference())
+			state, err := client.vm.PowerState(ctx)
 			if err != nil {
 				klog.Warningf("Unable to get current VM power state: %v", err)
 			} else {
@@ -164,11 +195,86 @@ func FindMoRef(uuid string, sdkURL string) (string, error) {
 		}
 	}
 
-	if moref == "" {
-		return "", errors.New("unable to locate VM in any datacenter")
+	if client.moref == "" {
+		client.Close()
+		return nil, errors.New("unable to locate VM in any datacenter")
+	}
+
+	return client, nil
+}
+
+// Close logs out of the VMware session.
+func (client *vmwareClient) Close() {
+	client.conn.Logout(client.ctx)
+	client.cancel()
+}
+
+// FindDiskFromName finds the virtual disk of the VM backed by the given file.
+func (client *vmwareClient) FindDiskFromName(fileName string) (*types.VirtualDisk, error) {
+	var vm mo.VirtualMachine
+	err := client.vm.Properties(client.ctx, client.vm.Reference(), []string{"config.hardware.device"}, &vm)
+	if err != nil {
+		return nil, err
 	}
+	for _, device := range vm.Config.Hardware.Device {
+		disk, ok := device.(*types.VirtualDisk)
+		if !ok {
+			continue
+		}
+		if backing, ok := disk.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+			if backing.GetVirtualDeviceFileBackingInfo().FileName == fileName {
+				return disk, nil
+			}
+		}
+	}
+	return nil, errors.New("unable to find disk matching backing file " + fileName)
+}
 
-	return moref, nil
+// FindSnapshotDiskName finds the name of the file backing the given disk in the given snapshot.
+func (client *vmwareClient) FindSnapshotDiskName(snapshot *types.ManagedObjectReference, disk *types.VirtualDisk) (string, error) {
+	var snap mo.VirtualMachineSnapshot
+	err := client.vm.Properties(client.ctx, snapshot.Reference(), []string{"config.hardware.device"}, &snap)
+	if err != nil {
+		return "", err
+	}
+	for _, device := range snap.Config.Hardware.Device {
+		snapDisk, ok := device.(*types.VirtualDisk)
+		if !ok || snapDisk.Key != disk.Key {
+			continue
+		}
+		if backing, ok := snapDisk.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+			return backing.GetVirtualDeviceFileBackingInfo().FileName, nil
+		}
+	}
+	return "", fmt.Errorf("unable to find disk %d in snapshot %s", disk.Key, snapshot.Value)
+}
+
+// QueryChangedBlocks collects all the areas of the disk that changed between the two snapshots.
+func (client *vmwareClient) QueryChangedBlocks(previous, current *types.ManagedObjectReference, disk *types.VirtualDisk) ([]types.DiskChangeExtent, error) {
+	var extents []types.DiskChangeExtent
+	offset := int64(0)
+	for offset < disk.CapacityInBytes {
+		changed, err := client.vm.QueryChangedDiskAreas(client.ctx, previous, current, disk, offset)
+		if err != nil {
+			return nil, err
+		}
+		extents = append(extents, changed.ChangedArea...)
+		if changed.Length <= 0 {
+			break
+		}
+		offset = changed.StartOffset + changed.Length
+	}
+	return extents, nil
+}
+
+// FindMoRef takes the UUID of the VM to migrate and finds its MOref from the given VMware URL.
+func FindMoRef(uuid string, sdkURL string) (string, error) {
+	client, err := newVMwareClient(uuid, sdkURL)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	return client.moref, nil
 }
 
 // WaitForNbd waits for nbdkit to start by watching for the existence of the given PID file.
@@ -205,8 +311,8 @@ func WaitForNbd(pidfile string) error {
 }
 
 // NewVDDKDataSource creates a new instance of the vddk data provider.
-func NewVDDKDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, backingFile string) (*VDDKDataSource, error) {
-	return newVddkDataSource(endpoint, accessKey, secKey, thumbprint, uuid, backingFile)
+func NewVDDKDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, backingFile string, currentCheckpoint string, previousCheckpoint string, finalCheckpoint bool) (*VDDKDataSource, error) {
+	return newVddkDataSource(endpoint, accessKey, secKey, thumbprint, uuid, backingFile, currentCheckpoint, previousCheckpoint, finalCheckpoint)
 }
 
 func validatePlugins() error {
@@ -252,7 +358,12 @@ func validatePlugins() error {
 	return nil
 }
 
-func createVddkDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, backingFile string) (*VDDKDataSource, error) {
+func createVddkDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, backingFile string, currentCheckpoint string, previousCheckpoint string, finalCheckpoint bool) (*VDDKDataSource, error) {
+	klog.Infof("Creating VDDK data source: backing file [%s], current checkpoint [%s], previous checkpoint [%s], final checkpoint [%t]", backingFile, currentCheckpoint, previousCheckpoint, finalCheckpoint)
+	if currentCheckpoint == "" && previousCheckpoint != "" {
+		return nil, errors.New("previous checkpoint set without current checkpoint")
+	}
+
 	vmwURL, err := url.Parse(endpoint)
 	if err != nil {
 		klog.Errorf("Unable to parse endpoint: %v", endpoint)
@@ -261,10 +372,48 @@ func createVddkDataSource(endpoint string, accessKey string, secKey string, thum
 
 	// Construct VMware SDK URL and get MOref
 	sdkURL := vmwURL.Scheme + "://" + accessKey + ":" + secKey + "@" + vmwURL.Host + "/sdk"
-	moref, err := FindMoRef(uuid, sdkURL)
+	vmware, err := newVMwareClient(uuid, sdkURL)
 	if err != nil {
 		return nil, err
 	}
+	defer vmware.Close()
+	moref := vmware.moref
+
+	// For a multistage import, read the disk from the checkpoint snapshot and
+	// collect the blocks that changed since the previous checkpoint.
+	diskFileName := backingFile
+	var changedBlocks []types.DiskChangeExtent
+	var currentSnapshot *types.ManagedObjectReference
+	if currentCheckpoint != "" {
+		disk, err := vmware.FindDiskFromName(backingFile)
+		if err != nil {
+			klog.Errorf("Unable to find disk for backing file %s: %v", backingFile, err)
+			return nil, err
+		}
+		currentSnapshot, err = vmware.vm.FindSnapshot(vmware.ctx, currentCheckpoint)
+		if err != nil {
+			klog.Errorf("Unable to find current checkpoint snapshot %s: %v", currentCheckpoint, err)
+			return nil, err
+		}
+		diskFileName, err = vmware.FindSnapshotDiskName(currentSnapshot, disk)
+		if err != nil {
+			klog.Errorf("Unable to find disk in snapshot %s: %v", currentCheckpoint, err)
+			return nil, err
+		}
+		if previousCheckpoint != "" {
+			previousSnapshot, err := vmware.vm.FindSnapshot(vmware.ctx, previousCheckpoint)
+			if err != nil {
+				klog.Errorf("Unable to find previous checkpoint snapshot %s: %v", previousCheckpoint, err)
+				return nil, err
+			}
+			changedBlocks, err = vmware.QueryChangedBlocks(previousSnapshot, currentSnapshot, disk)
+			if err != nil {
+				klog.Errorf("Unable to query changed blocks between %s and %s: %v", previousCheckpoint, currentCheckpoint, err)
+				return nil, err
+			}
+			klog.Infof("Found %d changed areas between snapshots %s and %s", len(changedBlocks), previousCheckpoint, currentCheckpoint)
+		}
+	}
 
 	err = validatePlugins()
 	if err != nil {
@@ -284,9 +433,12 @@ func createVddkDataSource(endpoint string, accessKey string, secKey string, thum
 		"password=" + secKey,
 		"thumbprint=" + thumbprint,
 		"vm=moref=" + moref,
-		"file=" + backingFile,
+		"file=" + diskFileName,
 		"libdir=" + nbdLibraryPath,
 	}
+	if currentSnapshot != nil {
+		args = append(args, "snapshot="+currentSnapshot.Value)
+	}
 
 	nbdkit := exec.Command("nbdkit", args...)
 	env := os.Environ()
@@ -330,6 +482,12 @@ func createVddkDataSource(endpoint string, accessKey string, secKey string, thum
 		return nil, err
 	}
 
+	// Ask for allocation information, so holes in changed areas can be skipped
+	err = handle.AddMetaContext("base:allocation")
+	if err != nil {
+		klog.Warningf("Unable to request allocation metadata: %v", err)
+	}
+
 	socket, _ := url.Parse("nbd://" + nbdUnixSocket)
 	err = handle.ConnectUri("nbd+unix://?socket=" + nbdUnixSocket)
 	if err != nil {
@@ -339,15 +497,19 @@ func createVddkDataSource(endpoint string, accessKey string, secKey string, thum
 	}
 
 	source := &VDDKDataSource{
-		Command:   nbdkit,
-		NbdSocket: socket,
-		NbdHandle: handle,
+		Command:          nbdkit,
+		NbdSocket:        socket,
+		NbdHandle:        handle,
+		CurrentSnapshot:  currentCheckpoint,
+		PreviousSnapshot: previousCheckpoint,
+		FinalCheckpoint:  finalCheckpoint,
+		ChangedBlocks:    changedBlocks,
 	}
 	return source, nil
 }
 
 func createVddkDataSink(destinationFile string, size uint64) (VDDKDataSink, error) {
-	file, err := os.OpenFile(destinationFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	file, err := os.OpenFile(destinationFile, os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return nil, err
 	}
@@ -355,12 +517,15 @@ func createVddkDataSink(destinationFile string, size uint64) (VDDKDataSink, erro
 	if err != nil {
 		klog.Warningf("Error with sequential fadvise: %v", err)
 	}
-	writer := bufio.NewWriter(file)
+	writer, err := util.NewSparseWriter(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
 	sink := &VDDKFileSink{
-		file:   file,
 		writer: writer,
 	}
-	return sink, err
+	return sink, nil
 }
 
 // Info is called to get initial information about the data.
@@ -390,6 +555,24 @@ func (vs *VDDKDataSource) Transfer(path string) (ProcessingPhase, error) {
 	return ProcessingPhaseTransferDataFile, nil
 }
 
+// GetResumePhase returns the next phase to process when resuming, used for the stages of a multistage import.
+func (vs *VDDKDataSource) GetResumePhase() ProcessingPhase {
+	return ProcessingPhaseTransferDataFile
+}
+
+// IsDeltaCopy returns true when this stage only needs to copy the blocks changed since the previous checkpoint.
+func (vs *VDDKDataSource) IsDeltaCopy() bool {
+	return vs.PreviousSnapshot != "" && vs.CurrentSnapshot != ""
+}
+
+// completionPhase returns the phase to move to after a successful copy, pausing between the stages of a multistage import.
+func (vs *VDDKDataSource) completionPhase() ProcessingPhase {
+	if vs.CurrentSnapshot != "" && !vs.FinalCheckpoint {
+		return ProcessingPhasePause
+	}
+	return ProcessingPhaseComplete
+}
+
 // TransferFile is called to transfer the data from the source to the file passed in.
 func (vs *VDDKDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
 	size, err := vs.NbdHandle.GetSize()
@@ -398,79 +581,232 @@ func (vs *VDDKDataSource) TransferFile(fileName string) (ProcessingPhase, error)
 		return ProcessingPhaseError, err
 	}
 
-	sink, err := newVddkDataSink(destinationFile, size)
+	sink, err := newVddkDataSink(fileName, size)
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
 	defer sink.Close()
 
-	start := uint64(0)
-	lastProgressPercent := uint(0)
-	lastProgressBytes := uint64(0)
-	lastProgressTime := time.Now()
-	initialProgressTime := time.Now()
-	blocksize := uint64(1024 * 1024)
+	if vs.IsDeltaCopy() {
+		err = vs.copyChangedBlocks(sink)
+	} else {
+		err = vs.copyFullDisk(sink, size)
+	}
+	if err != nil {
+		return ProcessingPhaseError, err
+	}
+	return vs.completionPhase(), nil
+}
+
+// readWithRetry reads from the NBD export, retrying once on failure.
+func (vs *VDDKDataSource) readWithRetry(buf []byte, offset uint64) error {
+	err := vs.NbdHandle.Pread(buf, offset, nil)
+	if err != nil {
+		klog.Errorf("Failed to read from data source at offset %d! First error was: %v", offset, err)
+		retryErr := vs.NbdHandle.Pread(buf, offset, nil)
+		if retryErr != nil {
+			klog.Errorf("Retry error was: %v", retryErr)
+			return err
+		}
+		klog.Infof("Retry was successful.")
+	}
+	return nil
+}
+
+// copyFullDisk copies the whole disk sequentially to the sink.
+func (vs *VDDKDataSource) copyFullDisk(sink VDDKDataSink, size uint64) error {
+	tracker := newVddkProgress(size)
+	blocksize := uint64(maxPreadLength)
 	buf := make([]byte, blocksize)
-	for i := start; i < size; i += blocksize {
+	for i := uint64(0); i < size; i += blocksize {
 		if (size - i) < blocksize {
 			blocksize = size - i
 			buf = make([]byte, blocksize)
 		}
 
-		err = vs.NbdHandle.Pread(buf, i, nil)
-		if err != nil {
-			klog.Errorf("Failed to read from data source at offset %d! First error was: %v", i, err)
-			retryErr := vs.NbdHandle.Pread(buf, i, nil)
-			if retryErr != nil {
-				klog.Errorf("Retry error was: %v", retryErr)
-				return ProcessingPhaseError, err
-			}
-			klog.Infof("Retry was successful.")
+		if err := vs.readWithRetry(buf, i); err != nil {
+			return err
 		}
 
 		written, err := sink.Write(buf)
 		if err != nil {
 			klog.Errorf("Failed to write source data to destination: %v", err)
-			return ProcessingPhaseError, err
+			return err
 		}
 		if uint64(written) < blocksize {
 			klog.Errorf("Failed to write whole buffer to destination! Wrote %d/%d bytes.", written, blocksize)
-			return ProcessingPhaseError, errors.New("failed to write whole buffer to destination")
+			return errors.New("failed to write whole buffer to destination")
 		}
 
-		// Only log progress at approximately 1% intervals.
-		currentProgressBytes := i + uint64(written)
-		currentProgressPercent := uint(100.0 * (float64(currentProgressBytes) / float64(size)))
-		if currentProgressPercent > lastProgressPercent {
-			progressMessage := fmt.Sprintf("Transferred %d/%d bytes (%d%%)", currentProgressBytes, size, currentProgressPercent)
+		tracker.update(uint64(written))
+	}
+	return nil
+}
 
-			currentProgressTime := time.Now()
-			overallProgressTime := uint64(time.Since(initialProgressTime).Seconds())
-			if overallProgressTime > 0 {
-				overallProgressRate := currentProgressBytes / overallProgressTime
-				progressMessage += fmt.Sprintf(" at %d bytes/second overall", overallProgressRate)
-			}
+// copyChangedBlocks copies only the areas reported as changed by VMware to their offsets in the sink.
+// Holes and zeroed blocks inside a changed area are zeroed on the destination instead of being read.
+func (vs *VDDKDataSource) copyChangedBlocks(sink VDDKDataSink) error {
+	total := uint64(0)
+	for _, extent := range vs.ChangedBlocks {
+		total += uint64(extent.Length)
+	}
+	klog.Infof("Copying %d changed bytes between snapshots %s and %s", total, vs.PreviousSnapshot, vs.CurrentSnapshot)
 
-			progressTimeDifference := uint64(currentProgressTime.Sub(lastProgressTime).Seconds())
-			if progressTimeDifference > 0 {
-				progressSize := currentProgressBytes - lastProgressBytes
-				progressRate := progressSize / progressTimeDifference
-				progressMessage += fmt.Sprintf(", last 1%% was %d bytes at %d bytes/second", progressSize, progressRate)
+	tracker := newVddkProgress(total)
+	for _, extent := range vs.ChangedBlocks {
+		blocks, err := vs.getBlockStatus(uint64(extent.Start), uint64(extent.Length))
+		if err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			if block.Zero {
+				if err := sink.ZeroRange(block.Offset, block.Length); err != nil {
+					klog.Errorf("Failed to zero destination range at offset %d: %v", block.Offset, err)
+					return err
+				}
+				tracker.update(block.Length)
+				continue
+			}
+			if err := vs.copyRange(sink, block.Offset, block.Length, tracker); err != nil {
+				return err
 			}
+		}
+	}
+	return nil
+}
+
+// copyRange reads the given range from the NBD export and writes it at the same offset in the sink.
+func (vs *VDDKDataSource) copyRange(sink VDDKDataSink, offset uint64, length uint64, tracker *vddkProgress) error {
+	blocksize := uint64(maxPreadLength)
+	buf := make([]byte, blocksize)
+	for length > 0 {
+		if length < blocksize {
+			blocksize = length
+		}
+		if err := vs.readWithRetry(buf[:blocksize], offset); err != nil {
+			return err
+		}
+		written, err := sink.Pwrite(buf[:blocksize], offset)
+		if err != nil {
+			klog.Errorf("Failed to write source data to destination at offset %d: %v", offset, err)
+			return err
+		}
+		if uint64(written) < blocksize {
+			klog.Errorf("Failed to write whole buffer to destination! Wrote %d/%d bytes.", written, blocksize)
+			return errors.New("failed to write whole buffer to destination")
+		}
+		offset += blocksize
+		length -= blocksize
+		tracker.update(blocksize)
+	}
+	return nil
+}
 
-			klog.Info(progressMessage)
+// BlockStatusData is a contiguous range of the source disk with the same allocation status.
+type BlockStatusData struct {
+	Offset uint64
+	Length uint64
+	Zero   bool
+}
 
-			lastProgressBytes = currentProgressBytes
-			lastProgressTime = currentProgressTime
-			lastProgressPercent = currentProgressPercent
+// getBlockStatus splits the given range into data and zero blocks, using the NBD base:allocation
+// metadata. If the export does not provide allocation information, the whole range is treated as data.
+func (vs *VDDKDataSource) getBlockStatus(offset uint64, length uint64) ([]*BlockStatusData, error) {
+	var blocks []*BlockStatusData
+	end := offset + length
+	for offset < end {
+		count := end - offset
+		if count > maxBlockStatusLength {
+			count = maxBlockStatusLength
 		}
-		v := float64(currentProgressPercent)
-		metric := &dto.Metric{}
-		err = progress.WithLabelValues(ownerUID).Write(metric)
-		if err == nil && v > 0 && v > *metric.Counter.Value {
-			progress.WithLabelValues(ownerUID).Add(v - *metric.Counter.Value)
+		current := offset
+		callback := func(metacontext string, extentOffset uint64, entries []uint32, nbdError *int) int {
+			if metacontext != "base:allocation" {
+				return 0
+			}
+			// Entries come in pairs of (length, flags)
+			for i := 0; i+1 < len(entries) && current < end; i += 2 {
+				blockLength := uint64(entries[i])
+				if current+blockLength > end {
+					blockLength = end - current
+				}
+				zero := entries[i+1]&(libnbd.STATE_HOLE|libnbd.STATE_ZERO) != 0
+				if last := len(blocks) - 1; last >= 0 && blocks[last].Zero == zero && blocks[last].Offset+blocks[last].Length == current {
+					blocks[last].Length += blockLength
+				} else {
+					blocks = append(blocks, &BlockStatusData{Offset: current, Length: blockLength, Zero: zero})
+				}
+				current += blockLength
+			}
+			return 0
 		}
+		err := vs.NbdHandle.BlockStatus(count, offset, callback, nil)
+		if err != nil || current == offset {
+			if err != nil {
+				klog.Warningf("Unable to get block status at offset %d, copying the whole range: %v", offset, err)
+			}
+			blocks = append(blocks, &BlockStatusData{Offset: offset, Length: end - offset})
+			return blocks, nil
+		}
+		offset = current
+	}
+	return blocks, nil
+}
+
+// vddkProgress logs and reports the transfer progress at approximately 1% intervals.
+type vddkProgress struct {
+	total               uint64
+	currentBytes        uint64
+	lastProgressPercent uint
+	lastProgressBytes   uint64
+	lastProgressTime    time.Time
+	initialProgressTime time.Time
+}
+
+func newVddkProgress(total uint64) *vddkProgress {
+	return &vddkProgress{
+		total:               total,
+		lastProgressTime:    time.Now(),
+		initialProgressTime: time.Now(),
+	}
+}
+
+func (p *vddkProgress) update(transferred uint64) {
+	p.currentBytes += transferred
+	if p.total == 0 {
+		return
 	}
 
-	return ProcessingPhaseComplete, nil
+	// Only log progress at approximately 1% intervals.
+	currentProgressBytes := p.currentBytes
+	currentProgressPercent := uint(100.0 * (float64(currentProgressBytes) / float64(p.total)))
+	if currentProgressPercent > p.lastProgressPercent {
+		progressMessage := fmt.Sprintf("Transferred %d/%d bytes (%d%%)", currentProgressBytes, p.total, currentProgressPercent)
+
+		currentProgressTime := time.Now()
+		overallProgressTime := uint64(time.Since(p.initialProgressTime).Seconds())
+		if overallProgressTime > 0 {
+			overallProgressRate := currentProgressBytes / overallProgressTime
+			progressMessage += fmt.Sprintf(" at %d bytes/second overall", overallProgressRate)
+		}
+
+		progressTimeDifference := uint64(currentProgressTime.Sub(p.lastProgressTime).Seconds())
+		if progressTimeDifference > 0 {
+			progressSize := currentProgressBytes - p.lastProgressBytes
+			progressRate := progressSize / progressTimeDifference
+			progressMessage += fmt.Sprintf(", last 1%% was %d bytes at %d bytes/second", progressSize, progressRate)
+		}
+
+		klog.Info(progressMessage)
+
+		p.lastProgressBytes = currentProgressBytes
+		p.lastProgressTime = currentProgressTime
+		p.lastProgressPercent = currentProgressPercent
+	}
+	v := float64(currentProgressPercent)
+	metric := &dto.Metric{}
+	err := progress.WithLabelValues(ownerUID).Write(metric)
+	if err == nil && v > 0 && v > *metric.Counter.Value {
+		progress.WithLabelValues(ownerUID).Add(v - *metric.Counter.Value)
+	}
 }
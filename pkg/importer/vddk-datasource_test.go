@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 const (
@@ -15,8 +16,9 @@ const (
 )
 
 type mockNbdExport struct {
-	Size func() (uint64, error)
-	Read func(uint64) ([]byte, error)
+	Size        func() (uint64, error)
+	Read        func(uint64) ([]byte, error)
+	BlockStatus func(uint64, uint64) ([]uint32, error)
 }
 
 func defaultMockNbdExport() mockNbdExport {
@@ -27,10 +29,14 @@ func defaultMockNbdExport() mockNbdExport {
 	export.Read = func(uint64) ([]byte, error) {
 		return bytes.Repeat([]byte{0}, 512), nil
 	}
+	export.BlockStatus = func(count uint64, offset uint64) ([]uint32, error) {
+		return []uint32{uint32(count), 0}, nil
+	}
 	return *export
 }
 
 var currentExport mockNbdExport
+var currentSink *mockVddkDataSink
 
 var _ = Describe("VDDK data source", func() {
 	BeforeEach(func() {
@@ -45,12 +51,12 @@ var _ = Describe("VDDK data source", func() {
 
 	It("NewVDDKDataSource should fail when called with an invalid endpoint", func() {
 		newVddkDataSource = createVddkDataSource
-		_, err := NewVDDKDataSource("httpx://-------", "", "", "", "", "")
+		_, err := NewVDDKDataSource("httpx://-------", "", "", "", "", "", "", "", false)
 		Expect(err).To(HaveOccurred())
 	})
 
 	It("VDDK data source GetURL should pass through NBD socket information", func() {
-		dp, err := NewVDDKDataSource("", "", "", "", "", "")
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", false)
 		Expect(err).ToNot(HaveOccurred())
 		socket := dp.GetURL()
 		path := socket.String()
@@ -58,7 +64,7 @@ var _ = Describe("VDDK data source", func() {
 	})
 
 	It("VDDK data source should move to transfer data phase after Info", func() {
-		dp, err := NewVDDKDataSource("", "", "", "", "", "")
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", false)
 		Expect(err).ToNot(HaveOccurred())
 		phase, err := dp.Info()
 		Expect(err).ToNot(HaveOccurred())
@@ -71,7 +77,7 @@ var _ = Describe("VDDK data source", func() {
 			return 0, errors.New("forced GetSize failure")
 		}
 		currentExport = replaceExport
-		dp, err := NewVDDKDataSource("", "", "", "", "", "")
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", false)
 		Expect(err).ToNot(HaveOccurred())
 		phase, err := dp.Info()
 		Expect(err).To(HaveOccurred())
@@ -87,7 +93,7 @@ var _ = Describe("VDDK data source", func() {
 			return bytes.Repeat([]byte{0x55}, 512), nil
 		}
 		currentExport = replaceExport
-		dp, err := NewVDDKDataSource("", "", "", "", "", "")
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", false)
 		Expect(err).ToNot(HaveOccurred())
 		phase, err := dp.Info()
 		Expect(err).ToNot(HaveOccurred())
@@ -99,7 +105,7 @@ var _ = Describe("VDDK data source", func() {
 
 	It("VDDK data source should fail if TransferFile fails", func() {
 		newVddkDataSink = createVddkDataSink
-		dp, err := NewVDDKDataSource("", "", "", "", "", "")
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", false)
 		Expect(err).ToNot(HaveOccurred())
 		phase, err := dp.Info()
 		Expect(err).ToNot(HaveOccurred())
@@ -108,6 +114,90 @@ var _ = Describe("VDDK data source", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(phase).To(Equal(ProcessingPhaseError))
 	})
+
+	It("NewVDDKDataSource should fail when given a previous checkpoint without a current one", func() {
+		newVddkDataSource = createVddkDataSource
+		_, err := NewVDDKDataSource("http://vcenter", "", "", "", "", "", "", "snapshot-1", false)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("VDDK data source should resume at the transfer data phase", func() {
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "snapshot-2", "snapshot-1", false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dp.GetResumePhase()).To(Equal(ProcessingPhaseTransferDataFile))
+		Expect(dp.IsDeltaCopy()).To(BeTrue())
+	})
+
+	It("VDDK data source should pause after copying a checkpoint that is not final", func() {
+		replaceExport := currentExport
+		replaceExport.Size = func() (uint64, error) {
+			return 512, nil
+		}
+		currentExport = replaceExport
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "snapshot-1", "", false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dp.IsDeltaCopy()).To(BeFalse())
+		phase, err := dp.TransferFile("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhasePause))
+	})
+
+	It("VDDK data source should only copy changed blocks for a delta checkpoint", func() {
+		replaceExport := currentExport
+		replaceExport.Size = func() (uint64, error) {
+			return 1024 * 1024 * 10, nil
+		}
+		replaceExport.Read = func(offset uint64) ([]byte, error) {
+			return bytes.Repeat([]byte{0x55}, 512), nil
+		}
+		currentExport = replaceExport
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "snapshot-2", "snapshot-1", true)
+		Expect(err).ToNot(HaveOccurred())
+		dp.ChangedBlocks = []types.DiskChangeExtent{
+			{Start: 0, Length: 512},
+			{Start: 4096, Length: 1024},
+		}
+		phase, err := dp.TransferFile("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseComplete))
+		Expect(currentSink.writes).To(Equal(map[uint64]uint64{0: 512, 4096: 1024}))
+		Expect(currentSink.zeroes).To(BeEmpty())
+	})
+
+	It("VDDK data source should zero holes inside changed blocks instead of reading them", func() {
+		replaceExport := currentExport
+		replaceExport.BlockStatus = func(count uint64, offset uint64) ([]uint32, error) {
+			return []uint32{512, 0, 512, libnbd.STATE_HOLE | libnbd.STATE_ZERO, uint32(count - 1024), 0}, nil
+		}
+		currentExport = replaceExport
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "snapshot-2", "snapshot-1", false)
+		Expect(err).ToNot(HaveOccurred())
+		dp.ChangedBlocks = []types.DiskChangeExtent{
+			{Start: 8192, Length: 2048},
+		}
+		phase, err := dp.TransferFile("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhasePause))
+		Expect(currentSink.writes).To(Equal(map[uint64]uint64{8192: 512, 9216: 1024}))
+		Expect(currentSink.zeroes).To(Equal(map[uint64]uint64{8704: 512}))
+	})
+
+	It("VDDK data source should copy whole changed blocks when block status fails", func() {
+		replaceExport := currentExport
+		replaceExport.BlockStatus = func(count uint64, offset uint64) ([]uint32, error) {
+			return nil, errors.New("forced block status failure")
+		}
+		currentExport = replaceExport
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "snapshot-2", "snapshot-1", true)
+		Expect(err).ToNot(HaveOccurred())
+		dp.ChangedBlocks = []types.DiskChangeExtent{
+			{Start: 512, Length: 1536},
+		}
+		phase, err := dp.TransferFile("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseComplete))
+		Expect(currentSink.writes).To(Equal(map[uint64]uint64{512: 1536}))
+	})
 })
 
 type mockNbdOperations struct{}
@@ -123,11 +213,21 @@ func (handle *mockNbdOperations) Pread(buf []byte, offset uint64, optargs *libnb
 	return err
 }
 
+func (handle *mockNbdOperations) BlockStatus(count uint64, offset uint64, callback libnbd.ExtentCallback, optargs *libnbd.BlockStatusOptargs) error {
+	entries, err := currentExport.BlockStatus(count, offset)
+	if err != nil {
+		return err
+	}
+	nbdError := 0
+	callback("base:allocation", offset, entries, &nbdError)
+	return nil
+}
+
 func (handle *mockNbdOperations) Close() *libnbd.LibnbdError {
 	return nil
 }
 
-func createMockVddkDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, backingFile string) (*VDDKDataSource, error) {
+func createMockVddkDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, backingFile string, currentCheckpoint string, previousCheckpoint string, finalCheckpoint bool) (*VDDKDataSource, error) {
 	socketURL, err := url.Parse(socketPath)
 	if err != nil {
 		return nil, err
@@ -136,21 +236,48 @@ func createMockVddkDataSource(endpoint string, accessKey string, secKey string,
 	handle := &mockNbdOperations{}
 
 	return &VDDKDataSource{
-		Command:   nil,
-		NbdHandle: handle,
-		NbdSocket: socketURL,
+		Command:          nil,
+		NbdHandle:        handle,
+		NbdSocket:        socketURL,
+		CurrentSnapshot:  currentCheckpoint,
+		PreviousSnapshot: previousCheckpoint,
+		FinalCheckpoint:  finalCheckpoint,
 	}, nil
 }
 
-type mockVddkDataSink struct{}
+type mockVddkDataSink struct {
+	// writes and zeroes map destination offsets to the number of bytes written there
+	writes map[uint64]uint64
+	zeroes map[uint64]uint64
+}
 
 func (sink *mockVddkDataSink) Write(buf []byte) (int, error) {
 	return len(buf), nil
 }
 
+func (sink *mockVddkDataSink) Pwrite(buf []byte, offset uint64) (int, error) {
+	// Merge with a preceding contiguous write, so tests can check whole ranges
+	for start, length := range sink.writes {
+		if start+length == offset {
+			sink.writes[start] += uint64(len(buf))
+			return len(buf), nil
+		}
+	}
+	sink.writes[offset] = uint64(len(buf))
+	return len(buf), nil
+}
+
+func (sink *mockVddkDataSink) ZeroRange(offset uint64, length uint64) error {
+	sink.zeroes[offset] = length
+	return nil
+}
+
 func (sink *mockVddkDataSink) Close() {}
 
 func createMockVddkDataSink(destinationFile string, size uint64) (VDDKDataSink, error) {
-	sink := &mockVddkDataSink{}
-	return sink, nil
+	currentSink = &mockVddkDataSink{
+		writes: map[uint64]uint64{},
+		zeroes: map[uint64]uint64{},
+	}
+	return currentSink, nil
 }
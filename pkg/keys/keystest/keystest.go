@@ -36,8 +36,9 @@ func NewPrivateKeySecret(namespace, secretName string, privateKey *rsa.PrivateKe
 	}
 
 	data := map[string][]byte{
-		"id_rsa":     privateKeyBytes,
-		"id_rsa.pub": publicKeyBytes,
+		"id_rsa":            privateKeyBytes,
+		"id_rsa.pub":        publicKeyBytes,
+		"id_rsa.pub.bundle": publicKeyBytes,
 	}
 
 	return newSecret(namespace, secretName, data, nil), nil
@@ -77,9 +77,30 @@ func GetOrCreatePrivateKey(client kubernetes.Interface, namespace, secretName st
 		return nil, errors.Wrap(err, "Secret missing private key")
 	}
 
+	if _, ok := secret.Data[KeyStorePublicKeyBundleFile]; !ok {
+		// secrets created by older versions have no bundle, the validators mount it
+		if err := addPublicKeyBundle(client, secret); err != nil {
+			return nil, err
+		}
+	}
+
 	return parsePrivateKey(bytes)
 }
 
+// addPublicKeyBundle adds the bundle of public keys to a private key secret
+func addPublicKeyBundle(client kubernetes.Interface, secret *v1.Secret) error {
+	bundle, err := publicKeyBundle(secret)
+	if err != nil {
+		return err
+	}
+	secret = secret.DeepCopy()
+	secret.Data[KeyStorePublicKeyBundleFile] = bundle
+	if _, err := client.CoreV1().Secrets(secret.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "Error adding public key bundle to secret")
+	}
+	return nil
+}
+
 // newPrivateKeySecret returns a new private key secret
 func newPrivateKeySecret(client kubernetes.Interface, namespace, secretName string, privateKey *rsa.PrivateKey) (*v1.Secret, error) {
 	privateKeyBytes := cert.EncodePrivateKeyPEM(privateKey)
@@ -89,8 +110,9 @@ func newPrivateKeySecret(client kubernetes.Interface, namespace, secretName stri
 	}
 
 	data := map[string][]byte{
-		KeyStorePrivateKeyFile: privateKeyBytes,
-		KeyStorePublicKeyFile:  publicKeyBytes,
+		KeyStorePrivateKeyFile:      privateKeyBytes,
+		KeyStorePublicKeyFile:       publicKeyBytes,
+		KeyStorePublicKeyBundleFile: publicKeyBytes,
 	}
 
 	secret, err := newSecret(client, namespace, secretName, data, nil)
@@ -17,6 +17,7 @@ limitations under the License.
 package keys
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"fmt"
@@ -25,6 +26,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/diff"
@@ -166,4 +168,23 @@ var _ = Describe("Create Private Key", func() {
 			Fail("Keys do not match")
 		}
 	})
+
+	It("Should add the public key bundle to an existing Private Key without one", func() {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+
+		privateKeySecret, err := keystest.NewPrivateKeySecret(namespace, secret, privateKey)
+		Expect(err).NotTo(HaveOccurred())
+		delete(privateKeySecret.Data, KeyStorePublicKeyBundleFile)
+
+		client := k8sfake.NewSimpleClientset(privateKeySecret)
+
+		returnedPrivateKey, err := GetOrCreatePrivateKey(client, namespace, secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(returnedPrivateKey).To(Equal(privateKey))
+
+		updatedSecret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), secret, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updatedSecret.Data[KeyStorePublicKeyBundleFile]).To(Equal(updatedSecret.Data[KeyStorePublicKeyFile]))
+	})
 })
@@ -0,0 +1,311 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keys
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/util/cert"
+)
+
+const (
+	// KeyStorePublicKeyBundleFile is the key in a signing key secret containing the public keys tokens may be signed
+	// with: the current key, the next one and the previous one during its grace window
+	KeyStorePublicKeyBundleFile = "id_rsa.pub.bundle"
+
+	// KeyStoreNextPrivateKeyFile is the key in a signing key secret containing the key that replaces the current one
+	// once its public key reached the validators
+	KeyStoreNextPrivateKeyFile = "id_rsa.next"
+
+	// KeyStorePreviousPublicKeyFile is the key in a signing key secret containing the public key of the replaced key
+	KeyStorePreviousPublicKeyFile = "id_rsa.pub.previous"
+
+	// AnnSigningKeyRotatedAt is the time the current signing key started to sign tokens
+	AnnSigningKeyRotatedAt = "cdi.kubevirt.io/signing-key.rotated-at"
+
+	// AnnSigningKeyNextPublishedAt is the time the public key of the next signing key was added to the bundle
+	AnnSigningKeyNextPublishedAt = "cdi.kubevirt.io/signing-key.next-published-at"
+
+	// AnnSigningKeyPreviousExpiresAt is the time the public key of the previous signing key is removed from the bundle
+	AnnSigningKeyPreviousExpiresAt = "cdi.kubevirt.io/signing-key.previous-expires-at"
+)
+
+// SigningKeyRotation contains the periods of the rotation of a signing key
+type SigningKeyRotation struct {
+	// Refresh is how long a key signs tokens before it is replaced
+	Refresh time.Duration
+	// Propagation is how long the next key is published in the bundle before it signs tokens, so the validators
+	// mounting the bundle receive it first
+	Propagation time.Duration
+	// Grace is how long the previous key stays in the bundle, it must be longer than the lifetime of the tokens
+	Grace time.Duration
+}
+
+// RotateSigningKeySecret updates a signing key secret to the state of its rotation at the time now, and returns true
+// if the secret changed. A new key is first published as the next key, it replaces the current key after the
+// propagation period, and the replaced key stays in the bundle of public keys during the grace period.
+func RotateSigningKeySecret(secret *v1.Secret, rotation SigningKeyRotation, now time.Time) (bool, error) {
+	if _, ok := secret.Data[KeyStorePrivateKeyFile]; !ok {
+		return false, errors.New("Secret missing private key")
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	changed := false
+
+	rotatedAt, err := time.Parse(time.RFC3339, secret.Annotations[AnnSigningKeyRotatedAt])
+	if err != nil {
+		// the key was created by the API server, its rotation starts now
+		rotatedAt = now
+		secret.Annotations[AnnSigningKeyRotatedAt] = now.UTC().Format(time.RFC3339)
+		changed = true
+	}
+
+	if _, ok := secret.Data[KeyStorePreviousPublicKeyFile]; ok {
+		expiresAt, err := time.Parse(time.RFC3339, secret.Annotations[AnnSigningKeyPreviousExpiresAt])
+		if err != nil || !now.Before(expiresAt) {
+			delete(secret.Data, KeyStorePreviousPublicKeyFile)
+			delete(secret.Annotations, AnnSigningKeyPreviousExpiresAt)
+			changed = true
+		}
+	}
+
+	if _, ok := secret.Data[KeyStoreNextPrivateKeyFile]; ok {
+		publishedAt, err := time.Parse(time.RFC3339, secret.Annotations[AnnSigningKeyNextPublishedAt])
+		if err != nil || !now.Before(publishedAt.Add(rotation.Propagation)) {
+			if err := promoteNextKey(secret, rotation, now); err != nil {
+				return false, err
+			}
+			changed = true
+		}
+	} else if !now.Before(rotatedAt.Add(rotation.Refresh)) {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return false, errors.Wrap(err, "Error generating key")
+		}
+		secret.Data[KeyStoreNextPrivateKeyFile] = cert.EncodePrivateKeyPEM(privateKey)
+		secret.Annotations[AnnSigningKeyNextPublishedAt] = now.UTC().Format(time.RFC3339)
+		changed = true
+	}
+
+	bundle, err := publicKeyBundle(secret)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(bundle, secret.Data[KeyStorePublicKeyBundleFile]) {
+		secret.Data[KeyStorePublicKeyBundleFile] = bundle
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// NextSigningKeyRotation returns the time a signing key secret updated by RotateSigningKeySecret changes next: the next
+// key is published or promoted, or the previous key is retired. It returns the zero time if the rotation did not start.
+func NextSigningKeyRotation(secret *v1.Secret, rotation SigningKeyRotation) time.Time {
+	var next time.Time
+	if publishedAt, err := time.Parse(time.RFC3339, secret.Annotations[AnnSigningKeyNextPublishedAt]); err == nil {
+		next = publishedAt.Add(rotation.Propagation)
+	} else if rotatedAt, err := time.Parse(time.RFC3339, secret.Annotations[AnnSigningKeyRotatedAt]); err == nil {
+		next = rotatedAt.Add(rotation.Refresh)
+	}
+	if expiresAt, err := time.Parse(time.RFC3339, secret.Annotations[AnnSigningKeyPreviousExpiresAt]); err == nil {
+		if next.IsZero() || expiresAt.Before(next) {
+			next = expiresAt
+		}
+	}
+	return next
+}
+
+// promoteNextKey replaces the current key with the next one
+func promoteNextKey(secret *v1.Secret, rotation SigningKeyRotation, now time.Time) error {
+	nextKey, err := parsePrivateKey(secret.Data[KeyStoreNextPrivateKeyFile])
+	if err != nil {
+		return err
+	}
+	publicKeyBytes, err := cert.EncodePublicKeyPEM(&nextKey.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding public key")
+	}
+	currentKey, err := parsePrivateKey(secret.Data[KeyStorePrivateKeyFile])
+	if err != nil {
+		return err
+	}
+	previousKeyBytes, err := cert.EncodePublicKeyPEM(&currentKey.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding public key")
+	}
+
+	secret.Data[KeyStorePrivateKeyFile] = secret.Data[KeyStoreNextPrivateKeyFile]
+	secret.Data[KeyStorePublicKeyFile] = publicKeyBytes
+	secret.Data[KeyStorePreviousPublicKeyFile] = previousKeyBytes
+	delete(secret.Data, KeyStoreNextPrivateKeyFile)
+	delete(secret.Annotations, AnnSigningKeyNextPublishedAt)
+	secret.Annotations[AnnSigningKeyRotatedAt] = now.UTC().Format(time.RFC3339)
+	secret.Annotations[AnnSigningKeyPreviousExpiresAt] = now.Add(rotation.Grace).UTC().Format(time.RFC3339)
+	return nil
+}
+
+// publicKeyBundle returns the public keys of the secret, the current one first
+func publicKeyBundle(secret *v1.Secret) ([]byte, error) {
+	currentKey, err := parsePrivateKey(secret.Data[KeyStorePrivateKeyFile])
+	if err != nil {
+		return nil, err
+	}
+	keys := []*rsa.PublicKey{&currentKey.PublicKey}
+	if keyBytes, ok := secret.Data[KeyStoreNextPrivateKeyFile]; ok {
+		nextKey, err := parsePrivateKey(keyBytes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, &nextKey.PublicKey)
+	}
+	if keyBytes, ok := secret.Data[KeyStorePreviousPublicKeyFile]; ok {
+		previousKeys, err := ParsePublicKeyBundle(keyBytes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, previousKeys...)
+	}
+
+	var bundle []byte
+	for _, key := range keys {
+		keyBytes, err := cert.EncodePublicKeyPEM(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error encoding public key")
+		}
+		bundle = append(bundle, keyBytes...)
+	}
+	return bundle, nil
+}
+
+// ParsePublicKeyBundle parses the PEM encoded RSA public keys of a bundle
+func ParsePublicKeyBundle(bytes []byte) ([]*rsa.PublicKey, error) {
+	objs, err := cert.ParsePublicKeysPEM(bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing public key bundle")
+	}
+
+	var keys []*rsa.PublicKey
+	for _, obj := range objs {
+		key, ok := obj.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("Invalid pem format")
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// PublicKeyBundleFile provides the public keys of a bundle mounted from a signing key secret, it reloads them when
+// the secret is rotated
+type PublicKeyBundleFile struct {
+	path         string
+	fallbackPath string
+	mutex        sync.Mutex
+	data         []byte
+	keys         []*rsa.PublicKey
+}
+
+// NewPublicKeyBundleFile returns the public key bundle of the file at path, or the public key of the file at
+// fallbackPath while the secret has no bundle, like secrets created by older versions
+func NewPublicKeyBundleFile(path, fallbackPath string) *PublicKeyBundleFile {
+	return &PublicKeyBundleFile{path: path, fallbackPath: fallbackPath}
+}
+
+// PublicKeys returns the public keys of the bundle
+func (f *PublicKeyBundleFile) PublicKeys() ([]*rsa.PublicKey, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		data, err = ioutil.ReadFile(f.fallbackPath)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading public key bundle")
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.keys == nil || !bytes.Equal(data, f.data) {
+		keys, err := ParsePublicKeyBundle(data)
+		if err != nil {
+			return nil, err
+		}
+		f.data = data
+		f.keys = keys
+	}
+
+	return f.keys, nil
+}
+
+// SigningKeyWatcher provides the private key of a signing key secret, it reloads it when the secret is rotated
+type SigningKeyWatcher struct {
+	client     kubernetes.Interface
+	namespace  string
+	secretName string
+	mutex      sync.Mutex
+	key        *rsa.PrivateKey
+}
+
+// NewSigningKeyWatcher gets or creates the signing key secret and returns a watcher of its private key
+func NewSigningKeyWatcher(client kubernetes.Interface, namespace, secretName string) (*SigningKeyWatcher, error) {
+	key, err := GetOrCreatePrivateKey(client, namespace, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKeyWatcher{client: client, namespace: namespace, secretName: secretName, key: key}, nil
+}
+
+// SigningKey returns the current private key
+func (w *SigningKeyWatcher) SigningKey() (*rsa.PrivateKey, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.key, nil
+}
+
+// Run reloads the private key every interval until stopCh is closed
+func (w *SigningKeyWatcher) Run(interval time.Duration, stopCh <-chan struct{}) {
+	wait.Until(w.refresh, interval, stopCh)
+}
+
+func (w *SigningKeyWatcher) refresh() {
+	key, err := GetOrCreatePrivateKey(w.client, w.namespace, w.secretName)
+	if err != nil {
+		klog.Errorf("Unable to reload signing key: %v", err)
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if !w.key.Equal(key) {
+		klog.Infof("Signing key %s/%s rotated", w.namespace, w.secretName)
+	}
+	w.key = key
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keys
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"kubevirt.io/containerized-data-importer/pkg/keys/keystest"
+)
+
+var _ = Describe("Signing key rotation", func() {
+	var (
+		privateKey *rsa.PrivateKey
+		rotation   = SigningKeyRotation{Refresh: 24 * time.Hour, Propagation: 10 * time.Minute, Grace: time.Hour}
+		start      = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		var err error
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	rotateAt := func(secret *v1.Secret, times ...time.Time) {
+		for _, t := range times {
+			_, err := RotateSigningKeySecret(secret, rotation, t)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	}
+
+	bundleOf := func(data []byte) []*rsa.PublicKey {
+		keys, err := ParsePublicKeyBundle(data)
+		Expect(err).ToNot(HaveOccurred())
+		return keys
+	}
+
+	It("Should publish, promote and retire keys", func() {
+		secret, err := keystest.NewPrivateKeySecret("myns", "mysecret", privateKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("Adopting the key")
+		changed, err := RotateSigningKeySecret(secret, rotation, start)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(secret.Annotations[AnnSigningKeyRotatedAt]).To(Equal("2021-01-01T00:00:00Z"))
+		Expect(bundleOf(secret.Data[KeyStorePublicKeyBundleFile])).To(Equal([]*rsa.PublicKey{&privateKey.PublicKey}))
+
+		changed, err = RotateSigningKeySecret(secret, rotation, start.Add(time.Hour))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		By("Publishing the next key after the refresh period")
+		changed, err = RotateSigningKeySecret(secret, rotation, start.Add(rotation.Refresh))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(secret.Data).To(HaveKey(KeyStoreNextPrivateKeyFile))
+		nextKey, err := parsePrivateKey(secret.Data[KeyStoreNextPrivateKeyFile])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bundleOf(secret.Data[KeyStorePublicKeyBundleFile])).To(Equal([]*rsa.PublicKey{&privateKey.PublicKey, &nextKey.PublicKey}))
+		currentKey, err := parsePrivateKey(secret.Data[KeyStorePrivateKeyFile])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(currentKey.Equal(privateKey)).To(BeTrue())
+
+		changed, err = RotateSigningKeySecret(secret, rotation, start.Add(rotation.Refresh+time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		By("Promoting the next key after the propagation period")
+		promotedAt := start.Add(rotation.Refresh + rotation.Propagation)
+		changed, err = RotateSigningKeySecret(secret, rotation, promotedAt)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(secret.Data).ToNot(HaveKey(KeyStoreNextPrivateKeyFile))
+		currentKey, err = parsePrivateKey(secret.Data[KeyStorePrivateKeyFile])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(currentKey.Equal(nextKey)).To(BeTrue())
+		Expect(bundleOf(secret.Data[KeyStorePublicKeyFile])).To(Equal([]*rsa.PublicKey{&nextKey.PublicKey}))
+		Expect(bundleOf(secret.Data[KeyStorePublicKeyBundleFile])).To(Equal([]*rsa.PublicKey{&nextKey.PublicKey, &privateKey.PublicKey}))
+		Expect(secret.Annotations[AnnSigningKeyRotatedAt]).To(Equal(promotedAt.Format(time.RFC3339)))
+		Expect(secret.Annotations[AnnSigningKeyPreviousExpiresAt]).To(Equal(promotedAt.Add(rotation.Grace).Format(time.RFC3339)))
+
+		By("Retiring the previous key after the grace period")
+		changed, err = RotateSigningKeySecret(secret, rotation, promotedAt.Add(rotation.Grace))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(secret.Data).ToNot(HaveKey(KeyStorePreviousPublicKeyFile))
+		Expect(secret.Annotations).ToNot(HaveKey(AnnSigningKeyPreviousExpiresAt))
+		Expect(bundleOf(secret.Data[KeyStorePublicKeyBundleFile])).To(Equal([]*rsa.PublicKey{&nextKey.PublicKey}))
+	})
+
+	It("Should return the time of the next rotation step", func() {
+		secret, err := keystest.NewPrivateKeySecret("myns", "mysecret", privateKey)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(NextSigningKeyRotation(secret, rotation).IsZero()).To(BeTrue())
+
+		By("Publishing the next key after the refresh period")
+		rotateAt(secret, start)
+		Expect(NextSigningKeyRotation(secret, rotation)).To(Equal(start.Add(rotation.Refresh)))
+
+		By("Promoting the next key after the propagation period")
+		publishedAt := start.Add(rotation.Refresh)
+		rotateAt(secret, publishedAt)
+		Expect(NextSigningKeyRotation(secret, rotation)).To(Equal(publishedAt.Add(rotation.Propagation)))
+
+		By("Retiring the previous key before the next refresh")
+		promotedAt := publishedAt.Add(rotation.Propagation)
+		rotateAt(secret, promotedAt)
+		Expect(NextSigningKeyRotation(secret, rotation)).To(Equal(promotedAt.Add(rotation.Grace)))
+
+		rotateAt(secret, promotedAt.Add(rotation.Grace))
+		Expect(NextSigningKeyRotation(secret, rotation)).To(Equal(promotedAt.Add(rotation.Refresh)))
+	})
+
+	It("Should fail without a private key", func() {
+		secret, err := keystest.NewPrivateKeySecret("myns", "mysecret", privateKey)
+		Expect(err).ToNot(HaveOccurred())
+		delete(secret.Data, KeyStorePrivateKeyFile)
+		_, err = RotateSigningKeySecret(secret, rotation, start)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should reload the public key bundle file when it changes", func() {
+		tmpDir, err := ioutil.TempDir("", "signing-key")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		path := filepath.Join(tmpDir, KeyStorePublicKeyBundleFile)
+
+		secret, err := keystest.NewPrivateKeySecret("myns", "mysecret", privateKey)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(path, secret.Data[KeyStorePublicKeyBundleFile], 0600)).To(Succeed())
+		bundle := NewPublicKeyBundleFile(path, filepath.Join(tmpDir, KeyStorePublicKeyFile))
+		keys, err := bundle.PublicKeys()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(Equal([]*rsa.PublicKey{&privateKey.PublicKey}))
+
+		rotateAt(secret, start, start.Add(rotation.Refresh))
+		Expect(ioutil.WriteFile(path, secret.Data[KeyStorePublicKeyBundleFile], 0600)).To(Succeed())
+		keys, err = bundle.PublicKeys()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(HaveLen(2))
+
+		Expect(os.Remove(path)).To(Succeed())
+		_, err = bundle.PublicKeys()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should read the public key while the secret has no bundle", func() {
+		tmpDir, err := ioutil.TempDir("", "signing-key")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		path := filepath.Join(tmpDir, KeyStorePublicKeyBundleFile)
+		fallbackPath := filepath.Join(tmpDir, KeyStorePublicKeyFile)
+
+		secret, err := keystest.NewPrivateKeySecret("myns", "mysecret", privateKey)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(fallbackPath, secret.Data[KeyStorePublicKeyFile], 0600)).To(Succeed())
+		bundle := NewPublicKeyBundleFile(path, fallbackPath)
+		keys, err := bundle.PublicKeys()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(Equal([]*rsa.PublicKey{&privateKey.PublicKey}))
+
+		By("Reading the bundle once it is added")
+		rotateAt(secret, start, start.Add(rotation.Refresh))
+		Expect(ioutil.WriteFile(path, secret.Data[KeyStorePublicKeyBundleFile], 0600)).To(Succeed())
+		keys, err = bundle.PublicKeys()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(HaveLen(2))
+	})
+
+	It("Should reload the signing key when it is rotated", func() {
+		secret, err := keystest.NewPrivateKeySecret("myns", "mysecret", privateKey)
+		Expect(err).ToNot(HaveOccurred())
+		client := k8sfake.NewSimpleClientset(secret)
+		watcher, err := NewSigningKeyWatcher(client, "myns", "mysecret")
+		Expect(err).ToNot(HaveOccurred())
+		key, err := watcher.SigningKey()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key.Equal(privateKey)).To(BeTrue())
+
+		rotateAt(secret, start, start.Add(rotation.Refresh), start.Add(rotation.Refresh+rotation.Propagation))
+		Expect(client.Tracker().Update(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, secret, "myns")).To(Succeed())
+		watcher.refresh()
+		key, err = watcher.SigningKey()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key.Equal(privateKey)).To(BeFalse())
+		Expect(bundleOf(secret.Data[KeyStorePublicKeyFile])).To(Equal([]*rsa.PublicKey{&key.PublicKey}))
+	})
+})
@@ -159,7 +159,11 @@ func (r *ReconcileCDI) Reconcile(request reconcile.Request) (reconcile.Result, e
 	operatorVersion := r.namespacedArgs.OperatorVersion
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling CDI")
-	return r.reconciler.Reconcile(request, operatorVersion, reqLogger)
+	result, err := r.reconciler.Reconcile(request, operatorVersion, reqLogger)
+	if err != nil {
+		return result, err
+	}
+	return r.requeueForSigningKeyRotation(result)
 }
 
 func (r *ReconcileCDI) add(mgr manager.Manager) error {
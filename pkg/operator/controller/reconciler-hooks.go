@@ -65,9 +65,12 @@ func (r *ReconcileCDI) checkSanity(cr controllerutil.Object, reqLogger logr.Logg
 	return nil, nil
 }
 
-// sync syncs certificates used by CDU
+// sync syncs certificates and the API signing key used by CDI
 func (r *ReconcileCDI) sync() error {
-	return r.certManager.Sync(r.getCertificateDefinitions())
+	if err := r.certManager.Sync(r.getCertificateDefinitions()); err != nil {
+		return err
+	}
+	return r.rotateSigningKey()
 }
 
 func (r *ReconcileCDI) configMapOwnerDeleted(cm *corev1.ConfigMap) (bool, error) {
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/keys"
+)
+
+const apiSigningKeySecretName = "cdi-api-signing-key"
+
+// signingKeyRotation is the rotation of the key the API server signs upload and clone tokens with, the grace period
+// is twice the longest lifetime of the tokens, common.MaxUploadTokenMaxExpirationSeconds
+var signingKeyRotation = keys.SigningKeyRotation{
+	Refresh:     15 * 24 * time.Hour,
+	Propagation: 10 * time.Minute,
+	Grace:       2 * common.MaxUploadTokenMaxExpirationSeconds * time.Second,
+}
+
+// getSigningKeySecret returns the signing key secret created by the API server, nil if it doesn't exist yet
+func (r *ReconcileCDI) getSigningKeySecret() (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Name: apiSigningKeySecretName, Namespace: r.namespace}
+	if err := r.client.Get(context.TODO(), key, secret); err != nil {
+		if errors.IsNotFound(err) {
+			// the API server creates the key on startup
+			return nil, nil
+		}
+		return nil, err
+	}
+	return secret, nil
+}
+
+// rotateSigningKey rotates the signing key created by the API server
+func (r *ReconcileCDI) rotateSigningKey() error {
+	secret, err := r.getSigningKeySecret()
+	if secret == nil || err != nil {
+		return err
+	}
+
+	changed, err := keys.RotateSigningKeySecret(secret, signingKeyRotation, time.Now())
+	if err != nil || !changed {
+		return err
+	}
+
+	log.Info("Rotating signing key", "secret", apiSigningKeySecretName)
+	return r.client.Update(context.TODO(), secret)
+}
+
+// requeueForSigningKeyRotation makes the result requeue the CDI no later than the next step of the signing key
+// rotation, which only happens when the CDI is reconciled
+func (r *ReconcileCDI) requeueForSigningKeyRotation(result reconcile.Result) (reconcile.Result, error) {
+	if result.Requeue && result.RequeueAfter == 0 {
+		return result, nil
+	}
+	secret, err := r.getSigningKeySecret()
+	if secret == nil || err != nil {
+		return result, err
+	}
+	next := keys.NextSigningKeyRotation(secret, signingKeyRotation)
+	if next.IsZero() {
+		return result, nil
+	}
+	requeueAfter := time.Until(next)
+	if requeueAfter < time.Second {
+		requeueAfter = time.Second
+	}
+	if result.RequeueAfter == 0 || requeueAfter < result.RequeueAfter {
+		result.RequeueAfter = requeueAfter
+	}
+	return result, nil
+}
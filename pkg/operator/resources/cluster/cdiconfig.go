@@ -130,6 +130,49 @@ func createCDIConfigCRD() *extv1.CustomResourceDefinition {
 												},
 											},
 										},
+										"preallocation": {
+											Description: "Preallocation controls whether storage for DataVolumes should be allocated in advance, unless the DataVolume overrides it.",
+											Type:        "boolean",
+										},
+										"maxDownloadConcurrency": {
+											Description: "MaxDownloadConcurrency caps the number of parallel ranged requests a DataVolume may use to download an http or S3 source, if not defined it is 4",
+											Type:        "integer",
+											Format:      "int32",
+										},
+										"uploadTokenMaxExpirationSeconds": {
+											Description: "UploadTokenMaxExpirationSeconds caps the lifetime clients may request for upload tokens, if not defined it is 3600, and it is at most 86400",
+											Type:        "integer",
+											Format:      "int64",
+										},
+										"cloneCodec": {
+											Description: "CloneCodec selects the compression of the stream of host-assisted clones, unless the DataVolume overrides it. If not defined it is snappy",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"level": {
+													Description: "Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3",
+													Type:        "integer",
+													Format:      "int32",
+												},
+												"name": {
+													Description: "Name of the codec, \"none\", \"snappy\" or \"zstd\"",
+													Type:        "string",
+													Enum: []extv1.JSON{
+														{
+															Raw: []byte(`"none"`),
+														},
+														{
+															Raw: []byte(`"snappy"`),
+														},
+														{
+															Raw: []byte(`"zstd"`),
+														},
+													},
+												},
+											},
+											Required: []string{
+												"name",
+											},
+										},
 									},
 								},
 								"status": {
@@ -208,6 +251,49 @@ func createCDIConfigCRD() *extv1.CustomResourceDefinition {
 												},
 											},
 										},
+										"preallocation": {
+											Description: "Preallocation controls whether storage for DataVolumes should be allocated in advance.",
+											Type:        "boolean",
+										},
+										"maxDownloadConcurrency": {
+											Description: "MaxDownloadConcurrency is the maximum number of parallel ranged requests a DataVolume may use to download an http or S3 source",
+											Type:        "integer",
+											Format:      "int32",
+										},
+										"uploadTokenMaxExpirationSeconds": {
+											Description: "UploadTokenMaxExpirationSeconds is the maximum lifetime of upload tokens",
+											Type:        "integer",
+											Format:      "int64",
+										},
+										"cloneCodec": {
+											Description: "CloneCodec is the compression of the stream of host-assisted clones",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"level": {
+													Description: "Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3",
+													Type:        "integer",
+													Format:      "int32",
+												},
+												"name": {
+													Description: "Name of the codec, \"none\", \"snappy\" or \"zstd\"",
+													Type:        "string",
+													Enum: []extv1.JSON{
+														{
+															Raw: []byte(`"none"`),
+														},
+														{
+															Raw: []byte(`"snappy"`),
+														},
+														{
+															Raw: []byte(`"zstd"`),
+														},
+													},
+												},
+											},
+											Required: []string{
+												"name",
+											},
+										},
 									},
 								},
 							},
@@ -322,6 +408,49 @@ func createCDIConfigCRD() *extv1.CustomResourceDefinition {
 												},
 											},
 										},
+										"preallocation": {
+											Description: "Preallocation controls whether storage for DataVolumes should be allocated in advance, unless the DataVolume overrides it.",
+											Type:        "boolean",
+										},
+										"maxDownloadConcurrency": {
+											Description: "MaxDownloadConcurrency caps the number of parallel ranged requests a DataVolume may use to download an http or S3 source, if not defined it is 4",
+											Type:        "integer",
+											Format:      "int32",
+										},
+										"uploadTokenMaxExpirationSeconds": {
+											Description: "UploadTokenMaxExpirationSeconds caps the lifetime clients may request for upload tokens, if not defined it is 3600, and it is at most 86400",
+											Type:        "integer",
+											Format:      "int64",
+										},
+										"cloneCodec": {
+											Description: "CloneCodec selects the compression of the stream of host-assisted clones, unless the DataVolume overrides it. If not defined it is snappy",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"level": {
+													Description: "Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3",
+													Type:        "integer",
+													Format:      "int32",
+												},
+												"name": {
+													Description: "Name of the codec, \"none\", \"snappy\" or \"zstd\"",
+													Type:        "string",
+													Enum: []extv1.JSON{
+														{
+															Raw: []byte(`"none"`),
+														},
+														{
+															Raw: []byte(`"snappy"`),
+														},
+														{
+															Raw: []byte(`"zstd"`),
+														},
+													},
+												},
+											},
+											Required: []string{
+												"name",
+											},
+										},
 									},
 								},
 								"status": {
@@ -400,6 +529,49 @@ func createCDIConfigCRD() *extv1.CustomResourceDefinition {
 												},
 											},
 										},
+										"preallocation": {
+											Description: "Preallocation controls whether storage for DataVolumes should be allocated in advance.",
+											Type:        "boolean",
+										},
+										"maxDownloadConcurrency": {
+											Description: "MaxDownloadConcurrency is the maximum number of parallel ranged requests a DataVolume may use to download an http or S3 source",
+											Type:        "integer",
+											Format:      "int32",
+										},
+										"uploadTokenMaxExpirationSeconds": {
+											Description: "UploadTokenMaxExpirationSeconds is the maximum lifetime of upload tokens",
+											Type:        "integer",
+											Format:      "int64",
+										},
+										"cloneCodec": {
+											Description: "CloneCodec is the compression of the stream of host-assisted clones",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"level": {
+													Description: "Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3",
+													Type:        "integer",
+													Format:      "int32",
+												},
+												"name": {
+													Description: "Name of the codec, \"none\", \"snappy\" or \"zstd\"",
+													Type:        "string",
+													Enum: []extv1.JSON{
+														{
+															Raw: []byte(`"none"`),
+														},
+														{
+															Raw: []byte(`"snappy"`),
+														},
+														{
+															Raw: []byte(`"zstd"`),
+														},
+													},
+												},
+											},
+											Required: []string{
+												"name",
+											},
+										},
 									},
 								},
 							},
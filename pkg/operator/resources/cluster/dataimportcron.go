@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubevirt.io/containerized-data-importer/pkg/operator/resources/utils"
+)
+
+// NewDataImportCronCrd - provides DataImportCron CRD
+func NewDataImportCronCrd() *extv1.CustomResourceDefinition {
+	return createDataImportCronCRD()
+}
+
+// createDataImportCronCRD creates the DataImportCron schema
+func createDataImportCronCRD() *extv1.CustomResourceDefinition {
+	preserveUnknownFields := true
+	return &extv1.CustomResourceDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apiextensions.k8s.io/v1",
+			Kind:       "CustomResourceDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "dataimportcrons.cdi.kubevirt.io",
+			Labels: utils.ResourcesBuiler.WithCommonLabels(nil),
+		},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: "cdi.kubevirt.io",
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:   "DataImportCron",
+				Plural: "dataimportcrons",
+				ShortNames: []string{
+					"dic",
+					"dics",
+				},
+				ListKind: "DataImportCronList",
+				Singular: "dataimportcron",
+				Categories: []string{
+					"all",
+				},
+			},
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{
+					Name:         "v1beta1",
+					Served:       true,
+					Storage:      true,
+					Subresources: &extv1.CustomResourceSubresources{},
+					Schema: &extv1.CustomResourceValidation{
+						OpenAPIV3Schema: &extv1.JSONSchemaProps{
+							Description: "DataImportCron defines a cron job for recurring polling/importing disk images as PVCs",
+							Type:        "object",
+							Properties: map[string]extv1.JSONSchemaProps{
+								// We are aware apiVersion, kind, and metadata are technically not needed, but to make comparision with
+								// kubebuilder easier, we add it here.
+								"apiVersion": {
+									Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+									Type:        "string",
+								},
+								"kind": {
+									Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+									Type:        "string",
+								},
+								"metadata": {
+									Type: "object",
+								},
+								"spec": {
+									Description: "DataImportCronSpec defines specification for DataImportCron",
+									Type:        "object",
+									Properties: map[string]extv1.JSONSchemaProps{
+										"template": {
+											Description: "Template specifies template for the DVs to be created, its source has to be registry or http",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"apiVersion": {
+													Type: "string",
+												},
+												"kind": {
+													Type: "string",
+												},
+												"metadata": {
+													Type:                   "object",
+													XPreserveUnknownFields: &preserveUnknownFields,
+												},
+												"spec": getDataVolumeSpecSchema(),
+											},
+											Required: []string{
+												"spec",
+											},
+										},
+										"schedule": {
+											Description: "Schedule specifies in cron format when and how often to look for new imports",
+											Type:        "string",
+										},
+										"garbageCollect": {
+											Description: "GarbageCollect specifies whether old PVCs should be cleaned up after a new PVC is imported. Options are currently \"Outdated\" and \"Never\", defaults to \"Outdated\".",
+											Type:        "string",
+										},
+										"importsToKeep": {
+											Description: "Number of import PVCs to keep when garbage collecting. Default is 3.",
+											Type:        "integer",
+											Format:      "int32",
+										},
+										"managedDataSource": {
+											Description: "ManagedDataSource specifies the name of the DataSource this cron will point to the last imported PVC. The DataSource is in the same namespace, and is created if it doesn't exist.",
+											Type:        "string",
+										},
+									},
+									Required: []string{
+										"schedule",
+										"template",
+									},
+								},
+								"status": {
+									Description: "DataImportCronStatus provides the most recently observed status of the DataImportCron",
+									Type:        "object",
+									Properties: map[string]extv1.JSONSchemaProps{
+										"currentImports": {
+											Description: "CurrentImports are the imports in progress. Currently only a single import is supported.",
+											Type:        "array",
+											Items: &extv1.JSONSchemaPropsOrArray{
+												Schema: &extv1.JSONSchemaProps{
+													Description: "ImportStatus of a currently in progress import",
+													Type:        "object",
+													Properties: map[string]extv1.JSONSchemaProps{
+														"dataVolumeName": {
+															Description: "DataVolumeName is the currently in progress import DataVolume",
+															Type:        "string",
+														},
+														"digest": {
+															Description: "Digest of the currently imported image",
+															Type:        "string",
+														},
+													},
+													Required: []string{
+														"dataVolumeName",
+													},
+												},
+											},
+										},
+										"lastImportedPVC": {
+											Description: "LastImportedPVC is the last imported PVC",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"namespace": {
+													Description: "The namespace of the source PVC",
+													Type:        "string",
+												},
+												"name": {
+													Description: "The name of the source PVC",
+													Type:        "string",
+												},
+											},
+											Required: []string{
+												"name",
+												"namespace",
+											},
+										},
+										"lastExecutionTimestamp": {
+											Description: "LastExecutionTimestamp is the time of the last polling",
+											Type:        "string",
+											Format:      "date-time",
+										},
+										"lastImportTimestamp": {
+											Description: "LastImportTimestamp is the time of the last import",
+											Type:        "string",
+											Format:      "date-time",
+										},
+										"conditions": {
+											Items: &extv1.JSONSchemaPropsOrArray{
+												Schema: &extv1.JSONSchemaProps{
+													Description: "DataImportCronCondition represents the state of a data import cron condition",
+													Type:        "object",
+													Properties: map[string]extv1.JSONSchemaProps{
+														"lastHeartbeatTime": {
+															Type:   "string",
+															Format: "date-time",
+														},
+														"lastTransitionTime": {
+															Type:   "string",
+															Format: "date-time",
+														},
+														"message": {
+															Type: "string",
+														},
+														"reason": {
+															Type: "string",
+														},
+														"status": {
+															Type: "string",
+														},
+														"type": {
+															Description: "DataImportCronConditionType is the string representation of known condition types",
+															Type:        "string",
+														},
+													},
+													Required: []string{
+														"status",
+														"type",
+													},
+												},
+											},
+											Type: "array",
+										},
+									},
+								},
+							},
+							Required: []string{
+								"spec",
+							},
+						},
+					},
+				},
+			},
+			Scope: "Namespaced",
+		},
+	}
+}
+
+// getDataVolumeSpecSchema returns the schema of the spec of the storage version of the DataVolume CRD
+func getDataVolumeSpecSchema() extv1.JSONSchemaProps {
+	for _, version := range createDataVolumeCRD().Spec.Versions {
+		if version.Storage {
+			return version.Schema.OpenAPIV3Schema.Properties["spec"]
+		}
+	}
+	return extv1.JSONSchemaProps{Type: "object"}
+}
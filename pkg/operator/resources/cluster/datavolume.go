@@ -114,6 +114,25 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 															Description: "CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate",
 															Type:        "string",
 														},
+														"checksum": {
+															Description: "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>. With an OVA, it is the checksum of the imported disk file.",
+															Type:        "string",
+														},
+														"ova": {
+															Description: "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import",
+															Type:        "object",
+															Properties: map[string]extv1.JSONSchemaProps{
+																"disk": {
+																	Description: "Disk is either the index of the disk in the DiskSection of the OVF descriptor, starting at 0, or the href of the disk file in its References section. Defaults to the first disk.",
+																	Type:        "string",
+																},
+															},
+														},
+														"concurrency": {
+															Description: "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.",
+															Type:        "integer",
+															Format:      "int32",
+														},
 													},
 													Required: []string{
 														"url",
@@ -157,6 +176,25 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 															Description: "SecretRef provides the secret reference needed to access the S3 source",
 															Type:        "string",
 														},
+														"checksum": {
+															Description: "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>. With an OVA, it is the checksum of the imported disk file.",
+															Type:        "string",
+														},
+														"ova": {
+															Description: "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import",
+															Type:        "object",
+															Properties: map[string]extv1.JSONSchemaProps{
+																"disk": {
+																	Description: "Disk is either the index of the disk in the DiskSection of the OVF descriptor, starting at 0, or the href of the disk file in its References section. Defaults to the first disk.",
+																	Type:        "string",
+																},
+															},
+														},
+														"concurrency": {
+															Description: "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.",
+															Type:        "integer",
+															Format:      "int32",
+														},
 													},
 													Required: []string{
 														"url",
@@ -178,6 +216,10 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 															Description: "CertConfigMap provides a reference to the Registry certs",
 															Type:        "string",
 														},
+														"checksum": {
+															Description: "Checksum is the expected checksum of the disk image file in the container image, in the <algorithm>:<hex digest> format",
+															Type:        "string",
+														},
 													},
 													Required: []string{
 														"url",
@@ -204,6 +246,12 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 												"upload": {
 													Description: "DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source",
 													Type:        "object",
+													Properties: map[string]extv1.JSONSchemaProps{
+														"checksum": {
+															Description: "Checksum is the expected checksum of the uploaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>",
+															Type:        "string",
+														},
+													},
 												},
 												"vddk": {
 													Description: "DataVolumeSourceVDDK provides the parameters to create a Data Volume from a Vmware source",
@@ -404,6 +452,39 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 											Description: "FinalCheckpoint indicates whether the current DataVolumeCheckpoint is the final checkpoint.",
 											Type:        "boolean",
 										},
+										"preallocation": {
+											Description: "Preallocation controls whether storage for DataVolumes should be allocated in advance. If not set, the preallocation value of the CDIConfig is used.",
+											Type:        "boolean",
+										},
+										"cloneCodec": {
+											Description: "CloneCodec selects the compression of the stream of a host-assisted clone. If not set, the cloneCodec of the CDIConfig is used.",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"level": {
+													Description: "Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3",
+													Type:        "integer",
+													Format:      "int32",
+												},
+												"name": {
+													Description: "Name of the codec, \"none\", \"snappy\" or \"zstd\"",
+													Type:        "string",
+													Enum: []extv1.JSON{
+														{
+															Raw: []byte(`"none"`),
+														},
+														{
+															Raw: []byte(`"snappy"`),
+														},
+														{
+															Raw: []byte(`"zstd"`),
+														},
+													},
+												},
+											},
+											Required: []string{
+												"name",
+											},
+										},
 									},
 									Required: []string{
 										"pvc",
@@ -427,6 +508,11 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 											Type:        "integer",
 											Format:      "int32",
 										},
+										"allocatedBytes": {
+											Description: "AllocatedBytes is the amount of storage allocated on the target volume when it was populated. Zero blocks of the source are not allocated, so this may be smaller than the size of the image. On block volumes it is the amount of data written to them.",
+											Type:        "integer",
+											Format:      "int64",
+										},
 										"conditions": {
 											Items: &extv1.JSONSchemaPropsOrArray{
 												Schema: &extv1.JSONSchemaProps{
@@ -556,6 +642,25 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 															Description: "CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate",
 															Type:        "string",
 														},
+														"checksum": {
+															Description: "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>. With an OVA, it is the checksum of the imported disk file.",
+															Type:        "string",
+														},
+														"ova": {
+															Description: "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import",
+															Type:        "object",
+															Properties: map[string]extv1.JSONSchemaProps{
+																"disk": {
+																	Description: "Disk is either the index of the disk in the DiskSection of the OVF descriptor, starting at 0, or the href of the disk file in its References section. Defaults to the first disk.",
+																	Type:        "string",
+																},
+															},
+														},
+														"concurrency": {
+															Description: "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.",
+															Type:        "integer",
+															Format:      "int32",
+														},
 													},
 													Required: []string{
 														"url",
@@ -599,6 +704,25 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 															Description: "SecretRef provides the secret reference needed to access the S3 source",
 															Type:        "string",
 														},
+														"checksum": {
+															Description: "Checksum is the expected checksum of the downloaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>. With an OVA, it is the checksum of the imported disk file.",
+															Type:        "string",
+														},
+														"ova": {
+															Description: "OVA indicates the downloaded data is an OVA archive, and selects the disk of the archive to import",
+															Type:        "object",
+															Properties: map[string]extv1.JSONSchemaProps{
+																"disk": {
+																	Description: "Disk is either the index of the disk in the DiskSection of the OVF descriptor, starting at 0, or the href of the disk file in its References section. Defaults to the first disk.",
+																	Type:        "string",
+																},
+															},
+														},
+														"concurrency": {
+															Description: "Concurrency is the number of ranged requests used to download a raw or decompressed image in parallel, capped by maxDownloadConcurrency in CDIConfig. Defaults to 1, a single request.",
+															Type:        "integer",
+															Format:      "int32",
+														},
 													},
 													Required: []string{
 														"url",
@@ -620,6 +744,10 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 															Description: "CertConfigMap provides a reference to the Registry certs",
 															Type:        "string",
 														},
+														"checksum": {
+															Description: "Checksum is the expected checksum of the disk image file in the container image, in the <algorithm>:<hex digest> format",
+															Type:        "string",
+														},
 													},
 													Required: []string{
 														"url",
@@ -646,6 +774,12 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 												"upload": {
 													Description: "DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source",
 													Type:        "object",
+													Properties: map[string]extv1.JSONSchemaProps{
+														"checksum": {
+															Description: "Checksum is the expected checksum of the uploaded data, in the <algorithm>:<hex digest> format, for example sha256:<hex>",
+															Type:        "string",
+														},
+													},
 												},
 												"vddk": {
 													Description: "DataVolumeSourceVDDK provides the parameters to create a Data Volume from a Vmware source",
@@ -677,6 +811,24 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 													Description: "DataVolumeBlankImage provides the parameters to create a new raw blank image for the PVC",
 													Type:        "object",
 												},
+												"snapshot": {
+													Description: "DataVolumeSourceSnapshot provides the parameters to create a Data Volume from an existing VolumeSnapshot",
+													Type:        "object",
+													Properties: map[string]extv1.JSONSchemaProps{
+														"namespace": {
+															Description: "The namespace of the source VolumeSnapshot",
+															Type:        "string",
+														},
+														"name": {
+															Description: "The name of the source VolumeSnapshot",
+															Type:        "string",
+														},
+													},
+													Required: []string{
+														"name",
+														"namespace",
+													},
+												},
 											},
 										},
 										"pvc": {
@@ -818,6 +970,145 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 												},
 											},
 										},
+										"storage": {
+											Description: "Storage is the requirements to be used for the PVC, an alternative to PVC where the size may be left out to be inferred from the source",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"resources": {
+													Description: "Resources represents the minimum resources the volume should have. More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#resources",
+													Type:        "object",
+													Properties: map[string]extv1.JSONSchemaProps{
+														"limits": {
+															Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+															Type:        "object",
+															AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+																Schema: &extv1.JSONSchemaProps{
+																	AnyOf: []extv1.JSONSchemaProps{
+																		{
+																			Type: "integer",
+																		},
+																		{
+																			Type: "string",
+																		},
+																	},
+																	Pattern:      "^(\\+|-)?(([0-9]+(\\.[0-9]*)?)|(\\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\\+|-)?(([0-9]+(\\.[0-9]*)?)|(\\.[0-9]+))))?$",
+																	XIntOrString: true,
+																},
+															},
+														},
+														"requests": {
+															Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+															Type:        "object",
+															AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+																Schema: &extv1.JSONSchemaProps{
+																	AnyOf: []extv1.JSONSchemaProps{
+																		{
+																			Type: "integer",
+																		},
+																		{
+																			Type: "string",
+																		},
+																	},
+																	Pattern:      "^(\\+|-)?(([0-9]+(\\.[0-9]*)?)|(\\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\\+|-)?(([0-9]+(\\.[0-9]*)?)|(\\.[0-9]+))))?$",
+																	XIntOrString: true,
+																},
+															},
+														},
+													},
+												},
+												"storageClassName": {
+													Description: "Name of the StorageClass required by the claim. More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#class-1",
+													Type:        "string",
+												},
+												"accessModes": {
+													Description: "AccessModes contains the desired access modes the volume should have. More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#access-modes-1",
+													Type:        "array",
+													Items: &extv1.JSONSchemaPropsOrArray{
+														Schema: &extv1.JSONSchemaProps{
+															Type: "string",
+														},
+													},
+												},
+												"selector": {
+													Description: "A label query over volumes to consider for binding.",
+													Properties: map[string]extv1.JSONSchemaProps{
+														"matchExpressions": {
+															Description: "matchExpressions is a list of label selector requirements. The requirements are ANDed.",
+															Items: &extv1.JSONSchemaPropsOrArray{
+																Schema: &extv1.JSONSchemaProps{
+																	Description: "A label selector requirement is a selector that contains values, a key, and an operator that relates the key and values.",
+																	Properties: map[string]extv1.JSONSchemaProps{
+																		"key": {
+																			Description: "key is the label key that the selector applies to.",
+																			Type:        "string",
+																		},
+																		"operator": {
+																			Description: "operator represents a key's relationship to a set of values. Valid operators are In, NotIn, Exists and DoesNotExist.",
+																			Type:        "string",
+																		},
+																		"values": {
+																			Description: "values is an array of string values. If the operator is In or NotIn, the values array must be non-empty. If the operator is Exists or DoesNotExist, the values array must be empty. This array is replaced during a strategic merge patch.",
+																			Type:        "array",
+																			Items: &extv1.JSONSchemaPropsOrArray{
+																				Schema: &extv1.JSONSchemaProps{
+																					Type: "string",
+																				},
+																			},
+																		},
+																	},
+																	Required: []string{
+																		"key",
+																		"operator",
+																	},
+																	Type: "object",
+																},
+															},
+															Type: "array",
+														},
+														"matchLabels": {
+															Description: "matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels map is equivalent to an element of matchExpressions, whose key field is \"key\", the operator is \"In\", and the values array contains only \"value\". The requirements are ANDed.",
+															AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+																Schema: &extv1.JSONSchemaProps{
+																	Type: "string",
+																},
+															},
+															Type: "object",
+														},
+													},
+													Type: "object",
+												},
+												"volumeMode": {
+													Description: "volumeMode defines what type of volume is required by the claim. Value of Filesystem is implied when not included in claim spec.",
+													Type:        "string",
+												},
+												"volumeName": {
+													Description: "VolumeName is the binding reference to the PersistentVolume backing this claim.",
+													Type:        "string",
+												},
+											},
+										},
+										"sourceRef": {
+											Description: "SourceRef is an indirect reference to the source of data for the requested DataVolume",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"kind": {
+													Description: "The kind of the source reference, currently only \"DataSource\" is supported",
+													Type:        "string",
+												},
+												"namespace": {
+													Description: "The namespace of the source reference, defaults to the DataVolume namespace",
+													Type:        "string",
+												},
+												"name": {
+													Description: "The name of the source reference",
+													Type:        "string",
+												},
+											},
+											Required: []string{
+												"kind",
+												"name",
+											},
+										},
 										"checkpoints": {
 											Items: &extv1.JSONSchemaPropsOrArray{
 												Schema: &extv1.JSONSchemaProps{
@@ -846,10 +1137,39 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 											Description: "FinalCheckpoint indicates whether the current DataVolumeCheckpoint is the final checkpoint.",
 											Type:        "boolean",
 										},
-									},
-									Required: []string{
-										"pvc",
-										"source",
+										"preallocation": {
+											Description: "Preallocation controls whether storage for DataVolumes should be allocated in advance. If not set, the preallocation value of the CDIConfig is used.",
+											Type:        "boolean",
+										},
+										"cloneCodec": {
+											Description: "CloneCodec selects the compression of the stream of a host-assisted clone. If not set, the cloneCodec of the CDIConfig is used.",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"level": {
+													Description: "Level is the zstd compression level, from 1 (fastest) to 22 (best compression), defaults to 3",
+													Type:        "integer",
+													Format:      "int32",
+												},
+												"name": {
+													Description: "Name of the codec, \"none\", \"snappy\" or \"zstd\"",
+													Type:        "string",
+													Enum: []extv1.JSON{
+														{
+															Raw: []byte(`"none"`),
+														},
+														{
+															Raw: []byte(`"snappy"`),
+														},
+														{
+															Raw: []byte(`"zstd"`),
+														},
+													},
+												},
+											},
+											Required: []string{
+												"name",
+											},
+										},
 									},
 								},
 								"status": {
@@ -869,6 +1189,24 @@ func createDataVolumeCRD() *extv1.CustomResourceDefinition {
 											Type:        "integer",
 											Format:      "int32",
 										},
+										"allocatedBytes": {
+											Description: "AllocatedBytes is the amount of storage allocated on the target volume when it was populated. Zero blocks of the source are not allocated, so this may be smaller than the size of the image. On block volumes it is the amount of data written to them.",
+											Type:        "integer",
+											Format:      "int64",
+										},
+										"inferredSize": {
+											Description: "InferredSize is the storage size requested for the PVC when spec.storage doesn't request one, computed from the size of the source and the filesystem overhead.",
+											AnyOf: []extv1.JSONSchemaProps{
+												{
+													Type: "integer",
+												},
+												{
+													Type: "string",
+												},
+											},
+											Pattern:      "^(\\+|-)?(([0-9]+(\\.[0-9]*)?)|(\\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\\+|-)?(([0-9]+(\\.[0-9]*)?)|(\\.[0-9]+))))?$",
+											XIntOrString: true,
+										},
 										"conditions": {
 											Items: &extv1.JSONSchemaPropsOrArray{
 												Schema: &extv1.JSONSchemaProps{
@@ -54,6 +54,10 @@ func createCRDResources(args *FactoryArgs) []runtime.Object {
 	return []runtime.Object{
 		createDataVolumeCRD(),
 		createCDIConfigCRD(),
+		createDataSourceCRD(),
+		createDataImportCronCRD(),
+		createStorageProfileCRD(),
+		createVolumeExportCRD(),
 	}
 }
 
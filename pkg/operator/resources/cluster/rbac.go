@@ -42,6 +42,9 @@ func getAdminPolicyRules() []rbacv1.PolicyRule {
 			},
 			Resources: []string{
 				"datavolumes",
+				"datasources",
+				"dataimportcrons",
+				"volumeexports",
 			},
 			Verbs: []string{
 				"*",
@@ -64,6 +67,7 @@ func getAdminPolicyRules() []rbacv1.PolicyRule {
 			},
 			Resources: []string{
 				"cdiconfigs",
+				"storageprofiles",
 			},
 			Verbs: []string{
 				"get",
@@ -79,6 +83,7 @@ func getAdminPolicyRules() []rbacv1.PolicyRule {
 			},
 			Resources: []string{
 				"uploadtokenrequests",
+				"exporttokenrequests",
 			},
 			Verbs: []string{
 				"*",
@@ -101,6 +106,9 @@ func getViewPolicyRules() []rbacv1.PolicyRule {
 			},
 			Resources: []string{
 				"datavolumes",
+				"datasources",
+				"dataimportcrons",
+				"volumeexports",
 			},
 			Verbs: []string{
 				"get",
@@ -125,6 +133,7 @@ func getViewPolicyRules() []rbacv1.PolicyRule {
 			},
 			Resources: []string{
 				"cdiconfigs",
+				"storageprofiles",
 			},
 			Verbs: []string{
 				"get",
@@ -143,6 +152,7 @@ func createConfigReaderClusterRole(name string) *rbacv1.ClusterRole {
 			},
 			Resources: []string{
 				"cdiconfigs",
+				"storageprofiles",
 			},
 			Verbs: []string{
 				"get",
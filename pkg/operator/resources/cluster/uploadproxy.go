@@ -41,6 +41,7 @@ func getUploadProxyClusterPolicyRules() []rbacv1.PolicyRule {
 			},
 			Resources: []string{
 				"persistentvolumeclaims",
+				"pods",
 			},
 			Verbs: []string{
 				"get",
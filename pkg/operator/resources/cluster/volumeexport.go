@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubevirt.io/containerized-data-importer/pkg/operator/resources/utils"
+)
+
+// NewVolumeExportCrd - provides VolumeExport CRD
+func NewVolumeExportCrd() *extv1.CustomResourceDefinition {
+	return createVolumeExportCRD()
+}
+
+// createVolumeExportCRD creates the VolumeExport schema
+func createVolumeExportCRD() *extv1.CustomResourceDefinition {
+	return &extv1.CustomResourceDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apiextensions.k8s.io/v1",
+			Kind:       "CustomResourceDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "volumeexports.cdi.kubevirt.io",
+			Labels: utils.ResourcesBuiler.WithCommonLabels(nil),
+		},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: "cdi.kubevirt.io",
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:   "VolumeExport",
+				Plural: "volumeexports",
+				ShortNames: []string{
+					"vex",
+					"vexs",
+				},
+				ListKind: "VolumeExportList",
+				Singular: "volumeexport",
+				Categories: []string{
+					"all",
+				},
+			},
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{
+					Name:         "v1beta1",
+					Served:       true,
+					Storage:      true,
+					Subresources: &extv1.CustomResourceSubresources{},
+					Schema: &extv1.CustomResourceValidation{
+						OpenAPIV3Schema: &extv1.JSONSchemaProps{
+							Description: "VolumeExport makes the data of a PVC or DataVolume available for download through the upload proxy",
+							Type:        "object",
+							Properties: map[string]extv1.JSONSchemaProps{
+								// We are aware apiVersion, kind, and metadata are technically not needed, but to make comparision with
+								// kubebuilder easier, we add it here.
+								"apiVersion": {
+									Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+									Type:        "string",
+								},
+								"kind": {
+									Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+									Type:        "string",
+								},
+								"metadata": {
+									Type: "object",
+								},
+								"spec": {
+									Description: "VolumeExportSpec defines specification for VolumeExport",
+									Type:        "object",
+									Properties: map[string]extv1.JSONSchemaProps{
+										"source": {
+											Description: "Source is the PVC or DataVolume to export, in the namespace of the VolumeExport",
+											Type:        "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"kind": {
+													Description: "The kind of the source, PersistentVolumeClaim or DataVolume",
+													Type:        "string",
+													Enum: []extv1.JSON{
+														{Raw: []byte(`"PersistentVolumeClaim"`)},
+														{Raw: []byte(`"DataVolume"`)},
+													},
+												},
+												"name": {
+													Description: "The name of the source",
+													Type:        "string",
+												},
+											},
+											Required: []string{
+												"kind",
+												"name",
+											},
+										},
+									},
+									Required: []string{
+										"source",
+									},
+								},
+								"status": {
+									Description: "VolumeExportStatus provides the most recently observed status of the VolumeExport",
+									Type:        "object",
+									Properties: map[string]extv1.JSONSchemaProps{
+										"phase": {
+											Description: "Phase is the current phase of the export",
+											Type:        "string",
+										},
+									},
+								},
+							},
+							Required: []string{
+								"spec",
+							},
+						},
+					},
+					AdditionalPrinterColumns: []extv1.CustomResourceColumnDefinition{
+						{
+							Name:        "Phase",
+							Type:        "string",
+							Description: "The phase the export is in",
+							JSONPath:    ".status.phase",
+						},
+						{
+							Name:     "Age",
+							Type:     "date",
+							JSONPath: ".metadata.creationTimestamp",
+						},
+					},
+				},
+			},
+			Scope: "Namespaced",
+		},
+	}
+}
@@ -93,6 +93,8 @@ func createControllerServiceAccount() *corev1.ServiceAccount {
 
 func createControllerDeployment(controllerImage, importerImage, clonerImage, uploadServerImage, verbosity, pullPolicy string, infraNodePlacement *sdkapi.NodePlacement) *appsv1.Deployment {
 	defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+	// signing key secrets created by older versions have no bundle, the single public key is used instead
+	signingKeyOptional := true
 	deployment := utils.CreateDeployment(controllerResourceName, "app", "containerized-data-importer", common.ControllerServiceAccountName, int32(1), infraNodePlacement)
 	container := utils.CreateContainer("cdi-controller", controllerImage, verbosity, pullPolicy)
 	container.Env = []corev1.EnvVar{
@@ -163,8 +165,13 @@ func createControllerDeployment(controllerImage, importerImage, clonerImage, upl
 							Key:  "id_rsa.pub",
 							Path: "id_rsa.pub",
 						},
+						{
+							Key:  "id_rsa.pub.bundle",
+							Path: "id_rsa.pub.bundle",
+						},
 					},
 					DefaultMode: &defaultMode,
+					Optional:    &signingKeyOptional,
 				},
 			},
 		},
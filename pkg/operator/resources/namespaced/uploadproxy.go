@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 
+	"kubevirt.io/containerized-data-importer/pkg/controller"
 	utils "kubevirt.io/containerized-data-importer/pkg/operator/resources/utils"
 )
 
@@ -83,21 +84,10 @@ func createUploadProxyRole() *rbacv1.Role {
 
 func createUploadProxyDeployment(image, verbosity, pullPolicy string, infraNodePlacement *sdkapi.NodePlacement) *appsv1.Deployment {
 	defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+	// signing key secrets created by older versions have no bundle, the single public key is used instead
+	signingKeyOptional := true
 	deployment := utils.CreateDeployment(uploadProxyResourceName, cdiLabel, uploadProxyResourceName, uploadProxyResourceName, int32(1), infraNodePlacement)
 	container := utils.CreateContainer(uploadProxyResourceName, image, verbosity, pullPolicy)
-	container.Env = []corev1.EnvVar{
-		{
-			Name: "APISERVER_PUBLIC_KEY",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: "cdi-api-signing-key",
-					},
-					Key: "id_rsa.pub",
-				},
-			},
-		},
-	}
 	container.ReadinessProbe = &corev1.Probe{
 		Handler: corev1.Handler{
 			HTTPGet: &corev1.HTTPGetAction{
@@ -116,6 +106,11 @@ func createUploadProxyDeployment(image, verbosity, pullPolicy string, infraNodeP
 		TimeoutSeconds:      1,
 	}
 	container.VolumeMounts = []corev1.VolumeMount{
+		{
+			Name:      "cdi-api-signing-key",
+			MountPath: controller.APIServerPublicKeyDir,
+			ReadOnly:  true,
+		},
 		{
 			Name:      "server-cert",
 			MountPath: "/var/run/certs/cdi-uploadproxy-server-cert",
@@ -129,6 +124,26 @@ func createUploadProxyDeployment(image, verbosity, pullPolicy string, infraNodeP
 	}
 	deployment.Spec.Template.Spec.Containers = []corev1.Container{container}
 	deployment.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{
+			Name: "cdi-api-signing-key",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: "cdi-api-signing-key",
+					Items: []corev1.KeyToPath{
+						{
+							Key:  "id_rsa.pub",
+							Path: "id_rsa.pub",
+						},
+						{
+							Key:  "id_rsa.pub.bundle",
+							Path: "id_rsa.pub.bundle",
+						},
+					},
+					DefaultMode: &defaultMode,
+					Optional:    &signingKeyOptional,
+				},
+			},
+		},
 		{
 			Name: "server-cert",
 			VolumeSource: corev1.VolumeSource{
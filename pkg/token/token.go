@@ -20,7 +20,9 @@
 package token
 
 import (
+	"crypto"
 	"crypto/rsa"
+	"encoding/base64"
 	"time"
 
 	"gopkg.in/square/go-jose.v2"
@@ -29,6 +31,7 @@ import (
 	"github.com/pkg/errors"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -37,6 +40,9 @@ const (
 
 	// OperationUpload is the type of token for uploading to a PVC
 	OperationUpload Operation = "Upload"
+
+	// OperationExport is the type of token for downloading a VolumeExport
+	OperationExport Operation = "Export"
 )
 
 // Operation is the type of the token
@@ -49,6 +55,52 @@ type Payload struct {
 	Namespace string                      `json:"namespace,omitempty"`
 	Resource  metav1.GroupVersionResource `json:"resource,omitempty"`
 	Params    map[string]string           `json:"params,omitempty"`
+	// UID binds the token to one incarnation of the named resource
+	UID types.UID `json:"uid,omitempty"`
+	// ID identifies a single-use token, it is empty for tokens that may be used repeatedly
+	ID string `json:"id,omitempty"`
+}
+
+// PublicKeySource provides the public keys tokens may be signed with: the current signing key, the next one and the
+// previous ones during the grace window of a key rotation
+type PublicKeySource interface {
+	PublicKeys() ([]*rsa.PublicKey, error)
+}
+
+// PublicKeys is a static PublicKeySource
+type PublicKeys []*rsa.PublicKey
+
+// PublicKeys returns the keys
+func (k PublicKeys) PublicKeys() ([]*rsa.PublicKey, error) {
+	return k, nil
+}
+
+// SigningKeySource provides the private key tokens are signed with, which changes when the key is rotated
+type SigningKeySource interface {
+	SigningKey() (*rsa.PrivateKey, error)
+}
+
+// StaticSigningKey returns a SigningKeySource always providing key
+func StaticSigningKey(key *rsa.PrivateKey) SigningKeySource {
+	return &staticSigningKey{key: key}
+}
+
+type staticSigningKey struct {
+	key *rsa.PrivateKey
+}
+
+func (k *staticSigningKey) SigningKey() (*rsa.PrivateKey, error) {
+	return k.key, nil
+}
+
+// KeyID returns the ID of a public key, the kid header of the tokens signed with it. It is the RFC 7638 thumbprint
+// of the key.
+func KeyID(key *rsa.PublicKey) (string, error) {
+	thumbprint, err := (&jose.JSONWebKey{Key: key}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", errors.Wrap(err, "error computing key thumbprint")
+	}
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
 }
 
 // Validator validates tokens
@@ -58,13 +110,18 @@ type Validator interface {
 
 type validator struct {
 	issuer string
-	key    *rsa.PublicKey
+	keys   PublicKeySource
 	leeway time.Duration
 }
 
 // NewValidator return a new Validator implementation
 func NewValidator(issuer string, key *rsa.PublicKey, leeway time.Duration) Validator {
-	return &validator{issuer: issuer, key: key, leeway: leeway}
+	return NewBundleValidator(issuer, PublicKeys{key}, leeway)
+}
+
+// NewBundleValidator returns a Validator accepting tokens signed with any of the keys of the source
+func NewBundleValidator(issuer string, keys PublicKeySource, leeway time.Duration) Validator {
+	return &validator{issuer: issuer, keys: keys, leeway: leeway}
 }
 
 // Validate checks the token signature and returns the contents
@@ -74,10 +131,15 @@ func (v *validator) Validate(token string) (*Payload, error) {
 		return nil, err
 	}
 
+	key, err := v.findKey(tok)
+	if err != nil {
+		return nil, err
+	}
+
 	public := &jwt.Claims{}
 	private := &Payload{}
 
-	if err = tok.Claims(v.key, public, private); err != nil {
+	if err = tok.Claims(key, public, private); err != nil {
 		return nil, err
 	}
 
@@ -93,38 +155,100 @@ func (v *validator) Validate(token string) (*Payload, error) {
 	return private, nil
 }
 
+// findKey returns the key with the kid of the token. Tokens without kid, signed before keys were rotated, are checked
+// against the current key.
+func (v *validator) findKey(tok *jwt.JSONWebToken) (*rsa.PublicKey, error) {
+	keys, err := v.keys.PublicKeys()
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting public keys")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no public key to validate the token")
+	}
+
+	var kid string
+	if len(tok.Headers) > 0 {
+		kid = tok.Headers[0].KeyID
+	}
+	if kid == "" {
+		return keys[0], nil
+	}
+
+	for _, key := range keys {
+		id, err := KeyID(key)
+		if err != nil {
+			return nil, err
+		}
+		if id == kid {
+			return key, nil
+		}
+	}
+
+	return nil, errors.Errorf("unknown signing key %q", kid)
+}
+
 // Generator generates tokens
 type Generator interface {
 	Generate(*Payload) (string, error)
+	// GenerateWithLifetime generates a token expiring after lifetime instead of the default lifetime, and returns
+	// its expiry
+	GenerateWithLifetime(*Payload, time.Duration) (string, time.Time, error)
 }
 
 type generator struct {
 	issuer   string
-	key      *rsa.PrivateKey
+	keys     SigningKeySource
 	lifetime time.Duration
 }
 
 // NewGenerator returns a new Generator
 func NewGenerator(issuer string, key *rsa.PrivateKey, lifetime time.Duration) Generator {
-	return &generator{issuer: issuer, key: key, lifetime: lifetime}
+	return NewRotatingGenerator(issuer, StaticSigningKey(key), lifetime)
+}
+
+// NewRotatingGenerator returns a new Generator signing with the current key of the source
+func NewRotatingGenerator(issuer string, keys SigningKeySource, lifetime time.Duration) Generator {
+	return &generator{issuer: issuer, keys: keys, lifetime: lifetime}
 }
 
 // Generate generates a token from the given parameters
 func (g *generator) Generate(payload *Payload) (string, error) {
-	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.PS256, Key: g.key}, nil)
+	token, _, err := g.GenerateWithLifetime(payload, g.lifetime)
+	return token, err
+}
+
+// GenerateWithLifetime generates a token from the given parameters expiring after lifetime
+func (g *generator) GenerateWithLifetime(payload *Payload, lifetime time.Duration) (string, time.Time, error) {
+	key, err := g.keys.SigningKey()
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, "error getting signing key")
+	}
+
+	kid, err := KeyID(&key.PublicKey)
 	if err != nil {
-		return "", errors.Wrap(err, "error creating JWT signer")
+		return "", time.Time{}, err
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.PS256, Key: jose.JSONWebKey{Key: key, KeyID: kid}}, nil)
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, "error creating JWT signer")
 	}
 
 	t := time.Now()
+	expiry := jwt.NewNumericDate(t.Add(lifetime))
 
-	return jwt.Signed(signer).
+	token, err := jwt.Signed(signer).
 		Claims(payload).
 		Claims(&jwt.Claims{
 			Issuer:    g.issuer,
 			IssuedAt:  jwt.NewNumericDate(t),
 			NotBefore: jwt.NewNumericDate(t),
-			Expiry:    jwt.NewNumericDate(t.Add(g.lifetime)),
+			Expiry:    expiry,
 		}).
 		CompactSerialize()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiry.Time(), nil
 }
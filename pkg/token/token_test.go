@@ -27,8 +27,11 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"kubevirt.io/containerized-data-importer/tests/reporters"
 )
@@ -77,6 +80,40 @@ var _ = Describe("Token test", func() {
 		Expect(reflect.DeepEqual(tokenData, payload)).To(BeTrue())
 	})
 
+	It("Token with lifetime", func() {
+		issuer := "issuer"
+
+		key, err := generateTestKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		tokenData := &Payload{
+			Operation: OperationUpload,
+			Name:      "fakepvc",
+			Namespace: "fakenamespace",
+			Resource: metav1.GroupVersionResource{
+				Group:    "",
+				Version:  "v1",
+				Resource: "persistentvolumeclaims",
+			},
+			UID: types.UID("fakeuid"),
+			ID:  "fakeid",
+		}
+
+		g := NewGenerator(issuer, key, 5*time.Minute)
+
+		start := time.Now().Truncate(time.Second)
+		signedToken, expiry, err := g.GenerateWithLifetime(tokenData, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expiry).To(BeTemporally(">=", start.Add(time.Hour)))
+		Expect(expiry).To(BeTemporally("<=", time.Now().Add(time.Hour)))
+
+		validator := NewValidator(issuer, &key.PublicKey, 0)
+
+		payload, err := validator.Validate(signedToken)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reflect.DeepEqual(tokenData, payload)).To(BeTrue())
+	})
+
 	It("Token timeout", func() {
 		issuer := "issuer"
 
@@ -165,4 +202,99 @@ var _ = Describe("Token test", func() {
 		_, err = validator.Validate(signedToken)
 		Expect(err).To(HaveOccurred())
 	})
+
+	Describe("Key rotation", func() {
+		var (
+			tokenData *Payload
+			current   *rsa.PrivateKey
+			previous  *rsa.PrivateKey
+		)
+
+		BeforeEach(func() {
+			var err error
+			tokenData = &Payload{
+				Operation: OperationUpload,
+				Name:      "fakepvc",
+				Namespace: "fakenamespace",
+				Resource: metav1.GroupVersionResource{
+					Group:    "",
+					Version:  "v1",
+					Resource: "persistentvolumeclaims",
+				},
+			}
+			current, err = generateTestKey()
+			Expect(err).ToNot(HaveOccurred())
+			previous, err = generateTestKey()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Should set the key ID header", func() {
+			signedToken, err := NewGenerator("issuer", current, 5*time.Minute).Generate(tokenData)
+			Expect(err).ToNot(HaveOccurred())
+			tok, err := jwt.ParseSigned(signedToken)
+			Expect(err).ToNot(HaveOccurred())
+			kid, err := KeyID(&current.PublicKey)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tok.Headers[0].KeyID).To(Equal(kid))
+		})
+
+		It("Should accept tokens signed with any key of the bundle", func() {
+			validator := NewBundleValidator("issuer", PublicKeys{&current.PublicKey, &previous.PublicKey}, 0)
+			for _, key := range []*rsa.PrivateKey{current, previous} {
+				signedToken, err := NewGenerator("issuer", key, 5*time.Minute).Generate(tokenData)
+				Expect(err).ToNot(HaveOccurred())
+				payload, err := validator.Validate(signedToken)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reflect.DeepEqual(tokenData, payload)).To(BeTrue())
+			}
+		})
+
+		It("Should reject tokens signed with a key out of the bundle", func() {
+			signedToken, err := NewGenerator("issuer", previous, 5*time.Minute).Generate(tokenData)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = NewBundleValidator("issuer", PublicKeys{&current.PublicKey}, 0).Validate(signedToken)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unknown signing key"))
+		})
+
+		It("Should validate tokens without key ID with the current key", func() {
+			signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.PS256, Key: current}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			signedToken, err := jwt.Signed(signer).
+				Claims(tokenData).
+				Claims(&jwt.Claims{Issuer: "issuer", Expiry: jwt.NewNumericDate(time.Now().Add(time.Minute))}).
+				CompactSerialize()
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = NewBundleValidator("issuer", PublicKeys{&current.PublicKey, &previous.PublicKey}, 0).Validate(signedToken)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = NewBundleValidator("issuer", PublicKeys{&previous.PublicKey, &current.PublicKey}, 0).Validate(signedToken)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should sign with the current key of the source", func() {
+			source := &testSigningKeySource{key: previous}
+			generator := NewRotatingGenerator("issuer", source, 5*time.Minute)
+			validator := NewBundleValidator("issuer", PublicKeys{&current.PublicKey}, 0)
+
+			signedToken, err := generator.Generate(tokenData)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = validator.Validate(signedToken)
+			Expect(err).To(HaveOccurred())
+
+			source.key = current
+			signedToken, err = generator.Generate(tokenData)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = validator.Validate(signedToken)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
 })
+
+type testSigningKeySource struct {
+	key *rsa.PrivateKey
+}
+
+func (s *testSigningKeySource) SigningKey() (*rsa.PrivateKey, error) {
+	return s.key, nil
+}
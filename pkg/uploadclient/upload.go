@@ -0,0 +1,287 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uploadclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+)
+
+// retryableError is an error of a request that may succeed when it is sent again
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// upload is the state of the upload of an image
+type upload struct {
+	client    *Client
+	namespace string
+	name      string
+	image     io.ReaderAt
+	size      int64
+	checksum  string
+	token     *uploadToken
+	offset    int64
+	// complete is set when the DataVolume succeeded while requests were failing
+	complete bool
+}
+
+// Upload sends the image to the DataVolume, which must be ready for the upload, in chunks. The upload resumes at the
+// offset the upload server already received, so an interrupted upload can be continued. checksum is sent in the
+// checksum header, if not empty.
+func (c *Client) Upload(ctx context.Context, namespace, name string, image io.ReaderAt, size int64, checksum string) error {
+	if size <= 0 {
+		return errors.New("the image is empty")
+	}
+
+	u := &upload{client: c, namespace: namespace, name: name, image: image, size: size, checksum: checksum}
+	if err := u.retry(ctx, u.readOffset); err != nil {
+		return err
+	}
+	if u.complete {
+		c.printf("Upload to %s/%s already complete\n", namespace, name)
+		return nil
+	}
+	if u.offset > 0 {
+		c.printf("Resuming upload at %d bytes\n", u.offset)
+	}
+
+	for {
+		done, err := u.sendChunkWithRetries(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			c.printf("Uploaded %d bytes to %s/%s\n", size, namespace, name)
+			return nil
+		}
+	}
+}
+
+// sendChunkWithRetries sends the chunk at the current offset, and returns true once the upload is complete
+func (u *upload) sendChunkWithRetries(ctx context.Context) (bool, error) {
+	done := false
+	err := u.retry(ctx, func(ctx context.Context) error {
+		var err error
+		done, err = u.sendChunk(ctx)
+		return err
+	})
+	return done || u.complete, err
+}
+
+// retry calls f until it succeeds, fails with an error that is not retryable or all the retries failed. The offset
+// is read again before each retry, as a failed chunk may have been partially received.
+func (u *upload) retry(ctx context.Context, f func(context.Context) error) error {
+	interval := u.client.options.RetryInterval
+	for attempt := 0; ; attempt++ {
+		err := f(ctx)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(*retryableError); !ok || attempt >= u.client.options.Retries {
+			return err
+		}
+
+		if succeeded, _ := u.dataVolumeSucceeded(ctx); succeeded {
+			// the response to the last chunk was lost
+			u.complete = true
+			return nil
+		}
+
+		u.client.printf("Retrying in %s: %v\n", interval, err)
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "upload canceled")
+		case <-time.After(interval):
+		}
+		interval *= 2
+
+		if err := u.readOffset(ctx); err != nil {
+			if _, ok := err.(*retryableError); !ok {
+				return err
+			}
+		}
+	}
+}
+
+// readOffset reads the offset the upload server already received
+func (u *upload) readOffset(ctx context.Context) error {
+	resp, err := u.do(ctx, http.MethodHead, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	offset, err := strconv.ParseInt(resp.Header.Get(common.UploadOffsetHeader), 10, 64)
+	if err != nil {
+		return errors.Errorf("invalid %s header in the response", common.UploadOffsetHeader)
+	}
+	u.offset = offset
+	return nil
+}
+
+// sendChunk sends the chunk at the current offset, an empty chunk once all the data was received so the processing
+// of the data is retried
+func (u *upload) sendChunk(ctx context.Context) (bool, error) {
+	length := u.client.options.ChunkSize
+	if remaining := u.size - u.offset; remaining < length {
+		length = remaining
+	}
+	headers := map[string]string{
+		common.UploadOffsetHeader: strconv.FormatInt(u.offset, 10),
+		common.UploadLengthHeader: strconv.FormatInt(u.size, 10),
+	}
+	if u.checksum != "" {
+		headers[common.UploadChecksumHeader] = u.checksum
+	}
+
+	var body io.Reader
+	if length > 0 {
+		body = io.NewSectionReader(u.image, u.offset, length)
+	}
+	resp, err := u.do(ctx, http.MethodPatch, body, length, headers)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		offset, err := strconv.ParseInt(resp.Header.Get(common.UploadOffsetHeader), 10, 64)
+		if err != nil {
+			return false, errors.Errorf("invalid %s header in the response", common.UploadOffsetHeader)
+		}
+		u.offset = offset
+		u.client.printProgress(u.offset, u.size)
+		return u.offset >= u.size, nil
+	case http.StatusConflict:
+		offsetHeader := resp.Header.Get(common.UploadOffsetHeader)
+		if offsetHeader == "" {
+			// the upload server is done with the upload
+			return true, nil
+		}
+		offset, err := strconv.ParseInt(offsetHeader, 10, 64)
+		if err != nil {
+			return false, errors.Errorf("invalid %s header in the response", common.UploadOffsetHeader)
+		}
+		u.offset = offset
+		return false, nil
+	default:
+		return false, responseError(resp)
+	}
+}
+
+// do sends a request to the chunked upload path of the upload proxy, with a token refreshed if it expires soon or was
+// rejected
+func (u *upload) do(ctx context.Context, method string, body io.Reader, length int64, headers map[string]string) (*http.Response, error) {
+	if u.token == nil || u.token.expiring() {
+		token, err := u.client.requestToken(ctx, u.namespace, u.name)
+		if err != nil {
+			return nil, err
+		}
+		u.token = token
+	}
+
+	req, err := http.NewRequest(method, u.client.proxyURL+common.UploadPathChunked, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the upload request")
+	}
+	req = req.WithContext(ctx)
+	req.ContentLength = length
+	req.Header.Set("Authorization", "Bearer "+u.token.token)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := u.client.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, errors.Wrap(ctx.Err(), "upload canceled")
+		}
+		return nil, &retryableError{errors.Wrap(err, "upload request failed")}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		// the token expired or was revoked, the next attempt requests a new one
+		u.token = nil
+		return nil, &retryableError{errors.New("upload token rejected")}
+	}
+	return resp, nil
+}
+
+// dataVolumeSucceeded returns true if the upload to the DataVolume is complete
+func (u *upload) dataVolumeSucceeded(ctx context.Context) (bool, error) {
+	dv, err := u.client.cdiClient.CdiV1beta1().DataVolumes(u.namespace).Get(ctx, u.name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return dv.Status.Phase == cdiv1.Succeeded, nil
+}
+
+// responseError returns the error of an unexpected response, server errors are retryable
+func responseError(resp *http.Response) error {
+	message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	err := errors.Errorf("upload failed with status %d", resp.StatusCode)
+	if len(message) > 0 {
+		err = errors.Errorf("upload failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return &retryableError{err}
+	}
+	return err
+}
+
+func (c *Client) printProgress(received, size int64) {
+	c.printf("%s\n", formatProgress(received, size))
+}
+
+// formatProgress returns the progress of the upload, like "64.0 MiB / 1.0 GiB (6%)"
+func formatProgress(received, size int64) string {
+	return fmt.Sprintf("%s / %s (%d%%)", formatBytes(received), formatBytes(size), received*100/size)
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTP"[exp])
+}
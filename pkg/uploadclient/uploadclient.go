@@ -0,0 +1,270 @@
+/*
+Copyright 2021 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uploadclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	uploadv1 "kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1"
+	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+)
+
+const (
+	defaultChunkSize     = 64 * 1024 * 1024
+	defaultRetries       = 5
+	defaultRetryInterval = 2 * time.Second
+	defaultPollInterval  = 2 * time.Second
+
+	// a token is refreshed when it expires in less than this, so it does not expire while a chunk is sent
+	tokenRefreshMargin = 30 * time.Second
+)
+
+// Options contains the parameters of the uploads, the zero value of a field selects its default
+type Options struct {
+	// ChunkSize is the size of the chunks the image is sent in, 64MiB by default
+	ChunkSize int64
+	// Retries is how many times a request failing with a network or server error is retried, 5 by default
+	Retries int
+	// RetryInterval is the wait before the first retry, it doubles for each following one. 2 seconds by default
+	RetryInterval time.Duration
+	// PollInterval is how often the DataVolume is checked while waiting for its phase, 2 seconds by default
+	PollInterval time.Duration
+	// TokenExpirationSeconds is the lifetime requested for the upload tokens, the API server default if not set
+	TokenExpirationSeconds int64
+	// Progress receives the progress output, none is written if nil
+	Progress io.Writer
+}
+
+// Client uploads disk images to DataVolumes through the upload proxy
+type Client struct {
+	cdiClient  cdiclient.Interface
+	httpClient *http.Client
+	proxyURL   string
+	options    Options
+}
+
+// uploadToken is an upload token and the time it expires
+type uploadToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// NewClient returns a Client creating DataVolumes and requesting tokens with cdiClient, and sending the images to the
+// upload proxy at proxyURL with httpClient
+func NewClient(cdiClient cdiclient.Interface, httpClient *http.Client, proxyURL string, options Options) *Client {
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = defaultChunkSize
+	}
+	if options.Retries <= 0 {
+		options.Retries = defaultRetries
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = defaultRetryInterval
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultPollInterval
+	}
+	return &Client{
+		cdiClient:  cdiClient,
+		httpClient: httpClient,
+		proxyURL:   strings.TrimSuffix(proxyURL, "/"),
+		options:    options,
+	}
+}
+
+// NewUploadDataVolume returns an upload DataVolume of the given size, checksum is the checksum the uploaded data must
+// match, if not empty
+func NewUploadDataVolume(namespace, name string, size resource.Quantity, storageClass, checksum string) *cdiv1.DataVolume {
+	dv := &cdiv1.DataVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: cdiv1.DataVolumeSpec{
+			Source: cdiv1.DataVolumeSource{
+				Upload: &cdiv1.DataVolumeSourceUpload{
+					Checksum: checksum,
+				},
+			},
+			PVC: &corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: size,
+					},
+				},
+			},
+		},
+	}
+	if storageClass != "" {
+		dv.Spec.PVC.StorageClassName = &storageClass
+	}
+	return dv
+}
+
+// UploadImage creates the DataVolume unless it exists, waits for it to be ready for the upload and sends the image
+func (c *Client) UploadImage(ctx context.Context, dv *cdiv1.DataVolume, image io.ReaderAt, size int64, checksum string) error {
+	if _, err := c.EnsureDataVolume(ctx, dv); err != nil {
+		return err
+	}
+	if err := c.WaitForUploadReady(ctx, dv.Namespace, dv.Name); err != nil {
+		return err
+	}
+	return c.Upload(ctx, dv.Namespace, dv.Name, image, size, checksum)
+}
+
+// EnsureDataVolume creates the DataVolume, or returns the existing one if it is an upload DataVolume
+func (c *Client) EnsureDataVolume(ctx context.Context, dv *cdiv1.DataVolume) (*cdiv1.DataVolume, error) {
+	created, err := c.cdiClient.CdiV1beta1().DataVolumes(dv.Namespace).Create(ctx, dv, metav1.CreateOptions{})
+	if err == nil {
+		c.printf("Created DataVolume %s/%s\n", dv.Namespace, dv.Name)
+		return created, nil
+	}
+	if !k8serrors.IsAlreadyExists(err) {
+		return nil, errors.Wrapf(err, "unable to create DataVolume %s/%s", dv.Namespace, dv.Name)
+	}
+
+	existing, err := c.cdiClient.CdiV1beta1().DataVolumes(dv.Namespace).Get(ctx, dv.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get DataVolume %s/%s", dv.Namespace, dv.Name)
+	}
+	if existing.Spec.Source.Upload == nil {
+		return nil, errors.Errorf("DataVolume %s/%s is not an upload DataVolume", dv.Namespace, dv.Name)
+	}
+	c.printf("Using existing DataVolume %s/%s\n", dv.Namespace, dv.Name)
+	return existing, nil
+}
+
+// WaitForUploadReady waits until the upload server of the DataVolume accepts uploads
+func (c *Client) WaitForUploadReady(ctx context.Context, namespace, name string) error {
+	return c.waitForPhase(ctx, namespace, name, cdiv1.UploadReady)
+}
+
+// WaitForSucceeded waits until the uploaded data of the DataVolume is processed
+func (c *Client) WaitForSucceeded(ctx context.Context, namespace, name string) error {
+	return c.waitForPhase(ctx, namespace, name, cdiv1.Succeeded)
+}
+
+func (c *Client) waitForPhase(ctx context.Context, namespace, name string, phase cdiv1.DataVolumePhase) error {
+	var lastPhase cdiv1.DataVolumePhase
+	err := wait.PollImmediateUntil(c.options.PollInterval, func() (bool, error) {
+		dv, err := c.cdiClient.CdiV1beta1().DataVolumes(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to get DataVolume %s/%s", namespace, name)
+		}
+		if dv.Status.Phase != lastPhase {
+			lastPhase = dv.Status.Phase
+			c.printf("DataVolume %s/%s phase %s\n", namespace, name, dv.Status.Phase)
+		}
+		switch dv.Status.Phase {
+		case phase:
+			return true, nil
+		case cdiv1.Failed:
+			return false, errors.Errorf("DataVolume %s/%s failed%s", namespace, name, conditionMessages(dv))
+		case cdiv1.Succeeded:
+			return false, errors.Errorf("DataVolume %s/%s already succeeded", namespace, name)
+		}
+		return false, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("stopped waiting for DataVolume %s/%s to be %s, it is %s", namespace, name, phase, lastPhase)
+	}
+	return err
+}
+
+// conditionMessages returns the messages of the conditions of the DataVolume, to explain its phase
+func conditionMessages(dv *cdiv1.DataVolume) string {
+	var messages []string
+	for _, condition := range dv.Status.Conditions {
+		if condition.Message != "" {
+			messages = append(messages, condition.Message)
+		}
+	}
+	if len(messages) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(messages, ", ")
+}
+
+// requestToken requests a token to upload to the PVC of the DataVolume
+func (c *Client) requestToken(ctx context.Context, namespace, name string) (*uploadToken, error) {
+	request := &uploadv1.UploadTokenRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: uploadv1.UploadTokenRequestSpec{
+			PvcName: name,
+		},
+	}
+	if c.options.TokenExpirationSeconds > 0 {
+		request.Spec.ExpirationSeconds = &c.options.TokenExpirationSeconds
+	}
+
+	requested := time.Now()
+	response, err := c.cdiClient.UploadV1beta1().UploadTokenRequests(namespace).Create(ctx, request, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to request an upload token for %s/%s", namespace, name)
+	}
+	if response.Status.Token == "" {
+		return nil, errors.Errorf("no upload token returned for %s/%s", namespace, name)
+	}
+
+	token := &uploadToken{token: response.Status.Token}
+	if response.Status.ExpirationTimestamp != nil {
+		token.expiresAt = response.Status.ExpirationTimestamp.Time
+	} else {
+		token.expiresAt = requested.Add(common.DefaultUploadTokenExpirationSeconds * time.Second)
+	}
+	return token, nil
+}
+
+// expiring returns true if the token must be refreshed before it is used
+func (t *uploadToken) expiring() bool {
+	return time.Now().Add(tokenRefreshMargin).After(t.expiresAt)
+}
+
+// Checksum returns the SHA-256 checksum of the image, in the format of DataVolume checksums
+func Checksum(image io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, image); err != nil {
+		return "", errors.Wrap(err, "unable to compute the image checksum")
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Client) printf(format string, args ...interface{}) {
+	if c.options.Progress != nil {
+		fmt.Fprintf(c.options.Progress, format, args...)
+	}
+}
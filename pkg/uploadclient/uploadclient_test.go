@@ -0,0 +1,292 @@
+package uploadclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1beta1"
+	uploadv1 "kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1"
+	cdiclientfake "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned/fake"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+)
+
+const (
+	dropConnection = -1
+	testChecksum   = "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+)
+
+// fakeProxy stands in for the upload proxy and the upload server of a chunked upload
+type fakeProxy struct {
+	mutex sync.Mutex
+	data  []byte
+	done  bool
+	// failures are the statuses the next PATCH requests fail with after receiving half of their chunk
+	failures []int
+	// revoked are the tokens rejected
+	revoked map[string]bool
+	// tokens are the tokens of the requests
+	tokens []string
+	// checksums are the checksum headers of the PATCH requests
+	checksums []string
+}
+
+func (p *fakeProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	Expect(r.URL.Path).To(Equal(common.UploadPathChunked))
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	p.tokens = append(p.tokens, token)
+	if token == "" || p.revoked[token] {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		w.Header().Set(common.UploadOffsetHeader, strconv.Itoa(len(p.data)))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPatch:
+		p.checksums = append(p.checksums, r.Header.Get(common.UploadChecksumHeader))
+		if p.done {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		offset, err := strconv.Atoi(r.Header.Get(common.UploadOffsetHeader))
+		Expect(err).ToNot(HaveOccurred())
+		if offset != len(p.data) {
+			w.Header().Set(common.UploadOffsetHeader, strconv.Itoa(len(p.data)))
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		length, err := strconv.Atoi(r.Header.Get(common.UploadLengthHeader))
+		Expect(err).ToNot(HaveOccurred())
+		chunk, err := ioutil.ReadAll(r.Body)
+		Expect(err).ToNot(HaveOccurred())
+
+		if len(p.failures) > 0 {
+			status := p.failures[0]
+			p.failures = p.failures[1:]
+			p.data = append(p.data, chunk[:len(chunk)/2]...)
+			if status == dropConnection {
+				panic(http.ErrAbortHandler)
+			}
+			w.WriteHeader(status)
+			w.Write([]byte("chunk failed"))
+			return
+		}
+
+		p.data = append(p.data, chunk...)
+		p.done = len(p.data) == length
+		w.Header().Set(common.UploadOffsetHeader, strconv.Itoa(len(p.data)))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newUploadDataVolume(phase cdiv1.DataVolumePhase) *cdiv1.DataVolume {
+	dv := NewUploadDataVolume("default", "upload-dv", resource.MustParse("1Gi"), "", "")
+	dv.Status.Phase = phase
+	return dv
+}
+
+var _ = Describe("Upload client", func() {
+	var (
+		proxy       *fakeProxy
+		server      *httptest.Server
+		cdiClient   *cdiclientfake.Clientset
+		tokenExpiry time.Duration
+		progress    *bytes.Buffer
+		image       []byte
+	)
+
+	newClient := func(objects ...runtime.Object) *Client {
+		cdiClient = cdiclientfake.NewSimpleClientset(objects...)
+		requested := 0
+		cdiClient.PrependReactor("create", "uploadtokenrequests", func(action core.Action) (bool, runtime.Object, error) {
+			request := action.(core.CreateAction).GetObject().(*uploadv1.UploadTokenRequest)
+			Expect(request.Spec.PvcName).To(Equal("upload-dv"))
+			requested++
+			request.Status.Token = fmt.Sprintf("token-%d", requested)
+			request.Status.ExpirationTimestamp = &metav1.Time{Time: time.Now().Add(tokenExpiry)}
+			return true, request, nil
+		})
+		return NewClient(cdiClient, server.Client(), server.URL+"/", Options{
+			ChunkSize:     4,
+			Retries:       3,
+			RetryInterval: time.Millisecond,
+			PollInterval:  time.Millisecond,
+			Progress:      progress,
+		})
+	}
+
+	BeforeEach(func() {
+		proxy = &fakeProxy{revoked: map[string]bool{}}
+		server = httptest.NewServer(proxy)
+		tokenExpiry = 5 * time.Minute
+		progress = &bytes.Buffer{}
+		image = []byte("hello world")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should upload the image in chunks with the checksum header", func() {
+		client := newClient(newUploadDataVolume(cdiv1.UploadReady))
+		Expect(client.Upload(context.Background(), "default", "upload-dv", bytes.NewReader(image), int64(len(image)), testChecksum)).To(Succeed())
+		proxy.mutex.Lock()
+		defer proxy.mutex.Unlock()
+		Expect(proxy.data).To(Equal(image))
+		Expect(proxy.done).To(BeTrue())
+		Expect(proxy.checksums).To(Equal([]string{testChecksum, testChecksum, testChecksum}))
+		Expect(proxy.tokens).To(ConsistOf("token-1", "token-1", "token-1", "token-1"))
+		Expect(progress.String()).To(ContainSubstring("4 B / 11 B (36%)"))
+		Expect(progress.String()).To(ContainSubstring("11 B / 11 B (100%)"))
+	})
+
+	It("Should resume at the offset already received", func() {
+		proxy.data = []byte("hello")
+		client := newClient(newUploadDataVolume(cdiv1.UploadReady))
+		Expect(client.Upload(context.Background(), "default", "upload-dv", bytes.NewReader(image), int64(len(image)), "")).To(Succeed())
+		proxy.mutex.Lock()
+		defer proxy.mutex.Unlock()
+		Expect(proxy.data).To(Equal(image))
+		Expect(proxy.checksums).To(Equal([]string{"", ""}))
+		Expect(progress.String()).To(ContainSubstring("Resuming upload at 5 bytes"))
+	})
+
+	It("Should retry chunks failing with server errors or dropped connections", func() {
+		proxy.failures = []int{http.StatusServiceUnavailable, dropConnection}
+		client := newClient(newUploadDataVolume(cdiv1.UploadReady))
+		Expect(client.Upload(context.Background(), "default", "upload-dv", bytes.NewReader(image), int64(len(image)), "")).To(Succeed())
+		proxy.mutex.Lock()
+		defer proxy.mutex.Unlock()
+		Expect(proxy.data).To(Equal(image))
+		Expect(progress.String()).To(ContainSubstring("Retrying"))
+	})
+
+	It("Should give up after the retries", func() {
+		proxy.failures = []int{500, 500, 500, 500}
+		client := newClient(newUploadDataVolume(cdiv1.UploadReady))
+		err := client.Upload(context.Background(), "default", "upload-dv", bytes.NewReader(image), int64(len(image)), "")
+		proxy.mutex.Lock()
+		defer proxy.mutex.Unlock()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("status 500: chunk failed"))
+	})
+
+	It("Should not retry rejected chunks", func() {
+		proxy.failures = []int{http.StatusBadRequest}
+		client := newClient(newUploadDataVolume(cdiv1.UploadReady))
+		err := client.Upload(context.Background(), "default", "upload-dv", bytes.NewReader(image), int64(len(image)), "")
+		proxy.mutex.Lock()
+		defer proxy.mutex.Unlock()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("status 400"))
+		Expect(proxy.checksums).To(HaveLen(1))
+	})
+
+	It("Should request a new token when the token is rejected", func() {
+		proxy.revoked["token-1"] = true
+		client := newClient(newUploadDataVolume(cdiv1.UploadReady))
+		Expect(client.Upload(context.Background(), "default", "upload-dv", bytes.NewReader(image), int64(len(image)), "")).To(Succeed())
+		proxy.mutex.Lock()
+		defer proxy.mutex.Unlock()
+		Expect(proxy.data).To(Equal(image))
+		Expect(proxy.tokens[0]).To(Equal("token-1"))
+		for _, token := range proxy.tokens[1:] {
+			Expect(token).To(Equal("token-2"))
+		}
+	})
+
+	It("Should request a new token before the token expires", func() {
+		tokenExpiry = tokenRefreshMargin
+		client := newClient(newUploadDataVolume(cdiv1.UploadReady))
+		Expect(client.Upload(context.Background(), "default", "upload-dv", bytes.NewReader(image), int64(len(image)), "")).To(Succeed())
+		proxy.mutex.Lock()
+		defer proxy.mutex.Unlock()
+		Expect(proxy.tokens).To(Equal([]string{"token-1", "token-2", "token-3", "token-4"}))
+	})
+
+	It("Should stop retrying when the DataVolume succeeded", func() {
+		proxy.failures = []int{dropConnection}
+		client := newClient(newUploadDataVolume(cdiv1.Succeeded))
+		Expect(client.Upload(context.Background(), "default", "upload-dv", bytes.NewReader(image), int64(len(image)), "")).To(Succeed())
+		proxy.mutex.Lock()
+		defer proxy.mutex.Unlock()
+		Expect(proxy.checksums).To(HaveLen(1))
+	})
+
+	It("Should create the DataVolume and wait for it to be ready", func() {
+		client := newClient()
+		cdiClient.PrependReactor("get", "datavolumes", func(action core.Action) (bool, runtime.Object, error) {
+			return true, newUploadDataVolume(cdiv1.UploadReady), nil
+		})
+		dv := NewUploadDataVolume("default", "upload-dv", resource.MustParse("1Gi"), "local", testChecksum)
+		Expect(client.UploadImage(context.Background(), dv, bytes.NewReader(image), int64(len(image)), testChecksum)).To(Succeed())
+		proxy.mutex.Lock()
+		defer proxy.mutex.Unlock()
+		Expect(proxy.data).To(Equal(image))
+
+		created := cdiClient.Actions()[0].(core.CreateAction).GetObject().(*cdiv1.DataVolume)
+		Expect(created.Spec.Source.Upload.Checksum).To(Equal(testChecksum))
+		Expect(*created.Spec.PVC.StorageClassName).To(Equal("local"))
+		Expect(progress.String()).To(ContainSubstring("Created DataVolume default/upload-dv"))
+	})
+
+	It("Should use an existing upload DataVolume", func() {
+		client := newClient(newUploadDataVolume(cdiv1.UploadScheduled))
+		dv, err := client.EnsureDataVolume(context.Background(), newUploadDataVolume(""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dv.Status.Phase).To(Equal(cdiv1.UploadScheduled))
+	})
+
+	It("Should not use an existing DataVolume of another source", func() {
+		dv := newUploadDataVolume(cdiv1.Succeeded)
+		dv.Spec.Source = cdiv1.DataVolumeSource{HTTP: &cdiv1.DataVolumeSourceHTTP{URL: "http://example.com/disk.img"}}
+		client := newClient(dv)
+		_, err := client.EnsureDataVolume(context.Background(), newUploadDataVolume(""))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is not an upload DataVolume"))
+	})
+
+	It("Should fail waiting for a failed DataVolume", func() {
+		dv := newUploadDataVolume(cdiv1.Failed)
+		dv.Status.Conditions = []cdiv1.DataVolumeCondition{{Message: "Upload server pod failed"}}
+		client := newClient(dv)
+		err := client.WaitForUploadReady(context.Background(), "default", "upload-dv")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed: Upload server pod failed"))
+	})
+
+	It("Should stop waiting when the context is canceled", func() {
+		client := newClient(newUploadDataVolume(cdiv1.Pending))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := client.WaitForUploadReady(ctx, "default", "upload-dv")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("to be UploadReady, it is Pending"))
+	})
+
+	It("Should compute the SHA-256 checksum of the image", func() {
+		Expect(Checksum(strings.NewReader("hello"))).To(Equal(testChecksum))
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
@@ -25,6 +27,7 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/controller"
 	"kubevirt.io/containerized-data-importer/pkg/token"
+	"kubevirt.io/containerized-data-importer/pkg/util"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/fetcher"
 )
 
@@ -37,8 +40,14 @@ const (
 	proxyRequestTimeout = 24 * time.Hour
 
 	uploadTokenLeeway = 10 * time.Second
+
+	// the processing phases the upload server reports once the upload is over
+	uploadPhaseComplete = "Complete"
+	uploadPhaseError    = "Error"
 )
 
+var errTokenRevoked = errors.New("the token was issued for a PVC that no longer exists")
+
 // Server is the public interface to the upload proxy
 type Server interface {
 	Start() error
@@ -72,8 +81,9 @@ type uploadProxyApp struct {
 	handler http.Handler
 
 	// test hooks
-	urlResolver    urlLookupFunc
-	uploadPossible uploadPossibleFunc
+	urlResolver       urlLookupFunc
+	uploadPossible    uploadPossibleFunc
+	exportURLResolver urlLookupFunc
 }
 
 type clientCreator struct {
@@ -86,23 +96,24 @@ var authHeaderMatcher = regexp.MustCompile(`(?i)^Bearer\s+([A-Za-z0-9\-\._~\+\/]
 // NewUploadProxy returns an initialized uploadProxyApp
 func NewUploadProxy(bindAddress string,
 	bindPort uint,
-	apiServerPublicKey string,
+	apiServerPublicKeys token.PublicKeySource,
 	certWatcher CertWatcher,
 	clientCertFetcher fetcher.CertFetcher,
 	serverCAFetcher fetcher.CertBundleFetcher,
 	client kubernetes.Interface) (Server, error) {
 	var err error
 	app := &uploadProxyApp{
-		bindAddress:    bindAddress,
-		bindPort:       bindPort,
-		certWatcher:    certWatcher,
-		clientCreator:  &clientCreator{certFetcher: clientCertFetcher, bundleFetcher: serverCAFetcher},
-		client:         client,
-		urlResolver:    controller.GetUploadServerURL,
-		uploadPossible: controller.UploadPossibleForPVC,
+		bindAddress:       bindAddress,
+		bindPort:          bindPort,
+		certWatcher:       certWatcher,
+		clientCreator:     &clientCreator{certFetcher: clientCertFetcher, bundleFetcher: serverCAFetcher},
+		client:            client,
+		urlResolver:       controller.GetUploadServerURL,
+		uploadPossible:    controller.UploadPossibleForPVC,
+		exportURLResolver: controller.GetExportServerURL,
 	}
-	// retrieve RSA key used by apiserver to sign tokens
-	err = app.getSigningKey(apiServerPublicKey)
+	// retrieve RSA keys used by apiserver to sign tokens
+	err = app.getSigningKey(apiServerPublicKeys)
 	if err != nil {
 		return nil, errors.Errorf("unable to retrieve apiserver signing key: %v", errors.WithStack(err))
 	}
@@ -154,7 +165,21 @@ func (app *uploadProxyApp) initHandler() {
 	for _, path := range common.ProxyPaths {
 		mux.HandleFunc(path, app.handleUploadRequest)
 	}
-	app.handler = cors.AllowAll().Handler(mux)
+	mux.HandleFunc(common.ExportPath, app.handleExportRequest)
+	app.handler = cors.New(cors.Options{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{
+			http.MethodHead,
+			http.MethodGet,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		},
+		AllowedHeaders: []string{"*"},
+		// browsers need to read the offset to resume chunked uploads
+		ExposedHeaders: []string{common.UploadOffsetHeader, common.UploadLengthHeader},
+	}).Handler(mux)
 }
 
 func (app *uploadProxyApp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -165,22 +190,29 @@ func (app *uploadProxyApp) handleHealthzRequest(w http.ResponseWriter, r *http.R
 	io.WriteString(w, "OK")
 }
 
-func (app *uploadProxyApp) handleUploadRequest(w http.ResponseWriter, r *http.Request) {
+// validateToken returns the payload of the bearer token of the request, or the status to reply with
+func (app *uploadProxyApp) validateToken(r *http.Request) (*token.Payload, int) {
 	tokenHeader := r.Header.Get("Authorization")
 	if tokenHeader == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, http.StatusBadRequest
 	}
 
 	match := authHeaderMatcher.FindStringSubmatch(tokenHeader)
 	if len(match) != 2 {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, http.StatusBadRequest
 	}
 
 	tokenData, err := app.tokenValidator.Validate(match[1])
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
+		return nil, http.StatusUnauthorized
+	}
+	return tokenData, http.StatusOK
+}
+
+func (app *uploadProxyApp) handleUploadRequest(w http.ResponseWriter, r *http.Request) {
+	tokenData, status := app.validateToken(r)
+	if tokenData == nil {
+		w.WriteHeader(status)
 		return
 	}
 
@@ -195,19 +227,57 @@ func (app *uploadProxyApp) handleUploadRequest(w http.ResponseWriter, r *http.Re
 
 	klog.V(1).Infof("Received valid token: pvc: %s, namespace: %s", tokenData.Name, tokenData.Namespace)
 
-	err = app.uploadReady(tokenData.Name, tokenData.Namespace)
+	if r.URL.Path == common.UploadPathStatus {
+		// the upload pod is gone once the upload is over, its outcome is recorded in the PVC
+		status, err := app.finalUploadStatus(tokenData.Name, tokenData.Namespace, tokenData.UID)
+		if errors.Is(err, errTokenRevoked) {
+			klog.Error(err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if status != nil {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(status)
+			return
+		}
+	}
+
+	err := app.uploadReady(tokenData.Name, tokenData.Namespace, tokenData.UID)
 	if err != nil {
 		klog.Error(err)
+		if errors.Is(err, errTokenRevoked) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
 		w.WriteHeader(http.StatusServiceUnavailable)
 		// Return the error to the caller in the body.
 		w.Write([]byte(err.Error()))
 		return
 	}
 
-	app.proxyUploadRequest(tokenData.Namespace, tokenData.Name, w, r)
+	app.proxyUploadRequest(tokenData.Namespace, tokenData.Name, tokenData.ID, w, r)
 }
 
-func (app *uploadProxyApp) uploadReady(pvcName, pvcNamespace string) error {
+// finalUploadStatus returns the status of an upload that is over, from the annotations of the PVC, or nil while the
+// upload pod serves the status. Errors getting the PVC are left to uploadReady.
+func (app *uploadProxyApp) finalUploadStatus(pvcName, pvcNamespace string, pvcUID types.UID) (*util.UploadStatus, error) {
+	pvc, err := app.client.CoreV1().PersistentVolumeClaims(pvcNamespace).Get(context.TODO(), pvcName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil
+	}
+	if pvcUID != "" && pvc.UID != pvcUID {
+		return nil, errors.Wrapf(errTokenRevoked, "rejecting Upload Status Request for PVC %s", pvcName)
+	}
+	switch v1.PodPhase(pvc.Annotations[controller.AnnPodPhase]) {
+	case v1.PodSucceeded:
+		return &util.UploadStatus{Phase: uploadPhaseComplete}, nil
+	case v1.PodFailed:
+		return &util.UploadStatus{Phase: uploadPhaseError, Error: pvc.Annotations[controller.AnnRunningConditionMessage]}, nil
+	}
+	return nil, nil
+}
+
+func (app *uploadProxyApp) uploadReady(pvcName, pvcNamespace string, pvcUID types.UID) error {
 	return wait.PollImmediate(waitReadyImterval, waitReadyTime, func() (bool, error) {
 		pvc, err := app.client.CoreV1().PersistentVolumeClaims(pvcNamespace).Get(context.TODO(), pvcName, metav1.GetOptions{})
 		if err != nil {
@@ -218,6 +288,11 @@ func (app *uploadProxyApp) uploadReady(pvcName, pvcNamespace string) error {
 			return false, err
 		}
 
+		// tokens issued before the PVC was deleted and recreated are not valid for the new PVC
+		if pvcUID != "" && pvc.UID != pvcUID {
+			return false, errors.Wrapf(errTokenRevoked, "rejecting Upload Request for PVC %s", pvcName)
+		}
+
 		err = app.uploadPossible(pvc)
 		if err != nil {
 			return false, err
@@ -232,7 +307,7 @@ func (app *uploadProxyApp) uploadReady(pvcName, pvcNamespace string) error {
 	})
 }
 
-func (app *uploadProxyApp) proxyUploadRequest(namespace, pvc string, w http.ResponseWriter, r *http.Request) {
+func (app *uploadProxyApp) proxyUploadRequest(namespace, pvc, tokenID string, w http.ResponseWriter, r *http.Request) {
 	client, err := app.clientCreator.CreateClient()
 	if err != nil {
 		klog.Error("Error creating http client")
@@ -243,6 +318,11 @@ func (app *uploadProxyApp) proxyUploadRequest(namespace, pvc string, w http.Resp
 	p := &httputil.ReverseProxy{
 		Director: func(req *http.Request) {
 			req.URL, _ = url.Parse(app.urlResolver(namespace, pvc, r.URL.Path))
+			// only the proxy may tell the upload server about single-use tokens
+			req.Header.Del(common.UploadTokenIDHeader)
+			if tokenID != "" {
+				req.Header.Set(common.UploadTokenIDHeader, tokenID)
+			}
 			if _, ok := req.Header["User-Agent"]; !ok {
 				// explicitly disable User-Agent so it's not set to default value
 				req.Header.Set("User-Agent", "")
@@ -254,13 +334,93 @@ func (app *uploadProxyApp) proxyUploadRequest(namespace, pvc string, w http.Resp
 	p.ServeHTTP(w, r)
 }
 
-func (app *uploadProxyApp) getSigningKey(publicKeyPEM string) error {
-	publicKey, err := controller.DecodePublicKey([]byte(publicKeyPEM))
+func (app *uploadProxyApp) handleExportRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	tokenData, status := app.validateToken(r)
+	if tokenData == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	if tokenData.Operation != token.OperationExport ||
+		tokenData.Name == "" ||
+		tokenData.Namespace == "" ||
+		tokenData.Resource.Resource != "volumeexports" {
+		klog.Errorf("Bad token %+v", tokenData)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	klog.V(1).Infof("Received valid token: volumeexport: %s, namespace: %s", tokenData.Name, tokenData.Namespace)
+
+	err := app.exportReady(tokenData.Name, tokenData.Namespace)
+	if err != nil {
+		klog.Error(err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		// Return the error to the caller in the body.
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	app.proxyExportRequest(tokenData.Namespace, tokenData.Name, w, r)
+}
+
+func (app *uploadProxyApp) exportReady(name, namespace string) error {
+	podName := controller.GetExportServerPodName(name)
+	return wait.PollImmediate(waitReadyImterval, waitReadyTime, func() (bool, error) {
+		pod, err := app.client.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return false, fmt.Errorf("rejecting Export Request for VolumeExport %s that is not started", name)
+			}
+
+			return false, err
+		}
+
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodReady {
+				return condition.Status == v1.ConditionTrue, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+func (app *uploadProxyApp) proxyExportRequest(namespace, name string, w http.ResponseWriter, r *http.Request) {
+	client, err := app.clientCreator.CreateClient()
 	if err != nil {
+		klog.Error("Error creating http client")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL, _ = url.Parse(app.exportURLResolver(namespace, name, r.URL.Path))
+			req.URL.RawQuery = r.URL.RawQuery
+			// the token is only meant for the proxy
+			req.Header.Del("Authorization")
+			if _, ok := req.Header["User-Agent"]; !ok {
+				// explicitly disable User-Agent so it's not set to default value
+				req.Header.Set("User-Agent", "")
+			}
+		},
+		Transport: client.Transport,
+	}
+
+	p.ServeHTTP(w, r)
+}
+
+func (app *uploadProxyApp) getSigningKey(publicKeys token.PublicKeySource) error {
+	if _, err := publicKeys.PublicKeys(); err != nil {
 		return err
 	}
 
-	app.tokenValidator = token.NewValidator(common.UploadTokenIssuer, publicKey, uploadTokenLeeway)
+	app.tokenValidator = token.NewBundleValidator(common.UploadTokenIssuer, publicKeys, uploadTokenLeeway)
 	return nil
 }
 
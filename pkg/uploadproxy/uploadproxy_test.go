@@ -1,6 +1,7 @@
 package uploadproxy
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"fmt"
@@ -19,6 +20,8 @@ import (
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/controller"
+	"kubevirt.io/containerized-data-importer/pkg/keys"
 	"kubevirt.io/containerized-data-importer/pkg/token"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/fetcher"
@@ -48,6 +51,29 @@ func (*validateSuccess) Validate(string) (*token.Payload, error) {
 	}, nil
 }
 
+type validateExport struct{}
+
+func (*validateExport) Validate(string) (*token.Payload, error) {
+	return &token.Payload{
+		Operation: token.OperationExport,
+		Name:      "testexport",
+		Namespace: "default",
+		Resource: metav1.GroupVersionResource{
+			Group:    "cdi.kubevirt.io",
+			Version:  "v1beta1",
+			Resource: "volumeexports",
+		},
+	}, nil
+}
+
+type validatePayload struct {
+	payload *token.Payload
+}
+
+func (v *validatePayload) Validate(string) (*token.Payload, error) {
+	return v.payload, nil
+}
+
 func (*validateFailure) Validate(string) (*token.Payload, error) {
 	return nil, fmt.Errorf("Bad token")
 }
@@ -125,14 +151,23 @@ func createApp() *uploadProxyApp {
 
 var _ = Describe("Certificate functions", func() {
 	It("Get signing key", func() {
-		publicKeyPEM := getPublicKeyEncoded()
+		publicKey, err := controller.DecodePublicKey([]byte(getPublicKeyEncoded()))
+		Expect(err).ToNot(HaveOccurred())
 		app := createApp()
 
-		err := app.getSigningKey(publicKeyPEM)
+		err = app.getSigningKey(token.PublicKeys{publicKey})
 		Expect(err).ToNot(HaveOccurred())
 		Expect(app.tokenValidator).ToNot(BeNil())
 	})
 
+	It("Should fail to get signing key without public keys", func() {
+		app := createApp()
+
+		err := app.getSigningKey(keys.NewPublicKeyBundleFile("/nonexistent/id_rsa.pub.bundle", "/nonexistent/id_rsa.pub"))
+		Expect(err).To(HaveOccurred())
+		Expect(app.tokenValidator).To(BeNil())
+	})
+
 	It("Get upload server client", func() {
 		certs := getHTTPClientConfig()
 		certFetcher := &fetcher.MemCertFetcher{Cert: certs.cert, Key: certs.key}
@@ -163,6 +198,7 @@ func setupProxyTests(handler http.HandlerFunc) *uploadProxyApp {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "testpvc",
 			Namespace: "default",
+			UID:       "testpvc-uid",
 			Annotations: map[string]string{
 				"cdi.kubevirt.io/storage.pod.phase": "Running",
 				"cdi.kubevirt.io/storage.pod.ready": "true",
@@ -219,6 +255,121 @@ var _ = Describe("submit request and check status", func() {
 		table.Entry("Test Form Async OK", common.UploadFormAsync, http.StatusOK),
 		table.Entry("Test Form Async error", common.UploadFormAsync, http.StatusInternalServerError),
 	)
+	It("Should forward status requests to the upload server", func() {
+		app := setupProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodGet))
+			w.Write([]byte(`{"bytesReceived":4}`))
+		}))
+		app.uploadPossible = func(*v1.PersistentVolumeClaim) error { return nil }
+
+		req := newProxyRequest(common.UploadPathStatus, "Bearer valid")
+		req.Method = http.MethodGet
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, req)
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Body.String()).To(Equal(`{"bytesReceived":4}`))
+	})
+	table.DescribeTable("Should report the status of an upload that is over from the PVC", func(podPhase, expected string) {
+		app := setupProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Fail("the upload pod is gone")
+		}))
+		app.uploadPossible = func(*v1.PersistentVolumeClaim) error { return nil }
+		pvc, err := app.client.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "testpvc", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		pvc.Annotations[controller.AnnPodPhase] = podPhase
+		pvc.Annotations[controller.AnnPodReady] = "false"
+		pvc.Annotations[controller.AnnRunningConditionMessage] = "image too big"
+		_, err = app.client.CoreV1().PersistentVolumeClaims("default").Update(context.TODO(), pvc, metav1.UpdateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		req := newProxyRequest(common.UploadPathStatus, "Bearer valid")
+		req.Method = http.MethodGet
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, req)
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Body.String()).To(MatchJSON(expected))
+	},
+		table.Entry("when it succeeded", string(v1.PodSucceeded), `{"bytesReceived":0,"phase":"Complete"}`),
+		table.Entry("when it failed", string(v1.PodFailed), `{"bytesReceived":0,"phase":"Error","error":"image too big"}`),
+	)
+	It("Should reject other requests once the upload succeeded", func() {
+		app := setupProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Fail("the upload pod is gone")
+		}))
+		app.uploadPossible = func(*v1.PersistentVolumeClaim) error { return nil }
+		pvc, err := app.client.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "testpvc", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		pvc.Annotations[controller.AnnPodPhase] = string(v1.PodSucceeded)
+		_, err = app.client.CoreV1().PersistentVolumeClaims("default").Update(context.TODO(), pvc, metav1.UpdateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		submitRequestAndCheckStatus(newProxyRequest(common.UploadPathSync, "Bearer valid"), http.StatusServiceUnavailable, app)
+	})
+	It("Should forward the chunks of resumable uploads to the upload server", func() {
+		app := setupProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPatch))
+			Expect(r.Header.Get(common.UploadOffsetHeader)).To(Equal("5"))
+			w.Header().Set(common.UploadOffsetHeader, "9")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		app.uploadPossible = func(*v1.PersistentVolumeClaim) error { return nil }
+		resolve := app.urlResolver
+		app.urlResolver = func(namespace, pvc, path string) string {
+			// continuations are routed to the upload pod of the PVC of the token
+			Expect(namespace).To(Equal("default"))
+			Expect(pvc).To(Equal("testpvc"))
+			Expect(path).To(Equal(common.UploadPathChunked))
+			return resolve(namespace, pvc, path)
+		}
+
+		req := newProxyRequest(common.UploadPathChunked, "Bearer valid")
+		req.Method = http.MethodPatch
+		req.Header.Set(common.UploadOffsetHeader, "5")
+		req.Header.Set("Origin", "foo.bar.com")
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, req)
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+		Expect(rr.Header().Get(common.UploadOffsetHeader)).To(Equal("9"))
+		Expect(rr.Header().Get("Access-Control-Expose-Headers")).To(ContainSubstring(common.UploadOffsetHeader))
+	})
+	It("Should pass the ID of single-use tokens to the upload server", func() {
+		app := setupProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get(common.UploadTokenIDHeader)).To(Equal("testid"))
+			w.WriteHeader(http.StatusOK)
+		}))
+		app.uploadPossible = func(*v1.PersistentVolumeClaim) error { return nil }
+		payload, _ := (&validateSuccess{}).Validate("")
+		payload.UID = "testpvc-uid"
+		payload.ID = "testid"
+		app.tokenValidator = &validatePayload{payload: payload}
+
+		req := newProxyRequest(common.UploadPathSync, "Bearer valid")
+		req.Header.Set(common.UploadTokenIDHeader, "forged")
+		submitRequestAndCheckStatus(req, http.StatusOK, app)
+	})
+	It("Should not let clients set the ID of single-use tokens", func() {
+		app := setupProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header).ToNot(HaveKey(common.UploadTokenIDHeader))
+			w.WriteHeader(http.StatusOK)
+		}))
+		app.uploadPossible = func(*v1.PersistentVolumeClaim) error { return nil }
+
+		req := newProxyRequest(common.UploadPathSync, "Bearer valid")
+		req.Header.Set(common.UploadTokenIDHeader, "forged")
+		submitRequestAndCheckStatus(req, http.StatusOK, app)
+	})
+	It("Should reject tokens issued for a deleted PVC", func() {
+		app := setupProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Fail("the request should not be proxied")
+		}))
+		app.uploadPossible = func(*v1.PersistentVolumeClaim) error { return nil }
+		payload, _ := (&validateSuccess{}).Validate("")
+		payload.UID = "deleted-uid"
+		app.tokenValidator = &validatePayload{payload: payload}
+
+		req := newProxyRequest(common.UploadPathSync, "Bearer valid")
+		submitRequestAndCheckStatus(req, http.StatusUnauthorized, app)
+	})
 	table.DescribeTable("Test head proxy status code", func(statusCode int) {
 		app := setupProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(statusCode)
@@ -265,6 +416,77 @@ var _ = Describe("submit request and check status", func() {
 		table.Entry("Test no annotation", func(*v1.PersistentVolumeClaim) error { return fmt.Errorf("NOPE") }, http.StatusBadRequest),
 	)
 
+	Context("Export", func() {
+		setupExportProxyTests := func(handler http.HandlerFunc, podReady v1.ConditionStatus) *uploadProxyApp {
+			server := httptest.NewServer(handler)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cdi-export-testexport",
+					Namespace: "default",
+				},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{
+						{Type: corev1.PodReady, Status: podReady},
+					},
+				},
+			}
+			app := createApp()
+			app.client = k8sfake.NewSimpleClientset(pod)
+			app.tokenValidator = &validateExport{}
+			app.exportURLResolver = func(namespace, name, path string) string {
+				Expect(namespace).To(Equal("default"))
+				Expect(name).To(Equal("testexport"))
+				return server.URL + path
+			}
+			app.clientCreator = &fakeClientCreator{client: server.Client()}
+			return app
+		}
+
+		It("Should proxy the download with its query and without the token", func() {
+			app := setupExportProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal(common.ExportPath))
+				Expect(r.URL.Query().Get("format")).To(Equal("gzip"))
+				Expect(r.Header.Get("Authorization")).To(BeEmpty())
+				Expect(r.Header.Get("Range")).To(Equal("bytes=0-9"))
+				w.WriteHeader(http.StatusPartialContent)
+			}), corev1.ConditionTrue)
+
+			req, err := http.NewRequest("GET", common.ExportPath+"?format=gzip", nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer valid")
+			req.Header.Set("Range", "bytes=0-9")
+			submitRequestAndCheckStatus(req, http.StatusPartialContent, app)
+		})
+
+		It("Should reject downloads while the export server is not ready", func() {
+			app := setupExportProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Fail("request should not be proxied")
+			}), corev1.ConditionFalse)
+			app.client = k8sfake.NewSimpleClientset()
+
+			req, err := http.NewRequest("GET", common.ExportPath, nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer valid")
+			submitRequestAndCheckStatus(req, http.StatusServiceUnavailable, app)
+		})
+
+		It("Should reject upload tokens", func() {
+			app := setupExportProxyTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Fail("request should not be proxied")
+			}), corev1.ConditionTrue)
+			app.tokenValidator = &validateSuccess{}
+
+			req, err := http.NewRequest("GET", common.ExportPath, nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer valid")
+			submitRequestAndCheckStatus(req, http.StatusBadRequest, app)
+		})
+
+		It("Should not accept uploads", func() {
+			submitRequestAndCheckStatus(newProxyRequest(common.ExportPath, "Bearer valid"), http.StatusMethodNotAllowed, nil)
+		})
+	})
+
 	It("Test healthz", func() {
 		req, err := http.NewRequest("GET", healthzPath, nil)
 		Expect(err).ToNot(HaveOccurred())
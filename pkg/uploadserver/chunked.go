@@ -0,0 +1,233 @@
+package uploadserver
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+)
+
+const (
+	chunkedUploadDirName   = "chunked-upload"
+	chunkedUploadDataFile  = "data"
+	chunkedUploadStateFile = "state.json"
+	usedTokenIDsFile       = "used-token-ids.json"
+)
+
+// may be overridden in tests
+var chunkedUploadDir = filepath.Join(common.ScratchDataDir, chunkedUploadDirName)
+
+var errChunkTooLarge = errors.New("chunk exceeds the upload length")
+
+// chunkedUploadState is the progress of a chunked upload, persisted in scratch space next to the received data so
+// the upload can be resumed after the upload server restarts.
+type chunkedUploadState struct {
+	// Offset is the number of bytes received and synced to the data file.
+	Offset int64 `json:"offset"`
+	// Length is the total size of the upload, 0 until the client announces it.
+	Length int64 `json:"length"`
+}
+
+// chunkedUpload stages the chunks of a resumable upload in scratch space until all of them are received.
+type chunkedUpload struct {
+	dir   string
+	mutex sync.Mutex
+	state chunkedUploadState
+}
+
+// openChunkedUpload opens the chunked upload staged in dir, or starts a new one. Data written after the last
+// persisted offset, by a request interrupted before it was synced, is dropped.
+func openChunkedUpload(dir string) (*chunkedUpload, error) {
+	if _, err := os.Stat(filepath.Dir(dir)); err != nil {
+		return nil, errors.Wrap(err, "chunked uploads require scratch space")
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, errors.Wrapf(err, "unable to create %s", dir)
+	}
+	upload := &chunkedUpload{dir: dir}
+	data, err := ioutil.ReadFile(upload.statePath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "unable to read chunked upload state")
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &upload.state); err != nil {
+			return nil, errors.Wrap(err, "unable to parse chunked upload state")
+		}
+	}
+	file, err := os.OpenFile(upload.dataPath(), os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open chunked upload data")
+	}
+	defer file.Close()
+	if err := file.Truncate(upload.state.Offset); err != nil {
+		return nil, errors.Wrap(err, "unable to truncate chunked upload data")
+	}
+	return upload, nil
+}
+
+func (c *chunkedUpload) dataPath() string {
+	return filepath.Join(c.dir, chunkedUploadDataFile)
+}
+
+func (c *chunkedUpload) statePath() string {
+	return filepath.Join(c.dir, chunkedUploadStateFile)
+}
+
+// status returns the offset and length of the upload.
+func (c *chunkedUpload) status() (int64, int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.state.Offset, c.state.Length
+}
+
+// complete returns true when all the data of the upload was received.
+func (c *chunkedUpload) complete() bool {
+	offset, length := c.status()
+	return length > 0 && offset == length
+}
+
+// setLength sets the total size of the upload, it cannot change once set.
+func (c *chunkedUpload) setLength(length int64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if length <= 0 {
+		return errors.Errorf("invalid upload length %d", length)
+	}
+	if c.state.Length != 0 {
+		if c.state.Length != length {
+			return errors.Errorf("upload length %d does not match the length %d of the upload in progress", length, c.state.Length)
+		}
+		return nil
+	}
+	state := c.state
+	state.Length = length
+	if err := c.saveState(state); err != nil {
+		return err
+	}
+	c.state = state
+	return nil
+}
+
+// append writes the data read from r after the received data and persists the new offset. The data received before
+// r fails is kept, so an interrupted chunk resumes where it stopped. A chunk exceeding the upload length is dropped.
+func (c *chunkedUpload) append(r io.Reader) error {
+	offset, length := c.status()
+	file, err := os.OpenFile(c.dataPath(), os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "unable to open chunked upload data")
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return errors.Wrap(err, "unable to seek chunked upload data")
+	}
+	n, copyErr := io.Copy(file, io.LimitReader(r, length-offset))
+	if copyErr == nil {
+		var extra [1]byte
+		if _, err := io.ReadFull(r, extra[:]); err == nil {
+			if err := file.Truncate(offset); err != nil {
+				return errors.Wrap(err, "unable to truncate chunked upload data")
+			}
+			return errChunkTooLarge
+		}
+	}
+	if err := file.Sync(); err != nil {
+		return errors.Wrap(err, "unable to sync chunked upload data")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	state := c.state
+	state.Offset += n
+	if err := c.saveState(state); err != nil {
+		return err
+	}
+	c.state = state
+	return copyErr
+}
+
+// reset drops the received data, so the upload starts over.
+func (c *chunkedUpload) reset() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := os.Truncate(c.dataPath(), 0); err != nil {
+		return errors.Wrap(err, "unable to truncate chunked upload data")
+	}
+	state := chunkedUploadState{}
+	if err := c.saveState(state); err != nil {
+		return err
+	}
+	c.state = state
+	return nil
+}
+
+// saveState atomically replaces the persisted state, must be called with the mutex held.
+func (c *chunkedUpload) saveState(state chunkedUploadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(writeFileAtomically(c.statePath(), data), "unable to write chunked upload state")
+}
+
+// usedTokenIDsPath returns the path of the IDs of the used single-use tokens, in scratch space next to the chunked
+// upload.
+func usedTokenIDsPath() string {
+	return filepath.Join(filepath.Dir(chunkedUploadDir), usedTokenIDsFile)
+}
+
+// loadUsedTokenIDs reads the IDs of the single-use tokens used before the upload server restarted. There are none
+// without scratch space.
+func loadUsedTokenIDs() (map[string]bool, error) {
+	ids := make(map[string]bool)
+	data, err := ioutil.ReadFile(usedTokenIDsPath())
+	if os.IsNotExist(err) {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the used token IDs")
+	}
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, errors.Wrap(err, "unable to parse the used token IDs")
+	}
+	return ids, nil
+}
+
+// saveUsedTokenIDs persists the IDs of the used single-use tokens, so they are still rejected after the upload server
+// restarts. Without scratch space, the upload is done in a single request and nothing needs to be persisted.
+func saveUsedTokenIDs(ids map[string]bool) error {
+	path := usedTokenIDsPath()
+	if _, err := os.Stat(filepath.Dir(path)); os.IsNotExist(err) {
+		return nil
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(writeFileAtomically(path, data), "unable to write the used token IDs")
+}
+
+// writeFileAtomically replaces the content of the file at path with data, through a synced temporary file.
+func writeFileAtomically(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,252 @@
+package uploadserver
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/importer"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func newChunkRequest(offset, length int64, body io.Reader) *http.Request {
+	req, err := http.NewRequest(http.MethodPatch, common.UploadPathChunked, body)
+	Expect(err).ToNot(HaveOccurred())
+	req.Header.Set(common.UploadOffsetHeader, strconv.FormatInt(offset, 10))
+	if length > 0 {
+		req.Header.Set(common.UploadLengthHeader, strconv.FormatInt(length, 10))
+	}
+	return req
+}
+
+func getUploadOffset(server *uploadServerApp) string {
+	req, err := http.NewRequest(http.MethodHead, common.UploadPathChunked, nil)
+	Expect(err).ToNot(HaveOccurred())
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+	Expect(rr.Code).To(Equal(http.StatusOK))
+	return rr.Header().Get(common.UploadOffsetHeader)
+}
+
+func serveChunk(server *uploadServerApp, req *http.Request) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+	return rr
+}
+
+var _ = Describe("Chunked upload", func() {
+	var (
+		tmpDir       string
+		origDir      string
+		origFunc     func(io.ReadCloser, string, string, float64, bool, string, string, importer.ProcessingObserver) (bool, error)
+		processed    []string
+		processorErr error
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "chunked-upload")
+		Expect(err).ToNot(HaveOccurred())
+		origDir = chunkedUploadDir
+		chunkedUploadDir = filepath.Join(tmpDir, chunkedUploadDirName)
+		origFunc = uploadProcessorFuncChunked
+		processed = nil
+		processorErr = nil
+		uploadProcessorFuncChunked = func(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (bool, error) {
+			defer stream.Close()
+			data, err := ioutil.ReadAll(stream)
+			Expect(err).ToNot(HaveOccurred())
+			processed = append(processed, string(data))
+			return false, processorErr
+		}
+	})
+
+	AfterEach(func() {
+		chunkedUploadDir = origDir
+		uploadProcessorFuncChunked = origFunc
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Should resume the upload after the server restarts", func() {
+		server := newServer()
+		rr := serveChunk(server, newChunkRequest(0, 10, strings.NewReader("hello")))
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+		Expect(rr.Header().Get(common.UploadOffsetHeader)).To(Equal("5"))
+		Expect(rr.Header().Get(common.UploadLengthHeader)).To(Equal("10"))
+
+		By("Rejecting a chunk at the wrong offset")
+		rr = serveChunk(server, newChunkRequest(0, 10, strings.NewReader("hello")))
+		Expect(rr.Code).To(Equal(http.StatusConflict))
+		Expect(rr.Header().Get(common.UploadOffsetHeader)).To(Equal("5"))
+
+		By("Reading the offset persisted in scratch space after a restart")
+		server = newServer()
+		Expect(getUploadOffset(server)).To(Equal("5"))
+		rr = serveChunk(server, newChunkRequest(5, 0, strings.NewReader("world")))
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+		Expect(rr.Header().Get(common.UploadOffsetHeader)).To(Equal("10"))
+		Expect(processed).To(Equal([]string{"helloworld"}))
+		Expect(server.done).To(BeTrue())
+
+		rr = serveChunk(server, newChunkRequest(10, 0, strings.NewReader("")))
+		Expect(rr.Code).To(Equal(http.StatusConflict))
+	})
+
+	It("Should keep the data received before the connection dropped", func() {
+		server := newServer()
+		rr := serveChunk(server, newChunkRequest(0, 10, io.MultiReader(strings.NewReader("hel"), failingReader{})))
+		Expect(rr.Code).To(Equal(http.StatusInternalServerError))
+		Expect(rr.Header().Get(common.UploadOffsetHeader)).To(Equal("3"))
+		Expect(server.uploading).To(BeFalse())
+
+		rr = serveChunk(server, newChunkRequest(3, 10, strings.NewReader("loworld")))
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+		Expect(processed).To(Equal([]string{"helloworld"}))
+	})
+
+	It("Should drop data written after the persisted offset", func() {
+		server := newServer()
+		rr := serveChunk(server, newChunkRequest(0, 10, strings.NewReader("hello")))
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+		file, err := os.OpenFile(filepath.Join(chunkedUploadDir, chunkedUploadDataFile), os.O_APPEND|os.O_WRONLY, 0600)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = file.WriteString("unsynced")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+
+		server = newServer()
+		rr = serveChunk(server, newChunkRequest(5, 0, strings.NewReader("world")))
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+		Expect(processed).To(Equal([]string{"helloworld"}))
+	})
+
+	It("Should reject invalid chunks", func() {
+		server := newServer()
+		req := newChunkRequest(0, 0, strings.NewReader("hello"))
+		req.Header.Del(common.UploadOffsetHeader)
+		Expect(serveChunk(server, req).Code).To(Equal(http.StatusBadRequest))
+
+		rr := serveChunk(server, newChunkRequest(0, 0, strings.NewReader("hello")))
+		Expect(rr.Code).To(Equal(http.StatusBadRequest))
+		Expect(rr.Body.String()).To(ContainSubstring("Upload-Length header required"))
+
+		rr = serveChunk(server, newChunkRequest(0, 4, strings.NewReader("hello")))
+		Expect(rr.Code).To(Equal(http.StatusBadRequest))
+		Expect(rr.Body.String()).To(ContainSubstring("chunk exceeds the upload length"))
+		Expect(rr.Header().Get(common.UploadOffsetHeader)).To(Equal("0"))
+
+		rr = serveChunk(server, newChunkRequest(0, 5, strings.NewReader("hello")))
+		Expect(rr.Code).To(Equal(http.StatusBadRequest))
+		Expect(rr.Body.String()).To(ContainSubstring("does not match the length 4"))
+		Expect(processed).To(BeEmpty())
+	})
+
+	It("Should retry the processing with an empty chunk after it failed", func() {
+		server := newServer()
+		processorErr = errors.New("Error using datastream")
+		rr := serveChunk(server, newChunkRequest(0, 5, strings.NewReader("hello")))
+		Expect(rr.Code).To(Equal(http.StatusInternalServerError))
+		Expect(rr.Header().Get(common.UploadOffsetHeader)).To(Equal("5"))
+		Expect(server.done).To(BeFalse())
+
+		processorErr = nil
+		rr = serveChunk(server, newChunkRequest(5, 0, strings.NewReader("")))
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+		Expect(processed).To(Equal([]string{"hello", "hello"}))
+		Expect(server.done).To(BeTrue())
+	})
+
+	It("Should start over when the checksum does not match", func() {
+		server := newServer()
+		_, processorErr = saveProcessorChecksumMismatch(nil, "", "", 0, false, "sha256:def", "", nil)
+		rr := serveChunk(server, newChunkRequest(0, 5, strings.NewReader("hello")))
+		Expect(rr.Code).To(Equal(http.StatusBadRequest))
+		Expect(rr.Body.String()).To(ContainSubstring("checksum"))
+		Expect(rr.Header().Get(common.UploadOffsetHeader)).To(Equal("0"))
+		Expect(getUploadOffset(server)).To(Equal("0"))
+	})
+
+	It("Should accept single-use tokens until the upload completes", func() {
+		server := newServer()
+		req := newChunkRequest(0, 10, io.MultiReader(strings.NewReader("hel"), failingReader{}))
+		req.Header.Set(common.UploadTokenIDHeader, "first")
+		Expect(serveChunk(server, req).Code).To(Equal(http.StatusInternalServerError))
+
+		By("Accepting the token again after a failed chunk")
+		req = newChunkRequest(3, 0, strings.NewReader("lo"))
+		req.Header.Set(common.UploadTokenIDHeader, "first")
+		Expect(serveChunk(server, req).Code).To(Equal(http.StatusNoContent))
+
+		By("Accepting the token again for the chunk completing the upload")
+		req = newChunkRequest(5, 0, strings.NewReader("world"))
+		req.Header.Set(common.UploadTokenIDHeader, "first")
+		Expect(serveChunk(server, req).Code).To(Equal(http.StatusNoContent))
+		Expect(processed).To(Equal([]string{"helloworld"}))
+		Expect(server.usedTokenIDs).To(HaveKey("first"))
+	})
+
+	It("Should use a single-use token for all the chunks and reject it after the server restarts", func() {
+		server := newServer()
+		req := newChunkRequest(0, 10, strings.NewReader("hello"))
+		req.Header.Set(common.UploadTokenIDHeader, "first")
+		Expect(serveChunk(server, req).Code).To(Equal(http.StatusNoContent))
+
+		By("Resuming the upload with the same token after a restart")
+		server = newServer()
+		req = newChunkRequest(5, 0, strings.NewReader("world"))
+		req.Header.Set(common.UploadTokenIDHeader, "first")
+		Expect(serveChunk(server, req).Code).To(Equal(http.StatusNoContent))
+		Expect(processed).To(Equal([]string{"helloworld"}))
+
+		By("Reading the used tokens persisted in scratch space after a restart")
+		server = newServer()
+		req = newChunkRequest(10, 0, strings.NewReader(""))
+		req.Header.Set(common.UploadTokenIDHeader, "first")
+		Expect(serveChunk(server, req).Code).To(Equal(http.StatusUnauthorized))
+		Expect(processed).To(Equal([]string{"helloworld"}))
+	})
+
+	It("Should fail if the used single-use tokens can't be read", func() {
+		Expect(ioutil.WriteFile(usedTokenIDsPath(), []byte("not json"), 0600)).To(Succeed())
+		server := newServer()
+		req := newChunkRequest(0, 10, strings.NewReader("hello"))
+		req.Header.Set(common.UploadTokenIDHeader, "first")
+		Expect(serveChunk(server, req).Code).To(Equal(http.StatusInternalServerError))
+		Expect(getUploadOffset(server)).To(Equal("0"))
+		Expect(processed).To(BeEmpty())
+	})
+
+	It("Should not accept chunks while another one is received", func() {
+		server := newServer()
+		server.uploading = true
+		Expect(serveChunk(server, newChunkRequest(0, 5, strings.NewReader("hello"))).Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("Should fail without scratch space", func() {
+		chunkedUploadDir = filepath.Join(tmpDir, "scratch", chunkedUploadDirName)
+		req, err := http.NewRequest(http.MethodHead, common.UploadPathChunked, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serveChunk(newServer(), req).Code).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("Should not process clone content types", func() {
+		_, err := newChunkedUploadStreamProcessor(ioutil.NopCloser(strings.NewReader("data")), "disk.img", "", 0.055, false, "", common.BlockdeviceClone, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not supported"))
+	})
+})
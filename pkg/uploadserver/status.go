@@ -0,0 +1,79 @@
+package uploadserver
+
+import (
+	"io"
+	"sync"
+
+	"kubevirt.io/containerized-data-importer/pkg/importer"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// uploadStatus tracks the progress of the upload reported by the status path. It observes the DataProcessor
+// processing the uploaded data.
+type uploadStatus struct {
+	mutex  sync.Mutex
+	status util.UploadStatus
+}
+
+// countingReadCloser counts the bytes read from the request body into the status.
+type countingReadCloser struct {
+	io.ReadCloser
+	status *uploadStatus
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.status.mutex.Lock()
+	r.status.status.BytesReceived += int64(n)
+	r.status.mutex.Unlock()
+	return n, err
+}
+
+// start resets the status for a new upload of the data read from stream, and returns the reader counting it.
+func (s *uploadStatus) start(stream io.ReadCloser) io.ReadCloser {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status = util.UploadStatus{}
+	return s.count(stream)
+}
+
+// count returns a reader adding the data read from stream to the bytes received.
+func (s *uploadStatus) count(stream io.ReadCloser) io.ReadCloser {
+	return &countingReadCloser{ReadCloser: stream, status: s}
+}
+
+// setReceived resets the status for a new attempt of a chunked upload, with the amount of data received so far.
+func (s *uploadStatus) setReceived(received int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status = util.UploadStatus{BytesReceived: received}
+}
+
+// failed records the error the upload failed with.
+func (s *uploadStatus) failed(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status.Phase = string(importer.ProcessingPhaseError)
+	s.status.Error = err.Error()
+}
+
+// get returns a copy of the status.
+func (s *uploadStatus) get() util.UploadStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.status
+}
+
+// PhaseChanged records the processing phase.
+func (s *uploadStatus) PhaseChanged(phase importer.ProcessingPhase) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status.Phase = string(phase)
+}
+
+// FormatDetected records the format of the uploaded data.
+func (s *uploadStatus) FormatDetected(format string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status.Format = format
+}
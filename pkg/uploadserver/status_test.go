@@ -0,0 +1,128 @@
+package uploadserver
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/importer"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+func getUploadStatus(server *uploadServerApp) util.UploadStatus {
+	req, err := http.NewRequest(http.MethodGet, common.UploadPathStatus, nil)
+	Expect(err).ToNot(HaveOccurred())
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+	Expect(rr.Code).To(Equal(http.StatusOK))
+	Expect(rr.Header().Get("Content-Type")).To(Equal("application/json"))
+	status := util.UploadStatus{}
+	Expect(json.Unmarshal(rr.Body.Bytes(), &status)).To(Succeed())
+	return status
+}
+
+func observingProcessor(processErr error) func(io.ReadCloser, string, string, float64, bool, string, string, importer.ProcessingObserver) (bool, error) {
+	return func(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (bool, error) {
+		observer.PhaseChanged(importer.ProcessingPhaseInfo)
+		_, err := ioutil.ReadAll(stream)
+		Expect(err).ToNot(HaveOccurred())
+		observer.FormatDetected("qcow2")
+		if processErr != nil {
+			observer.PhaseChanged(importer.ProcessingPhaseError)
+			return false, processErr
+		}
+		observer.PhaseChanged(importer.ProcessingPhaseComplete)
+		return false, nil
+	}
+}
+
+var _ = Describe("Upload status", func() {
+	It("Should report an upload that did not start", func() {
+		Expect(getUploadStatus(newServer())).To(Equal(util.UploadStatus{}))
+	})
+
+	It("Should report the bytes received, format and phase of a completed upload", func() {
+		replaceProcessorFunc(observingProcessor(nil), func() {
+			server := newServer()
+			req, err := http.NewRequest(http.MethodPost, common.UploadPathSync, strings.NewReader("data"))
+			Expect(err).ToNot(HaveOccurred())
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+			Expect(rr.Code).To(Equal(http.StatusOK))
+
+			Expect(getUploadStatus(server)).To(Equal(util.UploadStatus{
+				BytesReceived: 4,
+				Format:        "qcow2",
+				Phase:         string(importer.ProcessingPhaseComplete),
+			}))
+		})
+	})
+
+	It("Should report the error of a failed upload, and reset it on retry", func() {
+		replaceProcessorFunc(observingProcessor(errors.New("Virtual image size is larger than available size")), func() {
+			server := newServer()
+			req, err := http.NewRequest(http.MethodPost, common.UploadPathSync, strings.NewReader("data"))
+			Expect(err).ToNot(HaveOccurred())
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+			Expect(rr.Code).To(Equal(http.StatusInternalServerError))
+
+			Expect(getUploadStatus(server)).To(Equal(util.UploadStatus{
+				BytesReceived: 4,
+				Format:        "qcow2",
+				Phase:         string(importer.ProcessingPhaseError),
+				Error:         "Virtual image size is larger than available size",
+			}))
+
+			replaceProcessorFunc(observingProcessor(nil), func() {
+				req, err := http.NewRequest(http.MethodPost, common.UploadPathSync, strings.NewReader("retry"))
+				Expect(err).ToNot(HaveOccurred())
+				rr := httptest.NewRecorder()
+				server.ServeHTTP(rr, req)
+				Expect(rr.Code).To(Equal(http.StatusOK))
+			})
+			status := getUploadStatus(server)
+			Expect(status.BytesReceived).To(Equal(int64(5)))
+			Expect(status.Error).To(BeEmpty())
+		})
+	})
+
+	It("Should report the bytes received by a chunked upload", func() {
+		tmpDir, err := ioutil.TempDir("", "chunked-upload")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		origDir := chunkedUploadDir
+		chunkedUploadDir = filepath.Join(tmpDir, chunkedUploadDirName)
+		defer func() {
+			chunkedUploadDir = origDir
+		}()
+
+		server := newServer()
+		rr := serveChunk(server, newChunkRequest(0, 10, strings.NewReader("hello")))
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+		Expect(getUploadStatus(server)).To(Equal(util.UploadStatus{BytesReceived: 5}))
+
+		By("Reporting the data received before a restart")
+		server = newServer()
+		Expect(getUploadOffset(server)).To(Equal("5"))
+		Expect(getUploadStatus(server).BytesReceived).To(Equal(int64(5)))
+	})
+
+	It("Should only accept GET", func() {
+		req, err := http.NewRequest(http.MethodPost, common.UploadPathStatus, strings.NewReader("data"))
+		Expect(err).ToNot(HaveOccurred())
+		rr := httptest.NewRecorder()
+		newServer().ServeHTTP(rr, req)
+		Expect(rr.Code).To(Equal(http.StatusNotFound))
+	})
+})
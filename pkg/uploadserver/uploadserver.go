@@ -23,22 +23,26 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net"
 	"net/http"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 
-	"github.com/golang/snappy"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/importer"
 	"kubevirt.io/containerized-data-importer/pkg/util"
+	"kubevirt.io/containerized-data-importer/pkg/util/clonecodec"
 )
 
 const (
@@ -49,6 +53,7 @@ const (
 // UploadServer is the interface to uploadServerApp
 type UploadServer interface {
 	Run() error
+	PreallocationApplied() bool
 }
 
 type uploadServerApp struct {
@@ -63,6 +68,8 @@ type uploadServerApp struct {
 	certFile           string
 	imageSize          string
 	filesystemOverhead float64
+	preallocation      bool
+	checksum           string
 	mux                *http.ServeMux
 	uploading          bool
 	processing         bool
@@ -70,6 +77,15 @@ type uploadServerApp struct {
 	doneChan           chan struct{}
 	errChan            chan error
 	mutex              sync.Mutex
+	// chunked is the resumable upload staged in scratch space, opened by the first chunked request
+	chunked *chunkedUpload
+	// status is the progress of the upload, reported by the status path
+	status uploadStatus
+	// usedTokenIDs are the IDs of the single-use tokens of the completed uploads, persisted in scratch space
+	// and loaded by the first upload request
+	usedTokenIDs map[string]bool
+	// preallocationApplied is set when the uploaded data was written to a fully allocated target
+	preallocationApplied bool
 }
 
 type imageReadCloser func(*http.Request) (io.ReadCloser, error)
@@ -77,6 +93,7 @@ type imageReadCloser func(*http.Request) (io.ReadCloser, error)
 // may be overridden in tests
 var uploadProcessorFunc = newUploadStreamProcessor
 var uploadProcessorFuncAsync = newAsyncUploadStreamProcessor
+var uploadProcessorFuncChunked = newChunkedUploadStreamProcessor
 
 func bodyReadCloser(r *http.Request) (io.ReadCloser, error) {
 	return r.Body, nil
@@ -107,7 +124,7 @@ func formReadCloser(r *http.Request) (io.ReadCloser, error) {
 }
 
 // NewUploadServer returns a new instance of uploadServerApp
-func NewUploadServer(bindAddress string, bindPort int, destination, tlsKey, tlsCert, clientCert, clientName, imageSize string, filesystemOverhead float64) UploadServer {
+func NewUploadServer(bindAddress string, bindPort int, destination, tlsKey, tlsCert, clientCert, clientName, imageSize string, filesystemOverhead float64, preallocation bool, checksum string) UploadServer {
 	server := &uploadServerApp{
 		bindAddress:        bindAddress,
 		bindPort:           bindPort,
@@ -117,6 +134,8 @@ func NewUploadServer(bindAddress string, bindPort int, destination, tlsKey, tlsC
 		clientCert:         clientCert,
 		clientName:         clientName,
 		filesystemOverhead: filesystemOverhead,
+		preallocation:      preallocation,
+		checksum:           checksum,
 		imageSize:          imageSize,
 		mux:                http.NewServeMux(),
 		uploading:          false,
@@ -137,10 +156,21 @@ func NewUploadServer(bindAddress string, bindPort int, destination, tlsKey, tlsC
 	for _, path := range common.AsyncUploadFormPaths {
 		server.mux.HandleFunc(path, server.uploadHandlerAsync(formReadCloser))
 	}
+	for _, path := range common.ChunkedUploadPaths {
+		server.mux.HandleFunc(path, server.chunkedUploadHandler)
+	}
+	server.mux.HandleFunc(common.UploadPathStatus, server.statusHandler)
 
 	return server
 }
 
+// PreallocationApplied returns true if the uploaded data was written to a fully allocated target
+func (app *uploadServerApp) PreallocationApplied() bool {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	return app.preallocationApplied
+}
+
 func (app *uploadServerApp) Run() error {
 	uploadServer, err := app.createUploadServer()
 	if err != nil {
@@ -255,6 +285,40 @@ func (app *uploadServerApp) validateShouldHandleRequest(w http.ResponseWriter, r
 		return false
 	}
 
+	return app.validateClient(w, r) && app.validateChecksum(w, r) && app.beginUpload(w, r)
+}
+
+// validateChecksum checks the checksum header of the request is valid and does not conflict with the checksum of the
+// DataVolume
+func (app *uploadServerApp) validateChecksum(w http.ResponseWriter, r *http.Request) bool {
+	checksum := r.Header.Get(common.UploadChecksumHeader)
+	if checksum == "" {
+		return true
+	}
+
+	err := util.ValidateChecksum(checksum)
+	if err == nil && app.checksum != "" && !strings.EqualFold(checksum, app.checksum) {
+		err = errors.Errorf("checksum %s does not match the checksum %s of the DataVolume", checksum, app.checksum)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return false
+	}
+	return true
+}
+
+// uploadChecksum returns the checksum the uploaded data must match: the one of the DataVolume, or else the one of the
+// checksum header of the request
+func (app *uploadServerApp) uploadChecksum(r *http.Request) string {
+	if app.checksum != "" {
+		return app.checksum
+	}
+	return r.Header.Get(common.UploadChecksumHeader)
+}
+
+// validateClient checks the client certificate of the request is the one of the upload proxy
+func (app *uploadServerApp) validateClient(w http.ResponseWriter, r *http.Request) bool {
 	if r.TLS != nil {
 		found := false
 
@@ -273,9 +337,29 @@ func (app *uploadServerApp) validateShouldHandleRequest(w http.ResponseWriter, r
 		klog.V(3).Infof("Handling HTTP connection")
 	}
 
+	return true
+}
+
+// beginUpload marks the upload in progress, unless another one is, the upload is already done or the single-use token
+// of the request was already used
+func (app *uploadServerApp) beginUpload(w http.ResponseWriter, r *http.Request) bool {
 	app.mutex.Lock()
 	defer app.mutex.Unlock()
 
+	if tokenID := r.Header.Get(common.UploadTokenIDHeader); tokenID != "" {
+		usedTokenIDs, err := app.getUsedTokenIDs()
+		if err != nil {
+			klog.Errorf("Loading the used tokens failed: %s", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return false
+		}
+		if usedTokenIDs[tokenID] {
+			klog.Warning("Got upload request with a single-use token already used")
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+	}
+
 	if app.uploading || app.processing {
 		klog.Warning("Got concurrent upload request")
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -293,6 +377,36 @@ func (app *uploadServerApp) validateShouldHandleRequest(w http.ResponseWriter, r
 	return true
 }
 
+// getUsedTokenIDs returns the IDs of the used single-use tokens, must be called with the mutex held
+func (app *uploadServerApp) getUsedTokenIDs() (map[string]bool, error) {
+	if app.usedTokenIDs == nil {
+		usedTokenIDs, err := loadUsedTokenIDs()
+		if err != nil {
+			return nil, err
+		}
+		app.usedTokenIDs = usedTokenIDs
+	}
+	return app.usedTokenIDs, nil
+}
+
+// useToken records the single-use token of a completed upload, so it is rejected by the next requests, even
+// after a restart. Must be called with the mutex held.
+func (app *uploadServerApp) useToken(r *http.Request) {
+	tokenID := r.Header.Get(common.UploadTokenIDHeader)
+	if tokenID == "" {
+		return
+	}
+	usedTokenIDs, err := app.getUsedTokenIDs()
+	if err != nil {
+		klog.Errorf("Loading the used tokens failed: %s", err)
+		return
+	}
+	usedTokenIDs[tokenID] = true
+	if err := saveUsedTokenIDs(usedTokenIDs); err != nil {
+		klog.Errorf("Persisting the used token failed: %s", err)
+	}
+}
+
 func (app *uploadServerApp) uploadHandlerAsync(irc imageReadCloser) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "HEAD" {
@@ -313,13 +427,14 @@ func (app *uploadServerApp) uploadHandlerAsync(irc imageReadCloser) http.Handler
 			w.WriteHeader(http.StatusBadRequest)
 		}
 
-		processor, err := uploadProcessorFuncAsync(readCloser, app.destination, app.imageSize, app.filesystemOverhead, cdiContentType)
+		processor, err := uploadProcessorFuncAsync(app.status.start(readCloser), app.destination, app.imageSize, app.filesystemOverhead, app.preallocation, app.uploadChecksum(r), cdiContentType, &app.status)
 
 		app.mutex.Lock()
 
 		if err != nil {
 			klog.Errorf("Saving stream failed: %s", err)
-			if _, ok := err.(importer.ValidationSizeError); ok {
+			app.status.failed(err)
+			if _, ok := err.(importer.ValidationSizeError); ok || util.IsChecksumMismatch(err) {
 				w.WriteHeader(http.StatusBadRequest)
 			} else {
 				w.WriteHeader(http.StatusInternalServerError)
@@ -333,18 +448,21 @@ func (app *uploadServerApp) uploadHandlerAsync(irc imageReadCloser) http.Handler
 
 		app.uploading = false
 		app.processing = true
+		app.useToken(r)
 
 		// Start processing.
 		go func() {
 			defer close(app.doneChan)
 			if err := processor.ProcessDataResume(); err != nil {
 				klog.Errorf("Error during resumed processing: %v", err)
+				app.status.failed(err)
 				app.errChan <- err
 			}
 			app.mutex.Lock()
 			defer app.mutex.Unlock()
 			app.processing = false
 			app.done = true
+			app.preallocationApplied = processor.PreallocationApplied()
 			klog.Infof("Wrote data to %s", app.destination)
 		}()
 
@@ -367,20 +485,29 @@ func (app *uploadServerApp) uploadHandler(irc imageReadCloser) http.HandlerFunc
 			w.WriteHeader(http.StatusBadRequest)
 		}
 
-		err = uploadProcessorFunc(readCloser, app.destination, app.imageSize, app.filesystemOverhead, cdiContentType)
+		preallocationApplied, err := uploadProcessorFunc(app.status.start(readCloser), app.destination, app.imageSize, app.filesystemOverhead, app.preallocation, app.uploadChecksum(r), cdiContentType, &app.status)
 
 		app.mutex.Lock()
 		defer app.mutex.Unlock()
 
 		if err != nil {
 			klog.Errorf("Saving stream failed: %s", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			app.status.failed(err)
+			if util.IsChecksumMismatch(err) {
+				// The upload can be retried with the right data.
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(fmt.Sprintf("Saving stream failed: %s", err.Error())))
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
 			app.uploading = false
 			return
 		}
 
 		app.uploading = false
 		app.done = true
+		app.preallocationApplied = preallocationApplied
+		app.useToken(r)
 
 		close(app.doneChan)
 
@@ -388,46 +515,286 @@ func (app *uploadServerApp) uploadHandler(irc imageReadCloser) http.HandlerFunc
 	}
 }
 
-func newAsyncUploadStreamProcessor(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, contentType string) (*importer.DataProcessor, error) {
+func (app *uploadServerApp) statusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !app.validateClient(w, r) {
+		return
+	}
+
+	status := app.status.get()
+	app.mutex.Lock()
+	if status.BytesReceived == 0 && app.chunked != nil {
+		// After a restart, report the data received by a chunked upload before the first chunk.
+		status.BytesReceived, _ = app.chunked.status()
+	}
+	app.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		klog.Errorf("Writing status failed: %s", err)
+	}
+}
+
+// getChunkedUpload returns the staged chunked upload, must be called with the mutex held
+func (app *uploadServerApp) getChunkedUpload() (*chunkedUpload, error) {
+	if app.chunked == nil {
+		upload, err := openChunkedUpload(chunkedUploadDir)
+		if err != nil {
+			return nil, err
+		}
+		app.chunked = upload
+	}
+	return app.chunked, nil
+}
+
+// chunkedUploadHandler serves resumable uploads: HEAD returns the offset received so far, and PATCH appends the chunk
+// sent at that offset. The data is processed once the chunk completing the upload is received.
+func (app *uploadServerApp) chunkedUploadHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodHead:
+		app.chunkedUploadStatus(w, r)
+	case http.MethodPatch:
+		app.chunkedUploadPatch(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (app *uploadServerApp) chunkedUploadStatus(w http.ResponseWriter, r *http.Request) {
+	if !app.validateClient(w, r) {
+		return
+	}
+
+	app.mutex.Lock()
+	upload, err := app.getChunkedUpload()
+	app.mutex.Unlock()
+	if err != nil {
+		klog.Errorf("Opening chunked upload failed: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	setChunkedUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (app *uploadServerApp) chunkedUploadPatch(w http.ResponseWriter, r *http.Request) {
+	if !app.validateClient(w, r) {
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get(common.UploadOffsetHeader), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf("Invalid %s header", common.UploadOffsetHeader)))
+		return
+	}
+
+	if !app.validateChecksum(w, r) || !app.beginUpload(w, r) {
+		return
+	}
+
+	app.mutex.Lock()
+	upload, err := app.getChunkedUpload()
+	app.mutex.Unlock()
+	if err != nil {
+		klog.Errorf("Opening chunked upload failed: %s", err)
+		app.endChunk(w, r, nil, http.StatusInternalServerError, nil)
+		return
+	}
+
+	received, _ := upload.status()
+	app.status.setReceived(received)
+	r.Body = app.status.count(r.Body)
+	status, err := appendChunk(upload, offset, r)
+	received, _ = upload.status()
+	app.status.setReceived(received)
+	if err != nil {
+		klog.Errorf("Saving chunk failed: %s", err)
+		app.endChunk(w, r, upload, status, err)
+		return
+	}
+	if !upload.complete() {
+		app.endChunk(w, r, upload, http.StatusNoContent, nil)
+		return
+	}
+
+	klog.Infof("Received all the data of the chunked upload, processing it")
+	stream, err := os.Open(upload.dataPath())
+	var preallocationApplied bool
+	if err == nil {
+		preallocationApplied, err = uploadProcessorFuncChunked(stream, app.destination, app.imageSize, app.filesystemOverhead, app.preallocation, app.uploadChecksum(r), r.Header.Get(common.UploadContentTypeHeader), &app.status)
+	}
+	if err != nil {
+		klog.Errorf("Saving stream failed: %s", err)
+		app.status.failed(err)
+		if util.IsChecksumMismatch(err) {
+			// The data is wrong, the upload starts over.
+			if resetErr := upload.reset(); resetErr != nil {
+				klog.Errorf("Resetting chunked upload failed: %s", resetErr)
+			}
+			app.endChunk(w, r, upload, http.StatusBadRequest, errors.Wrap(err, "Saving stream failed"))
+		} else {
+			// The data is kept, the processing is retried by sending an empty chunk at the end of the upload.
+			app.endChunk(w, r, upload, http.StatusInternalServerError, nil)
+		}
+		return
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	app.uploading = false
+	app.done = true
+	app.preallocationApplied = preallocationApplied
+	app.useToken(r)
+	close(app.doneChan)
+
+	setChunkedUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+	klog.Infof("Wrote data to %s", app.destination)
+}
+
+// endChunk ends the handling of a chunk that did not complete the upload, replying with the status and the error
+func (app *uploadServerApp) endChunk(w http.ResponseWriter, r *http.Request, upload *chunkedUpload, status int, err error) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.uploading = false
+
+	if upload != nil {
+		setChunkedUploadHeaders(w, upload)
+	}
+	w.WriteHeader(status)
+	if err != nil {
+		w.Write([]byte(err.Error()))
+	}
+}
+
+// appendChunk appends the body of the request to the upload if it is sent at the offset received so far, and returns
+// the status to reply with
+func appendChunk(upload *chunkedUpload, offset int64, r *http.Request) (int, error) {
+	if current, _ := upload.status(); offset != current {
+		return http.StatusConflict, errors.Errorf("chunk offset %d does not match the upload offset %d", offset, current)
+	}
+	if lengthHeader := r.Header.Get(common.UploadLengthHeader); lengthHeader != "" {
+		length, err := strconv.ParseInt(lengthHeader, 10, 64)
+		if err != nil {
+			return http.StatusBadRequest, errors.Errorf("invalid %s header", common.UploadLengthHeader)
+		}
+		if err := upload.setLength(length); err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
+	if _, length := upload.status(); length == 0 {
+		return http.StatusBadRequest, errors.Errorf("%s header required", common.UploadLengthHeader)
+	}
+	if err := upload.append(r.Body); err != nil {
+		if err == errChunkTooLarge {
+			return http.StatusBadRequest, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusNoContent, nil
+}
+
+func setChunkedUploadHeaders(w http.ResponseWriter, upload *chunkedUpload) {
+	offset, length := upload.status()
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set(common.UploadOffsetHeader, strconv.FormatInt(offset, 10))
+	if length > 0 {
+		w.Header().Set(common.UploadLengthHeader, strconv.FormatInt(length, 10))
+	}
+}
+
+func newChunkedUploadStreamProcessor(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (bool, error) {
+	if contentType != "" {
+		return false, fmt.Errorf("chunked upload of content type %q not supported", contentType)
+	}
+
+	uds := importer.NewStagedUploadDataSource(stream, checksum, chunkedUploadDirName)
+	processor := importer.NewDataProcessor(uds, dest, common.ImporterVolumePath, common.ScratchDataDir, imageSize, filesystemOverhead, preallocation)
+	processor.SetObserver(observer)
+	err := processor.ProcessData()
+	return processor.PreallocationApplied(), err
+}
+
+func newAsyncUploadStreamProcessor(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (*importer.DataProcessor, error) {
+	contentType, codec, err := clonecodec.ParseContentType(contentType)
+	if err != nil {
+		return nil, err
+	}
 	if contentType == common.FilesystemCloneContentType {
 		return nil, fmt.Errorf("async filesystem clone not supported")
 	}
+	if contentType == common.BlockdeviceCloneSparse {
+		return nil, fmt.Errorf("async sparse block device clone not supported")
+	}
 
-	uds := importer.NewAsyncUploadDataSource(newContentReader(stream, contentType))
-	processor := importer.NewDataProcessor(uds, dest, common.ImporterVolumePath, common.ScratchDataDir, imageSize, filesystemOverhead)
+	reader, err := newContentReader(stream, contentType, codec)
+	if err != nil {
+		return nil, err
+	}
+	uds := importer.NewAsyncUploadDataSource(reader, checksum)
+	processor := importer.NewDataProcessor(uds, dest, common.ImporterVolumePath, common.ScratchDataDir, imageSize, filesystemOverhead, preallocation)
+	processor.SetObserver(observer)
 	return processor, processor.ProcessDataWithPause()
 }
 
-func newUploadStreamProcessor(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, contentType string) error {
+func newUploadStreamProcessor(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (bool, error) {
+	contentType, codec, err := clonecodec.ParseContentType(contentType)
+	if err != nil {
+		return false, err
+	}
 	if contentType == common.FilesystemCloneContentType {
-		return filesystemCloneProcessor(stream, common.ImporterVolumePath)
+		// a filesystem source is streamed as a raw disk image when the target is a block device
+		if dest == common.WriteBlockPath {
+			return false, fmt.Errorf("filesystem clone to a block device not supported")
+		}
+		return false, filesystemCloneProcessor(stream, common.ImporterVolumePath, codec)
 	}
 
-	uds := importer.NewUploadDataSource(newContentReader(stream, contentType))
-	processor := importer.NewDataProcessor(uds, dest, common.ImporterVolumePath, common.ScratchDataDir, imageSize, filesystemOverhead)
-	return processor.ProcessData()
+	reader, err := newContentReader(stream, contentType, codec)
+	if err != nil {
+		return false, err
+	}
+	var uds importer.DataSourceInterface
+	if contentType == common.BlockdeviceCloneSparse {
+		uds = importer.NewExtentUploadDataSource(reader)
+	} else {
+		uds = importer.NewUploadDataSource(reader, checksum)
+	}
+	processor := importer.NewDataProcessor(uds, dest, common.ImporterVolumePath, common.ScratchDataDir, imageSize, filesystemOverhead, preallocation)
+	processor.SetObserver(observer)
+	err = processor.ProcessData()
+	return processor.PreallocationApplied(), err
 }
 
-func filesystemCloneProcessor(stream io.ReadCloser, destDir string) error {
+func filesystemCloneProcessor(stream io.ReadCloser, destDir, codec string) error {
 	if err := importer.CleanDir(destDir); err != nil {
 		return errors.Wrapf(err, "error removing contents of %s", destDir)
 	}
 
-	if err := util.UnArchiveTar(newSnappyReadCloser(stream), destDir); err != nil {
+	reader, err := clonecodec.NewReader(stream, codec)
+	if err != nil {
+		return err
+	}
+	if err := util.UnArchiveTar(reader, destDir); err != nil {
 		return errors.Wrapf(err, "error unarchiving to %s", destDir)
 	}
 
 	return nil
 }
 
-func newContentReader(stream io.ReadCloser, contentType string) io.ReadCloser {
-	if contentType == common.BlockdeviceClone {
-		return newSnappyReadCloser(stream)
+// newContentReader decodes the stream of a block device clone with the codec the clone source announced
+func newContentReader(stream io.ReadCloser, contentType, codec string) (io.ReadCloser, error) {
+	if contentType == common.BlockdeviceClone || contentType == common.BlockdeviceCloneSparse {
+		return clonecodec.NewReader(stream, codec)
 	}
 
-	return stream
-}
-
-func newSnappyReadCloser(stream io.ReadCloser) io.ReadCloser {
-	return ioutil.NopCloser(snappy.NewReader(stream))
+	return stream, nil
 }
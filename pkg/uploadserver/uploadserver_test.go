@@ -25,25 +25,31 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/importer"
+	"kubevirt.io/containerized-data-importer/pkg/util"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/triple"
+	"kubevirt.io/containerized-data-importer/pkg/util/clonecodec"
 )
 
 func newServer() *uploadServerApp {
-	server := NewUploadServer("127.0.0.1", 0, "disk.img", "", "", "", "", "", 0.055)
+	server := NewUploadServer("127.0.0.1", 0, "disk.img", "", "", "", "", "", 0.055, false, "")
 	return server.(*uploadServerApp)
 }
 
@@ -61,7 +67,7 @@ func newTLSServer(clientCertName, expectedName string) (*uploadServerApp, *tripl
 	tlsCert := string(cert.EncodeCertPEM(serverKeyPair.Cert))
 	clientCert := string(cert.EncodeCertPEM(clientCA.Cert))
 
-	server := NewUploadServer("127.0.0.1", 0, "disk.img", tlsKey, tlsCert, clientCert, expectedName, "", 0.055).(*uploadServerApp)
+	server := NewUploadServer("127.0.0.1", 0, "disk.img", tlsKey, tlsCert, clientCert, expectedName, "", 0.055, false, "").(*uploadServerApp)
 
 	clientKeyPair, err := triple.NewClientKeyPair(clientCA, clientCertName, []string{})
 	Expect(err).ToNot(HaveOccurred())
@@ -88,12 +94,16 @@ func newHTTPClient(clientKeyPair *triple.KeyPair, serverCACert *x509.Certificate
 	return client
 }
 
-func saveProcessorSuccess(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, contentType string) error {
-	return nil
+func saveProcessorSuccess(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (bool, error) {
+	return preallocation, nil
+}
+
+func saveProcessorFailure(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (bool, error) {
+	return false, fmt.Errorf("Error using datastream")
 }
 
-func saveProcessorFailure(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, contentType string) error {
-	return fmt.Errorf("Error using datastream")
+func saveProcessorChecksumMismatch(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (bool, error) {
+	return false, errors.Wrap(&util.ChecksumMismatchError{Expected: checksum, Actual: "sha256:abc"}, "Unable to transfer source data to target file")
 }
 
 func withProcessorSuccess(f func()) {
@@ -104,7 +114,11 @@ func withProcessorFailure(f func()) {
 	replaceProcessorFunc(saveProcessorFailure, f)
 }
 
-func replaceProcessorFunc(replacement func(io.ReadCloser, string, string, float64, string) error, f func()) {
+func withProcessorChecksumMismatch(f func()) {
+	replaceProcessorFunc(saveProcessorChecksumMismatch, f)
+}
+
+func replaceProcessorFunc(replacement func(io.ReadCloser, string, string, float64, bool, string, string, importer.ProcessingObserver) (bool, error), f func()) {
 	origProcessorFunc := uploadProcessorFunc
 	uploadProcessorFunc = replacement
 	defer func() {
@@ -151,12 +165,12 @@ func (amd *AsyncMockDataSource) GetResumePhase() importer.ProcessingPhase {
 	return importer.ProcessingPhaseComplete
 }
 
-func saveAsyncProcessorSuccess(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, contentType string) (*importer.DataProcessor, error) {
-	return importer.NewDataProcessor(&AsyncMockDataSource{}, "", "", "", "", 0.055), nil
+func saveAsyncProcessorSuccess(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (*importer.DataProcessor, error) {
+	return importer.NewDataProcessor(&AsyncMockDataSource{}, "", "", "", "", 0.055, preallocation), nil
 }
 
-func saveAsyncProcessorFailure(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, contentType string) (*importer.DataProcessor, error) {
-	return importer.NewDataProcessor(&AsyncMockDataSource{}, "", "", "", "", 0.055), fmt.Errorf("Error using datastream")
+func saveAsyncProcessorFailure(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (*importer.DataProcessor, error) {
+	return importer.NewDataProcessor(&AsyncMockDataSource{}, "", "", "", "", 0.055, preallocation), fmt.Errorf("Error using datastream")
 }
 
 func withAsyncProcessorSuccess(f func()) {
@@ -167,7 +181,14 @@ func withAsyncProcessorFailure(f func()) {
 	replaceAsyncProcessorFunc(saveAsyncProcessorFailure, f)
 }
 
-func replaceAsyncProcessorFunc(replacement func(io.ReadCloser, string, string, float64, string) (*importer.DataProcessor, error), f func()) {
+func withAsyncProcessorChecksumMismatch(f func()) {
+	replaceAsyncProcessorFunc(func(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, checksum, contentType string, observer importer.ProcessingObserver) (*importer.DataProcessor, error) {
+		_, err := saveProcessorChecksumMismatch(stream, dest, imageSize, filesystemOverhead, preallocation, checksum, contentType, observer)
+		return importer.NewDataProcessor(&AsyncMockDataSource{}, "", "", "", "", 0.055, preallocation), err
+	}, f)
+}
+
+func replaceAsyncProcessorFunc(replacement func(io.ReadCloser, string, string, float64, bool, string, string, importer.ProcessingObserver) (*importer.DataProcessor, error), f func()) {
 	origProcessorFuncAsync := uploadProcessorFuncAsync
 	uploadProcessorFuncAsync = replacement
 	defer func() {
@@ -316,6 +337,54 @@ var _ = Describe("Upload server tests", func() {
 		table.Entry("sync", withProcessorFailure, common.UploadPathSync),
 	)
 
+	table.DescribeTable("Stream checksum mismatch", func(processorFunc func(func()), uploadPath string) {
+		processorFunc(func() {
+			req, err := http.NewRequest("POST", uploadPath, strings.NewReader("data"))
+			Expect(err).ToNot(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+
+			server := newServer()
+			server.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusBadRequest))
+			Expect(rr.Body.String()).To(ContainSubstring("checksum mismatch"))
+
+			By("Accepting another upload")
+			Expect(server.uploading).To(BeFalse())
+			Expect(server.done).To(BeFalse())
+		})
+	},
+		table.Entry("async", withAsyncProcessorChecksumMismatch, common.UploadPathAsync),
+		table.Entry("sync", withProcessorChecksumMismatch, common.UploadPathSync),
+	)
+
+	table.DescribeTable("Checksum header", func(dvChecksum, header string, expectedStatus int, expectedChecksum string) {
+		var checksum string
+		replaceProcessorFunc(func(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, c, contentType string, observer importer.ProcessingObserver) (bool, error) {
+			checksum = c
+			return false, nil
+		}, func() {
+			req, err := http.NewRequest("POST", common.UploadPathSync, strings.NewReader("data"))
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set(common.UploadChecksumHeader, header)
+
+			rr := httptest.NewRecorder()
+			server := NewUploadServer("127.0.0.1", 0, "disk.img", "", "", "", "", "", 0.055, false, dvChecksum).(*uploadServerApp)
+			server.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(expectedStatus))
+			Expect(checksum).To(Equal(expectedChecksum))
+			Expect(server.uploading).To(BeFalse())
+		})
+	},
+		table.Entry("uses the header without DataVolume checksum", "", "sha256:"+strings.Repeat("ab", 32), http.StatusOK, "sha256:"+strings.Repeat("ab", 32)),
+		table.Entry("uses the DataVolume checksum without header", "sha256:"+strings.Repeat("ab", 32), "", http.StatusOK, "sha256:"+strings.Repeat("ab", 32)),
+		table.Entry("accepts a header matching the DataVolume checksum", "sha256:"+strings.Repeat("ab", 32), "SHA256:"+strings.Repeat("AB", 32), http.StatusOK, "sha256:"+strings.Repeat("ab", 32)),
+		table.Entry("rejects a header conflicting with the DataVolume checksum", "sha256:"+strings.Repeat("ab", 32), "sha256:"+strings.Repeat("cd", 32), http.StatusBadRequest, ""),
+		table.Entry("rejects an invalid header", "", "sha256:abc", http.StatusBadRequest, ""),
+	)
+
 	table.DescribeTable("Stream fail form", func(processorFunc func(func()), uploadPath string) {
 		processorFunc(func() {
 			req := newFormRequest(uploadPath)
@@ -371,6 +440,54 @@ var _ = Describe("Upload server tests", func() {
 		table.Entry("Valid data", "client", "client", 200),
 		table.Entry("Invalid data", "foo", "bar", 401),
 	)
+
+	table.DescribeTable("Block device clone stream is decoded with the announced codec", func(codec string) {
+		var compressed bytes.Buffer
+		w, err := clonecodec.NewWriter(&compressed, codec, 0)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = w.Write([]byte("data"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		contentType, c, err := clonecodec.ParseContentType(clonecodec.ContentType(common.BlockdeviceClone, codec))
+		Expect(err).ToNot(HaveOccurred())
+		reader, err := newContentReader(ioutil.NopCloser(&compressed), contentType, c)
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("data"))
+	},
+		table.Entry("none", clonecodec.None),
+		table.Entry("snappy", clonecodec.Snappy),
+		table.Entry("zstd", clonecodec.Zstd),
+	)
+
+	It("Upload with an unknown clone codec fails", func() {
+		_, err := newUploadStreamProcessor(ioutil.NopCloser(strings.NewReader("data")), "disk.img", "", 0.055, false, "", common.BlockdeviceClone+"; codec=lz4", nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unknown clone codec"))
+	})
+
+	It("Filesystem clone to a block device fails", func() {
+		_, err := newUploadStreamProcessor(ioutil.NopCloser(strings.NewReader("data")), common.WriteBlockPath, "", 0.055, false, "", common.FilesystemCloneContentType, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("filesystem clone to a block device not supported"))
+	})
+
+	It("Sparse block device clone is written from the extent stream", func() {
+		tmpDir, err := ioutil.TempDir("", "sparse-clone")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		_, err = newUploadStreamProcessor(ioutil.NopCloser(strings.NewReader("raw data, no extents")), filepath.Join(tmpDir, "disk.img"), "", 0.055, false, "", clonecodec.ContentType(common.BlockdeviceCloneSparse, clonecodec.None), nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not an extent stream"))
+	})
+
+	It("Async sparse block device clone fails", func() {
+		_, err := newAsyncUploadStreamProcessor(ioutil.NopCloser(strings.NewReader("data")), "disk.img", "", 0.055, false, "", common.BlockdeviceCloneSparse, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("async sparse block device clone not supported"))
+	})
 })
 
 func newFormRequest(path string) *http.Request {
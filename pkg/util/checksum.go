@@ -0,0 +1,114 @@
+package util
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// ChecksumMismatchError is returned when the data read does not match the expected checksum
+type ChecksumMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch, expected %s but got %s", e.Expected, e.Actual)
+}
+
+// IsChecksumMismatch returns true if the cause of the error is a checksum mismatch
+func IsChecksumMismatch(err error) bool {
+	_, ok := errors.Cause(err).(*ChecksumMismatchError)
+	return ok
+}
+
+// ValidateChecksum checks the checksum is in the <algorithm>:<hex digest> format, with a supported algorithm
+func ValidateChecksum(checksum string) error {
+	_, _, err := parseChecksum(checksum)
+	return err
+}
+
+func parseChecksum(checksum string) (string, hash.Hash, error) {
+	parts := strings.SplitN(checksum, ":", 2)
+	if len(parts) != 2 {
+		return "", nil, errors.Errorf("checksum %q is not in the <algorithm>:<digest> format", checksum)
+	}
+	algorithm := strings.ToLower(parts[0])
+	newHash, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return "", nil, errors.Errorf("unsupported checksum algorithm %q", parts[0])
+	}
+	h := newHash()
+	digest, err := hex.DecodeString(parts[1])
+	if err != nil || len(digest) != h.Size() {
+		return "", nil, errors.Errorf("invalid %s digest %q", algorithm, parts[1])
+	}
+	return algorithm, h, nil
+}
+
+// ChecksumReader computes the checksum of the data read through it, so it can be compared with the expected one
+type ChecksumReader struct {
+	reader    io.ReadCloser
+	hash      hash.Hash
+	algorithm string
+	expected  string
+}
+
+// NewChecksumReader creates a ChecksumReader reading from the passed in stream, checksum is in the
+// <algorithm>:<hex digest> format
+func NewChecksumReader(stream io.ReadCloser, checksum string) (*ChecksumReader, error) {
+	algorithm, h, err := parseChecksum(checksum)
+	if err != nil {
+		return nil, err
+	}
+	return &ChecksumReader{
+		reader:    stream,
+		hash:      h,
+		algorithm: algorithm,
+		expected:  strings.ToLower(strings.SplitN(checksum, ":", 2)[1]),
+	}, nil
+}
+
+// Read reads from the underlying stream and adds the data to the checksum
+func (r *ChecksumReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+	return n, err
+}
+
+// Close closes the underlying stream
+func (r *ChecksumReader) Close() error {
+	return r.reader.Close()
+}
+
+// Verify reads what is left of the stream, and returns a ChecksumMismatchError if the checksum of the data does
+// not match the expected one
+func (r *ChecksumReader) Verify() error {
+	if _, err := io.Copy(ioutil.Discard, r); err != nil {
+		return errors.Wrap(err, "unable to read the remaining data to verify the checksum")
+	}
+	actual := hex.EncodeToString(r.hash.Sum(nil))
+	if actual != r.expected {
+		return &ChecksumMismatchError{
+			Expected: fmt.Sprintf("%s:%s", r.algorithm, r.expected),
+			Actual:   fmt.Sprintf("%s:%s", r.algorithm, actual),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+const (
+	// sha256 and md5 of "hello world"
+	helloSha256 = "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	helloMd5    = "md5:5eb63bbbe01eeed093cb22bb8f5acdc3"
+)
+
+var _ = Describe("Checksum", func() {
+	table.DescribeTable("Should validate the checksum format", func(checksum string, valid bool) {
+		err := ValidateChecksum(checksum)
+		if valid {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		table.Entry("sha256", helloSha256, true),
+		table.Entry("md5", helloMd5, true),
+		table.Entry("upper case algorithm", strings.ToUpper(helloSha256[:6])+helloSha256[6:], true),
+		table.Entry("missing algorithm", strings.TrimPrefix(helloSha256, "sha256:"), false),
+		table.Entry("unknown algorithm", "crc32:0d4a1185", false),
+		table.Entry("digest not hex", "sha256:not-hex", false),
+		table.Entry("digest of the wrong length", "sha512:5eb63bbbe01eeed093cb22bb8f5acdc3", false),
+	)
+
+	It("Should verify the data read through it", func() {
+		reader, err := NewChecksumReader(ioutil.NopCloser(strings.NewReader("hello world")), helloSha256)
+		Expect(err).ToNot(HaveOccurred())
+		buf := make([]byte, 5)
+		_, err = reader.Read(buf)
+		Expect(err).ToNot(HaveOccurred())
+		// The remaining data is read by Verify
+		Expect(reader.Verify()).To(Succeed())
+	})
+
+	It("Should report a mismatch", func() {
+		reader, err := NewChecksumReader(ioutil.NopCloser(bytes.NewReader([]byte("hello world!"))), helloMd5)
+		Expect(err).ToNot(HaveOccurred())
+		err = reader.Verify()
+		Expect(err).To(HaveOccurred())
+		Expect(IsChecksumMismatch(errors.Wrap(err, "wrapped"))).To(BeTrue())
+		Expect(err.(*ChecksumMismatchError).Expected).To(Equal(helloMd5))
+	})
+})
@@ -0,0 +1,123 @@
+/*
+ * This file is part of the CDI project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package clonecodec
+
+import (
+	"io"
+	"io/ioutil"
+	"mime"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+const (
+	// None sends the clone stream uncompressed
+	None = "none"
+	// Snappy compresses the clone stream with snappy, the codec used when none is negotiated
+	Snappy = "snappy"
+	// Zstd compresses the clone stream with zstd
+	Zstd = "zstd"
+
+	// DefaultZstdLevel is the zstd compression level used when none is requested
+	DefaultZstdLevel = 3
+
+	codecParam = "codec"
+)
+
+// Validate checks codec is a known codec name
+func Validate(codec string) error {
+	switch codec {
+	case None, Snappy, Zstd:
+		return nil
+	}
+	return errors.Errorf("unknown clone codec %q", codec)
+}
+
+// ContentType returns the content type header value announcing the codec of a clone stream. Snappy streams keep the
+// bare content type, so upload servers predating codec negotiation still understand them.
+func ContentType(contentType, codec string) string {
+	if codec == "" || codec == Snappy {
+		return contentType
+	}
+	return mime.FormatMediaType(contentType, map[string]string{codecParam: codec})
+}
+
+// ParseContentType splits a content type header value into the content type and the codec of the stream
+func ParseContentType(header string) (string, string, error) {
+	if header == "" {
+		return "", Snappy, nil
+	}
+	contentType, params, err := mime.ParseMediaType(header)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "invalid content type %q", header)
+	}
+	codec, ok := params[codecParam]
+	if !ok {
+		codec = Snappy
+	}
+	if err = Validate(codec); err != nil {
+		return "", "", err
+	}
+	return contentType, codec, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// NewWriter returns a writer compressing to w with codec. The level only applies to zstd, 0 selects the default one.
+// Closing the writer flushes the compressed stream but does not close w.
+func NewWriter(w io.Writer, codec string, level int) (io.WriteCloser, error) {
+	switch codec {
+	case None:
+		return nopWriteCloser{w}, nil
+	case Snappy, "":
+		return snappy.NewBufferedWriter(w), nil
+	case Zstd:
+		if level == 0 {
+			level = DefaultZstdLevel
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	return nil, Validate(codec)
+}
+
+// NewReader returns a reader decompressing r with codec
+func NewReader(r io.Reader, codec string) (io.ReadCloser, error) {
+	switch codec {
+	case None:
+		return ioutil.NopCloser(r), nil
+	case Snappy, "":
+		return ioutil.NopCloser(snappy.NewReader(r)), nil
+	case Zstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create zstd reader")
+		}
+		return zr.IOReadCloser(), nil
+	}
+	return nil, Validate(codec)
+}
@@ -0,0 +1,83 @@
+/*
+ * This file is part of the CDI project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package clonecodec
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Clone codec", func() {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+	table.DescribeTable("should round trip a stream", func(codec string, level int) {
+		var compressed bytes.Buffer
+		w, err := NewWriter(&compressed, codec, level)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = w.Write(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		if codec != None {
+			Expect(compressed.Len()).To(BeNumerically("<", len(data)))
+		}
+
+		r, err := NewReader(&compressed, codec)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := ioutil.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Close()).To(Succeed())
+		Expect(result).To(Equal(data))
+	},
+		table.Entry("uncompressed", None, 0),
+		table.Entry("with snappy", Snappy, 0),
+		table.Entry("with zstd at the default level", Zstd, 0),
+		table.Entry("with zstd at the fastest level", Zstd, 1),
+		table.Entry("with zstd at the best level", Zstd, 19),
+	)
+
+	It("should reject unknown codecs", func() {
+		_, err := NewWriter(&bytes.Buffer{}, "lz4", 0)
+		Expect(err).To(HaveOccurred())
+		_, err = NewReader(&bytes.Buffer{}, "lz4")
+		Expect(err).To(HaveOccurred())
+	})
+
+	table.DescribeTable("should announce the codec in the content type", func(codec, header, parsedCodec string) {
+		Expect(ContentType("blockdevice-clone", codec)).To(Equal(header))
+		contentType, c, err := ParseContentType(header)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contentType).To(Equal("blockdevice-clone"))
+		Expect(c).To(Equal(parsedCodec))
+	},
+		table.Entry("leaving out the default codec", "", "blockdevice-clone", Snappy),
+		table.Entry("leaving out snappy", Snappy, "blockdevice-clone", Snappy),
+		table.Entry("for zstd", Zstd, "blockdevice-clone; codec=zstd", Zstd),
+		table.Entry("for no compression", None, "blockdevice-clone; codec=none", None),
+	)
+
+	It("should reject an unknown codec in the content type", func() {
+		_, _, err := ParseContentType("blockdevice-clone; codec=lz4")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,254 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+// An extent stream describes the content of a file or block device as a sequence of contiguous extents, so ranges
+// that only contain zeroes are sent as a single record instead of their data. The stream starts with extentMagic,
+// followed by records made of a kind byte, a big endian uint64 offset and a big endian uint64 length. Data records
+// are followed by length bytes of data, the end record carries the total size as its offset.
+const (
+	extentMagic      = "CDIEXT01"
+	extentHeaderSize = 17
+	// extentChunkSize is the amount of data read from the source at a time.
+	extentChunkSize = 16 * sparseBlockSize
+
+	extentData byte = 1
+	extentZero byte = 2
+	extentEnd  byte = 3
+
+	// seekData and seekHole are the lseek whence values of SEEK_DATA and SEEK_HOLE, not exposed by x/sys/unix.
+	seekData = 3
+	seekHole = 4
+)
+
+// ExtentReader reads a file or block device and produces an extent stream of its content. Holes of regular files
+// are found with SEEK_DATA/SEEK_HOLE without reading them, and zero blocks are detected in the data that is read.
+// Block devices don't report their unmapped ranges, so they are read entirely: the zero ranges of a block source are
+// not sent, but they are still read.
+type ExtentReader struct {
+	file *os.File
+	size int64
+	// offset is the position of the next byte of the source to encode.
+	offset int64
+	// seekHoles is true when the holes of the source can be found with SEEK_DATA/SEEK_HOLE.
+	seekHoles bool
+	buf       []byte
+	// out holds the encoded records waiting to be read.
+	out bytes.Buffer
+	// zeroStart and zeroLength is the pending zero extent, merged with the following zero ranges.
+	zeroStart  int64
+	zeroLength int64
+	// dataBytes is the number of data bytes sent.
+	dataBytes int64
+	done      bool
+}
+
+// NewExtentReader creates an ExtentReader on top of an open file or block device.
+func NewExtentReader(file *os.File) (*ExtentReader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not stat %q", file.Name())
+	}
+	r := &ExtentReader{
+		file:      file,
+		size:      info.Size(),
+		seekHoles: info.Mode().IsRegular(),
+		buf:       make([]byte, extentChunkSize),
+	}
+	if !r.seekHoles {
+		if r.size, err = file.Seek(0, io.SeekEnd); err != nil {
+			return nil, errors.Wrapf(err, "could not determine size of %q", file.Name())
+		}
+	}
+	r.out.WriteString(extentMagic)
+	return r, nil
+}
+
+// Read reads the next part of the extent stream.
+func (r *ExtentReader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 && !r.done {
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	if r.out.Len() == 0 {
+		return 0, io.EOF
+	}
+	return r.out.Read(p)
+}
+
+// Close closes the underlying file.
+func (r *ExtentReader) Close() error {
+	return r.file.Close()
+}
+
+// Offset returns the number of bytes of the source that were encoded so far.
+func (r *ExtentReader) Offset() int64 {
+	return r.offset
+}
+
+// DataBytes returns the number of data bytes sent, the rest of the source was sent as zero extents.
+func (r *ExtentReader) DataBytes() int64 {
+	return r.dataBytes
+}
+
+func (r *ExtentReader) next() error {
+	if r.offset >= r.size {
+		r.flushZero()
+		r.writeHeader(extentEnd, r.size, 0)
+		r.done = true
+		return nil
+	}
+	limit := r.size
+	if r.seekHoles {
+		data, hole, err := r.nextDataRange()
+		if err != nil {
+			return err
+		}
+		if data > r.offset {
+			r.addZero(r.offset, data-r.offset)
+			r.offset = data
+			return nil
+		}
+		limit = hole
+	}
+	length := int64(len(r.buf))
+	if limit-r.offset < length {
+		length = limit - r.offset
+	}
+	chunk := r.buf[:length]
+	if _, err := r.file.ReadAt(chunk, r.offset); err != nil {
+		return errors.Wrapf(err, "unable to read %q at offset %d", r.file.Name(), r.offset)
+	}
+	start := 0
+	for start < len(chunk) {
+		end := nextBlockEnd(chunk, start)
+		zero := isZero(chunk[start:end])
+		for end < len(chunk) {
+			next := nextBlockEnd(chunk, end)
+			if isZero(chunk[end:next]) != zero {
+				break
+			}
+			end = next
+		}
+		off := r.offset + int64(start)
+		if zero {
+			r.addZero(off, int64(end-start))
+		} else {
+			r.flushZero()
+			r.writeHeader(extentData, off, int64(end-start))
+			r.out.Write(chunk[start:end])
+			r.dataBytes += int64(end - start)
+		}
+		start = end
+	}
+	r.offset += length
+	return nil
+}
+
+// nextDataRange returns the start of the next data range of the source from the current offset, and its end.
+func (r *ExtentReader) nextDataRange() (int64, int64, error) {
+	fd := int(r.file.Fd())
+	data, err := unix.Seek(fd, r.offset, seekData)
+	if err == unix.ENXIO {
+		// No data after the offset, the rest of the file is a hole.
+		return r.size, r.size, nil
+	}
+	if err != nil {
+		klog.V(3).Infof("Unable to seek data in %q, reading all of it: %v", r.file.Name(), err)
+		r.seekHoles = false
+		return r.offset, r.size, nil
+	}
+	hole, err := unix.Seek(fd, data, seekHole)
+	if err != nil || hole > r.size {
+		hole = r.size
+	}
+	if data > r.size {
+		data = r.size
+	}
+	return data, hole, nil
+}
+
+func (r *ExtentReader) addZero(off, length int64) {
+	if r.zeroLength > 0 && r.zeroStart+r.zeroLength == off {
+		r.zeroLength += length
+		return
+	}
+	r.flushZero()
+	r.zeroStart = off
+	r.zeroLength = length
+}
+
+func (r *ExtentReader) flushZero() {
+	if r.zeroLength > 0 {
+		r.writeHeader(extentZero, r.zeroStart, r.zeroLength)
+		r.zeroLength = 0
+	}
+}
+
+func (r *ExtentReader) writeHeader(kind byte, off, length int64) {
+	var header [extentHeaderSize]byte
+	header[0] = kind
+	binary.BigEndian.PutUint64(header[1:9], uint64(off))
+	binary.BigEndian.PutUint64(header[9:], uint64(length))
+	r.out.Write(header[:])
+}
+
+// WriteExtents applies the extent stream read from r to the SparseWriter, writing the data extents and zeroing the
+// zero extents with ZeroRange, which unmaps them on block devices, and returns the total size of the source.
+func WriteExtents(r io.Reader, w *SparseWriter) (int64, error) {
+	magic := make([]byte, len(extentMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return 0, errors.Wrap(err, "unable to read extent stream")
+	}
+	if string(magic) != extentMagic {
+		return 0, errors.New("not an extent stream")
+	}
+	var header [extentHeaderSize]byte
+	buf := make([]byte, extentChunkSize)
+	expected := int64(0)
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return 0, errors.Wrap(err, "unable to read extent header")
+		}
+		off := int64(binary.BigEndian.Uint64(header[1:9]))
+		length := int64(binary.BigEndian.Uint64(header[9:]))
+		if off != expected || length < 0 {
+			return 0, errors.Errorf("invalid extent at offset %d with length %d, expected offset %d", off, length, expected)
+		}
+		switch header[0] {
+		case extentData:
+			for remaining := length; remaining > 0; {
+				chunk := buf
+				if remaining < int64(len(chunk)) {
+					chunk = chunk[:remaining]
+				}
+				if _, err := io.ReadFull(r, chunk); err != nil {
+					return 0, errors.Wrap(err, "unable to read extent data")
+				}
+				if _, err := w.WriteAt(chunk, off+length-remaining); err != nil {
+					return 0, errors.Wrap(err, "unable to write extent data")
+				}
+				remaining -= int64(len(chunk))
+			}
+		case extentZero:
+			if err := w.ZeroRange(off, length); err != nil {
+				return 0, errors.Wrap(err, "unable to zero extent")
+			}
+		case extentEnd:
+			return off, nil
+		default:
+			return 0, errors.Errorf("unknown extent kind %d", header[0])
+		}
+		expected += length
+	}
+}
@@ -0,0 +1,150 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Extent stream", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "extents")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	data := func(size int, value byte) []byte {
+		return bytes.Repeat([]byte{value}, size)
+	}
+
+	encode := func(name string) ([]byte, *ExtentReader) {
+		file, err := os.Open(filepath.Join(tmpDir, name))
+		Expect(err).NotTo(HaveOccurred())
+		reader, err := NewExtentReader(file)
+		Expect(err).NotTo(HaveOccurred())
+		stream, err := ioutil.ReadAll(reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reader.Close()).To(Succeed())
+		return stream, reader
+	}
+
+	It("Should only send the data of a sparse file and restore it", func() {
+		source := filepath.Join(tmpDir, "source.img")
+		file, err := os.Create(source)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.WriteAt(data(sparseBlockSize, 1), 4*extentChunkSize)
+		Expect(err).NotTo(HaveOccurred())
+		// A zero block with allocated storage is detected while reading the data.
+		_, err = file.WriteAt(append(data(sparseBlockSize, 0), data(10, 2)...), 4*extentChunkSize+sparseBlockSize)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file.Truncate(16 * extentChunkSize)).To(Succeed())
+		Expect(file.Close()).To(Succeed())
+
+		stream, reader := encode("source.img")
+		Expect(reader.Offset()).To(Equal(int64(16 * extentChunkSize)))
+		// The data ranges found by SEEK_DATA are aligned to the filesystem blocks.
+		Expect(reader.DataBytes()).To(BeNumerically(">=", sparseBlockSize+10))
+		Expect(reader.DataBytes()).To(BeNumerically("<", 2*sparseBlockSize))
+		Expect(len(stream)).To(BeNumerically("<", 2*sparseBlockSize))
+
+		target := filepath.Join(tmpDir, "target.img")
+		Expect(StreamExtentsToFile(bytes.NewReader(stream), target)).To(Succeed())
+		expected, err := ioutil.ReadFile(source)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := ioutil.ReadFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(expected))
+		allocated, err := GetAllocatedSize(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allocated).To(BeNumerically("<", 4*sparseBlockSize))
+	})
+
+	It("Should encode an empty file", func() {
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "source.img"), nil, 0644)).To(Succeed())
+		stream, _ := encode("source.img")
+		Expect(stream).To(HaveLen(len(extentMagic) + extentHeaderSize))
+
+		target := filepath.Join(tmpDir, "target.img")
+		Expect(StreamExtentsToFile(bytes.NewReader(stream), target)).To(Succeed())
+		info, err := os.Stat(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Size()).To(BeZero())
+	})
+
+	It("Should zero existing data covered by zero extents", func() {
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "source.img"), append(data(sparseBlockSize, 1), data(2*sparseBlockSize, 0)...), 0644)).To(Succeed())
+		stream, _ := encode("source.img")
+
+		file, err := os.OpenFile(filepath.Join(tmpDir, "target.img"), os.O_CREATE|os.O_WRONLY, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.Write(data(3*sparseBlockSize, 5))
+		Expect(err).NotTo(HaveOccurred())
+		writer, err := NewSparseWriter(file)
+		Expect(err).NotTo(HaveOccurred())
+		size, err := WriteExtents(bytes.NewReader(stream), writer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(int64(3 * sparseBlockSize)))
+		Expect(writer.Close()).To(Succeed())
+
+		result, err := ioutil.ReadFile(filepath.Join(tmpDir, "target.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(append(data(sparseBlockSize, 1), data(2*sparseBlockSize, 0)...)))
+	})
+
+	It("Should unmap the zero extents on block devices", func() {
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "source.img"), append(data(sparseBlockSize, 1), data(2*sparseBlockSize, 0)...), 0644)).To(Succeed())
+		stream, _ := encode("source.img")
+
+		target := filepath.Join(tmpDir, "block.img")
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		writer, err := NewSparseWriter(file)
+		Expect(err).NotTo(HaveOccurred())
+		// regular files stand in for block devices, zero ranges are unmapped with a punched hole
+		writer.isBlock = true
+		_, err = WriteExtents(bytes.NewReader(stream), writer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		writes, ok := GetBlockWrites(target)
+		Expect(ok).To(BeTrue())
+		Expect(writes.BytesWritten).To(Equal(int64(sparseBlockSize)))
+		Expect(writes.Unmapped).To(Equal([]BlockRange{{Offset: sparseBlockSize, Length: 2 * sparseBlockSize}}))
+	})
+
+	It("Should reject a stream without the magic", func() {
+		err := StreamExtentsToFile(bytes.NewReader(data(100, 1)), filepath.Join(tmpDir, "target.img"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not an extent stream"))
+	})
+
+	It("Should reject a truncated stream", func() {
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "source.img"), data(sparseBlockSize, 1), 0644)).To(Succeed())
+		stream, _ := encode("source.img")
+		err := StreamExtentsToFile(bytes.NewReader(stream[:len(stream)-extentHeaderSize]), filepath.Join(tmpDir, "target.img"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unable to read extent header"))
+	})
+
+	It("Should reject extents that are not contiguous", func() {
+		var header [extentHeaderSize]byte
+		header[0] = extentZero
+		binary.BigEndian.PutUint64(header[1:9], 10)
+		binary.BigEndian.PutUint64(header[9:], 10)
+		stream := append([]byte(extentMagic), header[:]...)
+		err := StreamExtentsToFile(bytes.NewReader(stream), filepath.Join(tmpDir, "target.img"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid extent at offset 10"))
+	})
+})
@@ -32,6 +32,15 @@ func GetResourceName(base, suffix string) string {
 	return naming.GetName(base, suffix, kvalidation.DNS1123SubdomainMaxLength)
 }
 
+// cronJobNameMaxLength is the maximum length of a CronJob name, leaving room for the suffix of the Jobs it creates
+const cronJobNameMaxLength = 52
+
+// GetCronJobName creates a name with provided suffix, and shortens if needed
+// with the length restriction for cron jobs
+func GetCronJobName(base, suffix string) string {
+	return naming.GetName(base, suffix, cronJobNameMaxLength)
+}
+
 // GetLabelNameFromResourceName creates a name with the length restriction for labels, and shortens if needed
 func GetLabelNameFromResourceName(resourceName string) string {
 	// resourceName can have dots, service name cannot
@@ -68,4 +68,11 @@ var _ = Describe("GetName", func() {
 		Expect(result).To(HavePrefix(shortenedWithoutHash))
 	})
 
+	It("Should shorten cron job names to leave room for the job suffix", func() {
+		Expect(GetCronJobName("name", "poller")).To(Equal("name-poller"))
+		result := GetCronJobName(word63, "poller")
+		Expect(len(result)).To(BeNumerically("<=", 52))
+		Expect(result).To(HaveSuffix("-poller"))
+	})
+
 })
@@ -0,0 +1,387 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// sparseBlockSize is the granularity used to detect zero blocks in the written data.
+	sparseBlockSize = 64 * 1024
+	// blkZeroOut is the BLKZEROOUT ioctl request, _IO(0x12, 127), not exposed by x/sys/unix.
+	blkZeroOut = 0x127f
+	// blkDiscard is the BLKDISCARD ioctl request, _IO(0x12, 119), not exposed by x/sys/unix.
+	blkDiscard = 0x1277
+)
+
+var (
+	// zeroBlock is compared with the written data to detect zero blocks.
+	zeroBlock = make([]byte, sparseBlockSize)
+
+	blockWritesMutex sync.Mutex
+	// blockWrites is what the closed SparseWriters wrote to each block device, which doesn't report its allocation.
+	blockWrites = map[string]BlockWrites{}
+
+	// sysBlockDir is where the queue attributes of the block devices are found, by device number.
+	sysBlockDir = "/sys/dev/block"
+)
+
+// BlockRange is a range of a block device.
+type BlockRange struct {
+	Offset int64
+	Length int64
+}
+
+// BlockWrites is what the SparseWriters wrote to a block device.
+type BlockWrites struct {
+	// BytesWritten is the number of non-zero bytes written.
+	BytesWritten int64
+	// End is the end of the highest range written or zeroed.
+	End int64
+	// Unmapped are the zero ranges that were deallocated, they read back as zeroes but are not provisioned.
+	Unmapped []BlockRange
+}
+
+// SparseWriter writes data to a file or block device, skipping blocks that only contain zeroes.
+// Skipped ranges are left as holes in regular files (punched if they may contain older data). On block devices they
+// are unmapped with a punched hole, or a discard if the device reads discarded ranges back as zeroes, so thin
+// provisioned devices don't allocate them. Devices that can't unmap get them zeroed out with BLKZEROOUT, which may
+// still offload the zeroes to the device but provisions the range, and zeroes are written as a last resort.
+type SparseWriter struct {
+	file    *os.File
+	isBlock bool
+	// offset is the position used by sequential writes.
+	offset int64
+	// size is the end of the highest range written or zeroed.
+	size int64
+	// dataEnd is the end of the range of a regular file that may contain data.
+	dataEnd int64
+	// written is the number of non-zero bytes written.
+	written int64
+	// unmapped are the zero ranges of a block device that were deallocated.
+	unmapped []BlockRange
+}
+
+// NewSparseWriter creates a SparseWriter on top of an open file or block device.
+func NewSparseWriter(file *os.File) (*SparseWriter, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not stat %q", file.Name())
+	}
+	w := &SparseWriter{
+		file:    file,
+		isBlock: info.Mode()&os.ModeDevice != 0,
+	}
+	if !w.isBlock {
+		w.dataEnd = info.Size()
+	}
+	return w, nil
+}
+
+// Write writes the buffer at the current offset, skipping zero blocks.
+func (w *SparseWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+// WriteAt writes the buffer at the given offset, skipping zero blocks.
+func (w *SparseWriter) WriteAt(p []byte, off int64) (int, error) {
+	start := 0
+	for start < len(p) {
+		end := nextBlockEnd(p, start)
+		zero := isZero(p[start:end])
+		// Merge following blocks of the same kind, to issue as few calls as possible.
+		for end < len(p) {
+			next := nextBlockEnd(p, end)
+			if isZero(p[end:next]) != zero {
+				break
+			}
+			end = next
+		}
+		if zero {
+			if err := w.ZeroRange(off+int64(start), int64(end-start)); err != nil {
+				return start, err
+			}
+		} else {
+			n, err := w.file.WriteAt(p[start:end], off+int64(start))
+			w.written += int64(n)
+			w.extend(off + int64(start+n))
+			if err != nil {
+				return start + n, err
+			}
+		}
+		start = end
+	}
+	return len(p), nil
+}
+
+// ZeroRange makes sure the given range reads back as zeroes, deallocating it if the file or device supports it.
+func (w *SparseWriter) ZeroRange(off, length int64) error {
+	if length <= 0 {
+		return nil
+	}
+	end := off + length
+	if w.isBlock {
+		if err := unmapBlock(w.file, off, length); err != nil {
+			klog.V(3).Infof("Unable to unmap block range, zeroing it out: %v", err)
+			if err := zeroOutBlock(w.file, off, length); err != nil {
+				return err
+			}
+		} else {
+			w.addUnmapped(off, length)
+		}
+	} else if off < w.dataEnd {
+		// Only the part of the range that may already contain data needs a hole punched, the rest is a hole.
+		punchLength := length
+		if end > w.dataEnd {
+			punchLength = w.dataEnd - off
+		}
+		mode := uint32(unix.FALLOC_FL_PUNCH_HOLE | unix.FALLOC_FL_KEEP_SIZE)
+		if err := unix.Fallocate(int(w.file.Fd()), mode, off, punchLength); err != nil {
+			klog.V(3).Infof("Unable to punch hole, writing zeroes: %v", err)
+			if err := writeZeroes(w.file, off, punchLength); err != nil {
+				return err
+			}
+		}
+	}
+	if end > w.size {
+		w.size = end
+	}
+	return nil
+}
+
+// Close extends regular files to cover trailing holes, syncs and closes the underlying file.
+func (w *SparseWriter) Close() error {
+	if w.isBlock {
+		blockWritesMutex.Lock()
+		writes := blockWrites[w.file.Name()]
+		writes.BytesWritten += w.written
+		if w.size > writes.End {
+			writes.End = w.size
+		}
+		writes.Unmapped = append(writes.Unmapped, w.unmapped...)
+		blockWrites[w.file.Name()] = writes
+		blockWritesMutex.Unlock()
+	}
+	if err := w.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// Flush extends regular files to cover trailing holes and syncs the data to disk.
+func (w *SparseWriter) Flush() error {
+	if !w.isBlock && w.size > w.dataEnd {
+		if err := w.file.Truncate(w.size); err != nil {
+			return errors.Wrapf(err, "could not resize %q", w.file.Name())
+		}
+		w.dataEnd = w.size
+	}
+	return w.file.Sync()
+}
+
+// SetOffset sets the offset of the next sequential Write.
+func (w *SparseWriter) SetOffset(off int64) {
+	w.offset = off
+}
+
+// BytesWritten returns the number of non-zero bytes that were actually written.
+func (w *SparseWriter) BytesWritten() int64 {
+	return w.written
+}
+
+func (w *SparseWriter) extend(end int64) {
+	if end > w.size {
+		w.size = end
+	}
+	if !w.isBlock && end > w.dataEnd {
+		w.dataEnd = end
+	}
+}
+
+// addUnmapped records a deallocated range, merging it with the previous one when they are contiguous.
+func (w *SparseWriter) addUnmapped(off, length int64) {
+	if n := len(w.unmapped); n > 0 && w.unmapped[n-1].Offset+w.unmapped[n-1].Length == off {
+		w.unmapped[n-1].Length += length
+		return
+	}
+	w.unmapped = append(w.unmapped, BlockRange{Offset: off, Length: length})
+}
+
+func writeZeroes(file *os.File, off, length int64) error {
+	buf := make([]byte, sparseBlockSize)
+	for length > 0 {
+		chunk := int64(len(buf))
+		if length < chunk {
+			chunk = length
+		}
+		n, err := file.WriteAt(buf[:chunk], off)
+		if err != nil {
+			return errors.Wrap(err, "unable to write zeroes")
+		}
+		off += int64(n)
+		length -= int64(n)
+	}
+	return nil
+}
+
+// unmapBlock deallocates a range of a block device so it reads back as zeroes, with a punched hole, which the kernel
+// only accepts if the device can zero the range without writing it, or with a discard if the device reads discarded
+// ranges back as zeroes.
+func unmapBlock(file *os.File, off, length int64) error {
+	mode := uint32(unix.FALLOC_FL_PUNCH_HOLE | unix.FALLOC_FL_KEEP_SIZE)
+	err := unix.Fallocate(int(file.Fd()), mode, off, length)
+	if err == nil {
+		return nil
+	}
+	if !discardZeroesData(file) {
+		return errors.Wrap(err, "unable to punch hole and discards don't zero data")
+	}
+	return blockRangeIoctl(file, blkDiscard, off, length)
+}
+
+// zeroOutBlock zeroes out a range of a block device with BLKZEROOUT, which provisions the range, falling back to
+// writing zeroes.
+func zeroOutBlock(file *os.File, off, length int64) error {
+	if err := blockRangeIoctl(file, blkZeroOut, off, length); err != nil {
+		klog.V(3).Infof("Unable to zero out block range, writing zeroes: %v", err)
+		return writeZeroes(file, off, length)
+	}
+	return nil
+}
+
+// discardZeroesData returns true if the queue of the block device reports that discarded ranges read back as zeroes.
+func discardZeroesData(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	dev := uint64(stat.Rdev)
+	path := filepath.Join(sysBlockDir, fmt.Sprintf("%d:%d", unix.Major(dev), unix.Minor(dev)), "queue", "discard_zeroes_data")
+	value, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(value)) == "1"
+}
+
+func blockRangeIoctl(file *os.File, request uintptr, off, length int64) error {
+	r := [2]uint64{uint64(off), uint64(length)}
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, file.Fd(), request, uintptr(unsafe.Pointer(&r[0])))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func nextBlockEnd(p []byte, start int) int {
+	end := start + sparseBlockSize
+	if end > len(p) {
+		end = len(p)
+	}
+	return end
+}
+
+// isZero returns true if p only contains zeroes, p must not be larger than sparseBlockSize.
+func isZero(p []byte) bool {
+	return bytes.Equal(p, zeroBlock[:len(p)])
+}
+
+// GetBlockWrites returns what the closed SparseWriters wrote to the block device at path, and false if none was
+// closed.
+func GetBlockWrites(path string) (BlockWrites, bool) {
+	blockWritesMutex.Lock()
+	defer blockWritesMutex.Unlock()
+	writes, ok := blockWrites[path]
+	return writes, ok
+}
+
+// GetAllocatedSize returns the number of bytes allocated on disk for the regular file at path, or -1 if the path
+// is not a regular file, as block devices don't report their allocation.
+func GetAllocatedSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return int64(-1), err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !info.Mode().IsRegular() || !ok {
+		return int64(-1), nil
+	}
+	return stat.Blocks * 512, nil
+}
+
+// PreallocateFile allocates the holes of the regular file at path, without changing its content.
+func PreallocateFile(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return errors.Wrapf(err, "could not open %q", path)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "could not stat %q", path)
+	}
+	if err := unix.Fallocate(int(file.Fd()), 0, 0, info.Size()); err != nil {
+		return errors.Wrapf(err, "could not preallocate %q", path)
+	}
+	return file.Sync()
+}
+
+// PreallocateBlock zeroes out the block device at path from offset to its end with BLKZEROOUT, so all of it is
+// provisioned, along with the ranges below offset that SparseWriters unmapped. BLKZEROOUT doesn't unmap the range it
+// zeroes.
+func PreallocateBlock(path string, offset int64) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return errors.Wrapf(err, "could not open %q", path)
+	}
+	defer file.Close()
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errors.Wrapf(err, "could not determine size of %q", path)
+	}
+	blockWritesMutex.Lock()
+	writes := blockWrites[path]
+	unmapped := writes.Unmapped
+	writes.Unmapped = nil
+	if _, ok := blockWrites[path]; ok {
+		blockWrites[path] = writes
+	}
+	blockWritesMutex.Unlock()
+	ranges := []BlockRange{}
+	for _, r := range unmapped {
+		if r.Offset+r.Length > offset {
+			r.Length = offset - r.Offset
+		}
+		if r.Length > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	if size > offset {
+		ranges = append(ranges, BlockRange{Offset: offset, Length: size - offset})
+	}
+	for _, r := range ranges {
+		if err := zeroOutBlock(file, r.Offset, r.Length); err != nil {
+			return errors.Wrapf(err, "could not preallocate %q", path)
+		}
+	}
+	return file.Sync()
+}
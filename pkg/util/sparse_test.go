@@ -0,0 +1,173 @@
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sparse writer", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "sparse")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	data := func(size int, value byte) []byte {
+		return bytes.Repeat([]byte{value}, size)
+	}
+
+	openWriter := func(name string) *SparseWriter {
+		file, err := os.OpenFile(filepath.Join(tmpDir, name), os.O_CREATE|os.O_WRONLY, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		writer, err := NewSparseWriter(file)
+		Expect(err).NotTo(HaveOccurred())
+		return writer
+	}
+
+	It("Should skip zero blocks and keep the content", func() {
+		content := append(data(sparseBlockSize, 1), data(4*sparseBlockSize, 0)...)
+		content = append(content, data(sparseBlockSize, 2)...)
+		content = append(content, data(2*sparseBlockSize, 0)...)
+
+		writer := openWriter("disk.img")
+		n, err := writer.Write(content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(len(content)))
+		Expect(writer.BytesWritten()).To(Equal(int64(2 * sparseBlockSize)))
+		Expect(writer.Close()).To(Succeed())
+
+		result, err := ioutil.ReadFile(filepath.Join(tmpDir, "disk.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(content))
+		allocated, err := GetAllocatedSize(filepath.Join(tmpDir, "disk.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allocated).To(BeNumerically("<", len(content)))
+	})
+
+	It("Should zero existing data in a range", func() {
+		writer := openWriter("disk.img")
+		_, err := writer.Write(data(4*sparseBlockSize, 1))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		writer = openWriter("disk.img")
+		Expect(writer.ZeroRange(sparseBlockSize, 2*sparseBlockSize)).To(Succeed())
+		_, err = writer.WriteAt(data(sparseBlockSize, 0), 3*sparseBlockSize)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		result, err := ioutil.ReadFile(filepath.Join(tmpDir, "disk.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(append(data(sparseBlockSize, 1), data(3*sparseBlockSize, 0)...)))
+	})
+
+	It("Should extend the file over trailing holes", func() {
+		writer := openWriter("disk.img")
+		_, err := writer.Write(data(3*sparseBlockSize, 0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.BytesWritten()).To(BeZero())
+		Expect(writer.Close()).To(Succeed())
+
+		info, err := os.Stat(filepath.Join(tmpDir, "disk.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Size()).To(Equal(int64(3 * sparseBlockSize)))
+	})
+
+	It("Should stream data to a sparse file", func() {
+		content := append(data(2*sparseBlockSize, 0), data(10, 3)...)
+		Expect(StreamDataToFile(bytes.NewReader(content), filepath.Join(tmpDir, "disk.img"))).To(Succeed())
+		result, err := ioutil.ReadFile(filepath.Join(tmpDir, "disk.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(content))
+	})
+
+	It("Should write blocks with a single non-zero byte", func() {
+		content := data(2*sparseBlockSize, 0)
+		content[sparseBlockSize-1] = 1
+		content[sparseBlockSize] = 1
+
+		writer := openWriter("disk.img")
+		_, err := writer.Write(content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.BytesWritten()).To(Equal(int64(2 * sparseBlockSize)))
+		Expect(writer.Close()).To(Succeed())
+	})
+
+	It("Should record the bytes written to block devices", func() {
+		writer := openWriter("block.img")
+		// regular files stand in for block devices, zero ranges are unmapped with a punched hole
+		writer.isBlock = true
+		_, err := writer.Write(append(data(sparseBlockSize, 1), data(sparseBlockSize, 0)...))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		writes, ok := GetBlockWrites(filepath.Join(tmpDir, "block.img"))
+		Expect(ok).To(BeTrue())
+		Expect(writes.BytesWritten).To(Equal(int64(sparseBlockSize)))
+		Expect(writes.End).To(Equal(int64(2 * sparseBlockSize)))
+		_, ok = GetBlockWrites(filepath.Join(tmpDir, "disk.img"))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Should provision the unmapped ranges of block devices when preallocating", func() {
+		path := filepath.Join(tmpDir, "block.img")
+		content := append(data(sparseBlockSize, 1), data(2*sparseBlockSize, 0)...)
+		content = append(content, data(sparseBlockSize, 2)...)
+		writer := openWriter("block.img")
+		writer.isBlock = true
+		_, err := writer.Write(content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		writes, ok := GetBlockWrites(path)
+		Expect(ok).To(BeTrue())
+		Expect(writes.Unmapped).To(Equal([]BlockRange{{Offset: sparseBlockSize, Length: 2 * sparseBlockSize}}))
+		allocated, err := GetAllocatedSize(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allocated).To(BeNumerically("<", len(content)))
+
+		Expect(PreallocateBlock(path, writes.End)).To(Succeed())
+		allocated, err = GetAllocatedSize(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allocated).To(BeNumerically(">=", len(content)))
+		result, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(content))
+		writes, _ = GetBlockWrites(path)
+		Expect(writes.Unmapped).To(BeEmpty())
+	})
+
+	It("Should only discard when the device reads discarded ranges back as zeroes", func() {
+		defer func(dir string) { sysBlockDir = dir }(sysBlockDir)
+		sysBlockDir = tmpDir
+		file, err := os.Create(filepath.Join(tmpDir, "block.img"))
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+		Expect(discardZeroesData(file)).To(BeFalse())
+
+		// regular files have no device number
+		queueDir := filepath.Join(tmpDir, "0:0", "queue")
+		Expect(os.MkdirAll(queueDir, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(queueDir, "discard_zeroes_data"), []byte("0\n"), 0644)).To(Succeed())
+		Expect(discardZeroesData(file)).To(BeFalse())
+		Expect(ioutil.WriteFile(filepath.Join(queueDir, "discard_zeroes_data"), []byte("1\n"), 0644)).To(Succeed())
+		Expect(discardZeroesData(file)).To(BeTrue())
+	})
+
+	It("Should not report allocation for directories", func() {
+		allocated, err := GetAllocatedSize(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allocated).To(Equal(int64(-1)))
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -140,12 +141,60 @@ func MinQuantity(availableSpace, imageSize *resource.Quantity) resource.Quantity
 	return *imageSize
 }
 
-// StreamDataToFile provides a function to stream the specified io.Reader to the specified local file
+// StreamDataToFile provides a function to stream the specified io.Reader to the specified local file, zero blocks
+// are skipped so they are not allocated on the target.
 func StreamDataToFile(r io.Reader, fileName string) error {
+	outFile, err := OpenFileOrBlockDevice(fileName)
+	if err != nil {
+		return err
+	}
+	writer, err := NewSparseWriter(outFile)
+	if err != nil {
+		outFile.Close()
+		return err
+	}
+	klog.V(1).Infof("Writing data...\n")
+	if _, err = io.Copy(writer, r); err != nil {
+		klog.Errorf("Unable to write file from dataReader: %v\n", err)
+		outFile.Close()
+		os.Remove(outFile.Name())
+		return errors.Wrapf(err, "unable to write to file")
+	}
+	klog.V(1).Infof("Wrote %d non-zero bytes to %s\n", writer.BytesWritten(), fileName)
+	return writer.Close()
+}
+
+// StreamExtentsToFile applies the extent stream read from r to the file or block device fileName, only writing the
+// data extents and zeroing the others.
+func StreamExtentsToFile(r io.Reader, fileName string) error {
+	outFile, err := OpenFileOrBlockDevice(fileName)
+	if err != nil {
+		return err
+	}
+	writer, err := NewSparseWriter(outFile)
+	if err != nil {
+		outFile.Close()
+		return err
+	}
+	klog.V(1).Infof("Writing extents...\n")
+	size, err := WriteExtents(r, writer)
+	if err != nil {
+		klog.Errorf("Unable to write file from extent stream: %v\n", err)
+		outFile.Close()
+		os.Remove(outFile.Name())
+		return errors.Wrapf(err, "unable to write to file")
+	}
+	klog.V(1).Infof("Wrote %d non-zero bytes of %d to %s\n", writer.BytesWritten(), size, fileName)
+	return writer.Close()
+}
+
+// OpenFileOrBlockDevice opens the block device fileName for writing, or creates the regular file fileName, failing
+// if it already exists.
+func OpenFileOrBlockDevice(fileName string) (*os.File, error) {
 	var outFile *os.File
 	blockSize, err := GetAvailableSpaceBlock(fileName)
 	if err != nil {
-		return errors.Wrapf(err, "error determining if block device exists")
+		return nil, errors.Wrapf(err, "error determining if block device exists")
 	}
 	if blockSize >= 0 {
 		// Block device found and size determined.
@@ -154,18 +203,38 @@ func StreamDataToFile(r io.Reader, fileName string) error {
 		// Attempt to create the file with name filePath.  If it exists, fail.
 		outFile, err = os.OpenFile(fileName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.ModePerm)
 	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open file %q", fileName)
+	}
+	return outFile, nil
+}
+
+// StreamDataToFileAt streams the specified io.Reader to the regular file fileName starting at offset, creating the
+// file if needed and discarding its content past offset. Unlike StreamDataToFile the file is kept if writing fails,
+// so an interrupted transfer can be resumed, from at most the size of the file.
+func StreamDataToFileAt(r io.Reader, fileName string, offset int64) error {
+	outFile, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY, os.ModePerm)
 	if err != nil {
 		return errors.Wrapf(err, "could not open file %q", fileName)
 	}
-	defer outFile.Close()
-	klog.V(1).Infof("Writing data...\n")
-	if _, err = io.Copy(outFile, r); err != nil {
+	if err := outFile.Truncate(offset); err != nil {
+		outFile.Close()
+		return errors.Wrapf(err, "could not truncate file %q", fileName)
+	}
+	writer, err := NewSparseWriter(outFile)
+	if err != nil {
+		outFile.Close()
+		return err
+	}
+	writer.SetOffset(offset)
+	klog.V(1).Infof("Writing data at offset %d...\n", offset)
+	if _, err = io.Copy(writer, r); err != nil {
 		klog.Errorf("Unable to write file from dataReader: %v\n", err)
-		os.Remove(outFile.Name())
+		outFile.Close()
 		return errors.Wrapf(err, "unable to write to file")
 	}
-	err = outFile.Sync()
-	return err
+	klog.V(1).Infof("Wrote %d non-zero bytes to %s\n", writer.BytesWritten(), fileName)
+	return writer.Close()
 }
 
 // UnArchiveTar unarchives a tar file and streams its files
@@ -236,6 +305,99 @@ func WriteTerminationMessageToFile(file, message string) error {
 	return nil
 }
 
+// TerminationMessage is the structured termination message of the pods populating a PVC
+type TerminationMessage struct {
+	// Message is the human readable outcome of the pod
+	Message string `json:"message"`
+	// AllocatedBytes is the amount of storage allocated on the target, when it can be determined
+	AllocatedBytes *int64 `json:"allocatedBytes,omitempty"`
+	// PreallocationApplied is set when the target was fully allocated
+	PreallocationApplied bool `json:"preallocationApplied,omitempty"`
+	// SourceDigest is the digest or ETag of the source, reported by the pods polling it
+	SourceDigest string `json:"sourceDigest,omitempty"`
+	// VirtualSize is the virtual size of the source image, reported by the pods probing it
+	VirtualSize *int64 `json:"virtualSize,omitempty"`
+	// FailedTargets are the targets a fan-out clone source pod could not clone to
+	FailedTargets []string `json:"failedTargets,omitempty"`
+}
+
+// UploadStatus is the progress of an upload, reported by the upload server
+type UploadStatus struct {
+	// BytesReceived is the amount of data received so far
+	BytesReceived int64 `json:"bytesReceived"`
+	// Format is the format of the uploaded data, once detected
+	Format string `json:"format,omitempty"`
+	// Phase is the processing phase of the uploaded data
+	Phase string `json:"phase,omitempty"`
+	// Error is the error the processing of the uploaded data failed with, like a validation error
+	Error string `json:"error,omitempty"`
+}
+
+// WriteCompletionMessage writes the passed in message to the default termination message file, along with
+// the amount of storage allocated by the file at path, or written to the block device at path, and whether it was
+// preallocated
+func WriteCompletionMessage(message, path string, preallocationApplied bool) error {
+	termMsg := TerminationMessage{Message: message, PreallocationApplied: preallocationApplied}
+	allocated, err := GetAllocatedSize(path)
+	if err != nil {
+		klog.Warningf("Unable to determine allocated size of %s: %v", path, err)
+	} else if allocated >= 0 {
+		termMsg.AllocatedBytes = &allocated
+	} else if size, err := GetAvailableSpaceBlock(path); preallocationApplied && err == nil && size >= 0 {
+		// preallocated block devices are provisioned entirely
+		termMsg.AllocatedBytes = &size
+	} else if writes, ok := GetBlockWrites(path); ok {
+		// block devices don't report their allocation, the data written to them is allocated
+		termMsg.AllocatedBytes = &writes.BytesWritten
+	}
+	data, err := json.Marshal(termMsg)
+	if err != nil {
+		return errors.Wrap(err, "could not serialize termination message")
+	}
+	return WriteTerminationMessage(string(data))
+}
+
+// WriteSourceDigestMessage writes the digest of the polled source to the default termination message file
+func WriteSourceDigestMessage(digest string) error {
+	data, err := json.Marshal(TerminationMessage{Message: "Source polled", SourceDigest: digest})
+	if err != nil {
+		return errors.Wrap(err, "could not serialize termination message")
+	}
+	return WriteTerminationMessage(string(data))
+}
+
+// WriteVirtualSizeMessage writes the virtual size of the probed source image to the default termination message file
+func WriteVirtualSizeMessage(virtualSize int64) error {
+	data, err := json.Marshal(TerminationMessage{Message: "Source probed", VirtualSize: &virtualSize})
+	if err != nil {
+		return errors.Wrap(err, "could not serialize termination message")
+	}
+	return WriteTerminationMessage(string(data))
+}
+
+// WriteCloneCompletionMessage writes the completion message of a clone source pod to the default termination message
+// file, along with the targets it could not clone to
+func WriteCloneCompletionMessage(message string, failedTargets []string) error {
+	if len(failedTargets) == 0 {
+		return WriteTerminationMessage(message)
+	}
+	data, err := json.Marshal(TerminationMessage{Message: message, FailedTargets: failedTargets})
+	if err != nil {
+		return errors.Wrap(err, "could not serialize termination message")
+	}
+	return WriteTerminationMessage(string(data))
+}
+
+// ParseTerminationMessage parses a termination message written by WriteCompletionMessage, plain text messages are
+// returned unchanged
+func ParseTerminationMessage(message string) TerminationMessage {
+	termMsg := TerminationMessage{}
+	if !strings.HasPrefix(message, "{") || json.Unmarshal([]byte(message), &termMsg) != nil {
+		return TerminationMessage{Message: message}
+	}
+	return termMsg
+}
+
 // CopyDir copies a dir from one location to another.
 func CopyDir(source string, dest string) (err error) {
 	// get properties of source dir
@@ -124,6 +124,38 @@ var _ = Describe("Copy files", func() {
 	})
 })
 
+var _ = Describe("Termination message", func() {
+	It("Should parse a structured termination message", func() {
+		allocated := int64(1024)
+		termMsg := ParseTerminationMessage(`{"message":"Import Complete","allocatedBytes":1024}`)
+		Expect(termMsg.Message).To(Equal("Import Complete"))
+		Expect(termMsg.AllocatedBytes).To(Equal(&allocated))
+	})
+
+	It("Should parse the source digest of a polling pod", func() {
+		termMsg := ParseTerminationMessage(`{"message":"Source polled","sourceDigest":"sha256:1234"}`)
+		Expect(termMsg.Message).To(Equal("Source polled"))
+		Expect(termMsg.SourceDigest).To(Equal("sha256:1234"))
+	})
+
+	It("Should parse the virtual size of a probing pod", func() {
+		virtualSize := int64(10737418240)
+		termMsg := ParseTerminationMessage(`{"message":"Source probed","virtualSize":10737418240}`)
+		Expect(termMsg.Message).To(Equal("Source probed"))
+		Expect(termMsg.VirtualSize).To(Equal(&virtualSize))
+	})
+
+	table.DescribeTable("Should return plain text messages unchanged", func(message string) {
+		termMsg := ParseTerminationMessage(message)
+		Expect(termMsg.Message).To(Equal(message))
+		Expect(termMsg.AllocatedBytes).To(BeNil())
+	},
+		table.Entry("plain message", "Unable to process data: EOF"),
+		table.Entry("empty message", ""),
+		table.Entry("invalid json", "{not json"),
+	)
+})
+
 func md5sum(filePath string) (string, error) {
 	var returnMD5String string
 
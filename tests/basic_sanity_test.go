@@ -131,6 +131,10 @@ var _ = Describe("[rfe_id:1347][crit:high][vendor:cnv-qe@redhat.com][level:compo
 			table.Entry("[test_id:5056]CDIConfigs", "cdiconfigs.cdi.kubevirt.io"),
 			table.Entry("[test_id:5057]CDIs", "cdis.cdi.kubevirt.io"),
 			table.Entry("[test_id:5056]Datavolumes", "datavolumes.cdi.kubevirt.io"),
+			table.Entry("DataSources", "datasources.cdi.kubevirt.io"),
+			table.Entry("DataImportCrons", "dataimportcrons.cdi.kubevirt.io"),
+			table.Entry("StorageProfiles", "storageprofiles.cdi.kubevirt.io"),
+			table.Entry("VolumeExports", "volumeexports.cdi.kubevirt.io"),
 		)
 	})
 })
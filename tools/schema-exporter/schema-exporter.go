@@ -45,6 +45,10 @@ func main() {
 	crds = append(crds, cdioperator.NewCdiCrd())
 	crds = append(crds, cluster.NewCdiConfigCrd())
 	crds = append(crds, cluster.NewDataVolumeCrd())
+	crds = append(crds, cluster.NewDataSourceCrd())
+	crds = append(crds, cluster.NewDataImportCronCrd())
+	crds = append(crds, cluster.NewStorageProfileCrd())
+	crds = append(crds, cluster.NewVolumeExportCrd())
 
 	for _, crd := range crds {
 		crdPath := filepath.Join(*exportPath, crd.GetObjectMeta().GetName())